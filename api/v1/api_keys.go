@@ -0,0 +1,198 @@
+package api
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dairycart/dairycart/models/v1"
+	"github.com/dairycart/dairycart/storage/v1/database"
+
+	"github.com/dchest/uniuri"
+	"github.com/go-chi/chi"
+	"github.com/pkg/errors"
+)
+
+const (
+	apiKeyPrefix       = "dc"
+	apiKeyPrefixLength = 8
+	apiKeySecretLength = 32
+
+	// apiKeyUsageResolution is how stale an API key's last used time is allowed to get
+	apiKeyUsageResolution = time.Minute
+
+	// apiKeysWritePermission lets users act on other users' API keys. Everyone can manage their own.
+	apiKeysWritePermission = "api_keys:write"
+	apiKeyOwnerQueryKey    = "user_id"
+)
+
+// generateAPIKey returns a new API key, and the prefix it can be recognized by after it's been hashed.
+// Keys look like dc_<prefix>_<secret>.
+func generateAPIKey() (key, prefix string) {
+	prefix = apiKeyPrefix + "_" + uniuri.NewLen(apiKeyPrefixLength)
+	return prefix + "_" + uniuri.NewLen(apiKeySecretLength), prefix
+}
+
+// hashAPIKey hashes an API key for storage. Keys are long and random, so unlike passwords they don't need a slow hash.
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// bearerToken returns the token from a request's `Authorization: Bearer <token>` header
+func bearerToken(req *http.Request) (string, bool) {
+	parts := strings.SplitN(req.Header.Get("Authorization"), " ", 2)
+	if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") {
+		return "", false
+	}
+	token := strings.TrimSpace(parts[1])
+	return token, token != ""
+}
+
+// canManageAPIKeysOf reports whether user can act on the API keys of the user with the given id. Everyone can
+// manage their own keys, and the api_keys:write permission extends that to other users, but not to admins: only
+// admins can touch an admin's keys, so the permission can't be used to get at an admin's access.
+func canManageAPIKeysOf(db database.Querier, client database.Storer, user *authenticatedUser, ownerID uint64) (bool, error) {
+	if ownerID == user.ID || user.IsAdmin {
+		return true, nil
+	}
+	if !user.hasPermission(apiKeysWritePermission) {
+		return false, nil
+	}
+
+	owner, err := client.GetUser(db, ownerID)
+	if err == sql.ErrNoRows {
+		// whether an archived user was an admin can't be checked, so their keys are left alone
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return !owner.IsAdmin, nil
+}
+
+func buildAPIKeyListHandler(db *sql.DB, client database.Storer) http.HandlerFunc {
+	// APIKeyListHandler is a request handler that returns the API keys that belong to the requesting user, or, for
+	// users with the api_keys:write permission, to the user given by the user_id query parameter if they aren't an admin
+	return func(res http.ResponseWriter, req *http.Request) {
+		user, _ := authenticatedUserFromRequest(req)
+
+		ownerID := user.ID
+		if rawOwnerID := req.URL.Query().Get(apiKeyOwnerQueryKey); rawOwnerID != "" {
+			var err error
+			ownerID, err = strconv.ParseUint(rawOwnerID, 10, 64)
+			if err != nil {
+				notifyOfInvalidRequestBody(res, fmt.Errorf("invalid user id: '%s'", rawOwnerID))
+				return
+			}
+		}
+		allowed, err := canManageAPIKeysOf(db, client, user, ownerID)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve API key owner")
+			return
+		}
+		if !allowed {
+			notifyOfForbiddenRequest(res)
+			return
+		}
+
+		apiKeys, err := client.GetAPIKeysByUserID(db, ownerID)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve API keys from the database")
+			return
+		}
+
+		apiKeysResponse := &ListResponse{
+			Page:  1,
+			Count: uint64(len(apiKeys)),
+			Data:  apiKeys,
+		}
+		json.NewEncoder(res).Encode(apiKeysResponse)
+	}
+}
+
+func buildAPIKeyCreationHandler(db *sql.DB, client database.Storer) http.HandlerFunc {
+	// APIKeyCreationHandler is a request handler that creates an API key for the requesting user
+	return func(res http.ResponseWriter, req *http.Request) {
+		user, _ := authenticatedUserFromRequest(req)
+
+		input := &models.APIKeyCreationInput{}
+		err := validateRequestInput(req, input)
+		if err != nil {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
+		if input.Name == "" {
+			notifyOfInvalidRequestBody(res, errors.New("API keys must have a name"))
+			return
+		}
+		if input.ExpiresOn != nil && !input.ExpiresOn.After(time.Now()) {
+			notifyOfInvalidRequestBody(res, errors.New("API keys can't expire in the past"))
+			return
+		}
+
+		key, prefix := generateAPIKey()
+		newAPIKey := models.APIKey{
+			UserID:    user.ID,
+			Name:      input.Name,
+			Prefix:    prefix,
+			KeyHash:   hashAPIKey(key),
+			ExpiresOn: input.ExpiresOn,
+		}
+
+		newAPIKey.ID, newAPIKey.CreatedOn, err = client.CreateAPIKey(db, &newAPIKey)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "insert API key into database")
+			return
+		}
+
+		res.WriteHeader(http.StatusCreated)
+		json.NewEncoder(res).Encode(models.APIKeyCreationResponse{APIKey: newAPIKey, Key: key})
+	}
+}
+
+func buildAPIKeyDeletionHandler(db *sql.DB, client database.Storer) http.HandlerFunc {
+	// APIKeyDeletionHandler is a request handler that revokes one of the requesting user's API keys, or anyone's
+	// but an admin's, for users with the api_keys:write permission
+	return func(res http.ResponseWriter, req *http.Request) {
+		user, _ := authenticatedUserFromRequest(req)
+
+		apiKeyIDStr := chi.URLParam(req, "api_key_id")
+		// eating this error because the router should have ensured this is an integer
+		apiKeyID, _ := strconv.ParseUint(apiKeyIDStr, 10, 64)
+
+		apiKey, err := client.GetAPIKey(db, apiKeyID)
+		if err == sql.ErrNoRows {
+			respondThatRowDoesNotExist(req, res, "API key", apiKeyIDStr)
+			return
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve API key from database")
+			return
+		}
+
+		allowed, err := canManageAPIKeysOf(db, client, user, apiKey.UserID)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve API key owner")
+			return
+		}
+		// keys the user can't manage are treated as if they don't exist, so their ids aren't given away
+		if !allowed {
+			respondThatRowDoesNotExist(req, res, "API key", apiKeyIDStr)
+			return
+		}
+
+		archivedOn, err := client.DeleteAPIKey(db, apiKeyID)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "archive API key in database")
+			return
+		}
+		apiKey.ArchivedOn = &models.Dairytime{Time: archivedOn}
+
+		json.NewEncoder(res).Encode(apiKey)
+	}
+}
@@ -0,0 +1,423 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dairycart/dairycart/models/v1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateAPIKey(t *testing.T) {
+	t.Parallel()
+
+	key, prefix := generateAPIKey()
+	assert.True(t, strings.HasPrefix(prefix, "dc_"))
+	assert.True(t, strings.HasPrefix(key, prefix+"_"))
+	assert.Len(t, key, len(prefix)+1+apiKeySecretLength)
+
+	otherKey, _ := generateAPIKey()
+	assert.NotEqual(t, key, otherKey)
+}
+
+func TestHashAPIKey(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824", hashAPIKey("hello"))
+}
+
+func TestBearerToken(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		header   string
+		expected string
+		ok       bool
+	}{
+		{header: "Bearer example", expected: "example", ok: true},
+		{header: "bearer example", expected: "example", ok: true},
+		{header: "Bearer ", ok: false},
+		{header: "Basic example", ok: false},
+		{header: "", ok: false},
+	}
+
+	for _, tc := range testCases {
+		req, err := http.NewRequest(http.MethodGet, "", nil)
+		require.NoError(t, err)
+		req.Header.Set("Authorization", tc.header)
+
+		actual, ok := bearerToken(req)
+		assert.Equal(t, tc.expected, actual, "header: %q", tc.header)
+		assert.Equal(t, tc.ok, ok, "header: %q", tc.header)
+	}
+}
+
+////////////////////////////////////////////////////////
+//                                                    //
+//                 HTTP Handler Tests                 //
+//                                                    //
+////////////////////////////////////////////////////////
+
+func buildAuthenticatedRequest(t *testing.T, testUtil *TestUtil, method, url string, body *strings.Reader) *http.Request {
+	t.Helper()
+	var req *http.Request
+	var err error
+	if body != nil {
+		req, err = http.NewRequest(method, url, body)
+	} else {
+		req, err = http.NewRequest(method, url, nil)
+	}
+	require.NoError(t, err)
+
+	// users don't need any permissions to manage their own keys
+	req.AddCookie(buildCookieWithPermissionsForRequest(t, testUtil))
+	return req
+}
+
+func TestAPIKeyListHandler(t *testing.T) {
+	exampleAPIKeys := []models.APIKey{
+		{ID: 1, UserID: 666, Name: "deploys", Prefix: "dc_abcdefgh", KeyHash: "secret"},
+	}
+
+	t.Run("optimal conditions", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetAPIKeysByUserID", mock.Anything, uint64(666)).Return(exampleAPIKeys, nil)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req := buildAuthenticatedRequest(t, testUtil, http.MethodGet, "/v1/api_keys", nil)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusOK)
+		assert.Contains(t, testUtil.Response.Body.String(), `"prefix":"dc_abcdefgh"`)
+		assert.NotContains(t, testUtil.Response.Body.String(), "secret")
+	})
+
+	t.Run("for another user", func(t *testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetUser", mock.Anything, uint64(123)).Return(&models.User{ID: 123}, nil)
+		testUtil.MockDB.On("GetAPIKeysByUserID", mock.Anything, uint64(123)).Return(exampleAPIKeys, nil)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodGet, "/v1/api_keys?user_id=123", nil)
+		require.NoError(t, err)
+		req.AddCookie(buildCookieWithPermissionsForRequest(t, testUtil, "api_keys:write"))
+
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusOK)
+	})
+
+	t.Run("for an admin", func(t *testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetUser", mock.Anything, uint64(123)).Return(&models.User{ID: 123, IsAdmin: true}, nil)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodGet, "/v1/api_keys?user_id=123", nil)
+		require.NoError(t, err)
+		req.AddCookie(buildCookieWithPermissionsForRequest(t, testUtil, "api_keys:write"))
+
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusForbidden)
+		testUtil.MockDB.AssertNotCalled(t, "GetAPIKeysByUserID", mock.Anything, mock.Anything)
+	})
+
+	t.Run("for an admin as an admin", func(t *testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetAPIKeysByUserID", mock.Anything, uint64(123)).Return(exampleAPIKeys, nil)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodGet, "/v1/api_keys?user_id=123", nil)
+		require.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
+
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusOK)
+	})
+
+	t.Run("for an archived user", func(t *testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetUser", mock.Anything, uint64(123)).Return(&models.User{}, sql.ErrNoRows)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodGet, "/v1/api_keys?user_id=123", nil)
+		require.NoError(t, err)
+		req.AddCookie(buildCookieWithPermissionsForRequest(t, testUtil, "api_keys:write"))
+
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusForbidden)
+	})
+
+	t.Run("with error retrieving owner", func(t *testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetUser", mock.Anything, uint64(123)).Return(&models.User{}, generateArbitraryError())
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodGet, "/v1/api_keys?user_id=123", nil)
+		require.NoError(t, err)
+		req.AddCookie(buildCookieWithPermissionsForRequest(t, testUtil, "api_keys:write"))
+
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+	})
+
+	t.Run("for another user without permission", func(t *testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req := buildAuthenticatedRequest(t, testUtil, http.MethodGet, "/v1/api_keys?user_id=123", nil)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusForbidden)
+		testUtil.MockDB.AssertNotCalled(t, "GetAPIKeysByUserID", mock.Anything, mock.Anything)
+	})
+
+	t.Run("with invalid user id", func(t *testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req := buildAuthenticatedRequest(t, testUtil, http.MethodGet, "/v1/api_keys?user_id=banana", nil)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusBadRequest)
+	})
+
+	t.Run("without authorization", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodGet, "/v1/api_keys", nil)
+		require.NoError(t, err)
+
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusUnauthorized)
+	})
+
+	t.Run("with error retrieving API keys", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetAPIKeysByUserID", mock.Anything, uint64(666)).Return([]models.APIKey{}, generateArbitraryError())
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req := buildAuthenticatedRequest(t, testUtil, http.MethodGet, "/v1/api_keys", nil)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+	})
+}
+
+func TestAPIKeyCreationHandler(t *testing.T) {
+	t.Run("optimal conditions", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		var created *models.APIKey
+		testUtil.MockDB.On("CreateAPIKey", mock.Anything, mock.AnythingOfType("*models.APIKey")).
+			Run(func(args mock.Arguments) { created = args.Get(1).(*models.APIKey) }).
+			Return(uint64(1), buildTestTime(), nil)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req := buildAuthenticatedRequest(t, testUtil, http.MethodPost, "/v1/api_keys", strings.NewReader(`{"name": "deploys"}`))
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusCreated)
+
+		actual := models.APIKeyCreationResponse{}
+		require.NoError(t, json.NewDecoder(testUtil.Response.Body).Decode(&actual))
+		assert.Equal(t, uint64(1), actual.ID)
+		assert.Equal(t, uint64(666), created.UserID)
+		assert.Equal(t, "deploys", created.Name)
+		assert.True(t, strings.HasPrefix(actual.Key, created.Prefix+"_"))
+		assert.Equal(t, hashAPIKey(actual.Key), created.KeyHash)
+		assert.Nil(t, created.ExpiresOn)
+	})
+
+	t.Run("with expiry", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("CreateAPIKey", mock.Anything, mock.MatchedBy(func(k *models.APIKey) bool { return k.ExpiresOn != nil })).
+			Return(uint64(1), buildTestTime(), nil)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		body := fmt.Sprintf(`{"name": "deploys", "expires_on": %q}`, time.Now().Add(time.Hour).Format(time.RFC3339))
+		req := buildAuthenticatedRequest(t, testUtil, http.MethodPost, "/v1/api_keys", strings.NewReader(body))
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusCreated)
+	})
+
+	t.Run("with expiry in the past", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		body := fmt.Sprintf(`{"name": "deploys", "expires_on": %q}`, time.Now().Add(-time.Hour).Format(time.RFC3339))
+		req := buildAuthenticatedRequest(t, testUtil, http.MethodPost, "/v1/api_keys", strings.NewReader(body))
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusBadRequest)
+	})
+
+	t.Run("without name", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		body := fmt.Sprintf(`{"expires_on": %q}`, time.Now().Add(time.Hour).Format(time.RFC3339))
+		req := buildAuthenticatedRequest(t, testUtil, http.MethodPost, "/v1/api_keys", strings.NewReader(body))
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusBadRequest)
+	})
+
+	t.Run("with invalid input", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req := buildAuthenticatedRequest(t, testUtil, http.MethodPost, "/v1/api_keys", strings.NewReader(exampleGarbageInput))
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusBadRequest)
+	})
+
+	t.Run("with error creating API key", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("CreateAPIKey", mock.Anything, mock.AnythingOfType("*models.APIKey")).
+			Return(uint64(0), time.Time{}, generateArbitraryError())
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req := buildAuthenticatedRequest(t, testUtil, http.MethodPost, "/v1/api_keys", strings.NewReader(`{"name": "deploys"}`))
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+	})
+}
+
+func TestAPIKeyDeletionHandler(t *testing.T) {
+	buildExampleAPIKey := func() *models.APIKey {
+		return &models.APIKey{ID: 1, UserID: 666, Name: "deploys", Prefix: "dc_abcdefgh"}
+	}
+
+	t.Run("optimal conditions", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetAPIKey", mock.Anything, uint64(1)).Return(buildExampleAPIKey(), nil)
+		testUtil.MockDB.On("DeleteAPIKey", mock.Anything, uint64(1)).Return(buildTestTime(), nil)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req := buildAuthenticatedRequest(t, testUtil, http.MethodDelete, "/v1/api_keys/1", nil)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusOK)
+
+		actual := models.APIKey{}
+		require.NoError(t, json.NewDecoder(testUtil.Response.Body).Decode(&actual))
+		assert.NotNil(t, actual.ArchivedOn)
+	})
+
+	t.Run("with nonexistent API key", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetAPIKey", mock.Anything, uint64(1)).Return(buildExampleAPIKey(), sql.ErrNoRows)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req := buildAuthenticatedRequest(t, testUtil, http.MethodDelete, "/v1/api_keys/1", nil)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusNotFound)
+	})
+
+	t.Run("with another user's API key", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		exampleAPIKey := buildExampleAPIKey()
+		exampleAPIKey.UserID = 123
+		testUtil.MockDB.On("GetAPIKey", mock.Anything, uint64(1)).Return(exampleAPIKey, nil)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req := buildAuthenticatedRequest(t, testUtil, http.MethodDelete, "/v1/api_keys/1", nil)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusNotFound)
+		testUtil.MockDB.AssertNotCalled(t, "DeleteAPIKey", mock.Anything, mock.Anything)
+	})
+
+	t.Run("with another user's API key and permission", func(t *testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		exampleAPIKey := buildExampleAPIKey()
+		exampleAPIKey.UserID = 123
+		testUtil.MockDB.On("GetAPIKey", mock.Anything, uint64(1)).Return(exampleAPIKey, nil)
+		testUtil.MockDB.On("GetUser", mock.Anything, uint64(123)).Return(&models.User{ID: 123}, nil)
+		testUtil.MockDB.On("DeleteAPIKey", mock.Anything, uint64(1)).Return(buildTestTime(), nil)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodDelete, "/v1/api_keys/1", nil)
+		require.NoError(t, err)
+		req.AddCookie(buildCookieWithPermissionsForRequest(t, testUtil, "api_keys:write"))
+
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusOK)
+	})
+
+	t.Run("with an admin's API key and permission", func(t *testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		exampleAPIKey := buildExampleAPIKey()
+		exampleAPIKey.UserID = 123
+		testUtil.MockDB.On("GetAPIKey", mock.Anything, uint64(1)).Return(exampleAPIKey, nil)
+		testUtil.MockDB.On("GetUser", mock.Anything, uint64(123)).Return(&models.User{ID: 123, IsAdmin: true}, nil)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodDelete, "/v1/api_keys/1", nil)
+		require.NoError(t, err)
+		req.AddCookie(buildCookieWithPermissionsForRequest(t, testUtil, "api_keys:write"))
+
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusNotFound)
+		testUtil.MockDB.AssertNotCalled(t, "DeleteAPIKey", mock.Anything, mock.Anything)
+	})
+
+	t.Run("with an admin's API key as an admin", func(t *testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		exampleAPIKey := buildExampleAPIKey()
+		exampleAPIKey.UserID = 123
+		testUtil.MockDB.On("GetAPIKey", mock.Anything, uint64(1)).Return(exampleAPIKey, nil)
+		testUtil.MockDB.On("DeleteAPIKey", mock.Anything, uint64(1)).Return(buildTestTime(), nil)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodDelete, "/v1/api_keys/1", nil)
+		require.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
+
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusOK)
+	})
+
+	t.Run("with error retrieving API key", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetAPIKey", mock.Anything, uint64(1)).Return(buildExampleAPIKey(), generateArbitraryError())
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req := buildAuthenticatedRequest(t, testUtil, http.MethodDelete, "/v1/api_keys/1", nil)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+	})
+
+	t.Run("with error archiving API key", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetAPIKey", mock.Anything, uint64(1)).Return(buildExampleAPIKey(), nil)
+		testUtil.MockDB.On("DeleteAPIKey", mock.Anything, uint64(1)).Return(time.Time{}, generateArbitraryError())
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req := buildAuthenticatedRequest(t, testUtil, http.MethodDelete, "/v1/api_keys/1", nil)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+	})
+}
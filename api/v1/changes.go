@@ -0,0 +1,85 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/dairycart/dairycart/models/v1"
+	"github.com/dairycart/dairycart/storage/v1/database"
+)
+
+const (
+	defaultChangeFeedLimit = 100
+	maxChangeFeedLimit     = 1000
+)
+
+// parseChangeFeedToken reads the position a `since` token refers to. Tokens are the positions of changes, but
+// clients shouldn't depend on that, and no token means the beginning of the feed.
+func parseChangeFeedToken(token string) (uint64, error) {
+	if token == "" {
+		return 0, nil
+	}
+	position, err := strconv.ParseUint(token, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid since token: '%s'", token)
+	}
+	return position, nil
+}
+
+func buildChangeFeedToken(position uint64) string {
+	return strconv.FormatUint(position, 10)
+}
+
+func parseChangeFeedLimit(raw string) (uint, error) {
+	if raw == "" {
+		return defaultChangeFeedLimit, nil
+	}
+	limit, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil || limit == 0 {
+		return 0, fmt.Errorf("invalid limit: '%s'", raw)
+	}
+	if limit > maxChangeFeedLimit {
+		limit = maxChangeFeedLimit
+	}
+	return uint(limit), nil
+}
+
+func buildChangeFeedHandler(db *sql.DB, client database.Storer) http.HandlerFunc {
+	// changeFeedHandler is a request handler that returns the changes made after a point in the change feed, in the order they were made
+	return func(res http.ResponseWriter, req *http.Request) {
+		afterPosition, err := parseChangeFeedToken(req.URL.Query().Get("since"))
+		if err != nil {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
+		limit, err := parseChangeFeedLimit(req.URL.Query().Get("limit"))
+		if err != nil {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
+
+		// asking for one more than the limit tells us whether there are more changes waiting
+		changes, err := client.GetChangesAfter(db, afterPosition, limit+1)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve changes from the database")
+			return
+		}
+
+		feed := &models.ChangeFeedResponse{
+			Changes:   []models.Change{},
+			NextToken: buildChangeFeedToken(afterPosition),
+		}
+		if uint(len(changes)) > limit {
+			changes = changes[:limit]
+			feed.HasMore = true
+		}
+		if len(changes) > 0 {
+			feed.Changes = changes
+			feed.NextToken = buildChangeFeedToken(changes[len(changes)-1].Position)
+		}
+		json.NewEncoder(res).Encode(feed)
+	}
+}
@@ -0,0 +1,148 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/dairycart/dairycart/models/v1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseChangeFeedLimit(t *testing.T) {
+	t.Parallel()
+
+	inputOutputMap := map[string]uint{
+		"":      defaultChangeFeedLimit,
+		"1":     1,
+		"250":   250,
+		"10000": maxChangeFeedLimit,
+	}
+	for input, expected := range inputOutputMap {
+		actual, err := parseChangeFeedLimit(input)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, actual, "expected limit %q to be %d", input, expected)
+	}
+
+	for _, input := range []string{"0", "-1", "ten"} {
+		_, err := parseChangeFeedLimit(input)
+		assert.Error(t, err, "expected limit %q to be invalid", input)
+	}
+}
+
+////////////////////////////////////////////////////////
+//                                                    //
+//                 HTTP Handler Tests                 //
+//                                                    //
+////////////////////////////////////////////////////////
+
+func TestChangeFeedHandler(t *testing.T) {
+	exampleChanges := []models.Change{
+		{ID: 4, Position: 14, Resource: "product", ResourceID: 1, Operation: models.ChangeCreated, Data: json.RawMessage(`{"id":1,"sku":"skateboard"}`)},
+		{ID: 5, Position: 15, Resource: "product", ResourceID: 1, Operation: models.ChangeUpdated, Data: json.RawMessage(`{"id":1,"sku":"skateboard"}`)},
+		{ID: 6, Position: 16, Resource: "product", ResourceID: 1, Operation: models.ChangeArchived},
+	}
+
+	t.Run("optimal conditions", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetChangesAfter", mock.Anything, uint64(3), uint(defaultChangeFeedLimit+1)).
+			Return(exampleChanges, nil)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodGet, "/v1/changes?since=3", nil)
+		assert.NoError(t, err)
+
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusOK)
+
+		actual := &models.ChangeFeedResponse{}
+		require.NoError(t, json.NewDecoder(testUtil.Response.Body).Decode(actual))
+		assert.Len(t, actual.Changes, 3)
+		assert.Equal(t, "16", actual.NextToken)
+		assert.False(t, actual.HasMore)
+		assert.Equal(t, json.RawMessage("null"), actual.Changes[2].Data, "tombstones shouldn't have data")
+	})
+
+	t.Run("from the beginning", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetChangesAfter", mock.Anything, uint64(0), uint(3)).
+			Return(exampleChanges, nil)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodGet, "/v1/changes?limit=2", nil)
+		assert.NoError(t, err)
+
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusOK)
+
+		actual := &models.ChangeFeedResponse{}
+		require.NoError(t, json.NewDecoder(testUtil.Response.Body).Decode(actual))
+		assert.Len(t, actual.Changes, 2)
+		assert.Equal(t, "15", actual.NextToken)
+		assert.True(t, actual.HasMore)
+	})
+
+	t.Run("with no new changes", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetChangesAfter", mock.Anything, uint64(6), uint(defaultChangeFeedLimit+1)).
+			Return([]models.Change(nil), nil)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodGet, "/v1/changes?since=6", nil)
+		assert.NoError(t, err)
+
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusOK)
+		assert.JSONEq(t, `{"changes": [], "next_token": "6", "has_more": false}`, testUtil.Response.Body.String())
+	})
+
+	t.Run("with invalid token", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodGet, "/v1/changes?since=yesterday", nil)
+		assert.NoError(t, err)
+
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusBadRequest)
+	})
+
+	t.Run("with invalid limit", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodGet, "/v1/changes?limit=0", nil)
+		assert.NoError(t, err)
+
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusBadRequest)
+	})
+
+	t.Run("with error retrieving changes", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetChangesAfter", mock.Anything, uint64(0), uint(defaultChangeFeedLimit+1)).
+			Return([]models.Change(nil), generateArbitraryError())
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodGet, "/v1/changes", nil)
+		assert.NoError(t, err)
+
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+	})
+}
@@ -3,14 +3,15 @@ package api
 import (
 	"database/sql"
 	"fmt"
-	"net/http"
 	"plugin"
 	"strings"
+	"time"
 
 	"github.com/dairycart/dairycart/storage/v1/database"
 	"github.com/dairycart/dairycart/storage/v1/database/postgres"
 	"github.com/dairycart/dairycart/storage/v1/images"
 	"github.com/dairycart/dairycart/storage/v1/images/local"
+	"github.com/dairycart/dairycart/storage/v1/images/s3"
 
 	"github.com/dchest/uniuri"
 	"github.com/go-chi/chi"
@@ -23,6 +24,7 @@ const (
 	mandatorySecretLength       = 32
 	defaultPort                 = 4321
 	DefaultImageStorageProvider = "local"
+	S3ImageStorageProvider      = "s3"
 	DefaultDatabaseProvider     = "postgres"
 
 	// Config keys //
@@ -44,15 +46,71 @@ const (
 	imageStorageKey       = "image_storage"
 	imageStorageTypeKey   = "image_storage.type"
 	imageStoragePluginKey = "image_storage.plugin_path"
+
+	// uploads
+	maxUploadFileSizeKey = "uploads.max_file_size"
+	maxUploadFilesKey    = "uploads.max_files_per_request"
+	maxUploadPixelsKey   = "uploads.max_pixels"
+
+	// remote images
+	remoteImageTimeoutKey        = "remote_images.timeout"
+	remoteImageMaxSizeKey        = "remote_images.max_size"
+	remoteImageMaxRedirectsKey   = "remote_images.max_redirects"
+	remoteImageMaxPixelsKey      = "remote_images.max_pixels"
+	remoteImageAllowedDomainsKey = "remote_images.allowed_domains"
+
+	// webhook delivery
+	webhookWorkersKey          = "webhooks.workers"
+	webhookPollIntervalKey     = "webhooks.poll_interval"
+	webhookTimeoutKey          = "webhooks.timeout"
+	webhookMaxAttemptsKey      = "webhooks.max_attempts"
+	webhookInitialBackoffKey   = "webhooks.initial_backoff"
+	webhookMaxBackoffKey       = "webhooks.max_backoff"
+	webhookBreakerThresholdKey = "webhooks.breaker_threshold"
+	webhookBreakerCooldownKey  = "webhooks.breaker_cooldown"
+	webhookDisableThresholdKey = "webhooks.disable_threshold"
+	webhookMaxPerHostKey       = "webhooks.max_per_host"
+
+	// event stream
+	eventStreamPollIntervalKey      = "events.poll_interval"
+	eventStreamHeartbeatIntervalKey = "events.heartbeat_interval"
+
+	// orphaned image collection
+	imageCollectionIntervalKey = "image_collection.interval"
+	imageCollectionMinAgeKey   = "image_collection.min_age"
+	imageCollectionDryRunKey   = "image_collection.dry_run"
 )
 
+// OrphanedImageCollectionConfig decides whether the server collects orphaned images by itself, and how
+type OrphanedImageCollectionConfig struct {
+	// Interval is how often collection runs. Zero, the default, leaves it to the collect_orphaned_images command.
+	Interval time.Duration
+	Options  OrphanedImageCollectionOptions
+}
+
 type ServerConfig struct {
-	Router          *chi.Mux
-	DB              *sql.DB
-	CookieStore     *sessions.CookieStore
-	DatabaseClient  database.Storer
-	WebhookExecutor WebhookExecutor
-	ImageStorer     images.ImageStorer
+	Router            *chi.Mux
+	DB                *sql.DB
+	CookieStore       *sessions.CookieStore
+	DatabaseClient    database.Storer
+	WebhookDelivery   WebhookDeliverySettings
+	EventStream       EventStreamSettings
+	ImageStorer       images.ImageStorer
+	UploadLimits      UploadLimits
+	RemoteImageLimits RemoteImageLimits
+
+	// PasswordResetNotifier delivers password reset tokens to users. Tokens are logged when it's nil.
+	PasswordResetNotifier PasswordResetNotifier
+
+	OrphanedImageCollection OrphanedImageCollectionConfig
+}
+
+// MaxImagePixels is the most pixels an image accepted under this config could have, however it was provided
+func (c *ServerConfig) MaxImagePixels() int64 {
+	if c.RemoteImageLimits.maxPixels() > c.UploadLimits.maxPixels() {
+		return c.RemoteImageLimits.maxPixels()
+	}
+	return c.UploadLimits.maxPixels()
 }
 
 func loadPlugin(pluginPath string, symbolName string) (plugin.Symbol, error) {
@@ -100,6 +158,29 @@ func setConfigDefaults(config *viper.Viper, altCfgFilePath string) {
 	config.SetDefault(databaseTypeKey, DefaultDatabaseProvider)
 	config.SetDefault(imageStorageTypeKey, DefaultImageStorageProvider)
 
+	config.SetDefault(maxUploadFileSizeKey, defaultMaxUploadFileSize)
+	config.SetDefault(maxUploadFilesKey, defaultMaxUploadFiles)
+
+	config.SetDefault(remoteImageTimeoutKey, defaultRemoteImageTimeout)
+	config.SetDefault(remoteImageMaxSizeKey, defaultMaxUploadFileSize)
+	config.SetDefault(remoteImageMaxRedirectsKey, defaultRemoteImageMaxRedirects)
+
+	config.SetDefault(webhookWorkersKey, defaultWebhookWorkers)
+	config.SetDefault(webhookPollIntervalKey, defaultWebhookPollInterval)
+	config.SetDefault(webhookTimeoutKey, defaultWebhookTimeout)
+	config.SetDefault(webhookMaxAttemptsKey, defaultWebhookMaxAttempts)
+	config.SetDefault(webhookInitialBackoffKey, defaultWebhookInitialBackoff)
+	config.SetDefault(webhookMaxBackoffKey, defaultWebhookMaxBackoff)
+	config.SetDefault(webhookBreakerThresholdKey, defaultWebhookBreakerThreshold)
+	config.SetDefault(webhookBreakerCooldownKey, defaultWebhookBreakerCooldown)
+	config.SetDefault(webhookDisableThresholdKey, defaultWebhookDisableThreshold)
+	config.SetDefault(webhookMaxPerHostKey, defaultWebhookMaxPerHost)
+
+	config.SetDefault(eventStreamPollIntervalKey, defaultEventStreamPollInterval)
+	config.SetDefault(eventStreamHeartbeatIntervalKey, defaultEventStreamHeartbeatInterval)
+
+	config.SetDefault(imageCollectionMinAgeKey, defaultOrphanedImageMinAge)
+
 	// Secret stuff
 	config.BindEnv(secretKey, "DAIRYSECRET")
 	config.SetDefault(secretKey, uniuri.NewLen(mandatorySecretLength))
@@ -135,12 +216,46 @@ func BuildServerConfig(config *viper.Viper) (*ServerConfig, error) {
 	}
 
 	return &ServerConfig{
-		Router:          chi.NewMux(),
-		DB:              db,
-		CookieStore:     cookieStorer,
-		DatabaseClient:  dbClient,
-		WebhookExecutor: &webhookExecutor{Client: http.DefaultClient},
-		ImageStorer:     imageStorer,
+		Router:         chi.NewMux(),
+		DB:             db,
+		CookieStore:    cookieStorer,
+		DatabaseClient: dbClient,
+		WebhookDelivery: WebhookDeliverySettings{
+			Workers:          config.GetInt(webhookWorkersKey),
+			PollInterval:     config.GetDuration(webhookPollIntervalKey),
+			Timeout:          config.GetDuration(webhookTimeoutKey),
+			MaxAttempts:      config.GetInt(webhookMaxAttemptsKey),
+			InitialBackoff:   config.GetDuration(webhookInitialBackoffKey),
+			MaxBackoff:       config.GetDuration(webhookMaxBackoffKey),
+			BreakerThreshold: config.GetInt(webhookBreakerThresholdKey),
+			BreakerCooldown:  config.GetDuration(webhookBreakerCooldownKey),
+			DisableThreshold: config.GetInt(webhookDisableThresholdKey),
+			MaxPerHost:       config.GetInt(webhookMaxPerHostKey),
+		},
+		EventStream: EventStreamSettings{
+			PollInterval:      config.GetDuration(eventStreamPollIntervalKey),
+			HeartbeatInterval: config.GetDuration(eventStreamHeartbeatIntervalKey),
+		},
+		ImageStorer: imageStorer,
+		UploadLimits: UploadLimits{
+			MaxFileSize:        config.GetInt64(maxUploadFileSizeKey),
+			MaxFilesPerRequest: config.GetInt(maxUploadFilesKey),
+			MaxPixels:          config.GetInt64(maxUploadPixelsKey),
+		},
+		RemoteImageLimits: RemoteImageLimits{
+			Timeout:        config.GetDuration(remoteImageTimeoutKey),
+			MaxSize:        config.GetInt64(remoteImageMaxSizeKey),
+			MaxRedirects:   config.GetInt(remoteImageMaxRedirectsKey),
+			MaxPixels:      config.GetInt64(remoteImageMaxPixelsKey),
+			AllowedDomains: config.GetStringSlice(remoteImageAllowedDomainsKey),
+		},
+		OrphanedImageCollection: OrphanedImageCollectionConfig{
+			Interval: config.GetDuration(imageCollectionIntervalKey),
+			Options: OrphanedImageCollectionOptions{
+				DryRun: config.GetBool(imageCollectionDryRunKey),
+				MinAge: config.GetDuration(imageCollectionMinAgeKey),
+			},
+		},
 	}, nil
 }
 
@@ -202,9 +317,12 @@ func buildImageStorerFromConfig(cfg *viper.Viper) (images.ImageStorer, error) {
 	}
 
 	storageType := cfg.GetString(imageStorageTypeKey)
-	if storageType == DefaultImageStorageProvider {
+	switch storageType {
+	case DefaultImageStorageProvider:
 		storer = local.NewLocalImageStorer()
-	} else {
+	case S3ImageStorageProvider:
+		storer = s3.NewS3ImageStorer()
+	default:
 		missingPluginErr := errors.New("non-default image storer selected without complimentary plugin path, please check your configuration file")
 		if !cfg.IsSet(imageStoragePluginKey) {
 			return nil, missingPluginErr
@@ -233,16 +351,22 @@ func loadImageStoragePlugin(pluginPath string, name string) (images.ImageStorer,
 	return imgSym.(images.ImageStorer), nil
 }
 
+// imageRouteMountPath returns where the routes an image storer registers are served from
+func imageRouteMountPath(storer images.ImageStorer) string {
+	if m, ok := storer.(images.RouteMounter); ok {
+		return m.RouteMountPath()
+	}
+	return images.DefaultRouteMountPath
+}
+
 // InitializeServerComponents calls Init on all the relevant server components, and migrates the database.
 func InitializeServerComponents(cfg *viper.Viper, config *ServerConfig) error {
-	var err error
-	config.Router.Route("/product_images", func(r chi.Router) {
-		imageConfig := cfg.Sub(imageStorageKey)
-		err = config.ImageStorer.Init(imageConfig, r)
-	})
+	imageRouter := chi.NewRouter()
+	err := config.ImageStorer.Init(cfg.Sub(imageStorageKey), imageRouter)
 	if err != nil {
-		return errors.Wrap(err, "error migrating database")
+		return errors.Wrap(err, "error initializing image storage")
 	}
+	config.Router.Mount(imageRouteMountPath(config.ImageStorer), imageRouter)
 
 	dbConfig := cfg.Sub(databaseKey)
 	err = config.DatabaseClient.Migrate(config.DB, dbConfig)
@@ -8,7 +8,9 @@ import (
 	"testing"
 
 	"github.com/dairycart/dairycart/storage/v1/database/mock"
+	"github.com/dairycart/dairycart/storage/v1/images"
 	"github.com/dairycart/dairycart/storage/v1/images/mock"
+	"github.com/dairycart/dairycart/storage/v1/images/s3"
 
 	"github.com/dchest/uniuri"
 	"github.com/go-chi/chi"
@@ -324,6 +326,17 @@ func TestBuildImageStorerFromConfig(t *testing.T) {
 		assert.NoError(_t, err)
 	})
 
+	t.Run("with s3 storage", func(_t *testing.T) {
+		_t.Parallel()
+
+		cfg := viper.New()
+		cfg.Set(imageStorageTypeKey, S3ImageStorageProvider)
+
+		actual, err := buildImageStorerFromConfig(cfg)
+		assert.NoError(_t, err)
+		assert.NotNil(_t, actual)
+	})
+
 	t.Run("with missing storage key", func(_t *testing.T) {
 		_t.Parallel()
 
@@ -395,6 +408,16 @@ func TestLoadImageStoragePlugin(t *testing.T) {
 	})
 }
 
+func TestImageRouteMountPath(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, images.DefaultRouteMountPath, imageRouteMountPath(&imgmock.MockImageStorer{}))
+
+	s := s3.NewS3ImageStorer()
+	s.RoutePrefix = "images"
+	assert.Equal(t, "/images", imageRouteMountPath(s))
+}
+
 func TestInitializeServerComponents(t *testing.T) {
 	t.Parallel()
 
@@ -71,12 +71,32 @@ func buildDiscountCreationHandler(db *sql.DB, client database.Storer) http.Handl
 			return
 		}
 
-		newDiscount.ID, newDiscount.CreatedOn, err = client.CreateDiscount(db, newDiscount)
+		tx, err := db.Begin()
 		if err != nil {
+			notifyOfInternalIssue(res, err, "create new database transaction")
+			return
+		}
+
+		newDiscount.ID, newDiscount.CreatedOn, err = client.CreateDiscount(tx, newDiscount)
+		if err != nil {
+			tx.Rollback()
 			notifyOfInternalIssue(res, err, "insert discount into database")
 			return
 		}
 
+		err = enqueueWebhookDeliveries(tx, client, DiscountCreatedWebhookEvent, newDiscount)
+		if err != nil {
+			tx.Rollback()
+			notifyOfInternalIssue(res, err, "queue webhook deliveries")
+			return
+		}
+
+		err = tx.Commit()
+		if err != nil {
+			notifyOfInternalIssue(res, err, "close out transaction")
+			return
+		}
+
 		res.WriteHeader(http.StatusCreated)
 		json.NewEncoder(res).Encode(newDiscount)
 	}
@@ -98,13 +118,33 @@ func buildDiscountDeletionHandler(db *sql.DB, client database.Storer) http.Handl
 			return
 		}
 
-		archivedOn, err := client.DeleteDiscount(db, discountID)
+		tx, err := db.Begin()
 		if err != nil {
+			notifyOfInternalIssue(res, err, "create new database transaction")
+			return
+		}
+
+		archivedOn, err := client.DeleteDiscount(tx, discountID)
+		if err != nil {
+			tx.Rollback()
 			notifyOfInternalIssue(res, err, "archive discount in database")
 			return
 		}
 		discount.ArchivedOn = &models.Dairytime{Time: archivedOn}
 
+		err = enqueueWebhookDeliveries(tx, client, DiscountArchivedWebhookEvent, discount)
+		if err != nil {
+			tx.Rollback()
+			notifyOfInternalIssue(res, err, "queue webhook deliveries")
+			return
+		}
+
+		err = tx.Commit()
+		if err != nil {
+			notifyOfInternalIssue(res, err, "close out transaction")
+			return
+		}
+
 		json.NewEncoder(res).Encode(discount)
 	}
 }
@@ -133,14 +173,39 @@ func buildDiscountUpdateHandler(db *sql.DB, client database.Storer) http.Handler
 		}
 
 		mergo.Merge(updatedDiscount, existingDiscount)
+		// mergo doesn't merge time.Time fields, so without this they'd look like they changed to the zero time
+		if updatedDiscount.StartsOn.IsZero() {
+			updatedDiscount.StartsOn = existingDiscount.StartsOn
+		}
+		updatedDiscount.CreatedOn = existingDiscount.CreatedOn
+
+		tx, err := db.Begin()
+		if err != nil {
+			notifyOfInternalIssue(res, err, "create new database transaction")
+			return
+		}
 
-		updatedOn, err := client.UpdateDiscount(db, updatedDiscount)
+		updatedOn, err := client.UpdateDiscount(tx, updatedDiscount)
 		if err != nil {
+			tx.Rollback()
 			notifyOfInternalIssue(res, err, "update discount in database")
 			return
 		}
 		updatedDiscount.UpdatedOn = &models.Dairytime{Time: updatedOn}
 
+		err = enqueueWebhookChangeDeliveries(tx, client, DiscountUpdatedWebhookEvent, existingDiscount, updatedDiscount)
+		if err != nil {
+			tx.Rollback()
+			notifyOfInternalIssue(res, err, "queue webhook deliveries")
+			return
+		}
+
+		err = tx.Commit()
+		if err != nil {
+			notifyOfInternalIssue(res, err, "close out transaction")
+			return
+		}
+
 		json.NewEncoder(res).Encode(updatedDiscount)
 	}
 }
@@ -38,6 +38,7 @@ func TestDiscountRetrievalHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodGet, "/v1/discount/1", nil)
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusOK)
 	})
@@ -52,6 +53,7 @@ func TestDiscountRetrievalHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodGet, "/v1/discount/1", nil)
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusNotFound)
 	})
@@ -66,6 +68,7 @@ func TestDiscountRetrievalHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodGet, "/v1/discount/1", nil)
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusInternalServerError)
 	})
@@ -92,6 +95,7 @@ func TestDiscountListHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodGet, "/v1/discounts", nil)
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusOK)
 	})
@@ -106,6 +110,7 @@ func TestDiscountListHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodGet, "/v1/discounts", nil)
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusInternalServerError)
 	})
@@ -122,6 +127,7 @@ func TestDiscountListHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodGet, "/v1/discounts", nil)
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusInternalServerError)
 	})
@@ -141,16 +147,23 @@ func TestDiscountCreationHandler(t *testing.T) {
 
 	t.Run("optimal conditions", func(*testing.T) {
 		testUtil := setupTestVariablesWithMock(t)
+		testUtil.Mock.ExpectBegin()
+		testUtil.Mock.ExpectCommit()
 		testUtil.MockDB.On("CreateDiscount", mock.Anything, mock.Anything).
 			Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, DiscountCreatedWebhookEvent).
+			Return([]models.Webhook{}, nil)
 		config := buildServerConfigFromTestUtil(testUtil)
 		SetupAPIRouter(config)
 
 		req, err := http.NewRequest(http.MethodPost, "/v1/discount", strings.NewReader(exampleDiscountCreationInput))
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusCreated)
+		ensureExpectationsWereMet(t, testUtil.Mock)
 	})
 
 	t.Run("with invalid input", func(*testing.T) {
@@ -161,12 +174,15 @@ func TestDiscountCreationHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodPost, "/v1/discount", strings.NewReader(exampleGarbageInput))
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusBadRequest)
 	})
 
 	t.Run("with error creating discount", func(*testing.T) {
 		testUtil := setupTestVariablesWithMock(t)
+		testUtil.Mock.ExpectBegin()
+		testUtil.Mock.ExpectRollback()
 		testUtil.MockDB.On("CreateDiscount", mock.Anything, mock.Anything).
 			Return(uint64(1), buildTestTime(), generateArbitraryError())
 		config := buildServerConfigFromTestUtil(testUtil)
@@ -175,8 +191,31 @@ func TestDiscountCreationHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodPost, "/v1/discount", strings.NewReader(exampleDiscountCreationInput))
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+		ensureExpectationsWereMet(t, testUtil.Mock)
+	})
+
+	t.Run("with error queueing webhook deliveries", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.Mock.ExpectBegin()
+		testUtil.Mock.ExpectRollback()
+		testUtil.MockDB.On("CreateDiscount", mock.Anything, mock.Anything).
+			Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, DiscountCreatedWebhookEvent).
+			Return([]models.Webhook{}, generateArbitraryError())
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPost, "/v1/discount", strings.NewReader(exampleDiscountCreationInput))
+		assert.NoError(t, err)
+
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+		ensureExpectationsWereMet(t, testUtil.Mock)
 	})
 }
 
@@ -191,18 +230,25 @@ func TestDiscountDeletionHandler(t *testing.T) {
 
 	t.Run("optimal conditions", func(*testing.T) {
 		testUtil := setupTestVariablesWithMock(t)
+		testUtil.Mock.ExpectBegin()
+		testUtil.Mock.ExpectCommit()
 		testUtil.MockDB.On("GetDiscount", mock.Anything, exampleDiscount.ID).
 			Return(exampleDiscount, nil)
 		testUtil.MockDB.On("DeleteDiscount", mock.Anything, exampleDiscount.ID).
 			Return(buildTestTime(), nil)
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, DiscountArchivedWebhookEvent).
+			Return([]models.Webhook{}, nil)
 		config := buildServerConfigFromTestUtil(testUtil)
 		SetupAPIRouter(config)
 
 		req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("/v1/discount/%d", exampleDiscount.ID), nil)
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusOK)
+		ensureExpectationsWereMet(t, testUtil.Mock)
 	})
 
 	t.Run("with nonexistent discount", func(*testing.T) {
@@ -215,6 +261,7 @@ func TestDiscountDeletionHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("/v1/discount/%d", exampleDiscount.ID), nil)
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusNotFound)
 	})
@@ -229,12 +276,15 @@ func TestDiscountDeletionHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("/v1/discount/%d", exampleDiscount.ID), nil)
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusInternalServerError)
 	})
 
 	t.Run("with error deleting discount", func(*testing.T) {
 		testUtil := setupTestVariablesWithMock(t)
+		testUtil.Mock.ExpectBegin()
+		testUtil.Mock.ExpectRollback()
 		testUtil.MockDB.On("GetDiscount", mock.Anything, exampleDiscount.ID).
 			Return(exampleDiscount, nil)
 		testUtil.MockDB.On("DeleteDiscount", mock.Anything, exampleDiscount.ID).
@@ -245,8 +295,33 @@ func TestDiscountDeletionHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("/v1/discount/%d", exampleDiscount.ID), nil)
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+		ensureExpectationsWereMet(t, testUtil.Mock)
+	})
+
+	t.Run("with error queueing webhook deliveries", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.Mock.ExpectBegin()
+		testUtil.Mock.ExpectRollback()
+		testUtil.MockDB.On("GetDiscount", mock.Anything, exampleDiscount.ID).
+			Return(exampleDiscount, nil)
+		testUtil.MockDB.On("DeleteDiscount", mock.Anything, exampleDiscount.ID).
+			Return(buildTestTime(), nil)
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, DiscountArchivedWebhookEvent).
+			Return([]models.Webhook{}, generateArbitraryError())
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("/v1/discount/%d", exampleDiscount.ID), nil)
+		assert.NoError(t, err)
+
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+		ensureExpectationsWereMet(t, testUtil.Mock)
 	})
 }
 
@@ -269,18 +344,56 @@ func TestDiscountUpdateHandler(t *testing.T) {
 
 	t.Run("optimal conditions", func(*testing.T) {
 		testUtil := setupTestVariablesWithMock(t)
+		testUtil.Mock.ExpectBegin()
+		testUtil.Mock.ExpectCommit()
 		testUtil.MockDB.On("GetDiscount", mock.Anything, exampleDiscount.ID).
 			Return(exampleDiscount, nil)
 		testUtil.MockDB.On("UpdateDiscount", mock.Anything, mock.Anything).
 			Return(buildTestTime(), nil)
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, DiscountUpdatedWebhookEvent).
+			Return([]models.Webhook{}, nil)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPatch, "/v1/discount/1", strings.NewReader(exampleDiscountUpdateInput))
+		assert.NoError(t, err)
+
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusOK)
+		ensureExpectationsWereMet(t, testUtil.Mock)
+	})
+
+	t.Run("reports only the fields that changed", func(t *testing.T) {
+		existingDiscount := *exampleDiscount
+		existingDiscount.StartsOn = buildTestTime()
+		existingDiscount.CreatedOn = buildTestTime()
+
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.Mock.ExpectBegin()
+		testUtil.Mock.ExpectCommit()
+		testUtil.MockDB.On("GetDiscount", mock.Anything, exampleDiscount.ID).
+			Return(&existingDiscount, nil)
+		testUtil.MockDB.On("UpdateDiscount", mock.Anything, mock.Anything).
+			Return(buildTestTime(), nil)
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, DiscountUpdatedWebhookEvent).
+			Return([]models.Webhook{{URL: "https://dairycart.com", ContentType: "application/json"}}, nil)
+		testUtil.MockDB.On("CreateWebhookDelivery", mock.Anything, mock.MatchedBy(func(d *models.WebhookDelivery) bool {
+			return strings.Contains(d.Payload, `"previous":{"code":"example","name":"example","requires_code":false}`) &&
+				strings.Contains(d.Payload, `"changed_fields":["code","name","requires_code"]`)
+		})).Return(uint64(1), buildTestTime(), nil)
 		config := buildServerConfigFromTestUtil(testUtil)
 		SetupAPIRouter(config)
 
 		req, err := http.NewRequest(http.MethodPatch, "/v1/discount/1", strings.NewReader(exampleDiscountUpdateInput))
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusOK)
+		ensureExpectationsWereMet(t, testUtil.Mock)
 	})
 
 	t.Run("with invalid input", func(*testing.T) {
@@ -291,6 +404,7 @@ func TestDiscountUpdateHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodPatch, "/v1/discount/1", strings.NewReader(exampleGarbageInput))
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusBadRequest)
 	})
@@ -305,6 +419,7 @@ func TestDiscountUpdateHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodPatch, "/v1/discount/1", strings.NewReader(exampleDiscountUpdateInput))
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusNotFound)
 	})
@@ -319,12 +434,15 @@ func TestDiscountUpdateHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodPatch, "/v1/discount/1", strings.NewReader(exampleDiscountUpdateInput))
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusInternalServerError)
 	})
 
 	t.Run("with error updating discount", func(*testing.T) {
 		testUtil := setupTestVariablesWithMock(t)
+		testUtil.Mock.ExpectBegin()
+		testUtil.Mock.ExpectRollback()
 		testUtil.MockDB.On("GetDiscount", mock.Anything, exampleDiscount.ID).
 			Return(exampleDiscount, nil)
 		testUtil.MockDB.On("UpdateDiscount", mock.Anything, mock.Anything).
@@ -335,7 +453,32 @@ func TestDiscountUpdateHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodPatch, "/v1/discount/1", strings.NewReader(exampleDiscountUpdateInput))
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+		ensureExpectationsWereMet(t, testUtil.Mock)
+	})
+
+	t.Run("with error queueing webhook deliveries", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.Mock.ExpectBegin()
+		testUtil.Mock.ExpectRollback()
+		testUtil.MockDB.On("GetDiscount", mock.Anything, exampleDiscount.ID).
+			Return(exampleDiscount, nil)
+		testUtil.MockDB.On("UpdateDiscount", mock.Anything, mock.Anything).
+			Return(buildTestTime(), nil)
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, DiscountUpdatedWebhookEvent).
+			Return([]models.Webhook{}, generateArbitraryError())
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPatch, "/v1/discount/1", strings.NewReader(exampleDiscountUpdateInput))
+		assert.NoError(t, err)
+
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+		ensureExpectationsWereMet(t, testUtil.Mock)
 	})
 }
@@ -0,0 +1,175 @@
+package api
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dairycart/dairycart/models/v1"
+	"github.com/dairycart/dairycart/storage/v1/database"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultEventStreamPollInterval      = time.Second
+	defaultEventStreamHeartbeatInterval = 15 * time.Second
+
+	// eventStreamBatchSize is how many events are read from the event log at a time
+	eventStreamBatchSize = 100
+
+	lastEventIDHeader        = "Last-Event-ID"
+	lastEventIDQueryKey      = "last_event_id"
+	eventStreamTypesQueryKey = "event_types"
+)
+
+// EventStreamSettings control how the event stream follows the event log
+type EventStreamSettings struct {
+	// PollInterval is how long the stream waits between looking for new events, when it's caught up
+	PollInterval time.Duration
+	// HeartbeatInterval is how often a comment is sent to keep quiet connections from being closed by proxies
+	HeartbeatInterval time.Duration
+}
+
+// eventStreamTypes reads the event types a stream client asked for, which can be given as a comma separated list,
+// by repeating the parameter, or both. No event types means every event.
+func eventStreamTypes(req *http.Request) ([]string, error) {
+	var eventTypes []string
+	for _, value := range req.URL.Query()[eventStreamTypesQueryKey] {
+		for _, eventType := range strings.Split(value, ",") {
+			eventType = strings.TrimSpace(eventType)
+			if eventType == "" {
+				continue
+			}
+			if !validWebhookEventType(eventType) {
+				return nil, fmt.Errorf("invalid event type: '%s'", eventType)
+			}
+			eventTypes = append(eventTypes, eventType)
+		}
+	}
+	return eventTypes, nil
+}
+
+// eventStreamPosition works out the position of the event a stream client last saw. Browsers send the Last-Event-ID header when
+// they reconnect, and clients that can't set headers can use the last_event_id query parameter instead. ok is false
+// if the client didn't say, in which case the stream starts with the next event.
+func eventStreamPosition(req *http.Request) (position uint64, ok bool, err error) {
+	raw := req.Header.Get(lastEventIDHeader)
+	if raw == "" {
+		raw = req.URL.Query().Get(lastEventIDQueryKey)
+	}
+	if raw == "" {
+		return 0, false, nil
+	}
+
+	position, err = strconv.ParseUint(raw, 10, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid last event id: '%s'", raw)
+	}
+	return position, true, nil
+}
+
+// writeServerSentEvent writes an event in the text/event-stream format. Its id is the event's position, which is
+// what the client sends back as Last-Event-ID when it reconnects.
+func writeServerSentEvent(res http.ResponseWriter, e models.Event) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "id: %d\nevent: %s\n", e.Position, e.EventType)
+	for _, line := range strings.Split(e.Payload, "\n") {
+		fmt.Fprintf(&b, "data: %s\n", line)
+	}
+	b.WriteString("\n")
+
+	_, err := res.Write([]byte(b.String()))
+	return err
+}
+
+func buildEventStreamHandler(db database.Querier, client database.Storer, settings EventStreamSettings) http.HandlerFunc {
+	if settings.PollInterval <= 0 {
+		settings.PollInterval = defaultEventStreamPollInterval
+	}
+	if settings.HeartbeatInterval <= 0 {
+		settings.HeartbeatInterval = defaultEventStreamHeartbeatInterval
+	}
+
+	return func(res http.ResponseWriter, req *http.Request) {
+		flusher, ok := res.(http.Flusher)
+		if !ok {
+			notifyOfInternalIssue(res, errors.New("response writer can't be flushed"), "stream events")
+			return
+		}
+
+		eventTypes, err := eventStreamTypes(req)
+		if err != nil {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
+
+		lastPosition, resuming, err := eventStreamPosition(req)
+		if err != nil {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
+		if !resuming {
+			lastPosition, err = client.GetLatestEventPosition(db)
+			if err != nil {
+				notifyOfInternalIssue(res, err, "retrieve latest event")
+				return
+			}
+		}
+
+		res.Header().Set("Content-Type", "text/event-stream")
+		res.Header().Set("Cache-Control", "no-cache")
+		res.Header().Set("Connection", "keep-alive")
+		// stops nginx from buffering the stream
+		res.Header().Set("X-Accel-Buffering", "no")
+		res.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		poll := time.NewTicker(settings.PollInterval)
+		defer poll.Stop()
+		heartbeat := time.NewTicker(settings.HeartbeatInterval)
+		defer heartbeat.Stop()
+
+		for {
+			events, err := client.GetEventsAfter(db, lastPosition, eventTypes, eventStreamBatchSize)
+			if err != nil {
+				// the client reconnects with the last id it saw, so it won't miss anything
+				log.Printf("error retrieving events after %d: %v\n", lastPosition, err)
+				return
+			}
+			for _, e := range events {
+				if err = writeServerSentEvent(res, e); err != nil {
+					return
+				}
+				lastPosition = e.Position
+			}
+			if len(events) > 0 {
+				flusher.Flush()
+			}
+
+			// a full batch means there are probably more waiting
+			if len(events) == eventStreamBatchSize {
+				select {
+				case <-req.Context().Done():
+					return
+				default:
+					continue
+				}
+			}
+
+			select {
+			case <-req.Context().Done():
+				return
+			case <-heartbeat.C:
+				if _, err = res.Write([]byte(": heartbeat\n\n")); err != nil {
+					return
+				}
+				flusher.Flush()
+			case <-poll.C:
+			}
+		}
+	}
+}
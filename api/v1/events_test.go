@@ -0,0 +1,205 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dairycart/dairycart/models/v1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteServerSentEvent(t *testing.T) {
+	t.Parallel()
+
+	testUtil := setupTestVariablesWithMock(t)
+	require.NoError(t, writeServerSentEvent(testUtil.Response, models.Event{ID: 7, Position: 1, EventType: ProductCreatedWebhookEvent, Payload: "one\ntwo"}))
+	assert.Equal(t, "id: 1\nevent: product.created\ndata: one\ndata: two\n\n", testUtil.Response.Body.String())
+}
+
+////////////////////////////////////////////////////////
+//                                                    //
+//                 HTTP Handler Tests                 //
+//                                                    //
+////////////////////////////////////////////////////////
+
+func buildEventStreamRequest(t *testing.T, testUtil *TestUtil, url string) (*http.Request, context.CancelFunc) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+
+	cookie, err := buildCookieForRequest(t, testUtil, true, true)
+	require.NoError(t, err)
+	req.AddCookie(cookie)
+
+	return req.WithContext(ctx), cancel
+}
+
+func setupEventStreamRouter(testUtil *TestUtil, settings EventStreamSettings) {
+	config := buildServerConfigFromTestUtil(testUtil)
+	config.EventStream = settings
+	SetupAPIRouter(config)
+}
+
+func TestEventStreamHandler(t *testing.T) {
+	exampleEvents := []models.Event{
+		{ID: 5, Position: 4, EventType: ProductCreatedWebhookEvent, Payload: `{"event":"product.created"}`},
+		{ID: 4, Position: 5, EventType: ProductUpdatedWebhookEvent, Payload: `{"event":"product.updated"}`},
+	}
+	quickPolling := EventStreamSettings{PollInterval: time.Millisecond, HeartbeatInterval: time.Hour}
+
+	t.Run("optimal conditions", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		req, cancel := buildEventStreamRequest(t, testUtil, "/v1/events/stream?event_types=product.*")
+		defer cancel()
+
+		testUtil.MockDB.On("GetLatestEventPosition", mock.Anything).Return(uint64(3), nil)
+		testUtil.MockDB.On("GetEventsAfter", mock.Anything, uint64(3), []string{"product.*"}, uint(eventStreamBatchSize)).
+			Return(exampleEvents, nil).Once()
+		// once it's caught up, the client goes away
+		testUtil.MockDB.On("GetEventsAfter", mock.Anything, uint64(5), []string{"product.*"}, uint(eventStreamBatchSize)).
+			Run(func(mock.Arguments) { cancel() }).
+			Return([]models.Event{}, nil)
+		setupEventStreamRouter(testUtil, quickPolling)
+
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusOK)
+		assert.Equal(t, "text/event-stream", testUtil.Response.Header().Get("Content-Type"))
+		assert.Equal(t, "no-cache", testUtil.Response.Header().Get("Cache-Control"))
+
+		expected := "id: 4\nevent: product.created\ndata: {\"event\":\"product.created\"}\n\n" +
+			"id: 5\nevent: product.updated\ndata: {\"event\":\"product.updated\"}\n\n"
+		assert.Equal(t, expected, testUtil.Response.Body.String())
+	})
+
+	t.Run("resuming with the last event id header", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		req, cancel := buildEventStreamRequest(t, testUtil, "/v1/events/stream")
+		defer cancel()
+		req.Header.Set("Last-Event-ID", "1")
+
+		testUtil.MockDB.On("GetEventsAfter", mock.Anything, uint64(1), []string(nil), uint(eventStreamBatchSize)).
+			Run(func(mock.Arguments) { cancel() }).
+			Return(exampleEvents, nil)
+		setupEventStreamRouter(testUtil, quickPolling)
+
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusOK)
+		assert.Contains(t, testUtil.Response.Body.String(), "id: 4\n")
+		testUtil.MockDB.AssertNotCalled(t, "GetLatestEventPosition", mock.Anything)
+	})
+
+	t.Run("resuming with the last event id parameter", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		req, cancel := buildEventStreamRequest(t, testUtil, "/v1/events/stream?last_event_id=2&event_types=product.created,discount.*")
+		defer cancel()
+
+		testUtil.MockDB.On("GetEventsAfter", mock.Anything, uint64(2), []string{ProductCreatedWebhookEvent, "discount.*"}, uint(eventStreamBatchSize)).
+			Run(func(mock.Arguments) { cancel() }).
+			Return([]models.Event{}, nil)
+		setupEventStreamRouter(testUtil, quickPolling)
+
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusOK)
+		testUtil.MockDB.AssertNotCalled(t, "GetLatestEventPosition", mock.Anything)
+	})
+
+	t.Run("with heartbeats", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		req, cancel := buildEventStreamRequest(t, testUtil, "/v1/events/stream?last_event_id=5")
+		defer cancel()
+
+		testUtil.MockDB.On("GetEventsAfter", mock.Anything, uint64(5), []string(nil), uint(eventStreamBatchSize)).
+			Return([]models.Event{}, nil).Once()
+		// the stream looks for events again after each heartbeat
+		testUtil.MockDB.On("GetEventsAfter", mock.Anything, uint64(5), []string(nil), uint(eventStreamBatchSize)).
+			Run(func(mock.Arguments) { cancel() }).
+			Return([]models.Event{}, nil)
+		setupEventStreamRouter(testUtil, EventStreamSettings{PollInterval: time.Hour, HeartbeatInterval: time.Millisecond})
+
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusOK)
+		assert.True(t, strings.HasPrefix(testUtil.Response.Body.String(), ": heartbeat\n\n"))
+	})
+
+	t.Run("without authorization", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		setupEventStreamRouter(testUtil, quickPolling)
+
+		req, err := http.NewRequest(http.MethodGet, "/v1/events/stream", nil)
+		require.NoError(t, err)
+
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusUnauthorized)
+	})
+
+	t.Run("without permission", func(t *testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetPermissionsByUserID", mock.Anything, mock.Anything).Return([]string{}, nil)
+		setupEventStreamRouter(testUtil, quickPolling)
+
+		req, err := http.NewRequest(http.MethodGet, "/v1/events/stream", nil)
+		require.NoError(t, err)
+		cookie, err := buildCookieForRequest(t, testUtil, true, false)
+		require.NoError(t, err)
+		req.AddCookie(cookie)
+
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusForbidden)
+	})
+
+	t.Run("with invalid event type", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		req, cancel := buildEventStreamRequest(t, testUtil, "/v1/events/stream?event_types=product.created,nonsense.*")
+		defer cancel()
+		setupEventStreamRouter(testUtil, quickPolling)
+
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusBadRequest)
+		assert.Contains(t, testUtil.Response.Body.String(), "nonsense.*")
+	})
+
+	t.Run("with invalid last event id", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		req, cancel := buildEventStreamRequest(t, testUtil, "/v1/events/stream")
+		defer cancel()
+		req.Header.Set("Last-Event-ID", "banana")
+		setupEventStreamRouter(testUtil, quickPolling)
+
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusBadRequest)
+	})
+
+	t.Run("with error retrieving latest event", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		req, cancel := buildEventStreamRequest(t, testUtil, "/v1/events/stream")
+		defer cancel()
+
+		testUtil.MockDB.On("GetLatestEventPosition", mock.Anything).Return(uint64(0), generateArbitraryError())
+		setupEventStreamRouter(testUtil, quickPolling)
+
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+	})
+
+	t.Run("with error retrieving events", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		req, cancel := buildEventStreamRequest(t, testUtil, "/v1/events/stream?last_event_id=5")
+		defer cancel()
+
+		testUtil.MockDB.On("GetEventsAfter", mock.Anything, uint64(5), []string(nil), uint(eventStreamBatchSize)).
+			Return([]models.Event{}, generateArbitraryError())
+		setupEventStreamRouter(testUtil, quickPolling)
+
+		// the stream has already started, so it just ends, and the client reconnects
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusOK)
+		assert.Empty(t, testUtil.Response.Body.String())
+	})
+}
@@ -0,0 +1,227 @@
+package api
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/dairycart/dairycart/storage/v1/images"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultRemoteImageTimeout      = 10 * time.Second
+	defaultRemoteImageMaxRedirects = 3
+)
+
+var (
+	// deniedNetworks are the ranges we won't fetch images from, so URLs can't be used to poke at our own network
+	deniedNetworks = mustParseCIDRs(
+		"0.0.0.0/8",      // "this" network
+		"10.0.0.0/8",     // private
+		"100.64.0.0/10",  // carrier-grade NAT
+		"127.0.0.0/8",    // loopback
+		"169.254.0.0/16", // link-local, which includes cloud metadata services
+		"172.16.0.0/12",  // private
+		"192.0.0.0/24",   // IETF protocol assignments
+		"192.168.0.0/16", // private
+		"198.18.0.0/15",  // benchmarking
+		"224.0.0.0/4",    // multicast
+		"240.0.0.0/4",    // reserved, and broadcast
+		"::/128",         // unspecified
+		"::1/128",        // loopback
+		"64:ff9b::/96",   // IPv4/IPv6 translation
+		"2001::/32",      // Teredo, which tunnels to IPv4 addresses
+		"2002::/16",      // 6to4, which embeds IPv4 addresses, private ones included
+		"fc00::/7",       // unique local
+		"fe80::/10",      // link-local
+		"ff00::/8",       // multicast
+	)
+
+	errDeniedAddress = errors.New("requests can't be made to private or reserved addresses")
+)
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	var out []*net.IPNet
+	for _, cidr := range cidrs {
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		out = append(out, n)
+	}
+	return out
+}
+
+func isDeniedIP(ip net.IP) bool {
+	if v4 := ip.To4(); v4 != nil {
+		ip = v4
+	}
+	for _, n := range deniedNetworks {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// newRestrictedDialer builds a dialer that won't connect to any of the deniedNetworks. Addresses are checked
+// when connections are made, after DNS resolution, so neither redirects nor DNS tricks can get around it.
+func newRestrictedDialer(timeout time.Duration, allowPrivateNetworks bool) *net.Dialer {
+	return &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			if allowPrivateNetworks {
+				return nil
+			}
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || isDeniedIP(ip) {
+				return errDeniedAddress
+			}
+			return nil
+		},
+	}
+}
+
+// RemoteImageLimits restricts the images fetched for `url` image inputs
+type RemoteImageLimits struct {
+	Timeout      time.Duration
+	MaxSize      int64
+	MaxRedirects int
+	MaxPixels    int64
+	// AllowedDomains, when not empty, are the only domains (and their subdomains) images can be fetched from
+	AllowedDomains []string
+
+	// allowPrivateNetworks turns off the address checks, so tests can fetch from servers on localhost
+	allowPrivateNetworks bool
+}
+
+func (l RemoteImageLimits) timeout() time.Duration {
+	if l.Timeout <= 0 {
+		return defaultRemoteImageTimeout
+	}
+	return l.Timeout
+}
+
+func (l RemoteImageLimits) maxSize() int64 {
+	if l.MaxSize <= 0 {
+		return defaultMaxUploadFileSize
+	}
+	return l.MaxSize
+}
+
+func (l RemoteImageLimits) maxPixels() int64 {
+	if l.MaxPixels <= 0 {
+		return images.DefaultMaxPixels
+	}
+	return l.MaxPixels
+}
+
+func (l RemoteImageLimits) maxRedirects() int {
+	if l.MaxRedirects < 0 {
+		return 0
+	} else if l.MaxRedirects == 0 {
+		return defaultRemoteImageMaxRedirects
+	}
+	return l.MaxRedirects
+}
+
+func (l RemoteImageLimits) domainAllowed(host string) bool {
+	if len(l.AllowedDomains) == 0 {
+		return true
+	}
+
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	for _, domain := range l.AllowedDomains {
+		domain = strings.TrimSuffix(strings.ToLower(domain), ".")
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+func (l RemoteImageLimits) validateURL(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("unsupported url scheme: %s", u.Scheme)
+	}
+	if u.Hostname() == "" {
+		return errors.New("url has no host")
+	}
+	if !l.domainAllowed(u.Hostname()) {
+		return fmt.Errorf("images can't be fetched from %s", u.Hostname())
+	}
+	return nil
+}
+
+// imageFetcher downloads images from user-supplied URLs. It connects through a restricted dialer,
+// so neither redirects nor DNS tricks can point it somewhere it shouldn't go.
+type imageFetcher struct {
+	limits RemoteImageLimits
+	client *http.Client
+}
+
+func newImageFetcher(limits RemoteImageLimits) *imageFetcher {
+	dialer := newRestrictedDialer(limits.timeout(), limits.allowPrivateNetworks)
+
+	return &imageFetcher{
+		limits: limits,
+		client: &http.Client{
+			Timeout: limits.timeout(),
+			Transport: &http.Transport{
+				// no proxy, since the proxy would be the one doing the connecting
+				DialContext:           dialer.DialContext,
+				TLSHandshakeTimeout:   limits.timeout(),
+				ResponseHeaderTimeout: limits.timeout(),
+				MaxIdleConns:          10,
+				IdleConnTimeout:       90 * time.Second,
+			},
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				if len(via) > limits.maxRedirects() {
+					return fmt.Errorf("stopped after %d redirects", limits.maxRedirects())
+				}
+				return limits.validateURL(req.URL)
+			},
+		},
+	}
+}
+
+// Fetch downloads and decodes the image at rawURL
+func (f *imageFetcher) Fetch(rawURL string) (image.Image, string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "invalid url")
+	}
+	if err = f.limits.validateURL(u); err != nil {
+		return nil, "", err
+	}
+
+	res, err := f.client.Get(u.String())
+	if err != nil {
+		return nil, "", err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("server responded with status %d", res.StatusCode)
+	}
+	if res.ContentLength > f.limits.maxSize() {
+		return nil, "", errUploadTooLarge
+	}
+
+	in := bufio.NewReaderSize(&sizeLimitedReader{r: res.Body, remaining: f.limits.maxSize()}, contentTypeSniffingLength)
+	if err = validateUploadedImageType(res.Header.Get("Content-Type"), in); err != nil {
+		return nil, "", err
+	}
+	return loadImage(in, f.limits.maxPixels())
+}
@@ -0,0 +1,133 @@
+package api
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsDeniedIP(t *testing.T) {
+	t.Parallel()
+
+	denied := []string{"127.0.0.1", "10.1.2.3", "172.16.0.1", "192.168.1.1", "169.254.169.254", "0.0.0.0", "::1", "fe80::1", "fd00::1", "::ffff:127.0.0.1", "2002:7f00:1::1", "2002:a9fe:a9fe::1", "2001:0:4136:e378:8000:63bf:3fff:fdd2"}
+	for _, ip := range denied {
+		assert.True(t, isDeniedIP(net.ParseIP(ip)), "%s should be denied", ip)
+	}
+
+	allowed := []string{"93.184.216.34", "8.8.8.8", "2606:2800:220:1:248:1893:25c8:1946"}
+	for _, ip := range allowed {
+		assert.False(t, isDeniedIP(net.ParseIP(ip)), "%s should be allowed", ip)
+	}
+}
+
+func TestRemoteImageLimitsValidateURL(t *testing.T) {
+	t.Parallel()
+
+	limits := RemoteImageLimits{AllowedDomains: []string{"dairycart.com"}}
+	testCases := map[string]bool{
+		"https://dairycart.com/image.png":        true,
+		"https://images.dairycart.com/image.png": true,
+		"https://notdairycart.com/image.png":     false,
+		"https://dairycart.com.evil.com/a.png":   false,
+		"ftp://dairycart.com/image.png":          false,
+		"file:///etc/passwd":                     false,
+	}
+
+	for rawURL, expected := range testCases {
+		u, err := url.Parse(rawURL)
+		require.NoError(t, err)
+		assert.Equal(t, expected, limits.validateURL(u) == nil, rawURL)
+	}
+}
+
+func TestImageFetcherFetch(t *testing.T) {
+	t.Parallel()
+
+	pngBytes, err := base64.StdEncoding.DecodeString(smallGreenPNG)
+	require.NoError(t, err)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/image.png", func(res http.ResponseWriter, req *http.Request) {
+		res.Header().Set("Content-Type", "image/png")
+		res.Write(pngBytes)
+	})
+	mux.HandleFunc("/not_an_image.png", func(res http.ResponseWriter, req *http.Request) {
+		res.Header().Set("Content-Type", "image/png")
+		res.Write([]byte("<html><body>surprise</body></html>"))
+	})
+	mux.HandleFunc("/huge.png", func(res http.ResponseWriter, req *http.Request) {
+		res.Header().Set("Content-Type", "image/png")
+		res.Write(pngBytes)
+		res.Write(make([]byte, 1024))
+	})
+	mux.HandleFunc("/slow.png", func(res http.ResponseWriter, req *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		res.Write(pngBytes)
+	})
+	mux.HandleFunc("/redirect", func(res http.ResponseWriter, req *http.Request) {
+		http.Redirect(res, req, "/redirect", http.StatusFound)
+	})
+	mux.HandleFunc("/missing.png", http.NotFound)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	t.Run("optimal conditions", func(*testing.T) {
+		_, format, err := buildTestImageFetcher().Fetch(fmt.Sprintf("%s/image.png", ts.URL))
+		assert.NoError(t, err)
+		assert.Equal(t, "png", format)
+	})
+
+	t.Run("with private address", func(*testing.T) {
+		_, _, err := newImageFetcher(RemoteImageLimits{}).Fetch(fmt.Sprintf("%s/image.png", ts.URL))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), errDeniedAddress.Error())
+	})
+
+	t.Run("with private hostname", func(*testing.T) {
+		_, port, _ := net.SplitHostPort(strings.TrimPrefix(ts.URL, "http://"))
+		_, _, err := newImageFetcher(RemoteImageLimits{}).Fetch(fmt.Sprintf("http://localhost:%s/image.png", port))
+		assert.Error(t, err)
+	})
+
+	t.Run("with disallowed domain", func(*testing.T) {
+		limits := RemoteImageLimits{AllowedDomains: []string{"dairycart.com"}, allowPrivateNetworks: true}
+		_, _, err := newImageFetcher(limits).Fetch(fmt.Sprintf("%s/image.png", ts.URL))
+		assert.Error(t, err)
+	})
+
+	t.Run("with non-image content", func(*testing.T) {
+		_, _, err := buildTestImageFetcher().Fetch(fmt.Sprintf("%s/not_an_image.png", ts.URL))
+		assert.Error(t, err)
+	})
+
+	t.Run("with oversized image", func(*testing.T) {
+		limits := RemoteImageLimits{MaxSize: int64(len(pngBytes) + 10), allowPrivateNetworks: true}
+		_, _, err := newImageFetcher(limits).Fetch(fmt.Sprintf("%s/huge.png", ts.URL))
+		assert.Error(t, err)
+	})
+
+	t.Run("with slow server", func(*testing.T) {
+		limits := RemoteImageLimits{Timeout: 50 * time.Millisecond, allowPrivateNetworks: true}
+		_, _, err := newImageFetcher(limits).Fetch(fmt.Sprintf("%s/slow.png", ts.URL))
+		assert.Error(t, err)
+	})
+
+	t.Run("with endless redirects", func(*testing.T) {
+		_, _, err := buildTestImageFetcher().Fetch(fmt.Sprintf("%s/redirect", ts.URL))
+		assert.Error(t, err)
+	})
+
+	t.Run("with error response", func(*testing.T) {
+		_, _, err := buildTestImageFetcher().Fetch(fmt.Sprintf("%s/missing.png", ts.URL))
+		assert.Error(t, err)
+	})
+}
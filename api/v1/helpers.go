@@ -141,8 +141,12 @@ func respondThatRowDoesNotExist(req *http.Request, res http.ResponseWriter, item
 		"product option value": "id",
 		"product":              "sku",
 		"product root":         "id",
+		"product image":        "id",
 		"discount":             "id",
 		"user":                 "username",
+		"API key":              "id",
+		"role":                 "id",
+		"user role":            "role id",
 	}
 
 	// in case we forget one, default to ID
@@ -191,6 +195,24 @@ func notifyOfInternalIssue(res http.ResponseWriter, err error, attemptedTask str
 	json.NewEncoder(res).Encode(errRes)
 }
 
+func notifyOfUnauthorizedRequest(res http.ResponseWriter) {
+	res.WriteHeader(http.StatusUnauthorized)
+	errRes := &ErrorResponse{
+		Status:  http.StatusUnauthorized,
+		Message: "Unauthorized",
+	}
+	json.NewEncoder(res).Encode(errRes)
+}
+
+func notifyOfForbiddenRequest(res http.ResponseWriter) {
+	res.WriteHeader(http.StatusForbidden)
+	errRes := &ErrorResponse{
+		Status:  http.StatusForbidden,
+		Message: "Forbidden",
+	}
+	json.NewEncoder(res).Encode(errRes)
+}
+
 func notifyOfInvalidAuthenticationAttempt(res http.ResponseWriter) {
 	log.Println("Invalid login attempt")
 	res.WriteHeader(http.StatusUnauthorized)
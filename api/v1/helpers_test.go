@@ -24,6 +24,7 @@ import (
 	"github.com/gorilla/sessions"
 	log "github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"gopkg.in/DATA-DOG/go-sqlmock.v1"
 )
@@ -121,17 +122,22 @@ func setupTestVariablesWithMock(t *testing.T) *TestUtil {
 }
 
 func buildServerConfigFromTestUtil(testUtil *TestUtil) *ServerConfig {
-	whe := &mockWebhookExecutor{}
 	return &ServerConfig{
-		Router:          testUtil.Router,
-		DB:              testUtil.PlainDB,
-		CookieStore:     testUtil.Store,
-		DatabaseClient:  testUtil.MockDB,
-		ImageStorer:     testUtil.MockImageStorage,
-		WebhookExecutor: whe,
+		Router:         testUtil.Router,
+		DB:             testUtil.PlainDB,
+		CookieStore:    testUtil.Store,
+		DatabaseClient: testUtil.MockDB,
+		ImageStorer:    testUtil.MockImageStorage,
+		// test servers live on localhost
+		RemoteImageLimits: RemoteImageLimits{allowPrivateNetworks: true},
+		WebhookDelivery:   WebhookDeliverySettings{allowPrivateNetworks: true},
 	}
 }
 
+func buildTestImageFetcher() *imageFetcher {
+	return newImageFetcher(RemoteImageLimits{allowPrivateNetworks: true})
+}
+
 func ensureExpectationsWereMet(t *testing.T, mock sqlmock.Sqlmock) {
 	t.Helper()
 	if err := mock.ExpectationsWereMet(); err != nil {
@@ -139,27 +145,75 @@ func ensureExpectationsWereMet(t *testing.T, mock sqlmock.Sqlmock) {
 	}
 }
 
-func buildCookieForRequest(t *testing.T, store *sessions.CookieStore, authorized bool, admin bool) (*http.Cookie, error) {
+// testSessionUserID is who the cookies the helpers below build say the user is
+const testSessionUserID = uint64(666)
+
+// expectSessionUser sets up the lookups validateSessionCookieMiddleware makes for the user the test cookies belong to
+func expectSessionUser(testUtil *TestUtil, admin bool, permissions ...string) {
+	testUtil.MockDB.On("GetUser", mock.Anything, testSessionUserID).Return(&models.User{ID: testSessionUserID, IsAdmin: admin}, nil)
+	if !admin {
+		testUtil.MockDB.On("GetPermissionsByUserID", mock.Anything, testSessionUserID).Return(permissions, nil)
+	}
+}
+
+func buildCookieForRequest(t *testing.T, testUtil *TestUtil, authorized bool, admin bool) (*http.Cookie, error) {
 	t.Helper()
-	session, err := store.New(&http.Request{}, dairycartCookieName)
+	session, err := testUtil.Store.New(&http.Request{}, dairycartCookieName)
 	if err != nil {
 		return nil, err
 	}
-	session.Values[sessionUserIDKeyName] = 666
+	session.Values[sessionUserIDKeyName] = testSessionUserID
 	session.Values[sessionAuthorizedKeyName] = authorized
-	session.Values[sessionAdminKeyName] = admin
+	if authorized {
+		expectSessionUser(testUtil, admin)
+	}
 
-	encoded, err := securecookie.EncodeMulti(session.Name(), session.Values, store.Codecs...)
+	encoded, err := securecookie.EncodeMulti(session.Name(), session.Values, testUtil.Store.Codecs...)
 	assert.NoError(t, err)
 	cookie := sessions.NewCookie(session.Name(), encoded, session.Options)
 
 	return cookie, nil
 }
 
+// buildCookieWithPermissionsForRequest builds a cookie for a user who isn't an admin, but whose roles give them
+// the given permissions
+func buildCookieWithPermissionsForRequest(t *testing.T, testUtil *TestUtil, permissions ...string) *http.Cookie {
+	t.Helper()
+	session, err := testUtil.Store.New(&http.Request{}, dairycartCookieName)
+	require.NoError(t, err)
+	session.Values[sessionUserIDKeyName] = testSessionUserID
+	session.Values[sessionAuthorizedKeyName] = true
+	expectSessionUser(testUtil, false, permissions...)
+
+	encoded, err := securecookie.EncodeMulti(session.Name(), session.Values, testUtil.Store.Codecs...)
+	require.NoError(t, err)
+	return sessions.NewCookie(session.Name(), encoded, session.Options)
+}
+
 func attachBadCookieToRequest(req *http.Request) {
 	req.Header.Set("Cookie", fmt.Sprintf("%s=this is a bad cookie", dairycartCookieName))
 }
 
+func attachAdminCookieToRequest(t *testing.T, testUtil *TestUtil, req *http.Request) {
+	t.Helper()
+	cookie, err := buildCookieForRequest(t, testUtil, true, true)
+	require.NoError(t, err)
+	req.AddCookie(cookie)
+}
+
+// mockCallsTo returns the arguments of each call made to a mocked method. AssertCalled prints the arguments of every
+// call it compares, and printing a *sql.Tx races with the goroutine database/sql keeps watching it, so methods that
+// are called with a transaction are checked with this instead.
+func mockCallsTo(m *mock.Mock, method string) []mock.Arguments {
+	var calls []mock.Arguments
+	for _, call := range m.Calls {
+		if call.Method == method {
+			calls = append(calls, call.Arguments)
+		}
+	}
+	return calls
+}
+
 func assertStatusCode(t *testing.T, testUtil *TestUtil, statusCode int) {
 	t.Helper()
 	assert.Equal(t, statusCode, testUtil.Response.Code, "status code should be %d", statusCode)
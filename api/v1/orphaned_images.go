@@ -0,0 +1,150 @@
+package api
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/dairycart/dairycart/storage/v1/database"
+	"github.com/dairycart/dairycart/storage/v1/images"
+
+	"github.com/pkg/errors"
+)
+
+const defaultOrphanedImageMinAge = 24 * time.Hour
+
+// OrphanedImageCollectionOptions controls what CollectOrphanedProductImages is allowed to touch
+type OrphanedImageCollectionOptions struct {
+	// DryRun reports orphans without deleting them
+	DryRun bool
+
+	// MinAge is how long a file has to go unmodified before it can be considered orphaned. Images are stored
+	// before the rows that refer to them are committed, and stored again whenever a new row is going to refer to
+	// files that are already there, so this needs to be comfortably longer than any request takes. Product images
+	// created or updated more recently than this aren't checked for missing files either.
+	MinAge time.Duration
+}
+
+// MissingProductImage is a rendition a product image refers to that isn't in image storage
+type MissingProductImage struct {
+	ProductImageID uint64 `json:"product_image_id"`
+	Rendition      string `json:"rendition"`
+	Location       string `json:"location"`
+}
+
+// OrphanedImageReport is the outcome of CollectOrphanedProductImages
+type OrphanedImageReport struct {
+	DryRun        bool                  `json:"dry_run"`
+	Orphans       []images.StoredImage  `json:"orphans"`
+	OrphanedBytes int64                 `json:"orphaned_bytes"`
+	Deleted       int                   `json:"deleted"`
+	Missing       []MissingProductImage `json:"missing"`
+}
+
+// CollectOrphanedProductImages compares the files in image storage with the product images that refer to them.
+// Files nothing refers to are deleted, unless this is a dry run, and renditions whose files are gone are reported.
+// Since images with the same content share files, this is the only place files get deleted; deleting, replacing,
+// or regenerating an image, or failing to save one, leaves its files for this to clean up.
+// Files that can't be deleted are logged and skipped, and the report is returned regardless.
+func CollectOrphanedProductImages(db *sql.DB, client database.Storer, imager images.ImageStorer, opts OrphanedImageCollectionOptions) (*OrphanedImageReport, error) {
+	// storage has to be listed before the database is read, otherwise an image created in between
+	// would have files that weren't listed, or a row that wasn't read, depending on the order
+	stored, err := imager.ListImages()
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing stored images")
+	}
+	cutoff := time.Now().Add(-opts.MinAge)
+
+	productImages, err := getAllProductImages(db, client)
+	if err != nil {
+		return nil, err
+	}
+
+	referenced := map[string]bool{}
+	for _, productImage := range productImages {
+		for _, location := range locationsForProductImage(productImage) {
+			referenced[location] = true
+		}
+	}
+
+	report := &OrphanedImageReport{
+		DryRun:  opts.DryRun,
+		Orphans: []images.StoredImage{},
+		Missing: []MissingProductImage{},
+	}
+	present := map[string]bool{}
+	for _, s := range stored {
+		present[s.Location] = true
+		if referenced[s.Location] || s.ModifiedOn.After(cutoff) {
+			continue
+		}
+		report.Orphans = append(report.Orphans, s)
+		report.OrphanedBytes += s.Size
+	}
+	sort.Slice(report.Orphans, func(i, j int) bool { return report.Orphans[i].Location < report.Orphans[j].Location })
+
+	for _, productImage := range productImages {
+		lastChanged := productImage.CreatedOn
+		if productImage.UpdatedOn != nil && productImage.UpdatedOn.Time.After(lastChanged) {
+			lastChanged = productImage.UpdatedOn.Time
+		}
+		if lastChanged.After(cutoff) {
+			continue
+		}
+
+		for name, location := range locationsForProductImage(productImage) {
+			if !present[location] {
+				report.Missing = append(report.Missing, MissingProductImage{ProductImageID: productImage.ID, Rendition: name, Location: location})
+			}
+		}
+	}
+	sort.Slice(report.Missing, func(i, j int) bool {
+		a, b := report.Missing[i], report.Missing[j]
+		if a.ProductImageID != b.ProductImageID {
+			return a.ProductImageID < b.ProductImageID
+		}
+		return a.Rendition < b.Rendition
+	})
+
+	if opts.DryRun {
+		return report, nil
+	}
+
+	var failures int
+	for _, orphan := range report.Orphans {
+		if err = imager.DeleteImage(orphan.Location); err != nil {
+			log.Printf("error deleting orphaned image %s: %v\n", orphan.Location, err)
+			failures++
+			continue
+		}
+		report.Deleted++
+	}
+
+	if failures > 0 {
+		return report, fmt.Errorf("failed to delete %d of %d orphaned images", failures, len(report.Orphans))
+	}
+	return report, nil
+}
+
+// RunOrphanedImageCollection collects orphaned images every interval until stop is closed. Reports are logged.
+func RunOrphanedImageCollection(db *sql.DB, client database.Storer, imager images.ImageStorer, interval time.Duration, opts OrphanedImageCollectionOptions, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			report, err := CollectOrphanedProductImages(db, client, imager, opts)
+			if err != nil {
+				log.Printf("error collecting orphaned images: %v\n", err)
+			}
+			if report != nil {
+				log.Printf("found %d orphaned images (%d bytes), deleted %d, and %d missing renditions\n", len(report.Orphans), report.OrphanedBytes, report.Deleted, len(report.Missing))
+			}
+		}
+	}
+}
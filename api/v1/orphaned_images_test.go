@@ -0,0 +1,185 @@
+package api
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/dairycart/dairycart/models/v1"
+	"github.com/dairycart/dairycart/storage/v1/images"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollectOrphanedProductImages(t *testing.T) {
+	longAgo := time.Now().Add(-48 * time.Hour)
+	justNow := time.Now().Add(-time.Minute)
+
+	buildExampleImages := func() []models.ProductImage {
+		return []models.ProductImage{
+			{
+				ID:          10,
+				CreatedOn:   longAgo,
+				OriginalURL: "https://dairycart.com/product_images/skate/10/original.png",
+				Renditions: models.RenditionURLs{
+					images.OriginalRendition:  "https://dairycart.com/product_images/skate/10/original.png",
+					images.ThumbnailRendition: "https://dairycart.com/product_images/skate/10/thumbnail.png",
+				},
+			},
+			{
+				// this one is too new to be missing anything, its files are probably on their way
+				ID:          11,
+				CreatedOn:   justNow,
+				OriginalURL: "https://dairycart.com/product_images/skate/11/original.png",
+			},
+		}
+	}
+	buildStoredImages := func() []images.StoredImage {
+		return []images.StoredImage{
+			{Location: "https://dairycart.com/product_images/skate/10/original.png", Size: 100, ModifiedOn: longAgo},
+			{Location: "https://dairycart.com/product_images/skate/9/original.png", Size: 50, ModifiedOn: longAgo},
+			{Location: "https://dairycart.com/product_images/skate/9/main.png", Size: 25, ModifiedOn: longAgo},
+			// this one is too new to be orphaned, its row probably hasn't been committed yet
+			{Location: "https://dairycart.com/product_images/skate/12/original.png", Size: 100, ModifiedOn: justNow},
+		}
+	}
+	expectedOrphans := []images.StoredImage{
+		{Location: "https://dairycart.com/product_images/skate/9/main.png", Size: 25, ModifiedOn: longAgo},
+		{Location: "https://dairycart.com/product_images/skate/9/original.png", Size: 50, ModifiedOn: longAgo},
+	}
+	expectedMissing := []MissingProductImage{
+		{ProductImageID: 10, Rendition: images.ThumbnailRendition, Location: "https://dairycart.com/product_images/skate/10/thumbnail.png"},
+	}
+	opts := OrphanedImageCollectionOptions{MinAge: time.Hour}
+
+	t.Run("optimal conditions", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockImageStorage.On("ListImages").Return(buildStoredImages(), nil)
+		testUtil.Mock.ExpectBegin()
+		testUtil.MockDB.On("GetProductImagesAfterID", mock.Anything, uint64(0), uint(models.MaxLimit)).Return(buildExampleImages(), nil)
+		testUtil.Mock.ExpectRollback()
+		testUtil.MockImageStorage.On("DeleteImage", mock.Anything).Return(nil)
+
+		report, err := CollectOrphanedProductImages(testUtil.PlainDB, testUtil.MockDB, testUtil.MockImageStorage, opts)
+		require.NoError(t, err)
+		assert.Equal(t, expectedOrphans, report.Orphans)
+		assert.Equal(t, int64(75), report.OrphanedBytes)
+		assert.Equal(t, 2, report.Deleted)
+		assert.Equal(t, expectedMissing, report.Missing)
+		testUtil.MockImageStorage.AssertNumberOfCalls(t, "DeleteImage", 2)
+		testUtil.MockImageStorage.AssertCalled(t, "DeleteImage", expectedOrphans[0].Location)
+		testUtil.MockImageStorage.AssertCalled(t, "DeleteImage", expectedOrphans[1].Location)
+	})
+
+	t.Run("with archived product root", func(t *testing.T) {
+		// archiving a root archives its images, so they're no longer listed and their files become orphans
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockImageStorage.On("ListImages").Return(buildStoredImages(), nil)
+		testUtil.Mock.ExpectBegin()
+		testUtil.MockDB.On("GetProductImagesAfterID", mock.Anything, uint64(0), uint(models.MaxLimit)).Return(buildExampleImages()[1:], nil)
+		testUtil.Mock.ExpectRollback()
+
+		report, err := CollectOrphanedProductImages(testUtil.PlainDB, testUtil.MockDB, testUtil.MockImageStorage, OrphanedImageCollectionOptions{DryRun: true, MinAge: time.Hour})
+		require.NoError(t, err)
+		assert.Contains(t, report.Orphans, images.StoredImage{Location: "https://dairycart.com/product_images/skate/10/original.png", Size: 100, ModifiedOn: longAgo})
+		assert.Empty(t, report.Missing)
+	})
+
+	t.Run("with dry run", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockImageStorage.On("ListImages").Return(buildStoredImages(), nil)
+		testUtil.Mock.ExpectBegin()
+		testUtil.MockDB.On("GetProductImagesAfterID", mock.Anything, uint64(0), uint(models.MaxLimit)).Return(buildExampleImages(), nil)
+		testUtil.Mock.ExpectRollback()
+
+		report, err := CollectOrphanedProductImages(testUtil.PlainDB, testUtil.MockDB, testUtil.MockImageStorage, OrphanedImageCollectionOptions{DryRun: true, MinAge: time.Hour})
+		require.NoError(t, err)
+		assert.True(t, report.DryRun)
+		assert.Equal(t, expectedOrphans, report.Orphans)
+		assert.Zero(t, report.Deleted)
+		assert.Equal(t, expectedMissing, report.Missing)
+		testUtil.MockImageStorage.AssertNotCalled(t, "DeleteImage", mock.Anything)
+	})
+
+	t.Run("with more images than fit in a batch", func(t *testing.T) {
+		var firstBatch []models.ProductImage
+		for i := 0; i < models.MaxLimit; i++ {
+			id := uint64(100 + i)
+			firstBatch = append(firstBatch, models.ProductImage{ID: id, CreatedOn: justNow, OriginalURL: fmt.Sprintf("https://dairycart.com/product_images/skate/%d/original.png", id)})
+		}
+		lastID := firstBatch[len(firstBatch)-1].ID
+
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockImageStorage.On("ListImages").Return(buildStoredImages(), nil)
+		testUtil.Mock.ExpectBegin()
+		testUtil.MockDB.On("GetProductImagesAfterID", mock.Anything, uint64(0), uint(models.MaxLimit)).Return(firstBatch, nil)
+		testUtil.MockDB.On("GetProductImagesAfterID", mock.Anything, lastID, uint(models.MaxLimit)).Return(buildExampleImages(), nil)
+		testUtil.Mock.ExpectRollback()
+
+		report, err := CollectOrphanedProductImages(testUtil.PlainDB, testUtil.MockDB, testUtil.MockImageStorage, OrphanedImageCollectionOptions{DryRun: true, MinAge: time.Hour})
+		require.NoError(t, err)
+		assert.Equal(t, expectedOrphans, report.Orphans)
+		ensureExpectationsWereMet(t, testUtil.Mock)
+	})
+
+	t.Run("with error starting transaction", func(t *testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockImageStorage.On("ListImages").Return(buildStoredImages(), nil)
+		testUtil.Mock.ExpectBegin().WillReturnError(generateArbitraryError())
+
+		_, err := CollectOrphanedProductImages(testUtil.PlainDB, testUtil.MockDB, testUtil.MockImageStorage, opts)
+		assert.Error(t, err)
+		testUtil.MockImageStorage.AssertNotCalled(t, "DeleteImage", mock.Anything)
+	})
+
+	t.Run("with shorter min age", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockImageStorage.On("ListImages").Return(buildStoredImages(), nil)
+		testUtil.Mock.ExpectBegin()
+		testUtil.MockDB.On("GetProductImagesAfterID", mock.Anything, uint64(0), uint(models.MaxLimit)).Return(buildExampleImages(), nil)
+		testUtil.Mock.ExpectRollback()
+
+		report, err := CollectOrphanedProductImages(testUtil.PlainDB, testUtil.MockDB, testUtil.MockImageStorage, OrphanedImageCollectionOptions{DryRun: true})
+		require.NoError(t, err)
+		assert.Len(t, report.Orphans, 3)
+		assert.Len(t, report.Missing, 2)
+	})
+
+	t.Run("with error listing stored images", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockImageStorage.On("ListImages").Return([]images.StoredImage{}, generateArbitraryError())
+
+		_, err := CollectOrphanedProductImages(testUtil.PlainDB, testUtil.MockDB, testUtil.MockImageStorage, opts)
+		assert.Error(t, err)
+		testUtil.MockImageStorage.AssertNotCalled(t, "DeleteImage", mock.Anything)
+	})
+
+	t.Run("with error retrieving product images", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockImageStorage.On("ListImages").Return(buildStoredImages(), nil)
+		testUtil.Mock.ExpectBegin()
+		testUtil.MockDB.On("GetProductImagesAfterID", mock.Anything, uint64(0), uint(models.MaxLimit)).Return([]models.ProductImage{}, generateArbitraryError())
+		testUtil.Mock.ExpectRollback()
+
+		_, err := CollectOrphanedProductImages(testUtil.PlainDB, testUtil.MockDB, testUtil.MockImageStorage, opts)
+		assert.Error(t, err)
+		testUtil.MockImageStorage.AssertNotCalled(t, "DeleteImage", mock.Anything)
+	})
+
+	t.Run("with error deleting orphan", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockImageStorage.On("ListImages").Return(buildStoredImages(), nil)
+		testUtil.Mock.ExpectBegin()
+		testUtil.MockDB.On("GetProductImagesAfterID", mock.Anything, uint64(0), uint(models.MaxLimit)).Return(buildExampleImages(), nil)
+		testUtil.Mock.ExpectRollback()
+		testUtil.MockImageStorage.On("DeleteImage", expectedOrphans[0].Location).Return(generateArbitraryError())
+		testUtil.MockImageStorage.On("DeleteImage", expectedOrphans[1].Location).Return(nil)
+
+		report, err := CollectOrphanedProductImages(testUtil.PlainDB, testUtil.MockDB, testUtil.MockImageStorage, opts)
+		assert.Error(t, err)
+		require.NotNil(t, report)
+		assert.Equal(t, 1, report.Deleted)
+	})
+}
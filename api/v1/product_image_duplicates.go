@@ -0,0 +1,119 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/dairycart/dairycart/models/v1"
+	"github.com/dairycart/dairycart/storage/v1/database"
+	"github.com/dairycart/dairycart/storage/v1/images"
+)
+
+const (
+	// defaultDuplicateImageDistance is how many bits two perceptual hashes can differ by and still be considered
+	// the same picture. It's low enough that different photos of the same product don't usually get grouped.
+	defaultDuplicateImageDistance = 6
+	maxDuplicateImageDistance     = 32
+)
+
+// findDuplicateProductImages groups images whose perceptual hashes are within maxDistance of each other.
+// Groups are transitive, so two images in a group can be further apart than maxDistance, if there's an
+// image in between them. Images that look like nothing else aren't included.
+func findDuplicateProductImages(productImages []models.ProductImage, maxDistance int) []models.ProductImageDuplicateGroup {
+	var (
+		hashed []models.ProductImage
+		hashes []images.PerceptualHash
+	)
+	for _, img := range productImages {
+		h, err := images.ParsePerceptualHash(img.PerceptualHash)
+		if err != nil {
+			continue
+		}
+		hashed = append(hashed, img)
+		hashes = append(hashes, h)
+	}
+
+	// union-find, where parents[i] leads to the first image of i's group
+	parents := make([]int, len(hashed))
+	for i := range parents {
+		parents[i] = i
+	}
+	var find func(i int) int
+	find = func(i int) int {
+		if parents[i] != i {
+			parents[i] = find(parents[i])
+		}
+		return parents[i]
+	}
+
+	for i := range hashes {
+		for j := i + 1; j < len(hashes); j++ {
+			if hashes[i].Distance(hashes[j]) > maxDistance {
+				continue
+			}
+			if a, b := find(i), find(j); a < b {
+				parents[b] = a
+			} else if b < a {
+				parents[a] = b
+			}
+		}
+	}
+
+	members := map[int][]int{}
+	for i := range hashed {
+		root := find(i)
+		members[root] = append(members[root], i)
+	}
+
+	groups := []models.ProductImageDuplicateGroup{}
+	for _, indices := range members {
+		if len(indices) < 2 {
+			continue
+		}
+
+		group := models.ProductImageDuplicateGroup{}
+		for x, i := range indices {
+			group.ProductImages = append(group.ProductImages, hashed[i])
+			for _, j := range indices[x+1:] {
+				if d := hashes[i].Distance(hashes[j]); d > group.Distance {
+					group.Distance = d
+				}
+			}
+		}
+		groups = append(groups, group)
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].ProductImages[0].ID < groups[j].ProductImages[0].ID })
+
+	return groups
+}
+
+func buildProductImageDuplicateReportHandler(db *sql.DB, client database.Storer) http.HandlerFunc {
+	// ProductImageDuplicateReportHandler is a request handler that lists product images that look alike, for catalogue cleanup
+	return func(res http.ResponseWriter, req *http.Request) {
+		maxDistance := defaultDuplicateImageDistance
+		if raw := req.URL.Query().Get("max_distance"); raw != "" {
+			d, err := strconv.Atoi(raw)
+			if err != nil || d < 0 || d > maxDuplicateImageDistance {
+				notifyOfInvalidRequestBody(res, errors.New("max_distance must be a number from 0 to 32"))
+				return
+			}
+			maxDistance = d
+		}
+
+		productImages, err := client.GetHashedProductImages(db)
+		if err != nil && err != sql.ErrNoRows {
+			notifyOfInternalIssue(res, err, "retrieve product images from database")
+			return
+		}
+
+		report := &models.ProductImageDuplicateReport{
+			MaxDistance: maxDistance,
+			Groups:      findDuplicateProductImages(productImages, maxDistance),
+		}
+		json.NewEncoder(res).Encode(report)
+	}
+}
@@ -0,0 +1,116 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/dairycart/dairycart/models/v1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindDuplicateProductImages(t *testing.T) {
+	t.Parallel()
+
+	productImages := []models.ProductImage{
+		{ID: 1, PerceptualHash: "0000000000000000"},
+		{ID: 2, PerceptualHash: "00000000000000ff"},
+		{ID: 3, PerceptualHash: "0000000000000300"},
+		{ID: 4, PerceptualHash: "ffffffffffffffff"},
+		{ID: 5, PerceptualHash: "fffffffffffffffe"},
+		{ID: 6, PerceptualHash: "not a hash"},
+		{ID: 7, PerceptualHash: "0000ffff0000ffff"},
+	}
+
+	t.Run("optimal conditions", func(_t *testing.T) {
+		actual := findDuplicateProductImages(productImages, 6)
+		require.Len(t, actual, 2)
+
+		assert.Equal(t, 2, actual[0].Distance)
+		require.Len(t, actual[0].ProductImages, 2)
+		assert.Equal(t, uint64(1), actual[0].ProductImages[0].ID)
+		assert.Equal(t, uint64(3), actual[0].ProductImages[1].ID)
+
+		assert.Equal(t, 1, actual[1].Distance)
+		require.Len(t, actual[1].ProductImages, 2)
+		assert.Equal(t, uint64(4), actual[1].ProductImages[0].ID)
+	})
+
+	t.Run("groups are transitive", func(_t *testing.T) {
+		// 2 and 3 are both within 8 of 1, so they're all one group, even though 2 and 3 are 10 apart
+		actual := findDuplicateProductImages(productImages, 8)
+		require.Len(t, actual, 2)
+		assert.Len(t, actual[0].ProductImages, 3)
+		assert.Equal(t, 10, actual[0].Distance)
+	})
+
+	t.Run("with exact duplicates only", func(_t *testing.T) {
+		actual := findDuplicateProductImages(append(productImages, models.ProductImage{ID: 8, PerceptualHash: "0000ffff0000ffff"}), 0)
+		require.Len(t, actual, 1)
+		assert.Zero(t, actual[0].Distance)
+		assert.Equal(t, uint64(7), actual[0].ProductImages[0].ID)
+		assert.Equal(t, uint64(8), actual[0].ProductImages[1].ID)
+	})
+
+	t.Run("without any duplicates", func(_t *testing.T) {
+		actual := findDuplicateProductImages(productImages[:2], 0)
+		assert.NotNil(t, actual)
+		assert.Empty(t, actual)
+	})
+}
+
+func TestProductImageDuplicateReportHandler(t *testing.T) {
+	exampleImages := []models.ProductImage{
+		{ID: 1, ProductRootID: 1, PerceptualHash: "0000000000000000"},
+		{ID: 2, ProductRootID: 2, PerceptualHash: "0000000000000001"},
+	}
+
+	t.Run("optimal conditions", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetHashedProductImages", mock.Anything).Return(exampleImages, nil)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodGet, "/v1/product_images/duplicates?max_distance=2", nil)
+		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+
+		assertStatusCode(t, testUtil, http.StatusOK)
+		actual := &models.ProductImageDuplicateReport{}
+		require.NoError(t, json.NewDecoder(testUtil.Response.Body).Decode(actual))
+		assert.Equal(t, 2, actual.MaxDistance)
+		require.Len(t, actual.Groups, 1)
+		assert.Equal(t, exampleImages, actual.Groups[0].ProductImages)
+	})
+
+	t.Run("with invalid max distance", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodGet, "/v1/product_images/duplicates?max_distance=65", nil)
+		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+
+		assertStatusCode(t, testUtil, http.StatusBadRequest)
+	})
+
+	t.Run("with error retrieving images", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetHashedProductImages", mock.Anything).Return([]models.ProductImage{}, generateArbitraryError())
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodGet, "/v1/product_images/duplicates", nil)
+		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+
+		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+	})
+}
@@ -1,13 +1,23 @@
 package api
 
 import (
+	"bufio"
+	"bytes"
 	"database/sql"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"image"
+	"io"
 	"net/http"
+	"runtime"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	_ "image/gif"
 	_ "image/jpeg"
 	_ "image/png"
 
@@ -16,96 +26,1132 @@ import (
 	"github.com/dairycart/dairycart/storage/v1/images"
 
 	"github.com/fatih/set"
+	"github.com/go-chi/chi"
 	"github.com/pkg/errors"
-	"io"
+	_ "golang.org/x/image/webp"
 )
 
-func loadImage(in io.Reader) (image.Image, error) {
-	img, format, err := image.Decode(in)
+// exifPeekSize is how much of a JPEG we hold onto to look for EXIF data. The APP1 segment
+// that contains it can't be longer than 64KB, and generally comes right after the header.
+const exifPeekSize = 128 << 10
+
+// maxConcurrentImageJobs bounds how many images get fetched, decoded, resized, or stored at once
+var maxConcurrentImageJobs = runtime.NumCPU()
+
+// acceptedImageFormats are the formats we'll decode, as named by the image package
+var acceptedImageFormats = map[string]bool{
+	images.PNG:  true,
+	images.JPEG: true,
+	images.GIF:  true,
+	images.WebP: true,
+}
+
+// loadImage decodes an image, returning its format along with it. Images with more than maxPixels
+// are rejected before they're decoded. JPEGs are rotated according to their EXIF orientation, and
+// only the first frame of animated GIFs is kept.
+func loadImage(in io.Reader, maxPixels int64) (image.Image, string, error) {
+	br := bufio.NewReaderSize(in, exifPeekSize)
+	if err := images.CheckDimensions(br, maxPixels); err != nil {
+		return nil, "", err
+	}
+	// Peek returns an error when the image is smaller than we asked for, which is fine.
+	head, _ := br.Peek(exifPeekSize)
+	head = append([]byte(nil), head...)
+
+	img, format, err := image.Decode(br)
 	if err != nil {
-		return nil, errors.Wrap(err, "error decoding image")
-	} else if format != "png" {
-		return nil, errors.New("only pngs are accepted")
+		return nil, "", errors.Wrap(err, "error decoding image")
+	} else if !acceptedImageFormats[format] {
+		return nil, "", fmt.Errorf("unsupported image format: %s", format)
+	}
+
+	if format == images.JPEG {
+		img = images.ApplyOrientation(img, images.ReadOrientation(bytes.NewReader(head)))
+	}
+	return img, format, nil
+}
+
+// loadImageFromInput decodes the image described by a creation input, fetching it first if need be
+func loadImageFromInput(fetcher *imageFetcher, uploadLimits UploadLimits, imageInput models.ProductImageCreationInput) (image.Image, string, error) {
+	switch strings.ToLower(imageInput.Type) {
+	case "base64":
+		// note: base64 expects raw base64 data, not a data URI (`data:image/png;base64,blahblahblah`)
+		reader := base64.NewDecoder(base64.StdEncoding, strings.NewReader(imageInput.Data))
+		return loadImage(reader, uploadLimits.maxPixels())
+	case "url":
+		img, format, err := fetcher.Fetch(imageInput.Data)
+		if err != nil {
+			return nil, "", errors.Wrap(err, fmt.Sprintf("error retrieving product image from url %s", imageInput.Data))
+		}
+		return img, format, nil
+	default:
+		return nil, "", fmt.Errorf("invalid image type: %s", imageInput.Type)
+	}
+}
+
+func renderProductImage(imager images.ImageStorer, img image.Image, format string) images.ProductImageSet {
+	renditions := imager.CreateThumbnails(img)
+	renditions.Format = format
+	return renditions
+}
+
+// storeProductImageRenditions saves rendered images with the image storer, under the key for their content hash.
+// Whatever got stored is returned even when storing fails partway through, so that it can be cleaned up.
+func storeProductImageRenditions(imager images.ImageStorer, renditions images.ProductImageSet, contentHash string) (images.ProductImageLocations, error) {
+	locations, err := imager.StoreImages(renditions, images.ContentKey(contentHash))
+	if err != nil {
+		return locations, err
+	} else if locations == nil {
+		return nil, errors.New("image storer returned no image locations")
+	}
+	return locations, nil
+}
+
+// productImageStorageKey returns where an image's files are kept. Images from before content hashing
+// was introduced were stored under their sku prefix and ID, and stay there until they're replaced.
+func productImageStorageKey(skuPrefix string, img models.ProductImage) string {
+	if img.ContentHash != "" && strings.Contains(img.OriginalURL, images.ContentKey(img.ContentHash)) {
+		return images.ContentKey(img.ContentHash)
+	}
+	return fmt.Sprintf("%s/%d", skuPrefix, img.ID)
+}
+
+// setProductImageLocations records where every rendition of an image is stored. The thumbnail,
+// main, and original renditions keep getting their own fields so older clients don't break.
+func setProductImageLocations(productImage *models.ProductImage, locations images.ProductImageLocations) {
+	productImage.ThumbnailURL = locations[images.ThumbnailRendition]
+	productImage.MainURL = locations[images.MainRendition]
+	productImage.OriginalURL = locations[images.OriginalRendition]
+	productImage.Renditions = models.RenditionURLs(locations)
+}
+
+func locationsForProductImage(img models.ProductImage) images.ProductImageLocations {
+	out := images.ProductImageLocations{}
+	for name, location := range img.Renditions {
+		out[name] = location
 	}
-	return img, err
+
+	// images stored before renditions were configurable only have these
+	for name, location := range map[string]string{
+		images.ThumbnailRendition: img.ThumbnailURL,
+		images.MainRendition:      img.MainURL,
+		images.OriginalRendition:  img.OriginalURL,
+	} {
+		if _, ok := out[name]; !ok && location != "" {
+			out[name] = location
+		}
+	}
+	return out
 }
 
-func handleProductCreationImages(tx *sql.Tx, client database.Storer, imager images.ImageStorer, images []models.ProductImageCreationInput, sku string, rootID uint64) ([]models.ProductImage, *uint64, error) {
+// pendingProductImage is a decoded image that has yet to be saved as a product image
+type pendingProductImage struct {
+	image     image.Image
+	format    string
+	sourceURL string
+	isPrimary bool
+
+	// these are filled in by prepareProductImages
+	renditions     images.ProductImageSet
+	contentHash    string
+	perceptualHash string
+
+	// stored is where storeProductImages stored the image
+	stored images.ProductImageLocations
+}
+
+// forEachConcurrently calls fn for every index up to n, running at most maxConcurrentImageJobs calls at once.
+// Once a call fails, calls that haven't started yet are skipped. The error for the lowest index is returned.
+func forEachConcurrently(n int, fn func(i int) error) error {
 	var (
-		primaryImageID *uint64
-		imagesToCreate []models.ProductImageCreationInput
-		returnImages   []models.ProductImage
+		wg     sync.WaitGroup
+		failed int32
+		errs   = make([]error, n)
+		sem    = make(chan struct{}, maxConcurrentImageJobs)
 	)
 
+	for i := 0; i < n; i++ {
+		sem <- struct{}{}
+		if atomic.LoadInt32(&failed) != 0 {
+			<-sem
+			break
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			if errs[i] = fn(i); errs[i] != nil {
+				atomic.StoreInt32(&failed, 1)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// prepareProductImages fetches, decodes, hashes, and renders every image ahead of time, so that none of that
+// slow work happens while a database transaction is open. Duplicate images are only used once, and uploads
+// go after the inputs, so the order images were provided in is their sort order.
+func prepareProductImages(imager images.ImageStorer, fetcher *imageFetcher, uploadLimits UploadLimits, inputs []models.ProductImageCreationInput, uploads []pendingProductImage) ([]pendingProductImage, error) {
+	var uniqueInputs []models.ProductImageCreationInput
 	createdImages := set.New()
-	for _, img := range images {
+	for _, img := range inputs {
 		if createdImages.Has(img.Data) {
 			continue
 		}
 		createdImages.Add(img.Data)
-		imagesToCreate = append(imagesToCreate, img)
+		uniqueInputs = append(uniqueInputs, img)
 	}
 
-	// FIXME: Make this whole process concurrent
-	for i, imageInput := range imagesToCreate {
-		var (
-			img image.Image
-			err error
-		)
-		imageType := strings.ToLower(imageInput.Type)
+	pendingImages := make([]pendingProductImage, len(uniqueInputs), len(uniqueInputs)+len(uploads))
+	pendingImages = append(pendingImages, uploads...)
 
-		switch imageType {
-		case "base64":
-			// note: base64 expects raw base64 data, not a data URI (`data:image/png;base64,blahblahblah`)
-			reader := base64.NewDecoder(base64.StdEncoding, strings.NewReader(imageInput.Data))
-			img, err = loadImage(reader)
+	err := forEachConcurrently(len(pendingImages), func(i int) error {
+		pi := &pendingImages[i]
+		if i < len(uniqueInputs) {
+			input := uniqueInputs[i]
+			decoded, format, err := loadImageFromInput(fetcher, uploadLimits, input)
 			if err != nil {
-				return nil, nil, errors.Wrap(err, fmt.Sprintf("image data at index %d is invalid", i))
+				return errors.Wrap(err, fmt.Sprintf("image data at index %d is invalid", i))
+			}
+
+			pi.image, pi.format, pi.isPrimary = decoded, format, input.IsPrimary
+			if strings.ToLower(input.Type) == "url" {
+				pi.sourceURL = input.Data
+			}
+		}
+		pi.contentHash = images.ContentHash(pi.image)
+		pi.perceptualHash = images.ComputePerceptualHash(pi.image).String()
+		pi.renditions = renderProductImage(imager, pi.image, pi.format)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// the same picture can show up under different data, like a URL and an upload
+	var uniqueImages []pendingProductImage
+	firstWithHash := map[string]int{}
+	for _, pi := range pendingImages {
+		if j, ok := firstWithHash[pi.contentHash]; ok {
+			uniqueImages[j].isPrimary = uniqueImages[j].isPrimary || pi.isPrimary
+			continue
+		}
+		firstWithHash[pi.contentHash] = len(uniqueImages)
+		uniqueImages = append(uniqueImages, pi)
+	}
+	return uniqueImages, nil
+}
+
+// storeProductImages stores the renditions of every image prepared by prepareProductImages. It's meant to be
+// called before any transaction is opened, so that nothing waits on storage while holding locks. Every image
+// gets stored, even when some other image already has the same content, because files are stored by content
+// hash and storing them again is what tells orphaned image collection that they're in use. For the same
+// reason, nothing stored here gets deleted if saving the images fails later on; collection takes care of that.
+func storeProductImages(imager images.ImageStorer, pendingImages []pendingProductImage) error {
+	return forEachConcurrently(len(pendingImages), func(i int) error {
+		pi := &pendingImages[i]
+		var err error
+		pi.stored, err = storeProductImageRenditions(imager, pi.renditions, pi.contentHash)
+		return err
+	})
+}
+
+// handleProductCreationImages creates database records for images stored by storeProductImages. Images the
+// product root already has are reused as they are, and everything else gets a new record.
+func handleProductCreationImages(tx *sql.Tx, client database.Storer, pendingImages []pendingProductImage, rootID uint64, firstSortOrder uint32) ([]models.ProductImage, *uint64, error) {
+	var (
+		primaryImageID *uint64
+		sortOrder      = firstSortOrder
+		newImages      = make([]models.ProductImage, 0, len(pendingImages))
+	)
+	for i, pi := range pendingImages {
+		matches, err := client.GetProductImagesByContentHash(tx, pi.contentHash)
+		if err != nil && err != sql.ErrNoRows {
+			return nil, nil, err
+		}
+
+		var reused *models.ProductImage
+		for j := range matches {
+			if matches[j].ProductRootID == rootID {
+				reused = &matches[j]
+				break
 			}
-		case "url":
-			// FIXME: this is suuuuuuuuper lame, support JPEGs at least
-			if !strings.HasSuffix(imageInput.Data, "png") {
-				return nil, nil, errors.New("only PNG images are supported")
+		}
+
+		if reused != nil {
+			newImages = append(newImages, *reused)
+		} else {
+			newImage := models.ProductImage{
+				ProductRootID:  rootID,
+				SortOrder:      sortOrder,
+				SourceURL:      pi.sourceURL,
+				ContentHash:    pi.contentHash,
+				PerceptualHash: pi.perceptualHash,
 			}
-			response, err := http.Get(imageInput.Data)
+			setProductImageLocations(&newImage, pi.stored)
+
+			newImage.ID, newImage.CreatedOn, err = client.CreateProductImage(tx, &newImage)
 			if err != nil {
-				return nil, nil, errors.Wrap(err, fmt.Sprintf("error retrieving product image from url %s", imageInput.Data))
-			} else {
-				img, err = loadImage(response.Body)
+				return nil, nil, err
+			}
+			newImages = append(newImages, newImage)
+			sortOrder++
+		}
+
+		if pi.isPrimary && primaryImageID == nil {
+			primaryImageID = &newImages[i].ID
+		}
+	}
+	return newImages, primaryImageID, nil
+}
+
+// validateImagesBelongToProductRoot makes sure that every image ID provided refers to an
+// image owned by the given product root. Variants may only use their root's images.
+func validateImagesBelongToProductRoot(db *sql.DB, client database.Storer, productRootID uint64, imageIDs []uint64) (invalid error, err error) {
+	rootImages, err := client.GetProductImagesByProductRootID(db, productRootID)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	owned := map[uint64]bool{}
+	for _, img := range rootImages {
+		owned[img.ID] = true
+	}
+
+	for _, id := range imageIDs {
+		if !owned[id] {
+			return fmt.Errorf("product image %d does not belong to product root %d", id, productRootID), nil
+		}
+	}
+	return nil, nil
+}
+
+// imageIDsFromAssignmentInput returns the list of image IDs to attach, including the primary image
+func imageIDsFromAssignmentInput(in *models.ProductImageAssignmentInput) []uint64 {
+	ids := []uint64{}
+	seen := map[uint64]bool{}
+	for _, id := range in.ImageIDs {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if in.PrimaryImageID != nil && !seen[*in.PrimaryImageID] {
+		ids = append(ids, *in.PrimaryImageID)
+	}
+	return ids
+}
+
+func retrieveImagesForProduct(db *sql.DB, client database.Storer, p *models.Product) ([]models.ProductImage, error) {
+	productImages, err := client.GetProductImagesByProductID(db, p.ID)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	// variants without images of their own show the images of their product root
+	if len(productImages) == 0 {
+		productImages, err = client.GetProductImagesByProductRootID(db, p.ProductRootID)
+		if err != nil && err != sql.ErrNoRows {
+			return nil, err
+		}
+	}
+	return productImages, nil
+}
+
+func buildProductImageAttachmentHandler(db *sql.DB, client database.Storer) http.HandlerFunc {
+	// ProductImageAttachmentHandler is a request handler that attaches existing product root images to a variant
+	return func(res http.ResponseWriter, req *http.Request) {
+		sku := chi.URLParam(req, "sku")
+
+		input := &models.ProductImageAssignmentInput{}
+		err := validateRequestInput(req, input)
+		if err != nil {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
+		imageIDs := imageIDsFromAssignmentInput(input)
+
+		product, err := client.GetProductBySKU(db, sku)
+		if err == sql.ErrNoRows {
+			respondThatRowDoesNotExist(req, res, "product", sku)
+			return
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product from database")
+			return
+		}
+
+		invalid, err := validateImagesBelongToProductRoot(db, client, product.ProductRootID, imageIDs)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product images from database")
+			return
+		} else if invalid != nil {
+			notifyOfInvalidRequestBody(res, invalid)
+			return
+		}
+
+		tx, err := db.Begin()
+		if err != nil {
+			notifyOfInternalIssue(res, err, "create new database transaction")
+			return
+		}
+
+		err = client.CreateMultipleProductImageBridgesForProductID(tx, product.ID, imageIDs)
+		if err != nil {
+			tx.Rollback()
+			notifyOfInternalIssue(res, err, "attach images to product in database")
+			return
+		}
+
+		if input.PrimaryImageID != nil {
+			_, err = client.SetPrimaryProductImageForProduct(tx, product.ID, *input.PrimaryImageID)
+			if err != nil {
+				tx.Rollback()
+				notifyOfInternalIssue(res, err, "set primary image ID")
+				return
+			}
+			product.PrimaryImageID = input.PrimaryImageID
+		}
+
+		err = tx.Commit()
+		if err != nil {
+			notifyOfInternalIssue(res, err, "close out transaction")
+			return
+		}
+
+		product.Images, err = client.GetProductImagesByProductID(db, product.ID)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product images from database")
+			return
+		}
+
+		json.NewEncoder(res).Encode(product)
+	}
+}
+
+// replacePrimaryImageForProduct makes the first of a variant's remaining images its primary image, or leaves it
+// without one if it has none left
+func replacePrimaryImageForProduct(tx *sql.Tx, client database.Storer, product *models.Product) error {
+	remaining, err := client.GetProductImagesByProductID(tx, product.ID)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+
+	var updatedOn time.Time
+	if len(remaining) > 0 {
+		updatedOn, err = client.SetPrimaryProductImageForProduct(tx, product.ID, remaining[0].ID)
+		product.PrimaryImageID = &remaining[0].ID
+	} else {
+		updatedOn, err = client.ClearPrimaryProductImageForProduct(tx, product.ID)
+		product.PrimaryImageID = nil
+	}
+	if err != nil {
+		return err
+	}
+	product.UpdatedOn = &models.Dairytime{Time: updatedOn}
+	return nil
+}
+
+func buildProductImageDetachmentHandler(db *sql.DB, client database.Storer) http.HandlerFunc {
+	// ProductImageDetachmentHandler is a request handler that removes an image from a variant
+	return func(res http.ResponseWriter, req *http.Request) {
+		sku := chi.URLParam(req, "sku")
+		imageIDStr := chi.URLParam(req, "image_id")
+		// eating this error because the router should have ensured this is an integer
+		imageID, _ := strconv.ParseUint(imageIDStr, 10, 64)
+
+		product, err := client.GetProductBySKU(db, sku)
+		if err == sql.ErrNoRows {
+			respondThatRowDoesNotExist(req, res, "product", sku)
+			return
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product from database")
+			return
+		}
+
+		tx, err := db.Begin()
+		if err != nil {
+			notifyOfInternalIssue(res, err, "create new database transaction")
+			return
+		}
+
+		_, err = client.DeleteProductImageBridgeByProductIDAndImageID(tx, product.ID, imageID)
+		if err == sql.ErrNoRows {
+			tx.Rollback()
+			respondThatRowDoesNotExist(req, res, "product image", imageIDStr)
+			return
+		} else if err != nil {
+			tx.Rollback()
+			notifyOfInternalIssue(res, err, "detach image from product in database")
+			return
+		}
+
+		// the primary image should always be one of the variant's images, so it moves on to the next one
+		if product.PrimaryImageID != nil && *product.PrimaryImageID == imageID {
+			err = replacePrimaryImageForProduct(tx, client, product)
+			if err != nil {
+				tx.Rollback()
+				notifyOfInternalIssue(res, err, "replace primary image ID")
+				return
+			}
+		}
+
+		err = tx.Commit()
+		if err != nil {
+			notifyOfInternalIssue(res, err, "close out transaction")
+			return
+		}
+
+		product.Images, err = client.GetProductImagesByProductID(db, product.ID)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product images from database")
+			return
+		}
+
+		json.NewEncoder(res).Encode(product)
+	}
+}
+
+func buildProductPrimaryImageHandler(db *sql.DB, client database.Storer) http.HandlerFunc {
+	// ProductPrimaryImageHandler is a request handler that sets a variant's primary image
+	return func(res http.ResponseWriter, req *http.Request) {
+		sku := chi.URLParam(req, "sku")
+		imageIDStr := chi.URLParam(req, "image_id")
+		// eating this error because the router should have ensured this is an integer
+		imageID, _ := strconv.ParseUint(imageIDStr, 10, 64)
+
+		product, err := client.GetProductBySKU(db, sku)
+		if err == sql.ErrNoRows {
+			respondThatRowDoesNotExist(req, res, "product", sku)
+			return
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product from database")
+			return
+		}
+
+		invalid, err := validateImagesBelongToProductRoot(db, client, product.ProductRootID, []uint64{imageID})
+		if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product images from database")
+			return
+		} else if invalid != nil {
+			respondThatRowDoesNotExist(req, res, "product image", imageIDStr)
+			return
+		}
+
+		tx, err := db.Begin()
+		if err != nil {
+			notifyOfInternalIssue(res, err, "create new database transaction")
+			return
+		}
+
+		// the primary image should always be one of the variant's images
+		err = client.CreateMultipleProductImageBridgesForProductID(tx, product.ID, []uint64{imageID})
+		if err != nil {
+			tx.Rollback()
+			notifyOfInternalIssue(res, err, "attach image to product in database")
+			return
+		}
+
+		updatedOn, err := client.SetPrimaryProductImageForProduct(tx, product.ID, imageID)
+		if err != nil {
+			tx.Rollback()
+			notifyOfInternalIssue(res, err, "set primary image ID")
+			return
+		}
+		product.PrimaryImageID = &imageID
+		product.UpdatedOn = &models.Dairytime{Time: updatedOn}
+
+		err = tx.Commit()
+		if err != nil {
+			notifyOfInternalIssue(res, err, "close out transaction")
+			return
+		}
+
+		product.Images, err = client.GetProductImagesByProductID(db, product.ID)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product images from database")
+			return
+		}
+
+		json.NewEncoder(res).Encode(product)
+	}
+}
+
+func buildProductOptionValueImageAssignmentHandler(db *sql.DB, client database.Storer) http.HandlerFunc {
+	// ProductOptionValueImageAssignmentHandler is a request handler that attaches images to every variant with a given option value
+	return func(res http.ResponseWriter, req *http.Request) {
+		optionValueIDStr := chi.URLParam(req, "option_value_id")
+		// eating this error because the router should have ensured this is an integer
+		optionValueID, _ := strconv.ParseUint(optionValueIDStr, 10, 64)
+
+		input := &models.ProductImageAssignmentInput{}
+		err := validateRequestInput(req, input)
+		if err != nil {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
+		imageIDs := imageIDsFromAssignmentInput(input)
+
+		optionValue, err := client.GetProductOptionValue(db, optionValueID)
+		if err == sql.ErrNoRows {
+			respondThatRowDoesNotExist(req, res, "product option value", optionValueIDStr)
+			return
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product option value from database")
+			return
+		}
+
+		option, err := client.GetProductOption(db, optionValue.ProductOptionID)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product option from database")
+			return
+		}
+
+		invalid, err := validateImagesBelongToProductRoot(db, client, option.ProductRootID, imageIDs)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product images from database")
+			return
+		} else if invalid != nil {
+			notifyOfInvalidRequestBody(res, invalid)
+			return
+		}
+
+		productIDs, err := client.GetProductIDsByOptionValueID(db, optionValueID)
+		if err != nil && err != sql.ErrNoRows {
+			notifyOfInternalIssue(res, err, "retrieve products from database")
+			return
+		}
+
+		tx, err := db.Begin()
+		if err != nil {
+			notifyOfInternalIssue(res, err, "create new database transaction")
+			return
+		}
+
+		for _, productID := range productIDs {
+			err = client.CreateMultipleProductImageBridgesForProductID(tx, productID, imageIDs)
+			if err != nil {
+				tx.Rollback()
+				notifyOfInternalIssue(res, err, "attach images to products in database")
+				return
+			}
+
+			if input.PrimaryImageID != nil {
+				_, err = client.SetPrimaryProductImageForProduct(tx, productID, *input.PrimaryImageID)
 				if err != nil {
-					return nil, nil, errors.Wrap(err, fmt.Sprintf("image data at index %d is invalid", i))
+					tx.Rollback()
+					notifyOfInternalIssue(res, err, "set primary image ID")
+					return
 				}
 			}
-			response.Body.Close()
 		}
 
-		thumbnails := imager.CreateThumbnails(img)
-		locations, err := imager.StoreImages(thumbnails, sku, uint(i))
-		if err != nil || locations == nil {
-			return nil, nil, err
+		err = tx.Commit()
+		if err != nil {
+			notifyOfInternalIssue(res, err, "close out transaction")
+			return
+		}
+
+		json.NewEncoder(res).Encode(&models.ProductImageAssignment{
+			ProductIDs:     productIDs,
+			ImageIDs:       imageIDs,
+			PrimaryImageID: input.PrimaryImageID,
+		})
+	}
+}
+
+func retrieveProductRootImage(db *sql.DB, client database.Storer, productRootID uint64, imageID uint64) (*models.ProductImage, error) {
+	img, err := client.GetProductImage(db, imageID)
+	if err != nil {
+		return nil, err
+	} else if img.ProductRootID != productRootID {
+		// as far as this product root is concerned, this image doesn't exist
+		return nil, sql.ErrNoRows
+	}
+	return img, nil
+}
+
+func buildProductRootImageListHandler(db *sql.DB, client database.Storer) http.HandlerFunc {
+	// ProductRootImageListHandler is a request handler that returns the images for a product root
+	return func(res http.ResponseWriter, req *http.Request) {
+		productRootIDStr := chi.URLParam(req, "product_root_id")
+		// eating this error because the router should have ensured this is an integer
+		productRootID, _ := strconv.ParseUint(productRootIDStr, 10, 64)
+
+		productRootExists, err := client.ProductRootExists(db, productRootID)
+		if err == sql.ErrNoRows || !productRootExists {
+			respondThatRowDoesNotExist(req, res, "product root", productRootIDStr)
+			return
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product root from database")
+			return
 		}
 
-		newImage := &models.ProductImage{
-			ProductRootID: rootID,
-			ThumbnailURL:  locations.Thumbnail,
-			MainURL:       locations.Main,
-			OriginalURL:   locations.Original,
+		productImages, err := client.GetProductImagesByProductRootID(db, productRootID)
+		if err != nil && err != sql.ErrNoRows {
+			notifyOfInternalIssue(res, err, "retrieve product images from database")
+			return
 		}
 
-		if imageType == "url" {
-			newImage.SourceURL = imageInput.Data
+		imagesResponse := &ListResponse{
+			Page:  1,
+			Limit: uint8(len(productImages)),
+			Count: uint64(len(productImages)),
+			Data:  productImages,
 		}
+		json.NewEncoder(res).Encode(imagesResponse)
+	}
+}
 
-		newImage.ID, newImage.CreatedOn, err = client.CreateProductImage(tx, newImage)
+func buildProductRootImageCreationHandler(db *sql.DB, client database.Storer, imager images.ImageStorer, uploadLimits UploadLimits, fetcher *imageFetcher) http.HandlerFunc {
+	// ProductRootImageCreationHandler is a request handler that adds images to an existing product root
+	return func(res http.ResponseWriter, req *http.Request) {
+		productRootIDStr := chi.URLParam(req, "product_root_id")
+		// eating this error because the router should have ensured this is an integer
+		productRootID, _ := strconv.ParseUint(productRootIDStr, 10, 64)
+
+		var (
+			err     error
+			uploads []pendingProductImage
+			input   = &models.ProductImagesCreationInput{}
+		)
+		if isMultipartRequest(req) {
+			// uploaded files are all we need here
+			uploads, err = parseMultipartImageRequest(res, req, uploadLimits, nil)
+			if err == nil && len(uploads) == 0 {
+				err = errors.New("no files were uploaded")
+			}
+		} else {
+			err = validateRequestInput(req, input)
+		}
 		if err != nil {
-			return nil, nil, err
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
+
+		productRoot, err := client.GetProductRoot(db, productRootID)
+		if err == sql.ErrNoRows {
+			respondThatRowDoesNotExist(req, res, "product root", productRootIDStr)
+			return
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product root from database")
+			return
+		}
+
+		existingImages, err := client.GetProductImagesByProductRootID(db, productRootID)
+		if err != nil && err != sql.ErrNoRows {
+			notifyOfInternalIssue(res, err, "retrieve product images from database")
+			return
+		}
+
+		// new images go after the existing ones
+		var firstSortOrder uint32
+		if len(existingImages) > 0 {
+			firstSortOrder = existingImages[len(existingImages)-1].SortOrder + 1
+		}
+
+		pendingImages, err := prepareProductImages(imager, fetcher, uploadLimits, input.Images, uploads)
+		if err != nil {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
+
+		if err = storeProductImages(imager, pendingImages); err != nil {
+			notifyOfInternalIssue(res, err, "store product images")
+			return
+		}
+
+		tx, err := db.Begin()
+		if err != nil {
+			notifyOfInternalIssue(res, err, "create new database transaction")
+			return
+		}
+
+		newImages, primaryImageID, err := handleProductCreationImages(tx, client, pendingImages, productRoot.ID, firstSortOrder)
+		if err != nil {
+			tx.Rollback()
+			notifyOfInternalIssue(res, err, "insert product images in database")
+			return
+		}
+
+		// the first image a product root receives is its primary image, unless told otherwise
+		if primaryImageID == nil && productRoot.PrimaryImageID == nil && len(newImages) > 0 {
+			primaryImageID = &newImages[0].ID
+		}
+
+		if primaryImageID != nil {
+			err = setPrimaryImageForProductRoot(tx, db, client, productRoot, *primaryImageID)
+			if err != nil {
+				tx.Rollback()
+				notifyOfInternalIssue(res, err, "set primary image ID")
+				return
+			}
+		}
+
+		for _, image := range newImages {
+			err = enqueueWebhookDeliveries(tx, client, ProductImageCreatedWebhookEvent, image)
+			if err != nil {
+				tx.Rollback()
+				notifyOfInternalIssue(res, err, "queue webhook deliveries")
+				return
+			}
+		}
+
+		err = tx.Commit()
+		if err != nil {
+			notifyOfInternalIssue(res, err, "close out transaction")
+			return
+		}
+
+		res.WriteHeader(http.StatusCreated)
+		json.NewEncoder(res).Encode(newImages)
+	}
+}
+
+// setPrimaryImageForProductRoot sets the primary image for a product root, and for each of its products
+// that either have no primary image or are still using the product root's previous primary image.
+func setPrimaryImageForProductRoot(tx *sql.Tx, db *sql.DB, client database.Storer, productRoot *models.ProductRoot, imageID uint64) error {
+	previousPrimaryImageID := productRoot.PrimaryImageID
+
+	products, err := client.GetProductsByProductRootID(db, productRoot.ID)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+
+	for _, p := range products {
+		if p.PrimaryImageID == nil || (previousPrimaryImageID != nil && *p.PrimaryImageID == *previousPrimaryImageID) {
+			_, err = client.SetPrimaryProductImageForProduct(tx, p.ID, imageID)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	productRoot.PrimaryImageID = &imageID
+	updatedOn, err := client.UpdateProductRoot(tx, productRoot)
+	if err != nil {
+		return err
+	}
+	productRoot.UpdatedOn = &models.Dairytime{Time: updatedOn}
+	return nil
+}
+
+func buildProductRootPrimaryImageHandler(db *sql.DB, client database.Storer) http.HandlerFunc {
+	// ProductRootPrimaryImageHandler is a request handler that switches a product root's primary image
+	return func(res http.ResponseWriter, req *http.Request) {
+		productRootIDStr := chi.URLParam(req, "product_root_id")
+		imageIDStr := chi.URLParam(req, "image_id")
+		// eating these errors because the router should have ensured these are integers
+		productRootID, _ := strconv.ParseUint(productRootIDStr, 10, 64)
+		imageID, _ := strconv.ParseUint(imageIDStr, 10, 64)
+
+		productRoot, err := client.GetProductRoot(db, productRootID)
+		if err == sql.ErrNoRows {
+			respondThatRowDoesNotExist(req, res, "product root", productRootIDStr)
+			return
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product root from database")
+			return
+		}
+
+		_, err = retrieveProductRootImage(db, client, productRootID, imageID)
+		if err == sql.ErrNoRows {
+			respondThatRowDoesNotExist(req, res, "product image", imageIDStr)
+			return
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product image from database")
+			return
+		}
+
+		tx, err := db.Begin()
+		if err != nil {
+			notifyOfInternalIssue(res, err, "create new database transaction")
+			return
+		}
+
+		err = setPrimaryImageForProductRoot(tx, db, client, productRoot, imageID)
+		if err != nil {
+			tx.Rollback()
+			notifyOfInternalIssue(res, err, "set primary image ID")
+			return
 		}
 
-		if imageInput.IsPrimary && primaryImageID == nil {
-			primaryImageID = &newImage.ID
+		err = tx.Commit()
+		if err != nil {
+			notifyOfInternalIssue(res, err, "close out transaction")
+			return
+		}
+
+		json.NewEncoder(res).Encode(productRoot)
+	}
+}
+
+func buildProductRootImageOrderHandler(db *sql.DB, client database.Storer) http.HandlerFunc {
+	// ProductRootImageOrderHandler is a request handler that reorders a product root's images
+	return func(res http.ResponseWriter, req *http.Request) {
+		productRootIDStr := chi.URLParam(req, "product_root_id")
+		// eating this error because the router should have ensured this is an integer
+		productRootID, _ := strconv.ParseUint(productRootIDStr, 10, 64)
+
+		input := &models.ProductImageOrderInput{}
+		err := validateRequestInput(req, input)
+		if err != nil {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
+
+		productRootExists, err := client.ProductRootExists(db, productRootID)
+		if err == sql.ErrNoRows || !productRootExists {
+			respondThatRowDoesNotExist(req, res, "product root", productRootIDStr)
+			return
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product root from database")
+			return
+		}
+
+		existingImages, err := client.GetProductImagesByProductRootID(db, productRootID)
+		if err != nil && err != sql.ErrNoRows {
+			notifyOfInternalIssue(res, err, "retrieve product images from database")
+			return
+		}
+
+		// the new order has to account for every image, exactly once
+		imagesByID := map[uint64]models.ProductImage{}
+		for _, img := range existingImages {
+			imagesByID[img.ID] = img
+		}
+		if len(input.ImageIDs) != len(existingImages) {
+			notifyOfInvalidRequestBody(res, fmt.Errorf("expected %d image IDs, got %d", len(existingImages), len(input.ImageIDs)))
+			return
+		}
+
+		var orderedImages []models.ProductImage
+		for _, id := range input.ImageIDs {
+			img, ok := imagesByID[id]
+			if !ok {
+				notifyOfInvalidRequestBody(res, fmt.Errorf("product image %d does not belong to product root %d, or was listed more than once", id, productRootID))
+				return
+			}
+			delete(imagesByID, id)
+			orderedImages = append(orderedImages, img)
+		}
+
+		tx, err := db.Begin()
+		if err != nil {
+			notifyOfInternalIssue(res, err, "create new database transaction")
+			return
+		}
+
+		for i := range orderedImages {
+			orderedImages[i].SortOrder = uint32(i)
+			updatedOn, err := client.UpdateProductImage(tx, &orderedImages[i])
+			if err != nil {
+				tx.Rollback()
+				notifyOfInternalIssue(res, err, "update product image in database")
+				return
+			}
+			orderedImages[i].UpdatedOn = &models.Dairytime{Time: updatedOn}
+		}
+
+		err = tx.Commit()
+		if err != nil {
+			notifyOfInternalIssue(res, err, "close out transaction")
+			return
+		}
+
+		json.NewEncoder(res).Encode(orderedImages)
+	}
+}
+
+func buildProductRootImageReplacementHandler(db *sql.DB, client database.Storer, imager images.ImageStorer, uploadLimits UploadLimits, fetcher *imageFetcher) http.HandlerFunc {
+	// ProductRootImageReplacementHandler is a request handler that replaces the contents of an existing image
+	return func(res http.ResponseWriter, req *http.Request) {
+		productRootIDStr := chi.URLParam(req, "product_root_id")
+		imageIDStr := chi.URLParam(req, "image_id")
+		// eating these errors because the router should have ensured these are integers
+		productRootID, _ := strconv.ParseUint(productRootIDStr, 10, 64)
+		imageID, _ := strconv.ParseUint(imageIDStr, 10, 64)
+
+		var (
+			err         error
+			replacement *pendingProductImage
+			input       = &models.ProductImageCreationInput{}
+		)
+		if isMultipartRequest(req) {
+			var uploads []pendingProductImage
+			uploads, err = parseMultipartImageRequest(res, req, uploadLimits, nil)
+			if err == nil && len(uploads) != 1 {
+				err = errors.New("exactly one file must be uploaded")
+			}
+			if err == nil {
+				replacement = &uploads[0]
+			}
+		} else {
+			err = validateRequestInput(req, input)
+		}
+		if err != nil {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
+
+		_, err = client.GetProductRoot(db, productRootID)
+		if err == sql.ErrNoRows {
+			respondThatRowDoesNotExist(req, res, "product root", productRootIDStr)
+			return
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product root from database")
+			return
+		}
+
+		existingImage, err := retrieveProductRootImage(db, client, productRootID, imageID)
+		if err == sql.ErrNoRows {
+			respondThatRowDoesNotExist(req, res, "product image", imageIDStr)
+			return
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product image from database")
+			return
+		}
+		previous := *existingImage
+
+		if replacement == nil {
+			img, format, err := loadImageFromInput(fetcher, uploadLimits, *input)
+			if err != nil {
+				notifyOfInvalidRequestBody(res, err)
+				return
+			}
+			replacement = &pendingProductImage{image: img, format: format}
+			if strings.ToLower(input.Type) == "url" {
+				replacement.sourceURL = input.Data
+			}
+		}
+		contentHash := images.ContentHash(replacement.image)
+
+		// the files are stored even if some other image already has them, see storeProductImages
+		renditions := renderProductImage(imager, replacement.image, replacement.format)
+		locations, err := storeProductImageRenditions(imager, renditions, contentHash)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "store product image")
+			return
+		}
+
+		setProductImageLocations(existingImage, locations)
+		existingImage.SourceURL = replacement.sourceURL
+		existingImage.ContentHash = contentHash
+		existingImage.PerceptualHash = images.ComputePerceptualHash(replacement.image).String()
+
+		tx, err := db.Begin()
+		if err != nil {
+			notifyOfInternalIssue(res, err, "create new database transaction")
+			return
+		}
+
+		updatedOn, err := client.UpdateProductImage(tx, existingImage)
+		if err != nil {
+			tx.Rollback()
+			notifyOfInternalIssue(res, err, "update product image in database")
+			return
+		}
+		existingImage.UpdatedOn = &models.Dairytime{Time: updatedOn}
+
+		err = enqueueWebhookChangeDeliveries(tx, client, ProductImageUpdatedWebhookEvent, &previous, existingImage)
+		if err != nil {
+			tx.Rollback()
+			notifyOfInternalIssue(res, err, "queue webhook deliveries")
+			return
+		}
+
+		err = tx.Commit()
+		if err != nil {
+			notifyOfInternalIssue(res, err, "close out transaction")
+			return
+		}
+
+		json.NewEncoder(res).Encode(existingImage)
+	}
+}
+
+func buildProductRootImageDeletionHandler(db *sql.DB, client database.Storer) http.HandlerFunc {
+	// ProductRootImageDeletionHandler is a request handler that deletes an image. Its stored files are left
+	// for orphaned image collection, since other images may be sharing them.
+	return func(res http.ResponseWriter, req *http.Request) {
+		productRootIDStr := chi.URLParam(req, "product_root_id")
+		imageIDStr := chi.URLParam(req, "image_id")
+		// eating these errors because the router should have ensured these are integers
+		productRootID, _ := strconv.ParseUint(productRootIDStr, 10, 64)
+		imageID, _ := strconv.ParseUint(imageIDStr, 10, 64)
+
+		productRoot, err := client.GetProductRoot(db, productRootID)
+		if err == sql.ErrNoRows {
+			respondThatRowDoesNotExist(req, res, "product root", productRootIDStr)
+			return
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product root from database")
+			return
+		}
+
+		existingImage, err := retrieveProductRootImage(db, client, productRootID, imageID)
+		if err == sql.ErrNoRows {
+			respondThatRowDoesNotExist(req, res, "product image", imageIDStr)
+			return
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve product image from database")
+			return
+		}
+
+		// can't delete an image that's still somebody's primary image!
+		if productRoot.PrimaryImageID != nil && *productRoot.PrimaryImageID == imageID {
+			notifyOfInvalidRequestBody(res, fmt.Errorf("product image %d is the primary image for product root %d, set a different primary image first", imageID, productRootID))
+			return
+		}
+
+		products, err := client.GetProductsByProductRootID(db, productRootID)
+		if err != nil && err != sql.ErrNoRows {
+			notifyOfInternalIssue(res, err, "retrieve products from database")
+			return
+		}
+		for _, p := range products {
+			if p.PrimaryImageID != nil && *p.PrimaryImageID == imageID {
+				notifyOfInvalidRequestBody(res, fmt.Errorf("product image %d is the primary image for product %s, set a different primary image first", imageID, p.SKU))
+				return
+			}
+		}
+
+		tx, err := db.Begin()
+		if err != nil {
+			notifyOfInternalIssue(res, err, "create new database transaction")
+			return
+		}
+
+		archivedOn, err := client.DeleteProductImage(tx, imageID)
+		if err != nil {
+			tx.Rollback()
+			notifyOfInternalIssue(res, err, "archive product image in database")
+			return
+		}
+		existingImage.ArchivedOn = &models.Dairytime{Time: archivedOn}
+
+		err = enqueueWebhookDeliveries(tx, client, ProductImageArchivedWebhookEvent, existingImage)
+		if err != nil {
+			tx.Rollback()
+			notifyOfInternalIssue(res, err, "queue webhook deliveries")
+			return
+		}
+
+		err = tx.Commit()
+		if err != nil {
+			notifyOfInternalIssue(res, err, "close out transaction")
+			return
 		}
 
-		returnImages = append(returnImages, *newImage)
+		json.NewEncoder(res).Encode(existingImage)
 	}
-	return returnImages, primaryImageID, nil
 }
@@ -2,14 +2,19 @@ package api
 
 import (
 	"bytes"
+	"database/sql"
 	"encoding/base64"
 	"fmt"
 	"image"
+	"image/gif"
+	"image/jpeg"
 	"image/png"
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/dairycart/dairycart/models/v1"
 	"github.com/dairycart/dairycart/storage/v1/images"
@@ -23,14 +28,32 @@ const (
 	smallGreenPNG = "iVBORw0KGgoAAAANSUhEUgAAAAoAAAAKAQMAAAC3/F3+AAAABlBMVEUA/wAA/wD8J4MxAAAACXBIWXMAAA7EAAAOxAGVKw4bAAAAC0lEQVQImWNgwAcAAB4AAe72cCEAAAAASUVORK5CYII="
 )
 
+func prepareTestProductImages(t *testing.T, testUtil *TestUtil, inputs []models.ProductImageCreationInput) []pendingProductImage {
+	t.Helper()
+	pendingImages, err := prepareProductImages(testUtil.MockImageStorage, buildTestImageFetcher(), UploadLimits{}, inputs, nil)
+	require.NoError(t, err)
+	return pendingImages
+}
+
+func contentHashForBase64Image(t *testing.T, data string) string {
+	t.Helper()
+	img, _, err := image.Decode(base64.NewDecoder(base64.StdEncoding, strings.NewReader(data)))
+	require.NoError(t, err)
+	return images.ContentHash(img)
+}
+
 func TestHandleProductCreationImages(t *testing.T) {
 	t.Parallel()
 
-	exampleSKU := "example"
 	exampleID := uint64(1)
 	exampleThumbnailLocation := "https://dairycart.com/product_images/sku/0/thumbnail.png"
 	exampleMainLocation := "https://dairycart.com/product_images/sku/0/main.png"
 	exampleOriginalLocation := "https://dairycart.com/product_images/sku/0/original.png"
+	exampleRenditions := models.RenditionURLs{
+		images.ThumbnailRendition: exampleThumbnailLocation,
+		images.MainRendition:      exampleMainLocation,
+		images.OriginalRendition:  exampleOriginalLocation,
+	}
 
 	t.Run("optimal conditions", func(_t *testing.T) {
 		_t.Parallel()
@@ -39,12 +62,9 @@ func TestHandleProductCreationImages(t *testing.T) {
 
 		handlers := map[string]http.HandlerFunc{
 			"/cool.png": func(res http.ResponseWriter, req *http.Request) {
-				reader := base64.NewDecoder(base64.StdEncoding, strings.NewReader(smallGreenPNG))
-				img, _, err := image.Decode(reader)
-				require.Nil(t, err)
-
+				// a different picture than smallGreenPNG, so that the two aren't treated as duplicates
 				buffer := new(bytes.Buffer)
-				err = png.Encode(buffer, img)
+				err := png.Encode(buffer, image.NewRGBA(image.Rect(0, 0, 2, 2)))
 				require.Nil(t, err)
 				res.Write(buffer.Bytes())
 			},
@@ -64,45 +84,59 @@ func TestHandleProductCreationImages(t *testing.T) {
 			},
 		}
 
+		arbitraryImageSet := images.ProductImageSet{}
+		testUtil.MockImageStorage.On("CreateThumbnails", mock.Anything).
+			Return(arbitraryImageSet)
+		pendingImages := prepareTestProductImages(t, testUtil, exampleImageInputs)
+		require.Len(t, pendingImages, 2)
+
 		expectedPrimaryImageID := &exampleID
 		expectedImages := []models.ProductImage{
 			{
-				ID:            exampleID,
-				ProductRootID: exampleID,
-				ThumbnailURL:  exampleThumbnailLocation,
-				MainURL:       exampleMainLocation,
-				OriginalURL:   exampleOriginalLocation,
-				CreatedOn:     buildTestTime(),
+				ID:             exampleID,
+				ProductRootID:  exampleID,
+				ThumbnailURL:   exampleThumbnailLocation,
+				MainURL:        exampleMainLocation,
+				OriginalURL:    exampleOriginalLocation,
+				Renditions:     exampleRenditions,
+				CreatedOn:      buildTestTime(),
+				ContentHash:    pendingImages[0].contentHash,
+				PerceptualHash: pendingImages[0].perceptualHash,
 			},
 			{
-				ID:            exampleID,
-				ProductRootID: exampleID,
-				ThumbnailURL:  exampleThumbnailLocation,
-				MainURL:       exampleMainLocation,
-				OriginalURL:   exampleOriginalLocation,
-				SourceURL:     exampleImageInputs[1].Data,
-				CreatedOn:     buildTestTime(),
+				ID:             exampleID,
+				ProductRootID:  exampleID,
+				ThumbnailURL:   exampleThumbnailLocation,
+				MainURL:        exampleMainLocation,
+				OriginalURL:    exampleOriginalLocation,
+				Renditions:     exampleRenditions,
+				SourceURL:      exampleImageInputs[1].Data,
+				CreatedOn:      buildTestTime(),
+				SortOrder:      1,
+				ContentHash:    pendingImages[1].contentHash,
+				PerceptualHash: pendingImages[1].perceptualHash,
 			},
 		}
 
-		exampleProductImageLocations := &images.ProductImageLocations{
-			Thumbnail: exampleThumbnailLocation,
-			Main:      exampleMainLocation,
-			Original:  exampleOriginalLocation,
+		exampleProductImageLocations := images.ProductImageLocations{
+			images.ThumbnailRendition: exampleThumbnailLocation,
+			images.MainRendition:      exampleMainLocation,
+			images.OriginalRendition:  exampleOriginalLocation,
 		}
-		arbitraryImageSet := images.ProductImageSet{}
-		testUtil.MockImageStorage.On("CreateThumbnails", mock.Anything).
-			Return(arbitraryImageSet)
-		testUtil.MockImageStorage.On("StoreImages", mock.Anything, exampleSKU, mock.AnythingOfType("uint")).
+		testUtil.MockImageStorage.On("StoreImages", mock.Anything, images.ContentKey(pendingImages[0].contentHash)).
 			Return(exampleProductImageLocations, nil)
-
+		testUtil.MockImageStorage.On("StoreImages", mock.Anything, images.ContentKey(pendingImages[1].contentHash)).
+			Return(exampleProductImageLocations, nil)
+		testUtil.MockDB.On("GetProductImagesByContentHash", mock.AnythingOfType("*sql.Tx"), mock.AnythingOfType("string")).
+			Return([]models.ProductImage{}, nil)
 		testUtil.MockDB.On("CreateProductImage", mock.AnythingOfType("*sql.Tx"), mock.Anything).
 			Return(uint64(1), buildTestTime(), nil)
 
+		require.NoError(t, storeProductImages(testUtil.MockImageStorage, pendingImages))
 		tx, err := testUtil.PlainDB.Begin()
 		assert.NoError(t, err)
 
-		actualImages, actualPrimaryImageID, err := handleProductCreationImages(tx, testUtil.MockDB, testUtil.MockImageStorage, exampleImageInputs, exampleSKU, exampleID)
+		actualImages, actualPrimaryImageID, err := handleProductCreationImages(tx, testUtil.MockDB, pendingImages, exampleID, 0)
 
 		assert.NoError(t, err)
 		assert.Equal(t, expectedImages, actualImages, "expected and actual images should match")
@@ -125,60 +159,65 @@ func TestHandleProductCreationImages(t *testing.T) {
 			},
 		}
 
+		arbitraryImageSet := images.ProductImageSet{}
+		testUtil.MockImageStorage.On("CreateThumbnails", mock.Anything).
+			Return(arbitraryImageSet).
+			Once()
+		pendingImages := prepareTestProductImages(t, testUtil, exampleImageInputs)
+
 		expectedImages := []models.ProductImage{
 			{
-				ID:            exampleID,
-				ProductRootID: exampleID,
-				ThumbnailURL:  exampleThumbnailLocation,
-				MainURL:       exampleMainLocation,
-				OriginalURL:   exampleOriginalLocation,
-				CreatedOn:     buildTestTime(),
+				ID:             exampleID,
+				ProductRootID:  exampleID,
+				ThumbnailURL:   exampleThumbnailLocation,
+				MainURL:        exampleMainLocation,
+				OriginalURL:    exampleOriginalLocation,
+				Renditions:     exampleRenditions,
+				CreatedOn:      buildTestTime(),
+				ContentHash:    pendingImages[0].contentHash,
+				PerceptualHash: pendingImages[0].perceptualHash,
 			},
 		}
 
-		exampleProductImageLocations := &images.ProductImageLocations{
-			Thumbnail: exampleThumbnailLocation,
-			Main:      exampleMainLocation,
-			Original:  exampleOriginalLocation,
+		exampleProductImageLocations := images.ProductImageLocations{
+			images.ThumbnailRendition: exampleThumbnailLocation,
+			images.MainRendition:      exampleMainLocation,
+			images.OriginalRendition:  exampleOriginalLocation,
 		}
-		arbitraryImageSet := images.ProductImageSet{}
-		testUtil.MockImageStorage.On("CreateThumbnails", mock.Anything).
-			Return(arbitraryImageSet).
-			Once()
-		testUtil.MockImageStorage.On("StoreImages", mock.Anything, exampleSKU, mock.AnythingOfType("uint")).
+		testUtil.MockImageStorage.On("StoreImages", mock.Anything, mock.AnythingOfType("string")).
 			Return(exampleProductImageLocations, nil).
 			Once()
 
+		testUtil.MockDB.On("GetProductImagesByContentHash", mock.AnythingOfType("*sql.Tx"), mock.AnythingOfType("string")).
+			Return([]models.ProductImage{}, nil).
+			Once()
 		testUtil.MockDB.On("CreateProductImage", mock.AnythingOfType("*sql.Tx"), mock.Anything).
 			Return(uint64(1), buildTestTime(), nil).
 			Once()
 
+		require.NoError(t, storeProductImages(testUtil.MockImageStorage, pendingImages))
 		tx, err := testUtil.PlainDB.Begin()
 		assert.NoError(t, err)
 
-		actualImages, _, err := handleProductCreationImages(tx, testUtil.MockDB, testUtil.MockImageStorage, exampleImageInputs, exampleSKU, exampleID)
+		actualImages, _, err := handleProductCreationImages(tx, testUtil.MockDB, pendingImages, exampleID, 0)
 
 		assert.NoError(t, err)
 		assert.Equal(t, expectedImages, actualImages, "expected and actual images should match")
 	})
 
-	t.Run("with non png value in base64", func(_t *testing.T) {
+	t.Run("with unsupported image format in base64", func(_t *testing.T) {
 		_t.Parallel()
 
 		testUtil := setupTestVariablesWithMock(t)
-		testUtil.Mock.ExpectBegin()
 
 		exampleImageInputs := []models.ProductImageCreationInput{
 			{
 				Type: "base64",
-				Data: "/9j/4AAQSkZJRgABAQEAYABgAAD//gA+Q1JFQVRPUjogZ2QtanBlZyB2MS4wICh1c2luZyBJSkcgSlBFRyB2ODApLCBkZWZhdWx0IHF1YWxpdHkK/9sAQwAIBgYHBgUIBwcHCQkICgwUDQwLCwwZEhMPFB0aHx4dGhwcICQuJyAiLCMcHCg3KSwwMTQ0NB8nOT04MjwuMzQy/9sAQwEJCQkMCwwYDQ0YMiEcITIyMjIyMjIyMjIyMjIyMjIyMjIyMjIyMjIyMjIyMjIyMjIyMjIyMjIyMjIyMjIyMjIy/8AAEQgACgAKAwEiAAIRAQMRAf/EAB8AAAEFAQEBAQEBAAAAAAAAAAABAgMEBQYHCAkKC//EALUQAAIBAwMCBAMFBQQEAAABfQECAwAEEQUSITFBBhNRYQcicRQygZGhCCNCscEVUtHwJDNicoIJChYXGBkaJSYnKCkqNDU2Nzg5OkNERUZHSElKU1RVVldYWVpjZGVmZ2hpanN0dXZ3eHl6g4SFhoeIiYqSk5SVlpeYmZqio6Slpqeoqaqys7S1tre4ubrCw8TFxsfIycrS09TV1tfY2drh4uPk5ebn6Onq8fLz9PX29/j5+v/EAB8BAAMBAQEBAQEBAQEAAAAAAAABAgMEBQYHCAkKC//EALURAAIBAgQEAwQHBQQEAAECdwABAgMRBAUhMQYSQVEHYXETIjKBCBRCkaGxwQkjM1LwFWJy0QoWJDThJfEXGBkaJicoKSo1Njc4OTpDREVGR0hJSlNUVVZXWFlaY2RlZmdoaWpzdHV2d3h5eoKDhIWGh4iJipKTlJWWl5iZmqKjpKWmp6ipqrKztLW2t7i5usLDxMXGx8jJytLT1NXW19jZ2uLj5OXm5+jp6vLz9PX29/j5+v/aAAwDAQACEQMRAD8A1qKKK/ND8gP/2Q==",
+				Data: "Qk06AAAAAAAAADYAAAAoAAAAAQAAAAEAAAABABgAAAAAAAQAAAATCwAAEwsAAAAAAAAAAAAAAP8AAA==",
 			},
 		}
 
-		tx, err := testUtil.PlainDB.Begin()
-		assert.NoError(t, err)
-
-		_, _, err = handleProductCreationImages(tx, testUtil.MockDB, testUtil.MockImageStorage, exampleImageInputs, exampleSKU, exampleID)
+		_, err := prepareProductImages(testUtil.MockImageStorage, buildTestImageFetcher(), UploadLimits{}, exampleImageInputs, nil)
 		assert.Error(t, err)
 	})
 
@@ -186,7 +225,6 @@ func TestHandleProductCreationImages(t *testing.T) {
 		_t.Parallel()
 
 		testUtil := setupTestVariablesWithMock(t)
-		testUtil.Mock.ExpectBegin()
 
 		exampleImageInputs := []models.ProductImageCreationInput{
 			{
@@ -195,10 +233,7 @@ func TestHandleProductCreationImages(t *testing.T) {
 			},
 		}
 
-		tx, err := testUtil.PlainDB.Begin()
-		assert.NoError(t, err)
-
-		_, _, err = handleProductCreationImages(tx, testUtil.MockDB, testUtil.MockImageStorage, exampleImageInputs, exampleSKU, exampleID)
+		_, err := prepareProductImages(testUtil.MockImageStorage, buildTestImageFetcher(), UploadLimits{}, exampleImageInputs, nil)
 		assert.Error(t, err)
 	})
 
@@ -206,7 +241,6 @@ func TestHandleProductCreationImages(t *testing.T) {
 		_t.Parallel()
 
 		testUtil := setupTestVariablesWithMock(t)
-		testUtil.Mock.ExpectBegin()
 
 		exampleImageInputs := []models.ProductImageCreationInput{
 			{
@@ -215,17 +249,13 @@ func TestHandleProductCreationImages(t *testing.T) {
 			},
 		}
 
-		tx, err := testUtil.PlainDB.Begin()
-		assert.NoError(t, err)
-
-		_, _, err = handleProductCreationImages(tx, testUtil.MockDB, testUtil.MockImageStorage, exampleImageInputs, exampleSKU, exampleID)
+		_, err := prepareProductImages(testUtil.MockImageStorage, buildTestImageFetcher(), UploadLimits{}, exampleImageInputs, nil)
 		assert.Error(t, err)
 	})
 
 	t.Run("with error fetching image URL", func(_t *testing.T) {
 		_t.Parallel()
 		testUtil := setupTestVariablesWithMock(t)
-		testUtil.Mock.ExpectBegin()
 
 		exampleImageInputs := []models.ProductImageCreationInput{
 			{
@@ -234,17 +264,13 @@ func TestHandleProductCreationImages(t *testing.T) {
 			},
 		}
 
-		tx, err := testUtil.PlainDB.Begin()
-		assert.NoError(t, err)
-
-		_, _, err = handleProductCreationImages(tx, testUtil.MockDB, testUtil.MockImageStorage, exampleImageInputs, exampleSKU, exampleID)
+		_, err := prepareProductImages(testUtil.MockImageStorage, buildTestImageFetcher(), UploadLimits{}, exampleImageInputs, nil)
 		assert.Error(t, err)
 	})
 
 	t.Run("with invalid image data response", func(_t *testing.T) {
 		_t.Parallel()
 		testUtil := setupTestVariablesWithMock(t)
-		testUtil.Mock.ExpectBegin()
 
 		handlers := map[string]http.HandlerFunc{
 			"/cool.png": func(res http.ResponseWriter, req *http.Request) {
@@ -261,26 +287,19 @@ func TestHandleProductCreationImages(t *testing.T) {
 			},
 		}
 
-		tx, err := testUtil.PlainDB.Begin()
-		assert.NoError(t, err)
-
-		_, _, err = handleProductCreationImages(tx, testUtil.MockDB, testUtil.MockImageStorage, exampleImageInputs, exampleSKU, exampleID)
+		_, err := prepareProductImages(testUtil.MockImageStorage, buildTestImageFetcher(), UploadLimits{}, exampleImageInputs, nil)
 		assert.Error(t, err)
 	})
 
 	t.Run("with error storing images", func(_t *testing.T) {
 		_t.Parallel()
 		testUtil := setupTestVariablesWithMock(t)
-		testUtil.Mock.ExpectBegin()
 
 		handlers := map[string]http.HandlerFunc{
 			"/cool.png": func(res http.ResponseWriter, req *http.Request) {
-				reader := base64.NewDecoder(base64.StdEncoding, strings.NewReader(smallGreenPNG))
-				img, _, err := image.Decode(reader)
-				require.Nil(t, err)
-
+				// a different picture than smallGreenPNG, so that the two aren't treated as duplicates
 				buffer := new(bytes.Buffer)
-				err = png.Encode(buffer, img)
+				err := png.Encode(buffer, image.NewRGBA(image.Rect(0, 0, 2, 2)))
 				require.Nil(t, err)
 				res.Write(buffer.Bytes())
 			},
@@ -300,22 +319,21 @@ func TestHandleProductCreationImages(t *testing.T) {
 			},
 		}
 
-		exampleProductImageLocations := &images.ProductImageLocations{
-			Thumbnail: exampleThumbnailLocation,
-			Main:      exampleMainLocation,
-			Original:  exampleOriginalLocation,
+		exampleProductImageLocations := images.ProductImageLocations{
+			images.ThumbnailRendition: exampleThumbnailLocation,
+			images.MainRendition:      exampleMainLocation,
+			images.OriginalRendition:  exampleOriginalLocation,
 		}
 		arbitraryImageSet := images.ProductImageSet{}
 		testUtil.MockImageStorage.On("CreateThumbnails", mock.Anything).
 			Return(arbitraryImageSet)
-		testUtil.MockImageStorage.On("StoreImages", mock.Anything, exampleSKU, mock.AnythingOfType("uint")).
+		testUtil.MockImageStorage.On("StoreImages", mock.Anything, mock.AnythingOfType("string")).
 			Return(exampleProductImageLocations, generateArbitraryError())
 
-		tx, err := testUtil.PlainDB.Begin()
-		assert.NoError(t, err)
-
-		_, _, err = handleProductCreationImages(tx, testUtil.MockDB, testUtil.MockImageStorage, exampleImageInputs, exampleSKU, exampleID)
+		err := storeProductImages(testUtil.MockImageStorage, prepareTestProductImages(t, testUtil, exampleImageInputs))
 		assert.Error(t, err)
+		// other images might be sharing the files, so they're left for orphaned image collection
+		testUtil.MockImageStorage.AssertNotCalled(t, "DeleteImages", mock.Anything)
 	})
 
 	t.Run("with error creating product image records", func(_t *testing.T) {
@@ -325,12 +343,9 @@ func TestHandleProductCreationImages(t *testing.T) {
 
 		handlers := map[string]http.HandlerFunc{
 			"/cool.png": func(res http.ResponseWriter, req *http.Request) {
-				reader := base64.NewDecoder(base64.StdEncoding, strings.NewReader(smallGreenPNG))
-				img, _, err := image.Decode(reader)
-				require.Nil(t, err)
-
+				// a different picture than smallGreenPNG, so that the two aren't treated as duplicates
 				buffer := new(bytes.Buffer)
-				err = png.Encode(buffer, img)
+				err := png.Encode(buffer, image.NewRGBA(image.Rect(0, 0, 2, 2)))
 				require.Nil(t, err)
 				res.Write(buffer.Bytes())
 			},
@@ -350,24 +365,1183 @@ func TestHandleProductCreationImages(t *testing.T) {
 			},
 		}
 
-		exampleProductImageLocations := &images.ProductImageLocations{
-			Thumbnail: exampleThumbnailLocation,
-			Main:      exampleMainLocation,
-			Original:  exampleOriginalLocation,
+		exampleProductImageLocations := images.ProductImageLocations{
+			images.ThumbnailRendition: exampleThumbnailLocation,
+			images.MainRendition:      exampleMainLocation,
+			images.OriginalRendition:  exampleOriginalLocation,
 		}
 		testUtil.MockImageStorage.On("CreateThumbnails", mock.Anything).
 			Return(images.ProductImageSet{})
-		testUtil.MockImageStorage.On("StoreImages", mock.Anything, exampleSKU, mock.AnythingOfType("uint")).
+		testUtil.MockImageStorage.On("StoreImages", mock.Anything, mock.AnythingOfType("string")).
 			Return(exampleProductImageLocations, nil)
 
+		testUtil.MockDB.On("GetProductImagesByContentHash", mock.AnythingOfType("*sql.Tx"), mock.AnythingOfType("string")).
+			Return([]models.ProductImage{}, nil)
 		testUtil.MockDB.On("CreateProductImage", mock.AnythingOfType("*sql.Tx"), mock.Anything).
 			Return(uint64(1), buildTestTime(), generateArbitraryError())
 
+		pendingImages := prepareTestProductImages(t, testUtil, exampleImageInputs)
+		require.NoError(t, storeProductImages(testUtil.MockImageStorage, pendingImages))
 		tx, err := testUtil.PlainDB.Begin()
 		assert.NoError(t, err)
 
-		_, _, err = handleProductCreationImages(tx, testUtil.MockDB, testUtil.MockImageStorage, exampleImageInputs, exampleSKU, exampleID)
+		_, _, err = handleProductCreationImages(tx, testUtil.MockDB, pendingImages, exampleID, 0)
 		assert.Error(t, err)
+		// other images might be sharing the files, so they're left for orphaned image collection
+		testUtil.MockImageStorage.AssertNotCalled(t, "DeleteImages", mock.Anything)
+	})
+
+	t.Run("with image the product root already has", func(_t *testing.T) {
+		_t.Parallel()
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.Mock.ExpectBegin()
+
+		testUtil.MockImageStorage.On("CreateThumbnails", mock.Anything).
+			Return(images.ProductImageSet{})
+		pendingImages := prepareTestProductImages(t, testUtil, []models.ProductImageCreationInput{{Type: "base64", Data: smallGreenPNG, IsPrimary: true}})
+
+		existing := models.ProductImage{
+			ID:            10,
+			ProductRootID: exampleID,
+			OriginalURL:   exampleOriginalLocation,
+			Renditions:    exampleRenditions,
+			ContentHash:   pendingImages[0].contentHash,
+			SortOrder:     3,
+		}
+		testUtil.MockDB.On("GetProductImagesByContentHash", mock.AnythingOfType("*sql.Tx"), pendingImages[0].contentHash).
+			Return([]models.ProductImage{{ID: 9, ProductRootID: 2, ContentHash: pendingImages[0].contentHash}, existing}, nil)
+
+		tx, err := testUtil.PlainDB.Begin()
+		assert.NoError(t, err)
+
+		actualImages, actualPrimaryImageID, err := handleProductCreationImages(tx, testUtil.MockDB, pendingImages, exampleID, 5)
+		assert.NoError(t, err)
+		assert.Equal(t, []models.ProductImage{existing}, actualImages)
+		require.NotNil(t, actualPrimaryImageID)
+		assert.Equal(t, existing.ID, *actualPrimaryImageID)
+		testUtil.MockImageStorage.AssertNotCalled(t, "StoreImages", mock.Anything, mock.Anything)
+		testUtil.MockDB.AssertNotCalled(t, "CreateProductImage", mock.Anything, mock.Anything)
+	})
+
+	t.Run("with image another product root already has", func(_t *testing.T) {
+		_t.Parallel()
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.Mock.ExpectBegin()
+
+		testUtil.MockImageStorage.On("CreateThumbnails", mock.Anything).
+			Return(images.ProductImageSet{})
+		pendingImages := prepareTestProductImages(t, testUtil, []models.ProductImageCreationInput{{Type: "base64", Data: smallGreenPNG}})
+
+		existing := models.ProductImage{
+			ID:            10,
+			ProductRootID: 2,
+			ThumbnailURL:  exampleThumbnailLocation,
+			MainURL:       exampleMainLocation,
+			OriginalURL:   exampleOriginalLocation,
+			Renditions:    exampleRenditions,
+			ContentHash:   pendingImages[0].contentHash,
+		}
+		testUtil.MockDB.On("GetProductImagesByContentHash", mock.AnythingOfType("*sql.Tx"), pendingImages[0].contentHash).
+			Return([]models.ProductImage{existing}, nil)
+		// the files are stored again, under the same content hash key, so that they count as recently used
+		testUtil.MockImageStorage.On("StoreImages", mock.Anything, images.ContentKey(existing.ContentHash)).
+			Return(images.ProductImageLocations(exampleRenditions), nil)
+		testUtil.MockDB.On("CreateProductImage", mock.AnythingOfType("*sql.Tx"), mock.MatchedBy(func(img *models.ProductImage) bool {
+			return img.ProductRootID == exampleID && img.OriginalURL == exampleOriginalLocation && img.ContentHash == existing.ContentHash && img.SortOrder == 5
+		})).Return(uint64(11), buildTestTime(), nil)
+
+		require.NoError(t, storeProductImages(testUtil.MockImageStorage, pendingImages))
+		tx, err := testUtil.PlainDB.Begin()
+		assert.NoError(t, err)
+
+		actualImages, _, err := handleProductCreationImages(tx, testUtil.MockDB, pendingImages, exampleID, 5)
+		assert.NoError(t, err)
+		require.Len(t, actualImages, 1)
+		assert.Equal(t, uint64(11), actualImages[0].ID)
+		assert.Equal(t, exampleRenditions, actualImages[0].Renditions)
+		testUtil.MockImageStorage.AssertNumberOfCalls(t, "StoreImages", 1)
+	})
+
+	t.Run("with error looking up existing images", func(_t *testing.T) {
+		_t.Parallel()
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.Mock.ExpectBegin()
+
+		testUtil.MockImageStorage.On("CreateThumbnails", mock.Anything).
+			Return(images.ProductImageSet{})
+		testUtil.MockDB.On("GetProductImagesByContentHash", mock.AnythingOfType("*sql.Tx"), mock.AnythingOfType("string")).
+			Return([]models.ProductImage{}, generateArbitraryError())
+
+		tx, err := testUtil.PlainDB.Begin()
+		assert.NoError(t, err)
+
+		_, _, err = handleProductCreationImages(tx, testUtil.MockDB, prepareTestProductImages(t, testUtil, []models.ProductImageCreationInput{{Type: "base64", Data: smallGreenPNG}}), exampleID, 0)
+		assert.Error(t, err)
+		testUtil.MockImageStorage.AssertNotCalled(t, "StoreImages", mock.Anything, mock.Anything)
+	})
+
+}
+
+func TestProductImageAttachmentHandler(t *testing.T) {
+	exampleProduct := &models.Product{
+		ID:            2,
+		ProductRootID: 1,
+		SKU:           "skateboard",
+	}
+	exampleRootImages := []models.ProductImage{{ID: 10, ProductRootID: 1}, {ID: 11, ProductRootID: 1}}
+	exampleInput := `{"image_ids": [10], "primary_image_id": 11}`
+
+	t.Run("optimal conditions", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetProductBySKU", mock.Anything, exampleProduct.SKU).Return(exampleProduct, nil)
+		testUtil.MockDB.On("GetProductImagesByProductRootID", mock.Anything, exampleProduct.ProductRootID).Return(exampleRootImages, nil)
+		testUtil.Mock.ExpectBegin()
+		testUtil.MockDB.On("CreateMultipleProductImageBridgesForProductID", mock.Anything, exampleProduct.ID, []uint64{10, 11}).Return(nil)
+		testUtil.MockDB.On("SetPrimaryProductImageForProduct", mock.Anything, exampleProduct.ID, uint64(11)).Return(buildTestTime(), nil)
+		testUtil.Mock.ExpectCommit()
+		testUtil.MockDB.On("GetProductImagesByProductID", mock.Anything, exampleProduct.ID).Return(exampleRootImages, nil)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPost, "/v1/product/skateboard/images", strings.NewReader(exampleInput))
+		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+
+		assertStatusCode(t, testUtil, http.StatusOK)
+	})
+
+	t.Run("with invalid input", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPost, "/v1/product/skateboard/images", strings.NewReader(exampleGarbageInput))
+		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+
+		assertStatusCode(t, testUtil, http.StatusBadRequest)
+	})
+
+	t.Run("with nonexistent product", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetProductBySKU", mock.Anything, exampleProduct.SKU).Return(exampleProduct, sql.ErrNoRows)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPost, "/v1/product/skateboard/images", strings.NewReader(exampleInput))
+		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+
+		assertStatusCode(t, testUtil, http.StatusNotFound)
+	})
+
+	t.Run("with image from another product root", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetProductBySKU", mock.Anything, exampleProduct.SKU).Return(exampleProduct, nil)
+		testUtil.MockDB.On("GetProductImagesByProductRootID", mock.Anything, exampleProduct.ProductRootID).Return(exampleRootImages[:1], nil)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPost, "/v1/product/skateboard/images", strings.NewReader(exampleInput))
+		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+
+		assertStatusCode(t, testUtil, http.StatusBadRequest)
+	})
+
+	t.Run("with error attaching images", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetProductBySKU", mock.Anything, exampleProduct.SKU).Return(exampleProduct, nil)
+		testUtil.MockDB.On("GetProductImagesByProductRootID", mock.Anything, exampleProduct.ProductRootID).Return(exampleRootImages, nil)
+		testUtil.Mock.ExpectBegin()
+		testUtil.MockDB.On("CreateMultipleProductImageBridgesForProductID", mock.Anything, exampleProduct.ID, []uint64{10, 11}).Return(generateArbitraryError())
+		testUtil.Mock.ExpectRollback()
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPost, "/v1/product/skateboard/images", strings.NewReader(exampleInput))
+		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+
+		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+	})
+
+	t.Run("with error setting primary image", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetProductBySKU", mock.Anything, exampleProduct.SKU).Return(exampleProduct, nil)
+		testUtil.MockDB.On("GetProductImagesByProductRootID", mock.Anything, exampleProduct.ProductRootID).Return(exampleRootImages, nil)
+		testUtil.Mock.ExpectBegin()
+		testUtil.MockDB.On("CreateMultipleProductImageBridgesForProductID", mock.Anything, exampleProduct.ID, []uint64{10, 11}).Return(nil)
+		testUtil.MockDB.On("SetPrimaryProductImageForProduct", mock.Anything, exampleProduct.ID, uint64(11)).Return(buildTestTime(), generateArbitraryError())
+		testUtil.Mock.ExpectRollback()
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPost, "/v1/product/skateboard/images", strings.NewReader(exampleInput))
+		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+
+		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+	})
+}
+
+func TestProductImageDetachmentHandler(t *testing.T) {
+	exampleProduct := &models.Product{
+		ID:            2,
+		ProductRootID: 1,
+		SKU:           "skateboard",
+	}
+
+	t.Run("optimal conditions", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetProductBySKU", mock.Anything, exampleProduct.SKU).Return(exampleProduct, nil)
+		testUtil.Mock.ExpectBegin()
+		testUtil.MockDB.On("DeleteProductImageBridgeByProductIDAndImageID", mock.Anything, exampleProduct.ID, uint64(10)).Return(buildTestTime(), nil)
+		testUtil.Mock.ExpectCommit()
+		testUtil.MockDB.On("GetProductImagesByProductID", mock.Anything, exampleProduct.ID).Return([]models.ProductImage{}, nil)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodDelete, "/v1/product/skateboard/images/10", nil)
+		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+
+		assertStatusCode(t, testUtil, http.StatusOK)
+	})
+
+	t.Run("with primary image", func(t *testing.T) {
+		primaryImageID := uint64(10)
+		exampleProduct := &models.Product{ID: 2, ProductRootID: 1, SKU: "skateboard", PrimaryImageID: &primaryImageID}
+		remainingImages := []models.ProductImage{{ID: 11, ProductRootID: 1}, {ID: 12, ProductRootID: 1}}
+
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetProductBySKU", mock.Anything, exampleProduct.SKU).Return(exampleProduct, nil)
+		testUtil.Mock.ExpectBegin()
+		testUtil.MockDB.On("DeleteProductImageBridgeByProductIDAndImageID", mock.Anything, exampleProduct.ID, primaryImageID).Return(buildTestTime(), nil)
+		testUtil.MockDB.On("GetProductImagesByProductID", mock.Anything, exampleProduct.ID).Return(remainingImages, nil)
+		testUtil.MockDB.On("SetPrimaryProductImageForProduct", mock.Anything, exampleProduct.ID, uint64(11)).Return(buildTestTime(), nil)
+		testUtil.Mock.ExpectCommit()
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodDelete, "/v1/product/skateboard/images/10", nil)
+		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+
+		assertStatusCode(t, testUtil, http.StatusOK)
+		ensureExpectationsWereMet(t, testUtil.Mock)
+		assert.Contains(t, testUtil.Response.Body.String(), `"primary_image_id":11`)
+	})
+
+	t.Run("with primary image and no other images", func(t *testing.T) {
+		primaryImageID := uint64(10)
+		exampleProduct := &models.Product{ID: 2, ProductRootID: 1, SKU: "skateboard", PrimaryImageID: &primaryImageID}
+
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetProductBySKU", mock.Anything, exampleProduct.SKU).Return(exampleProduct, nil)
+		testUtil.Mock.ExpectBegin()
+		testUtil.MockDB.On("DeleteProductImageBridgeByProductIDAndImageID", mock.Anything, exampleProduct.ID, primaryImageID).Return(buildTestTime(), nil)
+		testUtil.MockDB.On("GetProductImagesByProductID", mock.Anything, exampleProduct.ID).Return([]models.ProductImage{}, nil)
+		testUtil.MockDB.On("ClearPrimaryProductImageForProduct", mock.Anything, exampleProduct.ID).Return(buildTestTime(), nil)
+		testUtil.Mock.ExpectCommit()
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodDelete, "/v1/product/skateboard/images/10", nil)
+		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+
+		assertStatusCode(t, testUtil, http.StatusOK)
+		ensureExpectationsWereMet(t, testUtil.Mock)
+		assert.Contains(t, testUtil.Response.Body.String(), `"primary_image_id":null`)
+	})
+
+	t.Run("with error replacing primary image", func(t *testing.T) {
+		primaryImageID := uint64(10)
+		exampleProduct := &models.Product{ID: 2, ProductRootID: 1, SKU: "skateboard", PrimaryImageID: &primaryImageID}
+
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetProductBySKU", mock.Anything, exampleProduct.SKU).Return(exampleProduct, nil)
+		testUtil.Mock.ExpectBegin()
+		testUtil.MockDB.On("DeleteProductImageBridgeByProductIDAndImageID", mock.Anything, exampleProduct.ID, primaryImageID).Return(buildTestTime(), nil)
+		testUtil.MockDB.On("GetProductImagesByProductID", mock.Anything, exampleProduct.ID).Return([]models.ProductImage{}, nil)
+		testUtil.MockDB.On("ClearPrimaryProductImageForProduct", mock.Anything, exampleProduct.ID).Return(buildTestTime(), generateArbitraryError())
+		testUtil.Mock.ExpectRollback()
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodDelete, "/v1/product/skateboard/images/10", nil)
+		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+
+		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+		ensureExpectationsWereMet(t, testUtil.Mock)
+	})
+
+	t.Run("with image not attached to product", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetProductBySKU", mock.Anything, exampleProduct.SKU).Return(exampleProduct, nil)
+		testUtil.Mock.ExpectBegin()
+		testUtil.MockDB.On("DeleteProductImageBridgeByProductIDAndImageID", mock.Anything, exampleProduct.ID, uint64(10)).Return(buildTestTime(), sql.ErrNoRows)
+		testUtil.Mock.ExpectRollback()
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodDelete, "/v1/product/skateboard/images/10", nil)
+		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+
+		assertStatusCode(t, testUtil, http.StatusNotFound)
+	})
+
+	t.Run("with error detaching image", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetProductBySKU", mock.Anything, exampleProduct.SKU).Return(exampleProduct, nil)
+		testUtil.Mock.ExpectBegin()
+		testUtil.MockDB.On("DeleteProductImageBridgeByProductIDAndImageID", mock.Anything, exampleProduct.ID, uint64(10)).Return(buildTestTime(), generateArbitraryError())
+		testUtil.Mock.ExpectRollback()
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodDelete, "/v1/product/skateboard/images/10", nil)
+		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+
+		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+	})
+}
+
+func TestProductPrimaryImageHandler(t *testing.T) {
+	exampleProduct := &models.Product{
+		ID:            2,
+		ProductRootID: 1,
+		SKU:           "skateboard",
+	}
+	exampleRootImages := []models.ProductImage{{ID: 10, ProductRootID: 1}}
+
+	t.Run("optimal conditions", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetProductBySKU", mock.Anything, exampleProduct.SKU).Return(exampleProduct, nil)
+		testUtil.MockDB.On("GetProductImagesByProductRootID", mock.Anything, exampleProduct.ProductRootID).Return(exampleRootImages, nil)
+		testUtil.Mock.ExpectBegin()
+		testUtil.MockDB.On("CreateMultipleProductImageBridgesForProductID", mock.Anything, exampleProduct.ID, []uint64{10}).Return(nil)
+		testUtil.MockDB.On("SetPrimaryProductImageForProduct", mock.Anything, exampleProduct.ID, uint64(10)).Return(buildTestTime(), nil)
+		testUtil.Mock.ExpectCommit()
+		testUtil.MockDB.On("GetProductImagesByProductID", mock.Anything, exampleProduct.ID).Return(exampleRootImages, nil)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPut, "/v1/product/skateboard/images/10/primary", nil)
+		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+
+		assertStatusCode(t, testUtil, http.StatusOK)
+	})
+
+	t.Run("with image from another product root", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetProductBySKU", mock.Anything, exampleProduct.SKU).Return(exampleProduct, nil)
+		testUtil.MockDB.On("GetProductImagesByProductRootID", mock.Anything, exampleProduct.ProductRootID).Return(exampleRootImages, nil)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPut, "/v1/product/skateboard/images/99/primary", nil)
+		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+
+		assertStatusCode(t, testUtil, http.StatusNotFound)
+	})
+
+	t.Run("with error retrieving root images", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetProductBySKU", mock.Anything, exampleProduct.SKU).Return(exampleProduct, nil)
+		testUtil.MockDB.On("GetProductImagesByProductRootID", mock.Anything, exampleProduct.ProductRootID).Return(exampleRootImages, generateArbitraryError())
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPut, "/v1/product/skateboard/images/10/primary", nil)
+		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+
+		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+	})
+}
+
+func TestProductOptionValueImageAssignmentHandler(t *testing.T) {
+	exampleOptionValue := &models.ProductOptionValue{ID: 3, ProductOptionID: 4, Value: "Red"}
+	exampleOption := &models.ProductOption{ID: 4, ProductRootID: 1, Name: "Color"}
+	exampleRootImages := []models.ProductImage{{ID: 10, ProductRootID: 1}}
+	exampleInput := `{"image_ids": [10], "primary_image_id": 10}`
+
+	t.Run("optimal conditions", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetProductOptionValue", mock.Anything, exampleOptionValue.ID).Return(exampleOptionValue, nil)
+		testUtil.MockDB.On("GetProductOption", mock.Anything, exampleOption.ID).Return(exampleOption, nil)
+		testUtil.MockDB.On("GetProductImagesByProductRootID", mock.Anything, exampleOption.ProductRootID).Return(exampleRootImages, nil)
+		testUtil.MockDB.On("GetProductIDsByOptionValueID", mock.Anything, exampleOptionValue.ID).Return([]uint64{5, 6}, nil)
+		testUtil.Mock.ExpectBegin()
+		testUtil.MockDB.On("CreateMultipleProductImageBridgesForProductID", mock.Anything, mock.Anything, []uint64{10}).Return(nil)
+		testUtil.MockDB.On("SetPrimaryProductImageForProduct", mock.Anything, mock.Anything, uint64(10)).Return(buildTestTime(), nil)
+		testUtil.Mock.ExpectCommit()
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPost, "/v1/product_option_values/3/images", strings.NewReader(exampleInput))
+		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+
+		assertStatusCode(t, testUtil, http.StatusOK)
+		testUtil.MockDB.AssertNumberOfCalls(t, "CreateMultipleProductImageBridgesForProductID", 2)
+	})
+
+	t.Run("with nonexistent option value", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetProductOptionValue", mock.Anything, exampleOptionValue.ID).Return(exampleOptionValue, sql.ErrNoRows)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPost, "/v1/product_option_values/3/images", strings.NewReader(exampleInput))
+		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+
+		assertStatusCode(t, testUtil, http.StatusNotFound)
 	})
 
+	t.Run("with image from another product root", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetProductOptionValue", mock.Anything, exampleOptionValue.ID).Return(exampleOptionValue, nil)
+		testUtil.MockDB.On("GetProductOption", mock.Anything, exampleOption.ID).Return(exampleOption, nil)
+		testUtil.MockDB.On("GetProductImagesByProductRootID", mock.Anything, exampleOption.ProductRootID).Return([]models.ProductImage{}, nil)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPost, "/v1/product_option_values/3/images", strings.NewReader(exampleInput))
+		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+
+		assertStatusCode(t, testUtil, http.StatusBadRequest)
+	})
+
+	t.Run("with error attaching images", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetProductOptionValue", mock.Anything, exampleOptionValue.ID).Return(exampleOptionValue, nil)
+		testUtil.MockDB.On("GetProductOption", mock.Anything, exampleOption.ID).Return(exampleOption, nil)
+		testUtil.MockDB.On("GetProductImagesByProductRootID", mock.Anything, exampleOption.ProductRootID).Return(exampleRootImages, nil)
+		testUtil.MockDB.On("GetProductIDsByOptionValueID", mock.Anything, exampleOptionValue.ID).Return([]uint64{5, 6}, nil)
+		testUtil.Mock.ExpectBegin()
+		testUtil.MockDB.On("CreateMultipleProductImageBridgesForProductID", mock.Anything, mock.Anything, []uint64{10}).Return(generateArbitraryError())
+		testUtil.Mock.ExpectRollback()
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPost, "/v1/product_option_values/3/images", strings.NewReader(exampleInput))
+		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+
+		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+	})
+}
+
+func TestProductRootImageListHandler(t *testing.T) {
+	exampleRootImages := []models.ProductImage{{ID: 10, ProductRootID: 1}, {ID: 11, ProductRootID: 1, SortOrder: 1}}
+
+	t.Run("optimal conditions", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("ProductRootExists", mock.Anything, uint64(1)).Return(true, nil)
+		testUtil.MockDB.On("GetProductImagesByProductRootID", mock.Anything, uint64(1)).Return(exampleRootImages, nil)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodGet, "/v1/product_root/1/images", nil)
+		assert.NoError(t, err)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+
+		assertStatusCode(t, testUtil, http.StatusOK)
+	})
+
+	t.Run("with nonexistent product root", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("ProductRootExists", mock.Anything, uint64(1)).Return(false, nil)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodGet, "/v1/product_root/1/images", nil)
+		assert.NoError(t, err)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+
+		assertStatusCode(t, testUtil, http.StatusNotFound)
+	})
+
+	t.Run("with error retrieving images", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("ProductRootExists", mock.Anything, uint64(1)).Return(true, nil)
+		testUtil.MockDB.On("GetProductImagesByProductRootID", mock.Anything, uint64(1)).Return(exampleRootImages, generateArbitraryError())
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodGet, "/v1/product_root/1/images", nil)
+		assert.NoError(t, err)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+
+		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+	})
+}
+
+func TestProductRootImageCreationHandler(t *testing.T) {
+	exampleInput := fmt.Sprintf(`{"images": [{"type": "base64", "data": "%s"}]}`, smallGreenPNG)
+	exampleLocations := images.ProductImageLocations{
+		images.ThumbnailRendition: "https://dairycart.com/product_images/skate/thumbnails/12.png",
+		images.MainRendition:      "https://dairycart.com/product_images/skate/main/12.png",
+		images.OriginalRendition:  "https://dairycart.com/product_images/skate/original/12.png",
+	}
+
+	greenHash := contentHashForBase64Image(t, smallGreenPNG)
+
+	t.Run("optimal conditions", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		exampleRoot := &models.ProductRoot{ID: 1, SKUPrefix: "skate"}
+		testUtil.MockDB.On("GetProductRoot", mock.Anything, uint64(1)).Return(exampleRoot, nil)
+		testUtil.MockDB.On("GetProductImagesByProductRootID", mock.Anything, uint64(1)).Return([]models.ProductImage{{ID: 10, ProductRootID: 1, SortOrder: 3}}, nil)
+		testUtil.MockImageStorage.On("CreateThumbnails", mock.Anything).Return(images.ProductImageSet{})
+		testUtil.MockImageStorage.On("StoreImages", mock.Anything, images.ContentKey(greenHash)).Return(exampleLocations, nil)
+		testUtil.Mock.ExpectBegin()
+		testUtil.MockDB.On("GetProductImagesByContentHash", mock.Anything, greenHash).Return([]models.ProductImage{}, nil)
+		testUtil.MockDB.On("CreateProductImage", mock.Anything, mock.MatchedBy(func(img *models.ProductImage) bool {
+			return img.SortOrder == 4 && img.ContentHash == greenHash && img.OriginalURL == exampleLocations[images.OriginalRendition]
+		})).Return(uint64(12), buildTestTime(), nil)
+		testUtil.MockDB.On("GetProductsByProductRootID", mock.Anything, uint64(1)).Return([]models.Product{{ID: 2}}, nil)
+		testUtil.MockDB.On("SetPrimaryProductImageForProduct", mock.Anything, uint64(2), uint64(12)).Return(buildTestTime(), nil)
+		testUtil.MockDB.On("UpdateProductRoot", mock.Anything, mock.Anything).Return(buildTestTime(), nil)
+		testUtil.Mock.ExpectCommit()
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, ProductImageCreatedWebhookEvent).Return([]models.Webhook{}, nil)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPost, "/v1/product_root/1/images", strings.NewReader(exampleInput))
+		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+
+		assertStatusCode(t, testUtil, http.StatusCreated)
+		require.NotNil(t, exampleRoot.PrimaryImageID)
+		assert.Equal(t, uint64(12), *exampleRoot.PrimaryImageID)
+	})
+
+	t.Run("with invalid input", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPost, "/v1/product_root/1/images", strings.NewReader(exampleGarbageInput))
+		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+
+		assertStatusCode(t, testUtil, http.StatusBadRequest)
+	})
+
+	t.Run("with nonexistent product root", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetProductRoot", mock.Anything, uint64(1)).Return(&models.ProductRoot{}, sql.ErrNoRows)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPost, "/v1/product_root/1/images", strings.NewReader(exampleInput))
+		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+
+		assertStatusCode(t, testUtil, http.StatusNotFound)
+	})
+
+	t.Run("with error storing images", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetProductRoot", mock.Anything, uint64(1)).Return(&models.ProductRoot{ID: 1, SKUPrefix: "skate"}, nil)
+		testUtil.MockDB.On("GetProductImagesByProductRootID", mock.Anything, uint64(1)).Return([]models.ProductImage{}, nil)
+		testUtil.MockImageStorage.On("CreateThumbnails", mock.Anything).Return(images.ProductImageSet{})
+		testUtil.MockImageStorage.On("StoreImages", mock.Anything, images.ContentKey(greenHash)).Return(images.ProductImageLocations{}, generateArbitraryError())
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPost, "/v1/product_root/1/images", strings.NewReader(exampleInput))
+		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+
+		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+		// nothing was begun, so there's nothing to roll back
+		ensureExpectationsWereMet(t, testUtil.Mock)
+		testUtil.MockDB.AssertNotCalled(t, "CreateProductImage", mock.Anything, mock.Anything)
+	})
+
+	t.Run("with error creating image", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetProductRoot", mock.Anything, uint64(1)).Return(&models.ProductRoot{ID: 1, SKUPrefix: "skate"}, nil)
+		testUtil.MockDB.On("GetProductImagesByProductRootID", mock.Anything, uint64(1)).Return([]models.ProductImage{}, nil)
+		testUtil.MockImageStorage.On("CreateThumbnails", mock.Anything).Return(images.ProductImageSet{})
+		testUtil.MockImageStorage.On("StoreImages", mock.Anything, images.ContentKey(greenHash)).Return(exampleLocations, nil)
+		testUtil.Mock.ExpectBegin()
+		testUtil.MockDB.On("GetProductImagesByContentHash", mock.Anything, greenHash).Return([]models.ProductImage{}, nil)
+		testUtil.MockDB.On("CreateProductImage", mock.Anything, mock.Anything).Return(uint64(0), buildTestTime(), generateArbitraryError())
+		testUtil.Mock.ExpectRollback()
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPost, "/v1/product_root/1/images", strings.NewReader(exampleInput))
+		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+
+		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+		testUtil.MockImageStorage.AssertNotCalled(t, "DeleteImages", mock.Anything)
+	})
+
+	t.Run("with image the product root already has", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		existingImage := models.ProductImage{ID: 10, ProductRootID: 1, SortOrder: 3, ContentHash: greenHash}
+		exampleRoot := &models.ProductRoot{ID: 1, SKUPrefix: "skate", PrimaryImageID: &existingImage.ID}
+		testUtil.MockDB.On("GetProductRoot", mock.Anything, uint64(1)).Return(exampleRoot, nil)
+		testUtil.MockDB.On("GetProductImagesByProductRootID", mock.Anything, uint64(1)).Return([]models.ProductImage{existingImage}, nil)
+		testUtil.MockImageStorage.On("CreateThumbnails", mock.Anything).Return(images.ProductImageSet{})
+		// it's stored before the transaction can tell it's already there, which only refreshes the files
+		testUtil.MockImageStorage.On("StoreImages", mock.Anything, images.ContentKey(greenHash)).Return(images.ProductImageLocations{}, nil)
+		testUtil.Mock.ExpectBegin()
+		testUtil.MockDB.On("GetProductImagesByContentHash", mock.Anything, greenHash).Return([]models.ProductImage{existingImage}, nil)
+		testUtil.Mock.ExpectCommit()
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, ProductImageCreatedWebhookEvent).Return([]models.Webhook{}, nil)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPost, "/v1/product_root/1/images", strings.NewReader(exampleInput))
+		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+
+		assertStatusCode(t, testUtil, http.StatusCreated)
+		testUtil.MockDB.AssertNotCalled(t, "CreateProductImage", mock.Anything, mock.Anything)
+	})
+}
+
+func TestProductRootPrimaryImageHandler(t *testing.T) {
+	previousPrimaryImageID := uint64(10)
+
+	t.Run("optimal conditions", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		exampleRoot := &models.ProductRoot{ID: 1, PrimaryImageID: &previousPrimaryImageID}
+		customPrimaryImageID := uint64(13)
+		exampleProducts := []models.Product{
+			{ID: 2, PrimaryImageID: &previousPrimaryImageID},
+			{ID: 3, PrimaryImageID: &customPrimaryImageID},
+			{ID: 4},
+		}
+		testUtil.MockDB.On("GetProductRoot", mock.Anything, uint64(1)).Return(exampleRoot, nil)
+		testUtil.MockDB.On("GetProductImage", mock.Anything, uint64(11)).Return(&models.ProductImage{ID: 11, ProductRootID: 1}, nil)
+		testUtil.Mock.ExpectBegin()
+		testUtil.MockDB.On("GetProductsByProductRootID", mock.Anything, uint64(1)).Return(exampleProducts, nil)
+		testUtil.MockDB.On("SetPrimaryProductImageForProduct", mock.Anything, uint64(2), uint64(11)).Return(buildTestTime(), nil)
+		testUtil.MockDB.On("SetPrimaryProductImageForProduct", mock.Anything, uint64(4), uint64(11)).Return(buildTestTime(), nil)
+		testUtil.MockDB.On("UpdateProductRoot", mock.Anything, mock.Anything).Return(buildTestTime(), nil)
+		testUtil.Mock.ExpectCommit()
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPut, "/v1/product_root/1/images/11/primary", nil)
+		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+
+		assertStatusCode(t, testUtil, http.StatusOK)
+		// the product with a primary image of its own keeps it
+		for _, args := range mockCallsTo(&testUtil.MockDB.Mock, "SetPrimaryProductImageForProduct") {
+			assert.NotEqual(t, uint64(3), args.Get(1))
+		}
+	})
+
+	t.Run("with image from another product root", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetProductRoot", mock.Anything, uint64(1)).Return(&models.ProductRoot{ID: 1}, nil)
+		testUtil.MockDB.On("GetProductImage", mock.Anything, uint64(11)).Return(&models.ProductImage{ID: 11, ProductRootID: 2}, nil)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPut, "/v1/product_root/1/images/11/primary", nil)
+		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+
+		assertStatusCode(t, testUtil, http.StatusNotFound)
+	})
+
+	t.Run("with error updating product root", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetProductRoot", mock.Anything, uint64(1)).Return(&models.ProductRoot{ID: 1}, nil)
+		testUtil.MockDB.On("GetProductImage", mock.Anything, uint64(11)).Return(&models.ProductImage{ID: 11, ProductRootID: 1}, nil)
+		testUtil.Mock.ExpectBegin()
+		testUtil.MockDB.On("GetProductsByProductRootID", mock.Anything, uint64(1)).Return([]models.Product{}, nil)
+		testUtil.MockDB.On("UpdateProductRoot", mock.Anything, mock.Anything).Return(buildTestTime(), generateArbitraryError())
+		testUtil.Mock.ExpectRollback()
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPut, "/v1/product_root/1/images/11/primary", nil)
+		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+
+		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+	})
+}
+
+func TestProductRootImageOrderHandler(t *testing.T) {
+	exampleRootImages := []models.ProductImage{{ID: 10, ProductRootID: 1}, {ID: 11, ProductRootID: 1, SortOrder: 1}}
+
+	t.Run("optimal conditions", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("ProductRootExists", mock.Anything, uint64(1)).Return(true, nil)
+		testUtil.MockDB.On("GetProductImagesByProductRootID", mock.Anything, uint64(1)).Return(exampleRootImages, nil)
+		testUtil.Mock.ExpectBegin()
+		testUtil.MockDB.On("UpdateProductImage", mock.Anything, mock.MatchedBy(func(img *models.ProductImage) bool {
+			return img.ID == 11 && img.SortOrder == 0
+		})).Return(buildTestTime(), nil)
+		testUtil.MockDB.On("UpdateProductImage", mock.Anything, mock.MatchedBy(func(img *models.ProductImage) bool {
+			return img.ID == 10 && img.SortOrder == 1
+		})).Return(buildTestTime(), nil)
+		testUtil.Mock.ExpectCommit()
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPut, "/v1/product_root/1/images/order", strings.NewReader(`{"image_ids": [11, 10]}`))
+		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+
+		assertStatusCode(t, testUtil, http.StatusOK)
+	})
+
+	t.Run("with missing image", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("ProductRootExists", mock.Anything, uint64(1)).Return(true, nil)
+		testUtil.MockDB.On("GetProductImagesByProductRootID", mock.Anything, uint64(1)).Return(exampleRootImages, nil)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPut, "/v1/product_root/1/images/order", strings.NewReader(`{"image_ids": [11]}`))
+		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+
+		assertStatusCode(t, testUtil, http.StatusBadRequest)
+	})
+
+	t.Run("with duplicate image", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("ProductRootExists", mock.Anything, uint64(1)).Return(true, nil)
+		testUtil.MockDB.On("GetProductImagesByProductRootID", mock.Anything, uint64(1)).Return(exampleRootImages, nil)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPut, "/v1/product_root/1/images/order", strings.NewReader(`{"image_ids": [11, 11]}`))
+		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+
+		assertStatusCode(t, testUtil, http.StatusBadRequest)
+	})
+
+	t.Run("with error updating image", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("ProductRootExists", mock.Anything, uint64(1)).Return(true, nil)
+		testUtil.MockDB.On("GetProductImagesByProductRootID", mock.Anything, uint64(1)).Return(exampleRootImages, nil)
+		testUtil.Mock.ExpectBegin()
+		testUtil.MockDB.On("UpdateProductImage", mock.Anything, mock.Anything).Return(buildTestTime(), generateArbitraryError())
+		testUtil.Mock.ExpectRollback()
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPut, "/v1/product_root/1/images/order", strings.NewReader(`{"image_ids": [11, 10]}`))
+		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+
+		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+	})
+}
+
+func TestProductRootImageReplacementHandler(t *testing.T) {
+	exampleInput := fmt.Sprintf(`{"type": "base64", "data": "%s"}`, smallGreenPNG)
+	exampleLocations := images.ProductImageLocations{
+		images.ThumbnailRendition: "https://dairycart.com/product_images/skate/thumbnails/10.png",
+		images.MainRendition:      "https://dairycart.com/product_images/skate/main/10.png",
+		images.OriginalRendition:  "https://dairycart.com/product_images/skate/original/10.png",
+	}
+	buildExistingImage := func() *models.ProductImage {
+		return &models.ProductImage{
+			ID:            10,
+			ProductRootID: 1,
+			ThumbnailURL:  exampleLocations[images.ThumbnailRendition],
+			MainURL:       exampleLocations[images.MainRendition],
+			OriginalURL:   exampleLocations[images.OriginalRendition],
+		}
+	}
+
+	greenHash := contentHashForBase64Image(t, smallGreenPNG)
+
+	t.Run("optimal conditions", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.Mock.ExpectBegin()
+		testUtil.Mock.ExpectCommit()
+		testUtil.MockDB.On("GetProductRoot", mock.Anything, uint64(1)).Return(&models.ProductRoot{ID: 1, SKUPrefix: "skate"}, nil)
+		testUtil.MockDB.On("GetProductImage", mock.Anything, uint64(10)).Return(buildExistingImage(), nil)
+		testUtil.MockImageStorage.On("CreateThumbnails", mock.Anything).Return(images.ProductImageSet{})
+		testUtil.MockImageStorage.On("StoreImages", mock.Anything, images.ContentKey(greenHash)).Return(exampleLocations, nil)
+		testUtil.MockDB.On("UpdateProductImage", mock.Anything, mock.Anything).Return(buildTestTime(), nil)
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, ProductImageUpdatedWebhookEvent).Return([]models.Webhook{}, nil)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPut, "/v1/product_root/1/images/10", strings.NewReader(exampleInput))
+		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+
+		assertStatusCode(t, testUtil, http.StatusOK)
+		testUtil.MockImageStorage.AssertNotCalled(t, "DeleteImages", mock.Anything)
+	})
+
+	t.Run("with changed rendition locations", func(*testing.T) {
+		newLocations := images.ProductImageLocations{
+			images.ThumbnailRendition: "https://dairycart.com/product_images/skate/thumbnails/10.jpg",
+			images.MainRendition:      exampleLocations[images.MainRendition],
+			images.OriginalRendition:  exampleLocations[images.OriginalRendition],
+		}
+
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.Mock.ExpectBegin()
+		testUtil.Mock.ExpectCommit()
+		testUtil.MockDB.On("GetProductRoot", mock.Anything, uint64(1)).Return(&models.ProductRoot{ID: 1, SKUPrefix: "skate"}, nil)
+		testUtil.MockDB.On("GetProductImage", mock.Anything, uint64(10)).Return(buildExistingImage(), nil)
+		testUtil.MockImageStorage.On("CreateThumbnails", mock.Anything).Return(images.ProductImageSet{})
+		testUtil.MockImageStorage.On("StoreImages", mock.Anything, images.ContentKey(greenHash)).Return(newLocations, nil)
+		testUtil.MockDB.On("UpdateProductImage", mock.Anything, mock.Anything).Return(buildTestTime(), nil)
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, ProductImageUpdatedWebhookEvent).Return([]models.Webhook{}, nil)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPut, "/v1/product_root/1/images/10", strings.NewReader(exampleInput))
+		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+
+		assertStatusCode(t, testUtil, http.StatusOK)
+		// the old thumbnail is left for orphaned image collection, since other images may be sharing it
+		testUtil.MockImageStorage.AssertNotCalled(t, "DeleteImages", mock.Anything)
+	})
+
+	t.Run("with nonexistent image", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetProductRoot", mock.Anything, uint64(1)).Return(&models.ProductRoot{ID: 1, SKUPrefix: "skate"}, nil)
+		testUtil.MockDB.On("GetProductImage", mock.Anything, uint64(10)).Return(&models.ProductImage{}, sql.ErrNoRows)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPut, "/v1/product_root/1/images/10", strings.NewReader(exampleInput))
+		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+
+		assertStatusCode(t, testUtil, http.StatusNotFound)
+	})
+
+	t.Run("with invalid image data", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetProductRoot", mock.Anything, uint64(1)).Return(&models.ProductRoot{ID: 1, SKUPrefix: "skate"}, nil)
+		testUtil.MockDB.On("GetProductImage", mock.Anything, uint64(10)).Return(buildExistingImage(), nil)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPut, "/v1/product_root/1/images/10", strings.NewReader(`{"type": "carrier pigeon", "data": "coo"}`))
+		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+
+		assertStatusCode(t, testUtil, http.StatusBadRequest)
+	})
+
+	t.Run("with error updating image", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.Mock.ExpectBegin()
+		testUtil.Mock.ExpectRollback()
+		testUtil.MockDB.On("GetProductRoot", mock.Anything, uint64(1)).Return(&models.ProductRoot{ID: 1, SKUPrefix: "skate"}, nil)
+		testUtil.MockDB.On("GetProductImage", mock.Anything, uint64(10)).Return(buildExistingImage(), nil)
+		testUtil.MockImageStorage.On("CreateThumbnails", mock.Anything).Return(images.ProductImageSet{})
+		testUtil.MockImageStorage.On("StoreImages", mock.Anything, images.ContentKey(greenHash)).Return(exampleLocations, nil)
+		testUtil.MockDB.On("UpdateProductImage", mock.Anything, mock.Anything).Return(buildTestTime(), generateArbitraryError())
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPut, "/v1/product_root/1/images/10", strings.NewReader(exampleInput))
+		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+
+		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+	})
+
+	t.Run("with picture another image already has", func(*testing.T) {
+		otherLocations := images.ProductImageLocations{
+			images.ThumbnailRendition: "https://dairycart.com/product_images/ab/abcd/thumbnail.png",
+			images.OriginalRendition:  "https://dairycart.com/product_images/ab/abcd/original.png",
+		}
+
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.Mock.ExpectBegin()
+		testUtil.Mock.ExpectCommit()
+		testUtil.MockDB.On("GetProductRoot", mock.Anything, uint64(1)).Return(&models.ProductRoot{ID: 1, SKUPrefix: "skate"}, nil)
+		testUtil.MockDB.On("GetProductImage", mock.Anything, uint64(10)).Return(buildExistingImage(), nil)
+		testUtil.MockImageStorage.On("CreateThumbnails", mock.Anything).Return(images.ProductImageSet{})
+		// files are stored by content hash, so storing the picture again puts it where the other image's files are
+		testUtil.MockImageStorage.On("StoreImages", mock.Anything, images.ContentKey(greenHash)).Return(otherLocations, nil)
+		testUtil.MockDB.On("UpdateProductImage", mock.Anything, mock.MatchedBy(func(img *models.ProductImage) bool {
+			return img.OriginalURL == otherLocations[images.OriginalRendition] && img.ContentHash == greenHash && img.PerceptualHash != ""
+		})).Return(buildTestTime(), nil)
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, ProductImageUpdatedWebhookEvent).Return([]models.Webhook{}, nil)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPut, "/v1/product_root/1/images/10", strings.NewReader(exampleInput))
+		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+
+		assertStatusCode(t, testUtil, http.StatusOK)
+		testUtil.MockDB.AssertNotCalled(t, "GetProductImagesByContentHash", mock.Anything, mock.Anything)
+		testUtil.MockImageStorage.AssertNotCalled(t, "DeleteImages", mock.Anything)
+	})
+}
+
+func TestProductRootImageDeletionHandler(t *testing.T) {
+	primaryImageID := uint64(10)
+	exampleImage := &models.ProductImage{
+		ID:            11,
+		ProductRootID: 1,
+		ThumbnailURL:  "https://dairycart.com/product_images/skate/thumbnails/11.png",
+		MainURL:       "https://dairycart.com/product_images/skate/main/11.png",
+		OriginalURL:   "https://dairycart.com/product_images/skate/original/11.png",
+	}
+
+	t.Run("optimal conditions", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.Mock.ExpectBegin()
+		testUtil.Mock.ExpectCommit()
+		testUtil.MockDB.On("GetProductRoot", mock.Anything, uint64(1)).Return(&models.ProductRoot{ID: 1, PrimaryImageID: &primaryImageID}, nil)
+		testUtil.MockDB.On("GetProductImage", mock.Anything, uint64(11)).Return(exampleImage, nil)
+		testUtil.MockDB.On("GetProductsByProductRootID", mock.Anything, uint64(1)).Return([]models.Product{{ID: 2, PrimaryImageID: &primaryImageID}}, nil)
+		testUtil.MockDB.On("DeleteProductImage", mock.Anything, uint64(11)).Return(buildTestTime(), nil)
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, ProductImageArchivedWebhookEvent).Return([]models.Webhook{}, nil)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodDelete, "/v1/product_root/1/images/11", nil)
+		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+
+		assertStatusCode(t, testUtil, http.StatusOK)
+		// the files are left for orphaned image collection, since other images may be sharing them
+		testUtil.MockImageStorage.AssertNotCalled(t, "DeleteImages", mock.Anything)
+	})
+
+	t.Run("with product root primary image", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetProductRoot", mock.Anything, uint64(1)).Return(&models.ProductRoot{ID: 1, PrimaryImageID: &exampleImage.ID}, nil)
+		testUtil.MockDB.On("GetProductImage", mock.Anything, uint64(11)).Return(exampleImage, nil)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodDelete, "/v1/product_root/1/images/11", nil)
+		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+
+		assertStatusCode(t, testUtil, http.StatusBadRequest)
+	})
+
+	t.Run("with product primary image", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetProductRoot", mock.Anything, uint64(1)).Return(&models.ProductRoot{ID: 1, PrimaryImageID: &primaryImageID}, nil)
+		testUtil.MockDB.On("GetProductImage", mock.Anything, uint64(11)).Return(exampleImage, nil)
+		testUtil.MockDB.On("GetProductsByProductRootID", mock.Anything, uint64(1)).Return([]models.Product{{ID: 2, SKU: "skate-red", PrimaryImageID: &exampleImage.ID}}, nil)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodDelete, "/v1/product_root/1/images/11", nil)
+		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+
+		assertStatusCode(t, testUtil, http.StatusBadRequest)
+	})
+
+	t.Run("with error archiving image", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.Mock.ExpectBegin()
+		testUtil.Mock.ExpectRollback()
+		testUtil.MockDB.On("GetProductRoot", mock.Anything, uint64(1)).Return(&models.ProductRoot{ID: 1}, nil)
+		testUtil.MockDB.On("GetProductImage", mock.Anything, uint64(11)).Return(exampleImage, nil)
+		testUtil.MockDB.On("GetProductsByProductRootID", mock.Anything, uint64(1)).Return([]models.Product{}, nil)
+		testUtil.MockDB.On("DeleteProductImage", mock.Anything, uint64(11)).Return(buildTestTime(), generateArbitraryError())
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodDelete, "/v1/product_root/1/images/11", nil)
+		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+
+		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+	})
+}
+
+func TestLoadImage(t *testing.T) {
+	t.Parallel()
+
+	img := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	encoders := map[string]func(*bytes.Buffer) error{
+		"png":  func(b *bytes.Buffer) error { return png.Encode(b, img) },
+		"jpeg": func(b *bytes.Buffer) error { return jpeg.Encode(b, img, nil) },
+		"gif":  func(b *bytes.Buffer) error { return gif.Encode(b, img, nil) },
+	}
+
+	for expectedFormat, encode := range encoders {
+		buf := &bytes.Buffer{}
+		require.NoError(t, encode(buf))
+
+		actual, actualFormat, err := loadImage(buf, images.DefaultMaxPixels)
+		assert.NoError(t, err)
+		assert.Equal(t, expectedFormat, actualFormat)
+		assert.Equal(t, img.Bounds(), actual.Bounds())
+	}
+
+	_, _, err := loadImage(strings.NewReader("not an image"), images.DefaultMaxPixels)
+	assert.Error(t, err)
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, png.Encode(buf, img))
+	_, _, err = loadImage(buf, 7)
+	assert.Error(t, err, "images with too many pixels should be rejected")
+
+	// a GIF header claiming to be 65535x65535, with nothing after it
+	bomb := []byte("GIF89a\xff\xff\xff\xff\x00\x00\x00")
+	_, _, err = loadImage(bytes.NewReader(bomb), images.DefaultMaxPixels)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "65535x65535")
+}
+
+func TestForEachConcurrently(t *testing.T) {
+	t.Parallel()
+
+	t.Run("optimal conditions", func(_t *testing.T) {
+		var (
+			running, maxRunning int32
+			calls               = make([]bool, 25)
+		)
+		err := forEachConcurrently(len(calls), func(i int) error {
+			now := atomic.AddInt32(&running, 1)
+			for {
+				max := atomic.LoadInt32(&maxRunning)
+				if now <= max || atomic.CompareAndSwapInt32(&maxRunning, max, now) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			calls[i] = true
+			atomic.AddInt32(&running, -1)
+			return nil
+		})
+
+		assert.NoError(t, err)
+		for i, called := range calls {
+			assert.True(t, called, "index %d should have been called", i)
+		}
+		assert.True(t, int(maxRunning) <= maxConcurrentImageJobs, "no more than %d calls should run at once", maxConcurrentImageJobs)
+	})
+
+	t.Run("with errors", func(_t *testing.T) {
+		err := forEachConcurrently(3, func(i int) error {
+			if i == 0 {
+				return nil
+			}
+			return fmt.Errorf("error at %d", i)
+		})
+		assert.EqualError(t, err, "error at 1")
+	})
+}
+
+func TestPrepareProductImages(t *testing.T) {
+	t.Parallel()
+
+	handlers := map[string]http.HandlerFunc{
+		"/cool.png": func(res http.ResponseWriter, req *http.Request) {
+			png.Encode(res, image.NewRGBA(image.Rect(0, 0, 2, 2)))
+		},
+		"/green.png": func(res http.ResponseWriter, req *http.Request) {
+			data, _ := base64.StdEncoding.DecodeString(smallGreenPNG)
+			res.Write(data)
+		},
+	}
+	ts := httptest.NewServer(handlerGenerator(handlers))
+	defer ts.Close()
+
+	testUtil := setupTestVariablesWithMock(t)
+	testUtil.MockImageStorage.On("CreateThumbnails", mock.Anything).Return(images.ProductImageSet{})
+
+	inputs := []models.ProductImageCreationInput{
+		{Type: "base64", Data: smallGreenPNG},
+		{Type: "url", Data: fmt.Sprintf("%s/cool.png", ts.URL)},
+		// the same picture as the first input, just from somewhere else
+		{Type: "url", Data: fmt.Sprintf("%s/green.png", ts.URL), IsPrimary: true},
+		{Type: "base64", Data: smallGreenPNG},
+	}
+	uploads := []pendingProductImage{{image: image.NewRGBA(image.Rect(0, 0, 1, 1)), format: images.GIF}}
+
+	actual, err := prepareProductImages(testUtil.MockImageStorage, buildTestImageFetcher(), UploadLimits{}, inputs, uploads)
+	require.NoError(t, err)
+	require.Len(t, actual, 3)
+
+	assert.Empty(t, actual[0].sourceURL)
+	assert.True(t, actual[0].isPrimary, "duplicates should pass being primary along to the image that's kept")
+	assert.Equal(t, contentHashForBase64Image(t, smallGreenPNG), actual[0].contentHash)
+	assert.Equal(t, inputs[1].Data, actual[1].sourceURL)
+	assert.False(t, actual[1].isPrimary)
+	assert.Equal(t, images.GIF, actual[2].format)
+	for _, pi := range actual {
+		assert.Equal(t, pi.format, pi.renditions.Format, "every image should be rendered")
+		assert.NotEmpty(t, pi.contentHash, "every image should be hashed")
+		assert.Len(t, pi.perceptualHash, 16, "every image should be hashed")
+	}
 }
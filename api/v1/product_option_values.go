@@ -36,15 +36,39 @@ func buildProductOptionValueUpdateHandler(db *sql.DB, client database.Storer) ht
 			notifyOfInternalIssue(res, err, "retrieve product option value from database")
 			return
 		}
+		previous := *existingOptionValue
 		existingOptionValue.Value = updatedValueData.Value
+		if updatedValueData.Abbreviation != "" {
+			existingOptionValue.Abbreviation = updatedValueData.Abbreviation
+		}
 
-		updatedOn, err := client.UpdateProductOptionValue(db, existingOptionValue)
+		tx, err := db.Begin()
 		if err != nil {
+			notifyOfInternalIssue(res, err, "starting a transaction")
+			return
+		}
+
+		updatedOn, err := client.UpdateProductOptionValue(tx, existingOptionValue)
+		if err != nil {
+			tx.Rollback()
 			notifyOfInternalIssue(res, err, "update product option value in the database")
 			return
 		}
 		existingOptionValue.UpdatedOn = &models.Dairytime{Time: updatedOn}
 
+		err = enqueueWebhookChangeDeliveries(tx, client, ProductOptionValueUpdatedWebhookEvent, &previous, existingOptionValue)
+		if err != nil {
+			tx.Rollback()
+			notifyOfInternalIssue(res, err, "queue webhook deliveries")
+			return
+		}
+
+		err = tx.Commit()
+		if err != nil {
+			notifyOfInternalIssue(res, err, "close out transaction")
+			return
+		}
+
 		json.NewEncoder(res).Encode(existingOptionValue)
 	}
 }
@@ -97,6 +121,13 @@ func buildProductOptionValueCreationHandler(db *sql.DB, client database.Storer)
 			return
 		}
 
+		err = enqueueWebhookDeliveries(tx, client, ProductOptionValueCreatedWebhookEvent, newValue)
+		if err != nil {
+			tx.Rollback()
+			notifyOfInternalIssue(res, err, "queue webhook deliveries")
+			return
+		}
+
 		err = tx.Commit()
 		if err != nil {
 			notifyOfInternalIssue(res, err, "close out transaction")
@@ -125,13 +156,33 @@ func buildProductOptionValueDeletionHandler(db *sql.DB, client database.Storer)
 			return
 		}
 
-		archivedOn, err := client.DeleteProductOptionValue(db, optionValueID)
+		tx, err := db.Begin()
 		if err != nil {
-			notifyOfInternalIssue(res, err, "close out transaction")
+			notifyOfInternalIssue(res, err, "starting a transaction")
+			return
+		}
+
+		archivedOn, err := client.DeleteProductOptionValue(tx, optionValueID)
+		if err != nil {
+			tx.Rollback()
+			notifyOfInternalIssue(res, err, "archive product option value in database")
 			return
 		}
 		optionValue.ArchivedOn = &models.Dairytime{Time: archivedOn}
 
+		err = enqueueWebhookDeliveries(tx, client, ProductOptionValueArchivedWebhookEvent, optionValue)
+		if err != nil {
+			tx.Rollback()
+			notifyOfInternalIssue(res, err, "queue webhook deliveries")
+			return
+		}
+
+		err = tx.Commit()
+		if err != nil {
+			notifyOfInternalIssue(res, err, "close out transaction")
+			return
+		}
+
 		json.NewEncoder(res).Encode(optionValue)
 	}
 }
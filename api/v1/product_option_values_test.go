@@ -44,14 +44,19 @@ func TestProductOptionValueCreationHandler(t *testing.T) {
 		testUtil.MockDB.On("CreateProductOptionValue", mock.Anything, mock.Anything).
 			Return(exampleProductOptionValue.ID, buildTestTime(), nil)
 		testUtil.Mock.ExpectCommit()
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, ProductOptionValueCreatedWebhookEvent).
+			Return([]models.Webhook{}, nil)
 		config := buildServerConfigFromTestUtil(testUtil)
 		SetupAPIRouter(config)
 
 		req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/v1/product_options/%d/value", exampleProductOption.ID), strings.NewReader(exampleProductOptionValueCreationBody))
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusCreated)
+		ensureExpectationsWereMet(t, testUtil.Mock)
 	})
 
 	t.Run("with invalid input", func(*testing.T) {
@@ -61,6 +66,7 @@ func TestProductOptionValueCreationHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/v1/product_options/%d/value", exampleProductOption.ID), strings.NewReader(exampleGarbageInput))
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusBadRequest)
 	})
@@ -75,6 +81,7 @@ func TestProductOptionValueCreationHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/v1/product_options/%d/value", exampleProductOption.ID), strings.NewReader(exampleProductOptionValueCreationBody))
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusNotFound)
 	})
@@ -89,6 +96,7 @@ func TestProductOptionValueCreationHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/v1/product_options/%d/value", exampleProductOption.ID), strings.NewReader(exampleProductOptionValueCreationBody))
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusInternalServerError)
 	})
@@ -105,6 +113,7 @@ func TestProductOptionValueCreationHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/v1/product_options/%d/value", exampleProductOption.ID), strings.NewReader(exampleProductOptionValueCreationBody))
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusBadRequest)
 	})
@@ -121,6 +130,7 @@ func TestProductOptionValueCreationHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/v1/product_options/%d/value", exampleProductOption.ID), strings.NewReader(exampleProductOptionValueCreationBody))
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusInternalServerError)
 	})
@@ -138,6 +148,7 @@ func TestProductOptionValueCreationHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/v1/product_options/%d/value", exampleProductOption.ID), strings.NewReader(exampleProductOptionValueCreationBody))
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusInternalServerError)
 	})
@@ -158,6 +169,7 @@ func TestProductOptionValueCreationHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/v1/product_options/%d/value", exampleProductOption.ID), strings.NewReader(exampleProductOptionValueCreationBody))
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusInternalServerError)
 	})
@@ -172,16 +184,45 @@ func TestProductOptionValueCreationHandler(t *testing.T) {
 		testUtil.MockDB.On("CreateProductOptionValue", mock.Anything, mock.Anything).
 			Return(exampleProductOptionValue.ID, buildTestTime(), nil)
 		testUtil.Mock.ExpectCommit().WillReturnError(generateArbitraryError())
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, ProductOptionValueCreatedWebhookEvent).
+			Return([]models.Webhook{}, nil)
 		config := buildServerConfigFromTestUtil(testUtil)
 		SetupAPIRouter(config)
 
 		req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/v1/product_options/%d/value", exampleProductOption.ID), strings.NewReader(exampleProductOptionValueCreationBody))
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+		ensureExpectationsWereMet(t, testUtil.Mock)
 	})
 
+	t.Run("with error queueing webhook deliveries", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("ProductOptionExists", mock.Anything, exampleProductOption.ID).
+			Return(true, nil)
+		testUtil.MockDB.On("ProductOptionValueForOptionIDExists", mock.Anything, exampleProductOption.ID, exampleProductOptionValue.Value).
+			Return(false, nil)
+		testUtil.Mock.ExpectBegin()
+		testUtil.MockDB.On("CreateProductOptionValue", mock.Anything, mock.Anything).
+			Return(exampleProductOptionValue.ID, buildTestTime(), nil)
+		testUtil.Mock.ExpectRollback()
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, ProductOptionValueCreatedWebhookEvent).
+			Return([]models.Webhook{}, generateArbitraryError())
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/v1/product_options/%d/value", exampleProductOption.ID), strings.NewReader(exampleProductOptionValueCreationBody))
+		assert.NoError(t, err)
+
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+		ensureExpectationsWereMet(t, testUtil.Mock)
+	})
 }
 
 func TestProductOptionValueUpdateHandler(t *testing.T) {
@@ -195,18 +236,25 @@ func TestProductOptionValueUpdateHandler(t *testing.T) {
 
 	t.Run("optimal conditions", func(*testing.T) {
 		testUtil := setupTestVariablesWithMock(t)
+		testUtil.Mock.ExpectBegin()
+		testUtil.Mock.ExpectCommit()
 		testUtil.MockDB.On("GetProductOptionValue", mock.Anything, exampleProductOptionValue.ID).
 			Return(exampleProductOptionValue, nil)
 		testUtil.MockDB.On("UpdateProductOptionValue", mock.Anything, mock.Anything).
 			Return(buildTestTime(), nil)
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, ProductOptionValueUpdatedWebhookEvent).
+			Return([]models.Webhook{}, nil)
 		config := buildServerConfigFromTestUtil(testUtil)
 		SetupAPIRouter(config)
 
 		req, err := http.NewRequest(http.MethodPatch, fmt.Sprintf("/v1/product_option_values/%d", exampleProductOptionValue.ID), strings.NewReader(exampleProductOptionValueUpdateBody))
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusOK)
+		ensureExpectationsWereMet(t, testUtil.Mock)
 	})
 
 	t.Run("with invalid input", func(*testing.T) {
@@ -217,6 +265,7 @@ func TestProductOptionValueUpdateHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodPatch, fmt.Sprintf("/v1/product_option_values/%d", exampleProductOptionValue.ID), strings.NewReader(exampleGarbageInput))
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusBadRequest)
 	})
@@ -231,6 +280,7 @@ func TestProductOptionValueUpdateHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodPatch, fmt.Sprintf("/v1/product_option_values/%d", exampleProductOptionValue.ID), strings.NewReader(exampleProductOptionValueUpdateBody))
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusNotFound)
 	})
@@ -245,12 +295,15 @@ func TestProductOptionValueUpdateHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodPatch, fmt.Sprintf("/v1/product_option_values/%d", exampleProductOptionValue.ID), strings.NewReader(exampleProductOptionValueUpdateBody))
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusInternalServerError)
 	})
 
 	t.Run("with error updating option value", func(*testing.T) {
 		testUtil := setupTestVariablesWithMock(t)
+		testUtil.Mock.ExpectBegin()
+		testUtil.Mock.ExpectRollback()
 		testUtil.MockDB.On("GetProductOptionValue", mock.Anything, exampleProductOptionValue.ID).
 			Return(exampleProductOptionValue, nil)
 		testUtil.MockDB.On("UpdateProductOptionValue", mock.Anything, mock.Anything).
@@ -261,8 +314,33 @@ func TestProductOptionValueUpdateHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodPatch, fmt.Sprintf("/v1/product_option_values/%d", exampleProductOptionValue.ID), strings.NewReader(exampleProductOptionValueUpdateBody))
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+		ensureExpectationsWereMet(t, testUtil.Mock)
+	})
+
+	t.Run("with error queueing webhook deliveries", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.Mock.ExpectBegin()
+		testUtil.Mock.ExpectRollback()
+		testUtil.MockDB.On("GetProductOptionValue", mock.Anything, exampleProductOptionValue.ID).
+			Return(exampleProductOptionValue, nil)
+		testUtil.MockDB.On("UpdateProductOptionValue", mock.Anything, mock.Anything).
+			Return(buildTestTime(), nil)
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, ProductOptionValueUpdatedWebhookEvent).
+			Return([]models.Webhook{}, generateArbitraryError())
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPatch, fmt.Sprintf("/v1/product_option_values/%d", exampleProductOptionValue.ID), strings.NewReader(exampleProductOptionValueUpdateBody))
+		assert.NoError(t, err)
+
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+		ensureExpectationsWereMet(t, testUtil.Mock)
 	})
 }
 
@@ -276,18 +354,25 @@ func TestProductOptionValueDeletionHandler(t *testing.T) {
 
 	t.Run("optimal conditions", func(*testing.T) {
 		testUtil := setupTestVariablesWithMock(t)
+		testUtil.Mock.ExpectBegin()
+		testUtil.Mock.ExpectCommit()
 		testUtil.MockDB.On("GetProductOptionValue", mock.Anything, exampleProductOptionValue.ID).
 			Return(exampleProductOptionValue, nil)
 		testUtil.MockDB.On("DeleteProductOptionValue", mock.Anything, exampleProductOptionValue.ID).
 			Return(buildTestTime(), nil)
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, ProductOptionValueArchivedWebhookEvent).
+			Return([]models.Webhook{}, nil)
 		config := buildServerConfigFromTestUtil(testUtil)
 		SetupAPIRouter(config)
 
 		req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("/v1/product_option_values/%d", exampleProductOptionValue.ID), nil)
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusOK)
+		ensureExpectationsWereMet(t, testUtil.Mock)
 	})
 
 	t.Run("with nonexistent option value", func(*testing.T) {
@@ -300,6 +385,7 @@ func TestProductOptionValueDeletionHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("/v1/product_option_values/%d", exampleProductOptionValue.ID), nil)
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusNotFound)
 	})
@@ -314,12 +400,15 @@ func TestProductOptionValueDeletionHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("/v1/product_option_values/%d", exampleProductOptionValue.ID), nil)
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusInternalServerError)
 	})
 
 	t.Run("with error deleting option value", func(*testing.T) {
 		testUtil := setupTestVariablesWithMock(t)
+		testUtil.Mock.ExpectBegin()
+		testUtil.Mock.ExpectRollback()
 		testUtil.MockDB.On("GetProductOptionValue", mock.Anything, exampleProductOptionValue.ID).
 			Return(exampleProductOptionValue, nil)
 		testUtil.MockDB.On("DeleteProductOptionValue", mock.Anything, exampleProductOptionValue.ID).
@@ -330,7 +419,32 @@ func TestProductOptionValueDeletionHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("/v1/product_option_values/%d", exampleProductOptionValue.ID), nil)
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+		ensureExpectationsWereMet(t, testUtil.Mock)
+	})
+
+	t.Run("with error queueing webhook deliveries", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.Mock.ExpectBegin()
+		testUtil.Mock.ExpectRollback()
+		testUtil.MockDB.On("GetProductOptionValue", mock.Anything, exampleProductOptionValue.ID).
+			Return(exampleProductOptionValue, nil)
+		testUtil.MockDB.On("DeleteProductOptionValue", mock.Anything, exampleProductOptionValue.ID).
+			Return(buildTestTime(), nil)
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, ProductOptionValueArchivedWebhookEvent).
+			Return([]models.Webhook{}, generateArbitraryError())
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("/v1/product_option_values/%d", exampleProductOptionValue.ID), nil)
+		assert.NoError(t, err)
+
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+		ensureExpectationsWereMet(t, testUtil.Mock)
 	})
 }
@@ -1,12 +1,15 @@
 package api
 
 import (
+	"bytes"
 	"database/sql"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
+	"text/template"
+	"text/template/parse"
 
 	"github.com/dairycart/dairycart/models/v1"
 	"github.com/dairycart/dairycart/storage/v1/database"
@@ -15,6 +18,20 @@ import (
 	"github.com/imdario/mergo"
 )
 
+const (
+	defaultSKUTemplate           = `{{ .SKU }}{{ range .Options }}_{{ lower .Value }}{{ end }}`
+	defaultOptionSummaryTemplate = `{{ range $i, $o := .Options }}{{ if $i }}, {{ end }}{{ $o.Name }}: {{ $o.Value }}{{ end }}`
+
+	maxVariantTemplateLength = 1024
+	// maxRenderedVariantTemplateLength is how much a SKU or option summary template can render before it's stopped
+	maxRenderedVariantTemplateLength = 256
+)
+
+var variantTemplateFuncs = template.FuncMap{
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+}
+
 type simpleProductOption struct {
 	IDs            []uint64
 	OptionSummary  string
@@ -24,12 +41,165 @@ type simpleProductOption struct {
 
 type optionPlaceholder struct {
 	ID            uint64
-	Summary       string
+	Name          string
 	Value         string
+	Abbreviation  string
 	OriginalValue models.ProductOptionValue
 }
 
-func buildProductsFromOptions(input *models.ProductCreationInput, createdOptions []models.ProductOption) (toCreate []*models.Product) {
+// variantTemplateOption is what SKU and option summary templates see for each option value
+type variantTemplateOption struct {
+	Name         string
+	Value        string
+	Abbreviation string
+}
+
+// variantTemplateData is the data passed to SKU and option summary templates. Options are in
+// the order they were provided, and Option allows templates to refer to them by name.
+type variantTemplateData struct {
+	SKU     string
+	Options []variantTemplateOption
+	Option  map[string]variantTemplateOption
+}
+
+func parseVariantTemplates(skuTemplateText, summaryTemplateText string) (skuTemplate *template.Template, summaryTemplate *template.Template, err error) {
+	if skuTemplateText == "" {
+		skuTemplateText = defaultSKUTemplate
+	}
+	if summaryTemplateText == "" {
+		summaryTemplateText = defaultOptionSummaryTemplate
+	}
+
+	skuTemplate, err = parseVariantTemplate("sku", skuTemplateText)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid sku template: %v", err)
+	}
+	summaryTemplate, err = parseVariantTemplate("option_summary", summaryTemplateText)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid option summary template: %v", err)
+	}
+	return skuTemplate, summaryTemplate, nil
+}
+
+// parseVariantTemplate parses a SKU or option summary template, and makes sure it only uses what those
+// templates need. Anything else, like ranging over a number, could keep rendering it busy indefinitely.
+func parseVariantTemplate(name, text string) (*template.Template, error) {
+	if len(text) > maxVariantTemplateLength {
+		return nil, fmt.Errorf("template is longer than %d characters", maxVariantTemplateLength)
+	}
+	t, err := template.New(name).Funcs(variantTemplateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	if len(t.Templates()) > 1 {
+		return nil, fmt.Errorf("templates can't define other templates")
+	}
+	if err = checkVariantTemplateNode(t.Tree.Root); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// checkVariantTemplateNode returns an error if a node of a variant template, or anything in it, is something
+// other than text, fields, variables, literals, the variant template functions, `if`, or `range .Options`
+func checkVariantTemplateNode(node parse.Node) error {
+	switch n := node.(type) {
+	case nil:
+		return nil
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		for _, child := range n.Nodes {
+			if err := checkVariantTemplateNode(child); err != nil {
+				return err
+			}
+		}
+		return nil
+	case *parse.TextNode, *parse.FieldNode, *parse.VariableNode, *parse.DotNode, *parse.StringNode, *parse.NumberNode, *parse.BoolNode:
+		return nil
+	case *parse.IdentifierNode:
+		if _, ok := variantTemplateFuncs[n.Ident]; !ok {
+			return fmt.Errorf("'%s' isn't available in templates", n.Ident)
+		}
+		return nil
+	case *parse.ActionNode:
+		return checkVariantTemplateNode(n.Pipe)
+	case *parse.PipeNode:
+		if n == nil {
+			return nil
+		}
+		for _, cmd := range n.Cmds {
+			if err := checkVariantTemplateNode(cmd); err != nil {
+				return err
+			}
+		}
+		return nil
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			if err := checkVariantTemplateNode(arg); err != nil {
+				return err
+			}
+		}
+		return nil
+	case *parse.IfNode:
+		return checkVariantTemplateBranch(&n.BranchNode)
+	case *parse.RangeNode:
+		if !rangesOverOptions(n.Pipe) {
+			return fmt.Errorf("templates can only range over .Options")
+		}
+		return checkVariantTemplateBranch(&n.BranchNode)
+	default:
+		return fmt.Errorf("'%s' isn't available in templates", node)
+	}
+}
+
+func checkVariantTemplateBranch(n *parse.BranchNode) error {
+	for _, child := range []parse.Node{n.Pipe, n.List, n.ElseList} {
+		if err := checkVariantTemplateNode(child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func rangesOverOptions(pipe *parse.PipeNode) bool {
+	if pipe == nil || len(pipe.Cmds) != 1 || len(pipe.Cmds[0].Args) != 1 {
+		return false
+	}
+	field, ok := pipe.Cmds[0].Args[0].(*parse.FieldNode)
+	return ok && len(field.Ident) == 1 && field.Ident[0] == "Options"
+}
+
+// errVariantTemplateTooLong is what a limitedVariantWriter returns once it's full
+var errVariantTemplateTooLong = fmt.Errorf("rendered to more than %d characters", maxRenderedVariantTemplateLength)
+
+// limitedVariantWriter is a buffer that refuses to hold more than a rendered variant template should need
+type limitedVariantWriter struct {
+	bytes.Buffer
+}
+
+func (w *limitedVariantWriter) Write(p []byte) (int, error) {
+	if w.Len()+len(p) > maxRenderedVariantTemplateLength {
+		return 0, errVariantTemplateTooLong
+	}
+	return w.Buffer.Write(p)
+}
+
+func renderVariantTemplate(t *template.Template, data variantTemplateData) (string, error) {
+	var b limitedVariantWriter
+	if err := t.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("error rendering %s template: %v", t.Name(), err)
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+func buildProductsFromOptions(input *models.ProductCreationInput, createdOptions []models.ProductOption) (toCreate []*models.Product, err error) {
+	skuTemplate, summaryTemplate, err := parseVariantTemplates(input.SKUTemplate, input.OptionSummaryTemplate)
+	if err != nil {
+		return nil, err
+	}
+
 	// lovingly borrowed from:
 	//     https://stackoverflow.com/questions/29002724/implement-ruby-style-cartesian-product-in-go
 	// NextIndex sets ix to the lexicographically next value,
@@ -49,11 +219,15 @@ func buildProductsFromOptions(input *models.ProductCreationInput, createdOptions
 	for _, o := range createdOptions {
 		var newOptions []optionPlaceholder
 		for _, v := range o.Values {
-			summary := fmt.Sprintf("%s: %s", o.Name, v.Value)
+			abbreviation := v.Abbreviation
+			if abbreviation == "" {
+				abbreviation = v.Value
+			}
 			ph := optionPlaceholder{
 				ID:            v.ID,
-				Summary:       summary,
+				Name:          o.Name,
 				Value:         v.Value,
+				Abbreviation:  abbreviation,
 				OriginalValue: v,
 			}
 			newOptions = append(newOptions, ph)
@@ -62,22 +236,68 @@ func buildProductsFromOptions(input *models.ProductCreationInput, createdOptions
 	}
 
 	for ix := make([]int, len(optionData)); ix[0] < len(optionData[0]); next(ix, optionData) {
-		var skuPrefixParts, optionSummaryParts []string
+		data := variantTemplateData{
+			SKU:    input.SKU,
+			Option: map[string]variantTemplateOption{},
+		}
 		var originalValues []models.ProductOptionValue
 		for j, k := range ix {
-			optionSummaryParts = append(optionSummaryParts, optionData[j][k].Summary)
-			skuPrefixParts = append(skuPrefixParts, strings.ToLower(optionData[j][k].Value))
-			originalValues = append(originalValues, optionData[j][k].OriginalValue)
+			ph := optionData[j][k]
+			o := variantTemplateOption{Name: ph.Name, Value: ph.Value, Abbreviation: ph.Abbreviation}
+			data.Options = append(data.Options, o)
+			data.Option[ph.Name] = o
+			originalValues = append(originalValues, ph.OriginalValue)
 		}
 
 		productTemplate := newProductFromCreationInput(input)
-		productTemplate.OptionSummary = strings.Join(optionSummaryParts, ", ")
-		productTemplate.SKU = fmt.Sprintf("%s_%s", input.SKU, strings.Join(skuPrefixParts, "_"))
+		productTemplate.OptionSummary, err = renderVariantTemplate(summaryTemplate, data)
+		if err != nil {
+			return nil, err
+		}
+		productTemplate.SKU, err = renderVariantTemplate(skuTemplate, data)
+		if err != nil {
+			return nil, err
+		}
 		productTemplate.ApplicableOptionValues = originalValues
 		toCreate = append(toCreate, productTemplate)
 
 	}
-	return toCreate
+	return toCreate, nil
+}
+
+// validateVariantSKUsFromInput renders the SKUs for every variant described by a product creation input
+// and makes sure they're valid and unique, so that we can reject bad input before starting a transaction
+func validateVariantSKUsFromInput(input *models.ProductCreationInput) ([]string, error) {
+	var placeholderOptions []models.ProductOption
+	for _, o := range input.Options {
+		option := models.ProductOption{Name: o.Name}
+		for _, v := range o.Values {
+			option.Values = append(option.Values, models.ProductOptionValue{Value: v, Abbreviation: o.Abbreviations[v]})
+		}
+		if len(option.Values) == 0 {
+			return nil, fmt.Errorf("product option '%s' has no values", o.Name)
+		}
+		placeholderOptions = append(placeholderOptions, option)
+	}
+
+	variants, err := buildProductsFromOptions(input, placeholderOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	var skus []string
+	for _, v := range variants {
+		if !restrictedStringIsValid(v.SKU) {
+			return nil, fmt.Errorf("the rendered sku for '%s' (%s) is invalid", v.OptionSummary, v.SKU)
+		}
+		if seen[v.SKU] {
+			return nil, fmt.Errorf("the rendered sku '%s' is used by more than one variant", v.SKU)
+		}
+		seen[v.SKU] = true
+		skus = append(skus, v.SKU)
+	}
+	return skus, nil
 }
 
 func buildProductOptionListHandler(db *sql.DB, client database.Storer) http.HandlerFunc {
@@ -141,21 +361,45 @@ func buildProductOptionUpdateHandler(db *sql.DB, client database.Storer) http.Ha
 			return
 		}
 
+		previous := *existingOption
 		mergo.MergeWithOverwrite(existingOption, buildProductOptionFromUpdateInfo(updatedOptionData))
 
-		updatedOn, err := client.UpdateProductOption(db, existingOption)
+		tx, err := db.Begin()
+		if err != nil {
+			notifyOfInternalIssue(res, err, "starting a new transaction")
+			return
+		}
+
+		updatedOn, err := client.UpdateProductOption(tx, existingOption)
 		if err != nil {
+			tx.Rollback()
 			notifyOfInternalIssue(res, err, "update product option in the database")
 			return
 		}
 		existingOption.UpdatedOn = &models.Dairytime{Time: updatedOn}
 
-		values, err := client.GetProductOptionValuesForOption(db, existingOption.ID)
+		values, err := client.GetProductOptionValuesForOption(tx, existingOption.ID)
 		if err != nil {
+			tx.Rollback()
 			notifyOfInternalIssue(res, err, "retrieve product option from the database")
 			return
 		}
 		existingOption.Values = values
+		// updating an option doesn't change its values, so they shouldn't look like they changed
+		previous.Values = values
+
+		err = enqueueWebhookChangeDeliveries(tx, client, ProductOptionUpdatedWebhookEvent, &previous, existingOption)
+		if err != nil {
+			tx.Rollback()
+			notifyOfInternalIssue(res, err, "queue webhook deliveries")
+			return
+		}
+
+		err = tx.Commit()
+		if err != nil {
+			notifyOfInternalIssue(res, err, "close out transaction")
+			return
+		}
 
 		json.NewEncoder(res).Encode(existingOption)
 	}
@@ -173,6 +417,7 @@ func createProductOptionAndValuesInDBFromInput(tx *sql.Tx, in models.ProductOpti
 		newOptionValue := models.ProductOptionValue{
 			ProductOptionID: newProductOption.ID,
 			Value:           value,
+			Abbreviation:    in.Abbreviations[value],
 		}
 		newOptionValue.ID, newOptionValue.CreatedOn, err = client.CreateProductOptionValue(tx, &newOptionValue)
 		if err != nil {
@@ -184,6 +429,21 @@ func createProductOptionAndValuesInDBFromInput(tx *sql.Tx, in models.ProductOpti
 	return *newProductOption, nil
 }
 
+// enqueueProductOptionCreationWebhookDeliveries queues the events for a newly created option, as well as for the values created along with it
+func enqueueProductOptionCreationWebhookDeliveries(tx database.Querier, client database.Storer, option models.ProductOption) error {
+	err := enqueueWebhookDeliveries(tx, client, ProductOptionCreatedWebhookEvent, option)
+	if err != nil {
+		return err
+	}
+	for _, value := range option.Values {
+		err = enqueueWebhookDeliveries(tx, client, ProductOptionValueCreatedWebhookEvent, value)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func buildProductOptionCreationHandler(db *sql.DB, client database.Storer) http.HandlerFunc {
 	// ProductOptionCreationHandler is a request handler that can create product options
 	return func(res http.ResponseWriter, req *http.Request) {
@@ -231,6 +491,13 @@ func buildProductOptionCreationHandler(db *sql.DB, client database.Storer) http.
 			return
 		}
 
+		err = enqueueProductOptionCreationWebhookDeliveries(tx, client, newProductOption)
+		if err != nil {
+			tx.Rollback()
+			notifyOfInternalIssue(res, err, "queue webhook deliveries")
+			return
+		}
+
 		err = tx.Commit()
 		if err != nil {
 			notifyOfInternalIssue(res, err, "close out transaction")
@@ -267,17 +534,26 @@ func buildProductOptionDeletionHandler(db *sql.DB, client database.Storer) http.
 
 		_, err = client.ArchiveProductOptionValuesForOption(tx, optionID)
 		if err != nil {
+			tx.Rollback()
 			notifyOfInternalIssue(res, err, "archiving product option values")
 			return
 		}
 
 		archivedOn, err := client.DeleteProductOption(tx, optionID)
 		if err != nil {
+			tx.Rollback()
 			notifyOfInternalIssue(res, err, "archiving product options")
 			return
 		}
 		existingOption.ArchivedOn = &models.Dairytime{Time: archivedOn}
 
+		err = enqueueWebhookDeliveries(tx, client, ProductOptionArchivedWebhookEvent, existingOption)
+		if err != nil {
+			tx.Rollback()
+			notifyOfInternalIssue(res, err, "queue webhook deliveries")
+			return
+		}
+
 		err = tx.Commit()
 		if err != nil {
 			notifyOfInternalIssue(res, err, "close out transaction")
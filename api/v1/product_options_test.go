@@ -132,11 +132,168 @@ func TestBuildProductsFromOptions(t *testing.T) {
 	}
 
 	for _, tc := range tt {
-		actual := buildProductsFromOptions(tc.input, tc.inOptions)
+		actual, err := buildProductsFromOptions(tc.input, tc.inOptions)
+		assert.NoError(t, err)
 		assert.Equal(t, tc.expected, actual, "expected output should match actual output")
 	}
 }
 
+func TestBuildProductsFromOptionsWithTemplates(t *testing.T) {
+	t.Parallel()
+
+	red := models.ProductOptionValue{ID: 1, Value: "Red", Abbreviation: "RD"}
+	blue := models.ProductOptionValue{ID: 2, Value: "Blue"}
+	xl := models.ProductOptionValue{ID: 3, Value: "Extra Large", Abbreviation: "XL"}
+
+	options := []models.ProductOption{
+		{Name: "Color", Values: []models.ProductOptionValue{red, blue}},
+		{Name: "Size", Values: []models.ProductOptionValue{xl}},
+	}
+
+	t.Run("optimal behavior", func(*testing.T) {
+		input := &models.ProductCreationInput{
+			SKU:                   "tshirt",
+			SKUTemplate:           `{{ upper .SKU }}{{ range .Options }}-{{ upper .Abbreviation }}{{ end }}`,
+			OptionSummaryTemplate: `{{ .Option.Size.Value }} / {{ .Option.Color.Value }}`,
+		}
+
+		actual, err := buildProductsFromOptions(input, options)
+		assert.NoError(t, err)
+		assert.Len(t, actual, 2)
+		assert.Equal(t, "TSHIRT-RD-XL", actual[0].SKU)
+		assert.Equal(t, "Extra Large / Red", actual[0].OptionSummary)
+		assert.Equal(t, "TSHIRT-BLUE-XL", actual[1].SKU, "values without abbreviations should fall back to the value itself")
+		assert.Equal(t, "Extra Large / Blue", actual[1].OptionSummary)
+	})
+
+	t.Run("with unparseable template", func(*testing.T) {
+		input := &models.ProductCreationInput{
+			SKU:         "tshirt",
+			SKUTemplate: `{{ .SKU`,
+		}
+
+		_, err := buildProductsFromOptions(input, options)
+		assert.Error(t, err)
+	})
+
+	t.Run("with template that fails to render", func(*testing.T) {
+		input := &models.ProductCreationInput{
+			SKU:         "tshirt",
+			SKUTemplate: `{{ .Option.Material.Value }}`,
+		}
+
+		_, err := buildProductsFromOptions(input, options)
+		assert.Error(t, err)
+	})
+
+	t.Run("with template that renders too much", func(*testing.T) {
+		input := &models.ProductCreationInput{
+			SKU:         "tshirt",
+			SKUTemplate: `{{ .SKU }}` + strings.Repeat("x", maxRenderedVariantTemplateLength),
+		}
+
+		_, err := buildProductsFromOptions(input, options)
+		assert.Error(t, err)
+	})
+}
+
+func TestParseVariantTemplates(t *testing.T) {
+	t.Parallel()
+
+	t.Run("optimal behavior", func(*testing.T) {
+		_, _, err := parseVariantTemplates(
+			`{{ upper .SKU }}{{ range $i, $o := .Options }}{{ if $i }}-{{ else }}_{{ end }}{{ lower $o.Abbreviation }}{{ end }}`,
+			`{{ .Option.Size.Value | upper }} / {{ .Option.Color.Value }}`,
+		)
+		assert.NoError(t, err)
+	})
+
+	t.Run("with defaults", func(*testing.T) {
+		_, _, err := parseVariantTemplates("", "")
+		assert.NoError(t, err)
+	})
+
+	t.Run("with template that's too long", func(*testing.T) {
+		_, _, err := parseVariantTemplates(`{{ .SKU }}`+strings.Repeat("x", maxVariantTemplateLength), "")
+		assert.Error(t, err)
+	})
+
+	disallowed := map[string]string{
+		"ranging over a number":        `{{ range 100000000000 }}x{{ end }}`,
+		"ranging over something else":  `{{ range .SKU }}x{{ end }}`,
+		"nested range over everything": `{{ range .Options }}{{ range $.Options }}x{{ end }}{{ end }}`,
+		"other functions":              `{{ printf "%0100000000d" 1 }}`,
+		"with":                         `{{ with .SKU }}{{ . }}{{ end }}`,
+		"defining templates":           `{{ define "x" }}x{{ end }}{{ .SKU }}`,
+		"calling templates":            `{{ template "sku" . }}`,
+	}
+	for name, text := range disallowed {
+		text := text
+		t.Run(fmt.Sprintf("with %s", name), func(*testing.T) {
+			_, _, err := parseVariantTemplates(text, "")
+			assert.Error(t, err)
+			_, _, err = parseVariantTemplates("", text)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestValidateVariantSKUsFromInput(t *testing.T) {
+	t.Parallel()
+
+	exampleOptions := []models.ProductOptionCreationInput{
+		{
+			Name:          "Color",
+			Values:        []string{"Red", "Blue"},
+			Abbreviations: map[string]string{"Red": "RD", "Blue": "BL"},
+		},
+	}
+
+	t.Run("optimal behavior", func(*testing.T) {
+		input := &models.ProductCreationInput{
+			SKU:         "tshirt",
+			SKUTemplate: `{{ upper .SKU }}{{ range .Options }}-{{ .Abbreviation }}{{ end }}`,
+			Options:     exampleOptions,
+		}
+
+		actual, err := validateVariantSKUsFromInput(input)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"TSHIRT-RD", "TSHIRT-BL"}, actual)
+	})
+
+	t.Run("with invalid rendered sku", func(*testing.T) {
+		input := &models.ProductCreationInput{
+			SKU:         "tshirt",
+			SKUTemplate: `{{ .SKU }} {{ range .Options }}{{ .Abbreviation }}{{ end }}`,
+			Options:     exampleOptions,
+		}
+
+		_, err := validateVariantSKUsFromInput(input)
+		assert.Error(t, err)
+	})
+
+	t.Run("with duplicate rendered skus", func(*testing.T) {
+		input := &models.ProductCreationInput{
+			SKU:         "tshirt",
+			SKUTemplate: `{{ .SKU }}`,
+			Options:     exampleOptions,
+		}
+
+		_, err := validateVariantSKUsFromInput(input)
+		assert.Error(t, err)
+	})
+
+	t.Run("with option lacking values", func(*testing.T) {
+		input := &models.ProductCreationInput{
+			SKU:     "tshirt",
+			Options: []models.ProductOptionCreationInput{{Name: "Color"}},
+		}
+
+		_, err := validateVariantSKUsFromInput(input)
+		assert.Error(t, err)
+	})
+}
+
 func TestCreateProductOptionAndValuesInDBFromInput(t *testing.T) {
 	exampleID := uint64(1)
 
@@ -267,14 +424,22 @@ func TestProductOptionCreationHandler(t *testing.T) {
 		testUtil.MockDB.On("CreateProductOptionValue", mock.Anything, mock.Anything).
 			Return(exampleProductOption.ID, buildTestTime(), nil)
 		testUtil.Mock.ExpectCommit()
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, ProductOptionCreatedWebhookEvent).
+			Return([]models.Webhook{}, nil)
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, ProductOptionValueCreatedWebhookEvent).
+			Return([]models.Webhook{}, nil)
 		config := buildServerConfigFromTestUtil(testUtil)
 		SetupAPIRouter(config)
 
 		req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/v1/product_root/%d/options", exampleProductOption.ProductRootID), strings.NewReader(exampleProductOptionCreationBody))
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusCreated)
+		ensureExpectationsWereMet(t, testUtil.Mock)
 	})
 
 	t.Run("with invalid input", func(*testing.T) {
@@ -285,6 +450,7 @@ func TestProductOptionCreationHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/v1/product_root/%d/options", exampleProductOption.ProductRootID), strings.NewReader(exampleGarbageInput))
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusBadRequest)
 	})
@@ -299,6 +465,7 @@ func TestProductOptionCreationHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/v1/product_root/%d/options", exampleProductOption.ProductRootID), strings.NewReader(exampleProductOptionCreationBody))
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusNotFound)
 	})
@@ -313,6 +480,7 @@ func TestProductOptionCreationHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/v1/product_root/%d/options", exampleProductOption.ProductRootID), strings.NewReader(exampleProductOptionCreationBody))
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusInternalServerError)
 	})
@@ -329,6 +497,7 @@ func TestProductOptionCreationHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/v1/product_root/%d/options", exampleProductOption.ProductRootID), strings.NewReader(exampleProductOptionCreationBody))
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusBadRequest)
 	})
@@ -345,6 +514,7 @@ func TestProductOptionCreationHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/v1/product_root/%d/options", exampleProductOption.ProductRootID), strings.NewReader(exampleProductOptionCreationBody))
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusInternalServerError)
 	})
@@ -362,6 +532,7 @@ func TestProductOptionCreationHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/v1/product_root/%d/options", exampleProductOption.ProductRootID), strings.NewReader(exampleProductOptionCreationBody))
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusInternalServerError)
 	})
@@ -382,6 +553,7 @@ func TestProductOptionCreationHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/v1/product_root/%d/options", exampleProductOption.ProductRootID), strings.NewReader(exampleProductOptionCreationBody))
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusInternalServerError)
 	})
@@ -398,14 +570,49 @@ func TestProductOptionCreationHandler(t *testing.T) {
 		testUtil.MockDB.On("CreateProductOptionValue", mock.Anything, mock.Anything).
 			Return(exampleProductOption.ID, buildTestTime(), nil)
 		testUtil.Mock.ExpectCommit().WillReturnError(generateArbitraryError())
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, ProductOptionCreatedWebhookEvent).
+			Return([]models.Webhook{}, nil)
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, ProductOptionValueCreatedWebhookEvent).
+			Return([]models.Webhook{}, nil)
 		config := buildServerConfigFromTestUtil(testUtil)
 		SetupAPIRouter(config)
 
 		req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/v1/product_root/%d/options", exampleProductOption.ProductRootID), strings.NewReader(exampleProductOptionCreationBody))
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+		ensureExpectationsWereMet(t, testUtil.Mock)
+	})
+
+	t.Run("with error queueing webhook deliveries", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("ProductRootExists", mock.Anything, exampleProductOption.ProductRootID).
+			Return(true, nil)
+		testUtil.MockDB.On("ProductOptionWithNameExistsForProductRoot", mock.Anything, exampleProductOption.Name, exampleProductOption.ProductRootID).
+			Return(false, nil)
+		testUtil.Mock.ExpectBegin()
+		testUtil.MockDB.On("CreateProductOption", mock.Anything, mock.Anything).
+			Return(exampleProductOption.ID, buildTestTime(), nil)
+		testUtil.MockDB.On("CreateProductOptionValue", mock.Anything, mock.Anything).
+			Return(exampleProductOption.ID, buildTestTime(), nil)
+		testUtil.Mock.ExpectRollback()
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, ProductOptionCreatedWebhookEvent).
+			Return([]models.Webhook{}, generateArbitraryError())
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/v1/product_root/%d/options", exampleProductOption.ProductRootID), strings.NewReader(exampleProductOptionCreationBody))
+		assert.NoError(t, err)
+
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+		ensureExpectationsWereMet(t, testUtil.Mock)
 	})
 }
 
@@ -424,20 +631,27 @@ func TestProductOptionUpdateHandler(t *testing.T) {
 
 	t.Run("optimal conditions", func(*testing.T) {
 		testUtil := setupTestVariablesWithMock(t)
+		testUtil.Mock.ExpectBegin()
+		testUtil.Mock.ExpectCommit()
 		testUtil.MockDB.On("GetProductOption", mock.Anything, exampleProductOption.ID).
 			Return(exampleProductOption, nil)
 		testUtil.MockDB.On("UpdateProductOption", mock.Anything, mock.Anything).
 			Return(buildTestTime(), nil)
 		testUtil.MockDB.On("GetProductOptionValuesForOption", mock.Anything, exampleProductOption.ID).
 			Return([]models.ProductOptionValue{}, nil)
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, ProductOptionUpdatedWebhookEvent).
+			Return([]models.Webhook{}, nil)
 		config := buildServerConfigFromTestUtil(testUtil)
 		SetupAPIRouter(config)
 
 		req, err := http.NewRequest(http.MethodPatch, fmt.Sprintf("/v1/product_options/%d", exampleProductOption.ID), strings.NewReader(exampleProductOptionUpdateBody))
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusOK)
+		ensureExpectationsWereMet(t, testUtil.Mock)
 	})
 
 	t.Run("with invalid input", func(*testing.T) {
@@ -448,6 +662,7 @@ func TestProductOptionUpdateHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodPatch, fmt.Sprintf("/v1/product_options/%d", exampleProductOption.ID), strings.NewReader(exampleGarbageInput))
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusBadRequest)
 	})
@@ -462,6 +677,7 @@ func TestProductOptionUpdateHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodPatch, fmt.Sprintf("/v1/product_options/%d", exampleProductOption.ID), strings.NewReader(exampleProductOptionUpdateBody))
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusNotFound)
 	})
@@ -476,12 +692,15 @@ func TestProductOptionUpdateHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodPatch, fmt.Sprintf("/v1/product_options/%d", exampleProductOption.ID), strings.NewReader(exampleProductOptionUpdateBody))
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusInternalServerError)
 	})
 
 	t.Run("with error updating product option", func(*testing.T) {
 		testUtil := setupTestVariablesWithMock(t)
+		testUtil.Mock.ExpectBegin()
+		testUtil.Mock.ExpectRollback()
 		testUtil.MockDB.On("GetProductOption", mock.Anything, exampleProductOption.ID).
 			Return(exampleProductOption, nil)
 		testUtil.MockDB.On("UpdateProductOption", mock.Anything, mock.Anything).
@@ -492,12 +711,16 @@ func TestProductOptionUpdateHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodPatch, fmt.Sprintf("/v1/product_options/%d", exampleProductOption.ID), strings.NewReader(exampleProductOptionUpdateBody))
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+		ensureExpectationsWereMet(t, testUtil.Mock)
 	})
 
 	t.Run("with error retrieving product option values", func(*testing.T) {
 		testUtil := setupTestVariablesWithMock(t)
+		testUtil.Mock.ExpectBegin()
+		testUtil.Mock.ExpectRollback()
 		testUtil.MockDB.On("GetProductOption", mock.Anything, exampleProductOption.ID).
 			Return(exampleProductOption, nil)
 		testUtil.MockDB.On("UpdateProductOption", mock.Anything, mock.Anything).
@@ -510,8 +733,35 @@ func TestProductOptionUpdateHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodPatch, fmt.Sprintf("/v1/product_options/%d", exampleProductOption.ID), strings.NewReader(exampleProductOptionUpdateBody))
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+		ensureExpectationsWereMet(t, testUtil.Mock)
+	})
+
+	t.Run("with error queueing webhook deliveries", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.Mock.ExpectBegin()
+		testUtil.Mock.ExpectRollback()
+		testUtil.MockDB.On("GetProductOption", mock.Anything, exampleProductOption.ID).
+			Return(exampleProductOption, nil)
+		testUtil.MockDB.On("UpdateProductOption", mock.Anything, mock.Anything).
+			Return(buildTestTime(), nil)
+		testUtil.MockDB.On("GetProductOptionValuesForOption", mock.Anything, exampleProductOption.ID).
+			Return([]models.ProductOptionValue{}, nil)
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, ProductOptionUpdatedWebhookEvent).
+			Return([]models.Webhook{}, generateArbitraryError())
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPatch, fmt.Sprintf("/v1/product_options/%d", exampleProductOption.ID), strings.NewReader(exampleProductOptionUpdateBody))
+		assert.NoError(t, err)
+
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+		ensureExpectationsWereMet(t, testUtil.Mock)
 	})
 }
 
@@ -533,14 +783,19 @@ func TestProductOptionDeletionHandler(t *testing.T) {
 		testUtil.MockDB.On("DeleteProductOption", mock.Anything, exampleProductOption.ID).
 			Return(buildTestTime(), nil)
 		testUtil.Mock.ExpectCommit()
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, ProductOptionArchivedWebhookEvent).
+			Return([]models.Webhook{}, nil)
 		config := buildServerConfigFromTestUtil(testUtil)
 		SetupAPIRouter(config)
 
 		req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("/v1/product_options/%d", exampleProductOption.ID), nil)
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusOK)
+		ensureExpectationsWereMet(t, testUtil.Mock)
 	})
 
 	t.Run("with nonexistent option", func(*testing.T) {
@@ -553,6 +808,7 @@ func TestProductOptionDeletionHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("/v1/product_options/%d", exampleProductOption.ID), nil)
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusNotFound)
 	})
@@ -567,6 +823,7 @@ func TestProductOptionDeletionHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("/v1/product_options/%d", exampleProductOption.ID), nil)
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusInternalServerError)
 	})
@@ -582,6 +839,7 @@ func TestProductOptionDeletionHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("/v1/product_options/%d", exampleProductOption.ID), nil)
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusInternalServerError)
 	})
@@ -600,8 +858,10 @@ func TestProductOptionDeletionHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("/v1/product_options/%d", exampleProductOption.ID), nil)
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+		ensureExpectationsWereMet(t, testUtil.Mock)
 	})
 
 	t.Run("with error deleting product option", func(*testing.T) {
@@ -620,8 +880,10 @@ func TestProductOptionDeletionHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("/v1/product_options/%d", exampleProductOption.ID), nil)
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+		ensureExpectationsWereMet(t, testUtil.Mock)
 	})
 
 	t.Run("with error committing transaction", func(*testing.T) {
@@ -634,13 +896,43 @@ func TestProductOptionDeletionHandler(t *testing.T) {
 		testUtil.MockDB.On("DeleteProductOption", mock.Anything, exampleProductOption.ID).
 			Return(buildTestTime(), nil)
 		testUtil.Mock.ExpectCommit().WillReturnError(generateArbitraryError())
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, ProductOptionArchivedWebhookEvent).
+			Return([]models.Webhook{}, nil)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("/v1/product_options/%d", exampleProductOption.ID), nil)
+		assert.NoError(t, err)
+
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+		ensureExpectationsWereMet(t, testUtil.Mock)
+	})
+
+	t.Run("with error queueing webhook deliveries", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetProductOption", mock.Anything, exampleProductOption.ID).
+			Return(exampleProductOption, nil)
+		testUtil.Mock.ExpectBegin()
+		testUtil.MockDB.On("ArchiveProductOptionValuesForOption", mock.Anything, exampleProductOption.ID).
+			Return(buildTestTime(), nil)
+		testUtil.MockDB.On("DeleteProductOption", mock.Anything, exampleProductOption.ID).
+			Return(buildTestTime(), nil)
+		testUtil.Mock.ExpectRollback()
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, ProductOptionArchivedWebhookEvent).
+			Return([]models.Webhook{}, generateArbitraryError())
 		config := buildServerConfigFromTestUtil(testUtil)
 		SetupAPIRouter(config)
 
 		req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("/v1/product_options/%d", exampleProductOption.ID), nil)
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+		ensureExpectationsWereMet(t, testUtil.Mock)
 	})
 }
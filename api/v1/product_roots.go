@@ -3,6 +3,7 @@ package api
 import (
 	"database/sql"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"strconv"
 
@@ -10,6 +11,7 @@ import (
 	"github.com/dairycart/dairycart/storage/v1/database"
 
 	"github.com/go-chi/chi"
+	"github.com/imdario/mergo"
 )
 
 func createProductRootFromProduct(p *models.Product) *models.ProductRoot {
@@ -106,6 +108,103 @@ func buildSingleProductRootHandler(db *sql.DB, client database.Storer) http.Hand
 	}
 }
 
+func buildProductRootUpdateHandler(db *sql.DB, client database.Storer) http.HandlerFunc {
+	// ProductRootUpdateHandler is a request handler that can update product roots, including the templates
+	// their variants' SKUs and option summaries are built with
+	return func(res http.ResponseWriter, req *http.Request) {
+		productRootIDStr := chi.URLParam(req, "product_root_id")
+		// eating this error because the router should have ensured this is an integer
+		productRootID, _ := strconv.ParseUint(productRootIDStr, 10, 64)
+
+		input := &models.ProductRootUpdateInput{}
+		err := validateRequestInput(req, input)
+		if err != nil {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
+
+		existingProductRoot, err := client.GetProductRoot(db, productRootID)
+		if err == sql.ErrNoRows {
+			respondThatRowDoesNotExist(req, res, "product root", productRootIDStr)
+			return
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "retrieving product root from database")
+			return
+		}
+
+		updatedProductRoot := &models.ProductRoot{
+			ID:                    existingProductRoot.ID,
+			Name:                  input.Name,
+			Subtitle:              input.Subtitle,
+			Description:           input.Description,
+			SKUPrefix:             input.SKUPrefix,
+			Manufacturer:          input.Manufacturer,
+			Brand:                 input.Brand,
+			Taxable:               input.Taxable,
+			Cost:                  input.Cost,
+			ProductWeight:         input.ProductWeight,
+			ProductHeight:         input.ProductHeight,
+			ProductWidth:          input.ProductWidth,
+			ProductLength:         input.ProductLength,
+			PackageWeight:         input.PackageWeight,
+			PackageHeight:         input.PackageHeight,
+			PackageWidth:          input.PackageWidth,
+			PackageLength:         input.PackageLength,
+			QuantityPerPackage:    input.QuantityPerPackage,
+			SKUTemplate:           input.SKUTemplate,
+			OptionSummaryTemplate: input.OptionSummaryTemplate,
+		}
+		mergo.Merge(updatedProductRoot, existingProductRoot)
+		// mergo doesn't merge time.Time fields, so without this they'd look like they changed to the zero time
+		updatedProductRoot.AvailableOn = existingProductRoot.AvailableOn
+		if input.AvailableOn != nil {
+			updatedProductRoot.AvailableOn = input.AvailableOn.Time
+		}
+		updatedProductRoot.CreatedOn = existingProductRoot.CreatedOn
+		// primary images have their own route, which keeps the products' primary images in step
+		updatedProductRoot.PrimaryImageID = existingProductRoot.PrimaryImageID
+
+		if !restrictedStringIsValid(updatedProductRoot.SKUPrefix) {
+			notifyOfInvalidRequestBody(res, fmt.Errorf("The sku prefix received (%s) is invalid", updatedProductRoot.SKUPrefix))
+			return
+		}
+		_, _, err = parseVariantTemplates(updatedProductRoot.SKUTemplate, updatedProductRoot.OptionSummaryTemplate)
+		if err != nil {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
+
+		tx, err := db.Begin()
+		if err != nil {
+			notifyOfInternalIssue(res, err, "create new database transaction")
+			return
+		}
+
+		updatedOn, err := client.UpdateProductRoot(tx, updatedProductRoot)
+		if err != nil {
+			tx.Rollback()
+			notifyOfInternalIssue(res, err, "update product root in database")
+			return
+		}
+		updatedProductRoot.UpdatedOn = &models.Dairytime{Time: updatedOn}
+
+		err = enqueueWebhookChangeDeliveries(tx, client, ProductRootUpdatedWebhookEvent, existingProductRoot, updatedProductRoot)
+		if err != nil {
+			tx.Rollback()
+			notifyOfInternalIssue(res, err, "queue webhook deliveries")
+			return
+		}
+
+		err = tx.Commit()
+		if err != nil {
+			notifyOfInternalIssue(res, err, "close out transaction")
+			return
+		}
+
+		json.NewEncoder(res).Encode(updatedProductRoot)
+	}
+}
+
 func buildProductRootDeletionHandler(db *sql.DB, client database.Storer) http.HandlerFunc {
 	// ProductDeletionHandler is a request handler that deletes a single product
 	return func(res http.ResponseWriter, req *http.Request) {
@@ -161,6 +260,14 @@ func buildProductRootDeletionHandler(db *sql.DB, client database.Storer) http.Ha
 			return
 		}
 
+		// delete product images, so their files are collected as orphans once nothing else uses them
+		_, err = client.ArchiveProductImagesWithProductRootID(tx, productRoot.ID)
+		if err != nil && err != sql.ErrNoRows {
+			tx.Rollback()
+			notifyOfInternalIssue(res, err, "archive product images in database")
+			return
+		}
+
 		// delete the actual product root
 		archivedOn, err := client.DeleteProductRoot(tx, productRoot.ID)
 		if err != nil {
@@ -170,6 +277,13 @@ func buildProductRootDeletionHandler(db *sql.DB, client database.Storer) http.Ha
 		}
 		productRoot.ArchivedOn = &models.Dairytime{Time: archivedOn}
 
+		err = enqueueWebhookDeliveries(tx, client, ProductRootArchivedWebhookEvent, productRoot)
+		if err != nil {
+			tx.Rollback()
+			notifyOfInternalIssue(res, err, "queue webhook deliveries")
+			return
+		}
+
 		err = tx.Commit()
 		if err != nil {
 			notifyOfInternalIssue(res, err, "close out transaction")
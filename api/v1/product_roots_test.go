@@ -4,12 +4,14 @@ import (
 	"database/sql"
 	"fmt"
 	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/dairycart/dairycart/models/v1"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCreateProductRootFromProduct(t *testing.T) {
@@ -278,6 +280,115 @@ func TestProductRootListRetrievalHandler(t *testing.T) {
 	})
 }
 
+func TestProductRootUpdateHandler(t *testing.T) {
+	buildExampleProductRoot := func() *models.ProductRoot {
+		return &models.ProductRoot{
+			ID:          2,
+			CreatedOn:   buildTestTime(),
+			AvailableOn: buildTestTime(),
+			Name:        "root_name",
+			SKUPrefix:   "sku_prefix",
+			SKUTemplate: "{{ .SKU }}",
+		}
+	}
+	exampleInput := `
+		{
+			"sku_template": "{{ .SKU }}{{ range .Options }}-{{ upper .Value }}{{ end }}",
+			"option_summary_template": "{{ range .Options }}{{ .Value }} {{ end }}"
+		}
+	`
+
+	t.Run("optimal conditions", func(t *testing.T) {
+		exampleProductRoot := buildExampleProductRoot()
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetProductRoot", mock.Anything, exampleProductRoot.ID).
+			Return(exampleProductRoot, nil)
+		testUtil.Mock.ExpectBegin()
+		testUtil.MockDB.On("UpdateProductRoot", mock.Anything, mock.AnythingOfType("*models.ProductRoot")).
+			Return(buildTestTime(), nil)
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, ProductRootUpdatedWebhookEvent).
+			Return([]models.Webhook{}, nil)
+		testUtil.Mock.ExpectCommit()
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPatch, fmt.Sprintf("/v1/product_root/%d", exampleProductRoot.ID), strings.NewReader(exampleInput))
+		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusOK)
+		ensureExpectationsWereMet(t, testUtil.Mock)
+
+		updated := testUtil.MockDB.Calls[2].Arguments.Get(1).(*models.ProductRoot)
+		assert.Equal(t, "{{ .SKU }}{{ range .Options }}-{{ upper .Value }}{{ end }}", updated.SKUTemplate)
+		assert.Equal(t, "{{ range .Options }}{{ .Value }} {{ end }}", updated.OptionSummaryTemplate)
+		assert.Equal(t, exampleProductRoot.Name, updated.Name, "fields that weren't provided should be left alone")
+		assert.Equal(t, exampleProductRoot.CreatedOn, updated.CreatedOn)
+		assert.Equal(t, exampleProductRoot.AvailableOn, updated.AvailableOn)
+	})
+
+	t.Run("with invalid template", func(t *testing.T) {
+		exampleProductRoot := buildExampleProductRoot()
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetProductRoot", mock.Anything, exampleProductRoot.ID).
+			Return(exampleProductRoot, nil)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPatch, fmt.Sprintf("/v1/product_root/%d", exampleProductRoot.ID), strings.NewReader(`{"sku_template": "{{ .SKU "}`))
+		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusBadRequest)
+	})
+
+	t.Run("with invalid input", func(t *testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPatch, "/v1/product_root/2", strings.NewReader(exampleGarbageInput))
+		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusBadRequest)
+	})
+
+	t.Run("with nonexistent product root", func(t *testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetProductRoot", mock.Anything, uint64(2)).
+			Return(buildExampleProductRoot(), sql.ErrNoRows)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPatch, "/v1/product_root/2", strings.NewReader(exampleInput))
+		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusNotFound)
+	})
+
+	t.Run("with error updating product root", func(t *testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetProductRoot", mock.Anything, uint64(2)).
+			Return(buildExampleProductRoot(), nil)
+		testUtil.Mock.ExpectBegin()
+		testUtil.MockDB.On("UpdateProductRoot", mock.Anything, mock.AnythingOfType("*models.ProductRoot")).
+			Return(buildTestTime(), generateArbitraryError())
+		testUtil.Mock.ExpectRollback()
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPatch, "/v1/product_root/2", strings.NewReader(exampleInput))
+		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+		ensureExpectationsWereMet(t, testUtil.Mock)
+	})
+}
+
 func TestProductRootDeletionHandler(t *testing.T) {
 	exampleProductRoot := &models.ProductRoot{
 		ID:           2,
@@ -303,17 +414,27 @@ func TestProductRootDeletionHandler(t *testing.T) {
 			Return(buildTestTime(), nil)
 		testUtil.MockDB.On("ArchiveProductsWithProductRootID", mock.Anything, exampleProductRoot.ID).
 			Return(buildTestTime(), nil)
+		testUtil.MockDB.On("ArchiveProductImagesWithProductRootID", mock.Anything, exampleProductRoot.ID).
+			Return(buildTestTime(), nil)
 		testUtil.MockDB.On("DeleteProductRoot", mock.Anything, exampleProductRoot.ID).
 			Return(buildTestTime(), nil)
 		testUtil.Mock.ExpectCommit()
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, ProductRootArchivedWebhookEvent).
+			Return([]models.Webhook{}, nil)
 		config := buildServerConfigFromTestUtil(testUtil)
 		SetupAPIRouter(config)
 
 		req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("/v1/product_root/%d", exampleProductRoot.ID), nil)
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusOK)
+		ensureExpectationsWereMet(t, testUtil.Mock)
+		archived := mockCallsTo(&testUtil.MockDB.Mock, "ArchiveProductImagesWithProductRootID")
+		require.Len(t, archived, 1)
+		assert.Equal(t, exampleProductRoot.ID, archived[0].Get(1))
 	})
 
 	t.Run("with nonexistent product root", func(*testing.T) {
@@ -326,6 +447,7 @@ func TestProductRootDeletionHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("/v1/product_root/%d", exampleProductRoot.ID), nil)
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusNotFound)
 	})
@@ -340,6 +462,7 @@ func TestProductRootDeletionHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("/v1/product_root/%d", exampleProductRoot.ID), nil)
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusInternalServerError)
 	})
@@ -355,6 +478,7 @@ func TestProductRootDeletionHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("/v1/product_root/%d", exampleProductRoot.ID), nil)
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusInternalServerError)
 	})
@@ -373,6 +497,7 @@ func TestProductRootDeletionHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("/v1/product_root/%d", exampleProductRoot.ID), nil)
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusInternalServerError)
 	})
@@ -393,6 +518,7 @@ func TestProductRootDeletionHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("/v1/product_root/%d", exampleProductRoot.ID), nil)
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusInternalServerError)
 	})
@@ -415,6 +541,7 @@ func TestProductRootDeletionHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("/v1/product_root/%d", exampleProductRoot.ID), nil)
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusInternalServerError)
 	})
@@ -439,10 +566,39 @@ func TestProductRootDeletionHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("/v1/product_root/%d", exampleProductRoot.ID), nil)
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusInternalServerError)
 	})
 
+	t.Run("with error archiving product images", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetProductRoot", mock.Anything, exampleProductRoot.ID).
+			Return(exampleProductRoot, nil)
+		testUtil.Mock.ExpectBegin()
+		testUtil.MockDB.On("ArchiveProductVariantBridgesWithProductRootID", mock.Anything, exampleProductRoot.ID).
+			Return(buildTestTime(), nil)
+		testUtil.MockDB.On("ArchiveProductOptionValuesWithProductRootID", mock.Anything, exampleProductRoot.ID).
+			Return(buildTestTime(), nil)
+		testUtil.MockDB.On("ArchiveProductOptionsWithProductRootID", mock.Anything, exampleProductRoot.ID).
+			Return(buildTestTime(), nil)
+		testUtil.MockDB.On("ArchiveProductsWithProductRootID", mock.Anything, exampleProductRoot.ID).
+			Return(buildTestTime(), nil)
+		testUtil.MockDB.On("ArchiveProductImagesWithProductRootID", mock.Anything, exampleProductRoot.ID).
+			Return(buildTestTime(), generateArbitraryError())
+		testUtil.Mock.ExpectRollback()
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("/v1/product_root/%d", exampleProductRoot.ID), nil)
+		assert.NoError(t, err)
+
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+		ensureExpectationsWereMet(t, testUtil.Mock)
+	})
+
 	t.Run("with error archiving product root", func(*testing.T) {
 		testUtil := setupTestVariablesWithMock(t)
 		testUtil.MockDB.On("GetProductRoot", mock.Anything, exampleProductRoot.ID).
@@ -456,6 +612,8 @@ func TestProductRootDeletionHandler(t *testing.T) {
 			Return(buildTestTime(), nil)
 		testUtil.MockDB.On("ArchiveProductsWithProductRootID", mock.Anything, exampleProductRoot.ID).
 			Return(buildTestTime(), nil)
+		testUtil.MockDB.On("ArchiveProductImagesWithProductRootID", mock.Anything, exampleProductRoot.ID).
+			Return(buildTestTime(), nil)
 		testUtil.MockDB.On("DeleteProductRoot", mock.Anything, exampleProductRoot.ID).
 			Return(buildTestTime(), generateArbitraryError())
 		testUtil.Mock.ExpectRollback()
@@ -465,6 +623,7 @@ func TestProductRootDeletionHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("/v1/product_root/%d", exampleProductRoot.ID), nil)
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusInternalServerError)
 	})
@@ -482,16 +641,56 @@ func TestProductRootDeletionHandler(t *testing.T) {
 			Return(buildTestTime(), nil)
 		testUtil.MockDB.On("ArchiveProductsWithProductRootID", mock.Anything, exampleProductRoot.ID).
 			Return(buildTestTime(), nil)
+		testUtil.MockDB.On("ArchiveProductImagesWithProductRootID", mock.Anything, exampleProductRoot.ID).
+			Return(buildTestTime(), nil)
 		testUtil.MockDB.On("DeleteProductRoot", mock.Anything, exampleProductRoot.ID).
 			Return(buildTestTime(), nil)
 		testUtil.Mock.ExpectCommit().WillReturnError(generateArbitraryError())
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, ProductRootArchivedWebhookEvent).
+			Return([]models.Webhook{}, nil)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("/v1/product_root/%d", exampleProductRoot.ID), nil)
+		assert.NoError(t, err)
+
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+		ensureExpectationsWereMet(t, testUtil.Mock)
+	})
+
+	t.Run("with error queueing webhook deliveries", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetProductRoot", mock.Anything, exampleProductRoot.ID).
+			Return(exampleProductRoot, nil)
+		testUtil.Mock.ExpectBegin()
+		testUtil.MockDB.On("ArchiveProductVariantBridgesWithProductRootID", mock.Anything, exampleProductRoot.ID).
+			Return(buildTestTime(), nil)
+		testUtil.MockDB.On("ArchiveProductOptionValuesWithProductRootID", mock.Anything, exampleProductRoot.ID).
+			Return(buildTestTime(), nil)
+		testUtil.MockDB.On("ArchiveProductOptionsWithProductRootID", mock.Anything, exampleProductRoot.ID).
+			Return(buildTestTime(), nil)
+		testUtil.MockDB.On("ArchiveProductsWithProductRootID", mock.Anything, exampleProductRoot.ID).
+			Return(buildTestTime(), nil)
+		testUtil.MockDB.On("ArchiveProductImagesWithProductRootID", mock.Anything, exampleProductRoot.ID).
+			Return(buildTestTime(), nil)
+		testUtil.MockDB.On("DeleteProductRoot", mock.Anything, exampleProductRoot.ID).
+			Return(buildTestTime(), nil)
+		testUtil.Mock.ExpectRollback()
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, ProductRootArchivedWebhookEvent).
+			Return([]models.Webhook{}, generateArbitraryError())
 		config := buildServerConfigFromTestUtil(testUtil)
 		SetupAPIRouter(config)
 
 		req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("/v1/product_root/%d", exampleProductRoot.ID), nil)
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+		ensureExpectationsWereMet(t, testUtil.Mock)
 	})
 }
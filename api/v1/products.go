@@ -16,12 +16,6 @@ import (
 	"github.com/imdario/mergo"
 )
 
-const (
-	ProductCreatedWebhookEvent  = "product_created"
-	ProductUpdatedWebhookEvent  = "product_updated"
-	ProductArchivedWebhookEvent = "product_archived"
-)
-
 // newProductFromCreationInput creates a new product from a ProductCreationInput
 func newProductFromCreationInput(in *models.ProductCreationInput) *models.Product {
 	np := &models.Product{
@@ -93,6 +87,12 @@ func buildSingleProductHandler(db *sql.DB, client database.Storer) http.HandlerF
 			return
 		}
 
+		product.Images, err = retrieveImagesForProduct(db, client, product)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "retrieving product images from database")
+			return
+		}
+
 		json.NewEncoder(res).Encode(product)
 	}
 }
@@ -124,7 +124,7 @@ func buildProductListHandler(db *sql.DB, client database.Storer) http.HandlerFun
 	}
 }
 
-func buildProductDeletionHandler(db *sql.DB, client database.Storer, webhookExecutor WebhookExecutor) http.HandlerFunc {
+func buildProductDeletionHandler(db *sql.DB, client database.Storer) http.HandlerFunc {
 	// ProductDeletionHandler is a request handler that deletes a single product
 	return func(res http.ResponseWriter, req *http.Request) {
 		sku := chi.URLParam(req, "sku")
@@ -158,29 +158,26 @@ func buildProductDeletionHandler(db *sql.DB, client database.Storer, webhookExec
 			notifyOfInternalIssue(res, err, "archive product in database")
 			return
 		}
+		product.ArchivedOn = &models.Dairytime{Time: archiveTime}
 
-		err = tx.Commit()
+		err = enqueueWebhookDeliveries(tx, client, ProductArchivedWebhookEvent, product)
 		if err != nil {
-			notifyOfInternalIssue(res, err, "close out transaction")
+			tx.Rollback()
+			notifyOfInternalIssue(res, err, "queue webhook deliveries")
 			return
 		}
-		product.ArchivedOn = &models.Dairytime{Time: archiveTime}
 
-		webhooks, err := client.GetWebhooksByEventType(db, ProductArchivedWebhookEvent)
-		if err != nil && err != sql.ErrNoRows {
-			notifyOfInternalIssue(res, err, "retrieve webhooks from database")
+		err = tx.Commit()
+		if err != nil {
+			notifyOfInternalIssue(res, err, "close out transaction")
 			return
 		}
 
-		for _, wh := range webhooks {
-			go webhookExecutor.CallWebhook(wh, product, db, client)
-		}
-
 		json.NewEncoder(res).Encode(product)
 	}
 }
 
-func buildProductUpdateHandler(db *sql.DB, client database.Storer, webhookExecutor WebhookExecutor) http.HandlerFunc {
+func buildProductUpdateHandler(db *sql.DB, client database.Storer) http.HandlerFunc {
 	// ProductUpdateHandler is a request handler that can update products
 	return func(res http.ResponseWriter, req *http.Request) {
 		sku := chi.URLParam(req, "sku")
@@ -202,27 +199,42 @@ func buildProductUpdateHandler(db *sql.DB, client database.Storer, webhookExecut
 		}
 
 		mergo.Merge(updatedProduct, existingProduct)
+		// mergo doesn't merge time.Time fields, so without this they'd look like they changed to the zero time
+		if updatedProduct.AvailableOn.IsZero() {
+			updatedProduct.AvailableOn = existingProduct.AvailableOn
+		}
+		updatedProduct.CreatedOn = existingProduct.CreatedOn
 
 		if !restrictedStringIsValid(updatedProduct.SKU) {
 			notifyOfInvalidRequestBody(res, fmt.Errorf("The sku received (%s) is invalid", updatedProduct.SKU))
 			return
 		}
 
-		updatedTime, err := client.UpdateProduct(db, updatedProduct)
+		tx, err := db.Begin()
+		if err != nil {
+			notifyOfInternalIssue(res, err, "create new database transaction")
+			return
+		}
+
+		updatedTime, err := client.UpdateProduct(tx, updatedProduct)
 		if err != nil {
+			tx.Rollback()
 			notifyOfInternalIssue(res, err, "update product in database")
 			return
 		}
 		updatedProduct.UpdatedOn = &models.Dairytime{Time: updatedTime}
 
-		webhooks, err := client.GetWebhooksByEventType(db, ProductUpdatedWebhookEvent)
-		if err != nil && err != sql.ErrNoRows {
-			notifyOfInternalIssue(res, err, "retrieve webhooks from database")
+		err = enqueueWebhookChangeDeliveries(tx, client, ProductUpdatedWebhookEvent, existingProduct, updatedProduct)
+		if err != nil {
+			tx.Rollback()
+			notifyOfInternalIssue(res, err, "queue webhook deliveries")
 			return
 		}
 
-		for _, wh := range webhooks {
-			go webhookExecutor.CallWebhook(wh, updatedProduct, db, client)
+		err = tx.Commit()
+		if err != nil {
+			notifyOfInternalIssue(res, err, "close out transaction")
+			return
 		}
 
 		json.NewEncoder(res).Encode(updatedProduct)
@@ -232,7 +244,10 @@ func buildProductUpdateHandler(db *sql.DB, client database.Storer, webhookExecut
 func createProductsInDBFromOptions(client database.Storer, tx *sql.Tx, r *models.ProductRoot, input *models.ProductCreationInput, createdOptions []models.ProductOption) ([]models.Product, error) {
 	var err error
 	createdProducts := []models.Product{}
-	productsToCreate := buildProductsFromOptions(input, createdOptions)
+	productsToCreate, err := buildProductsFromOptions(input, createdOptions)
+	if err != nil {
+		return nil, err
+	}
 	for _, p := range productsToCreate {
 		p.ProductRootID = r.ID
 		p.ID, p.CreatedOn, p.AvailableOn, err = client.CreateProduct(tx, p)
@@ -254,10 +269,47 @@ func createProductsInDBFromOptions(client database.Storer, tx *sql.Tx, r *models
 	return createdProducts, nil
 }
 
-func buildProductCreationHandler(db *sql.DB, client database.Storer, imager images.ImageStorer, webhookExecutor WebhookExecutor) http.HandlerFunc {
+// enqueueProductCreationWebhookDeliveries queues the events for a newly created product, as well as for
+// the product root, options, values, and images that were created along with it
+func enqueueProductCreationWebhookDeliveries(tx database.Querier, client database.Storer, productRoot *models.ProductRoot) error {
+	err := enqueueWebhookDeliveries(tx, client, ProductCreatedWebhookEvent, productRoot)
+	if err != nil {
+		return err
+	}
+
+	err = enqueueWebhookDeliveries(tx, client, ProductRootCreatedWebhookEvent, productRoot)
+	if err != nil {
+		return err
+	}
+
+	for _, option := range productRoot.Options {
+		err = enqueueProductOptionCreationWebhookDeliveries(tx, client, option)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, image := range productRoot.Images {
+		err = enqueueWebhookDeliveries(tx, client, ProductImageCreatedWebhookEvent, image)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func buildProductCreationHandler(db *sql.DB, client database.Storer, imager images.ImageStorer, uploadLimits UploadLimits, fetcher *imageFetcher) http.HandlerFunc {
 	return func(res http.ResponseWriter, req *http.Request) {
-		productInput := &models.ProductCreationInput{}
-		err := validateRequestInput(req, productInput)
+		var (
+			err          error
+			uploads      []pendingProductImage
+			productInput = &models.ProductCreationInput{}
+		)
+		if isMultipartRequest(req) {
+			uploads, err = parseMultipartImageRequest(res, req, uploadLimits, productInput)
+		} else {
+			err = validateRequestInput(req, productInput)
+		}
 		if err != nil {
 			notifyOfInvalidRequestBody(res, err)
 			return
@@ -280,6 +332,37 @@ func buildProductCreationHandler(db *sql.DB, client database.Storer, imager imag
 			return
 		}
 
+		// nor can we create variants whose skus are invalid or already taken
+		if len(productInput.Options) > 0 {
+			variantSKUs, err := validateVariantSKUsFromInput(productInput)
+			if err != nil {
+				notifyOfInvalidRequestBody(res, err)
+				return
+			}
+
+			for _, sku := range variantSKUs {
+				exists, err := client.ProductWithSKUExists(db, sku)
+				if err != nil && err != sql.ErrNoRows {
+					notifyOfInternalIssue(res, err, "check for existing product skus in database")
+					return
+				} else if exists {
+					notifyOfInvalidRequestBody(res, fmt.Errorf("product with sku '%s' already exists", sku))
+					return
+				}
+			}
+		}
+
+		pendingImages, err := prepareProductImages(imager, fetcher, uploadLimits, productInput.Images, uploads)
+		if err != nil {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
+
+		if err = storeProductImages(imager, pendingImages); err != nil {
+			notifyOfInternalIssue(res, err, "store product images")
+			return
+		}
+
 		tx, err := db.Begin()
 		if err != nil {
 			notifyOfInternalIssue(res, err, "create new database transaction")
@@ -287,6 +370,8 @@ func buildProductCreationHandler(db *sql.DB, client database.Storer, imager imag
 		}
 
 		productRoot := createProductRootFromProduct(newProduct)
+		productRoot.SKUTemplate = productInput.SKUTemplate
+		productRoot.OptionSummaryTemplate = productInput.OptionSummaryTemplate
 		productRoot.ID, productRoot.CreatedOn, err = client.CreateProductRoot(tx, productRoot)
 		if err != nil {
 			tx.Rollback()
@@ -294,7 +379,7 @@ func buildProductCreationHandler(db *sql.DB, client database.Storer, imager imag
 			return
 		}
 
-		productRoot.Images, productRoot.PrimaryImageID, err = handleProductCreationImages(tx, client, imager, productInput.Images, productInput.SKU, productRoot.ID)
+		productRoot.Images, productRoot.PrimaryImageID, err = handleProductCreationImages(tx, client, pendingImages, productRoot.ID, 0)
 		if err != nil {
 			tx.Rollback()
 			notifyOfInternalIssue(res, err, "insert product images in database")
@@ -345,22 +430,19 @@ func buildProductCreationHandler(db *sql.DB, client database.Storer, imager imag
 			}
 		}
 
-		err = tx.Commit()
+		err = enqueueProductCreationWebhookDeliveries(tx, client, productRoot)
 		if err != nil {
-			notifyOfInternalIssue(res, err, "close out transaction")
+			tx.Rollback()
+			notifyOfInternalIssue(res, err, "queue webhook deliveries")
 			return
 		}
 
-		webhooks, err := client.GetWebhooksByEventType(db, ProductCreatedWebhookEvent)
-		if err != nil && err != sql.ErrNoRows {
-			notifyOfInternalIssue(res, err, "retrieve webhooks from database")
+		err = tx.Commit()
+		if err != nil {
+			notifyOfInternalIssue(res, err, "close out transaction")
 			return
 		}
 
-		for _, wh := range webhooks {
-			go webhookExecutor.CallWebhook(wh, productRoot, db, client)
-		}
-
 		res.WriteHeader(http.StatusCreated)
 		json.NewEncoder(res).Encode(productRoot)
 	}
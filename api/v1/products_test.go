@@ -173,6 +173,7 @@ func TestProductExistenceHandler(t *testing.T) {
 
 		req, err := http.NewRequest("HEAD", "/v1/product/example", nil)
 		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 
 		assertStatusCode(t, testUtil, http.StatusOK)
@@ -185,6 +186,7 @@ func TestProductExistenceHandler(t *testing.T) {
 
 		req, err := http.NewRequest("HEAD", "/v1/product/example", nil)
 		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 
 		assertStatusCode(t, testUtil, http.StatusNotFound)
@@ -197,6 +199,7 @@ func TestProductExistenceHandler(t *testing.T) {
 
 		req, err := http.NewRequest("HEAD", "/v1/product/example", nil)
 		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 
 		assertStatusCode(t, testUtil, http.StatusNotFound)
@@ -228,6 +231,7 @@ func TestProductRetrievalHandler(t *testing.T) {
 		testUtil := setupTestVariablesWithMock(t)
 
 		testUtil.MockDB.On("GetProductBySKU", mock.Anything, exampleProduct.SKU).Return(exampleProduct, nil)
+		testUtil.MockDB.On("GetProductImagesByProductID", mock.Anything, exampleProduct.ID).Return([]models.ProductImage{{ID: 1}}, nil)
 		config := buildServerConfigFromTestUtil(testUtil)
 		SetupAPIRouter(config)
 
@@ -236,6 +240,39 @@ func TestProductRetrievalHandler(t *testing.T) {
 
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusOK)
+		assert.Contains(t, testUtil.Response.Body.String(), `"images":[{"id":1,`)
+	})
+
+	t.Run("without variant images", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+
+		testUtil.MockDB.On("GetProductBySKU", mock.Anything, exampleProduct.SKU).Return(exampleProduct, nil)
+		testUtil.MockDB.On("GetProductImagesByProductID", mock.Anything, exampleProduct.ID).Return([]models.ProductImage{}, nil)
+		testUtil.MockDB.On("GetProductImagesByProductRootID", mock.Anything, exampleProduct.ProductRootID).Return([]models.ProductImage{{ID: 2}}, nil)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/product/%s", exampleProduct.SKU), nil)
+		assert.NoError(t, err)
+
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusOK)
+		assert.Contains(t, testUtil.Response.Body.String(), `"images":[{"id":2,`)
+	})
+
+	t.Run("with error retrieving images", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+
+		testUtil.MockDB.On("GetProductBySKU", mock.Anything, exampleProduct.SKU).Return(exampleProduct, nil)
+		testUtil.MockDB.On("GetProductImagesByProductID", mock.Anything, exampleProduct.ID).Return([]models.ProductImage{}, generateArbitraryError())
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/product/%s", exampleProduct.SKU), nil)
+		assert.NoError(t, err)
+
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusInternalServerError)
 	})
 
 	t.Run("with DB error", func(*testing.T) {
@@ -372,12 +409,21 @@ func TestProductUpdateHandler(t *testing.T) {
 
 	t.Run("normal operation", func(*testing.T) {
 		testUtil := setupTestVariablesWithMock(t)
+		testUtil.Mock.ExpectBegin()
+		testUtil.Mock.ExpectCommit()
 		testUtil.MockDB.On("GetProductBySKU", mock.Anything, exampleProduct.SKU).
 			Return(exampleProduct, nil).Once()
 		testUtil.MockDB.On("UpdateProduct", mock.Anything, mock.Anything).
 			Return(buildTestTime(), nil).Once()
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
 		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, ProductUpdatedWebhookEvent).
 			Return([]models.Webhook{exampleWebhook}, nil).Once()
+		testUtil.MockDB.On("CreateWebhookDelivery", mock.Anything, mock.MatchedBy(func(d *models.WebhookDelivery) bool {
+			return d.EventType == ProductUpdatedWebhookEvent &&
+				strings.Contains(d.Payload, `"data":{"id":2,`) &&
+				strings.Contains(d.Payload, `"previous":{"name":"Skateboard","price":99.99,"quantity":123,"sku":"skateboard"}`) &&
+				strings.Contains(d.Payload, `"changed_fields":["name","price","quantity","sku"]`)
+		})).Return(uint64(1), buildTestTime(), nil).Once()
 		config := buildServerConfigFromTestUtil(testUtil)
 		SetupAPIRouter(config)
 
@@ -387,6 +433,7 @@ func TestProductUpdateHandler(t *testing.T) {
 			strings.NewReader(exampleProductUpdateInput),
 		)
 		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 
 		assertStatusCode(t, testUtil, http.StatusOK)
@@ -407,6 +454,7 @@ func TestProductUpdateHandler(t *testing.T) {
 		)
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusNotFound)
 	})
@@ -424,6 +472,7 @@ func TestProductUpdateHandler(t *testing.T) {
 		)
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusInternalServerError)
 	})
@@ -440,6 +489,7 @@ func TestProductUpdateHandler(t *testing.T) {
 		)
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusBadRequest)
 	})
@@ -458,12 +508,35 @@ func TestProductUpdateHandler(t *testing.T) {
 		)
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusBadRequest)
 	})
 
+	t.Run("with error beginning transaction", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.Mock.ExpectBegin().WillReturnError(generateArbitraryError())
+		testUtil.MockDB.On("GetProductBySKU", mock.Anything, exampleProduct.SKU).
+			Return(exampleProduct, nil).Once()
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(
+			http.MethodPatch,
+			fmt.Sprintf("/v1/product/%s", exampleProduct.SKU),
+			strings.NewReader(exampleProductUpdateInput),
+		)
+		assert.NoError(t, err)
+
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+	})
+
 	t.Run("with database error updating product", func(*testing.T) {
 		testUtil := setupTestVariablesWithMock(t)
+		testUtil.Mock.ExpectBegin()
+		testUtil.Mock.ExpectRollback()
 		testUtil.MockDB.On("GetProductBySKU", mock.Anything, exampleProduct.SKU).
 			Return(exampleProduct, nil).Once()
 		testUtil.MockDB.On("UpdateProduct", mock.Anything, mock.Anything).
@@ -478,16 +551,20 @@ func TestProductUpdateHandler(t *testing.T) {
 		)
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusInternalServerError)
 	})
 
 	t.Run("with error retrieving webhooks", func(*testing.T) {
 		testUtil := setupTestVariablesWithMock(t)
+		testUtil.Mock.ExpectBegin()
+		testUtil.Mock.ExpectRollback()
 		testUtil.MockDB.On("GetProductBySKU", mock.Anything, exampleProduct.SKU).
 			Return(exampleProduct, nil).Once()
 		testUtil.MockDB.On("UpdateProduct", mock.Anything, mock.Anything).
 			Return(buildTestTime(), nil).Once()
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
 		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, ProductUpdatedWebhookEvent).
 			Return([]models.Webhook{}, generateArbitraryError()).Once()
 		config := buildServerConfigFromTestUtil(testUtil)
@@ -500,6 +577,34 @@ func TestProductUpdateHandler(t *testing.T) {
 		)
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+		ensureExpectationsWereMet(t, testUtil.Mock)
+	})
+
+	t.Run("with error committing transaction", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.Mock.ExpectBegin()
+		testUtil.Mock.ExpectCommit().WillReturnError(generateArbitraryError())
+		testUtil.MockDB.On("GetProductBySKU", mock.Anything, exampleProduct.SKU).
+			Return(exampleProduct, nil).Once()
+		testUtil.MockDB.On("UpdateProduct", mock.Anything, mock.Anything).
+			Return(buildTestTime(), nil).Once()
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, ProductUpdatedWebhookEvent).
+			Return([]models.Webhook{}, nil).Once()
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(
+			http.MethodPatch,
+			fmt.Sprintf("/v1/product/%s", exampleProduct.SKU),
+			strings.NewReader(exampleProductUpdateInput),
+		)
+		assert.NoError(t, err)
+
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusInternalServerError)
 	})
@@ -527,14 +632,19 @@ func TestProductDeletionHandler(t *testing.T) {
 			Return(buildTestTime(), nil).Once()
 		testUtil.MockDB.On("DeleteProduct", mock.Anything, exampleProduct.ID).
 			Return(buildTestTime(), nil).Once()
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
 		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, ProductArchivedWebhookEvent).
 			Return([]models.Webhook{exampleWebhook}, nil).Once()
+		testUtil.MockDB.On("CreateWebhookDelivery", mock.Anything, mock.MatchedBy(func(d *models.WebhookDelivery) bool {
+			return d.EventType == ProductArchivedWebhookEvent && strings.Contains(d.Payload, `"archived_on":"`)
+		})).Return(uint64(1), buildTestTime(), nil).Once()
 		config := buildServerConfigFromTestUtil(testUtil)
 		SetupAPIRouter(config)
 
 		req, err := http.NewRequest(http.MethodDelete, "/v1/product/example", nil)
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusOK)
 	})
@@ -549,6 +659,7 @@ func TestProductDeletionHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodDelete, "/v1/product/example", nil)
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusNotFound)
 	})
@@ -563,6 +674,7 @@ func TestProductDeletionHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodDelete, "/v1/product/example", nil)
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusInternalServerError)
 	})
@@ -578,6 +690,7 @@ func TestProductDeletionHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodDelete, "/v1/product/example", nil)
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusInternalServerError)
 	})
@@ -596,6 +709,7 @@ func TestProductDeletionHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodDelete, "/v1/product/example", nil)
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusInternalServerError)
 	})
@@ -618,6 +732,7 @@ func TestProductDeletionHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodDelete, "/v1/product/example", nil)
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusInternalServerError)
 	})
@@ -632,26 +747,31 @@ func TestProductDeletionHandler(t *testing.T) {
 			Return(buildTestTime(), nil).Once()
 		testUtil.MockDB.On("DeleteProduct", mock.Anything, exampleProduct.ID).
 			Return(buildTestTime(), nil).Once()
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, ProductArchivedWebhookEvent).
+			Return([]models.Webhook{}, nil).Once()
 		config := buildServerConfigFromTestUtil(testUtil)
 		SetupAPIRouter(config)
 
 		req, err := http.NewRequest(http.MethodDelete, "/v1/product/example", nil)
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusInternalServerError)
 	})
 
-	t.Run("with existent product", func(*testing.T) {
+	t.Run("with error queueing webhook deliveries", func(*testing.T) {
 		testUtil := setupTestVariablesWithMock(t)
 		testUtil.Mock.ExpectBegin()
-		testUtil.Mock.ExpectCommit()
+		testUtil.Mock.ExpectRollback()
 		testUtil.MockDB.On("GetProductBySKU", mock.Anything, exampleProduct.SKU).
 			Return(exampleProduct, nil).Once()
 		testUtil.MockDB.On("DeleteProductVariantBridgeByProductID", mock.Anything, exampleProduct.ID).
 			Return(buildTestTime(), nil).Once()
 		testUtil.MockDB.On("DeleteProduct", mock.Anything, exampleProduct.ID).
 			Return(buildTestTime(), nil).Once()
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
 		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, ProductArchivedWebhookEvent).
 			Return([]models.Webhook{}, generateArbitraryError()).Once()
 		config := buildServerConfigFromTestUtil(testUtil)
@@ -660,6 +780,7 @@ func TestProductDeletionHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodDelete, "/v1/product/example", nil)
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusInternalServerError)
 	})
@@ -848,6 +969,8 @@ func TestProductCreationHandler(t *testing.T) {
 		testUtil := setupTestVariablesWithMock(t)
 		testUtil.MockDB.On("ProductRootWithSKUPrefixExists", mock.Anything, exampleProduct.SKU).
 			Return(false, nil)
+		testUtil.MockDB.On("ProductWithSKUExists", mock.Anything, mock.Anything).
+			Return(false, nil)
 		testUtil.Mock.ExpectBegin()
 		testUtil.MockDB.On("CreateProductRoot", mock.Anything, mock.Anything).
 			Return(exampleRoot.ID, buildTestTime(), nil)
@@ -860,13 +983,29 @@ func TestProductCreationHandler(t *testing.T) {
 		testUtil.MockDB.On("CreateProductOptionValue", mock.Anything, mock.Anything).
 			Return(expectedCreatedProductOption.Values[0].ID, buildTestTime(), nil)
 		testUtil.Mock.ExpectCommit()
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
 		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, ProductCreatedWebhookEvent).
 			Return([]models.Webhook{exampleWebhook}, nil).Once()
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, ProductRootCreatedWebhookEvent).
+			Return([]models.Webhook{}, nil)
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, ProductOptionCreatedWebhookEvent).
+			Return([]models.Webhook{}, nil)
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, ProductOptionValueCreatedWebhookEvent).
+			Return([]models.Webhook{}, nil)
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, ProductImageCreatedWebhookEvent).
+			Return([]models.Webhook{}, nil)
+		testUtil.MockDB.On("CreateWebhookDelivery", mock.Anything, mock.Anything).
+			Return(uint64(1), buildTestTime(), nil)
 		config := buildServerConfigFromTestUtil(testUtil)
 		SetupAPIRouter(config)
 
 		req, err := http.NewRequest(http.MethodPost, "/v1/product", strings.NewReader(exampleProductCreationInputWithOptions))
 		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 
 		assertStatusCode(t, testUtil, http.StatusCreated)
@@ -884,13 +1023,29 @@ func TestProductCreationHandler(t *testing.T) {
 		testUtil.MockDB.On("CreateMultipleProductVariantBridgesForProductID", mock.Anything, mock.Anything, mock.Anything).
 			Return(nil)
 		testUtil.Mock.ExpectCommit()
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
 		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, ProductCreatedWebhookEvent).
 			Return([]models.Webhook{exampleWebhook}, nil).Once()
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, ProductRootCreatedWebhookEvent).
+			Return([]models.Webhook{}, nil)
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, ProductOptionCreatedWebhookEvent).
+			Return([]models.Webhook{}, nil)
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, ProductOptionValueCreatedWebhookEvent).
+			Return([]models.Webhook{}, nil)
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, ProductImageCreatedWebhookEvent).
+			Return([]models.Webhook{}, nil)
+		testUtil.MockDB.On("CreateWebhookDelivery", mock.Anything, mock.Anything).
+			Return(uint64(1), buildTestTime(), nil)
 		config := buildServerConfigFromTestUtil(testUtil)
 		SetupAPIRouter(config)
 
 		req, err := http.NewRequest(http.MethodPost, "/v1/product", strings.NewReader(exampleProductCreationInput))
 		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 
 		assertStatusCode(t, testUtil, http.StatusCreated)
@@ -904,6 +1059,7 @@ func TestProductCreationHandler(t *testing.T) {
 
 		req, err := http.NewRequest(http.MethodPost, "/v1/product", strings.NewReader(exampleGarbageInput))
 		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 
 		assertStatusCode(t, testUtil, http.StatusBadRequest)
@@ -917,6 +1073,7 @@ func TestProductCreationHandler(t *testing.T) {
 
 		req, err := http.NewRequest(http.MethodPost, "/v1/product", strings.NewReader(badSKUUpdateJSON))
 		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 
 		assertStatusCode(t, testUtil, http.StatusBadRequest)
@@ -930,21 +1087,70 @@ func TestProductCreationHandler(t *testing.T) {
 
 		req, err := http.NewRequest(http.MethodPost, "/v1/product", strings.NewReader(exampleProductCreationInputWithOptions))
 		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 
 		assertStatusCode(t, testUtil, http.StatusBadRequest)
 	})
 
+	t.Run("with invalid rendered variant sku", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("ProductRootWithSKUPrefixExists", mock.Anything, exampleProduct.SKU).Return(false, nil)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		badTemplateInput := strings.Replace(exampleProductCreationInputWithOptions, `"sku": "skateboard",`, `"sku": "skateboard", "sku_template": "{{ .SKU }} 123",`, 1)
+		req, err := http.NewRequest(http.MethodPost, "/v1/product", strings.NewReader(badTemplateInput))
+		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+
+		assertStatusCode(t, testUtil, http.StatusBadRequest)
+	})
+
+	t.Run("with already existent variant sku", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("ProductRootWithSKUPrefixExists", mock.Anything, exampleProduct.SKU).Return(false, nil)
+		testUtil.MockDB.On("ProductWithSKUExists", mock.Anything, mock.Anything).Return(true, nil)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPost, "/v1/product", strings.NewReader(exampleProductCreationInputWithOptions))
+		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+
+		assertStatusCode(t, testUtil, http.StatusBadRequest)
+	})
+
+	t.Run("with error checking for existing variant sku", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("ProductRootWithSKUPrefixExists", mock.Anything, exampleProduct.SKU).Return(false, nil)
+		testUtil.MockDB.On("ProductWithSKUExists", mock.Anything, mock.Anything).Return(false, generateArbitraryError())
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPost, "/v1/product", strings.NewReader(exampleProductCreationInputWithOptions))
+		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+
+		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+	})
+
 	t.Run("when transaction fails to begin", func(*testing.T) {
 		testUtil := setupTestVariablesWithMock(t)
 		testUtil.MockDB.On("ProductRootWithSKUPrefixExists", mock.Anything, exampleProduct.SKU).
 			Return(false, nil)
+		testUtil.MockDB.On("ProductWithSKUExists", mock.Anything, mock.Anything).
+			Return(false, nil)
 		testUtil.Mock.ExpectBegin().WillReturnError(generateArbitraryError())
 		config := buildServerConfigFromTestUtil(testUtil)
 		SetupAPIRouter(config)
 
 		req, err := http.NewRequest(http.MethodPost, "/v1/product", strings.NewReader(exampleProductCreationInputWithOptions))
 		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 
 		assertStatusCode(t, testUtil, http.StatusInternalServerError)
@@ -954,6 +1160,8 @@ func TestProductCreationHandler(t *testing.T) {
 		testUtil := setupTestVariablesWithMock(t)
 		testUtil.MockDB.On("ProductRootWithSKUPrefixExists", mock.Anything, exampleProduct.SKU).
 			Return(false, nil)
+		testUtil.MockDB.On("ProductWithSKUExists", mock.Anything, mock.Anything).
+			Return(false, nil)
 		testUtil.Mock.ExpectBegin()
 		testUtil.MockDB.On("CreateProductRoot", mock.Anything, mock.Anything).
 			Return(exampleRoot.ID, buildTestTime(), generateArbitraryError())
@@ -963,6 +1171,7 @@ func TestProductCreationHandler(t *testing.T) {
 
 		req, err := http.NewRequest(http.MethodPost, "/v1/product", strings.NewReader(exampleProductCreationInputWithOptions))
 		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 
 		assertStatusCode(t, testUtil, http.StatusInternalServerError)
@@ -972,24 +1181,23 @@ func TestProductCreationHandler(t *testing.T) {
 		testUtil := setupTestVariablesWithMock(t)
 		testUtil.MockDB.On("ProductRootWithSKUPrefixExists", mock.Anything, exampleProduct.SKU).
 			Return(false, nil)
-		testUtil.Mock.ExpectBegin()
-		testUtil.MockDB.On("CreateProductRoot", mock.Anything, mock.Anything).
-			Return(exampleRoot.ID, buildTestTime(), nil)
-		testUtil.Mock.ExpectRollback()
-
 		testUtil.MockImageStorage.On("CreateThumbnails", mock.Anything).
 			Return(images.ProductImageSet{})
-		testUtil.MockImageStorage.On("StoreImages", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("uint")).
-			Return(&images.ProductImageLocations{}, generateArbitraryError())
+		testUtil.MockImageStorage.On("StoreImages", mock.Anything, mock.AnythingOfType("string")).
+			Return(images.ProductImageLocations{}, generateArbitraryError())
 
 		config := buildServerConfigFromTestUtil(testUtil)
 		SetupAPIRouter(config)
 
 		req, err := http.NewRequest(http.MethodPost, "/v1/product", strings.NewReader(exampleProductCreationInputWithImages))
 		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 
 		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+		// images are stored before the transaction is opened, so it never gets that far
+		ensureExpectationsWereMet(t, testUtil.Mock)
+		testUtil.MockDB.AssertNotCalled(t, "CreateProductRoot", mock.Anything, mock.Anything)
 	})
 
 	t.Run("where product creation fails", func(*testing.T) {
@@ -1007,6 +1215,7 @@ func TestProductCreationHandler(t *testing.T) {
 
 		req, err := http.NewRequest(http.MethodPost, "/v1/product", strings.NewReader(exampleProductCreationInput))
 		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 
 		assertStatusCode(t, testUtil, http.StatusInternalServerError)
@@ -1028,13 +1237,30 @@ func TestProductCreationHandler(t *testing.T) {
 		testUtil.MockDB.On("CreateProductOptionValue", mock.Anything, mock.Anything).
 			Return(expectedCreatedProductOption.Values[0].ID, buildTestTime(), nil)
 		testUtil.Mock.ExpectCommit()
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
 		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, ProductCreatedWebhookEvent).
 			Return([]models.Webhook{exampleWebhook}, nil).Once()
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, ProductRootCreatedWebhookEvent).
+			Return([]models.Webhook{}, nil)
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, ProductOptionCreatedWebhookEvent).
+			Return([]models.Webhook{}, nil)
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, ProductOptionValueCreatedWebhookEvent).
+			Return([]models.Webhook{}, nil)
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, ProductImageCreatedWebhookEvent).
+			Return([]models.Webhook{}, nil)
+		testUtil.MockDB.On("CreateWebhookDelivery", mock.Anything, mock.Anything).
+			Return(uint64(1), buildTestTime(), nil)
 
 		testUtil.MockImageStorage.On("CreateThumbnails", mock.Anything).
 			Return(images.ProductImageSet{})
-		testUtil.MockImageStorage.On("StoreImages", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("uint")).
-			Return(&images.ProductImageLocations{}, nil)
+		testUtil.MockImageStorage.On("StoreImages", mock.Anything, mock.AnythingOfType("string")).
+			Return(images.ProductImageLocations{}, nil)
+		testUtil.MockDB.On("GetProductImagesByContentHash", mock.Anything, mock.AnythingOfType("string")).
+			Return([]models.ProductImage{}, nil)
 		testUtil.MockDB.On("CreateProductImage", mock.Anything, mock.Anything).
 			Return(uint64(1), buildTestTime(), nil)
 		testUtil.MockDB.On("SetPrimaryProductImageForProduct", mock.Anything, mock.AnythingOfType("uint64"), mock.Anything).
@@ -1045,6 +1271,7 @@ func TestProductCreationHandler(t *testing.T) {
 
 		req, err := http.NewRequest(http.MethodPost, "/v1/product", strings.NewReader(exampleProductCreationInputWithNoPrimaryImages))
 		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 
 		assertStatusCode(t, testUtil, http.StatusCreated)
@@ -1066,13 +1293,30 @@ func TestProductCreationHandler(t *testing.T) {
 		testUtil.MockDB.On("CreateProductOptionValue", mock.Anything, mock.Anything).
 			Return(expectedCreatedProductOption.Values[0].ID, buildTestTime(), nil)
 		testUtil.Mock.ExpectCommit()
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
 		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, ProductCreatedWebhookEvent).
 			Return([]models.Webhook{exampleWebhook}, nil).Once()
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, ProductRootCreatedWebhookEvent).
+			Return([]models.Webhook{}, nil)
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, ProductOptionCreatedWebhookEvent).
+			Return([]models.Webhook{}, nil)
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, ProductOptionValueCreatedWebhookEvent).
+			Return([]models.Webhook{}, nil)
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, ProductImageCreatedWebhookEvent).
+			Return([]models.Webhook{}, nil)
+		testUtil.MockDB.On("CreateWebhookDelivery", mock.Anything, mock.Anything).
+			Return(uint64(1), buildTestTime(), nil)
 
 		testUtil.MockImageStorage.On("CreateThumbnails", mock.Anything).
 			Return(images.ProductImageSet{})
-		testUtil.MockImageStorage.On("StoreImages", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("uint")).
-			Return(&images.ProductImageLocations{}, nil)
+		testUtil.MockImageStorage.On("StoreImages", mock.Anything, mock.AnythingOfType("string")).
+			Return(images.ProductImageLocations{}, nil)
+		testUtil.MockDB.On("GetProductImagesByContentHash", mock.Anything, mock.AnythingOfType("string")).
+			Return([]models.ProductImage{}, nil)
 		testUtil.MockDB.On("CreateProductImage", mock.Anything, mock.Anything).
 			Return(uint64(1), buildTestTime(), nil)
 		testUtil.MockDB.On("SetPrimaryProductImageForProduct", mock.Anything, mock.AnythingOfType("uint64"), mock.Anything).
@@ -1083,6 +1327,7 @@ func TestProductCreationHandler(t *testing.T) {
 
 		req, err := http.NewRequest(http.MethodPost, "/v1/product", strings.NewReader(exampleProductCreationInputWithImages))
 		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 
 		assertStatusCode(t, testUtil, http.StatusCreated)
@@ -1104,13 +1349,91 @@ func TestProductCreationHandler(t *testing.T) {
 		testUtil.MockDB.On("CreateProductOptionValue", mock.Anything, mock.Anything).
 			Return(expectedCreatedProductOption.Values[0].ID, buildTestTime(), nil)
 		testUtil.Mock.ExpectCommit()
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, ProductCreatedWebhookEvent).
+			Return([]models.Webhook{exampleWebhook}, nil).Once()
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, ProductRootCreatedWebhookEvent).
+			Return([]models.Webhook{}, nil)
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, ProductOptionCreatedWebhookEvent).
+			Return([]models.Webhook{}, nil)
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, ProductOptionValueCreatedWebhookEvent).
+			Return([]models.Webhook{}, nil)
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, ProductImageCreatedWebhookEvent).
+			Return([]models.Webhook{}, nil)
+		testUtil.MockDB.On("CreateWebhookDelivery", mock.Anything, mock.Anything).
+			Return(uint64(1), buildTestTime(), nil)
+
+		testUtil.MockImageStorage.On("CreateThumbnails", mock.Anything).
+			Return(images.ProductImageSet{})
+		testUtil.MockImageStorage.On("StoreImages", mock.Anything, mock.AnythingOfType("string")).
+			Return(images.ProductImageLocations{}, nil)
+		testUtil.MockDB.On("GetProductImagesByContentHash", mock.Anything, mock.AnythingOfType("string")).
+			Return([]models.ProductImage{}, nil)
+		testUtil.MockDB.On("CreateProductImage", mock.Anything, mock.Anything).
+			Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("SetPrimaryProductImageForProduct", mock.Anything, mock.AnythingOfType("uint64"), mock.Anything).
+			Return(buildTestTime(), generateArbitraryError())
+
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPost, "/v1/product", strings.NewReader(exampleProductCreationInputWithNoPrimaryImages))
+		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+
+		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+	})
+
+	t.Run("with stored images and failure to set primary image for product", func(*testing.T) {
+		exampleLocations := images.ProductImageLocations{
+			images.ThumbnailRendition: "https://dairycart.com/product_images/skateboard/1/thumbnail.png",
+			images.OriginalRendition:  "https://dairycart.com/product_images/skateboard/1/original.png",
+		}
+
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("ProductRootWithSKUPrefixExists", mock.Anything, exampleProduct.SKU).
+			Return(false, nil)
+		testUtil.Mock.ExpectBegin()
+		testUtil.MockDB.On("CreateProductRoot", mock.Anything, mock.Anything).
+			Return(exampleRoot.ID, buildTestTime(), nil)
+		testUtil.MockDB.On("CreateProduct", mock.Anything, mock.Anything).
+			Return(exampleProduct.ID, buildTestTime(), buildTestTime(), nil)
+		testUtil.MockDB.On("CreateMultipleProductVariantBridgesForProductID", mock.Anything, mock.Anything, mock.Anything).
+			Return(nil)
+		testUtil.MockDB.On("CreateProductOption", mock.Anything, mock.Anything).
+			Return(expectedCreatedProductOption.ID, buildTestTime(), nil)
+		testUtil.MockDB.On("CreateProductOptionValue", mock.Anything, mock.Anything).
+			Return(expectedCreatedProductOption.Values[0].ID, buildTestTime(), nil)
+		testUtil.Mock.ExpectCommit()
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
 		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, ProductCreatedWebhookEvent).
 			Return([]models.Webhook{exampleWebhook}, nil).Once()
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, ProductRootCreatedWebhookEvent).
+			Return([]models.Webhook{}, nil)
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, ProductOptionCreatedWebhookEvent).
+			Return([]models.Webhook{}, nil)
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, ProductOptionValueCreatedWebhookEvent).
+			Return([]models.Webhook{}, nil)
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, ProductImageCreatedWebhookEvent).
+			Return([]models.Webhook{}, nil)
+		testUtil.MockDB.On("CreateWebhookDelivery", mock.Anything, mock.Anything).
+			Return(uint64(1), buildTestTime(), nil)
 
 		testUtil.MockImageStorage.On("CreateThumbnails", mock.Anything).
 			Return(images.ProductImageSet{})
-		testUtil.MockImageStorage.On("StoreImages", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("uint")).
-			Return(&images.ProductImageLocations{}, nil)
+		testUtil.MockImageStorage.On("StoreImages", mock.Anything, mock.AnythingOfType("string")).
+			Return(exampleLocations, nil)
+		testUtil.MockDB.On("GetProductImagesByContentHash", mock.Anything, mock.AnythingOfType("string")).
+			Return([]models.ProductImage{}, nil)
 		testUtil.MockDB.On("CreateProductImage", mock.Anything, mock.Anything).
 			Return(uint64(1), buildTestTime(), nil)
 		testUtil.MockDB.On("SetPrimaryProductImageForProduct", mock.Anything, mock.AnythingOfType("uint64"), mock.Anything).
@@ -1121,15 +1444,19 @@ func TestProductCreationHandler(t *testing.T) {
 
 		req, err := http.NewRequest(http.MethodPost, "/v1/product", strings.NewReader(exampleProductCreationInputWithNoPrimaryImages))
 		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 
 		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+		testUtil.MockImageStorage.AssertNotCalled(t, "DeleteImages", mock.Anything)
 	})
 
 	t.Run("with error creating product options", func(*testing.T) {
 		testUtil := setupTestVariablesWithMock(t)
 		testUtil.MockDB.On("ProductRootWithSKUPrefixExists", mock.Anything, exampleProduct.SKU).
 			Return(false, nil)
+		testUtil.MockDB.On("ProductWithSKUExists", mock.Anything, mock.Anything).
+			Return(false, nil)
 		testUtil.Mock.ExpectBegin()
 		testUtil.MockDB.On("CreateProductRoot", mock.Anything, mock.Anything).
 			Return(exampleRoot.ID, buildTestTime(), nil)
@@ -1142,6 +1469,7 @@ func TestProductCreationHandler(t *testing.T) {
 
 		req, err := http.NewRequest(http.MethodPost, "/v1/product", strings.NewReader(exampleProductCreationInputWithOptions))
 		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 
 		assertStatusCode(t, testUtil, http.StatusInternalServerError)
@@ -1151,6 +1479,8 @@ func TestProductCreationHandler(t *testing.T) {
 		testUtil := setupTestVariablesWithMock(t)
 		testUtil.MockDB.On("ProductRootWithSKUPrefixExists", mock.Anything, exampleProduct.SKU).
 			Return(false, nil)
+		testUtil.MockDB.On("ProductWithSKUExists", mock.Anything, mock.Anything).
+			Return(false, nil)
 		testUtil.Mock.ExpectBegin()
 		testUtil.MockDB.On("CreateProductRoot", mock.Anything, mock.Anything).
 			Return(exampleRoot.ID, buildTestTime(), nil)
@@ -1163,6 +1493,7 @@ func TestProductCreationHandler(t *testing.T) {
 
 		req, err := http.NewRequest(http.MethodPost, "/v1/product", strings.NewReader(exampleProductCreationInputWithOptions))
 		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 
 		assertStatusCode(t, testUtil, http.StatusInternalServerError)
@@ -1172,6 +1503,8 @@ func TestProductCreationHandler(t *testing.T) {
 		testUtil := setupTestVariablesWithMock(t)
 		testUtil.MockDB.On("ProductRootWithSKUPrefixExists", mock.Anything, exampleProduct.SKU).
 			Return(false, nil)
+		testUtil.MockDB.On("ProductWithSKUExists", mock.Anything, mock.Anything).
+			Return(false, nil)
 		testUtil.Mock.ExpectBegin()
 		testUtil.MockDB.On("CreateProductRoot", mock.Anything, mock.Anything).
 			Return(exampleRoot.ID, buildTestTime(), nil)
@@ -1189,6 +1522,7 @@ func TestProductCreationHandler(t *testing.T) {
 
 		req, err := http.NewRequest(http.MethodPost, "/v1/product", strings.NewReader(exampleProductCreationInputWithOptions))
 		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 
 		assertStatusCode(t, testUtil, http.StatusInternalServerError)
@@ -1198,6 +1532,8 @@ func TestProductCreationHandler(t *testing.T) {
 		testUtil := setupTestVariablesWithMock(t)
 		testUtil.MockDB.On("ProductRootWithSKUPrefixExists", mock.Anything, exampleProduct.SKU).
 			Return(false, nil)
+		testUtil.MockDB.On("ProductWithSKUExists", mock.Anything, mock.Anything).
+			Return(false, nil)
 		testUtil.Mock.ExpectBegin()
 		testUtil.MockDB.On("CreateProductRoot", mock.Anything, mock.Anything).
 			Return(exampleRoot.ID, buildTestTime(), nil)
@@ -1209,12 +1545,28 @@ func TestProductCreationHandler(t *testing.T) {
 			Return(expectedCreatedProductOption.ID, buildTestTime(), nil)
 		testUtil.MockDB.On("CreateProductOptionValue", mock.Anything, mock.Anything).
 			Return(expectedCreatedProductOption.Values[0].ID, buildTestTime(), nil)
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, ProductCreatedWebhookEvent).
+			Return([]models.Webhook{}, nil).Once()
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, ProductRootCreatedWebhookEvent).
+			Return([]models.Webhook{}, nil)
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, ProductOptionCreatedWebhookEvent).
+			Return([]models.Webhook{}, nil)
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, ProductOptionValueCreatedWebhookEvent).
+			Return([]models.Webhook{}, nil)
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, ProductImageCreatedWebhookEvent).
+			Return([]models.Webhook{}, nil)
 		testUtil.Mock.ExpectCommit().WillReturnError(generateArbitraryError())
 		config := buildServerConfigFromTestUtil(testUtil)
 		SetupAPIRouter(config)
 
 		req, err := http.NewRequest(http.MethodPost, "/v1/product", strings.NewReader(exampleProductCreationInputWithOptions))
 		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 
 		assertStatusCode(t, testUtil, http.StatusInternalServerError)
@@ -1224,6 +1576,8 @@ func TestProductCreationHandler(t *testing.T) {
 		testUtil := setupTestVariablesWithMock(t)
 		testUtil.MockDB.On("ProductRootWithSKUPrefixExists", mock.Anything, exampleProduct.SKU).
 			Return(false, nil)
+		testUtil.MockDB.On("ProductWithSKUExists", mock.Anything, mock.Anything).
+			Return(false, nil)
 		testUtil.Mock.ExpectBegin()
 		testUtil.MockDB.On("CreateProductRoot", mock.Anything, mock.Anything).
 			Return(exampleRoot.ID, buildTestTime(), nil)
@@ -1235,7 +1589,8 @@ func TestProductCreationHandler(t *testing.T) {
 			Return(expectedCreatedProductOption.ID, buildTestTime(), nil)
 		testUtil.MockDB.On("CreateProductOptionValue", mock.Anything, mock.Anything).
 			Return(expectedCreatedProductOption.Values[0].ID, buildTestTime(), nil)
-		testUtil.Mock.ExpectCommit()
+		testUtil.Mock.ExpectRollback()
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
 		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, ProductCreatedWebhookEvent).
 			Return([]models.Webhook{}, generateArbitraryError()).Once()
 		config := buildServerConfigFromTestUtil(testUtil)
@@ -1243,6 +1598,7 @@ func TestProductCreationHandler(t *testing.T) {
 
 		req, err := http.NewRequest(http.MethodPost, "/v1/product", strings.NewReader(exampleProductCreationInputWithOptions))
 		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 
 		assertStatusCode(t, testUtil, http.StatusInternalServerError)
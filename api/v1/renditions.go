@@ -0,0 +1,129 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+
+	"github.com/dairycart/dairycart/models/v1"
+	"github.com/dairycart/dairycart/storage/v1/database"
+	"github.com/dairycart/dairycart/storage/v1/images"
+
+	"github.com/pkg/errors"
+)
+
+// RegenerateProductImageRenditions rebuilds the renditions of every product image from its stored original,
+// so that changes to the rendition config apply to images uploaded before them. Renditions that are no longer
+// configured are left for orphaned image collection to delete. Images that can't be regenerated are logged and
+// skipped, and the number of images that were regenerated successfully is returned regardless. Originals with more
+// than maxPixels can't be regenerated.
+func RegenerateProductImageRenditions(db *sql.DB, client database.Storer, imager images.ImageStorer, maxPixels int64) (uint, error) {
+	// gather everything up front, so that the images being updated aren't the ones being read
+	productImages, err := getAllProductImages(db, client)
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		regenerated uint
+		failures    int
+		skuPrefixes = map[uint64]string{}
+	)
+	for i := range productImages {
+		productImage := &productImages[i]
+
+		skuPrefix, ok := skuPrefixes[productImage.ProductRootID]
+		if !ok {
+			root, err := client.GetProductRoot(db, productImage.ProductRootID)
+			if err != nil {
+				log.Printf("error retrieving product root %d for product image %d: %v\n", productImage.ProductRootID, productImage.ID, err)
+				failures++
+				continue
+			}
+			skuPrefix = root.SKUPrefix
+			skuPrefixes[productImage.ProductRootID] = skuPrefix
+		}
+
+		if err := regenerateProductImageRenditions(db, client, imager, maxPixels, skuPrefix, productImage); err != nil {
+			log.Printf("error regenerating renditions for product image %d: %v\n", productImage.ID, err)
+			failures++
+			continue
+		}
+		regenerated++
+	}
+
+	if failures > 0 {
+		return regenerated, fmt.Errorf("failed to regenerate renditions for %d of %d product images", failures, len(productImages))
+	}
+	return regenerated, nil
+}
+
+// getAllProductImages retrieves every product image that hasn't been deleted, as of a single snapshot of the
+// database. Images are read a batch at a time, in ID order, so that none get skipped or read twice.
+func getAllProductImages(db *sql.DB, client database.Storer) ([]models.ProductImage, error) {
+	tx, err := db.BeginTx(context.Background(), &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, errors.Wrap(err, "error starting transaction")
+	}
+	// nothing gets written, so there's nothing to lose by rolling back
+	defer tx.Rollback()
+
+	var (
+		productImages []models.ProductImage
+		lastID        uint64
+	)
+	for {
+		batch, err := client.GetProductImagesAfterID(tx, lastID, models.MaxLimit)
+		if err != nil {
+			return nil, errors.Wrap(err, "error retrieving product images")
+		}
+		productImages = append(productImages, batch...)
+		if len(batch) < models.MaxLimit {
+			return productImages, nil
+		}
+		lastID = batch[len(batch)-1].ID
+	}
+}
+
+func regenerateProductImageRenditions(db *sql.DB, client database.Storer, imager images.ImageStorer, maxPixels int64, skuPrefix string, productImage *models.ProductImage) error {
+	previousLocations := locationsForProductImage(*productImage)
+	originalLocation := previousLocations[images.OriginalRendition]
+	if originalLocation == "" {
+		return errors.New("product image has no original")
+	}
+
+	in, err := imager.ReadImage(originalLocation)
+	if err != nil {
+		return errors.Wrap(err, "error reading original image")
+	}
+	img, format, err := loadImage(in, maxPixels)
+	in.Close()
+	if err != nil {
+		return errors.Wrap(err, "error decoding original image")
+	}
+
+	// images from before content hashing get hashed now, so they can be deduplicated against
+	if productImage.ContentHash == "" {
+		productImage.ContentHash = images.ContentHash(img)
+		productImage.PerceptualHash = images.ComputePerceptualHash(img).String()
+	}
+
+	// the original is the source of truth here, so it stays exactly as it is
+	renditions := imager.CreateThumbnails(img)
+	delete(renditions.Renditions, images.OriginalRendition)
+	renditions.Format = format
+
+	locations, err := imager.StoreImages(renditions, productImageStorageKey(skuPrefix, *productImage))
+	if err != nil {
+		return errors.Wrap(err, "error storing renditions")
+	}
+	locations[images.OriginalRendition] = originalLocation
+
+	setProductImageLocations(productImage, locations)
+	if _, err = client.UpdateProductImage(db, productImage); err != nil {
+		return errors.Wrap(err, "error updating product image in database")
+	}
+
+	return nil
+}
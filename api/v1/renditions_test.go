@@ -0,0 +1,137 @@
+package api
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"io/ioutil"
+	"testing"
+
+	"github.com/dairycart/dairycart/models/v1"
+	"github.com/dairycart/dairycart/storage/v1/images"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegenerateProductImageRenditions(t *testing.T) {
+	originalPNG, err := base64.StdEncoding.DecodeString(smallGreenPNG)
+	require.NoError(t, err)
+
+	buildExampleImage := func() models.ProductImage {
+		return models.ProductImage{
+			ID:            10,
+			ProductRootID: 1,
+			ThumbnailURL:  "https://dairycart.com/product_images/skate/10/thumbnail.png",
+			MainURL:       "https://dairycart.com/product_images/skate/10/main.png",
+			OriginalURL:   "https://dairycart.com/product_images/skate/10/original.png",
+		}
+	}
+	newLocations := images.ProductImageLocations{
+		images.ThumbnailRendition: "https://dairycart.com/product_images/skate/10/thumbnail.jpg",
+		"square":                  "https://dairycart.com/product_images/skate/10/square.png",
+	}
+	imageSetWithOriginal := func() images.ProductImageSet {
+		img := image.NewRGBA(image.Rect(0, 0, 1, 1))
+		return images.ProductImageSet{Renditions: map[string]image.Image{
+			images.ThumbnailRendition: img,
+			"square":                  img,
+			images.OriginalRendition:  img,
+		}}
+	}
+
+	greenHash := contentHashForBase64Image(t, smallGreenPNG)
+
+	t.Run("optimal conditions", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.Mock.ExpectBegin()
+		testUtil.MockDB.On("GetProductImagesAfterID", mock.Anything, uint64(0), uint(models.MaxLimit)).Return([]models.ProductImage{buildExampleImage()}, nil)
+		testUtil.Mock.ExpectRollback()
+		testUtil.MockDB.On("GetProductRoot", mock.Anything, uint64(1)).Return(&models.ProductRoot{ID: 1, SKUPrefix: "skate"}, nil)
+		testUtil.MockImageStorage.On("ReadImage", buildExampleImage().OriginalURL).Return(ioutil.NopCloser(bytes.NewReader(originalPNG)), nil)
+		testUtil.MockImageStorage.On("CreateThumbnails", mock.Anything).Return(imageSetWithOriginal())
+		testUtil.MockImageStorage.On("StoreImages", mock.MatchedBy(func(in images.ProductImageSet) bool {
+			_, hasOriginal := in.Renditions[images.OriginalRendition]
+			return !hasOriginal && in.Format == images.PNG
+		}), "skate/10").Return(newLocations, nil)
+		testUtil.MockDB.On("UpdateProductImage", mock.Anything, mock.MatchedBy(func(in *models.ProductImage) bool {
+			return in.ThumbnailURL == newLocations[images.ThumbnailRendition] &&
+				in.MainURL == "" &&
+				in.OriginalURL == buildExampleImage().OriginalURL &&
+				len(in.Renditions) == 3 &&
+				in.ContentHash == greenHash &&
+				in.PerceptualHash != ""
+		})).Return(buildTestTime(), nil)
+
+		regenerated, err := RegenerateProductImageRenditions(testUtil.PlainDB, testUtil.MockDB, testUtil.MockImageStorage, images.DefaultMaxPixels)
+		assert.NoError(t, err)
+		assert.Equal(t, uint(1), regenerated)
+		// the main rendition isn't configured anymore, but it's left for orphaned image collection
+		testUtil.MockImageStorage.AssertNotCalled(t, "DeleteImages", mock.Anything)
+	})
+
+	t.Run("with content addressed image", func(*testing.T) {
+		exampleImage := models.ProductImage{
+			ID:            10,
+			ProductRootID: 1,
+			OriginalURL:   fmt.Sprintf("https://dairycart.com/product_images/%s/original.png", images.ContentKey(greenHash)),
+			ContentHash:   greenHash,
+		}
+
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.Mock.ExpectBegin()
+		testUtil.MockDB.On("GetProductImagesAfterID", mock.Anything, uint64(0), uint(models.MaxLimit)).Return([]models.ProductImage{exampleImage}, nil)
+		testUtil.Mock.ExpectRollback()
+		testUtil.MockDB.On("GetProductRoot", mock.Anything, uint64(1)).Return(&models.ProductRoot{ID: 1, SKUPrefix: "skate"}, nil)
+		testUtil.MockImageStorage.On("ReadImage", exampleImage.OriginalURL).Return(ioutil.NopCloser(bytes.NewReader(originalPNG)), nil)
+		testUtil.MockImageStorage.On("CreateThumbnails", mock.Anything).Return(imageSetWithOriginal())
+		testUtil.MockImageStorage.On("StoreImages", mock.Anything, images.ContentKey(greenHash)).Return(newLocations, nil)
+		testUtil.MockDB.On("UpdateProductImage", mock.Anything, mock.Anything).Return(buildTestTime(), nil)
+
+		regenerated, err := RegenerateProductImageRenditions(testUtil.PlainDB, testUtil.MockDB, testUtil.MockImageStorage, images.DefaultMaxPixels)
+		assert.NoError(t, err)
+		assert.Equal(t, uint(1), regenerated)
+	})
+
+	t.Run("with error listing images", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.Mock.ExpectBegin()
+		testUtil.MockDB.On("GetProductImagesAfterID", mock.Anything, uint64(0), uint(models.MaxLimit)).Return([]models.ProductImage{}, generateArbitraryError())
+		testUtil.Mock.ExpectRollback()
+
+		_, err := RegenerateProductImageRenditions(testUtil.PlainDB, testUtil.MockDB, testUtil.MockImageStorage, images.DefaultMaxPixels)
+		assert.Error(t, err)
+	})
+
+	t.Run("with unreadable original", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.Mock.ExpectBegin()
+		testUtil.MockDB.On("GetProductImagesAfterID", mock.Anything, uint64(0), uint(models.MaxLimit)).Return([]models.ProductImage{buildExampleImage()}, nil)
+		testUtil.Mock.ExpectRollback()
+		testUtil.MockDB.On("GetProductRoot", mock.Anything, uint64(1)).Return(&models.ProductRoot{ID: 1, SKUPrefix: "skate"}, nil)
+		testUtil.MockImageStorage.On("ReadImage", buildExampleImage().OriginalURL).Return(ioutil.NopCloser(bytes.NewReader(nil)), generateArbitraryError())
+
+		regenerated, err := RegenerateProductImageRenditions(testUtil.PlainDB, testUtil.MockDB, testUtil.MockImageStorage, images.DefaultMaxPixels)
+		assert.Error(t, err)
+		assert.Zero(t, regenerated)
+		testUtil.MockDB.AssertNotCalled(t, "UpdateProductImage", mock.Anything, mock.Anything)
+	})
+
+	t.Run("with error updating image", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.Mock.ExpectBegin()
+		testUtil.MockDB.On("GetProductImagesAfterID", mock.Anything, uint64(0), uint(models.MaxLimit)).Return([]models.ProductImage{buildExampleImage()}, nil)
+		testUtil.Mock.ExpectRollback()
+		testUtil.MockDB.On("GetProductRoot", mock.Anything, uint64(1)).Return(&models.ProductRoot{ID: 1, SKUPrefix: "skate"}, nil)
+		testUtil.MockImageStorage.On("ReadImage", buildExampleImage().OriginalURL).Return(ioutil.NopCloser(bytes.NewReader(originalPNG)), nil)
+		testUtil.MockImageStorage.On("CreateThumbnails", mock.Anything).Return(imageSetWithOriginal())
+		testUtil.MockImageStorage.On("StoreImages", mock.Anything, "skate/10").Return(newLocations, nil)
+		testUtil.MockDB.On("UpdateProductImage", mock.Anything, mock.Anything).Return(buildTestTime(), generateArbitraryError())
+
+		_, err := RegenerateProductImageRenditions(testUtil.PlainDB, testUtil.MockDB, testUtil.MockImageStorage, images.DefaultMaxPixels)
+		assert.Error(t, err)
+		testUtil.MockImageStorage.AssertNotCalled(t, "DeleteImages", mock.Anything)
+	})
+}
@@ -0,0 +1,146 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/dairycart/dairycart/models/v1"
+	"github.com/dairycart/dairycart/storage/v1/database"
+
+	"github.com/go-chi/chi"
+)
+
+// knownPermissions are the permissions routes can require. They have to match the ones roles are given in the roles
+// migration, or no role will ever be able to use the routes that need them.
+var knownPermissions = []string{
+	"products:write",
+	"discounts:read",
+	"discounts:write",
+	"users:read",
+	"users:reset_password",
+	"webhooks:read",
+	"webhooks:write",
+	"api_keys:write",
+	"events:read",
+}
+
+func validPermission(permission string) bool {
+	for _, p := range knownPermissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+func buildRoleListHandler(db *sql.DB, client database.Storer) http.HandlerFunc {
+	// RoleListHandler is a request handler that returns every role, along with its permissions
+	return func(res http.ResponseWriter, req *http.Request) {
+		roles, err := client.GetRoles(db)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve roles from the database")
+			return
+		}
+
+		rolesResponse := &ListResponse{
+			Page:  1,
+			Count: uint64(len(roles)),
+			Data:  roles,
+		}
+		json.NewEncoder(res).Encode(rolesResponse)
+	}
+}
+
+func buildUserRoleListHandler(db *sql.DB, client database.Storer) http.HandlerFunc {
+	// UserRoleListHandler is a request handler that returns the roles a user has been given
+	return func(res http.ResponseWriter, req *http.Request) {
+		userIDStr := chi.URLParam(req, "user_id")
+		// eating this error because the router should have ensured this is an integer
+		userID, _ := strconv.ParseUint(userIDStr, 10, 64)
+
+		_, err := client.GetUser(db, userID)
+		if err == sql.ErrNoRows {
+			respondThatRowDoesNotExist(req, res, "user ID", userIDStr)
+			return
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve user")
+			return
+		}
+
+		roles, err := client.GetRolesByUserID(db, userID)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve user roles from the database")
+			return
+		}
+
+		rolesResponse := &ListResponse{
+			Page:  1,
+			Count: uint64(len(roles)),
+			Data:  roles,
+		}
+		json.NewEncoder(res).Encode(rolesResponse)
+	}
+}
+
+func buildUserRoleAssignmentHandler(db *sql.DB, client database.Storer) http.HandlerFunc {
+	// UserRoleAssignmentHandler is a request handler that gives a user a role. Giving a user a role they already
+	// have does nothing.
+	return func(res http.ResponseWriter, req *http.Request) {
+		userIDStr := chi.URLParam(req, "user_id")
+		roleIDStr := chi.URLParam(req, "role_id")
+		// eating these errors because the router should have ensured these are integers
+		userID, _ := strconv.ParseUint(userIDStr, 10, 64)
+		roleID, _ := strconv.ParseUint(roleIDStr, 10, 64)
+
+		_, err := client.GetUser(db, userID)
+		if err == sql.ErrNoRows {
+			respondThatRowDoesNotExist(req, res, "user ID", userIDStr)
+			return
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve user")
+			return
+		}
+
+		_, err = client.GetRole(db, roleID)
+		if err == sql.ErrNoRows {
+			respondThatRowDoesNotExist(req, res, "role", roleIDStr)
+			return
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve role")
+			return
+		}
+
+		userRole := &models.UserRole{UserID: userID, RoleID: roleID}
+		userRole.CreatedOn, err = client.AssignRoleToUser(db, userID, roleID)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "assign role to user")
+			return
+		}
+
+		json.NewEncoder(res).Encode(userRole)
+	}
+}
+
+func buildUserRoleRemovalHandler(db *sql.DB, client database.Storer) http.HandlerFunc {
+	// UserRoleRemovalHandler is a request handler that takes a role away from a user
+	return func(res http.ResponseWriter, req *http.Request) {
+		userIDStr := chi.URLParam(req, "user_id")
+		roleIDStr := chi.URLParam(req, "role_id")
+		// eating these errors because the router should have ensured these are integers
+		userID, _ := strconv.ParseUint(userIDStr, 10, 64)
+		roleID, _ := strconv.ParseUint(roleIDStr, 10, 64)
+
+		err := client.RemoveRoleFromUser(db, userID, roleID)
+		if err == sql.ErrNoRows {
+			respondThatRowDoesNotExist(req, res, "user role", roleIDStr)
+			return
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "remove role from user")
+			return
+		}
+
+		json.NewEncoder(res).Encode(&models.UserRole{UserID: userID, RoleID: roleID})
+	}
+}
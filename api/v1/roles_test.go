@@ -0,0 +1,200 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"testing"
+
+	"github.com/dairycart/dairycart/models/v1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidPermission(t *testing.T) {
+	t.Parallel()
+
+	for _, permission := range knownPermissions {
+		assert.True(t, validPermission(permission), "%q should be a valid permission", permission)
+	}
+	assert.False(t, validPermission("products:read"))
+	assert.False(t, validPermission(""))
+}
+
+////////////////////////////////////////////////////////
+//                                                    //
+//                 HTTP Handler Tests                 //
+//                                                    //
+////////////////////////////////////////////////////////
+
+func buildAdminRequest(t *testing.T, testUtil *TestUtil, method, url string) *http.Request {
+	t.Helper()
+	req, err := http.NewRequest(method, url, nil)
+	require.NoError(t, err)
+	attachAdminCookieToRequest(t, testUtil, req)
+	return req
+}
+
+func TestRoleListHandler(t *testing.T) {
+	exampleRoles := []models.Role{
+		{ID: 1, Name: "catalog_editor", Permissions: []string{"products:write"}},
+		{ID: 2, Name: "marketing", Permissions: []string{"discounts:read", "discounts:write"}},
+	}
+
+	t.Run("optimal conditions", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetRoles", mock.Anything).Return(exampleRoles, nil)
+		SetupAPIRouter(buildServerConfigFromTestUtil(testUtil))
+
+		testUtil.Router.ServeHTTP(testUtil.Response, buildAdminRequest(t, testUtil, http.MethodGet, "/v1/roles"))
+		assertStatusCode(t, testUtil, http.StatusOK)
+		assert.Contains(t, testUtil.Response.Body.String(), `"discounts:write"`)
+	})
+
+	t.Run("with error retrieving roles", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetRoles", mock.Anything).Return([]models.Role{}, generateArbitraryError())
+		SetupAPIRouter(buildServerConfigFromTestUtil(testUtil))
+
+		testUtil.Router.ServeHTTP(testUtil.Response, buildAdminRequest(t, testUtil, http.MethodGet, "/v1/roles"))
+		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+	})
+}
+
+func TestUserRoleListHandler(t *testing.T) {
+	exampleUser := &models.User{ID: 1}
+	exampleRoles := []models.Role{{ID: 3, Name: "support", Permissions: []string{"users:read", "users:reset_password"}}}
+
+	t.Run("optimal conditions", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetUser", mock.Anything, exampleUser.ID).Return(exampleUser, nil)
+		testUtil.MockDB.On("GetRolesByUserID", mock.Anything, exampleUser.ID).Return(exampleRoles, nil)
+		SetupAPIRouter(buildServerConfigFromTestUtil(testUtil))
+
+		testUtil.Router.ServeHTTP(testUtil.Response, buildAdminRequest(t, testUtil, http.MethodGet, "/v1/user/1/roles"))
+		assertStatusCode(t, testUtil, http.StatusOK)
+	})
+
+	t.Run("with nonexistent user", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetUser", mock.Anything, exampleUser.ID).Return(exampleUser, sql.ErrNoRows)
+		SetupAPIRouter(buildServerConfigFromTestUtil(testUtil))
+
+		testUtil.Router.ServeHTTP(testUtil.Response, buildAdminRequest(t, testUtil, http.MethodGet, "/v1/user/1/roles"))
+		assertStatusCode(t, testUtil, http.StatusNotFound)
+	})
+
+	t.Run("with error retrieving user", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetUser", mock.Anything, exampleUser.ID).Return(exampleUser, generateArbitraryError())
+		SetupAPIRouter(buildServerConfigFromTestUtil(testUtil))
+
+		testUtil.Router.ServeHTTP(testUtil.Response, buildAdminRequest(t, testUtil, http.MethodGet, "/v1/user/1/roles"))
+		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+	})
+
+	t.Run("with error retrieving roles", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetUser", mock.Anything, exampleUser.ID).Return(exampleUser, nil)
+		testUtil.MockDB.On("GetRolesByUserID", mock.Anything, exampleUser.ID).Return([]models.Role{}, generateArbitraryError())
+		SetupAPIRouter(buildServerConfigFromTestUtil(testUtil))
+
+		testUtil.Router.ServeHTTP(testUtil.Response, buildAdminRequest(t, testUtil, http.MethodGet, "/v1/user/1/roles"))
+		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+	})
+}
+
+func TestUserRoleAssignmentHandler(t *testing.T) {
+	exampleUser := &models.User{ID: 1}
+	exampleRole := &models.Role{ID: 2, Name: "marketing"}
+
+	t.Run("optimal conditions", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetUser", mock.Anything, exampleUser.ID).Return(exampleUser, nil)
+		testUtil.MockDB.On("GetRole", mock.Anything, exampleRole.ID).Return(exampleRole, nil)
+		testUtil.MockDB.On("AssignRoleToUser", mock.Anything, exampleUser.ID, exampleRole.ID).Return(buildTestTime(), nil)
+		SetupAPIRouter(buildServerConfigFromTestUtil(testUtil))
+
+		testUtil.Router.ServeHTTP(testUtil.Response, buildAdminRequest(t, testUtil, http.MethodPut, "/v1/user/1/roles/2"))
+		assertStatusCode(t, testUtil, http.StatusOK)
+	})
+
+	t.Run("with nonexistent user", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetUser", mock.Anything, exampleUser.ID).Return(exampleUser, sql.ErrNoRows)
+		SetupAPIRouter(buildServerConfigFromTestUtil(testUtil))
+
+		testUtil.Router.ServeHTTP(testUtil.Response, buildAdminRequest(t, testUtil, http.MethodPut, "/v1/user/1/roles/2"))
+		assertStatusCode(t, testUtil, http.StatusNotFound)
+	})
+
+	t.Run("with error retrieving user", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetUser", mock.Anything, exampleUser.ID).Return(exampleUser, generateArbitraryError())
+		SetupAPIRouter(buildServerConfigFromTestUtil(testUtil))
+
+		testUtil.Router.ServeHTTP(testUtil.Response, buildAdminRequest(t, testUtil, http.MethodPut, "/v1/user/1/roles/2"))
+		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+	})
+
+	t.Run("with nonexistent role", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetUser", mock.Anything, exampleUser.ID).Return(exampleUser, nil)
+		testUtil.MockDB.On("GetRole", mock.Anything, exampleRole.ID).Return(exampleRole, sql.ErrNoRows)
+		SetupAPIRouter(buildServerConfigFromTestUtil(testUtil))
+
+		testUtil.Router.ServeHTTP(testUtil.Response, buildAdminRequest(t, testUtil, http.MethodPut, "/v1/user/1/roles/2"))
+		assertStatusCode(t, testUtil, http.StatusNotFound)
+	})
+
+	t.Run("with error retrieving role", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetUser", mock.Anything, exampleUser.ID).Return(exampleUser, nil)
+		testUtil.MockDB.On("GetRole", mock.Anything, exampleRole.ID).Return(exampleRole, generateArbitraryError())
+		SetupAPIRouter(buildServerConfigFromTestUtil(testUtil))
+
+		testUtil.Router.ServeHTTP(testUtil.Response, buildAdminRequest(t, testUtil, http.MethodPut, "/v1/user/1/roles/2"))
+		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+	})
+
+	t.Run("with error assigning role", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetUser", mock.Anything, exampleUser.ID).Return(exampleUser, nil)
+		testUtil.MockDB.On("GetRole", mock.Anything, exampleRole.ID).Return(exampleRole, nil)
+		testUtil.MockDB.On("AssignRoleToUser", mock.Anything, exampleUser.ID, exampleRole.ID).Return(buildTestTime(), generateArbitraryError())
+		SetupAPIRouter(buildServerConfigFromTestUtil(testUtil))
+
+		testUtil.Router.ServeHTTP(testUtil.Response, buildAdminRequest(t, testUtil, http.MethodPut, "/v1/user/1/roles/2"))
+		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+	})
+}
+
+func TestUserRoleRemovalHandler(t *testing.T) {
+	t.Run("optimal conditions", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("RemoveRoleFromUser", mock.Anything, uint64(1), uint64(2)).Return(nil)
+		SetupAPIRouter(buildServerConfigFromTestUtil(testUtil))
+
+		testUtil.Router.ServeHTTP(testUtil.Response, buildAdminRequest(t, testUtil, http.MethodDelete, "/v1/user/1/roles/2"))
+		assertStatusCode(t, testUtil, http.StatusOK)
+	})
+
+	t.Run("with role the user doesn't have", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("RemoveRoleFromUser", mock.Anything, uint64(1), uint64(2)).Return(sql.ErrNoRows)
+		SetupAPIRouter(buildServerConfigFromTestUtil(testUtil))
+
+		testUtil.Router.ServeHTTP(testUtil.Response, buildAdminRequest(t, testUtil, http.MethodDelete, "/v1/user/1/roles/2"))
+		assertStatusCode(t, testUtil, http.StatusNotFound)
+	})
+
+	t.Run("with error removing role", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("RemoveRoleFromUser", mock.Anything, uint64(1), uint64(2)).Return(generateArbitraryError())
+		SetupAPIRouter(buildServerConfigFromTestUtil(testUtil))
+
+		testUtil.Router.ServeHTTP(testUtil.Response, buildAdminRequest(t, testUtil, http.MethodDelete, "/v1/user/1/roles/2"))
+		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+	})
+}
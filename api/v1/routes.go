@@ -13,6 +13,9 @@ import (
 const (
 	// ValidURLCharactersPattern represents the valid characters a sku can contain
 	ValidURLCharactersPattern = `[a-zA-Z\-_]+`
+	// WebhookEventPattern represents the name of a webhook event, like `product.created`, or
+	// one of the legacy names events had before they were namespaced, like `product_created`
+	WebhookEventPattern = `[a-z_]+(\.[a-z_]+)?`
 	// NumericPattern repesents numeric values
 	NumericPattern = `[0-9]+`
 	// HealthCheckEndpointBody is what we respond to health check requests with
@@ -23,69 +26,264 @@ func buildRoute(routeVersion string, routeParts ...string) string {
 	return fmt.Sprintf("/%s/%s", routeVersion, strings.Join(routeParts, "/"))
 }
 
+// routePolicy says who's allowed to use a route. Every route is registered with one, so none of them can be left
+// open by accident.
+type routePolicy struct {
+	level routePolicyLevel
+	// permission is what users need to use the route, for permissionLevel routes
+	permission string
+}
+
+type routePolicyLevel int
+
+const (
+	// publicLevel routes can be used by anyone, and are what the storefront is built on
+	publicLevel routePolicyLevel = iota + 1
+	// customerLevel routes can be used by anyone with a session cookie or an API key
+	customerLevel
+	// permissionLevel routes can be used by admins, and by users with a role that gives them the route's permission
+	permissionLevel
+	// adminLevel routes can only be used by admins
+	adminLevel
+)
+
+var (
+	publicRoute   = routePolicy{level: publicLevel}
+	customerRoute = routePolicy{level: customerLevel}
+	adminRoute    = routePolicy{level: adminLevel}
+)
+
+// RequirePermission is the policy for routes that need a permission, like "products:write". Admins have every
+// permission, and other users get them from their roles.
+func RequirePermission(permission string) routePolicy {
+	return routePolicy{level: permissionLevel, permission: permission}
+}
+
+func (p routePolicy) String() string {
+	switch p.level {
+	case publicLevel:
+		return "public"
+	case customerLevel:
+		return "customer"
+	case permissionLevel:
+		return fmt.Sprintf("permission %s", p.permission)
+	case adminLevel:
+		return "admin"
+	}
+	return "undeclared"
+}
+
+// routePolicies maps routes, as returned by routePolicyKey, to the policy they were registered with
+type routePolicies map[string]routePolicy
+
+func routePolicyKey(method, pattern string) string {
+	return fmt.Sprintf("%s %s", method, pattern)
+}
+
+// policyRouter registers routes on a chi router behind the middleware their policy calls for
+type policyRouter struct {
+	router   chi.Router
+	prefix   string
+	policies routePolicies
+	config   *ServerConfig
+}
+
+func (pr *policyRouter) handle(policy routePolicy, method, pattern string, handler http.HandlerFunc) {
+	next := handler
+	switch policy.level {
+	case publicLevel:
+	case customerLevel:
+		handler = pr.authenticated(next)
+	case permissionLevel:
+		if !validPermission(policy.permission) {
+			panic(fmt.Sprintf("route %s requires unknown permission '%s'", routePolicyKey(method, pr.prefix+pattern), policy.permission))
+		}
+		handler = pr.authenticated(func(res http.ResponseWriter, req *http.Request) {
+			validatePermissionMiddleware(res, req, policy.permission, next)
+		})
+	case adminLevel:
+		handler = pr.authenticated(func(res http.ResponseWriter, req *http.Request) {
+			validateAdminMiddleware(res, req, next)
+		})
+	default:
+		panic(fmt.Sprintf("route %s has no policy", routePolicyKey(method, pr.prefix+pattern)))
+	}
+
+	pr.policies[routePolicyKey(method, pr.prefix+pattern)] = policy
+	pr.router.MethodFunc(method, pattern, handler)
+}
+
+func (pr *policyRouter) authenticated(next http.HandlerFunc) http.HandlerFunc {
+	return func(res http.ResponseWriter, req *http.Request) {
+		validateRequestAuthenticationMiddleware(res, req, pr.config.DB, pr.config.DatabaseClient, pr.config.CookieStore, next)
+	}
+}
+
+// Route mounts a sub-router at pattern, whose routes are also registered with policies
+func (pr *policyRouter) Route(pattern string, fn func(r *policyRouter)) {
+	sub := &policyRouter{
+		router:   chi.NewRouter(),
+		prefix:   pr.prefix + pattern,
+		policies: pr.policies,
+		config:   pr.config,
+	}
+	fn(sub)
+	pr.router.Mount(pattern, sub.router)
+}
+
+func (pr *policyRouter) Get(policy routePolicy, pattern string, handler http.HandlerFunc) {
+	pr.handle(policy, http.MethodGet, pattern, handler)
+}
+
+func (pr *policyRouter) Head(policy routePolicy, pattern string, handler http.HandlerFunc) {
+	pr.handle(policy, http.MethodHead, pattern, handler)
+}
+
+func (pr *policyRouter) Post(policy routePolicy, pattern string, handler http.HandlerFunc) {
+	pr.handle(policy, http.MethodPost, pattern, handler)
+}
+
+func (pr *policyRouter) Put(policy routePolicy, pattern string, handler http.HandlerFunc) {
+	pr.handle(policy, http.MethodPut, pattern, handler)
+}
+
+func (pr *policyRouter) Patch(policy routePolicy, pattern string, handler http.HandlerFunc) {
+	pr.handle(policy, http.MethodPatch, pattern, handler)
+}
+
+func (pr *policyRouter) Delete(policy routePolicy, pattern string, handler http.HandlerFunc) {
+	pr.handle(policy, http.MethodDelete, pattern, handler)
+}
+
 // SetupAPIRouter takes a mux router and a database connection and creates all the API routes for the API
 func SetupAPIRouter(config *ServerConfig) {
+	setupAPIRouter(config)
+}
+
+// setupAPIRouter does the work of SetupAPIRouter, and returns the policy each route was registered with
+func setupAPIRouter(config *ServerConfig) routePolicies {
+	fetcher := newImageFetcher(config.RemoteImageLimits)
+	// handlers use this to make one-off requests to webhooks; queued deliveries are sent by the server's own dispatcher
+	webhookDispatcher := NewWebhookDispatcher(config.DB, config.DatabaseClient, config.WebhookDelivery)
+	passwordResetNotifier := config.PasswordResetNotifier
+	if passwordResetNotifier == nil {
+		passwordResetNotifier = logPasswordResetNotifier{}
+	}
+
+	policies := routePolicies{}
+	root := &policyRouter{router: config.Router, policies: policies, config: config}
+
 	// health check
-	config.Router.Get("/health", func(w http.ResponseWriter, r *http.Request) { io.WriteString(w, healthCheckEndpointBody) })
+	root.Get(publicRoute, "/health", func(w http.ResponseWriter, r *http.Request) { io.WriteString(w, healthCheckEndpointBody) })
 
 	// Auth
-	config.Router.Post("/login", buildUserLoginHandler(config.DB, config.DatabaseClient, config.CookieStore))
-	config.Router.Post("/logout", buildUserLogoutHandler(config.CookieStore))
-	config.Router.Post("/user", buildUserCreationHandler(config.DB, config.DatabaseClient, config.CookieStore))
-	config.Router.Patch(fmt.Sprintf("/user/{user_id:%s}", NumericPattern), buildUserUpdateHandler(config.DB, config.DatabaseClient))
-	config.Router.Post("/password_reset", buildUserForgottenPasswordHandler(config.DB, config.DatabaseClient))
-	config.Router.Head("/password_reset/{reset_token}", buildUserPasswordResetTokenValidationHandler(config.DB, config.DatabaseClient))
-
-	config.Router.Route("/v1", func(r chi.Router) {
-		// r.Use(middleware.AllowContentType("application/json"))
+	root.Post(publicRoute, "/login", buildUserLoginHandler(config.DB, config.DatabaseClient, config.CookieStore))
+	root.Post(publicRoute, "/logout", buildUserLogoutHandler(config.CookieStore))
+	root.Post(publicRoute, "/user", buildUserCreationHandler(config.DB, config.DatabaseClient, config.CookieStore))
+	root.Patch(customerRoute, fmt.Sprintf("/user/{user_id:%s}", NumericPattern), buildUserUpdateHandler(config.DB, config.DatabaseClient))
+	root.Post(publicRoute, "/password_reset", buildUserForgottenPasswordHandler(config.DB, config.DatabaseClient, passwordResetNotifier))
+	root.Head(publicRoute, "/password_reset/{reset_token}", buildUserPasswordResetTokenValidationHandler(config.DB, config.DatabaseClient))
 
+	root.Route("/v1", func(r *policyRouter) {
 		// Users
-		r.Delete(fmt.Sprintf("/user/{user_id:%s}", NumericPattern), buildUserDeletionHandler(config.DB, config.DatabaseClient, config.CookieStore))
+		specificUserRoute := fmt.Sprintf("/user/{user_id:%s}", NumericPattern)
+		r.Get(RequirePermission("users:read"), "/users", buildUserListHandler(config.DB, config.DatabaseClient))
+		r.Get(RequirePermission("users:read"), specificUserRoute, buildSingleUserHandler(config.DB, config.DatabaseClient))
+		r.Delete(adminRoute, specificUserRoute, buildUserDeletionHandler(config.DB, config.DatabaseClient))
+		r.Post(RequirePermission("users:reset_password"), fmt.Sprintf("%s/password_reset", specificUserRoute), buildUserPasswordResetHandler(config.DB, config.DatabaseClient, passwordResetNotifier))
+
+		// Roles
+		userRolesRoute := fmt.Sprintf("%s/roles", specificUserRoute)
+		specificUserRoleRoute := fmt.Sprintf("%s/{role_id:%s}", userRolesRoute, NumericPattern)
+		r.Get(adminRoute, "/roles", buildRoleListHandler(config.DB, config.DatabaseClient))
+		r.Get(adminRoute, userRolesRoute, buildUserRoleListHandler(config.DB, config.DatabaseClient))
+		r.Put(adminRoute, specificUserRoleRoute, buildUserRoleAssignmentHandler(config.DB, config.DatabaseClient))
+		r.Delete(adminRoute, specificUserRoleRoute, buildUserRoleRemovalHandler(config.DB, config.DatabaseClient))
 
 		// Product Roots
 		specificProductRootRoute := fmt.Sprintf("/product_root/{product_root_id:%s}", NumericPattern)
-		r.Get("/product_roots", buildProductRootListHandler(config.DB, config.DatabaseClient))
-		r.Get(specificProductRootRoute, buildSingleProductRootHandler(config.DB, config.DatabaseClient))
-		r.Delete(specificProductRootRoute, buildProductRootDeletionHandler(config.DB, config.DatabaseClient))
+		r.Get(publicRoute, "/product_roots", buildProductRootListHandler(config.DB, config.DatabaseClient))
+		r.Get(publicRoute, specificProductRootRoute, buildSingleProductRootHandler(config.DB, config.DatabaseClient))
+		r.Patch(RequirePermission("products:write"), specificProductRootRoute, buildProductRootUpdateHandler(config.DB, config.DatabaseClient))
+		r.Delete(RequirePermission("products:write"), specificProductRootRoute, buildProductRootDeletionHandler(config.DB, config.DatabaseClient))
+
+		// Product Root Images
+		productRootImagesRoute := fmt.Sprintf("%s/images", specificProductRootRoute)
+		specificProductRootImageRoute := fmt.Sprintf("%s/{image_id:%s}", productRootImagesRoute, NumericPattern)
+		r.Get(publicRoute, productRootImagesRoute, buildProductRootImageListHandler(config.DB, config.DatabaseClient))
+		r.Post(RequirePermission("products:write"), productRootImagesRoute, buildProductRootImageCreationHandler(config.DB, config.DatabaseClient, config.ImageStorer, config.UploadLimits, fetcher))
+		r.Put(RequirePermission("products:write"), fmt.Sprintf("%s/order", productRootImagesRoute), buildProductRootImageOrderHandler(config.DB, config.DatabaseClient))
+		r.Put(RequirePermission("products:write"), specificProductRootImageRoute, buildProductRootImageReplacementHandler(config.DB, config.DatabaseClient, config.ImageStorer, config.UploadLimits, fetcher))
+		r.Delete(RequirePermission("products:write"), specificProductRootImageRoute, buildProductRootImageDeletionHandler(config.DB, config.DatabaseClient))
+		r.Put(RequirePermission("products:write"), fmt.Sprintf("%s/primary", specificProductRootImageRoute), buildProductRootPrimaryImageHandler(config.DB, config.DatabaseClient))
+		r.Get(RequirePermission("products:write"), "/product_images/duplicates", buildProductImageDuplicateReportHandler(config.DB, config.DatabaseClient))
 
 		// Products
 		specificProductRoute := fmt.Sprintf("/product/{sku:%s}", ValidURLCharactersPattern)
-		r.Get("/products", buildProductListHandler(config.DB, config.DatabaseClient))
-		r.Post("/product", buildProductCreationHandler(config.DB, config.DatabaseClient, config.ImageStorer, config.WebhookExecutor))
-		r.Get(specificProductRoute, buildSingleProductHandler(config.DB, config.DatabaseClient))
-		r.Patch(specificProductRoute, buildProductUpdateHandler(config.DB, config.DatabaseClient, config.WebhookExecutor))
-		r.Head(specificProductRoute, buildProductExistenceHandler(config.DB, config.DatabaseClient))
-		r.Delete(specificProductRoute, buildProductDeletionHandler(config.DB, config.DatabaseClient, config.WebhookExecutor))
+		r.Get(publicRoute, "/products", buildProductListHandler(config.DB, config.DatabaseClient))
+		r.Post(RequirePermission("products:write"), "/product", buildProductCreationHandler(config.DB, config.DatabaseClient, config.ImageStorer, config.UploadLimits, fetcher))
+		r.Get(publicRoute, specificProductRoute, buildSingleProductHandler(config.DB, config.DatabaseClient))
+		r.Patch(RequirePermission("products:write"), specificProductRoute, buildProductUpdateHandler(config.DB, config.DatabaseClient))
+		r.Head(publicRoute, specificProductRoute, buildProductExistenceHandler(config.DB, config.DatabaseClient))
+		r.Delete(RequirePermission("products:write"), specificProductRoute, buildProductDeletionHandler(config.DB, config.DatabaseClient))
+
+		// Product Images
+		productImagesRoute := fmt.Sprintf("%s/images", specificProductRoute)
+		specificProductImageRoute := fmt.Sprintf("%s/{image_id:%s}", productImagesRoute, NumericPattern)
+		r.Post(RequirePermission("products:write"), productImagesRoute, buildProductImageAttachmentHandler(config.DB, config.DatabaseClient))
+		r.Delete(RequirePermission("products:write"), specificProductImageRoute, buildProductImageDetachmentHandler(config.DB, config.DatabaseClient))
+		r.Put(RequirePermission("products:write"), fmt.Sprintf("%s/primary", specificProductImageRoute), buildProductPrimaryImageHandler(config.DB, config.DatabaseClient))
 
 		// Product Options
 		specificOptionRoute := fmt.Sprintf("/product_options/{option_id:%s}", NumericPattern)
-		r.Patch(specificOptionRoute, buildProductOptionUpdateHandler(config.DB, config.DatabaseClient))
-		r.Delete(specificOptionRoute, buildProductOptionDeletionHandler(config.DB, config.DatabaseClient))
+		r.Patch(RequirePermission("products:write"), specificOptionRoute, buildProductOptionUpdateHandler(config.DB, config.DatabaseClient))
+		r.Delete(RequirePermission("products:write"), specificOptionRoute, buildProductOptionDeletionHandler(config.DB, config.DatabaseClient))
 		productRootOptionsRoute := fmt.Sprintf("/product_root/{product_root_id:%s}/options", NumericPattern)
-		r.Get(productRootOptionsRoute, buildProductOptionListHandler(config.DB, config.DatabaseClient))
-		r.Post(productRootOptionsRoute, buildProductOptionCreationHandler(config.DB, config.DatabaseClient))
+		r.Get(publicRoute, productRootOptionsRoute, buildProductOptionListHandler(config.DB, config.DatabaseClient))
+		r.Post(RequirePermission("products:write"), productRootOptionsRoute, buildProductOptionCreationHandler(config.DB, config.DatabaseClient))
 
 		// Product Option Values
 		specificOptionValueRoute := fmt.Sprintf("/product_option_values/{option_value_id:%s}", NumericPattern)
-		// r.Get(fmt.Sprintf("/product_options/{option_id:%s}/values", NumericPattern), buildProductOptionValueListRetrievalHandler(config.DB, config.DatabaseClient))
-		r.Post(fmt.Sprintf("/product_options/{option_id:%s}/value", NumericPattern), buildProductOptionValueCreationHandler(config.DB, config.DatabaseClient))
-		r.Patch(specificOptionValueRoute, buildProductOptionValueUpdateHandler(config.DB, config.DatabaseClient))
-		r.Delete(specificOptionValueRoute, buildProductOptionValueDeletionHandler(config.DB, config.DatabaseClient))
+		// r.Get(publicRoute, fmt.Sprintf("/product_options/{option_id:%s}/values", NumericPattern), buildProductOptionValueListRetrievalHandler(config.DB, config.DatabaseClient))
+		r.Post(RequirePermission("products:write"), fmt.Sprintf("/product_options/{option_id:%s}/value", NumericPattern), buildProductOptionValueCreationHandler(config.DB, config.DatabaseClient))
+		r.Patch(RequirePermission("products:write"), specificOptionValueRoute, buildProductOptionValueUpdateHandler(config.DB, config.DatabaseClient))
+		r.Delete(RequirePermission("products:write"), specificOptionValueRoute, buildProductOptionValueDeletionHandler(config.DB, config.DatabaseClient))
+		r.Post(RequirePermission("products:write"), fmt.Sprintf("%s/images", specificOptionValueRoute), buildProductOptionValueImageAssignmentHandler(config.DB, config.DatabaseClient))
 
 		// Discounts
+		// customers need to be able to look up the discounts they're using, but the full list includes every code
 		specificDiscountRoute := fmt.Sprintf("/discount/{discount_id:%s}", NumericPattern)
-		r.Get(specificDiscountRoute, buildDiscountRetrievalHandler(config.DB, config.DatabaseClient))
-		r.Patch(specificDiscountRoute, buildDiscountUpdateHandler(config.DB, config.DatabaseClient))
-		r.Delete(specificDiscountRoute, buildDiscountDeletionHandler(config.DB, config.DatabaseClient))
-		r.Get("/discounts", buildDiscountListRetrievalHandler(config.DB, config.DatabaseClient))
-		r.Post("/discount", buildDiscountCreationHandler(config.DB, config.DatabaseClient))
+		r.Get(customerRoute, specificDiscountRoute, buildDiscountRetrievalHandler(config.DB, config.DatabaseClient))
+		r.Patch(RequirePermission("discounts:write"), specificDiscountRoute, buildDiscountUpdateHandler(config.DB, config.DatabaseClient))
+		r.Delete(RequirePermission("discounts:write"), specificDiscountRoute, buildDiscountDeletionHandler(config.DB, config.DatabaseClient))
+		r.Get(RequirePermission("discounts:read"), "/discounts", buildDiscountListRetrievalHandler(config.DB, config.DatabaseClient))
+		r.Post(RequirePermission("discounts:write"), "/discount", buildDiscountCreationHandler(config.DB, config.DatabaseClient))
 
 		// Webhooks
 		specificWebhookRoute := fmt.Sprintf("/webhook/{webhook_id:%s}", NumericPattern)
-		r.Get(fmt.Sprintf("/webhooks/{event_type:%s}", ValidURLCharactersPattern), buildWebhookListRetrievalByEventTypeHandler(config.DB, config.DatabaseClient))
-		r.Get("/webhooks", buildWebhookListRetrievalHandler(config.DB, config.DatabaseClient))
-		r.Post("/webhook", buildWebhookCreationHandler(config.DB, config.DatabaseClient))
-		r.Patch(specificWebhookRoute, buildWebhookUpdateHandler(config.DB, config.DatabaseClient))
-		r.Delete(specificWebhookRoute, buildWebhookDeletionHandler(config.DB, config.DatabaseClient))
+		r.Get(RequirePermission("webhooks:read"), fmt.Sprintf("/webhooks/{event_type:%s}", WebhookEventPattern), buildWebhookListRetrievalByEventTypeHandler(config.DB, config.DatabaseClient))
+		r.Get(RequirePermission("webhooks:read"), "/webhooks", buildWebhookListRetrievalHandler(config.DB, config.DatabaseClient))
+		r.Post(RequirePermission("webhooks:write"), "/webhook", buildWebhookCreationHandler(config.DB, config.DatabaseClient, webhookDispatcher))
+		r.Patch(RequirePermission("webhooks:write"), specificWebhookRoute, buildWebhookUpdateHandler(config.DB, config.DatabaseClient))
+		r.Delete(RequirePermission("webhooks:write"), specificWebhookRoute, buildWebhookDeletionHandler(config.DB, config.DatabaseClient))
+		r.Post(RequirePermission("webhooks:write"), fmt.Sprintf("%s/secret", specificWebhookRoute), buildWebhookSecretRotationHandler(config.DB, config.DatabaseClient))
+		r.Post(RequirePermission("webhooks:write"), fmt.Sprintf("%s/ping", specificWebhookRoute), buildWebhookPingHandler(config.DB, config.DatabaseClient, webhookDispatcher))
+		r.Post(RequirePermission("webhooks:write"), fmt.Sprintf("%s/verify", specificWebhookRoute), buildWebhookVerificationHandler(config.DB, config.DatabaseClient, webhookDispatcher))
+		r.Get(RequirePermission("webhooks:read"), fmt.Sprintf("%s/executions", specificWebhookRoute), buildWebhookExecutionListRetrievalHandler(config.DB, config.DatabaseClient))
+		r.Post(RequirePermission("webhooks:write"), fmt.Sprintf("%s/executions/{execution_id:%s}/replay", specificWebhookRoute, NumericPattern), buildWebhookExecutionReplayHandler(config.DB, config.DatabaseClient))
+
+		// Changes
+		r.Get(RequirePermission("events:read"), "/changes", buildChangeFeedHandler(config.DB, config.DatabaseClient))
+
+		// Events
+		r.Get(RequirePermission("events:read"), "/events/stream", buildEventStreamHandler(config.DB, config.DatabaseClient, config.EventStream))
+
+		// API Keys
+		// everyone can manage their own keys, and the handlers check for api_keys:write before acting on anyone else's
+		r.Get(customerRoute, "/api_keys", buildAPIKeyListHandler(config.DB, config.DatabaseClient))
+		r.Post(customerRoute, "/api_keys", buildAPIKeyCreationHandler(config.DB, config.DatabaseClient))
+		r.Delete(customerRoute, fmt.Sprintf("/api_keys/{api_key_id:%s}", NumericPattern), buildAPIKeyDeletionHandler(config.DB, config.DatabaseClient))
 	})
+
+	return policies
 }
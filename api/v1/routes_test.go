@@ -2,12 +2,37 @@ package api
 
 import (
 	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sort"
 	"strings"
 	"testing"
 
+	"github.com/go-chi/chi"
+	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
+var routeParamPattern = regexp.MustCompile(`\{(\w+)(:[^}]*)?\}`)
+
+// buildExampleRequestPath fills in a route pattern's parameters with values its regular expressions accept
+func buildExampleRequestPath(pattern string) string {
+	return routeParamPattern.ReplaceAllStringFunc(pattern, func(param string) string {
+		switch routeParamPattern.FindStringSubmatch(param)[1] {
+		case "sku":
+			return "skateboard"
+		case "event_type":
+			return ProductCreatedWebhookEvent
+		case "reset_token":
+			return "token"
+		default:
+			return "1"
+		}
+	})
+}
+
 func TestBuildRoute(t *testing.T) {
 	inputOutputMap := []struct {
 		In  []string
@@ -31,6 +56,167 @@ func TestBuildRoute(t *testing.T) {
 	}
 }
 
+func TestEveryRouteHasAPolicy(t *testing.T) {
+	testUtil := setupTestVariablesWithMock(t)
+	policies := setupAPIRouter(buildServerConfigFromTestUtil(testUtil))
+
+	var walked int
+	err := chi.Walk(testUtil.Router, func(method string, route string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+		// routes on mounted routers come back with the mount's wildcard in them
+		route = strings.Replace(route, "/*/", "/", -1)
+		walked++
+
+		policy, ok := policies[routePolicyKey(method, route)]
+		assert.True(t, ok, "%s %s should be registered with a policy", method, route)
+		assert.NotEqual(t, "undeclared", policy.String(), "%s %s should be registered with a policy", method, route)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, len(policies), walked, "every route registered with a policy should be on the router")
+}
+
+func TestPublicRoutes(t *testing.T) {
+	// making a route public should be a deliberate choice, so they're listed here too
+	expected := []string{
+		"GET /health",
+		"GET /v1/product/{sku:[a-zA-Z\\-_]+}",
+		"GET /v1/product_root/{product_root_id:[0-9]+}",
+		"GET /v1/product_root/{product_root_id:[0-9]+}/images",
+		"GET /v1/product_root/{product_root_id:[0-9]+}/options",
+		"GET /v1/product_roots",
+		"GET /v1/products",
+		"HEAD /password_reset/{reset_token}",
+		"HEAD /v1/product/{sku:[a-zA-Z\\-_]+}",
+		"POST /login",
+		"POST /logout",
+		"POST /password_reset",
+		"POST /user",
+	}
+
+	testUtil := setupTestVariablesWithMock(t)
+	policies := setupAPIRouter(buildServerConfigFromTestUtil(testUtil))
+
+	var actual []string
+	for route, policy := range policies {
+		if policy == publicRoute {
+			actual = append(actual, route)
+		}
+	}
+	sort.Strings(actual)
+	assert.Equal(t, expected, actual)
+}
+
+func TestImageStorageRoutesArePublic(t *testing.T) {
+	// image storers register their own routes when they're initialized, which serve the images the storefront
+	// shows, so they're deliberately public, and the only routes on the server that aren't registered with a policy
+	testUtil := setupTestVariablesWithMock(t)
+	testUtil.MockImageStorage.On("Init", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			args.Get(1).(chi.Router).Get("/*", func(res http.ResponseWriter, req *http.Request) {})
+		}).
+		Return(nil)
+	testUtil.MockDB.On("Migrate", mock.Anything, mock.Anything).Return(nil)
+
+	config := buildServerConfigFromTestUtil(testUtil)
+	policies := setupAPIRouter(config)
+	require.NoError(t, InitializeServerComponents(viper.New(), config))
+	mountPath := imageRouteMountPath(config.ImageStorer)
+
+	var imageRoutes []string
+	err := chi.Walk(testUtil.Router, func(method string, route string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+		route = strings.Replace(route, "/*/", "/", -1)
+		if _, ok := policies[routePolicyKey(method, route)]; ok {
+			return nil
+		}
+		assert.True(t, strings.HasPrefix(route, mountPath+"/"), "%s %s should be registered with a policy", method, route)
+		imageRoutes = append(imageRoutes, routePolicyKey(method, route))
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"GET /product_images/*"}, imageRoutes)
+
+	for _, route := range imageRoutes {
+		parts := strings.SplitN(route, " ", 2)
+		req, err := http.NewRequest(parts[0], buildExampleRequestPath(parts[1]), nil)
+		require.NoError(t, err)
+
+		res := httptest.NewRecorder()
+		testUtil.Router.ServeHTTP(res, req)
+		assert.Equal(t, http.StatusOK, res.Code, "%s should be public", route)
+	}
+}
+
+func TestRoutePoliciesAreEnforced(t *testing.T) {
+	testUtil := setupTestVariablesWithMock(t)
+	policies := setupAPIRouter(buildServerConfigFromTestUtil(testUtil))
+
+	for route, policy := range policies {
+		if policy == publicRoute {
+			continue
+		}
+		parts := strings.SplitN(route, " ", 2)
+		method, path := parts[0], buildExampleRequestPath(parts[1])
+
+		t.Run(route, func(t *testing.T) {
+			testUtil := setupTestVariablesWithMock(t)
+			SetupAPIRouter(buildServerConfigFromTestUtil(testUtil))
+
+			req, err := http.NewRequest(method, path, nil)
+			require.NoError(t, err)
+
+			testUtil.Router.ServeHTTP(testUtil.Response, req)
+			assertStatusCode(t, testUtil, http.StatusUnauthorized)
+		})
+
+		if policy.level != permissionLevel && policy.level != adminLevel {
+			continue
+		}
+		t.Run(route+" as a customer", func(t *testing.T) {
+			testUtil := setupTestVariablesWithMock(t)
+			SetupAPIRouter(buildServerConfigFromTestUtil(testUtil))
+
+			req, err := http.NewRequest(method, path, nil)
+			require.NoError(t, err)
+			cookie, err := buildCookieForRequest(t, testUtil, true, false)
+			require.NoError(t, err)
+			req.AddCookie(cookie)
+
+			testUtil.Router.ServeHTTP(testUtil.Response, req)
+			assertStatusCode(t, testUtil, http.StatusForbidden)
+		})
+
+		if policy.level != adminLevel {
+			continue
+		}
+		// a role can't grant access to admin routes, no matter how many permissions it gives
+		t.Run(route+" with every permission", func(t *testing.T) {
+			testUtil := setupTestVariablesWithMock(t)
+			SetupAPIRouter(buildServerConfigFromTestUtil(testUtil))
+
+			req, err := http.NewRequest(method, path, nil)
+			require.NoError(t, err)
+			req.AddCookie(buildCookieWithPermissionsForRequest(t, testUtil, knownPermissions...))
+
+			testUtil.Router.ServeHTTP(testUtil.Response, req)
+			assertStatusCode(t, testUtil, http.StatusForbidden)
+		})
+	}
+}
+
+func TestRequirePermission(t *testing.T) {
+	t.Parallel()
+
+	policy := RequirePermission("products:write")
+	assert.Equal(t, permissionLevel, policy.level)
+	assert.Equal(t, "products:write", policy.permission)
+
+	testUtil := setupTestVariablesWithMock(t)
+	router := &policyRouter{router: testUtil.Router, policies: routePolicies{}, config: buildServerConfigFromTestUtil(testUtil)}
+	assert.Panics(t, func() {
+		router.Get(RequirePermission("products:wreck"), "/example", func(http.ResponseWriter, *http.Request) {})
+	}, "routes shouldn't be able to require permissions no role can have")
+}
+
 ////////////////////////////////////////////////////////
 //                                                    //
 //                 HTTP Handler Tests                 //
@@ -38,7 +224,7 @@ func TestBuildRoute(t *testing.T) {
 ////////////////////////////////////////////////////////
 
 func TestHealthCheckHandler(t *testing.T) {
-	t.Run("optimal conditions", func(*testing.T) {
+	t.Run("optimal conditions", func(t *testing.T) {
 		testUtil := setupTestVariablesWithMock(t)
 		config := buildServerConfigFromTestUtil(testUtil)
 		SetupAPIRouter(config)
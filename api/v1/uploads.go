@@ -0,0 +1,192 @@
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/dairycart/dairycart/storage/v1/images"
+	"github.com/fatih/structs"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultMaxUploadFileSize     = 10 << 20 // 10 MB
+	defaultMaxUploadFiles        = 10
+	maxMultipartInputSize        = 1 << 20 // 1 MB
+	multipartInputFieldName      = "input"
+	multipartPrimaryImageField   = "primary_image"
+	contentTypeSniffingLength    = 512
+	multipartFormDataContentType = "multipart/form-data"
+)
+
+var (
+	errUploadTooLarge = errors.New("uploaded file exceeds the maximum allowed size")
+
+	// acceptedImageContentTypes are the sniffed content types we're willing to try decoding
+	acceptedImageContentTypes = map[string]bool{
+		"image/png":  true,
+		"image/jpeg": true,
+		"image/gif":  true,
+		"image/webp": true,
+	}
+)
+
+// UploadLimits restricts the files accepted in multipart/form-data requests
+type UploadLimits struct {
+	MaxFileSize        int64
+	MaxFilesPerRequest int
+	// MaxPixels is the most pixels an uploaded image can have. It also applies to base64 image inputs.
+	MaxPixels int64
+}
+
+func (l UploadLimits) maxFileSize() int64 {
+	if l.MaxFileSize <= 0 {
+		return defaultMaxUploadFileSize
+	}
+	return l.MaxFileSize
+}
+
+func (l UploadLimits) maxFilesPerRequest() int {
+	if l.MaxFilesPerRequest <= 0 {
+		return defaultMaxUploadFiles
+	}
+	return l.MaxFilesPerRequest
+}
+
+func (l UploadLimits) maxPixels() int64 {
+	if l.MaxPixels <= 0 {
+		return images.DefaultMaxPixels
+	}
+	return l.MaxPixels
+}
+
+func (l UploadLimits) maxRequestSize() int64 {
+	return l.maxFileSize()*int64(l.maxFilesPerRequest()) + maxMultipartInputSize
+}
+
+// sizeLimitedReader is like io.LimitedReader, except that it complains when
+// the underlying reader has more to give instead of quietly stopping.
+type sizeLimitedReader struct {
+	r         io.Reader
+	remaining int64
+}
+
+func (l *sizeLimitedReader) Read(p []byte) (int, error) {
+	if l.remaining <= 0 {
+		var b [1]byte
+		n, err := l.r.Read(b[:])
+		if n > 0 {
+			return 0, errUploadTooLarge
+		}
+		return 0, err
+	}
+
+	if int64(len(p)) > l.remaining {
+		p = p[:l.remaining]
+	}
+	n, err := l.r.Read(p)
+	l.remaining -= int64(n)
+	return n, err
+}
+
+func isMultipartRequest(req *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	return err == nil && mediaType == multipartFormDataContentType
+}
+
+// validateUploadedImageType makes sure a file is actually an image we can handle, based on
+// both what the client claims it is and what the first few bytes say it is.
+func validateUploadedImageType(claimedType string, in *bufio.Reader) error {
+	if claimedType != "" && claimedType != "application/octet-stream" {
+		mediaType, _, err := mime.ParseMediaType(claimedType)
+		if err != nil || !strings.HasPrefix(mediaType, "image/") {
+			return fmt.Errorf("unsupported content type: %s", claimedType)
+		}
+	}
+
+	// Peek returns an error when the file is shorter than we asked for, which is fine.
+	head, _ := in.Peek(contentTypeSniffingLength)
+	if sniffed := http.DetectContentType(head); !acceptedImageContentTypes[sniffed] {
+		return fmt.Errorf("unsupported image type: %s", sniffed)
+	}
+	return nil
+}
+
+// parseMultipartImageRequest reads a multipart/form-data request body part by part, so files never
+// have to be held in memory in their encoded form. The JSON found in the `input` field is decoded
+// into output (if output isn't nil), and every file is decoded as an image. Files uploaded under the
+// `primary_image` field are marked as primary.
+func parseMultipartImageRequest(res http.ResponseWriter, req *http.Request, limits UploadLimits, output interface{}) ([]pendingProductImage, error) {
+	req.Body = http.MaxBytesReader(res, req.Body, limits.maxRequestSize())
+	reader, err := req.MultipartReader()
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading multipart request")
+	}
+
+	var (
+		uploads     []pendingProductImage
+		foundInput  bool
+		maxFileSize = limits.maxFileSize()
+		maxFiles    = limits.maxFilesPerRequest()
+	)
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, errors.Wrap(err, "error reading multipart request")
+		}
+
+		if part.FileName() == "" {
+			if part.FormName() == multipartInputFieldName && output != nil {
+				err = json.NewDecoder(&sizeLimitedReader{r: part, remaining: maxMultipartInputSize}).Decode(output)
+				if err != nil {
+					part.Close()
+					return nil, errors.Wrap(err, "error decoding request input")
+				}
+				foundInput = true
+			}
+			part.Close()
+			continue
+		}
+
+		if len(uploads) == maxFiles {
+			part.Close()
+			return nil, fmt.Errorf("too many files uploaded, the maximum is %d", maxFiles)
+		}
+
+		in := bufio.NewReaderSize(&sizeLimitedReader{r: part, remaining: maxFileSize}, contentTypeSniffingLength)
+		if err = validateUploadedImageType(part.Header.Get("Content-Type"), in); err != nil {
+			part.Close()
+			return nil, errors.Wrap(err, fmt.Sprintf("file %s is invalid", part.FileName()))
+		}
+
+		img, format, err := loadImage(in, limits.maxPixels())
+		part.Close()
+		if err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("file %s is invalid", part.FileName()))
+		}
+
+		uploads = append(uploads, pendingProductImage{
+			image:     img,
+			format:    format,
+			isPrimary: part.FormName() == multipartPrimaryImageField,
+		})
+	}
+
+	if output != nil {
+		// same deal as validateRequestInput
+		if !foundInput || structs.New(output).IsZero() {
+			return nil, errors.New("Invalid input provided in request body")
+		}
+	}
+
+	return uploads, nil
+}
@@ -0,0 +1,230 @@
+package api
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"strings"
+	"testing"
+
+	"github.com/dairycart/dairycart/models/v1"
+	"github.com/dairycart/dairycart/storage/v1/images"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type testUploadFile struct {
+	fieldName   string
+	fileName    string
+	contentType string
+	data        []byte
+}
+
+func buildTestMultipartBody(t *testing.T, input string, files ...testUploadFile) (*bytes.Buffer, string) {
+	t.Helper()
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+
+	if input != "" {
+		require.Nil(t, writer.WriteField(multipartInputFieldName, input))
+	}
+
+	for _, f := range files {
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, f.fieldName, f.fileName))
+		if f.contentType != "" {
+			header.Set("Content-Type", f.contentType)
+		}
+		part, err := writer.CreatePart(header)
+		require.Nil(t, err)
+		_, err = part.Write(f.data)
+		require.Nil(t, err)
+	}
+
+	require.Nil(t, writer.Close())
+	return body, writer.FormDataContentType()
+}
+
+func buildTestPNGBytes(t *testing.T) []byte {
+	t.Helper()
+	out, err := base64.StdEncoding.DecodeString(smallGreenPNG)
+	require.Nil(t, err)
+	return out
+}
+
+func TestSizeLimitedReader(t *testing.T) {
+	t.Parallel()
+
+	t.Run("within limit", func(_t *testing.T) {
+		r := &sizeLimitedReader{r: strings.NewReader("dairy"), remaining: 5}
+		out, err := ioutil.ReadAll(r)
+		assert.NoError(t, err)
+		assert.Equal(t, "dairy", string(out))
+	})
+
+	t.Run("over limit", func(_t *testing.T) {
+		r := &sizeLimitedReader{r: strings.NewReader("dairycart"), remaining: 5}
+		_, err := ioutil.ReadAll(r)
+		assert.Equal(t, errUploadTooLarge, err)
+	})
+}
+
+func TestUploadLimits(t *testing.T) {
+	t.Parallel()
+
+	zero := UploadLimits{}
+	assert.Equal(t, int64(defaultMaxUploadFileSize), zero.maxFileSize())
+	assert.Equal(t, defaultMaxUploadFiles, zero.maxFilesPerRequest())
+
+	custom := UploadLimits{MaxFileSize: 100, MaxFilesPerRequest: 2}
+	assert.Equal(t, int64(100), custom.maxFileSize())
+	assert.Equal(t, 2, custom.maxFilesPerRequest())
+	assert.Equal(t, int64(200+maxMultipartInputSize), custom.maxRequestSize())
+}
+
+func TestIsMultipartRequest(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	req.Header.Set("Content-Type", "multipart/form-data; boundary=whatever")
+	assert.True(t, isMultipartRequest(req))
+
+	req.Header.Set("Content-Type", "application/json")
+	assert.False(t, isMultipartRequest(req))
+}
+
+func TestParseMultipartImageRequest(t *testing.T) {
+	t.Parallel()
+	examplePNG := buildTestPNGBytes(t)
+
+	t.Run("optimal conditions", func(_t *testing.T) {
+		body, contentType := buildTestMultipartBody(t, `{"sku": "skateboard"}`,
+			testUploadFile{fieldName: "images", fileName: "one.png", contentType: "image/png", data: examplePNG},
+			testUploadFile{fieldName: multipartPrimaryImageField, fileName: "two.png", data: examplePNG},
+		)
+		req := httptest.NewRequest(http.MethodPost, "/", body)
+		req.Header.Set("Content-Type", contentType)
+
+		input := &models.ProductCreationInput{}
+		uploads, err := parseMultipartImageRequest(httptest.NewRecorder(), req, UploadLimits{}, input)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "skateboard", input.SKU)
+		require.Len(t, uploads, 2)
+		assert.False(t, uploads[0].isPrimary)
+		assert.True(t, uploads[1].isPrimary)
+	})
+
+	t.Run("without required input", func(_t *testing.T) {
+		body, contentType := buildTestMultipartBody(t, "",
+			testUploadFile{fieldName: "images", fileName: "one.png", data: examplePNG},
+		)
+		req := httptest.NewRequest(http.MethodPost, "/", body)
+		req.Header.Set("Content-Type", contentType)
+
+		_, err := parseMultipartImageRequest(httptest.NewRecorder(), req, UploadLimits{}, &models.ProductCreationInput{})
+		assert.Error(t, err)
+	})
+
+	t.Run("with too many files", func(_t *testing.T) {
+		body, contentType := buildTestMultipartBody(t, "",
+			testUploadFile{fieldName: "images", fileName: "one.png", data: examplePNG},
+			testUploadFile{fieldName: "images", fileName: "two.png", data: examplePNG},
+		)
+		req := httptest.NewRequest(http.MethodPost, "/", body)
+		req.Header.Set("Content-Type", contentType)
+
+		_, err := parseMultipartImageRequest(httptest.NewRecorder(), req, UploadLimits{MaxFilesPerRequest: 1}, nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("with file that is too large", func(_t *testing.T) {
+		body, contentType := buildTestMultipartBody(t, "",
+			testUploadFile{fieldName: "images", fileName: "one.png", data: examplePNG},
+		)
+		req := httptest.NewRequest(http.MethodPost, "/", body)
+		req.Header.Set("Content-Type", contentType)
+
+		_, err := parseMultipartImageRequest(httptest.NewRecorder(), req, UploadLimits{MaxFileSize: 16}, nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("with non-image content type", func(_t *testing.T) {
+		body, contentType := buildTestMultipartBody(t, "",
+			testUploadFile{fieldName: "images", fileName: "one.png", contentType: "text/plain", data: examplePNG},
+		)
+		req := httptest.NewRequest(http.MethodPost, "/", body)
+		req.Header.Set("Content-Type", contentType)
+
+		_, err := parseMultipartImageRequest(httptest.NewRecorder(), req, UploadLimits{}, nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("with non-image file contents", func(_t *testing.T) {
+		body, contentType := buildTestMultipartBody(t, "",
+			testUploadFile{fieldName: "images", fileName: "one.png", contentType: "image/png", data: []byte("#!/bin/sh\necho pwned\n")},
+		)
+		req := httptest.NewRequest(http.MethodPost, "/", body)
+		req.Header.Set("Content-Type", contentType)
+
+		_, err := parseMultipartImageRequest(httptest.NewRecorder(), req, UploadLimits{}, nil)
+		assert.Error(t, err)
+	})
+}
+
+func TestProductRootImageCreationHandlerWithMultipartUpload(t *testing.T) {
+	exampleLocations := images.ProductImageLocations{
+		images.ThumbnailRendition: "https://dairycart.com/product_images/skate/thumbnails/12.png",
+		images.MainRendition:      "https://dairycart.com/product_images/skate/main/12.png",
+		images.OriginalRendition:  "https://dairycart.com/product_images/skate/original/12.png",
+	}
+	examplePrimaryImageID := uint64(10)
+
+	t.Run("optimal conditions", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetProductRoot", mock.Anything, uint64(1)).Return(&models.ProductRoot{ID: 1, SKUPrefix: "skate", PrimaryImageID: &examplePrimaryImageID}, nil)
+		testUtil.MockDB.On("GetProductImagesByProductRootID", mock.Anything, uint64(1)).Return([]models.ProductImage{}, nil)
+		testUtil.Mock.ExpectBegin()
+		testUtil.MockImageStorage.On("CreateThumbnails", mock.Anything).Return(images.ProductImageSet{})
+		testUtil.MockDB.On("GetProductImagesByContentHash", mock.Anything, mock.AnythingOfType("string")).Return([]models.ProductImage{}, nil)
+		testUtil.MockImageStorage.On("StoreImages", mock.Anything, mock.AnythingOfType("string")).Return(exampleLocations, nil)
+		testUtil.MockDB.On("CreateProductImage", mock.Anything, mock.Anything).Return(uint64(12), buildTestTime(), nil)
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, ProductImageCreatedWebhookEvent).
+			Return([]models.Webhook{}, nil)
+		testUtil.Mock.ExpectCommit()
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		body, contentType := buildTestMultipartBody(t, "", testUploadFile{fieldName: "images", fileName: "one.png", data: buildTestPNGBytes(t)})
+		req, err := http.NewRequest(http.MethodPost, "/v1/product_root/1/images", body)
+		assert.NoError(t, err)
+		req.Header.Set("Content-Type", contentType)
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+
+		assertStatusCode(t, testUtil, http.StatusCreated)
+	})
+
+	t.Run("without any files", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		body, contentType := buildTestMultipartBody(t, `{"whatever": true}`)
+		req, err := http.NewRequest(http.MethodPost, "/v1/product_root/1/images", body)
+		assert.NoError(t, err)
+		req.Header.Set("Content-Type", contentType)
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+
+		assertStatusCode(t, testUtil, http.StatusBadRequest)
+	})
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
@@ -26,7 +27,6 @@ const (
 	resetTokenSize           = 128
 	minimumPasswordSize      = 16
 	dairycartCookieName      = "dairycart"
-	sessionAdminKeyName      = "is_admin"
 	sessionUserIDKeyName     = "user_id"
 	sessionAuthorizedKeyName = "authenticated"
 )
@@ -52,15 +52,182 @@ type UserLoginInput struct {
 	Password string `json:"password"`
 }
 
-func validateSessionCookieMiddleware(res http.ResponseWriter, req *http.Request, store *sessions.CookieStore, next http.HandlerFunc) {
+// PasswordResetNotifier delivers a newly created password reset token to the user it belongs to, e.g. by email
+type PasswordResetNotifier interface {
+	NotifyPasswordReset(user *models.User, token *models.PasswordResetToken) error
+}
+
+// logPasswordResetNotifier writes reset tokens to the server log. It's meant for development; deployments should
+// provide a PasswordResetNotifier that actually reaches their users.
+type logPasswordResetNotifier struct{}
+
+func (logPasswordResetNotifier) NotifyPasswordReset(user *models.User, token *models.PasswordResetToken) error {
+	log.Printf("password reset token for user %d (%s): %s\n", user.ID, user.Email, token.Token)
+	return nil
+}
+
+type requestContextKey string
+
+// authenticatedUserContextKey is where the authentication middlewares leave the user a request was made by
+const authenticatedUserContextKey requestContextKey = "authenticated_user"
+
+// authenticatedUser is the user a request was made by, however they proved who they were
+type authenticatedUser struct {
+	ID      uint64
+	IsAdmin bool
+	// Permissions are the permissions the user's roles give them. Admins have every permission anyway.
+	Permissions []string
+	// APIKeyID is the key the request was made with, if it wasn't made with a session cookie
+	APIKeyID *uint64
+}
+
+func (u *authenticatedUser) hasPermission(permission string) bool {
+	if u.IsAdmin {
+		return true
+	}
+	for _, p := range u.Permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+func withAuthenticatedUser(req *http.Request, user *authenticatedUser) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), authenticatedUserContextKey, user))
+}
+
+// authenticatedUserFromRequest returns the user a request was made by. It's only set on requests that have been
+// through one of the authentication middlewares.
+func authenticatedUserFromRequest(req *http.Request) (*authenticatedUser, bool) {
+	user, ok := req.Context().Value(authenticatedUserContextKey).(*authenticatedUser)
+	return user, ok
+}
+
+// validateSessionCookieMiddleware authenticates requests made with a session cookie. The cookie only says who the
+// user is; whether they're an admin and what their roles let them do are looked up on every request, so that
+// changes to either, or the user being archived, take effect without waiting for them to log in again.
+func validateSessionCookieMiddleware(res http.ResponseWriter, req *http.Request, db database.Querier, client database.Storer, store *sessions.CookieStore, next http.HandlerFunc) {
 	session, err := store.Get(req, dairycartCookieName)
 	if auth, ok := session.Values[sessionAuthorizedKeyName].(bool); !ok || !auth || err != nil {
-		res.WriteHeader(http.StatusUnauthorized)
-		errRes := &ErrorResponse{
-			Status:  http.StatusUnauthorized,
-			Message: "Unauthorized",
+		notifyOfUnauthorizedRequest(res)
+		return
+	}
+	userID, ok := session.Values[sessionUserIDKeyName].(uint64)
+	if !ok {
+		notifyOfUnauthorizedRequest(res)
+		return
+	}
+
+	sessionUser, err := client.GetUser(db, userID)
+	if err == sql.ErrNoRows {
+		notifyOfUnauthorizedRequest(res)
+		return
+	} else if err != nil {
+		notifyOfInternalIssue(res, err, "retrieve session user")
+		return
+	}
+
+	user := &authenticatedUser{
+		ID:      sessionUser.ID,
+		IsAdmin: sessionUser.IsAdmin,
+	}
+	if !user.IsAdmin {
+		user.Permissions, err = client.GetPermissionsByUserID(db, sessionUser.ID)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve session user's permissions")
+			return
+		}
+	}
+
+	next(res, withAuthenticatedUser(req, user))
+}
+
+// validateAPIKeyMiddleware authenticates requests made with an `Authorization: Bearer <key>` header. Keys that
+// have been revoked or have expired, or that belong to archived users, aren't accepted.
+func validateAPIKeyMiddleware(res http.ResponseWriter, req *http.Request, db database.Querier, client database.Storer, next http.HandlerFunc) {
+	key, ok := bearerToken(req)
+	if !ok {
+		res.Header().Set("WWW-Authenticate", "Bearer")
+		notifyOfUnauthorizedRequest(res)
+		return
+	}
+
+	apiKey, err := client.GetAPIKeyByHash(db, hashAPIKey(key))
+	if err == sql.ErrNoRows {
+		res.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+		notifyOfUnauthorizedRequest(res)
+		return
+	} else if err != nil {
+		notifyOfInternalIssue(res, err, "retrieve API key")
+		return
+	}
+	if apiKey.ExpiresOn != nil && !apiKey.ExpiresOn.Time.After(time.Now()) {
+		res.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+		notifyOfUnauthorizedRequest(res)
+		return
+	}
+
+	keyOwner, err := client.GetUser(db, apiKey.UserID)
+	if err == sql.ErrNoRows {
+		res.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+		notifyOfUnauthorizedRequest(res)
+		return
+	} else if err != nil {
+		notifyOfInternalIssue(res, err, "retrieve API key owner")
+		return
+	}
+
+	// keys can be used many times a second, so when they were last used is only kept roughly up to date
+	if apiKey.LastUsedOn == nil || time.Since(apiKey.LastUsedOn.Time) > apiKeyUsageResolution {
+		if _, err = client.MarkAPIKeyUsed(db, apiKey.ID); err != nil {
+			log.Printf("error recording use of API key %d: %v\n", apiKey.ID, err)
 		}
-		json.NewEncoder(res).Encode(errRes)
+	}
+
+	user := &authenticatedUser{
+		ID:       keyOwner.ID,
+		IsAdmin:  keyOwner.IsAdmin,
+		APIKeyID: &apiKey.ID,
+	}
+	if !user.IsAdmin {
+		user.Permissions, err = client.GetPermissionsByUserID(db, keyOwner.ID)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve API key owner's permissions")
+			return
+		}
+	}
+
+	next(res, withAuthenticatedUser(req, user))
+}
+
+// validateRequestAuthenticationMiddleware accepts requests authenticated with either an API key or a session cookie.
+// Requests with an Authorization header have to have a valid API key, even if they have a valid cookie too.
+func validateRequestAuthenticationMiddleware(res http.ResponseWriter, req *http.Request, db database.Querier, client database.Storer, store *sessions.CookieStore, next http.HandlerFunc) {
+	if req.Header.Get("Authorization") != "" {
+		validateAPIKeyMiddleware(res, req, db, client, next)
+		return
+	}
+	validateSessionCookieMiddleware(res, req, db, client, store, next)
+}
+
+// validateAdminMiddleware only lets requests made by admins through. It has to come after one of the
+// authentication middlewares.
+func validateAdminMiddleware(res http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+	user, ok := authenticatedUserFromRequest(req)
+	if !ok || !user.IsAdmin {
+		notifyOfForbiddenRequest(res)
+		return
+	}
+	next(res, req)
+}
+
+// validatePermissionMiddleware only lets requests made by users with the given permission through. Like
+// validateAdminMiddleware, it has to come after one of the authentication middlewares.
+func validatePermissionMiddleware(res http.ResponseWriter, req *http.Request, permission string, next http.HandlerFunc) {
+	user, ok := authenticatedUserFromRequest(req)
+	if !ok || !user.hasPermission(permission) {
+		notifyOfForbiddenRequest(res)
 		return
 	}
 	next(res, req)
@@ -182,8 +349,17 @@ func buildUserCreationHandler(db *sql.DB, client database.Storer, store *session
 		}
 
 		if userInput.IsAdmin {
-			// only an admin user can create an admin user
-			if admin, ok := session.Values[sessionAdminKeyName].(bool); !ok || !admin {
+			// only an admin user can create an admin user, whether they're signed in or using an API key. Anyone
+			// can sign up, so the route is public, and requests only have to be authenticated to create admins.
+			var requester *authenticatedUser
+			validateRequestAuthenticationMiddleware(res, req, db, client, store, func(_ http.ResponseWriter, authenticated *http.Request) {
+				requester, _ = authenticatedUserFromRequest(authenticated)
+			})
+			if requester == nil {
+				// the middleware has already said why
+				return
+			}
+			if !requester.IsAdmin {
 				res.WriteHeader(http.StatusForbidden)
 				errRes := &ErrorResponse{
 					Status:  http.StatusForbidden,
@@ -208,11 +384,32 @@ func buildUserCreationHandler(db *sql.DB, client database.Storer, store *session
 			return
 		}
 
-		createdUserID, createdOn, err := client.CreateUser(db, newUser)
+		tx, err := db.Begin()
+		if err != nil {
+			notifyOfInternalIssue(res, err, "create new database transaction")
+			return
+		}
+
+		createdUserID, createdOn, err := client.CreateUser(tx, newUser)
 		if err != nil {
+			tx.Rollback()
 			notifyOfInternalIssue(res, err, "insert user in database")
 			return
 		}
+		newUser.ID, newUser.CreatedOn = createdUserID, createdOn
+
+		err = enqueueWebhookDeliveries(tx, client, UserCreatedWebhookEvent, createUserResponseFromUser(newUser))
+		if err != nil {
+			tx.Rollback()
+			notifyOfInternalIssue(res, err, "queue webhook deliveries")
+			return
+		}
+
+		err = tx.Commit()
+		if err != nil {
+			notifyOfInternalIssue(res, err, "close out transaction")
+			return
+		}
 
 		responseUser := &DisplayUser{
 			ID:        createdUserID,
@@ -224,7 +421,6 @@ func buildUserCreationHandler(db *sql.DB, client database.Storer, store *session
 		}
 		session.Values[sessionUserIDKeyName] = createdUserID
 		session.Values[sessionAuthorizedKeyName] = true
-		session.Values[sessionAdminKeyName] = newUser.IsAdmin
 		session.Save(req, res)
 
 		res.WriteHeader(http.StatusCreated)
@@ -310,7 +506,6 @@ func buildUserLoginHandler(db *sql.DB, client database.Storer, store *sessions.C
 			statusToWrite = http.StatusOK
 			session.Values[sessionUserIDKeyName] = user.ID
 			session.Values[sessionAuthorizedKeyName] = true
-			session.Values[sessionAdminKeyName] = user.IsAdmin
 			session.Save(req, res)
 		}
 		res.WriteHeader(statusToWrite)
@@ -330,7 +525,8 @@ func buildUserLogoutHandler(store *sessions.CookieStore) http.HandlerFunc {
 	}
 }
 
-func buildUserDeletionHandler(db *sql.DB, client database.Storer, store *sessions.CookieStore) http.HandlerFunc {
+func buildUserDeletionHandler(db *sql.DB, client database.Storer) http.HandlerFunc {
+	// only admins can get here, so there's no need to check who's deleting the user
 	return func(res http.ResponseWriter, req *http.Request) {
 		userID := chi.URLParam(req, "user_id")
 		// we can eat this error because Mux takes care of validating route params for us
@@ -348,36 +544,38 @@ func buildUserDeletionHandler(db *sql.DB, client database.Storer, store *session
 			return
 		}
 
-		session, err := store.Get(req, dairycartCookieName)
+		tx, err := db.Begin()
 		if err != nil {
-			notifyOfInvalidRequestCookie(res)
+			notifyOfInternalIssue(res, err, "create new database transaction")
 			return
 		}
 
-		// only an admin user can delete an admin user
-		admin, ok := session.Values[sessionAdminKeyName].(bool)
-		if !ok || !admin {
-			res.WriteHeader(http.StatusForbidden)
-			errRes := &ErrorResponse{
-				Status:  http.StatusForbidden,
-				Message: "User is not authorized to delete users",
-			}
-			json.NewEncoder(res).Encode(errRes)
+		archivedOn, err := client.DeleteUser(tx, userIDInt64)
+		if err != nil {
+			tx.Rollback()
+			notifyOfInternalIssue(res, err, "archive user")
+			return
+		}
+		user.ArchivedOn = &models.Dairytime{Time: archivedOn}
+
+		err = enqueueWebhookDeliveries(tx, client, UserArchivedWebhookEvent, createUserResponseFromUser(user))
+		if err != nil {
+			tx.Rollback()
+			notifyOfInternalIssue(res, err, "queue webhook deliveries")
+			return
+		}
+
+		err = tx.Commit()
+		if err != nil {
+			notifyOfInternalIssue(res, err, "close out transaction")
 			return
-		} else if admin {
-			archivedOn, err := client.DeleteUser(db, userIDInt64)
-			user.ArchivedOn = &models.Dairytime{Time: archivedOn}
-			if err != nil {
-				notifyOfInternalIssue(res, err, "archive user")
-				return
-			}
 		}
 
 		json.NewEncoder(res).Encode(createUserResponseFromUser(user))
 	}
 }
 
-func buildUserForgottenPasswordHandler(db *sql.DB, client database.Storer) http.HandlerFunc {
+func buildUserForgottenPasswordHandler(db *sql.DB, client database.Storer, notifier PasswordResetNotifier) http.HandlerFunc {
 	return func(res http.ResponseWriter, req *http.Request) {
 		loginInput := &UserLoginInput{}
 		err := validateRequestInput(req, loginInput)
@@ -397,24 +595,128 @@ func buildUserForgottenPasswordHandler(db *sql.DB, client database.Storer) http.
 			return
 		}
 
-		exists, err := client.PasswordResetTokenForUserIDExists(db, user.ID)
-		if err != nil || exists {
-			notifyOfInvalidRequestBody(res, errors.New("user has existent, non-expired password reset request"))
+		createPasswordResetToken(res, db, client, notifier, user)
+	}
+}
+
+// createPasswordResetToken creates a password reset token for a user and hands it to the notifier, unless they
+// already have one that hasn't expired. The token never appears in the response, so only the user can redeem it.
+func createPasswordResetToken(res http.ResponseWriter, db *sql.DB, client database.Storer, notifier PasswordResetNotifier, user *models.User) {
+	exists, err := client.PasswordResetTokenForUserIDExists(db, user.ID)
+	if err != nil || exists {
+		notifyOfInvalidRequestBody(res, errors.New("user has existent, non-expired password reset request"))
+		return
+	}
+
+	resetToken := &models.PasswordResetToken{
+		UserID: user.ID,
+		Token:  uniuri.NewLen(resetTokenSize),
+	}
+
+	resetToken.ID, resetToken.CreatedOn, err = client.CreatePasswordResetToken(db, resetToken)
+	if err != nil {
+		notifyOfInternalIssue(res, err, "create password reset token")
+		return
+	}
+
+	if err = notifier.NotifyPasswordReset(user, resetToken); err != nil {
+		notifyOfInternalIssue(res, err, "deliver password reset token")
+		return
+	}
+
+	res.WriteHeader(http.StatusAccepted)
+}
+
+func buildUserPasswordResetHandler(db *sql.DB, client database.Storer, notifier PasswordResetNotifier) http.HandlerFunc {
+	// UserPasswordResetHandler is a request handler that lets support staff start a password reset for a user. Staff
+	// can't use it against admins, or against anyone holding a permission they don't have themselves.
+	return func(res http.ResponseWriter, req *http.Request) {
+		requester, _ := authenticatedUserFromRequest(req)
+
+		userIDStr := chi.URLParam(req, "user_id")
+		// eating this error because the router should have ensured this is an integer
+		userID, _ := strconv.ParseUint(userIDStr, 10, 64)
+
+		user, err := client.GetUser(db, userID)
+		if err == sql.ErrNoRows {
+			respondThatRowDoesNotExist(req, res, "user ID", userIDStr)
+			return
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve user")
+			return
+		}
+
+		if user.IsAdmin && !requester.IsAdmin {
+			notifyOfForbiddenRequest(res)
+			return
+		}
+
+		permissions, err := client.GetPermissionsByUserID(db, user.ID)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve user permissions")
 			return
 		}
+		for _, p := range permissions {
+			if !requester.hasPermission(p) {
+				notifyOfForbiddenRequest(res)
+				return
+			}
+		}
 
-		resetToken := &models.PasswordResetToken{
-			UserID: user.ID,
-			Token:  uniuri.NewLen(resetTokenSize),
+		createPasswordResetToken(res, db, client, notifier, user)
+	}
+}
+
+func buildUserListHandler(db *sql.DB, client database.Storer) http.HandlerFunc {
+	// UserListHandler is a request handler that returns a list of users, without their passwords
+	return func(res http.ResponseWriter, req *http.Request) {
+		rawFilterParams := req.URL.Query()
+		queryFilter := parseRawFilterParams(rawFilterParams)
+
+		count, err := client.GetUserCount(db, queryFilter)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve count of users from the database")
+			return
 		}
 
-		resetToken.ID, resetToken.CreatedOn, err = client.CreatePasswordResetToken(db, resetToken)
+		users, err := client.GetUserList(db, queryFilter)
 		if err != nil {
-			notifyOfInternalIssue(res, err, "read session data")
+			notifyOfInternalIssue(res, err, "retrieve users from the database")
+			return
+		}
+
+		userResponses := []models.UserResponse{}
+		for i := range users {
+			userResponses = append(userResponses, *createUserResponseFromUser(&users[i]))
+		}
+
+		usersResponse := &ListResponse{
+			Page:  queryFilter.Page,
+			Limit: queryFilter.Limit,
+			Count: count,
+			Data:  userResponses,
+		}
+		json.NewEncoder(res).Encode(usersResponse)
+	}
+}
+
+func buildSingleUserHandler(db *sql.DB, client database.Storer) http.HandlerFunc {
+	// SingleUserHandler is a request handler that returns a single user, without their password
+	return func(res http.ResponseWriter, req *http.Request) {
+		userIDStr := chi.URLParam(req, "user_id")
+		// eating this error because the router should have ensured this is an integer
+		userID, _ := strconv.ParseUint(userIDStr, 10, 64)
+
+		user, err := client.GetUser(db, userID)
+		if err == sql.ErrNoRows {
+			respondThatRowDoesNotExist(req, res, "user ID", userIDStr)
+			return
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve user")
 			return
 		}
 
-		json.NewEncoder(res).Encode(resetToken)
+		json.NewEncoder(res).Encode(createUserResponseFromUser(user))
 	}
 }
 
@@ -440,6 +742,12 @@ func buildUserUpdateHandler(db *sql.DB, client database.Storer) http.HandlerFunc
 		userIDInt, _ := strconv.Atoi(userID)
 		userIDInt64 := uint64(userIDInt)
 
+		// users can only update themselves, unless they're an admin
+		if requester, ok := authenticatedUserFromRequest(req); !ok || (requester.ID != userIDInt64 && !requester.IsAdmin) {
+			notifyOfForbiddenRequest(res)
+			return
+		}
+
 		updatedUserInfo := &models.UserUpdateInput{}
 		err := validateRequestInput(req, updatedUserInfo)
 		if err != nil {
@@ -499,13 +807,33 @@ func buildUserUpdateHandler(db *sql.DB, client database.Storer) http.HandlerFunc
 			updatedUser.PasswordLastChangedOn = &models.Dairytime{Time: time.Now()}
 		}
 
-		updatedOn, err := client.UpdateUser(db, updatedUser)
+		tx, err := db.Begin()
 		if err != nil {
+			notifyOfInternalIssue(res, err, "create new database transaction")
+			return
+		}
+
+		updatedOn, err := client.UpdateUser(tx, updatedUser)
+		if err != nil {
+			tx.Rollback()
 			notifyOfInternalIssue(res, err, "update user")
 			return
 		}
 		updatedUser.UpdatedOn = &models.Dairytime{Time: updatedOn}
 
+		err = enqueueWebhookChangeDeliveries(tx, client, UserUpdatedWebhookEvent, createUserResponseFromUser(existingUser), createUserResponseFromUser(updatedUser))
+		if err != nil {
+			tx.Rollback()
+			notifyOfInternalIssue(res, err, "queue webhook deliveries")
+			return
+		}
+
+		err = tx.Commit()
+		if err != nil {
+			notifyOfInternalIssue(res, err, "close out transaction")
+			return
+		}
+
 		json.NewEncoder(res).Encode(updatedUser)
 	}
 }
@@ -7,11 +7,13 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/dairycart/dairycart/models/v1"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -28,8 +30,52 @@ const (
 func TestValidateSessionCookieMiddleware(t *testing.T) {
 	t.Parallel()
 
+	exampleUser := &models.User{ID: 1}
+	buildRequest := func(testUtil *TestUtil) *http.Request {
+		req, err := http.NewRequest(http.MethodGet, "", nil)
+		assert.NoError(t, err)
+
+		session, err := testUtil.Store.Get(req, dairycartCookieName)
+		assert.NoError(t, err)
+		session.Values[sessionUserIDKeyName] = exampleUser.ID
+		session.Values[sessionAuthorizedKeyName] = true
+		session.Save(req, testUtil.Response)
+		return req
+	}
+
 	t.Run("normal operation", func(_t *testing.T) {
 		_t.Parallel()
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetUser", mock.Anything, exampleUser.ID).Return(exampleUser, nil)
+		testUtil.MockDB.On("GetPermissionsByUserID", mock.Anything, exampleUser.ID).Return([]string{"products:write"}, nil)
+
+		var actual *authenticatedUser
+		exampleHandler := func(w http.ResponseWriter, r *http.Request) {
+			actual, _ = authenticatedUserFromRequest(r)
+		}
+
+		validateSessionCookieMiddleware(testUtil.Response, buildRequest(testUtil), testUtil.PlainDB, testUtil.MockDB, testUtil.Store, exampleHandler)
+		expected := &authenticatedUser{ID: exampleUser.ID, Permissions: []string{"products:write"}}
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("with admin", func(_t *testing.T) {
+		_t.Parallel()
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetUser", mock.Anything, exampleUser.ID).Return(&models.User{ID: exampleUser.ID, IsAdmin: true}, nil)
+
+		var actual *authenticatedUser
+		exampleHandler := func(w http.ResponseWriter, r *http.Request) {
+			actual, _ = authenticatedUserFromRequest(r)
+		}
+
+		validateSessionCookieMiddleware(testUtil.Response, buildRequest(testUtil), testUtil.PlainDB, testUtil.MockDB, testUtil.Store, exampleHandler)
+		assert.Equal(t, &authenticatedUser{ID: exampleUser.ID, IsAdmin: true}, actual)
+		testUtil.MockDB.AssertNotCalled(t, "GetPermissionsByUserID", mock.Anything, mock.Anything)
+	})
+
+	t.Run("with invalid cookie", func(_t *testing.T) {
+		_t.Parallel()
 
 		handlerWasCalled := false
 		exampleHandler := func(w http.ResponseWriter, r *http.Request) {
@@ -41,33 +87,310 @@ func TestValidateSessionCookieMiddleware(t *testing.T) {
 		req, err := http.NewRequest(http.MethodGet, "", nil)
 		assert.NoError(t, err)
 
-		session, err := testUtil.Store.Get(req, dairycartCookieName)
+		validateSessionCookieMiddleware(testUtil.Response, req, testUtil.PlainDB, testUtil.MockDB, testUtil.Store, exampleHandler)
+		assert.False(t, handlerWasCalled)
+		assertStatusCode(t, testUtil, http.StatusUnauthorized)
+	})
+
+	t.Run("with archived user", func(_t *testing.T) {
+		_t.Parallel()
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetUser", mock.Anything, exampleUser.ID).Return(exampleUser, sql.ErrNoRows)
+		exampleHandler := func(w http.ResponseWriter, r *http.Request) {
+			t.Fatal("handler should not be called")
+		}
+
+		validateSessionCookieMiddleware(testUtil.Response, buildRequest(testUtil), testUtil.PlainDB, testUtil.MockDB, testUtil.Store, exampleHandler)
+		assertStatusCode(t, testUtil, http.StatusUnauthorized)
+	})
+
+	t.Run("with error retrieving user", func(_t *testing.T) {
+		_t.Parallel()
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetUser", mock.Anything, exampleUser.ID).Return(exampleUser, generateArbitraryError())
+		exampleHandler := func(w http.ResponseWriter, r *http.Request) {
+			t.Fatal("handler should not be called")
+		}
+
+		validateSessionCookieMiddleware(testUtil.Response, buildRequest(testUtil), testUtil.PlainDB, testUtil.MockDB, testUtil.Store, exampleHandler)
+		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+	})
+
+	t.Run("with error retrieving permissions", func(_t *testing.T) {
+		_t.Parallel()
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetUser", mock.Anything, exampleUser.ID).Return(exampleUser, nil)
+		testUtil.MockDB.On("GetPermissionsByUserID", mock.Anything, exampleUser.ID).Return([]string(nil), generateArbitraryError())
+		exampleHandler := func(w http.ResponseWriter, r *http.Request) {
+			t.Fatal("handler should not be called")
+		}
+
+		validateSessionCookieMiddleware(testUtil.Response, buildRequest(testUtil), testUtil.PlainDB, testUtil.MockDB, testUtil.Store, exampleHandler)
+		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+	})
+}
+
+func TestValidateAPIKeyMiddleware(t *testing.T) {
+	t.Parallel()
+
+	exampleKey := "dc_abcdefgh_abcdefghijklmnopqrstuvwxyz123456"
+	exampleUser := &models.User{ID: 1, IsAdmin: true}
+	buildExampleAPIKey := func() *models.APIKey {
+		return &models.APIKey{ID: 2, UserID: exampleUser.ID, Prefix: "dc_abcdefgh", KeyHash: hashAPIKey(exampleKey)}
+	}
+	buildRequest := func(authorization string) *http.Request {
+		req, err := http.NewRequest(http.MethodGet, "", nil)
 		assert.NoError(t, err)
-		session.Values[sessionAuthorizedKeyName] = true
-		session.Save(req, testUtil.Response)
+		req.Header.Set("Authorization", authorization)
+		return req
+	}
+
+	t.Run("normal operation", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		exampleAPIKey := buildExampleAPIKey()
+		testUtil.MockDB.On("GetAPIKeyByHash", mock.Anything, hashAPIKey(exampleKey)).Return(exampleAPIKey, nil)
+		testUtil.MockDB.On("GetUser", mock.Anything, exampleUser.ID).Return(exampleUser, nil)
+		testUtil.MockDB.On("MarkAPIKeyUsed", mock.Anything, exampleAPIKey.ID).Return(buildTestTime(), nil)
+
+		var actual *authenticatedUser
+		exampleHandler := func(w http.ResponseWriter, r *http.Request) {
+			actual, _ = authenticatedUserFromRequest(r)
+		}
+
+		validateAPIKeyMiddleware(testUtil.Response, buildRequest("Bearer "+exampleKey), testUtil.PlainDB, testUtil.MockDB, exampleHandler)
+		expected := &authenticatedUser{ID: exampleUser.ID, IsAdmin: true, APIKeyID: &exampleAPIKey.ID}
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("with recently used key", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		exampleAPIKey := buildExampleAPIKey()
+		exampleAPIKey.LastUsedOn = &models.Dairytime{Time: time.Now()}
+		testUtil.MockDB.On("GetAPIKeyByHash", mock.Anything, hashAPIKey(exampleKey)).Return(exampleAPIKey, nil)
+		testUtil.MockDB.On("GetUser", mock.Anything, exampleUser.ID).Return(exampleUser, nil)
+
+		handlerWasCalled := false
+		exampleHandler := func(w http.ResponseWriter, r *http.Request) {
+			handlerWasCalled = true
+		}
 
-		validateSessionCookieMiddleware(testUtil.Response, req, testUtil.Store, exampleHandler)
+		validateAPIKeyMiddleware(testUtil.Response, buildRequest("Bearer "+exampleKey), testUtil.PlainDB, testUtil.MockDB, exampleHandler)
 		assert.True(t, handlerWasCalled)
+		testUtil.MockDB.AssertNotCalled(t, "MarkAPIKeyUsed", mock.Anything, mock.Anything)
 	})
 
-	t.Run("with invalid cookie", func(_t *testing.T) {
-		_t.Parallel()
+	t.Run("with error marking key used", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		exampleAPIKey := buildExampleAPIKey()
+		testUtil.MockDB.On("GetAPIKeyByHash", mock.Anything, hashAPIKey(exampleKey)).Return(exampleAPIKey, nil)
+		testUtil.MockDB.On("GetUser", mock.Anything, exampleUser.ID).Return(exampleUser, nil)
+		testUtil.MockDB.On("MarkAPIKeyUsed", mock.Anything, exampleAPIKey.ID).Return(time.Time{}, generateArbitraryError())
 
 		handlerWasCalled := false
 		exampleHandler := func(w http.ResponseWriter, r *http.Request) {
 			handlerWasCalled = true
 		}
 
+		validateAPIKeyMiddleware(testUtil.Response, buildRequest("Bearer "+exampleKey), testUtil.PlainDB, testUtil.MockDB, exampleHandler)
+		assert.True(t, handlerWasCalled)
+	})
+
+	t.Run("with malformed header", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		exampleHandler := func(w http.ResponseWriter, r *http.Request) {
+			t.Fatal("handler should not be called")
+		}
+
+		validateAPIKeyMiddleware(testUtil.Response, buildRequest("Basic "+exampleKey), testUtil.PlainDB, testUtil.MockDB, exampleHandler)
+		assertStatusCode(t, testUtil, http.StatusUnauthorized)
+		assert.Equal(t, "Bearer", testUtil.Response.Header().Get("WWW-Authenticate"))
+	})
+
+	t.Run("with unknown or revoked key", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetAPIKeyByHash", mock.Anything, hashAPIKey(exampleKey)).Return(buildExampleAPIKey(), sql.ErrNoRows)
+		exampleHandler := func(w http.ResponseWriter, r *http.Request) {
+			t.Fatal("handler should not be called")
+		}
+
+		validateAPIKeyMiddleware(testUtil.Response, buildRequest("Bearer "+exampleKey), testUtil.PlainDB, testUtil.MockDB, exampleHandler)
+		assertStatusCode(t, testUtil, http.StatusUnauthorized)
+	})
+
+	t.Run("with error retrieving key", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetAPIKeyByHash", mock.Anything, hashAPIKey(exampleKey)).Return(buildExampleAPIKey(), generateArbitraryError())
+		exampleHandler := func(w http.ResponseWriter, r *http.Request) {
+			t.Fatal("handler should not be called")
+		}
+
+		validateAPIKeyMiddleware(testUtil.Response, buildRequest("Bearer "+exampleKey), testUtil.PlainDB, testUtil.MockDB, exampleHandler)
+		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+	})
+
+	t.Run("with expired key", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		exampleAPIKey := buildExampleAPIKey()
+		exampleAPIKey.ExpiresOn = &models.Dairytime{Time: time.Now().Add(-time.Hour)}
+		testUtil.MockDB.On("GetAPIKeyByHash", mock.Anything, hashAPIKey(exampleKey)).Return(exampleAPIKey, nil)
+		exampleHandler := func(w http.ResponseWriter, r *http.Request) {
+			t.Fatal("handler should not be called")
+		}
+
+		validateAPIKeyMiddleware(testUtil.Response, buildRequest("Bearer "+exampleKey), testUtil.PlainDB, testUtil.MockDB, exampleHandler)
+		assertStatusCode(t, testUtil, http.StatusUnauthorized)
+	})
+
+	t.Run("with archived user", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetAPIKeyByHash", mock.Anything, hashAPIKey(exampleKey)).Return(buildExampleAPIKey(), nil)
+		testUtil.MockDB.On("GetUser", mock.Anything, exampleUser.ID).Return(exampleUser, sql.ErrNoRows)
+		exampleHandler := func(w http.ResponseWriter, r *http.Request) {
+			t.Fatal("handler should not be called")
+		}
+
+		validateAPIKeyMiddleware(testUtil.Response, buildRequest("Bearer "+exampleKey), testUtil.PlainDB, testUtil.MockDB, exampleHandler)
+		assertStatusCode(t, testUtil, http.StatusUnauthorized)
+	})
+
+	t.Run("with error retrieving user", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetAPIKeyByHash", mock.Anything, hashAPIKey(exampleKey)).Return(buildExampleAPIKey(), nil)
+		testUtil.MockDB.On("GetUser", mock.Anything, exampleUser.ID).Return(exampleUser, generateArbitraryError())
+		exampleHandler := func(w http.ResponseWriter, r *http.Request) {
+			t.Fatal("handler should not be called")
+		}
+
+		validateAPIKeyMiddleware(testUtil.Response, buildRequest("Bearer "+exampleKey), testUtil.PlainDB, testUtil.MockDB, exampleHandler)
+		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+	})
+
+	t.Run("as a user with roles", func(*testing.T) {
 		testUtil := setupTestVariablesWithMock(t)
+		exampleCustomer := &models.User{ID: 3}
+		exampleAPIKey := buildExampleAPIKey()
+		exampleAPIKey.UserID = exampleCustomer.ID
+		testUtil.MockDB.On("GetAPIKeyByHash", mock.Anything, hashAPIKey(exampleKey)).Return(exampleAPIKey, nil)
+		testUtil.MockDB.On("GetUser", mock.Anything, exampleCustomer.ID).Return(exampleCustomer, nil)
+		testUtil.MockDB.On("GetPermissionsByUserID", mock.Anything, exampleCustomer.ID).Return([]string{"webhooks:read"}, nil)
+		testUtil.MockDB.On("MarkAPIKeyUsed", mock.Anything, exampleAPIKey.ID).Return(buildTestTime(), nil)
+
+		var actual *authenticatedUser
+		exampleHandler := func(w http.ResponseWriter, r *http.Request) {
+			actual, _ = authenticatedUserFromRequest(r)
+		}
+
+		validateAPIKeyMiddleware(testUtil.Response, buildRequest("Bearer "+exampleKey), testUtil.PlainDB, testUtil.MockDB, exampleHandler)
+		expected := &authenticatedUser{ID: exampleCustomer.ID, Permissions: []string{"webhooks:read"}, APIKeyID: &exampleAPIKey.ID}
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("with error retrieving permissions", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		exampleCustomer := &models.User{ID: 3}
+		exampleAPIKey := buildExampleAPIKey()
+		exampleAPIKey.UserID = exampleCustomer.ID
+		testUtil.MockDB.On("GetAPIKeyByHash", mock.Anything, hashAPIKey(exampleKey)).Return(exampleAPIKey, nil)
+		testUtil.MockDB.On("GetUser", mock.Anything, exampleCustomer.ID).Return(exampleCustomer, nil)
+		testUtil.MockDB.On("GetPermissionsByUserID", mock.Anything, exampleCustomer.ID).Return([]string{}, generateArbitraryError())
+		testUtil.MockDB.On("MarkAPIKeyUsed", mock.Anything, exampleAPIKey.ID).Return(buildTestTime(), nil)
+		exampleHandler := func(w http.ResponseWriter, r *http.Request) {
+			t.Fatal("handler should not be called")
+		}
 
+		validateAPIKeyMiddleware(testUtil.Response, buildRequest("Bearer "+exampleKey), testUtil.PlainDB, testUtil.MockDB, exampleHandler)
+		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+	})
+}
+
+func TestValidateRequestAuthenticationMiddleware(t *testing.T) {
+	t.Parallel()
+
+	t.Run("with cookie", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
 		req, err := http.NewRequest(http.MethodGet, "", nil)
 		assert.NoError(t, err)
+		cookie, err := buildCookieForRequest(t, testUtil, true, false)
+		assert.NoError(t, err)
+		req.AddCookie(cookie)
 
-		validateSessionCookieMiddleware(testUtil.Response, req, testUtil.Store, exampleHandler)
-		assert.False(t, handlerWasCalled)
+		var actual *authenticatedUser
+		exampleHandler := func(w http.ResponseWriter, r *http.Request) {
+			actual, _ = authenticatedUserFromRequest(r)
+		}
+
+		validateRequestAuthenticationMiddleware(testUtil.Response, req, testUtil.PlainDB, testUtil.MockDB, testUtil.Store, exampleHandler)
+		assert.Equal(t, &authenticatedUser{ID: 666}, actual)
+	})
+
+	t.Run("with cookie and invalid key", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		req, err := http.NewRequest(http.MethodGet, "", nil)
+		assert.NoError(t, err)
+		cookie, err := buildCookieForRequest(t, testUtil, true, false)
+		assert.NoError(t, err)
+		req.AddCookie(cookie)
+		req.Header.Set("Authorization", "Bearer nonsense")
+		testUtil.MockDB.On("GetAPIKeyByHash", mock.Anything, hashAPIKey("nonsense")).Return(&models.APIKey{}, sql.ErrNoRows)
+
+		exampleHandler := func(w http.ResponseWriter, r *http.Request) {
+			t.Fatal("handler should not be called")
+		}
+
+		validateRequestAuthenticationMiddleware(testUtil.Response, req, testUtil.PlainDB, testUtil.MockDB, testUtil.Store, exampleHandler)
+		assertStatusCode(t, testUtil, http.StatusUnauthorized)
+	})
+
+	t.Run("with cookie carrying permissions", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		req, err := http.NewRequest(http.MethodGet, "", nil)
+		assert.NoError(t, err)
+		req.AddCookie(buildCookieWithPermissionsForRequest(t, testUtil, "discounts:read", "discounts:write"))
+
+		var actual *authenticatedUser
+		exampleHandler := func(w http.ResponseWriter, r *http.Request) {
+			actual, _ = authenticatedUserFromRequest(r)
+		}
+
+		validateRequestAuthenticationMiddleware(testUtil.Response, req, testUtil.PlainDB, testUtil.MockDB, testUtil.Store, exampleHandler)
+		assert.Equal(t, &authenticatedUser{ID: 666, Permissions: []string{"discounts:read", "discounts:write"}}, actual)
 	})
 }
 
+func TestValidatePermissionMiddleware(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		user     *authenticatedUser
+		expected int
+	}{
+		{name: "as an admin", user: &authenticatedUser{ID: 1, IsAdmin: true}, expected: http.StatusOK},
+		{name: "with permission", user: &authenticatedUser{ID: 1, Permissions: []string{"discounts:read", "products:write"}}, expected: http.StatusOK},
+		{name: "without permission", user: &authenticatedUser{ID: 1, Permissions: []string{"discounts:read"}}, expected: http.StatusForbidden},
+		{name: "without any roles", user: &authenticatedUser{ID: 1}, expected: http.StatusForbidden},
+		{name: "without a user", expected: http.StatusForbidden},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(*testing.T) {
+			testUtil := setupTestVariablesWithMock(t)
+			req, err := http.NewRequest(http.MethodGet, "", nil)
+			assert.NoError(t, err)
+			if tc.user != nil {
+				req = withAuthenticatedUser(req, tc.user)
+			}
+
+			exampleHandler := func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}
+
+			validatePermissionMiddleware(testUtil.Response, req, "products:write", exampleHandler)
+			assertStatusCode(t, testUtil, tc.expected)
+		})
+	}
+}
+
 func TestPasswordIsValid(t *testing.T) {
 	inputOutputMap := map[string]bool{
 		// the worst password ever
@@ -303,10 +626,15 @@ func TestUserCreationHandler(t *testing.T) {
 
 	t.Run("optimal conditions", func(*testing.T) {
 		testUtil := setupTestVariablesWithMock(t)
+		testUtil.Mock.ExpectBegin()
+		testUtil.Mock.ExpectCommit()
 		testUtil.MockDB.On("UserWithUsernameExists", mock.Anything, exampleUser.Username).
 			Return(false, nil)
 		testUtil.MockDB.On("CreateUser", mock.Anything, mock.Anything).
 			Return(exampleUser.ID, buildTestTime(), nil)
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, UserCreatedWebhookEvent).
+			Return([]models.Webhook{}, nil)
 		config := buildServerConfigFromTestUtil(testUtil)
 		SetupAPIRouter(config)
 
@@ -314,6 +642,7 @@ func TestUserCreationHandler(t *testing.T) {
 		assert.NoError(t, err)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusCreated)
+		ensureExpectationsWereMet(t, testUtil.Mock)
 	})
 
 	t.Run("invalid user creation input", func(*testing.T) {
@@ -359,8 +688,52 @@ func TestUserCreationHandler(t *testing.T) {
 
 		req, err := http.NewRequest(http.MethodPost, "/user", strings.NewReader(exampleAdminInput))
 		assert.NoError(t, err)
+		cookie, err := buildCookieForRequest(t, testUtil, true, false)
+		assert.NoError(t, err)
+		req.AddCookie(cookie)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusForbidden)
+		testUtil.MockDB.AssertNotCalled(t, "CreateUser", mock.Anything, mock.Anything)
+	})
+
+	t.Run("creating an admin user without signing in", func(t *testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPost, "/user", strings.NewReader(exampleAdminInput))
+		assert.NoError(t, err)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusUnauthorized)
+		testUtil.MockDB.AssertNotCalled(t, "CreateUser", mock.Anything, mock.Anything)
+	})
+
+	t.Run("creating an admin user with an admin's API key", func(t *testing.T) {
+		exampleKey := "dc_abcdefgh_abcdefghijklmnopqrstuvwxyz123456"
+		exampleAPIKey := &models.APIKey{ID: 2, UserID: 3, Prefix: "dc_abcdefgh", KeyHash: hashAPIKey(exampleKey)}
+
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.Mock.ExpectBegin()
+		testUtil.Mock.ExpectCommit()
+		testUtil.MockDB.On("GetAPIKeyByHash", mock.Anything, hashAPIKey(exampleKey)).Return(exampleAPIKey, nil)
+		testUtil.MockDB.On("GetUser", mock.Anything, exampleAPIKey.UserID).Return(&models.User{ID: exampleAPIKey.UserID, IsAdmin: true}, nil)
+		testUtil.MockDB.On("MarkAPIKeyUsed", mock.Anything, exampleAPIKey.ID).Return(buildTestTime(), nil)
+		testUtil.MockDB.On("UserWithUsernameExists", mock.Anything, exampleUser.Username).
+			Return(false, nil)
+		testUtil.MockDB.On("CreateUser", mock.Anything, mock.MatchedBy(func(u *models.User) bool { return u.IsAdmin })).
+			Return(exampleUser.ID, buildTestTime(), nil)
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, UserCreatedWebhookEvent).
+			Return([]models.Webhook{}, nil)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPost, "/user", strings.NewReader(exampleAdminInput))
+		assert.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+exampleKey)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusCreated)
+		ensureExpectationsWereMet(t, testUtil.Mock)
 	})
 
 	t.Run("with invalid input", func(*testing.T) {
@@ -388,6 +761,8 @@ func TestUserCreationHandler(t *testing.T) {
 
 	t.Run("with error creating user", func(*testing.T) {
 		testUtil := setupTestVariablesWithMock(t)
+		testUtil.Mock.ExpectBegin()
+		testUtil.Mock.ExpectRollback()
 		testUtil.MockDB.On("UserWithUsernameExists", mock.Anything, exampleUser.Username).
 			Return(false, nil)
 		testUtil.MockDB.On("CreateUser", mock.Anything, mock.Anything).
@@ -399,6 +774,28 @@ func TestUserCreationHandler(t *testing.T) {
 		assert.NoError(t, err)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+		ensureExpectationsWereMet(t, testUtil.Mock)
+	})
+
+	t.Run("with error queueing webhook deliveries", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.Mock.ExpectBegin()
+		testUtil.Mock.ExpectRollback()
+		testUtil.MockDB.On("UserWithUsernameExists", mock.Anything, exampleUser.Username).
+			Return(false, nil)
+		testUtil.MockDB.On("CreateUser", mock.Anything, mock.Anything).
+			Return(exampleUser.ID, buildTestTime(), nil)
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, UserCreatedWebhookEvent).
+			Return([]models.Webhook{}, generateArbitraryError())
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPost, "/user", strings.NewReader(exampleInput))
+		assert.NoError(t, err)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+		ensureExpectationsWereMet(t, testUtil.Mock)
 	})
 }
 
@@ -437,6 +834,15 @@ func TestUserLoginHandler(t *testing.T) {
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assert.Contains(t, testUtil.Response.HeaderMap, "Set-Cookie", "login handler should attach a cookie when request is valid")
 		assertStatusCode(t, testUtil, http.StatusOK)
+
+		// the session only says who the user is; what they're allowed to do is looked up on every request
+		followUp, err := http.NewRequest(http.MethodGet, "", nil)
+		assert.NoError(t, err)
+		followUp.Header.Set("Cookie", testUtil.Response.Header().Get("Set-Cookie"))
+		session, err := testUtil.Store.Get(followUp, dairycartCookieName)
+		assert.NoError(t, err)
+		assert.Equal(t, exampleUser.ID, session.Values[sessionUserIDKeyName])
+		testUtil.MockDB.AssertNotCalled(t, "GetPermissionsByUserID", mock.Anything, mock.Anything)
 	})
 
 	t.Run("with invalid login input", func(*testing.T) {
@@ -685,22 +1091,28 @@ func TestUserDeletionHandler(t *testing.T) {
 
 	t.Run("optimal conditions", func(*testing.T) {
 		testUtil := setupTestVariablesWithMock(t)
+		testUtil.Mock.ExpectBegin()
+		testUtil.Mock.ExpectCommit()
 		testUtil.MockDB.On("GetUser", mock.Anything, exampleID).
 			Return(exampleUser, nil)
 		testUtil.MockDB.On("DeleteUser", mock.Anything, exampleID).
 			Return(buildTestTime(), nil)
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, UserArchivedWebhookEvent).
+			Return([]models.Webhook{}, nil)
 		config := buildServerConfigFromTestUtil(testUtil)
 		SetupAPIRouter(config)
 
 		req, err := http.NewRequest(http.MethodDelete, buildRoute("v1", "user", exampleIDString), nil)
 		assert.NoError(t, err)
 
-		cookie, err := buildCookieForRequest(t, testUtil.Store, true, true)
+		cookie, err := buildCookieForRequest(t, testUtil, true, true)
 		assert.NoError(t, err)
 		req.AddCookie(cookie)
 
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusOK)
+		ensureExpectationsWereMet(t, testUtil.Mock)
 	})
 
 	t.Run("with nonexistent user", func(*testing.T) {
@@ -713,6 +1125,7 @@ func TestUserDeletionHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodDelete, buildRoute("v1", "user", exampleIDString), nil)
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusNotFound)
 	})
@@ -727,6 +1140,7 @@ func TestUserDeletionHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodDelete, buildRoute("v1", "user", exampleIDString), nil)
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusInternalServerError)
 	})
@@ -745,7 +1159,7 @@ func TestUserDeletionHandler(t *testing.T) {
 		attachBadCookieToRequest(req)
 
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
-		assertStatusCode(t, testUtil, http.StatusBadRequest)
+		assertStatusCode(t, testUtil, http.StatusUnauthorized)
 	})
 
 	t.Run("when deleting admin user as regular user", func(*testing.T) {
@@ -759,7 +1173,7 @@ func TestUserDeletionHandler(t *testing.T) {
 
 		req, err := http.NewRequest(http.MethodDelete, buildRoute("v1", "user", exampleIDString), nil)
 		assert.NoError(t, err)
-		cookie, err := buildCookieForRequest(t, testUtil.Store, true, false)
+		cookie, err := buildCookieForRequest(t, testUtil, true, false)
 		assert.NoError(t, err)
 		req.AddCookie(cookie)
 
@@ -769,6 +1183,8 @@ func TestUserDeletionHandler(t *testing.T) {
 
 	t.Run("with error deleting user", func(*testing.T) {
 		testUtil := setupTestVariablesWithMock(t)
+		testUtil.Mock.ExpectBegin()
+		testUtil.Mock.ExpectRollback()
 		testUtil.MockDB.On("GetUser", mock.Anything, exampleID).
 			Return(exampleUser, nil)
 		testUtil.MockDB.On("DeleteUser", mock.Anything, exampleID).
@@ -779,13 +1195,52 @@ func TestUserDeletionHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodDelete, buildRoute("v1", "user", exampleIDString), nil)
 		assert.NoError(t, err)
 
-		cookie, err := buildCookieForRequest(t, testUtil.Store, true, true)
+		cookie, err := buildCookieForRequest(t, testUtil, true, true)
 		assert.NoError(t, err)
 		req.AddCookie(cookie)
 
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+		ensureExpectationsWereMet(t, testUtil.Mock)
 	})
+
+	t.Run("with error queueing webhook deliveries", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.Mock.ExpectBegin()
+		testUtil.Mock.ExpectRollback()
+		testUtil.MockDB.On("GetUser", mock.Anything, exampleID).
+			Return(exampleUser, nil)
+		testUtil.MockDB.On("DeleteUser", mock.Anything, exampleID).
+			Return(buildTestTime(), nil)
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, UserArchivedWebhookEvent).
+			Return([]models.Webhook{}, generateArbitraryError())
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodDelete, buildRoute("v1", "user", exampleIDString), nil)
+		assert.NoError(t, err)
+
+		cookie, err := buildCookieForRequest(t, testUtil, true, true)
+		assert.NoError(t, err)
+		req.AddCookie(cookie)
+
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+		ensureExpectationsWereMet(t, testUtil.Mock)
+	})
+}
+
+// recordingPasswordResetNotifier keeps the last token it was asked to deliver
+type recordingPasswordResetNotifier struct {
+	user  *models.User
+	token *models.PasswordResetToken
+	err   error
+}
+
+func (n *recordingPasswordResetNotifier) NotifyPasswordReset(user *models.User, token *models.PasswordResetToken) error {
+	n.user, n.token = user, token
+	return n.err
 }
 
 func TestUserForgottenPasswordHandler(t *testing.T) {
@@ -814,13 +1269,20 @@ func TestUserForgottenPasswordHandler(t *testing.T) {
 			Return(false, nil)
 		testUtil.MockDB.On("CreatePasswordResetToken", mock.Anything, mock.Anything).
 			Return(exampleUser.ID, buildTestTime(), nil)
+		notifier := &recordingPasswordResetNotifier{}
 		config := buildServerConfigFromTestUtil(testUtil)
+		config.PasswordResetNotifier = notifier
 		SetupAPIRouter(config)
 
 		req, err := http.NewRequest(http.MethodPost, "/password_reset", strings.NewReader(exampleInput))
 		assert.NoError(t, err)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
-		assertStatusCode(t, testUtil, http.StatusOK)
+		assertStatusCode(t, testUtil, http.StatusAccepted)
+
+		require.NotNil(t, notifier.token)
+		assert.Equal(t, exampleUser.ID, notifier.user.ID)
+		assert.NotEmpty(t, notifier.token.Token)
+		assert.NotContains(t, testUtil.Response.Body.String(), notifier.token.Token)
 	})
 
 	t.Run("with invalid input", func(*testing.T) {
@@ -891,6 +1353,26 @@ func TestUserForgottenPasswordHandler(t *testing.T) {
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusInternalServerError)
 	})
+
+	t.Run("with error delivering reset token", func(t *testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetUserByUsername", mock.Anything, exampleUser.Username).
+			Return(exampleUser, nil)
+		testUtil.MockDB.On("PasswordResetTokenForUserIDExists", mock.Anything, mock.Anything).
+			Return(false, nil)
+		testUtil.MockDB.On("CreatePasswordResetToken", mock.Anything, mock.Anything).
+			Return(exampleUser.ID, buildTestTime(), nil)
+		notifier := &recordingPasswordResetNotifier{err: generateArbitraryError()}
+		config := buildServerConfigFromTestUtil(testUtil)
+		config.PasswordResetNotifier = notifier
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPost, "/password_reset", strings.NewReader(exampleInput))
+		assert.NoError(t, err)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+		assert.NotContains(t, testUtil.Response.Body.String(), notifier.token.Token)
+	})
 }
 
 func TestPasswordResetValidationHandler(t *testing.T) {
@@ -945,18 +1427,25 @@ func TestUserUpdateHandler(t *testing.T) {
 
 	t.Run("optimal conditions", func(*testing.T) {
 		testUtil := setupTestVariablesWithMock(t)
+		testUtil.Mock.ExpectBegin()
+		testUtil.Mock.ExpectCommit()
 		testUtil.MockDB.On("GetUser", mock.Anything, exampleUser.ID).
 			Return(exampleUser, nil)
 		testUtil.MockDB.On("UpdateUser", mock.Anything, mock.Anything).
 			Return(buildTestTime(), nil)
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, UserUpdatedWebhookEvent).
+			Return([]models.Webhook{}, nil)
 		config := buildServerConfigFromTestUtil(testUtil)
 		SetupAPIRouter(config)
 
 		req, err := http.NewRequest(http.MethodPatch, fmt.Sprintf("/user/%d", exampleUser.ID), strings.NewReader(exampleUserUpdateInput))
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusOK)
+		ensureExpectationsWereMet(t, testUtil.Mock)
 	})
 
 	t.Run("with invalid input", func(*testing.T) {
@@ -967,6 +1456,7 @@ func TestUserUpdateHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodPatch, fmt.Sprintf("/user/%d", exampleUser.ID), strings.NewReader(exampleGarbageInput))
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusBadRequest)
 	})
@@ -988,6 +1478,7 @@ func TestUserUpdateHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodPatch, fmt.Sprintf("/user/%d", exampleUser.ID), strings.NewReader(exampleInvalidUserUpdateInput))
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusBadRequest)
 	})
@@ -1002,6 +1493,7 @@ func TestUserUpdateHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodPatch, fmt.Sprintf("/user/%d", exampleUser.ID), strings.NewReader(exampleUserUpdateInput))
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusNotFound)
 	})
@@ -1016,6 +1508,7 @@ func TestUserUpdateHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodPatch, fmt.Sprintf("/user/%d", exampleUser.ID), strings.NewReader(exampleUserUpdateInput))
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusInternalServerError)
 	})
@@ -1039,12 +1532,15 @@ func TestUserUpdateHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodPatch, fmt.Sprintf("/user/%d", exampleUser.ID), strings.NewReader(exampleInvalidUserUpdateInput))
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusUnauthorized)
 	})
 
 	t.Run("with error updating user", func(*testing.T) {
 		testUtil := setupTestVariablesWithMock(t)
+		testUtil.Mock.ExpectBegin()
+		testUtil.Mock.ExpectRollback()
 		testUtil.MockDB.On("GetUser", mock.Anything, exampleUser.ID).
 			Return(exampleUser, nil)
 		testUtil.MockDB.On("UpdateUser", mock.Anything, mock.Anything).
@@ -1055,7 +1551,318 @@ func TestUserUpdateHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodPatch, fmt.Sprintf("/user/%d", exampleUser.ID), strings.NewReader(exampleUserUpdateInput))
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+		ensureExpectationsWereMet(t, testUtil.Mock)
+	})
+
+	t.Run("with error queueing webhook deliveries", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.Mock.ExpectBegin()
+		testUtil.Mock.ExpectRollback()
+		testUtil.MockDB.On("GetUser", mock.Anything, exampleUser.ID).
+			Return(exampleUser, nil)
+		testUtil.MockDB.On("UpdateUser", mock.Anything, mock.Anything).
+			Return(buildTestTime(), nil)
+		testUtil.MockDB.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, UserUpdatedWebhookEvent).
+			Return([]models.Webhook{}, generateArbitraryError())
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPatch, fmt.Sprintf("/user/%d", exampleUser.ID), strings.NewReader(exampleUserUpdateInput))
+		assert.NoError(t, err)
+
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+		ensureExpectationsWereMet(t, testUtil.Mock)
+	})
+
+	t.Run("when updating another user as a regular user", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPatch, fmt.Sprintf("/user/%d", exampleUser.ID), strings.NewReader(exampleUserUpdateInput))
+		assert.NoError(t, err)
+		cookie, err := buildCookieForRequest(t, testUtil, true, false)
+		assert.NoError(t, err)
+		req.AddCookie(cookie)
+
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusForbidden)
+	})
+
+	t.Run("without authorization", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPatch, fmt.Sprintf("/user/%d", exampleUser.ID), strings.NewReader(exampleUserUpdateInput))
+		assert.NoError(t, err)
+
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusUnauthorized)
+	})
+}
+
+func TestUserListHandler(t *testing.T) {
+	exampleUsers := []models.User{
+		{ID: 1, Username: "frankzappa", Password: examplePasswordStr},
+		{ID: 2, Username: "captainbeefheart", Password: examplePasswordStr},
+	}
+
+	t.Run("optimal conditions", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetUserCount", mock.Anything, mock.Anything).
+			Return(uint64(len(exampleUsers)), nil)
+		testUtil.MockDB.On("GetUserList", mock.Anything, mock.Anything).
+			Return(exampleUsers, nil)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodGet, "/v1/users", nil)
+		assert.NoError(t, err)
+		req.AddCookie(buildCookieWithPermissionsForRequest(t, testUtil, "users:read"))
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusOK)
+		assert.NotContains(t, testUtil.Response.Body.String(), examplePasswordStr, "user list should not include passwords")
+	})
+
+	t.Run("without permission", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodGet, "/v1/users", nil)
+		assert.NoError(t, err)
+		req.AddCookie(buildCookieWithPermissionsForRequest(t, testUtil, "discounts:read"))
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusForbidden)
+	})
+
+	t.Run("with error retrieving user count", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetUserCount", mock.Anything, mock.Anything).
+			Return(uint64(0), generateArbitraryError())
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodGet, "/v1/users", nil)
+		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+	})
+
+	t.Run("with error retrieving user list", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetUserCount", mock.Anything, mock.Anything).
+			Return(uint64(len(exampleUsers)), nil)
+		testUtil.MockDB.On("GetUserList", mock.Anything, mock.Anything).
+			Return([]models.User{}, generateArbitraryError())
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodGet, "/v1/users", nil)
+		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+	})
+}
+
+func TestSingleUserHandler(t *testing.T) {
+	exampleUser := &models.User{ID: 1, Username: "frankzappa", Password: examplePasswordStr}
+	exampleUserIDStr := strconv.Itoa(int(exampleUser.ID))
+
+	t.Run("optimal conditions", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetUser", mock.Anything, exampleUser.ID).
+			Return(exampleUser, nil)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/user/%s", exampleUserIDStr), nil)
+		assert.NoError(t, err)
+		req.AddCookie(buildCookieWithPermissionsForRequest(t, testUtil, "users:read"))
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusOK)
+		assert.NotContains(t, testUtil.Response.Body.String(), examplePasswordStr, "user should not include their password")
+	})
+
+	t.Run("with nonexistent user", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetUser", mock.Anything, exampleUser.ID).
+			Return(exampleUser, sql.ErrNoRows)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/user/%s", exampleUserIDStr), nil)
+		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusNotFound)
+	})
+
+	t.Run("with error retrieving user", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetUser", mock.Anything, exampleUser.ID).
+			Return(exampleUser, generateArbitraryError())
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/user/%s", exampleUserIDStr), nil)
+		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+	})
+}
+
+func TestUserPasswordResetHandler(t *testing.T) {
+	exampleUser := &models.User{ID: 1, Username: "frankzappa"}
+	exampleUserIDStr := strconv.Itoa(int(exampleUser.ID))
+	examplePath := fmt.Sprintf("/v1/user/%s/password_reset", exampleUserIDStr)
+
+	t.Run("optimal conditions", func(t *testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetUser", mock.Anything, exampleUser.ID).
+			Return(exampleUser, nil)
+		testUtil.MockDB.On("GetPermissionsByUserID", mock.Anything, exampleUser.ID).
+			Return([]string{"users:read"}, nil)
+		testUtil.MockDB.On("PasswordResetTokenForUserIDExists", mock.Anything, exampleUser.ID).
+			Return(false, nil)
+		testUtil.MockDB.On("CreatePasswordResetToken", mock.Anything, mock.Anything).
+			Return(uint64(1), buildTestTime(), nil)
+		notifier := &recordingPasswordResetNotifier{}
+		config := buildServerConfigFromTestUtil(testUtil)
+		config.PasswordResetNotifier = notifier
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPost, examplePath, nil)
+		assert.NoError(t, err)
+		req.AddCookie(buildCookieWithPermissionsForRequest(t, testUtil, "users:read", "users:reset_password"))
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusAccepted)
+
+		require.NotNil(t, notifier.token)
+		assert.Equal(t, exampleUser.ID, notifier.user.ID)
+		assert.NotContains(t, testUtil.Response.Body.String(), notifier.token.Token)
+	})
+
+	t.Run("with admin user", func(t *testing.T) {
+		exampleAdmin := &models.User{ID: exampleUser.ID, Username: "frankzappa", IsAdmin: true}
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetUser", mock.Anything, exampleUser.ID).
+			Return(exampleAdmin, nil)
+		notifier := &recordingPasswordResetNotifier{}
+		config := buildServerConfigFromTestUtil(testUtil)
+		config.PasswordResetNotifier = notifier
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPost, examplePath, nil)
+		assert.NoError(t, err)
+		req.AddCookie(buildCookieWithPermissionsForRequest(t, testUtil, "users:read", "users:reset_password"))
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusForbidden)
+		assert.Nil(t, notifier.token)
+		testUtil.MockDB.AssertNotCalled(t, "CreatePasswordResetToken", mock.Anything, mock.Anything)
+	})
+
+	t.Run("with user holding permissions the requester lacks", func(t *testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetUser", mock.Anything, exampleUser.ID).
+			Return(exampleUser, nil)
+		testUtil.MockDB.On("GetPermissionsByUserID", mock.Anything, exampleUser.ID).
+			Return([]string{"users:read", "roles:write"}, nil)
+		notifier := &recordingPasswordResetNotifier{}
+		config := buildServerConfigFromTestUtil(testUtil)
+		config.PasswordResetNotifier = notifier
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPost, examplePath, nil)
+		assert.NoError(t, err)
+		req.AddCookie(buildCookieWithPermissionsForRequest(t, testUtil, "users:read", "users:reset_password"))
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusForbidden)
+		assert.Nil(t, notifier.token)
+		testUtil.MockDB.AssertNotCalled(t, "CreatePasswordResetToken", mock.Anything, mock.Anything)
+	})
+
+	t.Run("with error retrieving user permissions", func(t *testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetUser", mock.Anything, exampleUser.ID).
+			Return(exampleUser, nil)
+		testUtil.MockDB.On("GetPermissionsByUserID", mock.Anything, exampleUser.ID).
+			Return([]string{}, generateArbitraryError())
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPost, examplePath, nil)
+		assert.NoError(t, err)
+		req.AddCookie(buildCookieWithPermissionsForRequest(t, testUtil, "users:read", "users:reset_password"))
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+	})
+
+	t.Run("without permission", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPost, examplePath, nil)
+		assert.NoError(t, err)
+		req.AddCookie(buildCookieWithPermissionsForRequest(t, testUtil, "users:read"))
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusForbidden)
+	})
+
+	t.Run("with nonexistent user", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetUser", mock.Anything, exampleUser.ID).
+			Return(exampleUser, sql.ErrNoRows)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPost, examplePath, nil)
+		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusNotFound)
+	})
+
+	t.Run("with error retrieving user", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetUser", mock.Anything, exampleUser.ID).
+			Return(exampleUser, generateArbitraryError())
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPost, examplePath, nil)
+		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+	})
+
+	t.Run("with existing reset token", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetUser", mock.Anything, exampleUser.ID).
+			Return(exampleUser, nil)
+		testUtil.MockDB.On("GetPermissionsByUserID", mock.Anything, exampleUser.ID).
+			Return([]string{}, nil)
+		testUtil.MockDB.On("PasswordResetTokenForUserIDExists", mock.Anything, exampleUser.ID).
+			Return(true, nil)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPost, examplePath, nil)
+		assert.NoError(t, err)
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusBadRequest)
 	})
 }
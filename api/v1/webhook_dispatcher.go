@@ -0,0 +1,581 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dairycart/dairycart/models/v1"
+	"github.com/dairycart/dairycart/storage/v1/database"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultWebhookWorkers          = 4
+	defaultWebhookPollInterval     = time.Second
+	defaultWebhookTimeout          = 10 * time.Second
+	defaultWebhookMaxAttempts      = 10
+	defaultWebhookInitialBackoff   = 10 * time.Second
+	defaultWebhookMaxBackoff       = time.Hour
+	defaultWebhookBreakerThreshold = 5
+	defaultWebhookBreakerCooldown  = time.Minute
+	defaultWebhookDisableThreshold = 50
+	defaultWebhookMaxPerHost       = 2
+
+	maxWebhookErrorLength        = 1024
+	maxWebhookResponseBodyLength = 4096
+)
+
+// WebhookDeliverySettings controls how the webhook dispatcher delivers events
+type WebhookDeliverySettings struct {
+	// Workers is how many deliveries are attempted at once
+	Workers int
+	// PollInterval is how long the dispatcher waits between looking for deliveries, when it's caught up
+	PollInterval time.Duration
+	// Timeout is how long a webhook has to respond
+	Timeout time.Duration
+	// MaxAttempts is how many times a delivery is tried before it's declared dead
+	MaxAttempts int
+	// InitialBackoff is how long the dispatcher waits after the first failed attempt. It doubles
+	// with every attempt after that, up to MaxBackoff.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// BreakerThreshold is how many deliveries to a webhook can fail in a row before the rest are paused.
+	// Zero means they never are.
+	BreakerThreshold int
+	// BreakerCooldown is how long deliveries are paused for when the breaker first trips. Each failure
+	// after the pause doubles it, up to MaxBackoff.
+	BreakerCooldown time.Duration
+	// DisableThreshold is how many deliveries to a webhook can fail in a row before it's disabled.
+	// Zero means webhooks are never disabled.
+	DisableThreshold int
+	// MaxPerHost is how many requests can be in flight to the same host at once. Zero means there's no limit.
+	MaxPerHost int
+
+	// allowPrivateNetworks turns off the address checks, so tests can deliver to servers on localhost
+	allowPrivateNetworks bool
+}
+
+// hostLimiter caps how many requests can be in flight to each host at once
+type hostLimiter struct {
+	limit int
+
+	mu    sync.Mutex
+	slots map[string]*hostSlots
+}
+
+// hostSlots are the requests in flight to a host. Hosts are forgotten once nothing is using or waiting for their
+// slots, so the limiter doesn't keep an entry for every host that's ever been delivered to.
+type hostSlots struct {
+	inFlight chan struct{}
+	// users counts the requests that hold a slot or are waiting for one
+	users int
+}
+
+func newHostLimiter(limit int) *hostLimiter {
+	return &hostLimiter{limit: limit, slots: map[string]*hostSlots{}}
+}
+
+// acquire waits until there's room for another request to host, and returns the function that makes room again
+func (l *hostLimiter) acquire(host string) (release func()) {
+	if l.limit < 1 {
+		return func() {}
+	}
+
+	l.mu.Lock()
+	slots, ok := l.slots[host]
+	if !ok {
+		slots = &hostSlots{inFlight: make(chan struct{}, l.limit)}
+		l.slots[host] = slots
+	}
+	slots.users++
+	l.mu.Unlock()
+
+	slots.inFlight <- struct{}{}
+	return func() {
+		<-slots.inFlight
+
+		l.mu.Lock()
+		slots.users--
+		if slots.users == 0 {
+			delete(l.slots, host)
+		}
+		l.mu.Unlock()
+	}
+}
+
+func webhookContentTypeIsXML(contentType string) bool {
+	return strings.ToLower(contentType) == "application/xml"
+}
+
+// marshalWebhookPayload encodes an object the way a webhook asked for it
+func marshalWebhookPayload(contentType string, object interface{}) ([]byte, error) {
+	if webhookContentTypeIsXML(contentType) {
+		return xml.Marshal(object)
+	}
+	return json.Marshal(object)
+}
+
+// enqueueWebhookDeliveries records eventType in the event log, which feeds the event stream, and a delivery of
+// object to every webhook that's listening for eventType and whose filter it matches. It should be called with
+// the same transaction as the change that caused the event, so that the event is only sent if the change is
+// committed, and is never lost if it is.
+func enqueueWebhookDeliveries(tx database.Querier, client database.Storer, eventType string, object interface{}) error {
+	return enqueueWebhookChangeDeliveries(tx, client, eventType, nil, object)
+}
+
+// enqueueWebhookChangeDeliveries is enqueueWebhookDeliveries for updates. previous is the object as it was
+// before it was updated, and the fields that changed are sent along with what they used to be.
+func enqueueWebhookChangeDeliveries(tx database.Querier, client database.Storer, eventType string, previous, object interface{}) error {
+	event, err := newWebhookEvent(eventType, time.Now(), previous, object)
+	if err != nil {
+		return errors.Wrap(err, "error encoding webhook payload")
+	}
+
+	// the event log keeps the whole envelope, since stream clients don't choose fields
+	logged, err := json.Marshal(event.envelopeFor(&models.Webhook{}))
+	if err != nil {
+		return errors.Wrap(err, "error encoding event")
+	}
+	if _, _, err = client.CreateEvent(tx, &models.Event{EventType: eventType, Payload: string(logged)}); err != nil {
+		return errors.Wrap(err, "error creating event")
+	}
+
+	webhooks, err := client.GetWebhooksByEventType(tx, eventType)
+	if err != nil && err != sql.ErrNoRows {
+		return errors.Wrap(err, "error retrieving webhooks")
+	}
+
+	for _, wh := range webhooks {
+		if wh.Filter != "" {
+			filter, err := parseWebhookFilter(wh.Filter)
+			if err != nil {
+				// filters are validated when they're saved, so this shouldn't happen, and it shouldn't stop the change
+				log.Printf("skipping webhook %d, its filter is invalid: %v\n", wh.ID, err)
+				continue
+			}
+			if !filter.matches(event.filterDocument) {
+				continue
+			}
+		}
+
+		payload, err := marshalWebhookPayload(wh.ContentType, event.envelopeFor(&wh))
+		if err != nil {
+			return errors.Wrap(err, "error encoding webhook payload")
+		}
+
+		delivery := &models.WebhookDelivery{
+			WebhookID:   wh.ID,
+			EventType:   eventType,
+			ContentType: wh.ContentType,
+			Payload:     string(payload),
+		}
+		if _, _, err = client.CreateWebhookDelivery(tx, delivery); err != nil {
+			return errors.Wrap(err, "error creating webhook delivery")
+		}
+	}
+	return nil
+}
+
+// truncateForLog shortens s to at most max bytes, without splitting a character, so it can be stored in a text column
+func truncateForLog(s string, max int) string {
+	if len(s) > max {
+		s = s[:max]
+	}
+	return strings.ToValidUTF8(s, "")
+}
+
+// flattenWebhookHeaders turns a request's headers into the form they're logged in
+func flattenWebhookHeaders(header http.Header) models.WebhookHeaders {
+	out := models.WebhookHeaders{}
+	for k, v := range header {
+		out[k] = strings.Join(v, ", ")
+	}
+	return out
+}
+
+// webhookBackoff returns how long to wait before trying a delivery again, after it has failed attempts times
+func webhookBackoff(attempts int, initial, max time.Duration) time.Duration {
+	backoff := initial
+	for i := 1; i < attempts && backoff < max; i++ {
+		backoff *= 2
+	}
+	if backoff > max {
+		backoff = max
+	}
+	return backoff
+}
+
+// jitterBackoff spreads retries out over the second half of the backoff, so webhooks that failed together
+// don't all get retried together
+func jitterBackoff(backoff time.Duration) time.Duration {
+	if half := int64(backoff / 2); half > 0 {
+		return time.Duration(half + rand.Int63n(half))
+	}
+	return backoff
+}
+
+// WebhookDispatcher delivers the events in the webhook_deliveries table. Any number of dispatchers can run
+// against the same database, since deliveries are leased to one dispatcher at a time.
+type WebhookDispatcher struct {
+	db         *sql.DB
+	client     database.Storer
+	httpClient *http.Client
+	settings   WebhookDeliverySettings
+	hosts      *hostLimiter
+
+	jitter func(time.Duration) time.Duration
+	now    func() time.Time
+}
+
+func NewWebhookDispatcher(db *sql.DB, client database.Storer, settings WebhookDeliverySettings) *WebhookDispatcher {
+	if settings.Workers < 1 {
+		settings.Workers = 1
+	}
+	return &WebhookDispatcher{
+		db:         db,
+		client:     client,
+		httpClient: newWebhookHTTPClient(settings),
+		settings:   settings,
+		hosts:      newHostLimiter(settings.MaxPerHost),
+		jitter:     jitterBackoff,
+		now:        time.Now,
+	}
+}
+
+// newWebhookHTTPClient builds the client requests to webhooks are made with. Webhook URLs are user-supplied, so
+// like remote images, they can't be used to reach private or reserved addresses, redirects included.
+func newWebhookHTTPClient(settings WebhookDeliverySettings) *http.Client {
+	dialer := newRestrictedDialer(settings.Timeout, settings.allowPrivateNetworks)
+	return &http.Client{
+		Timeout: settings.Timeout,
+		Transport: &http.Transport{
+			// no proxy, since the proxy would be the one doing the connecting
+			DialContext:           dialer.DialContext,
+			TLSHandshakeTimeout:   settings.Timeout,
+			ResponseHeaderTimeout: settings.Timeout,
+			MaxIdleConns:          100,
+			IdleConnTimeout:       90 * time.Second,
+		},
+	}
+}
+
+// Run dispatches deliveries until stop is closed
+func (d *WebhookDispatcher) Run(stop <-chan struct{}) {
+	for {
+		claimed, err := d.dispatchPending()
+		if err != nil {
+			log.Printf("error dispatching webhook deliveries: %v\n", err)
+		}
+
+		// a full batch means there are probably more waiting
+		if claimed == d.settings.Workers {
+			select {
+			case <-stop:
+				return
+			default:
+				continue
+			}
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-time.After(d.settings.PollInterval):
+		}
+	}
+}
+
+// dispatchPending claims as many due deliveries as there are workers, and attempts them all at once
+func (d *WebhookDispatcher) dispatchPending() (int, error) {
+	// a delivery is only claimed while it's being attempted, so the lease only has to outlast the request
+	lease := 2*d.settings.Timeout + time.Minute
+	deliveries, err := d.client.ClaimPendingWebhookDeliveries(d.db, uint(d.settings.Workers), lease)
+	if err != nil && err != sql.ErrNoRows {
+		return 0, errors.Wrap(err, "error claiming webhook deliveries")
+	}
+
+	var wg sync.WaitGroup
+	for i := range deliveries {
+		wg.Add(1)
+		go func(delivery *models.WebhookDelivery) {
+			defer wg.Done()
+			if err := d.attempt(delivery); err != nil {
+				log.Printf("error recording attempt of webhook delivery %d: %v\n", delivery.ID, err)
+			}
+		}(&deliveries[i])
+	}
+	wg.Wait()
+
+	return len(deliveries), nil
+}
+
+// attempt tries a delivery once, and records how it went
+func (d *WebhookDispatcher) attempt(delivery *models.WebhookDelivery) error {
+	delivery.Attempts++
+	delivery.LockedUntil = nil
+
+	wh, err := d.client.GetWebhook(d.db, delivery.WebhookID)
+	if err == sql.ErrNoRows {
+		// nobody to deliver it to any more, so there's no point trying again
+		delivery.Status = models.WebhookDeliveryDead
+		delivery.LastError = "webhook was deleted"
+		_, err = d.client.UpdateWebhookDelivery(d.db, delivery)
+		return err
+	} else if err != nil {
+		d.recordFailure(delivery, 0, errors.Wrap(err, "error retrieving webhook"))
+		_, err = d.client.UpdateWebhookDelivery(d.db, delivery)
+		return err
+	} else if wh.Status != models.WebhookStatusActive {
+		// deliveries are only queued for active webhooks, but a webhook can be disabled while they wait
+		delivery.Status = models.WebhookDeliveryDead
+		delivery.LastError = fmt.Sprintf("webhook is %s", wh.Status)
+		_, err = d.client.UpdateWebhookDelivery(d.db, delivery)
+		return err
+	}
+
+	wel, err := d.post(wh, delivery, d.now())
+	err = checkWebhookResponse(wel, err)
+	statusCode := wel.StatusCode
+	d.logExecution(wel)
+
+	if err != nil {
+		d.recordFailure(delivery, statusCode, err)
+		d.recordWebhookFailure(wh)
+	} else {
+		delivery.Status = models.WebhookDeliveryDelivered
+		delivery.LastStatusCode = statusCode
+		delivery.LastError = ""
+		delivery.DeliveredOn = &models.Dairytime{Time: d.now()}
+		if wh.ConsecutiveFailures > 0 {
+			if err = d.client.ResetWebhookFailures(d.db, wh.ID); err != nil {
+				log.Printf("error resetting failures of webhook %d: %v\n", wh.ID, err)
+			}
+		}
+	}
+
+	_, err = d.client.UpdateWebhookDelivery(d.db, delivery)
+	return err
+}
+
+// recordWebhookFailure counts a failed delivery against its webhook. Once BreakerThreshold deliveries in a row
+// have failed, the webhook's other deliveries are paused for a while, so a receiver that's down isn't sent every
+// one of them just to fail. Once DisableThreshold deliveries in a row have failed, the webhook is disabled.
+func (d *WebhookDispatcher) recordWebhookFailure(wh *models.Webhook) {
+	failures, err := d.client.IncrementWebhookFailures(d.db, wh.ID)
+	if err != nil {
+		log.Printf("error recording failure of webhook %d: %v\n", wh.ID, err)
+		return
+	}
+
+	// the count is checked for equality so only one failure disables the webhook, but a webhook that was already
+	// over the threshold (because disabling it failed last time) gets another try
+	threshold := d.settings.DisableThreshold
+	if threshold > 0 && (failures == threshold || wh.ConsecutiveFailures >= threshold) {
+		if err = d.disableWebhook(wh, failures); err != nil {
+			log.Printf("error disabling webhook %d: %v\n", wh.ID, err)
+		}
+		return
+	}
+
+	if d.settings.BreakerThreshold > 0 && failures >= d.settings.BreakerThreshold {
+		cooldown := webhookBackoff(failures-d.settings.BreakerThreshold+1, d.settings.BreakerCooldown, d.settings.MaxBackoff)
+		if err = d.client.PauseWebhook(d.db, wh.ID, d.now().Add(cooldown)); err != nil {
+			log.Printf("error pausing webhook %d: %v\n", wh.ID, err)
+		}
+	}
+}
+
+// webhookEventObject is a webhook the way it's sent in webhook events. It's its own type, rather than
+// models.Webhook, so that a webhook's secrets can't end up in a payload no matter how it's encoded.
+type webhookEventObject struct {
+	ID                  uint64            `json:"id"`
+	URL                 string            `json:"url"`
+	EventTypes          []string          `json:"event_types"`
+	ContentType         string            `json:"content_type"`
+	Fields              []string          `json:"fields"`
+	Filter              string            `json:"filter"`
+	Status              string            `json:"status"`
+	ConsecutiveFailures int               `json:"consecutive_failures"`
+	PausedUntil         *models.Dairytime `json:"paused_until"`
+	SecretRotatedOn     *models.Dairytime `json:"secret_rotated_on"`
+	CreatedOn           time.Time         `json:"created_on"`
+	UpdatedOn           *models.Dairytime `json:"updated_on"`
+	ArchivedOn          *models.Dairytime `json:"archived_on"`
+}
+
+func newWebhookEventObject(wh *models.Webhook) *webhookEventObject {
+	return &webhookEventObject{
+		ID:                  wh.ID,
+		URL:                 wh.URL,
+		EventTypes:          wh.EventTypes,
+		ContentType:         wh.ContentType,
+		Fields:              wh.Fields,
+		Filter:              wh.Filter,
+		Status:              wh.Status,
+		ConsecutiveFailures: wh.ConsecutiveFailures,
+		PausedUntil:         wh.PausedUntil,
+		SecretRotatedOn:     wh.SecretRotatedOn,
+		CreatedOn:           wh.CreatedOn,
+		UpdatedOn:           wh.UpdatedOn,
+		ArchivedOn:          wh.ArchivedOn,
+	}
+}
+
+// disableWebhook stops events being sent to a webhook, and lets anyone listening for it know
+func (d *WebhookDispatcher) disableWebhook(wh *models.Webhook, failures int) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return errors.Wrap(err, "error creating transaction")
+	}
+
+	updatedOn, err := d.client.UpdateWebhookStatus(tx, wh.ID, models.WebhookStatusDisabled)
+	if err != nil {
+		tx.Rollback()
+		return errors.Wrap(err, "error updating webhook status")
+	}
+	previous := newWebhookEventObject(wh)
+	wh.Status = models.WebhookStatusDisabled
+	wh.ConsecutiveFailures = failures
+	wh.UpdatedOn = &models.Dairytime{Time: updatedOn}
+
+	if err = enqueueWebhookChangeDeliveries(tx, d.client, WebhookDisabledWebhookEvent, previous, newWebhookEventObject(wh)); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "error committing transaction")
+	}
+	log.Printf("disabled webhook %d after %d failed deliveries in a row\n", wh.ID, failures)
+	return nil
+}
+
+// Send makes a one-off request to a webhook, outside of the delivery queue, and returns the log of how it went.
+// Since these requests aren't deliveries, their delivery ID is always 0, and receivers shouldn't treat them as
+// duplicates of each other.
+func (d *WebhookDispatcher) Send(wh *models.Webhook, eventType string, object interface{}) (*models.WebhookExecutionLog, error) {
+	event, err := newWebhookEvent(eventType, d.now(), nil, object)
+	if err != nil {
+		return nil, errors.Wrap(err, "error encoding webhook payload")
+	}
+
+	payload, err := marshalWebhookPayload(wh.ContentType, event.envelopeFor(wh))
+	if err != nil {
+		return nil, errors.Wrap(err, "error encoding webhook payload")
+	}
+
+	delivery := &models.WebhookDelivery{
+		WebhookID:   wh.ID,
+		EventType:   eventType,
+		ContentType: wh.ContentType,
+		Payload:     string(payload),
+	}
+	wel, err := d.post(wh, delivery, d.now())
+	checkWebhookResponse(wel, err)
+	d.logExecution(wel)
+
+	return wel, nil
+}
+
+// checkWebhookResponse records whether a request to a webhook succeeded on its log, and returns why if it didn't
+func checkWebhookResponse(wel *models.WebhookExecutionLog, err error) error {
+	if err == nil && (wel.StatusCode < http.StatusOK || wel.StatusCode >= http.StatusMultipleChoices) {
+		err = fmt.Errorf("webhook responded with status %d", wel.StatusCode)
+	}
+
+	wel.Succeeded = err == nil
+	if err != nil {
+		wel.Error = truncateForLog(err.Error(), maxWebhookErrorLength)
+	}
+	return err
+}
+
+func (d *WebhookDispatcher) logExecution(wel *models.WebhookExecutionLog) {
+	if _, _, err := d.client.CreateWebhookExecutionLog(d.db, wel); err != nil {
+		log.Printf("error encountered logging webhook execution: %v", err)
+	}
+}
+
+// recordFailure schedules the next attempt of a delivery, or gives up on it if it's had all its attempts
+func (d *WebhookDispatcher) recordFailure(delivery *models.WebhookDelivery, statusCode int, err error) {
+	delivery.LastStatusCode = statusCode
+	delivery.LastError = truncateForLog(err.Error(), maxWebhookErrorLength)
+
+	if delivery.Attempts >= d.settings.MaxAttempts {
+		delivery.Status = models.WebhookDeliveryDead
+		log.Printf("giving up on webhook delivery %d after %d attempts: %v\n", delivery.ID, delivery.Attempts, err)
+		return
+	}
+	backoff := webhookBackoff(delivery.Attempts, d.settings.InitialBackoff, d.settings.MaxBackoff)
+	delivery.NextAttemptOn = d.now().Add(d.jitter(backoff))
+}
+
+// post sends a delivery to its webhook, signed with the webhook's secrets as of the given time. The returned
+// log describes the request and its response, and is filled in as far as the attempt got even if it fails.
+func (d *WebhookDispatcher) post(wh *models.Webhook, delivery *models.WebhookDelivery, signedOn time.Time) (*models.WebhookExecutionLog, error) {
+	contentType := delivery.ContentType
+	if contentType == "" {
+		contentType = "application/json"
+	}
+
+	wel := &models.WebhookExecutionLog{
+		WebhookID:      wh.ID,
+		EventType:      delivery.EventType,
+		Attempt:        delivery.Attempts,
+		RequestURL:     wh.URL,
+		RequestHeaders: models.WebhookHeaders{},
+		RequestBody:    delivery.Payload,
+		ExecutedOn:     signedOn,
+	}
+	if delivery.ID != 0 {
+		deliveryID := delivery.ID
+		wel.DeliveryID = &deliveryID
+	}
+
+	req, err := http.NewRequest(http.MethodPost, wh.URL, bytes.NewBufferString(delivery.Payload))
+	if err != nil {
+		return wel, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set(models.WebhookEventHeader, delivery.EventType)
+	req.Header.Set(models.WebhookDeliveryHeader, fmt.Sprintf("%d", delivery.ID))
+	req.Header.Set(models.WebhookTimestampHeader, fmt.Sprintf("%d", signedOn.Unix()))
+	// webhooks created before secrets existed have nothing to sign with until they're rotated
+	if secrets := webhookSigningSecrets(wh, signedOn); len(secrets) > 0 {
+		signature := models.BuildWebhookSignatureHeader(signedOn, delivery.ID, []byte(delivery.Payload), secrets...)
+		req.Header.Set(models.WebhookSignatureHeader, signature)
+	}
+
+	wel.RequestHeaders = flattenWebhookHeaders(req.Header)
+
+	release := d.hosts.acquire(req.URL.Host)
+	defer release()
+
+	sentOn := d.now()
+	res, err := d.httpClient.Do(req)
+	wel.LatencyMS = int64(d.now().Sub(sentOn) / time.Millisecond)
+	if err != nil {
+		return wel, err
+	}
+	defer res.Body.Close()
+	wel.StatusCode = res.StatusCode
+
+	body, _ := ioutil.ReadAll(io.LimitReader(res.Body, maxWebhookResponseBodyLength))
+	wel.ResponseBody = truncateForLog(string(body), maxWebhookResponseBodyLength)
+	// reading the rest of the body lets the connection be reused
+	io.Copy(ioutil.Discard, io.LimitReader(res.Body, 1<<16))
+
+	return wel, nil
+}
@@ -0,0 +1,732 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dairycart/dairycart/models/v1"
+	"github.com/dairycart/dairycart/storage/v1/database/mock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type testBreakableStruct struct {
+	Thing json.Number `json:"thing"`
+}
+
+func buildTestWebhookDispatcher(t *testing.T, client *dairymock.MockDB) *WebhookDispatcher {
+	d := NewWebhookDispatcher(setupTestVariablesWithMock(t).PlainDB, client, WebhookDeliverySettings{
+		Workers:        2,
+		PollInterval:   time.Millisecond,
+		Timeout:        time.Second,
+		MaxAttempts:    3,
+		InitialBackoff: time.Minute,
+		MaxBackoff:     time.Hour,
+		// test servers live on localhost
+		allowPrivateNetworks: true,
+	})
+	d.jitter = func(d time.Duration) time.Duration { return d }
+	d.now = buildTestTime
+	return d
+}
+
+func TestEnqueueWebhookDeliveries(t *testing.T) {
+	exampleWebhooks := []models.Webhook{
+		{ID: 1, URL: "https://example.com/json", ContentType: "application/json"},
+		{ID: 2, URL: "https://example.com/xml", ContentType: "application/xml"},
+	}
+
+	t.Run("optimal conditions", func(*testing.T) {
+		client := &dairymock.MockDB{}
+		client.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		client.On("GetWebhooksByEventType", mock.Anything, ProductUpdatedWebhookEvent).Return(exampleWebhooks, nil)
+		client.On("CreateWebhookDelivery", mock.Anything, mock.Anything).Return(uint64(10), buildTestTime(), nil)
+
+		type testProduct struct {
+			ID   int    `json:"id"`
+			Name string `json:"name"`
+		}
+		err := enqueueWebhookDeliveries(nil, client, ProductUpdatedWebhookEvent, testProduct{Name: "skateboard"})
+		assert.NoError(t, err)
+		client.AssertNumberOfCalls(t, "CreateWebhookDelivery", 2)
+
+		event := client.Calls[0].Arguments.Get(1).(*models.Event)
+		assert.Equal(t, ProductUpdatedWebhookEvent, event.EventType)
+		logged := map[string]interface{}{}
+		require.NoError(t, json.Unmarshal([]byte(event.Payload), &logged))
+		assert.Equal(t, map[string]interface{}{"id": float64(0), "name": "skateboard"}, logged["data"])
+
+		jsonDelivery := client.Calls[2].Arguments.Get(1).(*models.WebhookDelivery)
+		assert.Equal(t, uint64(1), jsonDelivery.WebhookID)
+		assert.Equal(t, ProductUpdatedWebhookEvent, jsonDelivery.EventType)
+		assert.Equal(t, "application/json", jsonDelivery.ContentType)
+		envelope := map[string]interface{}{}
+		require.NoError(t, json.Unmarshal([]byte(jsonDelivery.Payload), &envelope))
+		assert.Equal(t, float64(models.WebhookEventEnvelopeVersion), envelope["version"])
+		assert.Equal(t, ProductUpdatedWebhookEvent, envelope["event"])
+		assert.NotEmpty(t, envelope["occurred_at"])
+		assert.Equal(t, map[string]interface{}{"id": float64(0), "name": "skateboard"}, envelope["data"])
+		assert.Nil(t, envelope["previous"])
+		assert.Equal(t, []interface{}{}, envelope["changed_fields"])
+
+		xmlDelivery := client.Calls[3].Arguments.Get(1).(*models.WebhookDelivery)
+		assert.Equal(t, uint64(2), xmlDelivery.WebhookID)
+		assert.Equal(t, "application/xml", xmlDelivery.ContentType)
+		assert.True(t, strings.HasPrefix(xmlDelivery.Payload, `<event version="1"><type>product.updated</type>`), xmlDelivery.Payload)
+		assert.Contains(t, xmlDelivery.Payload, `<data><ID>0</ID><Name>skateboard</Name></data>`)
+	})
+
+	t.Run("with changes", func(*testing.T) {
+		client := &dairymock.MockDB{}
+		client.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		client.On("GetWebhooksByEventType", mock.Anything, ProductUpdatedWebhookEvent).Return(exampleWebhooks, nil)
+		client.On("CreateWebhookDelivery", mock.Anything, mock.Anything).Return(uint64(10), buildTestTime(), nil)
+
+		previous := &models.Product{SKU: "skateboard", Price: 10, Quantity: 3}
+		updated := &models.Product{SKU: "skateboard", Price: 12.5, Quantity: 3, UpdatedOn: &models.Dairytime{Time: buildTestTime()}}
+		err := enqueueWebhookChangeDeliveries(nil, client, ProductUpdatedWebhookEvent, previous, updated)
+		assert.NoError(t, err)
+
+		jsonDelivery := client.Calls[2].Arguments.Get(1).(*models.WebhookDelivery)
+		envelope := map[string]interface{}{}
+		require.NoError(t, json.Unmarshal([]byte(jsonDelivery.Payload), &envelope))
+		// updated_on always changes, so it isn't reported
+		assert.Equal(t, map[string]interface{}{"price": float64(10)}, envelope["previous"])
+		assert.Equal(t, []interface{}{"price"}, envelope["changed_fields"])
+
+		xmlDelivery := client.Calls[3].Arguments.Get(1).(*models.WebhookDelivery)
+		assert.Contains(t, xmlDelivery.Payload, `<previous><price>10</price></previous><changed_fields><field>price</field></changed_fields>`)
+	})
+
+	t.Run("with filters and fields", func(*testing.T) {
+		filteredWebhooks := []models.Webhook{
+			{ID: 1, ContentType: "application/json", Filter: `brand in ("Acme", "Globex") and quantity < 10 and previous.quantity >= 10`, Fields: []string{"sku", "quantity"}},
+			{ID: 2, ContentType: "application/json", Filter: `brand == "Initech"`},
+			{ID: 3, ContentType: "application/json", Filter: `brand ==`},
+			{ID: 4, ContentType: "application/xml", Filter: `price > 100`},
+			{ID: 5, ContentType: "application/json", Fields: []string{"sku"}},
+		}
+
+		client := &dairymock.MockDB{}
+		client.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		client.On("GetWebhooksByEventType", mock.Anything, ProductUpdatedWebhookEvent).Return(filteredWebhooks, nil)
+		client.On("CreateWebhookDelivery", mock.Anything, mock.Anything).Return(uint64(10), buildTestTime(), nil)
+
+		previous := &models.Product{SKU: "skateboard", Brand: "Acme", Quantity: 12, Price: 12.5}
+		updated := &models.Product{SKU: "skateboard", Brand: "Acme", Quantity: 8, Price: 12.5}
+		err := enqueueWebhookChangeDeliveries(nil, client, ProductUpdatedWebhookEvent, previous, updated)
+		assert.NoError(t, err)
+		// webhooks whose filters don't match, or can't be parsed, aren't sent anything
+		client.AssertNumberOfCalls(t, "CreateWebhookDelivery", 2)
+
+		delivery := client.Calls[2].Arguments.Get(1).(*models.WebhookDelivery)
+		assert.Equal(t, uint64(1), delivery.WebhookID)
+		envelope := map[string]interface{}{}
+		require.NoError(t, json.Unmarshal([]byte(delivery.Payload), &envelope))
+		assert.Equal(t, map[string]interface{}{"sku": "skateboard", "quantity": float64(8)}, envelope["data"])
+		assert.Equal(t, map[string]interface{}{"quantity": float64(12)}, envelope["previous"])
+		assert.Equal(t, []interface{}{"quantity"}, envelope["changed_fields"])
+
+		// changes to fields a webhook didn't ask for aren't mentioned
+		delivery = client.Calls[3].Arguments.Get(1).(*models.WebhookDelivery)
+		assert.Equal(t, uint64(5), delivery.WebhookID)
+		envelope = map[string]interface{}{}
+		require.NoError(t, json.Unmarshal([]byte(delivery.Payload), &envelope))
+		assert.Equal(t, map[string]interface{}{}, envelope["previous"])
+		assert.Equal(t, []interface{}{}, envelope["changed_fields"])
+	})
+
+	t.Run("with no webhooks", func(*testing.T) {
+		client := &dairymock.MockDB{}
+		client.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		client.On("GetWebhooksByEventType", mock.Anything, ProductUpdatedWebhookEvent).Return([]models.Webhook{}, sql.ErrNoRows)
+
+		assert.NoError(t, enqueueWebhookDeliveries(nil, client, ProductUpdatedWebhookEvent, &models.Product{}))
+		client.AssertNotCalled(t, "CreateWebhookDelivery", mock.Anything, mock.Anything)
+		// events are logged for the event stream whether anything's listening or not
+		client.AssertNumberOfCalls(t, "CreateEvent", 1)
+	})
+
+	t.Run("with error creating event", func(*testing.T) {
+		client := &dairymock.MockDB{}
+		client.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(0), buildTestTime(), generateArbitraryError())
+
+		assert.Error(t, enqueueWebhookDeliveries(nil, client, ProductUpdatedWebhookEvent, &models.Product{}))
+		client.AssertNotCalled(t, "GetWebhooksByEventType", mock.Anything, mock.Anything)
+	})
+
+	t.Run("with error retrieving webhooks", func(*testing.T) {
+		client := &dairymock.MockDB{}
+		client.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		client.On("GetWebhooksByEventType", mock.Anything, ProductUpdatedWebhookEvent).Return([]models.Webhook{}, generateArbitraryError())
+
+		assert.Error(t, enqueueWebhookDeliveries(nil, client, ProductUpdatedWebhookEvent, &models.Product{}))
+	})
+
+	t.Run("with invalid object", func(*testing.T) {
+		client := &dairymock.MockDB{}
+		client.On("GetWebhooksByEventType", mock.Anything, ProductUpdatedWebhookEvent).Return(exampleWebhooks, nil)
+
+		assert.Error(t, enqueueWebhookDeliveries(nil, client, ProductUpdatedWebhookEvent, &testBreakableStruct{Thing: "broken"}))
+	})
+
+	t.Run("with invalid object and a filter", func(*testing.T) {
+		client := &dairymock.MockDB{}
+		client.On("GetWebhooksByEventType", mock.Anything, ProductUpdatedWebhookEvent).Return([]models.Webhook{{ID: 1, Filter: "thing > 1"}}, nil)
+
+		assert.Error(t, enqueueWebhookDeliveries(nil, client, ProductUpdatedWebhookEvent, &testBreakableStruct{Thing: "broken"}))
+	})
+
+	t.Run("with error creating delivery", func(*testing.T) {
+		client := &dairymock.MockDB{}
+		client.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		client.On("GetWebhooksByEventType", mock.Anything, ProductUpdatedWebhookEvent).Return(exampleWebhooks, nil)
+		client.On("CreateWebhookDelivery", mock.Anything, mock.Anything).Return(uint64(0), buildTestTime(), generateArbitraryError())
+
+		assert.Error(t, enqueueWebhookDeliveries(nil, client, ProductUpdatedWebhookEvent, &models.Product{}))
+	})
+}
+
+func TestWebhookBackoff(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, 10*time.Second, webhookBackoff(1, 10*time.Second, time.Hour))
+	assert.Equal(t, 20*time.Second, webhookBackoff(2, 10*time.Second, time.Hour))
+	assert.Equal(t, 80*time.Second, webhookBackoff(4, 10*time.Second, time.Hour))
+	assert.Equal(t, time.Hour, webhookBackoff(20, 10*time.Second, time.Hour))
+	// lots of attempts shouldn't overflow
+	assert.Equal(t, time.Hour, webhookBackoff(1000, 10*time.Second, time.Hour))
+
+	for i := 0; i < 100; i++ {
+		jittered := jitterBackoff(time.Minute)
+		assert.True(t, jittered >= 30*time.Second && jittered < time.Minute, "jittered backoff %v should be in the second half of the backoff", jittered)
+	}
+}
+
+func TestWebhookDispatcherAttempt(t *testing.T) {
+	t.Run("optimal conditions", func(*testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+			body, err := ioutil.ReadAll(req.Body)
+			require.NoError(t, err)
+			assert.Equal(t, `{"id":1}`, string(body))
+			assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+			assert.Equal(t, ProductUpdatedWebhookEvent, req.Header.Get("X-Dairycart-Event"))
+			assert.Equal(t, "10", req.Header.Get("X-Dairycart-Delivery"))
+			assert.Equal(t, fmt.Sprintf("%d", buildTestTime().Unix()), req.Header.Get("X-Dairycart-Timestamp"))
+			expectedSignature := models.ComputeWebhookSignature("secret", buildTestTime(), 10, body)
+			assert.Equal(t, fmt.Sprintf("v1=%s", expectedSignature), req.Header.Get("X-Dairycart-Signature"))
+			res.WriteHeader(http.StatusNoContent)
+		}))
+		defer ts.Close()
+
+		client := &dairymock.MockDB{}
+		client.On("GetWebhook", mock.Anything, uint64(1)).Return(&models.Webhook{ID: 1, Status: models.WebhookStatusActive, URL: ts.URL, Secret: "secret"}, nil)
+		client.On("CreateWebhookExecutionLog", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		client.On("UpdateWebhookDelivery", mock.Anything, mock.Anything).Return(buildTestTime(), nil)
+
+		d := buildTestWebhookDispatcher(t, client)
+		delivery := &models.WebhookDelivery{
+			ID:          10,
+			WebhookID:   1,
+			EventType:   ProductUpdatedWebhookEvent,
+			Payload:     `{"id":1}`,
+			Status:      models.WebhookDeliveryPending,
+			LockedUntil: &models.Dairytime{Time: buildTestTime()},
+		}
+		require.NoError(t, d.attempt(delivery))
+
+		assert.Equal(t, models.WebhookDeliveryDelivered, delivery.Status)
+		assert.Equal(t, 1, delivery.Attempts)
+		assert.Equal(t, http.StatusNoContent, delivery.LastStatusCode)
+		assert.Nil(t, delivery.LockedUntil)
+		require.NotNil(t, delivery.DeliveredOn)
+		client.AssertCalled(t, "UpdateWebhookDelivery", mock.Anything, delivery)
+
+		wel := client.Calls[1].Arguments.Get(1).(*models.WebhookExecutionLog)
+		assert.Equal(t, uint64(1), wel.WebhookID)
+		require.NotNil(t, wel.DeliveryID)
+		assert.Equal(t, uint64(10), *wel.DeliveryID)
+		assert.Equal(t, ProductUpdatedWebhookEvent, wel.EventType)
+		assert.Equal(t, 1, wel.Attempt)
+		assert.Equal(t, ts.URL, wel.RequestURL)
+		assert.Equal(t, "application/json", wel.RequestHeaders["Content-Type"])
+		assert.Equal(t, "10", wel.RequestHeaders["X-Dairycart-Delivery"])
+		assert.Equal(t, `{"id":1}`, wel.RequestBody)
+		assert.Equal(t, http.StatusNoContent, wel.StatusCode)
+		assert.Empty(t, wel.Error)
+		assert.True(t, wel.Succeeded)
+		assert.Equal(t, buildTestTime(), wel.ExecutedOn)
+	})
+
+	t.Run("with failure", func(*testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+			res.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprint(res, strings.Repeat("down for maintenance ", 1000))
+		}))
+		defer ts.Close()
+
+		client := &dairymock.MockDB{}
+		client.On("GetWebhook", mock.Anything, uint64(1)).Return(&models.Webhook{ID: 1, Status: models.WebhookStatusActive, URL: ts.URL}, nil)
+		client.On("CreateWebhookExecutionLog", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		client.On("IncrementWebhookFailures", mock.Anything, uint64(1)).Return(1, nil)
+		client.On("UpdateWebhookDelivery", mock.Anything, mock.Anything).Return(buildTestTime(), nil)
+
+		d := buildTestWebhookDispatcher(t, client)
+		delivery := &models.WebhookDelivery{ID: 10, WebhookID: 1, Status: models.WebhookDeliveryPending, Attempts: 1}
+		require.NoError(t, d.attempt(delivery))
+
+		assert.Equal(t, models.WebhookDeliveryPending, delivery.Status)
+		assert.Equal(t, 2, delivery.Attempts)
+		assert.Equal(t, http.StatusServiceUnavailable, delivery.LastStatusCode)
+		assert.NotEmpty(t, delivery.LastError)
+		// the second failure waits twice as long as the first
+		assert.Equal(t, buildTestTime().Add(2*time.Minute), delivery.NextAttemptOn)
+		assert.Nil(t, delivery.DeliveredOn)
+
+		wel := client.Calls[1].Arguments.Get(1).(*models.WebhookExecutionLog)
+		assert.Equal(t, 2, wel.Attempt)
+		assert.Equal(t, http.StatusServiceUnavailable, wel.StatusCode)
+		assert.Len(t, wel.ResponseBody, maxWebhookResponseBodyLength, "response body should be truncated")
+		assert.NotEmpty(t, wel.Error)
+		assert.False(t, wel.Succeeded)
+	})
+
+	t.Run("with final failure", func(*testing.T) {
+		client := &dairymock.MockDB{}
+		client.On("GetWebhook", mock.Anything, uint64(1)).Return(&models.Webhook{ID: 1, Status: models.WebhookStatusActive, URL: ":"}, nil)
+		client.On("CreateWebhookExecutionLog", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		client.On("IncrementWebhookFailures", mock.Anything, uint64(1)).Return(1, nil)
+		client.On("UpdateWebhookDelivery", mock.Anything, mock.Anything).Return(buildTestTime(), nil)
+
+		d := buildTestWebhookDispatcher(t, client)
+		delivery := &models.WebhookDelivery{ID: 10, WebhookID: 1, Status: models.WebhookDeliveryPending, Attempts: 2}
+		require.NoError(t, d.attempt(delivery))
+
+		assert.Equal(t, models.WebhookDeliveryDead, delivery.Status)
+		assert.Equal(t, 3, delivery.Attempts)
+		assert.NotEmpty(t, delivery.LastError)
+
+		// requests that never got a response are still logged
+		wel := client.Calls[1].Arguments.Get(1).(*models.WebhookExecutionLog)
+		assert.Equal(t, 0, wel.StatusCode)
+		assert.Equal(t, delivery.LastError, wel.Error)
+		assert.False(t, wel.Succeeded)
+	})
+
+	t.Run("with success after failures", func(*testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {}))
+		defer ts.Close()
+
+		client := &dairymock.MockDB{}
+		client.On("GetWebhook", mock.Anything, uint64(1)).Return(&models.Webhook{ID: 1, Status: models.WebhookStatusActive, URL: ts.URL, ConsecutiveFailures: 3}, nil)
+		client.On("CreateWebhookExecutionLog", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		client.On("ResetWebhookFailures", mock.Anything, uint64(1)).Return(nil)
+		client.On("UpdateWebhookDelivery", mock.Anything, mock.Anything).Return(buildTestTime(), nil)
+
+		d := buildTestWebhookDispatcher(t, client)
+		delivery := &models.WebhookDelivery{ID: 10, WebhookID: 1, Status: models.WebhookDeliveryPending}
+		require.NoError(t, d.attempt(delivery))
+
+		assert.Equal(t, models.WebhookDeliveryDelivered, delivery.Status)
+		client.AssertCalled(t, "ResetWebhookFailures", mock.Anything, uint64(1))
+	})
+
+	t.Run("with failure tripping the breaker", func(*testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+			res.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer ts.Close()
+
+		client := &dairymock.MockDB{}
+		client.On("GetWebhook", mock.Anything, uint64(1)).Return(&models.Webhook{ID: 1, Status: models.WebhookStatusActive, URL: ts.URL, ConsecutiveFailures: 5}, nil)
+		client.On("CreateWebhookExecutionLog", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		client.On("IncrementWebhookFailures", mock.Anything, uint64(1)).Return(6, nil)
+		client.On("PauseWebhook", mock.Anything, uint64(1), mock.Anything).Return(nil)
+		client.On("UpdateWebhookDelivery", mock.Anything, mock.Anything).Return(buildTestTime(), nil)
+
+		d := buildTestWebhookDispatcher(t, client)
+		d.settings.BreakerThreshold = 5
+		d.settings.BreakerCooldown = time.Minute
+		d.settings.DisableThreshold = 50
+		delivery := &models.WebhookDelivery{ID: 10, WebhookID: 1, Status: models.WebhookDeliveryPending}
+		require.NoError(t, d.attempt(delivery))
+
+		// the second failure past the threshold pauses twice as long as the first
+		client.AssertCalled(t, "PauseWebhook", mock.Anything, uint64(1), buildTestTime().Add(2*time.Minute))
+	})
+
+	t.Run("with failure below the breaker threshold", func(*testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+			res.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer ts.Close()
+
+		client := &dairymock.MockDB{}
+		client.On("GetWebhook", mock.Anything, uint64(1)).Return(&models.Webhook{ID: 1, Status: models.WebhookStatusActive, URL: ts.URL}, nil)
+		client.On("CreateWebhookExecutionLog", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		client.On("IncrementWebhookFailures", mock.Anything, uint64(1)).Return(1, nil)
+		client.On("UpdateWebhookDelivery", mock.Anything, mock.Anything).Return(buildTestTime(), nil)
+
+		d := buildTestWebhookDispatcher(t, client)
+		d.settings.BreakerThreshold = 5
+		require.NoError(t, d.attempt(&models.WebhookDelivery{ID: 10, WebhookID: 1, Status: models.WebhookDeliveryPending}))
+
+		client.AssertNotCalled(t, "PauseWebhook", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("with failure disabling the webhook", func(*testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+			res.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer ts.Close()
+
+		testUtil := setupTestVariablesWithMock(t)
+		client := testUtil.MockDB
+		client.On("GetWebhook", mock.Anything, uint64(1)).Return(&models.Webhook{ID: 1, Status: models.WebhookStatusActive, URL: ts.URL, ConsecutiveFailures: 2}, nil)
+		client.On("CreateWebhookExecutionLog", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		client.On("IncrementWebhookFailures", mock.Anything, uint64(1)).Return(3, nil)
+		client.On("UpdateWebhookStatus", mock.Anything, uint64(1), models.WebhookStatusDisabled).Return(buildTestTime(), nil)
+		client.On("CreateEvent", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		client.On("GetWebhooksByEventType", mock.Anything, WebhookDisabledWebhookEvent).Return([]models.Webhook{{ID: 2}}, nil)
+		client.On("CreateWebhookDelivery", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		client.On("UpdateWebhookDelivery", mock.Anything, mock.Anything).Return(buildTestTime(), nil)
+		testUtil.Mock.ExpectBegin()
+		testUtil.Mock.ExpectCommit()
+
+		d := buildTestWebhookDispatcher(t, client)
+		d.db = testUtil.PlainDB
+		d.settings.BreakerThreshold = 2
+		d.settings.DisableThreshold = 3
+		delivery := &models.WebhookDelivery{ID: 10, WebhookID: 1, Status: models.WebhookDeliveryPending}
+		require.NoError(t, d.attempt(delivery))
+
+		disabled := mockCallsTo(&client.Mock, "UpdateWebhookStatus")
+		require.Len(t, disabled, 1)
+		assert.Equal(t, uint64(1), disabled[0].Get(1))
+		assert.Equal(t, models.WebhookStatusDisabled, disabled[0].Get(2))
+		assert.Empty(t, mockCallsTo(&client.Mock, "PauseWebhook"))
+		notice := client.Calls[6].Arguments.Get(1).(*models.WebhookDelivery)
+		assert.Equal(t, uint64(2), notice.WebhookID)
+		assert.Contains(t, notice.Payload, `"status":"disabled"`)
+		ensureExpectationsWereMet(t, testUtil.Mock)
+	})
+
+	t.Run("with error disabling the webhook", func(*testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+			res.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer ts.Close()
+
+		testUtil := setupTestVariablesWithMock(t)
+		client := testUtil.MockDB
+		client.On("GetWebhook", mock.Anything, uint64(1)).Return(&models.Webhook{ID: 1, Status: models.WebhookStatusActive, URL: ts.URL, ConsecutiveFailures: 2}, nil)
+		client.On("CreateWebhookExecutionLog", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		client.On("IncrementWebhookFailures", mock.Anything, uint64(1)).Return(3, nil)
+		client.On("UpdateWebhookStatus", mock.Anything, uint64(1), models.WebhookStatusDisabled).Return(buildTestTime(), generateArbitraryError())
+		client.On("UpdateWebhookDelivery", mock.Anything, mock.Anything).Return(buildTestTime(), nil)
+		testUtil.Mock.ExpectBegin()
+		testUtil.Mock.ExpectRollback()
+
+		d := buildTestWebhookDispatcher(t, client)
+		d.db = testUtil.PlainDB
+		d.settings.DisableThreshold = 3
+		delivery := &models.WebhookDelivery{ID: 10, WebhookID: 1, Status: models.WebhookDeliveryPending}
+		require.NoError(t, d.attempt(delivery))
+
+		assert.Equal(t, models.WebhookDeliveryPending, delivery.Status)
+		ensureExpectationsWereMet(t, testUtil.Mock)
+	})
+
+	t.Run("with deleted webhook", func(*testing.T) {
+		client := &dairymock.MockDB{}
+		client.On("GetWebhook", mock.Anything, uint64(1)).Return(&models.Webhook{}, sql.ErrNoRows)
+		client.On("UpdateWebhookDelivery", mock.Anything, mock.Anything).Return(buildTestTime(), nil)
+
+		d := buildTestWebhookDispatcher(t, client)
+		delivery := &models.WebhookDelivery{ID: 10, WebhookID: 1, Status: models.WebhookDeliveryPending}
+		require.NoError(t, d.attempt(delivery))
+
+		assert.Equal(t, models.WebhookDeliveryDead, delivery.Status)
+		client.AssertNotCalled(t, "CreateWebhookExecutionLog", mock.Anything, mock.Anything)
+	})
+
+	t.Run("with disabled webhook", func(*testing.T) {
+		client := &dairymock.MockDB{}
+		client.On("GetWebhook", mock.Anything, uint64(1)).Return(&models.Webhook{ID: 1, Status: models.WebhookStatusDisabled, URL: ":"}, nil)
+		client.On("UpdateWebhookDelivery", mock.Anything, mock.Anything).Return(buildTestTime(), nil)
+
+		d := buildTestWebhookDispatcher(t, client)
+		delivery := &models.WebhookDelivery{ID: 10, WebhookID: 1, Status: models.WebhookDeliveryPending}
+		require.NoError(t, d.attempt(delivery))
+
+		assert.Equal(t, models.WebhookDeliveryDead, delivery.Status)
+		assert.Equal(t, "webhook is disabled", delivery.LastError)
+		client.AssertNotCalled(t, "CreateWebhookExecutionLog", mock.Anything, mock.Anything)
+	})
+
+	t.Run("with error retrieving webhook", func(*testing.T) {
+		client := &dairymock.MockDB{}
+		client.On("GetWebhook", mock.Anything, uint64(1)).Return(&models.Webhook{}, generateArbitraryError())
+		client.On("UpdateWebhookDelivery", mock.Anything, mock.Anything).Return(buildTestTime(), nil)
+
+		d := buildTestWebhookDispatcher(t, client)
+		delivery := &models.WebhookDelivery{ID: 10, WebhookID: 1, Status: models.WebhookDeliveryPending}
+		require.NoError(t, d.attempt(delivery))
+
+		assert.Equal(t, models.WebhookDeliveryPending, delivery.Status)
+		assert.Equal(t, buildTestTime().Add(time.Minute), delivery.NextAttemptOn)
+	})
+
+	t.Run("with error logging execution", func(*testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {}))
+		defer ts.Close()
+
+		client := &dairymock.MockDB{}
+		client.On("GetWebhook", mock.Anything, uint64(1)).Return(&models.Webhook{ID: 1, Status: models.WebhookStatusActive, URL: ts.URL}, nil)
+		client.On("CreateWebhookExecutionLog", mock.Anything, mock.Anything).Return(uint64(0), buildTestTime(), generateArbitraryError())
+		client.On("UpdateWebhookDelivery", mock.Anything, mock.Anything).Return(buildTestTime(), nil)
+
+		d := buildTestWebhookDispatcher(t, client)
+		delivery := &models.WebhookDelivery{ID: 10, WebhookID: 1, Status: models.WebhookDeliveryPending}
+		require.NoError(t, d.attempt(delivery))
+
+		assert.Equal(t, models.WebhookDeliveryDelivered, delivery.Status)
+	})
+
+	t.Run("with error updating delivery", func(*testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {}))
+		defer ts.Close()
+
+		client := &dairymock.MockDB{}
+		client.On("GetWebhook", mock.Anything, uint64(1)).Return(&models.Webhook{ID: 1, Status: models.WebhookStatusActive, URL: ts.URL}, nil)
+		client.On("CreateWebhookExecutionLog", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+		client.On("UpdateWebhookDelivery", mock.Anything, mock.Anything).Return(buildTestTime(), generateArbitraryError())
+
+		d := buildTestWebhookDispatcher(t, client)
+		assert.Error(t, d.attempt(&models.WebhookDelivery{ID: 10, WebhookID: 1}))
+	})
+}
+
+func TestWebhookEventObject(t *testing.T) {
+	t.Parallel()
+
+	wh := &models.Webhook{
+		ID:             1,
+		URL:            "https://example.com",
+		ContentType:    "application/xml",
+		Status:         models.WebhookStatusActive,
+		Secret:         "current-secret",
+		PreviousSecret: "previous-secret",
+	}
+	previous := newWebhookEventObject(wh)
+	wh.Status = models.WebhookStatusDisabled
+
+	event, err := newWebhookEvent(WebhookDisabledWebhookEvent, buildTestTime(), previous, newWebhookEventObject(wh))
+	require.NoError(t, err)
+	payload, err := marshalWebhookPayload(wh.ContentType, event.envelopeFor(wh))
+	require.NoError(t, err)
+
+	assert.Contains(t, string(payload), "<Status>disabled</Status>")
+	assert.NotContains(t, string(payload), wh.Secret)
+	assert.NotContains(t, string(payload), wh.PreviousSecret)
+	assert.Equal(t, []string{"status"}, event.changedFields)
+
+	// and webhooks themselves shouldn't give their secrets away in XML either
+	encoded, err := xml.Marshal(wh)
+	require.NoError(t, err)
+	assert.NotContains(t, string(encoded), wh.Secret)
+	assert.NotContains(t, string(encoded), wh.PreviousSecret)
+}
+
+func TestWebhookDispatcherSend(t *testing.T) {
+	t.Run("optimal conditions", func(*testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+			body, err := ioutil.ReadAll(req.Body)
+			require.NoError(t, err)
+			assert.Equal(t, `{"version":1,"event":"webhook.ping","occurred_at":"2016-12-31T12:00:00Z","data":{"thing":1},"previous":null,"changed_fields":[]}`, string(body))
+			assert.Equal(t, WebhookPingEvent, req.Header.Get("X-Dairycart-Event"))
+			assert.Equal(t, "0", req.Header.Get("X-Dairycart-Delivery"))
+			res.Write([]byte("pong"))
+		}))
+		defer ts.Close()
+
+		client := &dairymock.MockDB{}
+		client.On("CreateWebhookExecutionLog", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+
+		d := buildTestWebhookDispatcher(t, client)
+		wh := &models.Webhook{ID: 1, Status: models.WebhookStatusPending, URL: ts.URL, Secret: "secret"}
+		actual, err := d.Send(wh, WebhookPingEvent, &testBreakableStruct{Thing: "1"})
+		require.NoError(t, err)
+
+		assert.True(t, actual.Succeeded)
+		assert.Nil(t, actual.DeliveryID, "one-off requests aren't deliveries")
+		assert.Equal(t, http.StatusOK, actual.StatusCode)
+		assert.Equal(t, "pong", actual.ResponseBody)
+		client.AssertCalled(t, "CreateWebhookExecutionLog", mock.Anything, actual)
+	})
+
+	t.Run("with unreachable webhook", func(*testing.T) {
+		client := &dairymock.MockDB{}
+		client.On("CreateWebhookExecutionLog", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+
+		d := buildTestWebhookDispatcher(t, client)
+		actual, err := d.Send(&models.Webhook{ID: 1, URL: ":"}, WebhookPingEvent, &testBreakableStruct{Thing: "1"})
+		require.NoError(t, err)
+
+		assert.False(t, actual.Succeeded)
+		assert.NotEmpty(t, actual.Error)
+	})
+
+	t.Run("with private address", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+			t.Error("webhook on a private address shouldn't have been reached")
+		}))
+		defer ts.Close()
+
+		client := &dairymock.MockDB{}
+		client.On("CreateWebhookExecutionLog", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+
+		d := NewWebhookDispatcher(setupTestVariablesWithMock(t).PlainDB, client, WebhookDeliverySettings{Timeout: time.Second})
+		actual, err := d.Send(&models.Webhook{ID: 1, URL: ts.URL}, WebhookPingEvent, &testBreakableStruct{Thing: "1"})
+		require.NoError(t, err)
+
+		assert.False(t, actual.Succeeded)
+		assert.Contains(t, actual.Error, errDeniedAddress.Error())
+	})
+
+	t.Run("with error responding", func(*testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+			res.WriteHeader(http.StatusNotFound)
+		}))
+		defer ts.Close()
+
+		client := &dairymock.MockDB{}
+		client.On("CreateWebhookExecutionLog", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+
+		d := buildTestWebhookDispatcher(t, client)
+		actual, err := d.Send(&models.Webhook{ID: 1, URL: ts.URL}, WebhookPingEvent, &testBreakableStruct{Thing: "1"})
+		require.NoError(t, err)
+
+		assert.False(t, actual.Succeeded)
+		assert.Equal(t, http.StatusNotFound, actual.StatusCode)
+		assert.NotEmpty(t, actual.Error)
+	})
+
+	t.Run("with invalid object", func(*testing.T) {
+		d := buildTestWebhookDispatcher(t, &dairymock.MockDB{})
+		_, err := d.Send(&models.Webhook{ID: 1, URL: ":"}, WebhookPingEvent, &testBreakableStruct{Thing: "dongs"})
+		assert.Error(t, err)
+	})
+}
+
+func TestWebhookDispatcherRun(t *testing.T) {
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&calls, 1)
+	}))
+	defer ts.Close()
+
+	client := &dairymock.MockDB{}
+	d := buildTestWebhookDispatcher(t, client)
+	lease := 2*d.settings.Timeout + time.Minute
+
+	// a full batch gets dispatched straight away, followed by whatever's left
+	client.On("ClaimPendingWebhookDeliveries", mock.Anything, uint(2), lease).Return([]models.WebhookDelivery{{ID: 1, WebhookID: 1}, {ID: 2, WebhookID: 1}}, nil).Once()
+	client.On("ClaimPendingWebhookDeliveries", mock.Anything, uint(2), lease).Return([]models.WebhookDelivery{{ID: 3, WebhookID: 1}}, nil).Once()
+	client.On("ClaimPendingWebhookDeliveries", mock.Anything, uint(2), lease).Return([]models.WebhookDelivery{}, generateArbitraryError())
+	client.On("GetWebhook", mock.Anything, uint64(1)).Return(&models.Webhook{ID: 1, Status: models.WebhookStatusActive, URL: ts.URL}, nil)
+	client.On("CreateWebhookExecutionLog", mock.Anything, mock.Anything).Return(uint64(1), buildTestTime(), nil)
+	client.On("UpdateWebhookDelivery", mock.Anything, mock.Anything).Return(buildTestTime(), nil)
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		d.Run(stop)
+		close(done)
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for atomic.LoadInt32(&calls) < 3 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	close(stop)
+	<-done
+
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+	client.AssertNumberOfCalls(t, "UpdateWebhookDelivery", 3)
+}
+
+func TestHostLimiter(t *testing.T) {
+	t.Run("optimal conditions", func(*testing.T) {
+		l := newHostLimiter(2)
+		var inFlight, most int32
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				release := l.acquire("example.com")
+				defer release()
+
+				n := atomic.AddInt32(&inFlight, 1)
+				for {
+					m := atomic.LoadInt32(&most)
+					if n <= m || atomic.CompareAndSwapInt32(&most, m, n) {
+						break
+					}
+				}
+				time.Sleep(5 * time.Millisecond)
+				atomic.AddInt32(&inFlight, -1)
+			}()
+		}
+		wg.Wait()
+
+		assert.True(t, atomic.LoadInt32(&most) <= 2, "no more than two requests should be in flight at once")
+		assert.Empty(t, l.slots, "hosts should be forgotten once nothing's in flight to them")
+	})
+
+	t.Run("with separate hosts", func(*testing.T) {
+		l := newHostLimiter(1)
+		releaseFirst := l.acquire("example.com")
+		defer releaseFirst()
+
+		acquired := make(chan struct{})
+		go func() {
+			release := l.acquire("example.org")
+			release()
+			close(acquired)
+		}()
+
+		select {
+		case <-acquired:
+		case <-time.After(time.Second):
+			t.Error("one busy host shouldn't hold up another")
+		}
+	})
+
+	t.Run("with no limit", func(*testing.T) {
+		l := newHostLimiter(0)
+		for i := 0; i < 10; i++ {
+			l.acquire("example.com")
+		}
+	})
+}
+
+func TestTruncateForLog(t *testing.T) {
+	assert.Equal(t, "short", truncateForLog("short", 10))
+	assert.Equal(t, "trunc", truncateForLog("truncated", 5))
+	// "é" is two bytes, and shouldn't be cut in half
+	assert.Equal(t, "caf", truncateForLog("café", 4))
+}
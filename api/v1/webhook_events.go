@@ -0,0 +1,245 @@
+package api
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dairycart/dairycart/models/v1"
+)
+
+// Webhook events are named `<resource>.<action>`, and webhooks can subscribe to them
+// by name, to every event for a resource with `<resource>.*`, or to everything with `*`
+const (
+	ProductCreatedWebhookEvent  = "product.created"
+	ProductUpdatedWebhookEvent  = "product.updated"
+	ProductArchivedWebhookEvent = "product.archived"
+
+	ProductRootCreatedWebhookEvent  = "product_root.created"
+	ProductRootUpdatedWebhookEvent  = "product_root.updated"
+	ProductRootArchivedWebhookEvent = "product_root.archived"
+
+	ProductOptionCreatedWebhookEvent  = "product_option.created"
+	ProductOptionUpdatedWebhookEvent  = "product_option.updated"
+	ProductOptionArchivedWebhookEvent = "product_option.archived"
+
+	ProductOptionValueCreatedWebhookEvent  = "product_option_value.created"
+	ProductOptionValueUpdatedWebhookEvent  = "product_option_value.updated"
+	ProductOptionValueArchivedWebhookEvent = "product_option_value.archived"
+
+	ProductImageCreatedWebhookEvent  = "product_image.created"
+	ProductImageUpdatedWebhookEvent  = "product_image.updated"
+	ProductImageArchivedWebhookEvent = "product_image.archived"
+
+	DiscountCreatedWebhookEvent  = "discount.created"
+	DiscountUpdatedWebhookEvent  = "discount.updated"
+	DiscountArchivedWebhookEvent = "discount.archived"
+
+	UserCreatedWebhookEvent  = "user.created"
+	UserUpdatedWebhookEvent  = "user.updated"
+	UserArchivedWebhookEvent = "user.archived"
+
+	// WebhookDisabledWebhookEvent is sent when a webhook is disabled for failing too many deliveries in a row
+	WebhookDisabledWebhookEvent = "webhook.disabled"
+
+	// AllWebhookEvents is the wildcard that subscribes a webhook to every event
+	AllWebhookEvents = "*"
+)
+
+// These events are only ever sent to one webhook, when someone asks for them, so nothing can subscribe to them,
+// even with `webhook.*`
+const (
+	// WebhookPingEvent is sent to test that a webhook is reachable
+	WebhookPingEvent = "webhook.ping"
+	// WebhookVerificationEvent carries the challenge a webhook has to echo back to prove it's listening
+	WebhookVerificationEvent = "webhook.verification"
+)
+
+// webhookEvents is every event a webhook can subscribe to. Adding an event here is all it
+// takes for webhooks to be able to subscribe to it, since the database doesn't know about them.
+var webhookEvents = map[string]bool{
+	ProductCreatedWebhookEvent:             true,
+	ProductUpdatedWebhookEvent:             true,
+	ProductArchivedWebhookEvent:            true,
+	ProductRootCreatedWebhookEvent:         true,
+	ProductRootUpdatedWebhookEvent:         true,
+	ProductRootArchivedWebhookEvent:        true,
+	ProductOptionCreatedWebhookEvent:       true,
+	ProductOptionUpdatedWebhookEvent:       true,
+	ProductOptionArchivedWebhookEvent:      true,
+	ProductOptionValueCreatedWebhookEvent:  true,
+	ProductOptionValueUpdatedWebhookEvent:  true,
+	ProductOptionValueArchivedWebhookEvent: true,
+	ProductImageCreatedWebhookEvent:        true,
+	ProductImageUpdatedWebhookEvent:        true,
+	ProductImageArchivedWebhookEvent:       true,
+	DiscountCreatedWebhookEvent:            true,
+	DiscountUpdatedWebhookEvent:            true,
+	DiscountArchivedWebhookEvent:           true,
+	UserCreatedWebhookEvent:                true,
+	UserUpdatedWebhookEvent:                true,
+	UserArchivedWebhookEvent:               true,
+	WebhookDisabledWebhookEvent:            true,
+}
+
+// validWebhookEventType reports whether a webhook can subscribe to eventType, which
+// is either an event's name, a wildcard for a resource's events, or the catch-all wildcard
+func validWebhookEventType(eventType string) bool {
+	if eventType == AllWebhookEvents || webhookEvents[eventType] {
+		return true
+	}
+
+	if strings.HasSuffix(eventType, ".*") {
+		resource := strings.TrimSuffix(eventType, "*")
+		for event := range webhookEvents {
+			if strings.HasPrefix(event, resource) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// legacyWebhookEvents maps the names events had before they were namespaced by resource
+// to the names they have now, so webhooks created with the old names keep working
+var legacyWebhookEvents = map[string]string{
+	"product_created":  ProductCreatedWebhookEvent,
+	"product_updated":  ProductUpdatedWebhookEvent,
+	"product_archived": ProductArchivedWebhookEvent,
+}
+
+// normalizeWebhookEventTypes folds the deprecated single event type into eventTypes, and maps legacy
+// event names to their current ones. It returns nil if neither was provided.
+func normalizeWebhookEventTypes(legacyEventType string, eventTypes []string) []string {
+	if legacyEventType != "" {
+		eventTypes = append(eventTypes, legacyEventType)
+	}
+	if eventTypes == nil {
+		return nil
+	}
+
+	normalized := []string{}
+	seen := map[string]bool{}
+	for _, eventType := range eventTypes {
+		if current, ok := legacyWebhookEvents[eventType]; ok {
+			eventType = current
+		}
+		if !seen[eventType] {
+			seen[eventType] = true
+			normalized = append(normalized, eventType)
+		}
+	}
+	return normalized
+}
+
+func validateWebhookEventTypes(eventTypes []string) error {
+	if len(eventTypes) == 0 {
+		return fmt.Errorf("at least one event type is required")
+	}
+	for _, eventType := range eventTypes {
+		if !validWebhookEventType(eventType) {
+			return fmt.Errorf("invalid event type: '%s'", eventType)
+		}
+	}
+	return nil
+}
+
+// fields that change with every update, and so aren't worth reporting as changed
+var unreportedWebhookFields = map[string]bool{
+	"updated_on": true,
+}
+
+// webhookEvent is an event that's worked out once, and then sent to each webhook the way it asked for it
+type webhookEvent struct {
+	eventType  string
+	occurredAt time.Time
+	object     interface{}
+	// document is the object the way it's encoded in JSON
+	document map[string]interface{}
+	// filterDocument is what filters are matched against. It's the document, with the previous values of
+	// changed fields under `previous`, so a filter like `quantity < 10 and previous.quantity >= 10` can tell
+	// when a field crosses a threshold.
+	filterDocument map[string]interface{}
+	previous       models.WebhookPreviousValues
+	changedFields  []string
+}
+
+// newWebhookEvent works out what's sent for an event. previous is nil unless object was updated, in which
+// case it's the object as it was before.
+func newWebhookEvent(eventType string, occurredAt time.Time, previous, object interface{}) (*webhookEvent, error) {
+	document, err := buildWebhookDocument(object)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &webhookEvent{
+		eventType:      eventType,
+		occurredAt:     occurredAt.UTC(),
+		object:         object,
+		document:       document,
+		filterDocument: document,
+		changedFields:  []string{},
+	}
+	if previous == nil {
+		return e, nil
+	}
+
+	previousDocument, err := buildWebhookDocument(previous)
+	if err != nil {
+		return nil, err
+	}
+
+	e.previous = models.WebhookPreviousValues{}
+	for field, value := range previousDocument {
+		if !unreportedWebhookFields[field] && !reflect.DeepEqual(value, document[field]) {
+			e.previous[field] = value
+		}
+	}
+	for field := range document {
+		if _, ok := previousDocument[field]; !ok && !unreportedWebhookFields[field] {
+			e.previous[field] = nil
+		}
+	}
+	e.changedFields = sortedWebhookFields(e.previous)
+
+	e.filterDocument = map[string]interface{}{}
+	for field, value := range document {
+		e.filterDocument[field] = value
+	}
+	e.filterDocument["previous"] = map[string]interface{}(e.previous)
+
+	return e, nil
+}
+
+// envelopeFor returns the envelope an event is sent to a webhook in, with only the fields it asked for
+func (e *webhookEvent) envelopeFor(wh *models.Webhook) *models.WebhookEventEnvelope {
+	envelope := &models.WebhookEventEnvelope{
+		Version:       models.WebhookEventEnvelopeVersion,
+		Event:         e.eventType,
+		OccurredAt:    e.occurredAt,
+		Data:          e.object,
+		Previous:      e.previous,
+		ChangedFields: e.changedFields,
+	}
+
+	// field lists are only allowed for JSON payloads, since a document can't be encoded as XML
+	if len(wh.Fields) > 0 && !webhookContentTypeIsXML(wh.ContentType) {
+		envelope.Data = projectWebhookDocument(e.document, wh.Fields)
+		if e.previous != nil {
+			envelope.Previous = projectWebhookDocument(e.previous, wh.Fields)
+			envelope.ChangedFields = sortedWebhookFields(envelope.Previous)
+		}
+	}
+	return envelope
+}
+
+func sortedWebhookFields(values models.WebhookPreviousValues) []string {
+	fields := make([]string, 0, len(values))
+	for field := range values {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
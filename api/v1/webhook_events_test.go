@@ -0,0 +1,54 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidWebhookEventType(t *testing.T) {
+	t.Parallel()
+
+	valid := []string{
+		ProductCreatedWebhookEvent,
+		DiscountArchivedWebhookEvent,
+		"product.*",
+		"product_option_value.*",
+		"*",
+	}
+	for _, eventType := range valid {
+		assert.True(t, validWebhookEventType(eventType), "expected %q to be valid", eventType)
+	}
+
+	invalid := []string{
+		"",
+		"product",
+		"product.exploded",
+		"product_created",
+		"nonsense.*",
+		"product*",
+		"*.created",
+	}
+	for _, eventType := range invalid {
+		assert.False(t, validWebhookEventType(eventType), "expected %q to be invalid", eventType)
+	}
+}
+
+func TestNormalizeWebhookEventTypes(t *testing.T) {
+	t.Parallel()
+
+	assert.Nil(t, normalizeWebhookEventTypes("", nil))
+	assert.Equal(t, []string{ProductCreatedWebhookEvent}, normalizeWebhookEventTypes("product_created", nil))
+	assert.Equal(t,
+		[]string{ProductUpdatedWebhookEvent, "discount.*", ProductArchivedWebhookEvent},
+		normalizeWebhookEventTypes("product_archived", []string{"product_updated", "discount.*", ProductUpdatedWebhookEvent}),
+	)
+}
+
+func TestValidateWebhookEventTypes(t *testing.T) {
+	t.Parallel()
+
+	assert.NoError(t, validateWebhookEventTypes([]string{ProductCreatedWebhookEvent, "discount.*"}))
+	assert.Error(t, validateWebhookEventTypes(nil), "webhooks should have to subscribe to something")
+	assert.Error(t, validateWebhookEventTypes([]string{ProductCreatedWebhookEvent, "product.exploded"}))
+}
@@ -0,0 +1,526 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Webhooks can narrow down what they're sent in two ways. A filter is an expression that an event's object
+// has to match for the event to be sent at all, like
+//
+//     brand == "Acme" and (quantity < 10 or price >= 100)
+//     brand in ("Acme", "Globex") and not on_sale == true
+//
+// Comparisons are between a field and a string, number, boolean or null, with ==, !=, <, <=, > or >=, or
+// between a field and a list of values with `in`. A field that's missing counts as null. Fields are named the
+// way they're named in JSON payloads, and nested fields are named with dots, like `options.name`.
+//
+// Fields are an allowlist of the fields a payload should include, named the same way. Everything else is left
+// out, which keeps payloads small for receivers that only care about a few things.
+
+// webhookFilter is a parsed filter expression
+type webhookFilter interface {
+	matches(document map[string]interface{}) bool
+}
+
+type webhookAndFilter struct{ left, right webhookFilter }
+
+func (f webhookAndFilter) matches(document map[string]interface{}) bool {
+	return f.left.matches(document) && f.right.matches(document)
+}
+
+type webhookOrFilter struct{ left, right webhookFilter }
+
+func (f webhookOrFilter) matches(document map[string]interface{}) bool {
+	return f.left.matches(document) || f.right.matches(document)
+}
+
+type webhookNotFilter struct{ inner webhookFilter }
+
+func (f webhookNotFilter) matches(document map[string]interface{}) bool {
+	return !f.inner.matches(document)
+}
+
+type webhookComparisonFilter struct {
+	field    string
+	operator string
+	// values has one value, unless the operator is `in`
+	values []interface{}
+}
+
+func (f webhookComparisonFilter) matches(document map[string]interface{}) bool {
+	actual := lookUpWebhookField(document, f.field)
+
+	if f.operator == "in" {
+		for _, v := range f.values {
+			if cmp, ok := compareWebhookValues(actual, v); ok && cmp == 0 {
+				return true
+			}
+		}
+		return false
+	}
+
+	cmp, ok := compareWebhookValues(actual, f.values[0])
+	switch f.values[0].(type) {
+	case float64, string:
+	default:
+		// bools and nulls have no order, so they can only be equal or not
+		if f.operator != "==" && f.operator != "!=" {
+			return false
+		}
+	}
+
+	switch f.operator {
+	case "==":
+		return ok && cmp == 0
+	case "!=":
+		return !ok || cmp != 0
+	case "<":
+		return ok && cmp < 0
+	case "<=":
+		return ok && cmp <= 0
+	case ">":
+		return ok && cmp > 0
+	case ">=":
+		return ok && cmp >= 0
+	}
+	return false
+}
+
+// lookUpWebhookField returns the value of a dotted field name in a document, or nil if it isn't there
+func lookUpWebhookField(document map[string]interface{}, field string) interface{} {
+	var current interface{} = document
+	for _, part := range strings.Split(field, ".") {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		current = m[part]
+	}
+	return current
+}
+
+// compareWebhookValues compares a value from a document with a value from a filter. ok is false if they
+// can't be compared, like a string and a number.
+func compareWebhookValues(actual, expected interface{}) (cmp int, ok bool) {
+	switch e := expected.(type) {
+	case nil:
+		if actual == nil {
+			return 0, true
+		}
+		return 1, true
+	case float64:
+		n, isNumber := actual.(json.Number)
+		if !isNumber {
+			return 0, false
+		}
+		a, err := n.Float64()
+		if err != nil {
+			return 0, false
+		}
+		switch {
+		case a < e:
+			return -1, true
+		case a > e:
+			return 1, true
+		}
+		return 0, true
+	case string:
+		a, isString := actual.(string)
+		if !isString {
+			return 0, false
+		}
+		return strings.Compare(a, e), true
+	case bool:
+		a, isBool := actual.(bool)
+		if !isBool {
+			return 0, false
+		}
+		if a == e {
+			return 0, true
+		}
+		return 1, true
+	}
+	return 0, false
+}
+
+type webhookFilterToken struct {
+	kind string // one of "field", "string", "number", "operator", "keyword", or "punctuation"
+	text string
+	pos  int
+}
+
+var webhookFilterKeywords = map[string]bool{
+	"and":   true,
+	"or":    true,
+	"not":   true,
+	"in":    true,
+	"true":  true,
+	"false": true,
+	"null":  true,
+}
+
+func tokenizeWebhookFilter(expression string) ([]webhookFilterToken, error) {
+	var tokens []webhookFilterToken
+	for i := 0; i < len(expression); {
+		c := expression[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(' || c == ')' || c == ',':
+			tokens = append(tokens, webhookFilterToken{kind: "punctuation", text: string(c), pos: i})
+			i++
+		case strings.ContainsRune("=!<>&|", rune(c)):
+			op := string(c)
+			if i+1 < len(expression) {
+				switch pair := expression[i : i+2]; pair {
+				case "==", "!=", "<=", ">=", "&&", "||":
+					op = pair
+				}
+			}
+			switch op {
+			case "&&":
+				tokens = append(tokens, webhookFilterToken{kind: "keyword", text: "and", pos: i})
+			case "||":
+				tokens = append(tokens, webhookFilterToken{kind: "keyword", text: "or", pos: i})
+			case "!":
+				tokens = append(tokens, webhookFilterToken{kind: "keyword", text: "not", pos: i})
+			case "==", "!=", "<", "<=", ">", ">=":
+				tokens = append(tokens, webhookFilterToken{kind: "operator", text: op, pos: i})
+			default:
+				return nil, fmt.Errorf("unexpected '%s' at position %d", op, i)
+			}
+			i += len(op)
+		case c == '"':
+			end := i + 1
+			for ; end < len(expression) && expression[end] != '"'; end++ {
+				if expression[end] == '\\' {
+					end++
+				}
+			}
+			if end >= len(expression) {
+				return nil, fmt.Errorf("unterminated string at position %d", i)
+			}
+			s, err := strconv.Unquote(expression[i : end+1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid string at position %d", i)
+			}
+			tokens = append(tokens, webhookFilterToken{kind: "string", text: s, pos: i})
+			i = end + 1
+		case c == '-' || c == '.' || (c >= '0' && c <= '9'):
+			end := i + 1
+			for ; end < len(expression) && (expression[end] == '.' || (expression[end] >= '0' && expression[end] <= '9')); end++ {
+			}
+			if _, err := strconv.ParseFloat(expression[i:end], 64); err != nil {
+				return nil, fmt.Errorf("invalid number '%s' at position %d", expression[i:end], i)
+			}
+			tokens = append(tokens, webhookFilterToken{kind: "number", text: expression[i:end], pos: i})
+			i = end
+		case c == '_' || unicode.IsLetter(rune(c)):
+			end := i + 1
+			for ; end < len(expression); end++ {
+				r := rune(expression[end])
+				if r != '_' && r != '.' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+					break
+				}
+			}
+			word := expression[i:end]
+			kind := "field"
+			if webhookFilterKeywords[word] {
+				kind = "keyword"
+			} else if strings.HasSuffix(word, ".") || strings.Contains(word, "..") {
+				return nil, fmt.Errorf("invalid field '%s' at position %d", word, i)
+			}
+			tokens = append(tokens, webhookFilterToken{kind: kind, text: word, pos: i})
+			i = end
+		default:
+			return nil, fmt.Errorf("unexpected '%c' at position %d", c, i)
+		}
+	}
+	return tokens, nil
+}
+
+const (
+	// maxWebhookFilterLength is the longest filter a webhook can have, in bytes
+	maxWebhookFilterLength = 4096
+	// maxWebhookFilterDepth is how deeply parentheses and `not`s can be nested in a filter. Each level is a
+	// recursive call when the filter's parsed, so without a limit a filter could exhaust the stack.
+	maxWebhookFilterDepth = 32
+)
+
+// webhookFilterParser is a recursive descent parser for filter expressions. `or` binds loosest, then `and`,
+// then `not`.
+type webhookFilterParser struct {
+	tokens []webhookFilterToken
+	pos    int
+	length int
+	depth  int
+}
+
+// parseWebhookFilter parses a filter expression, returning an error that says what's wrong with it if it's invalid
+func parseWebhookFilter(expression string) (webhookFilter, error) {
+	tokens, err := tokenizeWebhookFilter(expression)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("filter is empty")
+	}
+
+	p := &webhookFilterParser{tokens: tokens, length: len(expression)}
+	f, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t, ok := p.peek(); ok {
+		return nil, fmt.Errorf("unexpected '%s' at position %d", t.text, t.pos)
+	}
+	return f, nil
+}
+
+func (p *webhookFilterParser) peek() (webhookFilterToken, bool) {
+	if p.pos >= len(p.tokens) {
+		return webhookFilterToken{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+// next returns the next token, or an error if the expression ended early
+func (p *webhookFilterParser) next() (webhookFilterToken, error) {
+	t, ok := p.peek()
+	if !ok {
+		return t, fmt.Errorf("unexpected end of filter at position %d", p.length)
+	}
+	p.pos++
+	return t, nil
+}
+
+func (p *webhookFilterParser) accept(kind, text string) bool {
+	if t, ok := p.peek(); ok && t.kind == kind && t.text == text {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *webhookFilterParser) expect(kind, text string) error {
+	t, err := p.next()
+	if err != nil {
+		return err
+	}
+	if t.kind != kind || t.text != text {
+		return fmt.Errorf("expected '%s' at position %d, found '%s'", text, t.pos, t.text)
+	}
+	return nil
+}
+
+func (p *webhookFilterParser) parseOr() (webhookFilter, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("keyword", "or") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = webhookOrFilter{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *webhookFilterParser) parseAnd() (webhookFilter, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("keyword", "and") {
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = webhookAndFilter{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *webhookFilterParser) parseNot() (webhookFilter, error) {
+	if t, ok := p.peek(); ok && (t.kind == "keyword" && t.text == "not" || t.kind == "punctuation" && t.text == "(") {
+		if p.depth == maxWebhookFilterDepth {
+			return nil, fmt.Errorf("filter is nested more than %d levels deep at position %d", maxWebhookFilterDepth, t.pos)
+		}
+		p.depth++
+		defer func() { p.depth-- }()
+	}
+
+	if p.accept("keyword", "not") {
+		inner, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return webhookNotFilter{inner: inner}, nil
+	}
+
+	if p.accept("punctuation", "(") {
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return inner, p.expect("punctuation", ")")
+	}
+
+	return p.parseComparison()
+}
+
+func (p *webhookFilterParser) parseComparison() (webhookFilter, error) {
+	field, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	if field.kind != "field" {
+		return nil, fmt.Errorf("expected a field at position %d, found '%s'", field.pos, field.text)
+	}
+
+	if p.accept("keyword", "in") {
+		if err = p.expect("punctuation", "("); err != nil {
+			return nil, err
+		}
+		f := webhookComparisonFilter{field: field.text, operator: "in"}
+		for {
+			v, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			f.values = append(f.values, v)
+			if !p.accept("punctuation", ",") {
+				break
+			}
+		}
+		return f, p.expect("punctuation", ")")
+	}
+
+	op, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	if op.kind != "operator" {
+		return nil, fmt.Errorf("expected a comparison at position %d, found '%s'", op.pos, op.text)
+	}
+	v, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	return webhookComparisonFilter{field: field.text, operator: op.text, values: []interface{}{v}}, nil
+}
+
+func (p *webhookFilterParser) parseValue() (interface{}, error) {
+	t, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case t.kind == "string":
+		return t.text, nil
+	case t.kind == "number":
+		// the tokenizer has already made sure this parses
+		n, _ := strconv.ParseFloat(t.text, 64)
+		return n, nil
+	case t.kind == "keyword" && t.text == "true":
+		return true, nil
+	case t.kind == "keyword" && t.text == "false":
+		return false, nil
+	case t.kind == "keyword" && t.text == "null":
+		return nil, nil
+	}
+	return nil, fmt.Errorf("expected a value at position %d, found '%s'", t.pos, t.text)
+}
+
+// buildWebhookDocument turns an object into the form filters and field lists are applied to
+func buildWebhookDocument(object interface{}) (map[string]interface{}, error) {
+	encoded, err := json.Marshal(object)
+	if err != nil {
+		return nil, err
+	}
+
+	document := map[string]interface{}{}
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	// numbers are kept as they were, so a large ID doesn't lose precision on its way through a float
+	decoder.UseNumber()
+	if err = decoder.Decode(&document); err != nil {
+		return nil, err
+	}
+	return document, nil
+}
+
+// projectWebhookDocument copies the listed fields of a document into a new one. A field inside a list of
+// objects, like `options.name`, is copied from every object in the list.
+func projectWebhookDocument(document map[string]interface{}, fields []string) map[string]interface{} {
+	out := map[string]interface{}{}
+	for _, field := range fields {
+		projectWebhookField(out, document, strings.Split(field, "."))
+	}
+	return out
+}
+
+func projectWebhookField(dst, src map[string]interface{}, path []string) {
+	value, ok := src[path[0]]
+	if !ok {
+		return
+	}
+	if len(path) == 1 {
+		dst[path[0]] = value
+		return
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		child, _ := dst[path[0]].(map[string]interface{})
+		if child == nil {
+			child = map[string]interface{}{}
+		}
+		projectWebhookField(child, v, path[1:])
+		dst[path[0]] = child
+	case []interface{}:
+		children, _ := dst[path[0]].([]interface{})
+		if children == nil {
+			children = make([]interface{}, len(v))
+		}
+		for i, item := range v {
+			if m, isMap := item.(map[string]interface{}); isMap {
+				child, _ := children[i].(map[string]interface{})
+				if child == nil {
+					child = map[string]interface{}{}
+				}
+				projectWebhookField(child, m, path[1:])
+				children[i] = child
+			}
+		}
+		dst[path[0]] = children
+	}
+}
+
+// validateWebhookPayloadOptions makes sure a webhook's field list and filter can be used
+func validateWebhookPayloadOptions(contentType string, fields []string, filter string) error {
+	if len(fields) > 0 && webhookContentTypeIsXML(contentType) {
+		return fmt.Errorf("fields can only be chosen for JSON payloads")
+	}
+	for _, field := range fields {
+		if field == "" || strings.HasPrefix(field, ".") || strings.HasSuffix(field, ".") || strings.Contains(field, "..") {
+			return fmt.Errorf("invalid field: '%s'", field)
+		}
+	}
+
+	if len(filter) > maxWebhookFilterLength {
+		return fmt.Errorf("filter can't be longer than %d characters", maxWebhookFilterLength)
+	}
+	if filter != "" {
+		if _, err := parseWebhookFilter(filter); err != nil {
+			return fmt.Errorf("invalid filter: %v", err)
+		}
+	}
+	return nil
+}
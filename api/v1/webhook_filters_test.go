@@ -0,0 +1,148 @@
+package api
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dairycart/dairycart/models/v1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseWebhookFilter(t *testing.T) {
+	t.Parallel()
+
+	document, err := buildWebhookDocument(&models.Product{
+		Brand:    "Acme",
+		Name:     "Skateboard",
+		Quantity: 8,
+		Price:    12.5,
+		OnSale:   true,
+	})
+	require.NoError(t, err)
+
+	matching := []string{
+		`brand == "Acme"`,
+		`brand != "Globex"`,
+		`quantity < 10`,
+		`quantity <= 8 and quantity >= 8`,
+		`price > 12`,
+		`on_sale == true`,
+		`brand in ("Globex", "Acme")`,
+		`brand == "Globex" or quantity < 10`,
+		`brand == "Acme" && (quantity > 100 || price < 20)`,
+		`not brand == "Globex"`,
+		`!(quantity > 10)`,
+		`nonexistent == null`,
+		`nonexistent != "anything"`,
+		`available_on.nested == null`,
+	}
+	for _, expression := range matching {
+		f, err := parseWebhookFilter(expression)
+		require.NoError(t, err, "expected %q to parse", expression)
+		assert.True(t, f.matches(document), "expected %q to match", expression)
+	}
+
+	notMatching := []string{
+		`brand == "Globex"`,
+		`quantity > 10`,
+		`brand in ("Globex", "Initech")`,
+		`brand == "Acme" and quantity > 10`,
+		`not (brand == "Acme")`,
+		// things that can't be compared never match
+		`brand > 10`,
+		`quantity == "8"`,
+		`on_sale > false`,
+		`nonexistent < 10`,
+	}
+	for _, expression := range notMatching {
+		f, err := parseWebhookFilter(expression)
+		require.NoError(t, err, "expected %q to parse", expression)
+		assert.False(t, f.matches(document), "expected %q not to match", expression)
+	}
+
+	invalid := []string{
+		``,
+		`brand`,
+		`brand ==`,
+		`brand = "Acme"`,
+		`"Acme" == brand`,
+		`brand == "Acme`,
+		`brand == Acme`,
+		`quantity < 1.2.3`,
+		`(brand == "Acme"`,
+		`brand == "Acme")`,
+		`brand in "Acme"`,
+		`brand in ("Acme"`,
+		`brand == "Acme" and`,
+		`brand. == "Acme"`,
+		`brand == "Acme" & quantity < 10`,
+		`brand ~ "Acme"`,
+	}
+	for _, expression := range invalid {
+		_, err := parseWebhookFilter(expression)
+		assert.Error(t, err, "expected %q to be invalid", expression)
+	}
+}
+
+func TestParseWebhookFilterNesting(t *testing.T) {
+	t.Parallel()
+
+	nested := func(depth int) string {
+		return strings.Repeat("(", depth) + `brand == "Acme"` + strings.Repeat(")", depth)
+	}
+	_, err := parseWebhookFilter(nested(maxWebhookFilterDepth))
+	assert.NoError(t, err)
+	_, err = parseWebhookFilter(nested(maxWebhookFilterDepth + 1))
+	assert.Error(t, err)
+
+	_, err = parseWebhookFilter(strings.Repeat("not ", maxWebhookFilterDepth) + `brand == "Acme"`)
+	assert.NoError(t, err)
+	_, err = parseWebhookFilter(strings.Repeat("not ", maxWebhookFilterDepth+1) + `brand == "Acme"`)
+	assert.Error(t, err)
+
+	// levels that have been closed don't count towards the limit
+	sequential := strings.Repeat(nested(maxWebhookFilterDepth)+" and ", 2) + `brand == "Acme"`
+	_, err = parseWebhookFilter(sequential)
+	assert.NoError(t, err)
+}
+
+func TestProjectWebhookDocument(t *testing.T) {
+	t.Parallel()
+
+	document, err := buildWebhookDocument(&models.ProductRoot{
+		ID:    1,
+		Name:  "Skateboard",
+		Brand: "Acme",
+		Options: []models.ProductOption{
+			{ID: 2, Name: "color", ProductRootID: 1},
+			{ID: 3, Name: "size", ProductRootID: 1},
+		},
+	})
+	require.NoError(t, err)
+
+	actual := projectWebhookDocument(document, []string{"id", "brand", "options.name", "nonexistent", "name.nested"})
+	assert.Equal(t, map[string]interface{}{
+		"id":    document["id"],
+		"brand": "Acme",
+		"options": []interface{}{
+			map[string]interface{}{"name": "color"},
+			map[string]interface{}{"name": "size"},
+		},
+	}, actual)
+}
+
+func TestValidateWebhookPayloadOptions(t *testing.T) {
+	t.Parallel()
+
+	assert.NoError(t, validateWebhookPayloadOptions("application/json", []string{"price", "options.name"}, `brand == "Acme"`))
+	assert.NoError(t, validateWebhookPayloadOptions("application/xml", nil, `brand == "Acme"`))
+	assert.Error(t, validateWebhookPayloadOptions("application/xml", []string{"price"}, ""), "fields should only be allowed for JSON")
+	assert.Error(t, validateWebhookPayloadOptions("application/json", []string{"options..name"}, ""))
+	assert.Error(t, validateWebhookPayloadOptions("application/json", []string{""}, ""))
+	assert.Error(t, validateWebhookPayloadOptions("application/json", nil, `brand ==`))
+
+	tooLong := `brand == "` + strings.Repeat("a", maxWebhookFilterLength) + `"`
+	assert.Error(t, validateWebhookPayloadOptions("application/json", nil, tooLong))
+}
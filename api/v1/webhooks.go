@@ -3,16 +3,100 @@ package api
 import (
 	"database/sql"
 	"encoding/json"
+	"log"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/dairycart/dairycart/models/v1"
 	"github.com/dairycart/dairycart/storage/v1/database"
 
+	"github.com/dchest/uniuri"
 	"github.com/go-chi/chi"
 	"github.com/imdario/mergo"
 )
 
+const (
+	webhookSecretSize    = 64
+	webhookChallengeSize = 32
+	// webhookSecretRotationGracePeriod is how long deliveries are also signed with a webhook's previous
+	// secret after it's rotated, so receivers have time to switch over without rejecting anything
+	webhookSecretRotationGracePeriod = 24 * time.Hour
+)
+
+func generateWebhookSecret() string {
+	return uniuri.NewLen(webhookSecretSize)
+}
+
+// webhookSigningSecrets returns the secrets a delivery to the given webhook should be signed with
+func webhookSigningSecrets(webhook *models.Webhook, now time.Time) []string {
+	var secrets []string
+	if webhook.Secret != "" {
+		secrets = append(secrets, webhook.Secret)
+	}
+	if webhook.PreviousSecret != "" && webhook.SecretRotatedOn != nil && now.Sub(webhook.SecretRotatedOn.Time) < webhookSecretRotationGracePeriod {
+		secrets = append(secrets, webhook.PreviousSecret)
+	}
+	return secrets
+}
+
+// webhookPing is what's sent to a webhook when it's pinged
+type webhookPing struct {
+	WebhookID  uint64   `json:"webhook_id" xml:"webhook_id"`
+	EventTypes []string `json:"event_types" xml:"event_types"`
+}
+
+// webhookVerificationChallenge is what's sent to a webhook during its verification handshake
+type webhookVerificationChallenge struct {
+	Challenge string `json:"challenge" xml:"challenge"`
+}
+
+// challengeWasEchoed reports whether a webhook responded to its verification handshake with the challenge,
+// either as the whole response body or as the `challenge` field of a JSON object. Echoing the whole event
+// doesn't count, since anything that reflects what it's sent would pass without knowing about the handshake.
+func challengeWasEchoed(responseBody, challenge string) bool {
+	if strings.TrimSpace(responseBody) == challenge {
+		return true
+	}
+	echoed := &webhookVerificationChallenge{}
+	return json.Unmarshal([]byte(responseBody), echoed) == nil && echoed.Challenge == challenge
+}
+
+// verifyWebhook sends a webhook a challenge, and activates it if it echoes the challenge back. A webhook
+// that doesn't is left the way it was.
+func verifyWebhook(db *sql.DB, client database.Storer, dispatcher *WebhookDispatcher, webhook *models.Webhook) (*models.WebhookExecutionLog, error) {
+	challenge := uniuri.NewLen(webhookChallengeSize)
+	execution, err := dispatcher.Send(webhook, WebhookVerificationEvent, &webhookVerificationChallenge{Challenge: challenge})
+	if err != nil {
+		return nil, err
+	}
+	if !execution.Succeeded || !challengeWasEchoed(execution.ResponseBody, challenge) {
+		return execution, nil
+	}
+
+	updatedOn, err := client.UpdateWebhookStatus(db, webhook.ID, models.WebhookStatusActive)
+	if err != nil {
+		return execution, err
+	}
+	webhook.Status = models.WebhookStatusActive
+	webhook.UpdatedOn = &models.Dairytime{Time: updatedOn}
+	return execution, nil
+}
+
+// buildWebhookPingResult reports how a webhook responded to a one-off request. What it responded with is
+// left out, the same as it is from the execution logs.
+func buildWebhookPingResult(execution *models.WebhookExecutionLog) *models.WebhookPingResult {
+	if execution == nil {
+		return nil
+	}
+	return &models.WebhookPingResult{
+		StatusCode: execution.StatusCode,
+		LatencyMS:  execution.LatencyMS,
+		Succeeded:  execution.Succeeded,
+	}
+}
+
 func buildWebhookListRetrievalHandler(db *sql.DB, client database.Storer) http.HandlerFunc {
 	// WebhookListRetrievalHandler is a request handler that returns a list of Webhooks
 	return func(res http.ResponseWriter, req *http.Request) {
@@ -42,9 +126,12 @@ func buildWebhookListRetrievalHandler(db *sql.DB, client database.Storer) http.H
 }
 
 func buildWebhookListRetrievalByEventTypeHandler(db *sql.DB, client database.Storer) http.HandlerFunc {
-	// WebhookListRetrievalHandler is a request handler that returns a list of Webhooks
+	// WebhookListRetrievalByEventTypeHandler is a request handler that returns the Webhooks an event would be delivered to
 	return func(res http.ResponseWriter, req *http.Request) {
 		eventType := chi.URLParam(req, "event_type")
+		if current, ok := legacyWebhookEvents[eventType]; ok {
+			eventType = current
+		}
 
 		webhooks, err := client.GetWebhooksByEventType(db, eventType)
 		if err != nil {
@@ -56,24 +143,58 @@ func buildWebhookListRetrievalByEventTypeHandler(db *sql.DB, client database.Sto
 	}
 }
 
-func buildWebhookCreationHandler(db *sql.DB, client database.Storer) http.HandlerFunc {
-	// WebhookCreationHandler is a request handler that creates a Webhook from user input
+func buildWebhookCreationHandler(db *sql.DB, client database.Storer, dispatcher *WebhookDispatcher) http.HandlerFunc {
+	// WebhookCreationHandler is a request handler that creates a Webhook from user input. If verification
+	// is requested, the webhook stays pending until its URL echoes back the challenge it's sent.
 	return func(res http.ResponseWriter, req *http.Request) {
-		newWebhook := &models.Webhook{}
-		err := validateRequestInput(req, newWebhook)
+		input := &models.WebhookCreationInput{}
+		err := validateRequestInput(req, input)
+		if err != nil {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
+
+		input.EventTypes = normalizeWebhookEventTypes(input.EventType, input.EventTypes)
+		err = validateWebhookEventTypes(input.EventTypes)
+		if err != nil {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
+
+		err = validateWebhookPayloadOptions(input.ContentType, input.Fields, input.Filter)
 		if err != nil {
 			notifyOfInvalidRequestBody(res, err)
 			return
 		}
 
+		newWebhook := &models.Webhook{
+			URL:         input.URL,
+			EventTypes:  input.EventTypes,
+			ContentType: input.ContentType,
+			Fields:      input.Fields,
+			Filter:      input.Filter,
+			Status:      models.WebhookStatusActive,
+			Secret:      generateWebhookSecret(),
+		}
+		if input.Verify {
+			newWebhook.Status = models.WebhookStatusPending
+		}
+
 		newWebhook.ID, newWebhook.CreatedOn, err = client.CreateWebhook(db, newWebhook)
 		if err != nil {
 			notifyOfInternalIssue(res, err, "insert webhook into database")
 			return
 		}
 
+		if input.Verify {
+			// the webhook exists either way, so a failed handshake just leaves it pending until it's verified again
+			if _, err = verifyWebhook(db, client, dispatcher, newWebhook); err != nil {
+				log.Printf("error verifying webhook %d: %v\n", newWebhook.ID, err)
+			}
+		}
+
 		res.WriteHeader(http.StatusCreated)
-		json.NewEncoder(res).Encode(newWebhook)
+		json.NewEncoder(res).Encode(&models.WebhookSecretResponse{Webhook: *newWebhook, Secret: newWebhook.Secret})
 	}
 }
 
@@ -118,6 +239,16 @@ func buildWebhookUpdateHandler(db *sql.DB, client database.Storer) http.HandlerF
 			return
 		}
 
+		updatedWebhook.EventTypes = normalizeWebhookEventTypes(updatedWebhook.EventType, updatedWebhook.EventTypes)
+		updatedWebhook.EventType = ""
+		if updatedWebhook.EventTypes != nil {
+			err = validateWebhookEventTypes(updatedWebhook.EventTypes)
+			if err != nil {
+				notifyOfInvalidRequestBody(res, err)
+				return
+			}
+		}
+
 		existingWebhook, err := client.GetWebhook(db, webhookID)
 		if err == sql.ErrNoRows {
 			respondThatRowDoesNotExist(req, res, "webhook", webhookIDStr)
@@ -127,8 +258,17 @@ func buildWebhookUpdateHandler(db *sql.DB, client database.Storer) http.HandlerF
 			return
 		}
 
+		// a webhook's status only changes through verification
+		updatedWebhook.Status = ""
 		mergo.Merge(updatedWebhook, existingWebhook)
 
+		// checked after merging, since the content type might not be the one that was sent
+		err = validateWebhookPayloadOptions(updatedWebhook.ContentType, updatedWebhook.Fields, updatedWebhook.Filter)
+		if err != nil {
+			notifyOfInvalidRequestBody(res, err)
+			return
+		}
+
 		updatedOn, err := client.UpdateWebhook(db, updatedWebhook)
 		if err != nil {
 			notifyOfInternalIssue(res, err, "update webhook in database")
@@ -139,3 +279,196 @@ func buildWebhookUpdateHandler(db *sql.DB, client database.Storer) http.HandlerF
 		json.NewEncoder(res).Encode(updatedWebhook)
 	}
 }
+
+func buildWebhookSecretRotationHandler(db *sql.DB, client database.Storer) http.HandlerFunc {
+	// WebhookSecretRotationHandler is a request handler that replaces a webhook's secret with a new one.
+	// The old secret keeps signing deliveries alongside the new one until the grace period is over.
+	return func(res http.ResponseWriter, req *http.Request) {
+		webhookIDStr := chi.URLParam(req, "webhook_id")
+		// eating this error because the router should have ensured this is an integer
+		webhookID, _ := strconv.ParseUint(webhookIDStr, 10, 64)
+
+		webhook, err := client.GetWebhook(db, webhookID)
+		if err == sql.ErrNoRows {
+			respondThatRowDoesNotExist(req, res, "webhook", webhookIDStr)
+			return
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve webhook from database")
+			return
+		}
+
+		newSecret := generateWebhookSecret()
+		rotatedOn, err := client.RotateWebhookSecret(db, webhookID, newSecret)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "rotate webhook secret in database")
+			return
+		}
+		webhook.PreviousSecret = webhook.Secret
+		webhook.Secret = newSecret
+		webhook.SecretRotatedOn = &models.Dairytime{Time: rotatedOn}
+		webhook.UpdatedOn = &models.Dairytime{Time: rotatedOn}
+
+		json.NewEncoder(res).Encode(&models.WebhookSecretResponse{Webhook: *webhook, Secret: webhook.Secret})
+	}
+}
+
+func buildWebhookExecutionListRetrievalHandler(db *sql.DB, client database.Storer) http.HandlerFunc {
+	// WebhookExecutionListRetrievalHandler is a request handler that returns a webhook's execution logs, most recent first
+	return func(res http.ResponseWriter, req *http.Request) {
+		webhookIDStr := chi.URLParam(req, "webhook_id")
+		// eating this error because the router should have ensured this is an integer
+		webhookID, _ := strconv.ParseUint(webhookIDStr, 10, 64)
+
+		exists, err := client.WebhookExists(db, webhookID)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve webhook from database")
+			return
+		} else if !exists {
+			respondThatRowDoesNotExist(req, res, "webhook", webhookIDStr)
+			return
+		}
+
+		rawFilterParams := req.URL.Query()
+		queryFilter := parseRawFilterParams(rawFilterParams)
+
+		count, err := client.GetWebhookExecutionLogCountByWebhookID(db, webhookID, queryFilter)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve count of webhook executions from the database")
+			return
+		}
+
+		executions, err := client.GetWebhookExecutionLogsByWebhookID(db, webhookID, queryFilter)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve webhook executions from the database")
+			return
+		}
+
+		executionsResponse := &ListResponse{
+			Page:  queryFilter.Page,
+			Limit: queryFilter.Limit,
+			Count: count,
+			Data:  executions,
+		}
+		json.NewEncoder(res).Encode(executionsResponse)
+	}
+}
+
+func buildWebhookExecutionReplayHandler(db *sql.DB, client database.Storer) http.HandlerFunc {
+	// WebhookExecutionReplayHandler is a request handler that queues a new delivery of the payload a webhook was sent
+	// in a past execution. The replay gets its own delivery ID, so receivers won't discard it as a duplicate.
+	return func(res http.ResponseWriter, req *http.Request) {
+		webhookIDStr := chi.URLParam(req, "webhook_id")
+		executionIDStr := chi.URLParam(req, "execution_id")
+		// eating these errors because the router should have ensured these are integers
+		webhookID, _ := strconv.ParseUint(webhookIDStr, 10, 64)
+		executionID, _ := strconv.ParseUint(executionIDStr, 10, 64)
+
+		webhook, err := client.GetWebhook(db, webhookID)
+		if err == sql.ErrNoRows {
+			respondThatRowDoesNotExist(req, res, "webhook", webhookIDStr)
+			return
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve webhook from database")
+			return
+		}
+
+		execution, err := client.GetWebhookExecutionLog(db, executionID)
+		if err == sql.ErrNoRows || (err == nil && execution.WebhookID != webhook.ID) {
+			respondThatRowDoesNotExist(req, res, "webhook execution", executionIDStr)
+			return
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve webhook execution from database")
+			return
+		}
+
+		// executions logged before payloads were captured have nothing to replay
+		if execution.EventType == "" {
+			res.WriteHeader(http.StatusUnprocessableEntity)
+			errRes := &ErrorResponse{
+				Status:  http.StatusUnprocessableEntity,
+				Message: "webhook execution was logged without its payload, and can't be replayed",
+			}
+			json.NewEncoder(res).Encode(errRes)
+			return
+		}
+
+		// the payload was encoded for the content type the webhook had at the time
+		contentType := execution.RequestHeaders["Content-Type"]
+		if contentType == "" {
+			contentType = webhook.ContentType
+		}
+
+		delivery := &models.WebhookDelivery{
+			WebhookID:   webhook.ID,
+			EventType:   execution.EventType,
+			ContentType: contentType,
+			Payload:     execution.RequestBody,
+			Status:      models.WebhookDeliveryPending,
+		}
+		delivery.ID, delivery.CreatedOn, err = client.CreateWebhookDelivery(db, delivery)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "queue webhook delivery")
+			return
+		}
+		delivery.NextAttemptOn = delivery.CreatedOn
+
+		res.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(res).Encode(delivery)
+	}
+}
+
+func buildWebhookPingHandler(db *sql.DB, client database.Storer, dispatcher *WebhookDispatcher) http.HandlerFunc {
+	// WebhookPingHandler is a request handler that sends a webhook a test event, and responds with its status code and latency
+	return func(res http.ResponseWriter, req *http.Request) {
+		webhookIDStr := chi.URLParam(req, "webhook_id")
+		// eating this error because the router should have ensured this is an integer
+		webhookID, _ := strconv.ParseUint(webhookIDStr, 10, 64)
+
+		webhook, err := client.GetWebhook(db, webhookID)
+		if err == sql.ErrNoRows {
+			respondThatRowDoesNotExist(req, res, "webhook", webhookIDStr)
+			return
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve webhook from database")
+			return
+		}
+
+		execution, err := dispatcher.Send(webhook, WebhookPingEvent, &webhookPing{WebhookID: webhook.ID, EventTypes: webhook.EventTypes})
+		if err != nil {
+			notifyOfInternalIssue(res, err, "ping webhook")
+			return
+		}
+
+		json.NewEncoder(res).Encode(buildWebhookPingResult(execution))
+	}
+}
+
+func buildWebhookVerificationHandler(db *sql.DB, client database.Storer, dispatcher *WebhookDispatcher) http.HandlerFunc {
+	// WebhookVerificationHandler is a request handler that runs a webhook's verification handshake again
+	return func(res http.ResponseWriter, req *http.Request) {
+		webhookIDStr := chi.URLParam(req, "webhook_id")
+		// eating this error because the router should have ensured this is an integer
+		webhookID, _ := strconv.ParseUint(webhookIDStr, 10, 64)
+
+		webhook, err := client.GetWebhook(db, webhookID)
+		if err == sql.ErrNoRows {
+			respondThatRowDoesNotExist(req, res, "webhook", webhookIDStr)
+			return
+		} else if err != nil {
+			notifyOfInternalIssue(res, err, "retrieve webhook from database")
+			return
+		}
+
+		execution, err := verifyWebhook(db, client, dispatcher, webhook)
+		if err != nil {
+			notifyOfInternalIssue(res, err, "verify webhook")
+			return
+		}
+
+		json.NewEncoder(res).Encode(&models.WebhookVerificationResponse{
+			Webhook:   *webhook,
+			Verified:  webhook.Status == models.WebhookStatusActive,
+			Execution: buildWebhookPingResult(execution),
+		})
+	}
+}
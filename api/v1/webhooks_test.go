@@ -2,15 +2,20 @@ package api
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/dairycart/dairycart/models/v1"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 ////////////////////////////////////////////////////////
@@ -23,7 +28,7 @@ func TestWebhookListByEventTypeHandler(t *testing.T) {
 	exampleWebhook := models.Webhook{
 		ID:          1,
 		URL:         "https://example.com",
-		EventType:   ProductUpdatedWebhookEvent,
+		EventTypes:  []string{ProductUpdatedWebhookEvent},
 		ContentType: "application/json",
 	}
 
@@ -34,9 +39,25 @@ func TestWebhookListByEventTypeHandler(t *testing.T) {
 		config := buildServerConfigFromTestUtil(testUtil)
 		SetupAPIRouter(config)
 
-		req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/webhooks/%s", exampleWebhook.EventType), nil)
+		req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/webhooks/%s", ProductUpdatedWebhookEvent), nil)
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusOK)
+	})
+
+	t.Run("with legacy event name", func(t *testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetWebhooksByEventType", mock.Anything, ProductUpdatedWebhookEvent).
+			Return([]models.Webhook{exampleWebhook}, nil)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodGet, "/v1/webhooks/product_updated", nil)
+		assert.NoError(t, err)
+
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusOK)
 	})
@@ -48,9 +69,10 @@ func TestWebhookListByEventTypeHandler(t *testing.T) {
 		config := buildServerConfigFromTestUtil(testUtil)
 		SetupAPIRouter(config)
 
-		req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/webhooks/%s", exampleWebhook.EventType), nil)
+		req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/webhooks/%s", ProductUpdatedWebhookEvent), nil)
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusInternalServerError)
 	})
@@ -60,7 +82,7 @@ func TestWebhookListHandler(t *testing.T) {
 	exampleWebhook := models.Webhook{
 		ID:          1,
 		URL:         "https://example.com",
-		EventType:   ProductUpdatedWebhookEvent,
+		EventTypes:  []string{ProductUpdatedWebhookEvent},
 		ContentType: "application/json",
 	}
 
@@ -76,6 +98,7 @@ func TestWebhookListHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodGet, "/v1/webhooks", nil)
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusOK)
 	})
@@ -90,6 +113,7 @@ func TestWebhookListHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodGet, "/v1/webhooks", nil)
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusInternalServerError)
 	})
@@ -106,6 +130,7 @@ func TestWebhookListHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodGet, "/v1/webhooks", nil)
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusInternalServerError)
 	})
@@ -115,7 +140,7 @@ func TestWebhookCreationHandler(t *testing.T) {
 	exampleWebhookCreationInput := `
 		{
 			"url": "https://example.com",
-			"event_type": "product_created"
+			"event_types": ["product.created", "discount.*"]
 		}
 	`
 
@@ -129,8 +154,130 @@ func TestWebhookCreationHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodPost, "/v1/webhook", strings.NewReader(exampleWebhookCreationInput))
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusCreated)
+
+		created := testUtil.MockDB.Calls[1].Arguments.Get(1).(*models.Webhook)
+		assert.Len(t, created.Secret, webhookSecretSize)
+		actual := &models.WebhookSecretResponse{}
+		require.NoError(t, json.NewDecoder(testUtil.Response.Body).Decode(actual))
+		assert.Equal(t, created.Secret, actual.Secret, "secret should be returned when the webhook is created")
+		assert.Equal(t, models.WebhookStatusActive, actual.Status)
+	})
+
+	t.Run("with filter and fields", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		hasOptions := func(w *models.Webhook) bool {
+			return w.Filter == `brand == "Acme"` && assert.ObjectsAreEqual([]string{"price", "quantity"}, w.Fields)
+		}
+		testUtil.MockDB.On("CreateWebhook", mock.Anything, mock.MatchedBy(hasOptions)).
+			Return(uint64(1), buildTestTime(), nil)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		exampleInput := `{"url": "https://example.com", "event_types": ["product.updated"], "fields": ["price", "quantity"], "filter": "brand == \"Acme\""}`
+		req, err := http.NewRequest(http.MethodPost, "/v1/webhook", strings.NewReader(exampleInput))
+		assert.NoError(t, err)
+
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusCreated)
+	})
+
+	t.Run("with legacy event type", func(t *testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		hasEventTypes := func(w *models.Webhook) bool {
+			return assert.ObjectsAreEqual([]string{ProductCreatedWebhookEvent}, w.EventTypes)
+		}
+		testUtil.MockDB.On("CreateWebhook", mock.Anything, mock.MatchedBy(hasEventTypes)).
+			Return(uint64(1), buildTestTime(), nil)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		exampleInput := `{"url": "https://example.com", "event_type": "product_created"}`
+		req, err := http.NewRequest(http.MethodPost, "/v1/webhook", strings.NewReader(exampleInput))
+		assert.NoError(t, err)
+
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusCreated)
+		assert.NotContains(t, testUtil.Response.Body.String(), `"event_type"`)
+	})
+
+	t.Run("with invalid filter", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		exampleInput := `{"url": "https://example.com", "event_types": ["product.updated"], "filter": "brand =="}`
+		req, err := http.NewRequest(http.MethodPost, "/v1/webhook", strings.NewReader(exampleInput))
+		assert.NoError(t, err)
+
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusBadRequest)
+		assert.Contains(t, testUtil.Response.Body.String(), "invalid filter")
+	})
+
+	t.Run("with verification", func(*testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+			assert.Equal(t, WebhookVerificationEvent, req.Header.Get("X-Dairycart-Event"))
+			echoWebhookChallenge(t, res, req)
+		}))
+		defer ts.Close()
+
+		testUtil := setupTestVariablesWithMock(t)
+		// webhooks being verified should be created pending
+		isPending := func(w *models.Webhook) bool { return w.Status == models.WebhookStatusPending }
+		testUtil.MockDB.On("CreateWebhook", mock.Anything, mock.MatchedBy(isPending)).
+			Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("CreateWebhookExecutionLog", mock.Anything, mock.Anything).
+			Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("UpdateWebhookStatus", mock.Anything, uint64(1), models.WebhookStatusActive).
+			Return(buildTestTime(), nil)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		exampleInput := fmt.Sprintf(`{"url": %q, "event_types": ["*"], "verify": true}`, ts.URL)
+		req, err := http.NewRequest(http.MethodPost, "/v1/webhook", strings.NewReader(exampleInput))
+		assert.NoError(t, err)
+
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusCreated)
+
+		actual := &models.WebhookSecretResponse{}
+		require.NoError(t, json.NewDecoder(testUtil.Response.Body).Decode(actual))
+		assert.Equal(t, models.WebhookStatusActive, actual.Status)
+	})
+
+	t.Run("with failed verification", func(*testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+			res.Write([]byte("hello?"))
+		}))
+		defer ts.Close()
+
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("CreateWebhook", mock.Anything, mock.Anything).
+			Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("CreateWebhookExecutionLog", mock.Anything, mock.Anything).
+			Return(uint64(1), buildTestTime(), nil)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		exampleInput := fmt.Sprintf(`{"url": %q, "event_types": ["*"], "verify": true}`, ts.URL)
+		req, err := http.NewRequest(http.MethodPost, "/v1/webhook", strings.NewReader(exampleInput))
+		assert.NoError(t, err)
+
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusCreated)
+
+		actual := &models.WebhookSecretResponse{}
+		require.NoError(t, json.NewDecoder(testUtil.Response.Body).Decode(actual))
+		assert.Equal(t, models.WebhookStatusPending, actual.Status)
+		testUtil.MockDB.AssertNotCalled(t, "UpdateWebhookStatus", mock.Anything, mock.Anything, mock.Anything)
 	})
 
 	t.Run("with invalid input", func(*testing.T) {
@@ -141,6 +288,35 @@ func TestWebhookCreationHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodPost, "/v1/webhook", strings.NewReader(exampleGarbageInput))
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusBadRequest)
+	})
+
+	t.Run("with invalid event type", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		exampleInput := `{"url": "https://example.com", "event_types": ["product.exploded"]}`
+		req, err := http.NewRequest(http.MethodPost, "/v1/webhook", strings.NewReader(exampleInput))
+		assert.NoError(t, err)
+
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusBadRequest)
+	})
+
+	t.Run("with no event types", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		exampleInput := `{"url": "https://example.com"}`
+		req, err := http.NewRequest(http.MethodPost, "/v1/webhook", strings.NewReader(exampleInput))
+		assert.NoError(t, err)
+
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusBadRequest)
 	})
@@ -155,6 +331,7 @@ func TestWebhookCreationHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodPost, "/v1/webhook", strings.NewReader(exampleWebhookCreationInput))
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusInternalServerError)
 	})
@@ -164,7 +341,7 @@ func TestWebhookDeletionHandler(t *testing.T) {
 	exampleWebhook := &models.Webhook{
 		ID:          1,
 		URL:         "https://example.com",
-		EventType:   ProductUpdatedWebhookEvent,
+		EventTypes:  []string{ProductUpdatedWebhookEvent},
 		ContentType: "application/json",
 	}
 
@@ -180,6 +357,7 @@ func TestWebhookDeletionHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("/v1/webhook/%d", exampleWebhook.ID), nil)
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusOK)
 	})
@@ -194,6 +372,7 @@ func TestWebhookDeletionHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("/v1/webhook/%d", exampleWebhook.ID), nil)
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusNotFound)
 	})
@@ -208,6 +387,7 @@ func TestWebhookDeletionHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("/v1/webhook/%d", exampleWebhook.ID), nil)
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusInternalServerError)
 	})
@@ -224,6 +404,7 @@ func TestWebhookDeletionHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("/v1/webhook/%d", exampleWebhook.ID), nil)
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusInternalServerError)
 	})
@@ -233,13 +414,13 @@ func TestWebhookUpdateHandler(t *testing.T) {
 	exampleWebhook := &models.Webhook{
 		ID:          1,
 		URL:         "https://example.com",
-		EventType:   ProductUpdatedWebhookEvent,
+		EventTypes:  []string{ProductUpdatedWebhookEvent},
 		ContentType: "application/json",
 	}
 
 	exampleWebhookUpdateInput := `
 		{
-			"event_type": "product_created"
+			"event_types": ["product.created"]
 		}
 	`
 
@@ -255,6 +436,7 @@ func TestWebhookUpdateHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodPatch, "/v1/webhook/1", strings.NewReader(exampleWebhookUpdateInput))
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusOK)
 	})
@@ -267,8 +449,62 @@ func TestWebhookUpdateHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodPatch, "/v1/webhook/1", strings.NewReader(exampleGarbageInput))
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusBadRequest)
+	})
+
+	t.Run("with legacy event type", func(t *testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetWebhook", mock.Anything, exampleWebhook.ID).
+			Return(exampleWebhook, nil)
+		hasEventTypes := func(w *models.Webhook) bool {
+			return assert.ObjectsAreEqual([]string{ProductArchivedWebhookEvent}, w.EventTypes)
+		}
+		testUtil.MockDB.On("UpdateWebhook", mock.Anything, mock.MatchedBy(hasEventTypes)).
+			Return(buildTestTime(), nil)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPatch, "/v1/webhook/1", strings.NewReader(`{"event_type": "product_archived"}`))
+		assert.NoError(t, err)
+
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusOK)
+		assert.NotContains(t, testUtil.Response.Body.String(), `"event_type"`)
+	})
+
+	t.Run("with invalid event type", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPatch, "/v1/webhook/1", strings.NewReader(`{"event_types": ["nonsense"]}`))
+		assert.NoError(t, err)
+
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusBadRequest)
+	})
+
+	t.Run("with fields for an XML webhook", func(*testing.T) {
+		xmlWebhook := *exampleWebhook
+		xmlWebhook.ContentType = "application/xml"
+
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetWebhook", mock.Anything, exampleWebhook.ID).
+			Return(&xmlWebhook, nil)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPatch, "/v1/webhook/1", strings.NewReader(`{"fields": ["price"]}`))
+		assert.NoError(t, err)
+
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusBadRequest)
+		testUtil.MockDB.AssertNotCalled(t, "UpdateWebhook", mock.Anything, mock.Anything)
 	})
 
 	t.Run("with nonexistent error", func(*testing.T) {
@@ -281,6 +517,7 @@ func TestWebhookUpdateHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodPatch, "/v1/webhook/1", strings.NewReader(exampleWebhookUpdateInput))
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusNotFound)
 	})
@@ -295,6 +532,7 @@ func TestWebhookUpdateHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodPatch, "/v1/webhook/1", strings.NewReader(exampleWebhookUpdateInput))
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusInternalServerError)
 	})
@@ -311,6 +549,607 @@ func TestWebhookUpdateHandler(t *testing.T) {
 		req, err := http.NewRequest(http.MethodPatch, "/v1/webhook/1", strings.NewReader(exampleWebhookUpdateInput))
 		assert.NoError(t, err)
 
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+	})
+}
+
+func TestWebhookSecretRotationHandler(t *testing.T) {
+	exampleWebhook := &models.Webhook{
+		ID:          1,
+		URL:         "https://example.com",
+		EventTypes:  []string{ProductUpdatedWebhookEvent},
+		ContentType: "application/json",
+		Secret:      "old secret",
+	}
+	exampleRoute := fmt.Sprintf("/v1/webhook/%d/secret", exampleWebhook.ID)
+
+	t.Run("optimal conditions", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetWebhook", mock.Anything, exampleWebhook.ID).
+			Return(exampleWebhook, nil)
+		testUtil.MockDB.On("RotateWebhookSecret", mock.Anything, exampleWebhook.ID, mock.AnythingOfType("string")).
+			Return(buildTestTime(), nil)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPost, exampleRoute, nil)
+		assert.NoError(t, err)
+
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusOK)
+
+		newSecret := testUtil.MockDB.Calls[2].Arguments.String(2)
+		assert.Len(t, newSecret, webhookSecretSize)
+		assert.NotContains(t, testUtil.Response.Body.String(), "old secret", "previous secret should never be returned")
+		actual := &models.WebhookSecretResponse{}
+		require.NoError(t, json.NewDecoder(testUtil.Response.Body).Decode(actual))
+		assert.Equal(t, newSecret, actual.Secret)
+	})
+
+	t.Run("with nonexistent webhook", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetWebhook", mock.Anything, exampleWebhook.ID).
+			Return(exampleWebhook, sql.ErrNoRows)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPost, exampleRoute, nil)
+		assert.NoError(t, err)
+
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusNotFound)
+	})
+
+	t.Run("with error retrieving webhook", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetWebhook", mock.Anything, exampleWebhook.ID).
+			Return(exampleWebhook, generateArbitraryError())
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPost, exampleRoute, nil)
+		assert.NoError(t, err)
+
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+	})
+
+	t.Run("with error rotating secret", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetWebhook", mock.Anything, exampleWebhook.ID).
+			Return(exampleWebhook, nil)
+		testUtil.MockDB.On("RotateWebhookSecret", mock.Anything, exampleWebhook.ID, mock.AnythingOfType("string")).
+			Return(buildTestTime(), generateArbitraryError())
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPost, exampleRoute, nil)
+		assert.NoError(t, err)
+
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+	})
+}
+
+func TestWebhookSigningSecrets(t *testing.T) {
+	t.Parallel()
+	now := buildTestTime()
+
+	assert.Empty(t, webhookSigningSecrets(&models.Webhook{}, now), "webhooks without a secret shouldn't be signed")
+	assert.Equal(t, []string{"new"}, webhookSigningSecrets(&models.Webhook{Secret: "new"}, now))
+
+	recentlyRotated := &models.Webhook{
+		Secret:          "new",
+		PreviousSecret:  "old",
+		SecretRotatedOn: &models.Dairytime{Time: now.Add(-time.Hour)},
+	}
+	assert.Equal(t, []string{"new", "old"}, webhookSigningSecrets(recentlyRotated, now))
+
+	rotatedLongAgo := &models.Webhook{
+		Secret:          "new",
+		PreviousSecret:  "old",
+		SecretRotatedOn: &models.Dairytime{Time: now.Add(-webhookSecretRotationGracePeriod)},
+	}
+	assert.Equal(t, []string{"new"}, webhookSigningSecrets(rotatedLongAgo, now))
+}
+
+func TestWebhookExecutionListRetrievalHandler(t *testing.T) {
+	exampleWebhookID := uint64(1)
+	exampleRoute := fmt.Sprintf("/v1/webhook/%d/executions?page=2", exampleWebhookID)
+	exampleExecution := models.WebhookExecutionLog{
+		ID:           2,
+		WebhookID:    exampleWebhookID,
+		EventType:    ProductUpdatedWebhookEvent,
+		Attempt:      1,
+		RequestBody:  `{"id":1}`,
+		StatusCode:   http.StatusOK,
+		ResponseBody: "the secret admin page",
+		Succeeded:    true,
+	}
+
+	t.Run("optimal conditions", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("WebhookExists", mock.Anything, exampleWebhookID).
+			Return(true, nil)
+		testUtil.MockDB.On("GetWebhookExecutionLogCountByWebhookID", mock.Anything, exampleWebhookID, mock.Anything).
+			Return(uint64(26), nil)
+		testUtil.MockDB.On("GetWebhookExecutionLogsByWebhookID", mock.Anything, exampleWebhookID, mock.Anything).
+			Return([]models.WebhookExecutionLog{exampleExecution}, nil)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodGet, exampleRoute, nil)
+		assert.NoError(t, err)
+
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusOK)
+
+		qf := testUtil.MockDB.Calls[3].Arguments.Get(2).(*models.QueryFilter)
+		assert.Equal(t, uint64(2), qf.Page)
+		assert.Contains(t, testUtil.Response.Body.String(), `"count":26`)
+		assert.Contains(t, testUtil.Response.Body.String(), `"request_body":"{\"id\":1}"`)
+		assert.NotContains(t, testUtil.Response.Body.String(), "the secret admin page", "receiver's response body should never be returned")
+	})
+
+	t.Run("with nonexistent webhook", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("WebhookExists", mock.Anything, exampleWebhookID).
+			Return(false, nil)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodGet, exampleRoute, nil)
+		assert.NoError(t, err)
+
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusNotFound)
+	})
+
+	t.Run("with error checking for webhook", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("WebhookExists", mock.Anything, exampleWebhookID).
+			Return(false, generateArbitraryError())
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodGet, exampleRoute, nil)
+		assert.NoError(t, err)
+
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+	})
+
+	t.Run("with error retrieving execution count", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("WebhookExists", mock.Anything, exampleWebhookID).
+			Return(true, nil)
+		testUtil.MockDB.On("GetWebhookExecutionLogCountByWebhookID", mock.Anything, exampleWebhookID, mock.Anything).
+			Return(uint64(0), generateArbitraryError())
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodGet, exampleRoute, nil)
+		assert.NoError(t, err)
+
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+	})
+
+	t.Run("with error retrieving executions", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("WebhookExists", mock.Anything, exampleWebhookID).
+			Return(true, nil)
+		testUtil.MockDB.On("GetWebhookExecutionLogCountByWebhookID", mock.Anything, exampleWebhookID, mock.Anything).
+			Return(uint64(26), nil)
+		testUtil.MockDB.On("GetWebhookExecutionLogsByWebhookID", mock.Anything, exampleWebhookID, mock.Anything).
+			Return([]models.WebhookExecutionLog{}, generateArbitraryError())
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodGet, exampleRoute, nil)
+		assert.NoError(t, err)
+
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+	})
+}
+
+func TestWebhookExecutionReplayHandler(t *testing.T) {
+	exampleWebhook := &models.Webhook{
+		ID:          1,
+		URL:         "https://example.com",
+		EventTypes:  []string{ProductUpdatedWebhookEvent},
+		ContentType: "application/json",
+	}
+	buildExampleExecution := func() *models.WebhookExecutionLog {
+		return &models.WebhookExecutionLog{
+			ID:             2,
+			WebhookID:      exampleWebhook.ID,
+			EventType:      ProductUpdatedWebhookEvent,
+			RequestHeaders: models.WebhookHeaders{"Content-Type": "application/xml"},
+			RequestBody:    `<Product><id>1</id></Product>`,
+		}
+	}
+	exampleRoute := fmt.Sprintf("/v1/webhook/%d/executions/%d/replay", exampleWebhook.ID, 2)
+
+	t.Run("optimal conditions", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetWebhook", mock.Anything, exampleWebhook.ID).
+			Return(exampleWebhook, nil)
+		testUtil.MockDB.On("GetWebhookExecutionLog", mock.Anything, uint64(2)).
+			Return(buildExampleExecution(), nil)
+		testUtil.MockDB.On("CreateWebhookDelivery", mock.Anything, mock.Anything).
+			Return(uint64(3), buildTestTime(), nil)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPost, exampleRoute, nil)
+		assert.NoError(t, err)
+
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusAccepted)
+
+		delivery := testUtil.MockDB.Calls[3].Arguments.Get(1).(*models.WebhookDelivery)
+		assert.Equal(t, exampleWebhook.ID, delivery.WebhookID)
+		assert.Equal(t, ProductUpdatedWebhookEvent, delivery.EventType)
+		assert.Equal(t, "application/xml", delivery.ContentType, "replay should use the content type the payload was encoded with")
+		assert.Equal(t, `<Product><id>1</id></Product>`, delivery.Payload)
+
+		actual := &models.WebhookDelivery{}
+		require.NoError(t, json.NewDecoder(testUtil.Response.Body).Decode(actual))
+		assert.Equal(t, uint64(3), actual.ID)
+		assert.Equal(t, models.WebhookDeliveryPending, actual.Status)
+	})
+
+	t.Run("with nonexistent webhook", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetWebhook", mock.Anything, exampleWebhook.ID).
+			Return(exampleWebhook, sql.ErrNoRows)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPost, exampleRoute, nil)
+		assert.NoError(t, err)
+
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusNotFound)
+	})
+
+	t.Run("with error retrieving webhook", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetWebhook", mock.Anything, exampleWebhook.ID).
+			Return(exampleWebhook, generateArbitraryError())
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPost, exampleRoute, nil)
+		assert.NoError(t, err)
+
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+	})
+
+	t.Run("with nonexistent execution", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetWebhook", mock.Anything, exampleWebhook.ID).
+			Return(exampleWebhook, nil)
+		testUtil.MockDB.On("GetWebhookExecutionLog", mock.Anything, uint64(2)).
+			Return(buildExampleExecution(), sql.ErrNoRows)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPost, exampleRoute, nil)
+		assert.NoError(t, err)
+
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusNotFound)
+	})
+
+	t.Run("with execution belonging to another webhook", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		execution := buildExampleExecution()
+		execution.WebhookID = 7
+		testUtil.MockDB.On("GetWebhook", mock.Anything, exampleWebhook.ID).
+			Return(exampleWebhook, nil)
+		testUtil.MockDB.On("GetWebhookExecutionLog", mock.Anything, uint64(2)).
+			Return(execution, nil)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPost, exampleRoute, nil)
+		assert.NoError(t, err)
+
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusNotFound)
+	})
+
+	t.Run("with error retrieving execution", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetWebhook", mock.Anything, exampleWebhook.ID).
+			Return(exampleWebhook, nil)
+		testUtil.MockDB.On("GetWebhookExecutionLog", mock.Anything, uint64(2)).
+			Return(buildExampleExecution(), generateArbitraryError())
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPost, exampleRoute, nil)
+		assert.NoError(t, err)
+
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+	})
+
+	t.Run("with execution logged without its payload", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetWebhook", mock.Anything, exampleWebhook.ID).
+			Return(exampleWebhook, nil)
+		testUtil.MockDB.On("GetWebhookExecutionLog", mock.Anything, uint64(2)).
+			Return(&models.WebhookExecutionLog{ID: 2, WebhookID: exampleWebhook.ID}, nil)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPost, exampleRoute, nil)
+		assert.NoError(t, err)
+
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusUnprocessableEntity)
+	})
+
+	t.Run("with error queueing delivery", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetWebhook", mock.Anything, exampleWebhook.ID).
+			Return(exampleWebhook, nil)
+		testUtil.MockDB.On("GetWebhookExecutionLog", mock.Anything, uint64(2)).
+			Return(buildExampleExecution(), nil)
+		testUtil.MockDB.On("CreateWebhookDelivery", mock.Anything, mock.Anything).
+			Return(uint64(0), buildTestTime(), generateArbitraryError())
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPost, exampleRoute, nil)
+		assert.NoError(t, err)
+
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+	})
+}
+
+func TestChallengeWasEchoed(t *testing.T) {
+	assert.True(t, challengeWasEchoed("challenge", "challenge"))
+	assert.True(t, challengeWasEchoed("challenge\n", "challenge"))
+	assert.True(t, challengeWasEchoed(`{"challenge": "challenge"}`, "challenge"))
+	assert.False(t, challengeWasEchoed("", "challenge"))
+	assert.False(t, challengeWasEchoed(`{"challenge": "something else"}`, "challenge"))
+	assert.False(t, challengeWasEchoed("ok", "challenge"))
+	assert.False(t, challengeWasEchoed(`{"event": "webhook.verification", "data": {"challenge": "challenge"}}`, "challenge"), "echoing the whole event shouldn't count")
+}
+
+func TestWebhookPingHandler(t *testing.T) {
+	exampleRoute := "/v1/webhook/1/ping"
+
+	t.Run("optimal conditions", func(*testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+			assert.Equal(t, WebhookPingEvent, req.Header.Get("X-Dairycart-Event"))
+			res.WriteHeader(http.StatusTeapot)
+			res.Write([]byte("short and stout"))
+		}))
+		defer ts.Close()
+
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetWebhook", mock.Anything, uint64(1)).
+			Return(&models.Webhook{ID: 1, URL: ts.URL, EventTypes: []string{AllWebhookEvents}}, nil)
+		testUtil.MockDB.On("CreateWebhookExecutionLog", mock.Anything, mock.Anything).
+			Return(uint64(1), buildTestTime(), nil)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPost, exampleRoute, nil)
+		assert.NoError(t, err)
+
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusOK)
+
+		assert.NotContains(t, testUtil.Response.Body.String(), "short and stout", "receiver's response body should never be returned")
+		actual := &models.WebhookPingResult{}
+		require.NoError(t, json.NewDecoder(testUtil.Response.Body).Decode(actual))
+		assert.Equal(t, http.StatusTeapot, actual.StatusCode, "receiver's status code should be returned inline")
+		assert.False(t, actual.Succeeded)
+
+		sent := testUtil.MockDB.Calls[2].Arguments.Get(1).(*models.WebhookExecutionLog)
+		assert.Contains(t, sent.RequestBody, `"data":{"webhook_id":1,"event_types":["*"]}`)
+	})
+
+	t.Run("with nonexistent webhook", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetWebhook", mock.Anything, uint64(1)).
+			Return(&models.Webhook{}, sql.ErrNoRows)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPost, exampleRoute, nil)
+		assert.NoError(t, err)
+
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusNotFound)
+	})
+
+	t.Run("with error retrieving webhook", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetWebhook", mock.Anything, uint64(1)).
+			Return(&models.Webhook{}, generateArbitraryError())
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPost, exampleRoute, nil)
+		assert.NoError(t, err)
+
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+	})
+}
+
+// echoWebhookChallenge responds to a verification handshake the way a receiver is supposed to
+func echoWebhookChallenge(t *testing.T, res http.ResponseWriter, req *http.Request) {
+	t.Helper()
+	challenge := &webhookVerificationChallenge{}
+	require.NoError(t, json.NewDecoder(req.Body).Decode(&models.WebhookEventEnvelope{Data: challenge}))
+	json.NewEncoder(res).Encode(challenge)
+}
+
+func TestWebhookVerificationHandler(t *testing.T) {
+	exampleRoute := "/v1/webhook/1/verify"
+	echoServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		echoWebhookChallenge(t, res, req)
+	}))
+	defer echoServer.Close()
+
+	t.Run("optimal conditions", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetWebhook", mock.Anything, uint64(1)).
+			Return(&models.Webhook{ID: 1, URL: echoServer.URL, Status: models.WebhookStatusPending}, nil)
+		testUtil.MockDB.On("CreateWebhookExecutionLog", mock.Anything, mock.Anything).
+			Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("UpdateWebhookStatus", mock.Anything, uint64(1), models.WebhookStatusActive).
+			Return(buildTestTime(), nil)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPost, exampleRoute, nil)
+		assert.NoError(t, err)
+
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusOK)
+
+		actual := &models.WebhookVerificationResponse{}
+		require.NoError(t, json.NewDecoder(testUtil.Response.Body).Decode(actual))
+		assert.True(t, actual.Verified)
+		assert.Equal(t, models.WebhookStatusActive, actual.Status)
+		require.NotNil(t, actual.Execution)
+		assert.True(t, actual.Execution.Succeeded)
+		assert.NotContains(t, testUtil.Response.Body.String(), "challenge", "receiver's response body should never be returned")
+	})
+
+	t.Run("without challenge being echoed", func(*testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {}))
+		defer ts.Close()
+
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetWebhook", mock.Anything, uint64(1)).
+			Return(&models.Webhook{ID: 1, URL: ts.URL, Status: models.WebhookStatusPending}, nil)
+		testUtil.MockDB.On("CreateWebhookExecutionLog", mock.Anything, mock.Anything).
+			Return(uint64(1), buildTestTime(), nil)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPost, exampleRoute, nil)
+		assert.NoError(t, err)
+
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusOK)
+
+		actual := &models.WebhookVerificationResponse{}
+		require.NoError(t, json.NewDecoder(testUtil.Response.Body).Decode(actual))
+		assert.False(t, actual.Verified)
+		assert.Equal(t, models.WebhookStatusPending, actual.Status)
+	})
+
+	t.Run("with whole event being echoed", func(*testing.T) {
+		// anything that reflects what it's sent would look like it was listening
+		ts := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+			io.Copy(res, req.Body)
+		}))
+		defer ts.Close()
+
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetWebhook", mock.Anything, uint64(1)).
+			Return(&models.Webhook{ID: 1, URL: ts.URL, Status: models.WebhookStatusPending}, nil)
+		testUtil.MockDB.On("CreateWebhookExecutionLog", mock.Anything, mock.Anything).
+			Return(uint64(1), buildTestTime(), nil)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPost, exampleRoute, nil)
+		assert.NoError(t, err)
+
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusOK)
+
+		actual := &models.WebhookVerificationResponse{}
+		require.NoError(t, json.NewDecoder(testUtil.Response.Body).Decode(actual))
+		assert.False(t, actual.Verified)
+		testUtil.MockDB.AssertNotCalled(t, "UpdateWebhookStatus", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("with nonexistent webhook", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetWebhook", mock.Anything, uint64(1)).
+			Return(&models.Webhook{}, sql.ErrNoRows)
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPost, exampleRoute, nil)
+		assert.NoError(t, err)
+
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusNotFound)
+	})
+
+	t.Run("with error retrieving webhook", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetWebhook", mock.Anything, uint64(1)).
+			Return(&models.Webhook{}, generateArbitraryError())
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPost, exampleRoute, nil)
+		assert.NoError(t, err)
+
+		attachAdminCookieToRequest(t, testUtil, req)
+		testUtil.Router.ServeHTTP(testUtil.Response, req)
+		assertStatusCode(t, testUtil, http.StatusInternalServerError)
+	})
+
+	t.Run("with error updating status", func(*testing.T) {
+		testUtil := setupTestVariablesWithMock(t)
+		testUtil.MockDB.On("GetWebhook", mock.Anything, uint64(1)).
+			Return(&models.Webhook{ID: 1, URL: echoServer.URL, Status: models.WebhookStatusPending}, nil)
+		testUtil.MockDB.On("CreateWebhookExecutionLog", mock.Anything, mock.Anything).
+			Return(uint64(1), buildTestTime(), nil)
+		testUtil.MockDB.On("UpdateWebhookStatus", mock.Anything, uint64(1), models.WebhookStatusActive).
+			Return(buildTestTime(), generateArbitraryError())
+		config := buildServerConfigFromTestUtil(testUtil)
+		SetupAPIRouter(config)
+
+		req, err := http.NewRequest(http.MethodPost, exampleRoute, nil)
+		assert.NoError(t, err)
+
+		attachAdminCookieToRequest(t, testUtil, req)
 		testUtil.Router.ServeHTTP(testUtil.Response, req)
 		assertStatusCode(t, testUtil, http.StatusInternalServerError)
 	})
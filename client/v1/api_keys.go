@@ -0,0 +1,44 @@
+package dairyclient
+
+import (
+	"github.com/dairycart/dairycart/models/v1"
+)
+
+////////////////////////////////////////////////////////
+//                                                    //
+//                 API Key Functions                  //
+//                                                    //
+////////////////////////////////////////////////////////
+
+// GetAPIKeys returns the API keys that belong to the client's user. Revoked keys aren't included.
+func (dc *V1Client) GetAPIKeys() ([]models.APIKey, error) {
+	u := dc.buildURL(nil, "api_keys")
+	k := &models.APIKeyListResponse{}
+
+	err := dc.get(u, k)
+	if err != nil {
+		return nil, err
+	}
+	return k.APIKeys, nil
+}
+
+// CreateAPIKey creates an API key for the client's user. The key itself is only ever returned here, so it has
+// to be kept somewhere safe.
+func (dc *V1Client) CreateAPIKey(nk models.APIKeyCreationInput) (*models.APIKeyCreationResponse, error) {
+	k := models.APIKeyCreationResponse{}
+	u := dc.buildURL(nil, "api_keys")
+
+	err := dc.post(u, nk, &k)
+	if err != nil {
+		return nil, err
+	}
+
+	return &k, nil
+}
+
+// DeleteAPIKey revokes one of the client's user's API keys
+func (dc *V1Client) DeleteAPIKey(apiKeyID uint64) error {
+	apiKeyIDString := convertIDToString(apiKeyID)
+	u := dc.buildURL(nil, "api_keys", apiKeyIDString)
+	return dc.delete(u)
+}
@@ -0,0 +1,139 @@
+package dairyclient_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dairycart/dairycart/models/v1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func buildNotFoundAPIKeyResponse(id uint64) string {
+	return fmt.Sprintf(`
+		{
+			"status": 404,
+			"message": "The API key you were looking for (id '%d') does not exist"
+		}
+	`, id)
+}
+
+func TestGetAPIKeys(t *testing.T) {
+	exampleGoodResponse := loadExampleResponse(t, "api_keys")
+
+	t.Run("normal usage", func(*testing.T) {
+		expected := []models.APIKey{
+			{
+				ID:         1,
+				UserID:     1,
+				Name:       "deploys",
+				Prefix:     "dc_abcdefgh",
+				LastUsedOn: buildTestDairytime(t),
+				CreatedOn:  buildTestTime(t),
+			},
+			{
+				ID:        2,
+				UserID:    1,
+				Name:      "reporting",
+				Prefix:    "dc_ijklmnop",
+				ExpiresOn: buildTestDairytime(t),
+				CreatedOn: buildTestTime(t),
+			},
+		}
+
+		handlers := map[string]http.HandlerFunc{
+			"/v1/api_keys": generateGetHandler(t, exampleGoodResponse, http.StatusOK),
+		}
+		ts := httptest.NewTLSServer(handlerGenerator(handlers))
+		defer ts.Close()
+		c := buildTestClient(t, ts)
+
+		actual, err := c.GetAPIKeys()
+
+		assert.Nil(t, err)
+		assert.Equal(t, expected, actual, "expected API key list doesn't match actual API key list")
+	})
+
+	t.Run("with bad server response", func(*testing.T) {
+		handlers := map[string]http.HandlerFunc{
+			"/v1/api_keys": generateGetHandler(t, exampleBadJSON, http.StatusOK),
+		}
+		ts := httptest.NewTLSServer(handlerGenerator(handlers))
+		defer ts.Close()
+		c := buildTestClient(t, ts)
+
+		_, err := c.GetAPIKeys()
+		assert.NotNil(t, err, "GetAPIKeys should return an error when it receives nonsense")
+	})
+}
+
+func TestCreateAPIKey(t *testing.T) {
+	exampleResponseJSON := loadExampleResponse(t, "created_api_key")
+	expectedBody := `
+		{
+			"name": "deploys"
+		}
+	`
+	exampleInput := models.APIKeyCreationInput{
+		Name: "deploys",
+	}
+
+	t.Run("normal operation", func(*testing.T) {
+		handlers := map[string]http.HandlerFunc{"/v1/api_keys": generatePostHandler(t, expectedBody, exampleResponseJSON, http.StatusCreated)}
+		ts := httptest.NewTLSServer(handlerGenerator(handlers))
+		defer ts.Close()
+		c := buildTestClient(t, ts)
+
+		expected := &models.APIKeyCreationResponse{
+			APIKey: models.APIKey{
+				ID:        1,
+				UserID:    1,
+				Name:      "deploys",
+				Prefix:    "dc_abcdefgh",
+				CreatedOn: buildTestTime(t),
+			},
+			Key: "dc_abcdefgh_abcdefghijklmnopqrstuvwxyz123456",
+		}
+
+		actual, err := c.CreateAPIKey(exampleInput)
+		require.Nil(t, err)
+		assert.Equal(t, expected, actual, "expected API key doesn't match actual API key")
+	})
+
+	t.Run("with bad server response", func(*testing.T) {
+		handlers := map[string]http.HandlerFunc{"/v1/api_keys": generatePostHandler(t, expectedBody, exampleBadJSON, http.StatusCreated)}
+		ts := httptest.NewTLSServer(handlerGenerator(handlers))
+		defer ts.Close()
+		c := buildTestClient(t, ts)
+
+		_, err := c.CreateAPIKey(exampleInput)
+		assert.NotNil(t, err, "CreateAPIKey should return an error when it receives nonsense")
+	})
+}
+
+func TestDeleteAPIKey(t *testing.T) {
+	existentID, nonexistentID := uint64(1), uint64(2)
+	exampleResponseJSON := loadExampleResponse(t, "deleted_api_key")
+
+	handlers := map[string]http.HandlerFunc{
+		fmt.Sprintf("/v1/api_keys/%d", existentID):    generateDeleteHandler(t, exampleResponseJSON, http.StatusOK),
+		fmt.Sprintf("/v1/api_keys/%d", nonexistentID): generateDeleteHandler(t, buildNotFoundAPIKeyResponse(nonexistentID), http.StatusNotFound),
+	}
+
+	ts := httptest.NewTLSServer(handlerGenerator(handlers))
+	defer ts.Close()
+	c := buildTestClient(t, ts)
+
+	t.Run("with existent API key", func(*testing.T) {
+		err := c.DeleteAPIKey(existentID)
+		assert.Nil(t, err)
+	})
+
+	t.Run("with nonexistent API key", func(*testing.T) {
+		err := c.DeleteAPIKey(nonexistentID)
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,34 @@
+package dairyclient
+
+import (
+	"strconv"
+
+	"github.com/dairycart/dairycart/models/v1"
+)
+
+////////////////////////////////////////////////////////
+//                                                    //
+//               Change Feed Functions                //
+//                                                    //
+////////////////////////////////////////////////////////
+
+// GetChanges returns the page of the change feed that comes after since, which is the NextToken of the last page
+// that was read, or empty to start from the beginning. limit is the most changes to return, or zero for the
+// server's default. Keep calling it with the NextToken of each page until HasMore is false.
+func (dc *V1Client) GetChanges(since string, limit uint) (*models.ChangeFeedResponse, error) {
+	queryParams := map[string]string{}
+	if since != "" {
+		queryParams["since"] = since
+	}
+	if limit > 0 {
+		queryParams["limit"] = strconv.FormatUint(uint64(limit), 10)
+	}
+	u := dc.buildURL(queryParams, "changes")
+	feed := &models.ChangeFeedResponse{}
+
+	err := dc.get(u, feed)
+	if err != nil {
+		return nil, err
+	}
+	return feed, nil
+}
@@ -0,0 +1,68 @@
+package dairyclient_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dairycart/dairycart/models/v1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetChanges(t *testing.T) {
+	exampleGoodResponse := loadExampleResponse(t, "changes")
+
+	t.Run("normal usage", func(*testing.T) {
+		var query string
+		handlers := map[string]http.HandlerFunc{
+			"/v1/changes": func(res http.ResponseWriter, req *http.Request) {
+				query = req.URL.RawQuery
+				generateGetHandler(t, exampleGoodResponse, http.StatusOK)(res, req)
+			},
+		}
+		ts := httptest.NewTLSServer(handlerGenerator(handlers))
+		c := buildTestClient(t, ts)
+
+		actual, err := c.GetChanges("3", 2)
+
+		require.Nil(t, err)
+		assert.Equal(t, "limit=2&since=3", query)
+		assert.Equal(t, "5", actual.NextToken)
+		assert.True(t, actual.HasMore)
+		require.Len(t, actual.Changes, 2)
+		assert.Equal(t, models.ChangeUpdated, actual.Changes[0].Operation)
+		assert.JSONEq(t, `{"id": 1, "sku": "skateboard"}`, string(actual.Changes[0].Data))
+		assert.Equal(t, models.ChangeArchived, actual.Changes[1].Operation)
+		assert.Equal(t, json.RawMessage("null"), actual.Changes[1].Data)
+	})
+
+	t.Run("from the beginning", func(*testing.T) {
+		var query string
+		handlers := map[string]http.HandlerFunc{
+			"/v1/changes": func(res http.ResponseWriter, req *http.Request) {
+				query = req.URL.RawQuery
+				generateGetHandler(t, exampleGoodResponse, http.StatusOK)(res, req)
+			},
+		}
+		ts := httptest.NewTLSServer(handlerGenerator(handlers))
+		c := buildTestClient(t, ts)
+
+		_, err := c.GetChanges("", 0)
+		assert.Nil(t, err)
+		assert.Empty(t, query)
+	})
+
+	t.Run("with bad server response", func(*testing.T) {
+		handlers := map[string]http.HandlerFunc{
+			"/v1/changes": generateGetHandler(t, exampleBadJSON, http.StatusOK),
+		}
+		ts := httptest.NewTLSServer(handlerGenerator(handlers))
+		c := buildTestClient(t, ts)
+
+		_, err := c.GetChanges("", 0)
+		assert.NotNil(t, err, "GetChanges should return an error when it receives nonsense")
+	})
+}
@@ -16,6 +16,8 @@ type V1Client struct {
 	*http.Client
 	URL        *url.URL
 	AuthCookie *http.Cookie
+	// APIKey is used instead of AuthCookie when it's set
+	APIKey string
 }
 
 func NewV1Client(storeURL string, username string, password string, client *http.Client) (*V1Client, error) {
@@ -75,8 +77,32 @@ func NewV1ClientFromCookie(apiURL string, cookie *http.Cookie, client *http.Clie
 	return dc, nil
 }
 
+// NewV1ClientFromAPIKey builds a client that authenticates with an API key rather than logging in. A default
+// HTTP client is used if client is nil.
+func NewV1ClientFromAPIKey(apiURL string, apiKey string, client *http.Client) (*V1Client, error) {
+	if client == nil {
+		client = &http.Client{}
+	}
+	dc := &V1Client{Client: client}
+
+	u, err := url.Parse(apiURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "API URL is not valid")
+	}
+	dc.URL = u
+
+	dc.APIKey = apiKey
+	dc.Client.Timeout = 5 * time.Second
+
+	return dc, nil
+}
+
 func (dc *V1Client) executeRequest(req *http.Request) (*http.Response, error) {
-	req.AddCookie(dc.AuthCookie)
+	if dc.APIKey != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", dc.APIKey))
+	} else {
+		req.AddCookie(dc.AuthCookie)
+	}
 	return dc.Do(req)
 }
 
@@ -93,6 +93,30 @@ func TestExecuteRequestAddsCookieToRequests(t *testing.T) {
 	assert.True(t, endpointCalled, "endpoint should have been called")
 }
 
+func TestExecuteRequestUsesAPIKeyWhenSet(t *testing.T) {
+	var endpointCalled bool
+	exampleEndpoint := "/v1/whatever"
+
+	handlers := map[string]func(res http.ResponseWriter, req *http.Request){
+		exampleEndpoint: func(res http.ResponseWriter, req *http.Request) {
+			endpointCalled = true
+			assert.Equal(t, "Bearer example", req.Header.Get("Authorization"))
+			assert.Empty(t, req.Cookies(), "cookies shouldn't be sent with API keys")
+		},
+	}
+
+	ts := httptest.NewTLSServer(handlerGenerator(handlers))
+	defer ts.Close()
+	c := createInternalClient(t, ts)
+	c.APIKey = "example"
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s%s", ts.URL, exampleEndpoint), nil)
+	assert.Nil(t, err, "no error should be returned when creating a new request")
+
+	c.executeRequest(req)
+	assert.True(t, endpointCalled, "endpoint should have been called")
+}
+
 func TestUnexportedBuildURL(t *testing.T) {
 	ts := httptest.NewTLSServer(http.NotFoundHandler())
 	defer ts.Close()
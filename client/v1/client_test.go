@@ -69,6 +69,21 @@ func TestNewV1ClientFromCookie(t *testing.T) {
 	})
 }
 
+func TestNewV1ClientFromAPIKey(t *testing.T) {
+
+	t.Run("normal use", func(*testing.T) {
+		c, err := dairyclient.NewV1ClientFromAPIKey(exampleURL, "example", nil)
+		assert.Nil(t, err)
+		assert.Equal(t, "example", c.APIKey)
+		assert.NotNil(t, c.Client)
+	})
+
+	t.Run("with invalid URL", func(*testing.T) {
+		_, err := dairyclient.NewV1ClientFromAPIKey(":", "example", http.DefaultClient)
+		assert.NotNil(t, err)
+	})
+}
+
 func TestBuildURL(t *testing.T) {
 
 	ts := httptest.NewTLSServer(http.NotFoundHandler())
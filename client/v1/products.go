@@ -98,6 +98,19 @@ func (dc *V1Client) GetProductRoots(queryFilter map[string]string) ([]models.Pro
 	return rl.ProductRoots, nil
 }
 
+func (dc *V1Client) UpdateProductRoot(rootID uint64, ur models.ProductRootUpdateInput) (*models.ProductRoot, error) {
+	rootIDString := convertIDToString(rootID)
+	u := dc.buildURL(nil, "product_root", rootIDString)
+	r := models.ProductRoot{}
+
+	err := dc.patch(u, ur, &r)
+	if err != nil {
+		return nil, err
+	}
+
+	return &r, nil
+}
+
 func (dc *V1Client) DeleteProductRoot(rootID uint64) error {
 	rootIDString := convertIDToString(rootID)
 	u := dc.buildURL(nil, "product_root", rootIDString)
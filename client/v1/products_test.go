@@ -814,6 +814,42 @@ func TestGetProductRoot(t *testing.T) {
 	})
 }
 
+func TestUpdateProductRoot(t *testing.T) {
+	exampleInput := models.ProductRootUpdateInput{
+		SKUTemplate:           "{{ .SKU }}{{ range .Options }}-{{ upper .Value }}{{ end }}",
+		OptionSummaryTemplate: "{{ range .Options }}{{ .Value }} {{ end }}",
+	}
+
+	var endpointCalled bool
+	handlers := map[string]http.HandlerFunc{
+		"/v1/product_root/1": func(res http.ResponseWriter, req *http.Request) {
+			endpointCalled = true
+			assert.Equal(t, req.Method, http.MethodPatch, "UpdateProductRoot should only be making PATCH requests")
+
+			bodyBytes, err := ioutil.ReadAll(req.Body)
+			assert.Nil(t, err)
+			expected := `
+				{
+					"sku_template": "{{ .SKU }}{{ range .Options }}-{{ upper .Value }}{{ end }}",
+					"option_summary_template": "{{ range .Options }}{{ .Value }} {{ end }}"
+				}
+			`
+			assert.Equal(t, minifyJSON(t, expected), string(bodyBytes), "UpdateProductRoot should attach the correct JSON to the request body")
+
+			res.Write([]byte(loadExampleResponse(t, "product_root")))
+		},
+	}
+
+	ts := httptest.NewTLSServer(handlerGenerator(handlers))
+	defer ts.Close()
+	c := buildTestClient(t, ts)
+
+	actual, err := c.UpdateProductRoot(1, exampleInput)
+	assert.Nil(t, err)
+	assert.True(t, endpointCalled, "the product root endpoint should be called")
+	assert.Equal(t, uint64(1), actual.ID)
+}
+
 func TestGetProductRoots(t *testing.T) {
 	t.Run("normal usage", func(*testing.T) {
 		exampleResponseJSON := loadExampleResponse(t, "product_roots")
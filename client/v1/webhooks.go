@@ -0,0 +1,105 @@
+package dairyclient
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/dairycart/dairycart/models/v1"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultWebhookTolerance is how old a webhook request can be before VerifyWebhookRequest rejects it
+const DefaultWebhookTolerance = 5 * time.Minute
+
+var (
+	// ErrWebhookNotSigned is returned when a webhook request is missing any of its signature headers
+	ErrWebhookNotSigned = errors.New("webhook request is not signed")
+	// ErrWebhookSignatureMismatch is returned when none of a webhook request's signatures match the secret
+	ErrWebhookSignatureMismatch = errors.New("webhook request signature does not match")
+	// ErrWebhookTooOld is returned when a webhook request's timestamp is outside the tolerance
+	ErrWebhookTooOld = errors.New("webhook request timestamp is outside the tolerance")
+)
+
+// WebhookDelivery is a webhook request that has been verified as coming from Dairycart
+type WebhookDelivery struct {
+	ID        uint64
+	EventType string
+	Timestamp time.Time
+	Body      []byte
+}
+
+// VerifyWebhookRequest checks that a request sent to a webhook was signed with its secret and was sent within
+// the tolerance (DefaultWebhookTolerance is used if it's zero). The request body is read, and replaced so it can
+// be read again. Dairycart retries a delivery with the same ID, so receivers that can't handle the same event
+// twice should keep track of the IDs they've seen.
+func VerifyWebhookRequest(req *http.Request, secret string, tolerance time.Duration) (*WebhookDelivery, error) {
+	if tolerance == 0 {
+		tolerance = DefaultWebhookTolerance
+	}
+
+	deliveryHeader := req.Header.Get(models.WebhookDeliveryHeader)
+	timestampHeader := req.Header.Get(models.WebhookTimestampHeader)
+	signatures := models.ParseWebhookSignatureHeader(req.Header.Get(models.WebhookSignatureHeader))
+	if deliveryHeader == "" || timestampHeader == "" || len(signatures) == 0 {
+		return nil, ErrWebhookNotSigned
+	}
+
+	deliveryID, err := strconv.ParseUint(deliveryHeader, 10, 64)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid delivery ID")
+	}
+	timestamp, err := models.ParseWebhookTimestampHeader(timestampHeader)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid timestamp")
+	}
+
+	var body []byte
+	if req.Body != nil {
+		body, err = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, errors.Wrap(err, "error reading request body")
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	expected := []byte(models.ComputeWebhookSignature(secret, timestamp, deliveryID, body))
+	var matched bool
+	for _, sig := range signatures {
+		if hmac.Equal(expected, []byte(sig)) {
+			matched = true
+		}
+	}
+	if !matched {
+		return nil, ErrWebhookSignatureMismatch
+	}
+
+	// checked after the signature so a forged timestamp can't be used to learn anything
+	if age := time.Since(timestamp); age > tolerance || age < -tolerance {
+		return nil, ErrWebhookTooOld
+	}
+
+	delivery := &WebhookDelivery{
+		ID:        deliveryID,
+		EventType: req.Header.Get(models.WebhookEventHeader),
+		Timestamp: timestamp,
+		Body:      body,
+	}
+	return delivery, nil
+}
+
+// DecodeEvent decodes a JSON delivery's event envelope. The envelope's data is decoded into data, which
+// should be a pointer to whatever the event is about, like a *models.Product for `product.updated`.
+func (d *WebhookDelivery) DecodeEvent(data interface{}) (*models.WebhookEventEnvelope, error) {
+	envelope := &models.WebhookEventEnvelope{Data: data}
+	if err := json.Unmarshal(d.Body, envelope); err != nil {
+		return nil, errors.Wrap(err, "error decoding webhook event")
+	}
+	return envelope, nil
+}
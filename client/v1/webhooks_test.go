@@ -0,0 +1,151 @@
+package dairyclient_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dairycart/dairycart/client/v1"
+	"github.com/dairycart/dairycart/models/v1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	exampleWebhookSecret = "secret"
+	exampleWebhookBody   = `{"id":1}`
+)
+
+func buildSignedWebhookRequest(t *testing.T, timestamp time.Time, deliveryID uint64, body string, secrets ...string) *http.Request {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, "https://example.com/webhook", strings.NewReader(body))
+	require.NoError(t, err)
+
+	req.Header.Set(models.WebhookEventHeader, "product.updated")
+	req.Header.Set(models.WebhookDeliveryHeader, fmt.Sprintf("%d", deliveryID))
+	req.Header.Set(models.WebhookTimestampHeader, fmt.Sprintf("%d", timestamp.Unix()))
+	req.Header.Set(models.WebhookSignatureHeader, models.BuildWebhookSignatureHeader(timestamp, deliveryID, []byte(body), secrets...))
+	return req
+}
+
+func TestVerifyWebhookRequest(t *testing.T) {
+	t.Parallel()
+
+	t.Run("normal usage", func(t *testing.T) {
+		req := buildSignedWebhookRequest(t, time.Now(), 10, exampleWebhookBody, exampleWebhookSecret)
+
+		actual, err := dairyclient.VerifyWebhookRequest(req, exampleWebhookSecret, 0)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(10), actual.ID)
+		assert.Equal(t, "product.updated", actual.EventType)
+		assert.Equal(t, exampleWebhookBody, string(actual.Body))
+
+		body, err := ioutil.ReadAll(req.Body)
+		require.NoError(t, err)
+		assert.Equal(t, exampleWebhookBody, string(body), "body should still be readable after verification")
+	})
+
+	t.Run("while secret is being rotated", func(t *testing.T) {
+		req := buildSignedWebhookRequest(t, time.Now(), 10, exampleWebhookBody, "new secret", exampleWebhookSecret)
+
+		_, err := dairyclient.VerifyWebhookRequest(req, exampleWebhookSecret, 0)
+		assert.NoError(t, err)
+	})
+
+	t.Run("with unsigned request", func(t *testing.T) {
+		req := buildSignedWebhookRequest(t, time.Now(), 10, exampleWebhookBody, exampleWebhookSecret)
+		req.Header.Del(models.WebhookSignatureHeader)
+
+		_, err := dairyclient.VerifyWebhookRequest(req, exampleWebhookSecret, 0)
+		assert.Equal(t, dairyclient.ErrWebhookNotSigned, err)
+	})
+
+	t.Run("with wrong secret", func(t *testing.T) {
+		req := buildSignedWebhookRequest(t, time.Now(), 10, exampleWebhookBody, "some other secret")
+
+		_, err := dairyclient.VerifyWebhookRequest(req, exampleWebhookSecret, 0)
+		assert.Equal(t, dairyclient.ErrWebhookSignatureMismatch, err)
+	})
+
+	t.Run("with tampered body", func(t *testing.T) {
+		req := buildSignedWebhookRequest(t, time.Now(), 10, exampleWebhookBody, exampleWebhookSecret)
+		req.Body = ioutil.NopCloser(strings.NewReader(`{"id":2}`))
+
+		_, err := dairyclient.VerifyWebhookRequest(req, exampleWebhookSecret, 0)
+		assert.Equal(t, dairyclient.ErrWebhookSignatureMismatch, err)
+	})
+
+	t.Run("with swapped delivery ID", func(t *testing.T) {
+		req := buildSignedWebhookRequest(t, time.Now(), 10, exampleWebhookBody, exampleWebhookSecret)
+		req.Header.Set(models.WebhookDeliveryHeader, "11")
+
+		_, err := dairyclient.VerifyWebhookRequest(req, exampleWebhookSecret, 0)
+		assert.Equal(t, dairyclient.ErrWebhookSignatureMismatch, err)
+	})
+
+	t.Run("with replayed request", func(t *testing.T) {
+		req := buildSignedWebhookRequest(t, time.Now().Add(-time.Hour), 10, exampleWebhookBody, exampleWebhookSecret)
+
+		_, err := dairyclient.VerifyWebhookRequest(req, exampleWebhookSecret, 0)
+		assert.Equal(t, dairyclient.ErrWebhookTooOld, err)
+	})
+
+	t.Run("with custom tolerance", func(t *testing.T) {
+		req := buildSignedWebhookRequest(t, time.Now().Add(-time.Hour), 10, exampleWebhookBody, exampleWebhookSecret)
+
+		_, err := dairyclient.VerifyWebhookRequest(req, exampleWebhookSecret, 2*time.Hour)
+		assert.NoError(t, err)
+	})
+
+	t.Run("with invalid delivery ID", func(t *testing.T) {
+		req := buildSignedWebhookRequest(t, time.Now(), 10, exampleWebhookBody, exampleWebhookSecret)
+		req.Header.Set(models.WebhookDeliveryHeader, "ten")
+
+		_, err := dairyclient.VerifyWebhookRequest(req, exampleWebhookSecret, 0)
+		assert.Error(t, err)
+	})
+
+	t.Run("with invalid timestamp", func(t *testing.T) {
+		req := buildSignedWebhookRequest(t, time.Now(), 10, exampleWebhookBody, exampleWebhookSecret)
+		req.Header.Set(models.WebhookTimestampHeader, "yesterday")
+
+		_, err := dairyclient.VerifyWebhookRequest(req, exampleWebhookSecret, 0)
+		assert.Error(t, err)
+	})
+}
+
+func TestWebhookDeliveryDecodeEvent(t *testing.T) {
+	t.Parallel()
+
+	t.Run("normal usage", func(t *testing.T) {
+		delivery := &dairyclient.WebhookDelivery{Body: []byte(`{
+			"version": 1,
+			"event": "product.updated",
+			"occurred_at": "2016-12-31T12:00:00Z",
+			"data": {"sku": "skateboard", "price": 12.5},
+			"previous": {"price": 10},
+			"changed_fields": ["price"]
+		}`)}
+
+		product := &models.Product{}
+		actual, err := delivery.DecodeEvent(product)
+		require.NoError(t, err)
+		assert.Equal(t, models.WebhookEventEnvelopeVersion, actual.Version)
+		assert.Equal(t, "product.updated", actual.Event)
+		assert.Equal(t, "skateboard", product.SKU)
+		assert.Equal(t, 12.5, product.Price)
+		assert.Equal(t, float64(10), actual.Previous["price"])
+		assert.Equal(t, []string{"price"}, actual.ChangedFields)
+	})
+
+	t.Run("with invalid body", func(t *testing.T) {
+		delivery := &dairyclient.WebhookDelivery{Body: []byte(`<event version="1"></event>`)}
+
+		_, err := delivery.DecodeEvent(&models.Product{})
+		assert.Error(t, err)
+	})
+}
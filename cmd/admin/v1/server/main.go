@@ -102,18 +102,27 @@ func apiForwarder(res http.ResponseWriter, req *http.Request) {
 	u, _ := url.Parse(fmt.Sprintf("%s?%s", strings.Replace(req.URL.Path, "/api", "", 1), req.URL.Query().Encode()))
 	toForwardTo := apiServerURL.ResolveReference(u)
 
-	req, err := http.NewRequest(req.Method, toForwardTo.String(), req.Body)
+	forwarded, err := http.NewRequest(req.Method, toForwardTo.String(), req.Body)
 	if err != nil {
 		informUserOfForwardingError(res, err)
 		return
 	}
-	resp, err := http.DefaultClient.Do(req)
+	// the API needs the session cookie, and the event stream needs Last-Event-ID
+	forwarded.Header = req.Header
+	forwarded = forwarded.WithContext(req.Context())
+
+	resp, err := http.DefaultClient.Do(forwarded)
 	if err != nil {
 		informUserOfForwardingError(res, err)
 		return
 	}
 	defer resp.Body.Close()
 
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		streamResponse(res, resp)
+		return
+	}
+
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		informUserOfForwardingError(res, err)
@@ -124,6 +133,31 @@ func apiForwarder(res http.ResponseWriter, req *http.Request) {
 	res.Write(body)
 }
 
+// streamResponse passes an event stream through as it arrives, instead of waiting for it to end
+func streamResponse(res http.ResponseWriter, resp *http.Response) {
+	for _, header := range []string{"Content-Type", "Cache-Control"} {
+		res.Header().Set(header, resp.Header.Get(header))
+	}
+	res.WriteHeader(resp.StatusCode)
+
+	flusher, _ := res.(http.Flusher)
+	buf := make([]byte, 4096)
+	for {
+		n, err := resp.Body.Read(buf)
+		if n > 0 {
+			if _, writeErr := res.Write(buf[:n]); writeErr != nil {
+				return
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
 func main() {
 	debug = strings.ToLower(os.Getenv("DEBUG")) == "true"
 
@@ -0,0 +1,63 @@
+// collect_orphaned_images compares the files in image storage with the product images in the database, deletes
+// the ones nothing refers to, and reports any that product images refer to but are missing. Run it with the same
+// config file as the server:
+//
+//	collect_orphaned_images [-dry-run] [-min-age 24h] [config path]
+//
+// The report is written to stdout as JSON.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+
+	dairyserver "github.com/dairycart/dairycart/api/v1"
+
+	"github.com/sirupsen/logrus"
+
+	_ "github.com/lib/pq"
+)
+
+func main() {
+	logrus.SetOutput(os.Stderr)
+	logrus.SetLevel(logrus.InfoLevel)
+
+	dryRun := flag.Bool("dry-run", false, "report orphaned images without deleting them")
+	minAge := flag.Duration("min-age", 0, "leave files changed more recently than this alone (defaults to image_collection.min_age)")
+	flag.Parse()
+
+	cfg, err := dairyserver.LoadServerConfig(flag.Arg(0))
+	if err != nil {
+		logrus.Fatalf("error validating server configuration: %v\n", err)
+	}
+
+	config, err := dairyserver.BuildServerConfig(cfg)
+	if err != nil {
+		logrus.Fatalf("error configuring server: %v\n", err)
+	}
+
+	err = dairyserver.InitializeServerComponents(cfg, config)
+	if err != nil {
+		logrus.Fatalf("error initializing server: %v\n", err)
+	}
+
+	opts := config.OrphanedImageCollection.Options
+	opts.DryRun = opts.DryRun || *dryRun
+	if *minAge > 0 {
+		opts.MinAge = *minAge
+	}
+
+	report, collectionErr := dairyserver.CollectOrphanedProductImages(config.DB, config.DatabaseClient, config.ImageStorer, opts)
+	if report != nil {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err = enc.Encode(report); err != nil {
+			logrus.Fatalf("error writing report: %v\n", err)
+		}
+		logrus.Infof("found %d orphaned images (%d bytes), deleted %d, and %d missing renditions\n", len(report.Orphans), report.OrphanedBytes, report.Deleted, len(report.Missing))
+	}
+	if collectionErr != nil {
+		logrus.Fatalf("error collecting orphaned images: %v\n", collectionErr)
+	}
+}
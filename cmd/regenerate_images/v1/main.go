@@ -0,0 +1,45 @@
+// regenerate_images rebuilds the renditions of every product image from its stored original. Run it with
+// the same config file as the server after changing the `[image_storage.renditions]` config section.
+package main
+
+import (
+	"os"
+
+	dairyserver "github.com/dairycart/dairycart/api/v1"
+
+	"github.com/sirupsen/logrus"
+
+	_ "github.com/lib/pq"
+)
+
+func main() {
+	logrus.SetOutput(os.Stdout)
+	logrus.SetLevel(logrus.InfoLevel)
+
+	configPath := ""
+	if len(os.Args) >= 2 {
+		configPath = os.Args[1]
+	}
+
+	cfg, err := dairyserver.LoadServerConfig(configPath)
+	if err != nil {
+		logrus.Fatalf("error validating server configuration: %v\n", err)
+	}
+
+	config, err := dairyserver.BuildServerConfig(cfg)
+	if err != nil {
+		logrus.Fatalf("error configuring server: %v\n", err)
+	}
+
+	// this also migrates the database, which makes sure there's somewhere to put the new renditions
+	err = dairyserver.InitializeServerComponents(cfg, config)
+	if err != nil {
+		logrus.Fatalf("error initializing server: %v\n", err)
+	}
+
+	regenerated, err := dairyserver.RegenerateProductImageRenditions(config.DB, config.DatabaseClient, config.ImageStorer, config.MaxImagePixels())
+	logrus.Infof("regenerated renditions for %d product images\n", regenerated)
+	if err != nil {
+		logrus.Fatalf("error regenerating renditions: %v\n", err)
+	}
+}
@@ -43,6 +43,12 @@ func main() {
 		logrus.Fatalf("error initializing server: %v\n", err)
 	}
 
+	go dairyserver.NewWebhookDispatcher(config.DB, config.DatabaseClient, config.WebhookDelivery).Run(nil)
+
+	if gc := config.OrphanedImageCollection; gc.Interval > 0 {
+		go dairyserver.RunOrphanedImageCollection(config.DB, config.DatabaseClient, config.ImageStorer, gc.Interval, gc.Options, nil)
+	}
+
 	port := cfg.GetInt("port")
 	http.Handle("/", context.ClearHandler(config.Router))
 	log.Printf("API now listening for requests on port %d\n", port)
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+)
+
+// APIKey represents a Dairycart API key, which authenticates requests as the user it belongs to. Only a hash of
+// the key is stored, so the key itself can't be shown again after it's created, but its prefix can be, to help
+// tell keys apart. Revoked keys are archived.
+type APIKey struct {
+	ID         uint64     `json:"id"`           // id
+	UserID     uint64     `json:"user_id"`      // user_id
+	Name       string     `json:"name"`         // name
+	Prefix     string     `json:"prefix"`       // prefix
+	KeyHash    string     `json:"-"`            // key_hash
+	LastUsedOn *Dairytime `json:"last_used_on"` // last_used_on
+	ExpiresOn  *Dairytime `json:"expires_on"`   // expires_on
+	CreatedOn  time.Time  `json:"created_on"`   // created_on
+	UpdatedOn  *Dairytime `json:"updated_on"`   // updated_on
+	ArchivedOn *Dairytime `json:"archived_on"`  // archived_on
+}
+
+// APIKeyCreationInput is a struct to use for creating APIKeys. Keys without an expiry never expire.
+type APIKeyCreationInput struct {
+	Name      string     `json:"name,omitempty"`       // name
+	ExpiresOn *Dairytime `json:"expires_on,omitempty"` // expires_on
+}
+
+// APIKeyCreationResponse is the response to creating an API key, and the only time the key is ever returned
+type APIKeyCreationResponse struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+type APIKeyListResponse struct {
+	ListResponse
+	APIKeys []APIKey `json:"data"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	// ChangeCreated is the operation of a change that created something
+	ChangeCreated = "created"
+	// ChangeUpdated is the operation of a change that updated something
+	ChangeUpdated = "updated"
+	// ChangeArchived is the operation of a tombstone, which records that something was archived. Tombstones have no data.
+	ChangeArchived = "archived"
+)
+
+// Change represents a Dairycart change, which is a record in the change feed of something that was created,
+// updated, or archived. Data is the row as it was after the change, with the same field names as the resource's
+// JSON representation. Position is where the change falls in the feed, which is the order changes were committed
+// in, and isn't known until its transaction commits.
+type Change struct {
+	ID         uint64          `json:"id"`          // id
+	Position   uint64          `json:"position"`    // position
+	Resource   string          `json:"resource"`    // resource
+	ResourceID uint64          `json:"resource_id"` // resource_id
+	Operation  string          `json:"operation"`   // operation
+	Data       json.RawMessage `json:"data"`        // data
+	ChangedOn  time.Time       `json:"changed_on"`  // changed_on
+}
+
+// ChangeFeedResponse is a page of the change feed. NextToken is what to pass as `since` to get the changes
+// after this page, and it's the same token that was passed in if there weren't any. HasMore is true if
+// there are more changes waiting.
+type ChangeFeedResponse struct {
+	Changes   []Change `json:"changes"`
+	NextToken string   `json:"next_token"`
+	HasMore   bool     `json:"has_more"`
+}
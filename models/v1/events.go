@@ -0,0 +1,16 @@
+package models
+
+import (
+	"time"
+)
+
+// Event represents a Dairycart event, which is a record of something that happened to the catalog. Payload is
+// the event's envelope, encoded as JSON. Position is where the event falls in the event log, which is the
+// order events were committed in, and isn't known until its transaction commits.
+type Event struct {
+	ID        uint64    `json:"id"`         // id
+	Position  uint64    `json:"position"`   // position
+	EventType string    `json:"event_type"` // event_type
+	Payload   string    `json:"payload"`    // payload
+	CreatedOn time.Time `json:"created_on"` // created_on
+}
@@ -2,6 +2,7 @@ package models
 
 import (
 	"database/sql/driver"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"time"
@@ -69,6 +70,76 @@ func (dt *Dairytime) String() string {
 	return dt.Time.Format(timeLayout)
 }
 
+// RenditionURLs maps the names of an image's renditions (like `thumbnail` or `main`) to their URLs
+type RenditionURLs map[string]string
+
+// Scan implements the Scanner interface.
+func (r *RenditionURLs) Scan(value interface{}) error {
+	var raw []byte
+	switch v := value.(type) {
+	case nil:
+		*r = RenditionURLs{}
+		return nil
+	case []byte:
+		raw = v
+	case string:
+		raw = []byte(v)
+	default:
+		return errors.New("value is not a JSON object")
+	}
+
+	out := RenditionURLs{}
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return err
+	}
+	*r = out
+	return nil
+}
+
+// Value implements the driver Valuer interface.
+func (r RenditionURLs) Value() (driver.Value, error) {
+	if r == nil {
+		return "{}", nil
+	}
+	out, err := json.Marshal(map[string]string(r))
+	return string(out), err
+}
+
+// WebhookHeaders holds the HTTP headers of a webhook request, one value per header
+type WebhookHeaders map[string]string
+
+// Scan implements the Scanner interface.
+func (h *WebhookHeaders) Scan(value interface{}) error {
+	var raw []byte
+	switch v := value.(type) {
+	case nil:
+		*h = WebhookHeaders{}
+		return nil
+	case []byte:
+		raw = v
+	case string:
+		raw = []byte(v)
+	default:
+		return errors.New("value is not a JSON object")
+	}
+
+	out := WebhookHeaders{}
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return err
+	}
+	*h = out
+	return nil
+}
+
+// Value implements the driver Valuer interface.
+func (h WebhookHeaders) Value() (driver.Value, error) {
+	if h == nil {
+		return "{}", nil
+	}
+	out, err := json.Marshal(map[string]string(h))
+	return string(out), err
+}
+
 // ListResponse is a generic list response struct containing values that represent
 // pagination, meant to be embedded into other object response structs
 type ListResponse struct {
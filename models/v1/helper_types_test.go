@@ -149,3 +149,101 @@ func TestErrorResponseError(t *testing.T) {
 		assert.Equal(t, expected, actual)
 	})
 }
+
+func TestRenditionURLsScan(t *testing.T) {
+	t.Parallel()
+
+	t.Run("with valid value", func(_t *testing.T) {
+		_t.Parallel()
+
+		r := RenditionURLs{}
+		assert.NoError(t, r.Scan([]byte(`{"thumbnail": "https://dairycart.com/thumbnail.png"}`)))
+		assert.Equal(t, RenditionURLs{"thumbnail": "https://dairycart.com/thumbnail.png"}, r)
+	})
+
+	t.Run("with nil value", func(_t *testing.T) {
+		_t.Parallel()
+
+		var r RenditionURLs
+		assert.NoError(t, r.Scan(nil))
+		assert.NotNil(t, r)
+	})
+
+	t.Run("with invalid value", func(_t *testing.T) {
+		_t.Parallel()
+
+		r := RenditionURLs{}
+		assert.Error(t, r.Scan(123))
+		assert.Error(t, r.Scan(`["not", "an", "object"]`))
+	})
+}
+
+func TestRenditionURLsValue(t *testing.T) {
+	t.Parallel()
+
+	t.Run("with valid value", func(_t *testing.T) {
+		_t.Parallel()
+
+		actual, err := RenditionURLs{"main": "https://dairycart.com/main.png"}.Value()
+		assert.NoError(t, err)
+		assert.Equal(t, driver.Value(`{"main":"https://dairycart.com/main.png"}`), actual)
+	})
+
+	t.Run("with nil value", func(_t *testing.T) {
+		_t.Parallel()
+
+		var r RenditionURLs
+		actual, err := r.Value()
+		assert.NoError(t, err)
+		assert.Equal(t, driver.Value("{}"), actual)
+	})
+}
+
+func TestWebhookHeadersScan(t *testing.T) {
+	t.Parallel()
+
+	t.Run("with valid value", func(_t *testing.T) {
+		_t.Parallel()
+
+		h := WebhookHeaders{}
+		assert.NoError(t, h.Scan([]byte(`{"Content-Type": "application/json"}`)))
+		assert.Equal(t, WebhookHeaders{"Content-Type": "application/json"}, h)
+	})
+
+	t.Run("with nil value", func(_t *testing.T) {
+		_t.Parallel()
+
+		var h WebhookHeaders
+		assert.NoError(t, h.Scan(nil))
+		assert.NotNil(t, h)
+	})
+
+	t.Run("with invalid value", func(_t *testing.T) {
+		_t.Parallel()
+
+		h := WebhookHeaders{}
+		assert.Error(t, h.Scan(123))
+		assert.Error(t, h.Scan(`["not", "an", "object"]`))
+	})
+}
+
+func TestWebhookHeadersValue(t *testing.T) {
+	t.Parallel()
+
+	t.Run("with valid value", func(_t *testing.T) {
+		_t.Parallel()
+
+		actual, err := WebhookHeaders{"Content-Type": "application/json"}.Value()
+		assert.NoError(t, err)
+		assert.Equal(t, driver.Value(`{"Content-Type":"application/json"}`), actual)
+	})
+
+	t.Run("with nil value", func(_t *testing.T) {
+		_t.Parallel()
+
+		var h WebhookHeaders
+		actual, err := h.Value()
+		assert.NoError(t, err)
+		assert.Equal(t, driver.Value("{}"), actual)
+	})
+}
@@ -1,10 +1,16 @@
 package models
 
+import (
+	"time"
+)
+
 // ProductImageBridge represents a Dairycart product image bridge
 type ProductImageBridge struct {
-	ID             uint64 `json:"id"`               // id
-	ProductID      uint64 `json:"product_id"`       // product_id
-	ProductImageID uint64 `json:"product_image_id"` // product_image_id
+	ID             uint64     `json:"id"`               // id
+	ProductID      uint64     `json:"product_id"`       // product_id
+	ProductImageID uint64     `json:"product_image_id"` // product_image_id
+	CreatedOn      time.Time  `json:"created_on"`       // created_on
+	ArchivedOn     *Dairytime `json:"archived_on"`      // archived_on
 }
 
 // ProductImageBridgeCreationInput is a struct to use for creating ProductImageBridges
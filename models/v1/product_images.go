@@ -6,15 +6,32 @@ import (
 
 // ProductImage represents a Dairycart product image
 type ProductImage struct {
-	ID            uint64     `json:"id"`              // id
-	ProductRootID uint64     `json:"product_root_id"` // product_root_id
-	ThumbnailURL  string     `json:"thumbnail_url"`   // thumbnail_url
-	MainURL       string     `json:"main_url"`        // main_url
-	OriginalURL   string     `json:"original_url"`    // original_url
-	SourceURL     string     `json:"source_url"`      // source_url
-	CreatedOn     time.Time  `json:"created_on"`      // created_on
-	UpdatedOn     *Dairytime `json:"updated_on"`      // updated_on
-	ArchivedOn    *Dairytime `json:"archived_on"`     // archived_on
+	ID             uint64        `json:"id"`              // id
+	ProductRootID  uint64        `json:"product_root_id"` // product_root_id
+	ThumbnailURL   string        `json:"thumbnail_url"`   // thumbnail_url
+	MainURL        string        `json:"main_url"`        // main_url
+	OriginalURL    string        `json:"original_url"`    // original_url
+	SourceURL      string        `json:"source_url"`      // source_url
+	CreatedOn      time.Time     `json:"created_on"`      // created_on
+	UpdatedOn      *Dairytime    `json:"updated_on"`      // updated_on
+	ArchivedOn     *Dairytime    `json:"archived_on"`     // archived_on
+	SortOrder      uint32        `json:"sort_order"`      // sort_order
+	Renditions     RenditionURLs `json:"renditions"`      // renditions
+	ContentHash    string        `json:"content_hash"`    // content_hash
+	PerceptualHash string        `json:"perceptual_hash"` // perceptual_hash
+}
+
+// ProductImageDuplicateGroup is a set of product images that look alike
+type ProductImageDuplicateGroup struct {
+	// Distance is the largest difference between any two images' perceptual hashes, 0 meaning they're identical
+	Distance      int            `json:"distance"`
+	ProductImages []ProductImage `json:"product_images"`
+}
+
+// ProductImageDuplicateReport lists groups of product images that are likely the same picture
+type ProductImageDuplicateReport struct {
+	MaxDistance int                          `json:"max_distance"`
+	Groups      []ProductImageDuplicateGroup `json:"groups"`
 }
 
 // ProductImageCreationInput is a struct to use for creating ProductImages
@@ -30,9 +47,33 @@ type ProductImageUpdateInput struct {
 	ThumbnailURL  string `json:"thumbnail_url,omitempty"`   // thumbnail_url
 	MainURL       string `json:"main_url,omitempty"`        // main_url
 	OriginalURL   string `json:"original_url,omitempty"`    // original_url
+	SortOrder     uint32 `json:"sort_order,omitempty"`      // sort_order
 }
 
 type ProductImageListResponse struct {
 	ListResponse
 	ProductImages []ProductImage `json:"product_images"`
 }
+
+// ProductImageAssignmentInput is a struct to use for attaching existing product root images to variants
+type ProductImageAssignmentInput struct {
+	ImageIDs       []uint64 `json:"image_ids"`
+	PrimaryImageID *uint64  `json:"primary_image_id,omitempty"`
+}
+
+// ProductImageAssignment describes which images were attached to which products
+type ProductImageAssignment struct {
+	ProductIDs     []uint64 `json:"product_ids"`
+	ImageIDs       []uint64 `json:"image_ids"`
+	PrimaryImageID *uint64  `json:"primary_image_id,omitempty"`
+}
+
+// ProductImagesCreationInput is a struct to use for adding images to an existing product root
+type ProductImagesCreationInput struct {
+	Images []ProductImageCreationInput `json:"images"`
+}
+
+// ProductImageOrderInput is a struct to use for reordering a product root's images
+type ProductImageOrderInput struct {
+	ImageIDs []uint64 `json:"image_ids"`
+}
@@ -12,17 +12,20 @@ type ProductOptionValue struct {
 	CreatedOn       time.Time  `json:"created_on"`        // created_on
 	UpdatedOn       *Dairytime `json:"updated_on"`        // updated_on
 	ArchivedOn      *Dairytime `json:"archived_on"`       // archived_on
+	Abbreviation    string     `json:"abbreviation"`      // abbreviation
 }
 
 // ProductOptionValueCreationInput is a struct to use for creating ProductOptionValues
 type ProductOptionValueCreationInput struct {
-	Value string `json:"value,omitempty"` // value
+	Value        string `json:"value,omitempty"`        // value
+	Abbreviation string `json:"abbreviation,omitempty"` // abbreviation
 }
 
 // ProductOptionValueUpdateInput is a struct to use for updating ProductOptionValues
 type ProductOptionValueUpdateInput struct {
 	ProductOptionID uint64 `json:"product_option_id,omitempty"` // product_option_id
 	Value           string `json:"value,omitempty"`             // value
+	Abbreviation    string `json:"abbreviation,omitempty"`      // abbreviation
 }
 
 type ProductOptionValueListResponse struct {
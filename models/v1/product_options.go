@@ -21,6 +21,9 @@ type ProductOption struct {
 type ProductOptionCreationInput struct {
 	Name   string   `json:"name,omitempty"`
 	Values []string `json:"values,omitempty"`
+
+	// Abbreviations maps option values to the short codes used in variant SKUs (e.g. `Red` -> `RD`)
+	Abbreviations map[string]string `json:"abbreviations,omitempty"`
 }
 
 // ProductOptionUpdateInput is a struct to use for updating ProductOptions
@@ -69,6 +69,11 @@ type ProductCreationInput struct {
 	QuantityPerPackage uint32     `json:"quantity_per_package,omitempty"` // quantity_per_package
 	AvailableOn        *Dairytime `json:"available_on,omitempty"`         // available_on
 
+	// SKUTemplate and OptionSummaryTemplate are stored on the product root and
+	// used to render the SKU and option summary of every generated variant
+	SKUTemplate           string `json:"sku_template,omitempty"`
+	OptionSummaryTemplate string `json:"option_summary_template,omitempty"`
+
 	Images  []ProductImageCreationInput  `json:"images,omitempty"`
 	Options []ProductOptionCreationInput `json:"options,omitempty"`
 }
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+)
+
+// Role represents a Dairycart role, which is a named set of permissions that can be given to users. Admins have
+// every permission, whatever roles they have.
+type Role struct {
+	ID          uint64     `json:"id"`          // id
+	Name        string     `json:"name"`        // name
+	Description string     `json:"description"` // description
+	Permissions []string   `json:"permissions"` // the names of the role's permissions
+	CreatedOn   time.Time  `json:"created_on"`  // created_on
+	UpdatedOn   *Dairytime `json:"updated_on"`  // updated_on
+	ArchivedOn  *Dairytime `json:"archived_on"` // archived_on
+}
+
+// UserRole represents a role having been given to a user
+type UserRole struct {
+	UserID    uint64    `json:"user_id"`    // user_id
+	RoleID    uint64    `json:"role_id"`    // role_id
+	CreatedOn time.Time `json:"created_on"` // created_on
+}
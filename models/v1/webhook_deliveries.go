@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+)
+
+const (
+	// WebhookDeliveryPending is the status of a delivery that's waiting for its next attempt
+	WebhookDeliveryPending = "pending"
+	// WebhookDeliveryDelivered is the status of a delivery the webhook accepted
+	WebhookDeliveryDelivered = "delivered"
+	// WebhookDeliveryDead is the status of a delivery that ran out of attempts
+	WebhookDeliveryDead = "dead"
+)
+
+// WebhookDelivery represents a Dairycart webhook delivery, which is an event on its way to a webhook
+type WebhookDelivery struct {
+	ID             uint64     `json:"id"`               // id
+	WebhookID      uint64     `json:"webhook_id"`       // webhook_id
+	EventType      string     `json:"event_type"`       // event_type
+	ContentType    string     `json:"content_type"`     // content_type
+	Payload        string     `json:"payload"`          // payload
+	Status         string     `json:"status"`           // status
+	Attempts       int        `json:"attempts"`         // attempts
+	NextAttemptOn  time.Time  `json:"next_attempt_on"`  // next_attempt_on
+	LockedUntil    *Dairytime `json:"locked_until"`     // locked_until
+	LastStatusCode int        `json:"last_status_code"` // last_status_code
+	LastError      string     `json:"last_error"`       // last_error
+	CreatedOn      time.Time  `json:"created_on"`       // created_on
+	UpdatedOn      *Dairytime `json:"updated_on"`       // updated_on
+	DeliveredOn    *Dairytime `json:"delivered_on"`     // delivered_on
+}
+
+type WebhookDeliveryListResponse struct {
+	ListResponse
+	WebhookDeliveries []WebhookDelivery `json:"webhook_deliveries"`
+}
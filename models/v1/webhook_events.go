@@ -0,0 +1,75 @@
+package models
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"sort"
+	"time"
+)
+
+// WebhookEventEnvelopeVersion is the version of the envelope every webhook event is sent in. It only changes
+// when the envelope changes in a way that would break receivers.
+const WebhookEventEnvelopeVersion = 1
+
+// WebhookEventEnvelope is the payload of every webhook delivery. Data is the object the event happened to.
+// For updates, Previous holds what each changed field was before the update, and ChangedFields names them.
+// For every other event, Previous is null and ChangedFields is empty.
+//
+// In JSON, an envelope looks like
+//
+//	{
+//	    "version": 1,
+//	    "event": "product.updated",
+//	    "occurred_at": "2018-06-01T12:00:00Z",
+//	    "data": {"sku": "skateboard", "price": 12.5, ...},
+//	    "previous": {"price": 10},
+//	    "changed_fields": ["price"]
+//	}
+//
+// and in XML, the same envelope is an <event version="1"> element with a child element for each field.
+type WebhookEventEnvelope struct {
+	XMLName       xml.Name              `json:"-" xml:"event"`
+	Version       int                   `json:"version" xml:"version,attr"`
+	Event         string                `json:"event" xml:"type"`
+	OccurredAt    time.Time             `json:"occurred_at" xml:"occurred_at"`
+	Data          interface{}           `json:"data" xml:"data"`
+	Previous      WebhookPreviousValues `json:"previous" xml:"previous,omitempty"`
+	ChangedFields []string              `json:"changed_fields" xml:"changed_fields>field"`
+}
+
+// WebhookPreviousValues maps the JSON names of an object's changed fields to what they were before it was updated
+type WebhookPreviousValues map[string]interface{}
+
+// MarshalXML writes each previous value as an element named after its field. Values that aren't strings are
+// written the way they'd appear in JSON.
+func (p WebhookPreviousValues) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+
+	fields := make([]string, 0, len(p))
+	for field := range p {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	for _, field := range fields {
+		var text string
+		switch v := p[field].(type) {
+		case nil:
+		case string:
+			text = v
+		default:
+			encoded, err := json.Marshal(v)
+			if err != nil {
+				return err
+			}
+			text = string(encoded)
+		}
+		if err := e.EncodeElement(text, xml.StartElement{Name: xml.Name{Local: field}}); err != nil {
+			return err
+		}
+	}
+
+	return e.EncodeToken(start.End())
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookEventEnvelope(t *testing.T) {
+	t.Parallel()
+
+	type testObject struct {
+		ID   uint64 `json:"id"`
+		Name string `json:"name"`
+	}
+	envelope := &WebhookEventEnvelope{
+		Version:       WebhookEventEnvelopeVersion,
+		Event:         "thing.updated",
+		OccurredAt:    time.Unix(1483185600, 0).UTC(),
+		Data:          testObject{ID: 1, Name: "new"},
+		Previous:      WebhookPreviousValues{"name": "old", "tags": []interface{}{"a", "b"}, "deleted": nil},
+		ChangedFields: []string{"deleted", "name", "tags"},
+	}
+
+	t.Run("as JSON", func(t *testing.T) {
+		actual, err := json.Marshal(envelope)
+		require.NoError(t, err)
+		expected := `{"version":1,"event":"thing.updated","occurred_at":"2016-12-31T12:00:00Z","data":{"id":1,"name":"new"},"previous":{"deleted":null,"name":"old","tags":["a","b"]},"changed_fields":["deleted","name","tags"]}`
+		assert.Equal(t, expected, string(actual))
+	})
+
+	t.Run("as XML", func(t *testing.T) {
+		actual, err := xml.Marshal(envelope)
+		require.NoError(t, err)
+		expected := `<event version="1"><type>thing.updated</type><occurred_at>2016-12-31T12:00:00Z</occurred_at>` +
+			`<data><ID>1</ID><Name>new</Name></data>` +
+			`<previous><deleted></deleted><name>old</name><tags>[&#34;a&#34;,&#34;b&#34;]</tags></previous>` +
+			`<changed_fields><field>deleted</field><field>name</field><field>tags</field></changed_fields></event>`
+		assert.Equal(t, expected, string(actual))
+	})
+
+	t.Run("as XML without changes", func(t *testing.T) {
+		created := *envelope
+		created.Previous = nil
+		created.ChangedFields = []string{}
+
+		actual, err := xml.Marshal(&created)
+		require.NoError(t, err)
+		assert.NotContains(t, string(actual), "<previous>")
+	})
+}
@@ -4,13 +4,26 @@ import (
 	"time"
 )
 
-// WebhookExecutionLog represents a Dairycart webhook execution log
+// WebhookExecutionLog represents a Dairycart webhook execution log, which is a record of one attempt to deliver an event.
+// ResponseBody is kept, truncated, for the verification handshake and for anyone reading the database, but it's never
+// encoded, so webhooks can't be used to read responses from the URLs they point at.
 type WebhookExecutionLog struct {
-	ID         uint64    `json:"id"`          // id
-	WebhookID  uint64    `json:"webhook_id"`  // webhook_id
-	StatusCode int       `json:"status_code"` // status_code
-	Succeeded  bool      `json:"succeeded"`   // succeeded
-	ExecutedOn time.Time `json:"executed_on"` // executed_on
+	ID             uint64         `json:"id"`              // id
+	WebhookID      uint64         `json:"webhook_id"`      // webhook_id
+	DeliveryID     *uint64        `json:"delivery_id"`     // delivery_id
+	EventType      string         `json:"event_type"`      // event_type
+	Attempt        int            `json:"attempt"`         // attempt
+	RequestURL     string         `json:"request_url"`     // request_url
+	RequestHeaders WebhookHeaders `json:"request_headers"` // request_headers
+	RequestBody    string         `json:"request_body"`    // request_body
+	StatusCode     int            `json:"status_code"`     // status_code
+	ResponseBody   string         `json:"-"`               // response_body
+	LatencyMS      int64          `json:"latency_ms"`      // latency_ms
+	Error          string         `json:"error"`           // error
+	Succeeded      bool           `json:"succeeded"`       // succeeded
+	ExecutedOn     time.Time      `json:"executed_on"`     // executed_on
+	UpdatedOn      *Dairytime     `json:"updated_on"`      // updated_on
+	ArchivedOn     *Dairytime     `json:"archived_on"`     // archived_on
 }
 
 // WebhookExecutionLogCreationInput is a struct to use for creating WebhookExecutionLogs
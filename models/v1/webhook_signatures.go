@@ -0,0 +1,67 @@
+package models
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// WebhookEventHeader is the header a webhook delivery's event type is sent in
+	WebhookEventHeader = "X-Dairycart-Event"
+	// WebhookDeliveryHeader is the header a webhook delivery's unique ID is sent in
+	WebhookDeliveryHeader = "X-Dairycart-Delivery"
+	// WebhookTimestampHeader is the header containing the unix time a webhook delivery was signed at
+	WebhookTimestampHeader = "X-Dairycart-Timestamp"
+	// WebhookSignatureHeader is the header containing a webhook delivery's signatures
+	WebhookSignatureHeader = "X-Dairycart-Signature"
+
+	// WebhookSignatureScheme prefixes every signature in the signature header, so the scheme can change later
+	// without breaking receivers that only understand this one
+	WebhookSignatureScheme = "v1"
+)
+
+// ComputeWebhookSignature returns the hex encoded HMAC-SHA256 of a webhook delivery. The timestamp
+// and delivery ID are signed along with the body so neither can be swapped out to replay a request.
+func ComputeWebhookSignature(secret string, timestamp time.Time, deliveryID uint64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.%d.", timestamp.Unix(), deliveryID)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// BuildWebhookSignatureHeader returns the value of the signature header for a delivery signed with each
+// of the given secrets. More than one secret is used while a webhook's previous secret is still valid.
+func BuildWebhookSignatureHeader(timestamp time.Time, deliveryID uint64, body []byte, secrets ...string) string {
+	var signatures []string
+	for _, secret := range secrets {
+		sig := ComputeWebhookSignature(secret, timestamp, deliveryID, body)
+		signatures = append(signatures, fmt.Sprintf("%s=%s", WebhookSignatureScheme, sig))
+	}
+	return strings.Join(signatures, ",")
+}
+
+// ParseWebhookSignatureHeader returns the signatures in a signature header that use the current scheme
+func ParseWebhookSignatureHeader(header string) []string {
+	var signatures []string
+	for _, part := range strings.Split(header, ",") {
+		kv := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		if len(kv) == 2 && kv[0] == WebhookSignatureScheme {
+			signatures = append(signatures, kv[1])
+		}
+	}
+	return signatures
+}
+
+// ParseWebhookTimestampHeader parses the unix time in a timestamp header
+func ParseWebhookTimestampHeader(header string) (time.Time, error) {
+	seconds, err := strconv.ParseInt(header, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(seconds, 0), nil
+}
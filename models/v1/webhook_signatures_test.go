@@ -0,0 +1,52 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeWebhookSignature(t *testing.T) {
+	t.Parallel()
+
+	timestamp := time.Unix(1483185600, 0)
+	body := []byte(`{"id":1}`)
+	expected := ComputeWebhookSignature("secret", timestamp, 1, body)
+
+	assert.Len(t, expected, 64)
+	assert.Equal(t, expected, ComputeWebhookSignature("secret", timestamp, 1, body))
+	assert.NotEqual(t, expected, ComputeWebhookSignature("other secret", timestamp, 1, body), "signature should depend on the secret")
+	assert.NotEqual(t, expected, ComputeWebhookSignature("secret", timestamp.Add(time.Second), 1, body), "signature should depend on the timestamp")
+	assert.NotEqual(t, expected, ComputeWebhookSignature("secret", timestamp, 2, body), "signature should depend on the delivery ID")
+	assert.NotEqual(t, expected, ComputeWebhookSignature("secret", timestamp, 1, []byte(`{"id":2}`)), "signature should depend on the body")
+}
+
+func TestBuildAndParseWebhookSignatureHeader(t *testing.T) {
+	t.Parallel()
+
+	timestamp := time.Unix(1483185600, 0)
+	body := []byte(`{"id":1}`)
+
+	header := BuildWebhookSignatureHeader(timestamp, 1, body, "new", "old")
+	expected := []string{
+		ComputeWebhookSignature("new", timestamp, 1, body),
+		ComputeWebhookSignature("old", timestamp, 1, body),
+	}
+	assert.Equal(t, expected, ParseWebhookSignatureHeader(header))
+
+	assert.Empty(t, ParseWebhookSignatureHeader(""))
+	assert.Equal(t, []string{"abc"}, ParseWebhookSignatureHeader("v0=def, v1=abc, garbage"), "signatures from other schemes should be ignored")
+}
+
+func TestParseWebhookTimestampHeader(t *testing.T) {
+	t.Parallel()
+
+	actual, err := ParseWebhookTimestampHeader("1483185600")
+	require.Nil(t, err)
+	assert.Equal(t, time.Unix(1483185600, 0), actual)
+
+	_, err = ParseWebhookTimestampHeader("yesterday")
+	assert.Error(t, err)
+}
@@ -4,29 +4,83 @@ import (
 	"time"
 )
 
+const (
+	// WebhookStatusPending is the status of a webhook that hasn't completed its verification handshake yet
+	WebhookStatusPending = "pending"
+	// WebhookStatusActive is the status of a webhook that events are delivered to
+	WebhookStatusActive = "active"
+	// WebhookStatusDisabled is the status of a webhook that events aren't delivered to any more
+	WebhookStatusDisabled = "disabled"
+)
+
 // Webhook represents a Dairycart webhook
 type Webhook struct {
-	ID          uint64     `json:"id"`           // id
-	URL         string     `json:"url"`          // url
-	EventType   string     `json:"event_type"`   // event_type
-	ContentType string     `json:"content_type"` // content_type
-	CreatedOn   time.Time  `json:"created_on"`   // created_on
-	UpdatedOn   *Dairytime `json:"updated_on"`   // updated_on
-	ArchivedOn  *Dairytime `json:"archived_on"`  // archived_on
+	ID                  uint64     `json:"id"`                   // id
+	URL                 string     `json:"url"`                  // url
+	EventTypes          []string   `json:"event_types"`          // event_types
+	ContentType         string     `json:"content_type"`         // content_type
+	Fields              []string   `json:"fields"`               // fields
+	Filter              string     `json:"filter"`               // filter
+	Status              string     `json:"status"`               // status
+	ConsecutiveFailures int        `json:"consecutive_failures"` // consecutive_failures
+	PausedUntil         *Dairytime `json:"paused_until"`         // paused_until
+	Secret              string     `json:"-" xml:"-"`            // secret
+	PreviousSecret      string     `json:"-" xml:"-"`            // previous_secret
+	SecretRotatedOn     *Dairytime `json:"secret_rotated_on"`    // secret_rotated_on
+	CreatedOn           time.Time  `json:"created_on"`           // created_on
+	UpdatedOn           *Dairytime `json:"updated_on"`           // updated_on
+	ArchivedOn          *Dairytime `json:"archived_on"`          // archived_on
+
+	// EventType is only read from requests, and is deprecated in favor of EventTypes
+	EventType string `json:"event_type,omitempty"`
+}
+
+// WebhookSecretResponse is the only response a webhook's secret is ever shown in,
+// and it's only sent when the secret is generated or rotated
+type WebhookSecretResponse struct {
+	Webhook
+	Secret string `json:"secret"`
+}
+
+// WebhookPingResult is how a webhook responded to a one-off request, like a ping or a verification handshake.
+// What the webhook sent back is left out, just like it is from WebhookExecutionLog.
+type WebhookPingResult struct {
+	StatusCode int   `json:"status_code"`
+	LatencyMS  int64 `json:"latency_ms"`
+	Succeeded  bool  `json:"succeeded"`
+}
+
+// WebhookVerificationResponse reports the outcome of a webhook's verification handshake
+type WebhookVerificationResponse struct {
+	Webhook
+	Verified  bool               `json:"verified"`
+	Execution *WebhookPingResult `json:"execution"`
 }
 
 // WebhookCreationInput is a struct to use for creating Webhooks
 type WebhookCreationInput struct {
-	URL         string `json:"url,omitempty"`          // url
-	EventType   string `json:"event_type,omitempty"`   // event_type
-	ContentType string `json:"content_type,omitempty"` // content_type
+	URL         string   `json:"url,omitempty"`          // url
+	EventTypes  []string `json:"event_types,omitempty"`  // event_types
+	ContentType string   `json:"content_type,omitempty"` // content_type
+	// EventType is deprecated in favor of EventTypes, and is added to them
+	EventType string `json:"event_type,omitempty"`
+	// Fields limits payloads to the fields listed, like `price` or `options.name`
+	Fields []string `json:"fields,omitempty"` // fields
+	// Filter is an expression events have to match to be sent, like `brand == "Acme" and quantity < 10`
+	Filter string `json:"filter,omitempty"` // filter
+	// Verify keeps the webhook pending until its URL echoes a challenge back
+	Verify bool `json:"verify,omitempty"`
 }
 
 // WebhookUpdateInput is a struct to use for updating Webhooks
 type WebhookUpdateInput struct {
-	URL         string `json:"url,omitempty"`          // url
-	EventType   string `json:"event_type,omitempty"`   // event_type
-	ContentType string `json:"content_type,omitempty"` // content_type
+	URL         string   `json:"url,omitempty"`          // url
+	EventTypes  []string `json:"event_types,omitempty"`  // event_types
+	ContentType string   `json:"content_type,omitempty"` // content_type
+	Fields      []string `json:"fields,omitempty"`       // fields
+	Filter      string   `json:"filter,omitempty"`       // filter
+	// EventType is deprecated in favor of EventTypes, and is added to them
+	EventType string `json:"event_type,omitempty"`
 }
 
 type WebhookListResponse struct {
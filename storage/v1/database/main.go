@@ -55,6 +55,7 @@ type Storer interface {
 	ArchiveProductVariantBridgesWithProductRootID(Querier, uint64) (time.Time, error)
 	DeleteProductVariantBridgeByProductID(Querier, uint64) (time.Time, error)
 	CreateMultipleProductVariantBridgesForProductID(Querier, uint64, []uint64) error
+	GetProductIDsByOptionValueID(Querier, uint64) ([]uint64, error)
 
 	// Discounts
 	GetDiscount(Querier, uint64) (*models.Discount, error)
@@ -77,6 +78,22 @@ type Storer interface {
 	GetUserByUsername(Querier, string) (*models.User, error)
 	UserWithUsernameExists(Querier, string) (bool, error)
 
+	// APIKeys
+	GetAPIKey(Querier, uint64) (*models.APIKey, error)
+	GetAPIKeyByHash(db Querier, keyHash string) (*models.APIKey, error)
+	GetAPIKeysByUserID(db Querier, userID uint64) ([]models.APIKey, error)
+	CreateAPIKey(Querier, *models.APIKey) (newID uint64, createdOn time.Time, e error)
+	MarkAPIKeyUsed(Querier, uint64) (time.Time, error)
+	DeleteAPIKey(Querier, uint64) (time.Time, error)
+
+	// Roles
+	GetRole(Querier, uint64) (*models.Role, error)
+	GetRoles(db Querier) ([]models.Role, error)
+	GetRolesByUserID(db Querier, userID uint64) ([]models.Role, error)
+	GetPermissionsByUserID(db Querier, userID uint64) ([]string, error)
+	AssignRoleToUser(db Querier, userID uint64, roleID uint64) (time.Time, error)
+	RemoveRoleFromUser(db Querier, userID uint64, roleID uint64) error
+
 	// LoginAttempts
 	GetLoginAttempt(Querier, uint64) (*models.LoginAttempt, error)
 	GetLoginAttemptList(Querier, *models.QueryFilter) ([]models.LoginAttempt, error)
@@ -95,17 +112,40 @@ type Storer interface {
 	CreateWebhook(Querier, *models.Webhook) (newID uint64, createdOn time.Time, e error)
 	UpdateWebhook(Querier, *models.Webhook) (time.Time, error)
 	DeleteWebhook(Querier, uint64) (time.Time, error)
+	RotateWebhookSecret(db Querier, id uint64, secret string) (time.Time, error)
+	UpdateWebhookStatus(db Querier, id uint64, status string) (time.Time, error)
+	IncrementWebhookFailures(db Querier, id uint64) (int, error)
+	ResetWebhookFailures(db Querier, id uint64) error
+	PauseWebhook(db Querier, id uint64, until time.Time) error
 	GetWebhooksByEventType(db Querier, eventType string) ([]models.Webhook, error)
 
 	// WebhookExecutionLogs
 	GetWebhookExecutionLog(Querier, uint64) (*models.WebhookExecutionLog, error)
 	GetWebhookExecutionLogList(Querier, *models.QueryFilter) ([]models.WebhookExecutionLog, error)
 	GetWebhookExecutionLogCount(Querier, *models.QueryFilter) (uint64, error)
+	GetWebhookExecutionLogsByWebhookID(db Querier, webhookID uint64, qf *models.QueryFilter) ([]models.WebhookExecutionLog, error)
+	GetWebhookExecutionLogCountByWebhookID(db Querier, webhookID uint64, qf *models.QueryFilter) (uint64, error)
 	WebhookExecutionLogExists(Querier, uint64) (bool, error)
 	CreateWebhookExecutionLog(Querier, *models.WebhookExecutionLog) (newID uint64, createdOn time.Time, e error)
 	UpdateWebhookExecutionLog(Querier, *models.WebhookExecutionLog) (time.Time, error)
 	DeleteWebhookExecutionLog(Querier, uint64) (time.Time, error)
 
+	// WebhookDeliveries
+	GetWebhookDelivery(Querier, uint64) (*models.WebhookDelivery, error)
+	GetWebhookDeliveryList(Querier, *models.QueryFilter) ([]models.WebhookDelivery, error)
+	GetWebhookDeliveryCount(Querier, *models.QueryFilter) (uint64, error)
+	CreateWebhookDelivery(Querier, *models.WebhookDelivery) (newID uint64, createdOn time.Time, e error)
+	UpdateWebhookDelivery(Querier, *models.WebhookDelivery) (time.Time, error)
+	ClaimPendingWebhookDeliveries(db Querier, limit uint, lease time.Duration) ([]models.WebhookDelivery, error)
+
+	// Events
+	CreateEvent(Querier, *models.Event) (newID uint64, createdOn time.Time, e error)
+	GetEventsAfter(db Querier, afterPosition uint64, eventTypes []string, limit uint) ([]models.Event, error)
+	GetLatestEventPosition(Querier) (uint64, error)
+
+	// Changes
+	GetChangesAfter(db Querier, afterPosition uint64, limit uint) ([]models.Change, error)
+
 	// ProductImages
 	GetProductImage(Querier, uint64) (*models.ProductImage, error)
 	GetProductImageList(Querier, *models.QueryFilter) ([]models.ProductImage, error)
@@ -115,7 +155,13 @@ type Storer interface {
 	UpdateProductImage(Querier, *models.ProductImage) (time.Time, error)
 	DeleteProductImage(Querier, uint64) (time.Time, error)
 	GetProductImagesByProductID(Querier, uint64) ([]models.ProductImage, error)
+	GetProductImagesByProductRootID(Querier, uint64) ([]models.ProductImage, error)
+	GetProductImagesByContentHash(Querier, string) ([]models.ProductImage, error)
+	GetHashedProductImages(Querier) ([]models.ProductImage, error)
+	GetProductImagesAfterID(db Querier, afterID uint64, limit uint) ([]models.ProductImage, error)
 	SetPrimaryProductImageForProduct(Querier, uint64, uint64) (time.Time, error)
+	ClearPrimaryProductImageForProduct(Querier, uint64) (time.Time, error)
+	ArchiveProductImagesWithProductRootID(Querier, uint64) (time.Time, error)
 
 	// ProductImageBridge
 	GetProductImageBridge(Querier, uint64) (*models.ProductImageBridge, error)
@@ -125,6 +171,8 @@ type Storer interface {
 	CreateProductImageBridge(Querier, *models.ProductImageBridge) (newID uint64, createdOn time.Time, e error)
 	UpdateProductImageBridge(Querier, *models.ProductImageBridge) (time.Time, error)
 	DeleteProductImageBridge(Querier, uint64) (time.Time, error)
+	CreateMultipleProductImageBridgesForProductID(Querier, uint64, []uint64) error
+	DeleteProductImageBridgeByProductIDAndImageID(Querier, uint64, uint64) (time.Time, error)
 
 	// ProductRoots
 	GetProductRoot(Querier, uint64) (*models.ProductRoot, error)
@@ -0,0 +1,38 @@
+package dairymock
+
+import (
+	"time"
+
+	"github.com/dairycart/dairycart/models/v1"
+	"github.com/dairycart/dairycart/storage/v1/database"
+)
+
+func (m *MockDB) GetAPIKey(db database.Querier, id uint64) (*models.APIKey, error) {
+	args := m.Called(db, id)
+	return args.Get(0).(*models.APIKey), args.Error(1)
+}
+
+func (m *MockDB) GetAPIKeyByHash(db database.Querier, keyHash string) (*models.APIKey, error) {
+	args := m.Called(db, keyHash)
+	return args.Get(0).(*models.APIKey), args.Error(1)
+}
+
+func (m *MockDB) GetAPIKeysByUserID(db database.Querier, userID uint64) ([]models.APIKey, error) {
+	args := m.Called(db, userID)
+	return args.Get(0).([]models.APIKey), args.Error(1)
+}
+
+func (m *MockDB) CreateAPIKey(db database.Querier, nu *models.APIKey) (uint64, time.Time, error) {
+	args := m.Called(db, nu)
+	return args.Get(0).(uint64), args.Get(1).(time.Time), args.Error(2)
+}
+
+func (m *MockDB) MarkAPIKeyUsed(db database.Querier, id uint64) (time.Time, error) {
+	args := m.Called(db, id)
+	return args.Get(0).(time.Time), args.Error(1)
+}
+
+func (m *MockDB) DeleteAPIKey(db database.Querier, id uint64) (time.Time, error) {
+	args := m.Called(db, id)
+	return args.Get(0).(time.Time), args.Error(1)
+}
@@ -0,0 +1,11 @@
+package dairymock
+
+import (
+	"github.com/dairycart/dairycart/models/v1"
+	"github.com/dairycart/dairycart/storage/v1/database"
+)
+
+func (m *MockDB) GetChangesAfter(db database.Querier, afterPosition uint64, limit uint) ([]models.Change, error) {
+	args := m.Called(db, afterPosition, limit)
+	return args.Get(0).([]models.Change), args.Error(1)
+}
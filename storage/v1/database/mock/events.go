@@ -0,0 +1,23 @@
+package dairymock
+
+import (
+	"time"
+
+	"github.com/dairycart/dairycart/models/v1"
+	"github.com/dairycart/dairycart/storage/v1/database"
+)
+
+func (m *MockDB) CreateEvent(db database.Querier, nu *models.Event) (uint64, time.Time, error) {
+	args := m.Called(db, nu)
+	return args.Get(0).(uint64), args.Get(1).(time.Time), args.Error(2)
+}
+
+func (m *MockDB) GetEventsAfter(db database.Querier, afterPosition uint64, eventTypes []string, limit uint) ([]models.Event, error) {
+	args := m.Called(db, afterPosition, eventTypes, limit)
+	return args.Get(0).([]models.Event), args.Error(1)
+}
+
+func (m *MockDB) GetLatestEventPosition(db database.Querier) (uint64, error) {
+	args := m.Called(db)
+	return args.Get(0).(uint64), args.Error(1)
+}
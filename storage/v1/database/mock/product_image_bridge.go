@@ -41,3 +41,13 @@ func (m *MockDB) DeleteProductImageBridge(db database.Querier, id uint64) (time.
 	args := m.Called(db, id)
 	return args.Get(0).(time.Time), args.Error(1)
 }
+
+func (m *MockDB) CreateMultipleProductImageBridgesForProductID(db database.Querier, productID uint64, imageIDs []uint64) error {
+	args := m.Called(db, productID, imageIDs)
+	return args.Error(0)
+}
+
+func (m *MockDB) DeleteProductImageBridgeByProductIDAndImageID(db database.Querier, productID uint64, imageID uint64) (time.Time, error) {
+	args := m.Called(db, productID, imageID)
+	return args.Get(0).(time.Time), args.Error(1)
+}
@@ -12,11 +12,41 @@ func (m *MockDB) GetProductImagesByProductID(db database.Querier, productID uint
 	return args.Get(0).([]models.ProductImage), args.Error(1)
 }
 
+func (m *MockDB) GetProductImagesByProductRootID(db database.Querier, productRootID uint64) ([]models.ProductImage, error) {
+	args := m.Called(db, productRootID)
+	return args.Get(0).([]models.ProductImage), args.Error(1)
+}
+
+func (m *MockDB) GetProductImagesByContentHash(db database.Querier, contentHash string) ([]models.ProductImage, error) {
+	args := m.Called(db, contentHash)
+	return args.Get(0).([]models.ProductImage), args.Error(1)
+}
+
+func (m *MockDB) GetHashedProductImages(db database.Querier) ([]models.ProductImage, error) {
+	args := m.Called(db)
+	return args.Get(0).([]models.ProductImage), args.Error(1)
+}
+
+func (m *MockDB) GetProductImagesAfterID(db database.Querier, afterID uint64, limit uint) ([]models.ProductImage, error) {
+	args := m.Called(db, afterID, limit)
+	return args.Get(0).([]models.ProductImage), args.Error(1)
+}
+
 func (m *MockDB) SetPrimaryProductImageForProduct(db database.Querier, productID, imageID uint64) (time.Time, error) {
 	args := m.Called(db, productID, imageID)
 	return args.Get(0).(time.Time), args.Error(1)
 }
 
+func (m *MockDB) ArchiveProductImagesWithProductRootID(db database.Querier, id uint64) (time.Time, error) {
+	args := m.Called(db, id)
+	return args.Get(0).(time.Time), args.Error(1)
+}
+
+func (m *MockDB) ClearPrimaryProductImageForProduct(db database.Querier, productID uint64) (time.Time, error) {
+	args := m.Called(db, productID)
+	return args.Get(0).(time.Time), args.Error(1)
+}
+
 func (m *MockDB) ProductImageExists(db database.Querier, id uint64) (bool, error) {
 	args := m.Called(db, id)
 	return args.Bool(0), args.Error(1)
@@ -37,6 +37,11 @@ func (m *MockDB) CreateMultipleProductVariantBridgesForProductID(db database.Que
 	return args.Error(0)
 }
 
+func (m *MockDB) GetProductIDsByOptionValueID(db database.Querier, optionValueID uint64) ([]uint64, error) {
+	args := m.Called(db, optionValueID)
+	return args.Get(0).([]uint64), args.Error(1)
+}
+
 func (m *MockDB) UpdateProductVariantBridge(db database.Querier, updated *models.ProductVariantBridge) (time.Time, error) {
 	args := m.Called(db, updated)
 	return args.Get(0).(time.Time), args.Error(1)
@@ -0,0 +1,38 @@
+package dairymock
+
+import (
+	"time"
+
+	"github.com/dairycart/dairycart/models/v1"
+	"github.com/dairycart/dairycart/storage/v1/database"
+)
+
+func (m *MockDB) GetRole(db database.Querier, id uint64) (*models.Role, error) {
+	args := m.Called(db, id)
+	return args.Get(0).(*models.Role), args.Error(1)
+}
+
+func (m *MockDB) GetRoles(db database.Querier) ([]models.Role, error) {
+	args := m.Called(db)
+	return args.Get(0).([]models.Role), args.Error(1)
+}
+
+func (m *MockDB) GetRolesByUserID(db database.Querier, userID uint64) ([]models.Role, error) {
+	args := m.Called(db, userID)
+	return args.Get(0).([]models.Role), args.Error(1)
+}
+
+func (m *MockDB) GetPermissionsByUserID(db database.Querier, userID uint64) ([]string, error) {
+	args := m.Called(db, userID)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockDB) AssignRoleToUser(db database.Querier, userID uint64, roleID uint64) (time.Time, error) {
+	args := m.Called(db, userID, roleID)
+	return args.Get(0).(time.Time), args.Error(1)
+}
+
+func (m *MockDB) RemoveRoleFromUser(db database.Querier, userID uint64, roleID uint64) error {
+	args := m.Called(db, userID, roleID)
+	return args.Error(0)
+}
@@ -0,0 +1,38 @@
+package dairymock
+
+import (
+	"time"
+
+	"github.com/dairycart/dairycart/models/v1"
+	"github.com/dairycart/dairycart/storage/v1/database"
+)
+
+func (m *MockDB) GetWebhookDelivery(db database.Querier, id uint64) (*models.WebhookDelivery, error) {
+	args := m.Called(db, id)
+	return args.Get(0).(*models.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockDB) GetWebhookDeliveryList(db database.Querier, qf *models.QueryFilter) ([]models.WebhookDelivery, error) {
+	args := m.Called(db, qf)
+	return args.Get(0).([]models.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockDB) GetWebhookDeliveryCount(db database.Querier, qf *models.QueryFilter) (uint64, error) {
+	args := m.Called(db, qf)
+	return args.Get(0).(uint64), args.Error(1)
+}
+
+func (m *MockDB) CreateWebhookDelivery(db database.Querier, nu *models.WebhookDelivery) (uint64, time.Time, error) {
+	args := m.Called(db, nu)
+	return args.Get(0).(uint64), args.Get(1).(time.Time), args.Error(2)
+}
+
+func (m *MockDB) UpdateWebhookDelivery(db database.Querier, updated *models.WebhookDelivery) (time.Time, error) {
+	args := m.Called(db, updated)
+	return args.Get(0).(time.Time), args.Error(1)
+}
+
+func (m *MockDB) ClaimPendingWebhookDeliveries(db database.Querier, limit uint, lease time.Duration) ([]models.WebhookDelivery, error) {
+	args := m.Called(db, limit, lease)
+	return args.Get(0).([]models.WebhookDelivery), args.Error(1)
+}
@@ -27,6 +27,16 @@ func (m *MockDB) GetWebhookExecutionLogCount(db database.Querier, qf *models.Que
 	return args.Get(0).(uint64), args.Error(1)
 }
 
+func (m *MockDB) GetWebhookExecutionLogsByWebhookID(db database.Querier, webhookID uint64, qf *models.QueryFilter) ([]models.WebhookExecutionLog, error) {
+	args := m.Called(db, webhookID, qf)
+	return args.Get(0).([]models.WebhookExecutionLog), args.Error(1)
+}
+
+func (m *MockDB) GetWebhookExecutionLogCountByWebhookID(db database.Querier, webhookID uint64, qf *models.QueryFilter) (uint64, error) {
+	args := m.Called(db, webhookID, qf)
+	return args.Get(0).(uint64), args.Error(1)
+}
+
 func (m *MockDB) CreateWebhookExecutionLog(db database.Querier, nu *models.WebhookExecutionLog) (uint64, time.Time, error) {
 	args := m.Called(db, nu)
 	return args.Get(0).(uint64), args.Get(1).(time.Time), args.Error(2)
@@ -42,6 +42,31 @@ func (m *MockDB) DeleteWebhook(db database.Querier, id uint64) (time.Time, error
 	return args.Get(0).(time.Time), args.Error(1)
 }
 
+func (m *MockDB) RotateWebhookSecret(db database.Querier, id uint64, secret string) (time.Time, error) {
+	args := m.Called(db, id, secret)
+	return args.Get(0).(time.Time), args.Error(1)
+}
+
+func (m *MockDB) UpdateWebhookStatus(db database.Querier, id uint64, status string) (time.Time, error) {
+	args := m.Called(db, id, status)
+	return args.Get(0).(time.Time), args.Error(1)
+}
+
+func (m *MockDB) IncrementWebhookFailures(db database.Querier, id uint64) (int, error) {
+	args := m.Called(db, id)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockDB) ResetWebhookFailures(db database.Querier, id uint64) error {
+	args := m.Called(db, id)
+	return args.Error(0)
+}
+
+func (m *MockDB) PauseWebhook(db database.Querier, id uint64, until time.Time) error {
+	args := m.Called(db, id, until)
+	return args.Error(0)
+}
+
 func (m *MockDB) GetWebhooksByEventType(db database.Querier, eventType string) ([]models.Webhook, error) {
 	args := m.Called(db, eventType)
 	return args.Get(0).([]models.Webhook), args.Error(1)
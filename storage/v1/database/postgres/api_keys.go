@@ -0,0 +1,164 @@
+package postgres
+
+import (
+	"time"
+
+	"github.com/dairycart/dairycart/models/v1"
+	"github.com/dairycart/dairycart/storage/v1/database"
+)
+
+const apiKeySelectionQuery = `
+    SELECT
+        id,
+        user_id,
+        name,
+        prefix,
+        key_hash,
+        last_used_on,
+        expires_on,
+        created_on,
+        updated_on,
+        archived_on
+    FROM
+        api_keys
+    WHERE
+        archived_on is null
+    AND
+        id = $1
+`
+
+func (pg *postgres) GetAPIKey(db database.Querier, id uint64) (*models.APIKey, error) {
+	k := &models.APIKey{}
+
+	err := db.QueryRow(apiKeySelectionQuery, id).Scan(&k.ID, &k.UserID, &k.Name, &k.Prefix, &k.KeyHash, &k.LastUsedOn, &k.ExpiresOn, &k.CreatedOn, &k.UpdatedOn, &k.ArchivedOn)
+
+	return k, err
+}
+
+const apiKeySelectionByHashQuery = `
+    SELECT
+        id,
+        user_id,
+        name,
+        prefix,
+        key_hash,
+        last_used_on,
+        expires_on,
+        created_on,
+        updated_on,
+        archived_on
+    FROM
+        api_keys
+    WHERE
+        archived_on is null
+    AND
+        key_hash = $1
+`
+
+// GetAPIKeyByHash returns the unrevoked API key with the given hash. Expired keys are still returned.
+func (pg *postgres) GetAPIKeyByHash(db database.Querier, keyHash string) (*models.APIKey, error) {
+	k := &models.APIKey{}
+
+	err := db.QueryRow(apiKeySelectionByHashQuery, keyHash).Scan(&k.ID, &k.UserID, &k.Name, &k.Prefix, &k.KeyHash, &k.LastUsedOn, &k.ExpiresOn, &k.CreatedOn, &k.UpdatedOn, &k.ArchivedOn)
+
+	return k, err
+}
+
+const apiKeysByUserIDQuery = `
+    SELECT
+        id,
+        user_id,
+        name,
+        prefix,
+        key_hash,
+        last_used_on,
+        expires_on,
+        created_on,
+        updated_on,
+        archived_on
+    FROM
+        api_keys
+    WHERE
+        archived_on is null
+    AND
+        user_id = $1
+    ORDER BY id
+`
+
+// GetAPIKeysByUserID returns a user's unrevoked API keys, oldest first
+func (pg *postgres) GetAPIKeysByUserID(db database.Querier, userID uint64) ([]models.APIKey, error) {
+	var list []models.APIKey
+
+	rows, err := db.Query(apiKeysByUserIDQuery, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var k models.APIKey
+		err := rows.Scan(
+			&k.ID,
+			&k.UserID,
+			&k.Name,
+			&k.Prefix,
+			&k.KeyHash,
+			&k.LastUsedOn,
+			&k.ExpiresOn,
+			&k.CreatedOn,
+			&k.UpdatedOn,
+			&k.ArchivedOn,
+		)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, k)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return list, err
+}
+
+const apiKeyCreationQuery = `
+    INSERT INTO api_keys
+        (
+            user_id, name, prefix, key_hash, expires_on
+        )
+    VALUES
+        (
+            $1, $2, $3, $4, $5
+        )
+    RETURNING
+        id, created_on;
+`
+
+func (pg *postgres) CreateAPIKey(db database.Querier, nu *models.APIKey) (createdID uint64, createdOn time.Time, err error) {
+	err = db.QueryRow(apiKeyCreationQuery, &nu.UserID, &nu.Name, &nu.Prefix, &nu.KeyHash, &nu.ExpiresOn).Scan(&createdID, &createdOn)
+	return createdID, createdOn, err
+}
+
+const apiKeyUsageQuery = `
+    UPDATE api_keys
+    SET last_used_on = NOW()
+    WHERE id = $1
+    RETURNING last_used_on
+`
+
+func (pg *postgres) MarkAPIKeyUsed(db database.Querier, id uint64) (t time.Time, err error) {
+	err = db.QueryRow(apiKeyUsageQuery, id).Scan(&t)
+	return t, err
+}
+
+const apiKeyDeletionQuery = `
+    UPDATE api_keys
+    SET archived_on = NOW()
+    WHERE id = $1
+    RETURNING archived_on
+`
+
+func (pg *postgres) DeleteAPIKey(db database.Querier, id uint64) (t time.Time, err error) {
+	err = db.QueryRow(apiKeyDeletionQuery, id).Scan(&t)
+	return t, err
+}
@@ -0,0 +1,201 @@
+package postgres
+
+import (
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	// internal dependencies
+	"github.com/dairycart/dairycart/models/v1"
+
+	// external dependencies
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+var apiKeyColumns = []string{
+	"id",
+	"user_id",
+	"name",
+	"prefix",
+	"key_hash",
+	"last_used_on",
+	"expires_on",
+	"created_on",
+	"updated_on",
+	"archived_on",
+}
+
+func apiKeyRowValues(k *models.APIKey) []driver.Value {
+	return []driver.Value{
+		k.ID,
+		k.UserID,
+		k.Name,
+		k.Prefix,
+		k.KeyHash,
+		nil,
+		nil,
+		k.CreatedOn,
+		nil,
+		nil,
+	}
+}
+
+func TestGetAPIKey(t *testing.T) {
+	t.Parallel()
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+	client := NewPostgres()
+	expected := &models.APIKey{ID: 1, UserID: 2, Name: "integration", Prefix: "dc_abcdefgh", KeyHash: "hash"}
+
+	t.Run("optimal behavior", func(t *testing.T) {
+		mock.ExpectQuery(formatQueryForSQLMock(apiKeySelectionQuery)).
+			WithArgs(expected.ID).
+			WillReturnRows(sqlmock.NewRows(apiKeyColumns).AddRow(apiKeyRowValues(expected)...))
+
+		actual, err := client.GetAPIKey(mockDB, expected.ID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, expected, actual, "expected API key did not match actual API key")
+		assert.Nil(t, mock.ExpectationsWereMet(), "not all database expectations were met")
+	})
+}
+
+func TestGetAPIKeyByHash(t *testing.T) {
+	t.Parallel()
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+	client := NewPostgres()
+	expected := &models.APIKey{ID: 1, UserID: 2, Prefix: "dc_abcdefgh", KeyHash: "hash"}
+
+	t.Run("optimal behavior", func(t *testing.T) {
+		mock.ExpectQuery(formatQueryForSQLMock(apiKeySelectionByHashQuery)).
+			WithArgs(expected.KeyHash).
+			WillReturnRows(sqlmock.NewRows(apiKeyColumns).AddRow(apiKeyRowValues(expected)...))
+
+		actual, err := client.GetAPIKeyByHash(mockDB, expected.KeyHash)
+
+		assert.NoError(t, err)
+		assert.Equal(t, expected, actual, "expected API key did not match actual API key")
+		assert.Nil(t, mock.ExpectationsWereMet(), "not all database expectations were met")
+	})
+}
+
+func TestGetAPIKeysByUserID(t *testing.T) {
+	t.Parallel()
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+	client := NewPostgres()
+	example := &models.APIKey{ID: 1, UserID: 2, Prefix: "dc_abcdefgh", KeyHash: "hash"}
+
+	t.Run("optimal behavior", func(t *testing.T) {
+		exampleRows := sqlmock.NewRows(apiKeyColumns).
+			AddRow(apiKeyRowValues(example)...).
+			AddRow(apiKeyRowValues(example)...)
+		mock.ExpectQuery(formatQueryForSQLMock(apiKeysByUserIDQuery)).
+			WithArgs(example.UserID).
+			WillReturnRows(exampleRows)
+
+		actual, err := client.GetAPIKeysByUserID(mockDB, example.UserID)
+
+		assert.NoError(t, err)
+		assert.Len(t, actual, 2)
+		assert.Nil(t, mock.ExpectationsWereMet(), "not all database expectations were met")
+	})
+
+	t.Run("with error executing query", func(t *testing.T) {
+		mock.ExpectQuery(formatQueryForSQLMock(apiKeysByUserIDQuery)).
+			WillReturnError(errors.New("pineapple on pizza"))
+
+		actual, err := client.GetAPIKeysByUserID(mockDB, example.UserID)
+
+		assert.NotNil(t, err)
+		assert.Nil(t, actual)
+		assert.Nil(t, mock.ExpectationsWereMet(), "not all database expectations were met")
+	})
+
+	t.Run("with error scanning values", func(t *testing.T) {
+		mock.ExpectQuery(formatQueryForSQLMock(apiKeysByUserIDQuery)).
+			WillReturnRows(sqlmock.NewRows([]string{"things"}).AddRow("stuff"))
+
+		actual, err := client.GetAPIKeysByUserID(mockDB, example.UserID)
+
+		assert.NotNil(t, err)
+		assert.Nil(t, actual)
+		assert.Nil(t, mock.ExpectationsWereMet(), "not all database expectations were met")
+	})
+}
+
+func TestCreateAPIKey(t *testing.T) {
+	t.Parallel()
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+	client := NewPostgres()
+	exampleInput := &models.APIKey{UserID: 2, Name: "integration", Prefix: "dc_abcdefgh", KeyHash: "hash"}
+
+	t.Run("optimal behavior", func(t *testing.T) {
+		expectedCreatedOn := buildTestTime(t)
+		mock.ExpectQuery(formatQueryForSQLMock(apiKeyCreationQuery)).
+			WithArgs(
+				exampleInput.UserID,
+				exampleInput.Name,
+				exampleInput.Prefix,
+				exampleInput.KeyHash,
+				exampleInput.ExpiresOn,
+			).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_on"}).AddRow(uint64(1), expectedCreatedOn))
+
+		actualID, actualCreatedOn, err := client.CreateAPIKey(mockDB, exampleInput)
+
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(1), actualID, "expected and actual IDs don't match")
+		assert.Equal(t, expectedCreatedOn, actualCreatedOn, "expected creation time did not match actual creation time")
+		assert.Nil(t, mock.ExpectationsWereMet(), "not all database expectations were met")
+	})
+}
+
+func TestMarkAPIKeyUsed(t *testing.T) {
+	t.Parallel()
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+	client := NewPostgres()
+
+	t.Run("optimal behavior", func(t *testing.T) {
+		expected := buildTestTime(t)
+		mock.ExpectQuery(formatQueryForSQLMock(apiKeyUsageQuery)).
+			WithArgs(uint64(1)).
+			WillReturnRows(sqlmock.NewRows([]string{"last_used_on"}).AddRow(expected))
+
+		actual, err := client.MarkAPIKeyUsed(mockDB, 1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, expected, actual, "expected last use time did not match actual last use time")
+		assert.Nil(t, mock.ExpectationsWereMet(), "not all database expectations were met")
+	})
+}
+
+func TestDeleteAPIKey(t *testing.T) {
+	t.Parallel()
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+	client := NewPostgres()
+
+	t.Run("optimal behavior", func(t *testing.T) {
+		expected := buildTestTime(t)
+		mock.ExpectQuery(formatQueryForSQLMock(apiKeyDeletionQuery)).
+			WithArgs(uint64(1)).
+			WillReturnRows(sqlmock.NewRows([]string{"archived_on"}).AddRow(expected))
+
+		actual, err := client.DeleteAPIKey(mockDB, 1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, expected, actual, "expected deletion time did not match actual deletion time")
+		assert.Nil(t, mock.ExpectationsWereMet(), "not all database expectations were met")
+	})
+}
@@ -0,0 +1,62 @@
+package postgres
+
+import (
+	"github.com/dairycart/dairycart/models/v1"
+	"github.com/dairycart/dairycart/storage/v1/database"
+)
+
+// the changes table is written to by triggers on each table in the change feed; see the changes migration
+const changesAfterQuery = `
+    SELECT
+        id,
+        position,
+        resource,
+        resource_id,
+        operation,
+        data,
+        changed_on
+    FROM
+        changes
+    WHERE
+        position > $1
+    ORDER BY position
+    LIMIT $2
+`
+
+// GetChangesAfter returns up to limit changes with positions greater than afterPosition, oldest first
+func (pg *postgres) GetChangesAfter(db database.Querier, afterPosition uint64, limit uint) ([]models.Change, error) {
+	var list []models.Change
+
+	rows, err := db.Query(changesAfterQuery, afterPosition, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			c    models.Change
+			data []byte
+		)
+		// tombstones have no data, and a RawMessage can't be scanned into directly when it's null
+		err := rows.Scan(
+			&c.ID,
+			&c.Position,
+			&c.Resource,
+			&c.ResourceID,
+			&c.Operation,
+			&data,
+			&c.ChangedOn,
+		)
+		if err != nil {
+			return nil, err
+		}
+		c.Data = data
+		list = append(list, c)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return list, err
+}
@@ -0,0 +1,73 @@
+package postgres
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	// internal dependencies
+	"github.com/dairycart/dairycart/models/v1"
+
+	// external dependencies
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+var changeColumns = []string{
+	"id",
+	"position",
+	"resource",
+	"resource_id",
+	"operation",
+	"data",
+	"changed_on",
+}
+
+func TestGetChangesAfter(t *testing.T) {
+	t.Parallel()
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+	client := NewPostgres()
+
+	t.Run("optimal behavior", func(t *testing.T) {
+		exampleRows := sqlmock.NewRows(changeColumns).
+			AddRow(uint64(4), uint64(14), "product", uint64(1), models.ChangeUpdated, []byte(`{"id": 1}`), buildTestTime(t)).
+			AddRow(uint64(5), uint64(15), "product", uint64(1), models.ChangeArchived, nil, buildTestTime(t))
+		mock.ExpectQuery(formatQueryForSQLMock(changesAfterQuery)).
+			WithArgs(uint64(3), uint(10)).
+			WillReturnRows(exampleRows)
+
+		actual, err := client.GetChangesAfter(mockDB, 3, 10)
+
+		assert.NoError(t, err)
+		assert.Len(t, actual, 2)
+		assert.Equal(t, uint64(15), actual[1].Position)
+		assert.Equal(t, json.RawMessage(`{"id": 1}`), actual[0].Data)
+		assert.Nil(t, actual[1].Data, "tombstones shouldn't have data")
+		assert.Nil(t, mock.ExpectationsWereMet(), "not all database expectations were met")
+	})
+
+	t.Run("with error executing query", func(t *testing.T) {
+		mock.ExpectQuery(formatQueryForSQLMock(changesAfterQuery)).
+			WillReturnError(errors.New("pineapple on pizza"))
+
+		actual, err := client.GetChangesAfter(mockDB, 3, 10)
+
+		assert.NotNil(t, err)
+		assert.Nil(t, actual)
+		assert.Nil(t, mock.ExpectationsWereMet(), "not all database expectations were met")
+	})
+
+	t.Run("with error scanning values", func(t *testing.T) {
+		exampleRows := sqlmock.NewRows([]string{"things"}).AddRow("stuff")
+		mock.ExpectQuery(formatQueryForSQLMock(changesAfterQuery)).
+			WillReturnRows(exampleRows)
+
+		actual, err := client.GetChangesAfter(mockDB, 3, 10)
+
+		assert.NotNil(t, err)
+		assert.Nil(t, actual)
+		assert.Nil(t, mock.ExpectationsWereMet(), "not all database expectations were met")
+	})
+}
@@ -0,0 +1,94 @@
+package postgres
+
+import (
+	"time"
+
+	"github.com/dairycart/dairycart/models/v1"
+	"github.com/dairycart/dairycart/storage/v1/database"
+
+	"github.com/lib/pq"
+)
+
+// eventCreationQuery doesn't give the event its position. That's done by a deferred trigger as the
+// transaction commits, so events become visible in the order of their positions.
+const eventCreationQuery = `
+    INSERT INTO events
+        (
+            event_type, payload
+        )
+    VALUES
+        (
+            $1, $2
+        )
+    RETURNING
+        id, created_on;
+`
+
+func (pg *postgres) CreateEvent(db database.Querier, nu *models.Event) (createdID uint64, createdOn time.Time, err error) {
+	err = db.QueryRow(eventCreationQuery, &nu.EventType, &nu.Payload).Scan(&createdID, &createdOn)
+	return createdID, createdOn, err
+}
+
+const eventsAfterQuery = `
+    SELECT
+        id,
+        position,
+        event_type,
+        payload,
+        created_on
+    FROM
+        events
+    WHERE
+        position > $1
+    AND
+        ($2::text[] IS NULL OR ARRAY[event_type, split_part(event_type, '.', 1) || '.*', '*'] && $2::text[])
+    ORDER BY position
+    LIMIT $3
+`
+
+// GetEventsAfter returns up to limit events with positions greater than afterPosition, oldest first. If eventTypes
+// isn't empty, only events it names, either by name, through a wildcard for the event's resource (like `product.*`),
+// or through `*`, are returned.
+func (pg *postgres) GetEventsAfter(db database.Querier, afterPosition uint64, eventTypes []string, limit uint) ([]models.Event, error) {
+	var list []models.Event
+
+	var types interface{}
+	if len(eventTypes) > 0 {
+		types = pq.Array(eventTypes)
+	}
+
+	rows, err := db.Query(eventsAfterQuery, afterPosition, types, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var e models.Event
+		err := rows.Scan(
+			&e.ID,
+			&e.Position,
+			&e.EventType,
+			&e.Payload,
+			&e.CreatedOn,
+		)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, e)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return list, err
+}
+
+const latestEventPositionQuery = `SELECT COALESCE(MAX(position), 0) FROM events`
+
+// GetLatestEventPosition returns the position of the most recent event, or zero if there aren't any
+func (pg *postgres) GetLatestEventPosition(db database.Querier) (uint64, error) {
+	var position uint64
+	err := db.QueryRow(latestEventPositionQuery).Scan(&position)
+	return position, err
+}
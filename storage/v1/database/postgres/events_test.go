@@ -0,0 +1,128 @@
+package postgres
+
+import (
+	"errors"
+	"testing"
+
+	// internal dependencies
+	"github.com/dairycart/dairycart/models/v1"
+
+	// external dependencies
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+var eventColumns = []string{
+	"id",
+	"position",
+	"event_type",
+	"payload",
+	"created_on",
+}
+
+func TestCreateEvent(t *testing.T) {
+	t.Parallel()
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+	exampleInput := &models.Event{
+		EventType: "product.created",
+		Payload:   "{}",
+	}
+	client := NewPostgres()
+
+	t.Run("optimal behavior", func(t *testing.T) {
+		expectedCreatedOn := buildTestTime(t)
+		mock.ExpectQuery(formatQueryForSQLMock(eventCreationQuery)).
+			WithArgs(exampleInput.EventType, exampleInput.Payload).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_on"}).AddRow(uint64(1), expectedCreatedOn))
+
+		actualID, actualCreatedOn, err := client.CreateEvent(mockDB, exampleInput)
+
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(1), actualID, "expected and actual IDs don't match")
+		assert.Equal(t, expectedCreatedOn, actualCreatedOn, "expected creation time did not match actual creation time")
+		assert.Nil(t, mock.ExpectationsWereMet(), "not all database expectations were met")
+	})
+}
+
+func TestGetEventsAfter(t *testing.T) {
+	t.Parallel()
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+	client := NewPostgres()
+
+	t.Run("optimal behavior", func(t *testing.T) {
+		exampleRows := sqlmock.NewRows(eventColumns).
+			AddRow(uint64(4), uint64(14), "product.created", "{}", buildTestTime(t)).
+			AddRow(uint64(5), uint64(15), "product.updated", "{}", buildTestTime(t))
+		mock.ExpectQuery(formatQueryForSQLMock(eventsAfterQuery)).
+			WithArgs(uint64(3), `{"product.*"}`, uint(10)).
+			WillReturnRows(exampleRows)
+
+		actual, err := client.GetEventsAfter(mockDB, 3, []string{"product.*"}, 10)
+
+		assert.NoError(t, err)
+		assert.Len(t, actual, 2)
+		assert.Equal(t, uint64(5), actual[1].ID)
+		assert.Equal(t, uint64(15), actual[1].Position)
+		assert.Nil(t, mock.ExpectationsWereMet(), "not all database expectations were met")
+	})
+
+	t.Run("without event types", func(t *testing.T) {
+		exampleRows := sqlmock.NewRows(eventColumns).
+			AddRow(uint64(4), uint64(14), "product.created", "{}", buildTestTime(t))
+		mock.ExpectQuery(formatQueryForSQLMock(eventsAfterQuery)).
+			WithArgs(uint64(3), nil, uint(10)).
+			WillReturnRows(exampleRows)
+
+		actual, err := client.GetEventsAfter(mockDB, 3, nil, 10)
+
+		assert.NoError(t, err)
+		assert.Len(t, actual, 1)
+		assert.Nil(t, mock.ExpectationsWereMet(), "not all database expectations were met")
+	})
+
+	t.Run("with error executing query", func(t *testing.T) {
+		mock.ExpectQuery(formatQueryForSQLMock(eventsAfterQuery)).
+			WillReturnError(errors.New("pineapple on pizza"))
+
+		actual, err := client.GetEventsAfter(mockDB, 3, nil, 10)
+
+		assert.NotNil(t, err)
+		assert.Nil(t, actual)
+		assert.Nil(t, mock.ExpectationsWereMet(), "not all database expectations were met")
+	})
+
+	t.Run("with error scanning values", func(t *testing.T) {
+		exampleRows := sqlmock.NewRows([]string{"things"}).AddRow("stuff")
+		mock.ExpectQuery(formatQueryForSQLMock(eventsAfterQuery)).
+			WillReturnRows(exampleRows)
+
+		actual, err := client.GetEventsAfter(mockDB, 3, nil, 10)
+
+		assert.NotNil(t, err)
+		assert.Nil(t, actual)
+		assert.Nil(t, mock.ExpectationsWereMet(), "not all database expectations were met")
+	})
+}
+
+func TestGetLatestEventPosition(t *testing.T) {
+	t.Parallel()
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+	client := NewPostgres()
+
+	t.Run("optimal behavior", func(t *testing.T) {
+		mock.ExpectQuery(formatQueryForSQLMock(latestEventPositionQuery)).
+			WillReturnRows(sqlmock.NewRows([]string{"position"}).AddRow(uint64(12)))
+
+		actual, err := client.GetLatestEventPosition(mockDB)
+
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(12), actual)
+		assert.Nil(t, mock.ExpectationsWereMet(), "not all database expectations were met")
+	})
+}
@@ -0,0 +1,6 @@
+ALTER TABLE IF EXISTS "product_option_values"
+    DROP COLUMN "abbreviation";
+
+ALTER TABLE IF EXISTS "product_roots"
+    DROP COLUMN "option_summary_template",
+    DROP COLUMN "sku_template";
\ No newline at end of file
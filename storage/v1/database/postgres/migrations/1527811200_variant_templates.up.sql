@@ -0,0 +1,6 @@
+ALTER TABLE IF EXISTS "product_roots"
+    ADD COLUMN "sku_template" text NOT NULL DEFAULT '',
+    ADD COLUMN "option_summary_template" text NOT NULL DEFAULT '';
+
+ALTER TABLE IF EXISTS "product_option_values"
+    ADD COLUMN "abbreviation" text NOT NULL DEFAULT '';
\ No newline at end of file
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS product_image_bridge_active_idx;
+
+DELETE FROM product_image_bridge WHERE archived_on IS NOT NULL;
+
+ALTER TABLE IF EXISTS "product_image_bridge"
+    DROP COLUMN IF EXISTS "archived_on",
+    DROP COLUMN IF EXISTS "created_on",
+    ADD UNIQUE ("product_id", "product_image_id");
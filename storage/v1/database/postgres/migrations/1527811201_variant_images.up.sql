@@ -0,0 +1,6 @@
+ALTER TABLE IF EXISTS "product_image_bridge"
+    ADD COLUMN "created_on" timestamp NOT NULL DEFAULT NOW(),
+    ADD COLUMN "archived_on" timestamp,
+    DROP CONSTRAINT IF EXISTS "product_image_bridge_product_id_product_image_id_key";
+
+CREATE UNIQUE INDEX product_image_bridge_active_idx ON product_image_bridge (product_id, product_image_id) WHERE archived_on IS NULL;
@@ -0,0 +1,2 @@
+ALTER TABLE IF EXISTS "product_images"
+    DROP COLUMN "sort_order";
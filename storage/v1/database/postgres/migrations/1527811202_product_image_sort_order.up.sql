@@ -0,0 +1,2 @@
+ALTER TABLE IF EXISTS "product_images"
+    ADD COLUMN "sort_order" integer NOT NULL DEFAULT 0;
@@ -0,0 +1,5 @@
+ALTER TABLE IF EXISTS "product_images"
+    ADD COLUMN "renditions" jsonb NOT NULL DEFAULT '{}'::jsonb;
+
+UPDATE "product_images"
+    SET "renditions" = jsonb_build_object('thumbnail', "thumbnail_url", 'main', "main_url", 'original', "original_url");
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS "product_images_content_hash_idx";
+
+ALTER TABLE IF EXISTS "product_images"
+    DROP COLUMN IF EXISTS "content_hash",
+    DROP COLUMN IF EXISTS "perceptual_hash";
@@ -0,0 +1,5 @@
+ALTER TABLE IF EXISTS "product_images"
+    ADD COLUMN "content_hash" text NOT NULL DEFAULT '',
+    ADD COLUMN "perceptual_hash" text NOT NULL DEFAULT '';
+
+CREATE INDEX "product_images_content_hash_idx" ON "product_images" ("content_hash") WHERE "content_hash" <> '' AND "archived_on" IS NULL;
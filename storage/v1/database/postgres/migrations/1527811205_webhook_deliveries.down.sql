@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS "webhook_deliveries_pending_idx";
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TYPE IF EXISTS webhook_delivery_status;
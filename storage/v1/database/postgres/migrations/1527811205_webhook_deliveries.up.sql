@@ -0,0 +1,22 @@
+CREATE TYPE webhook_delivery_status AS ENUM ('pending', 'delivered', 'dead');
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    "id" bigserial,
+    "webhook_id" bigint NOT NULL,
+    "event_type" text NOT NULL,
+    "content_type" text NOT NULL DEFAULT 'application/json',
+    "payload" text NOT NULL,
+    "status" webhook_delivery_status NOT NULL DEFAULT 'pending',
+    "attempts" int NOT NULL DEFAULT 0,
+    "next_attempt_on" timestamp NOT NULL DEFAULT NOW(),
+    "locked_until" timestamp,
+    "last_status_code" int NOT NULL DEFAULT 0,
+    "last_error" text NOT NULL DEFAULT '',
+    "created_on" timestamp NOT NULL DEFAULT NOW(),
+    "updated_on" timestamp,
+    "delivered_on" timestamp,
+    PRIMARY KEY ("id"),
+    FOREIGN KEY ("webhook_id") REFERENCES "webhooks"("id")
+);
+
+CREATE INDEX "webhook_deliveries_pending_idx" ON "webhook_deliveries" ("next_attempt_on") WHERE "status" = 'pending';
@@ -0,0 +1,3 @@
+ALTER TABLE webhooks DROP COLUMN IF EXISTS "secret_rotated_on";
+ALTER TABLE webhooks DROP COLUMN IF EXISTS "previous_secret";
+ALTER TABLE webhooks DROP COLUMN IF EXISTS "secret";
@@ -0,0 +1,3 @@
+ALTER TABLE webhooks ADD COLUMN "secret" text NOT NULL DEFAULT '';
+ALTER TABLE webhooks ADD COLUMN "previous_secret" text NOT NULL DEFAULT '';
+ALTER TABLE webhooks ADD COLUMN "secret_rotated_on" timestamp;
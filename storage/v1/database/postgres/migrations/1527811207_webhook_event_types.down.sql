@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS "webhooks_event_types_idx";
+
+CREATE TYPE webhook_event AS ENUM ('product_created', 'product_updated', 'product_archived');
+ALTER TABLE webhooks ADD COLUMN "event_type" webhook_event;
+UPDATE webhooks SET "event_type" = replace("event_types"[1], '.', '_')::webhook_event
+    WHERE "event_types"[1] IN ('product.created', 'product.updated', 'product.archived');
+-- the old enum can't represent anything else, so those webhooks can't be kept around
+UPDATE webhooks SET "event_type" = 'product_updated', "archived_on" = COALESCE("archived_on", NOW())
+    WHERE "event_type" IS NULL;
+ALTER TABLE webhooks ALTER COLUMN "event_type" SET NOT NULL;
+ALTER TABLE webhooks DROP COLUMN "event_types";
@@ -0,0 +1,6 @@
+ALTER TABLE webhooks ADD COLUMN "event_types" text[] NOT NULL DEFAULT '{}';
+UPDATE webhooks SET "event_types" = ARRAY[replace("event_type"::text, '_', '.')];
+ALTER TABLE webhooks DROP COLUMN "event_type";
+DROP TYPE IF EXISTS webhook_event;
+
+CREATE INDEX "webhooks_event_types_idx" ON "webhooks" USING GIN ("event_types");
@@ -0,0 +1,13 @@
+DROP INDEX IF EXISTS "webhook_execution_logs_webhook_id_idx";
+
+ALTER TABLE webhook_execution_logs DROP COLUMN IF EXISTS "archived_on";
+ALTER TABLE webhook_execution_logs DROP COLUMN IF EXISTS "updated_on";
+ALTER TABLE webhook_execution_logs DROP COLUMN IF EXISTS "error";
+ALTER TABLE webhook_execution_logs DROP COLUMN IF EXISTS "latency_ms";
+ALTER TABLE webhook_execution_logs DROP COLUMN IF EXISTS "response_body";
+ALTER TABLE webhook_execution_logs DROP COLUMN IF EXISTS "request_body";
+ALTER TABLE webhook_execution_logs DROP COLUMN IF EXISTS "request_headers";
+ALTER TABLE webhook_execution_logs DROP COLUMN IF EXISTS "request_url";
+ALTER TABLE webhook_execution_logs DROP COLUMN IF EXISTS "attempt";
+ALTER TABLE webhook_execution_logs DROP COLUMN IF EXISTS "event_type";
+ALTER TABLE webhook_execution_logs DROP COLUMN IF EXISTS "delivery_id";
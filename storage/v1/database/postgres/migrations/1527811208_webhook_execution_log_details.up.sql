@@ -0,0 +1,13 @@
+ALTER TABLE webhook_execution_logs ADD COLUMN "delivery_id" bigint REFERENCES "webhook_deliveries"("id");
+ALTER TABLE webhook_execution_logs ADD COLUMN "event_type" text NOT NULL DEFAULT '';
+ALTER TABLE webhook_execution_logs ADD COLUMN "attempt" int NOT NULL DEFAULT 0;
+ALTER TABLE webhook_execution_logs ADD COLUMN "request_url" text NOT NULL DEFAULT '';
+ALTER TABLE webhook_execution_logs ADD COLUMN "request_headers" jsonb NOT NULL DEFAULT '{}';
+ALTER TABLE webhook_execution_logs ADD COLUMN "request_body" text NOT NULL DEFAULT '';
+ALTER TABLE webhook_execution_logs ADD COLUMN "response_body" text NOT NULL DEFAULT '';
+ALTER TABLE webhook_execution_logs ADD COLUMN "latency_ms" bigint NOT NULL DEFAULT 0;
+ALTER TABLE webhook_execution_logs ADD COLUMN "error" text NOT NULL DEFAULT '';
+ALTER TABLE webhook_execution_logs ADD COLUMN "updated_on" timestamp;
+ALTER TABLE webhook_execution_logs ADD COLUMN "archived_on" timestamp;
+
+CREATE INDEX "webhook_execution_logs_webhook_id_idx" ON "webhook_execution_logs" ("webhook_id", "executed_on" DESC);
@@ -0,0 +1,3 @@
+ALTER TABLE webhooks DROP COLUMN IF EXISTS "status";
+
+DROP TYPE IF EXISTS webhook_status;
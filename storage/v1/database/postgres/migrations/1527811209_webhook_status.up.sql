@@ -0,0 +1,3 @@
+CREATE TYPE webhook_status AS ENUM ('pending', 'active', 'disabled');
+
+ALTER TABLE webhooks ADD COLUMN "status" webhook_status NOT NULL DEFAULT 'active';
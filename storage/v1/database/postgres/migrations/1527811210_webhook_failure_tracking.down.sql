@@ -0,0 +1,2 @@
+ALTER TABLE webhooks DROP COLUMN IF EXISTS "paused_until";
+ALTER TABLE webhooks DROP COLUMN IF EXISTS "consecutive_failures";
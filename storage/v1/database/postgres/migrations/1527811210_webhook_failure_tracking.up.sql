@@ -0,0 +1,2 @@
+ALTER TABLE webhooks ADD COLUMN "consecutive_failures" int NOT NULL DEFAULT 0;
+ALTER TABLE webhooks ADD COLUMN "paused_until" timestamp;
@@ -0,0 +1,2 @@
+ALTER TABLE webhooks ADD COLUMN "fields" text[] NOT NULL DEFAULT '{}';
+ALTER TABLE webhooks ADD COLUMN "filter" text NOT NULL DEFAULT '';
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS events (
+    "id" bigserial,
+    "event_type" text NOT NULL,
+    "payload" text NOT NULL,
+    "created_on" timestamp NOT NULL DEFAULT NOW(),
+    PRIMARY KEY ("id")
+);
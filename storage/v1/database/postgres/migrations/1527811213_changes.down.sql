@@ -0,0 +1,9 @@
+DROP TRIGGER IF EXISTS product_images_changes ON product_images;
+DROP TRIGGER IF EXISTS discounts_changes ON discounts;
+DROP TRIGGER IF EXISTS product_option_values_changes ON product_option_values;
+DROP TRIGGER IF EXISTS product_options_changes ON product_options;
+DROP TRIGGER IF EXISTS products_changes ON products;
+DROP TRIGGER IF EXISTS product_roots_changes ON product_roots;
+DROP FUNCTION IF EXISTS record_change();
+DROP TABLE IF EXISTS changes;
+DROP TYPE IF EXISTS change_operation;
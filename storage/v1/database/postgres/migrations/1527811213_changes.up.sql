@@ -0,0 +1,72 @@
+CREATE TYPE change_operation AS ENUM ('created', 'updated', 'archived');
+
+CREATE TABLE IF NOT EXISTS changes (
+    "id" bigserial,
+    "resource" text NOT NULL,
+    "resource_id" bigint NOT NULL,
+    "operation" change_operation NOT NULL,
+    "data" jsonb,
+    "changed_on" timestamp NOT NULL DEFAULT NOW(),
+    PRIMARY KEY ("id")
+);
+
+-- the feed starts with everything that exists now, so a client reading it from the beginning ends up with the whole catalog
+INSERT INTO changes (resource, resource_id, operation, data)
+    SELECT 'product_root', id, 'created', to_jsonb(t) FROM product_roots t WHERE archived_on IS NULL ORDER BY id;
+INSERT INTO changes (resource, resource_id, operation, data)
+    SELECT 'product_image', id, 'created', to_jsonb(t) FROM product_images t WHERE archived_on IS NULL ORDER BY id;
+INSERT INTO changes (resource, resource_id, operation, data)
+    SELECT 'product_option', id, 'created', to_jsonb(t) FROM product_options t WHERE archived_on IS NULL ORDER BY id;
+INSERT INTO changes (resource, resource_id, operation, data)
+    SELECT 'product_option_value', id, 'created', to_jsonb(t) FROM product_option_values t WHERE archived_on IS NULL ORDER BY id;
+INSERT INTO changes (resource, resource_id, operation, data)
+    SELECT 'product', id, 'created', to_jsonb(t) FROM products t WHERE archived_on IS NULL ORDER BY id;
+INSERT INTO changes (resource, resource_id, operation, data)
+    SELECT 'discount', id, 'created', to_jsonb(t) FROM discounts t WHERE archived_on IS NULL ORDER BY id;
+
+-- record_change is called by a trigger on each table in the change feed, with the name of the table's
+-- resource as its argument. Archiving a row records a tombstone, which has no data.
+--
+-- The advisory lock is held until the writing transaction commits, so changes become visible in the order
+-- their ids were allocated, and a reader can't skip past a change that hasn't been committed yet.
+CREATE FUNCTION record_change() RETURNS trigger AS $$
+DECLARE
+    op change_operation;
+    row_data jsonb;
+BEGIN
+    PERFORM pg_advisory_xact_lock(hashtext('changes'));
+
+    IF TG_OP = 'INSERT' THEN
+        op := 'created';
+        row_data := to_jsonb(NEW);
+    ELSIF TG_OP = 'DELETE' OR (OLD.archived_on IS NULL AND NEW.archived_on IS NOT NULL) THEN
+        op := 'archived';
+    ELSIF OLD.archived_on IS NOT NULL AND NEW.archived_on IS NOT NULL THEN
+        -- changes to archived rows aren't interesting to anyone
+        RETURN NULL;
+    ELSE
+        op := 'updated';
+        row_data := to_jsonb(NEW);
+    END IF;
+
+    IF TG_OP = 'DELETE' THEN
+        INSERT INTO changes (resource, resource_id, operation, data) VALUES (TG_ARGV[0], OLD.id, op, row_data);
+    ELSE
+        INSERT INTO changes (resource, resource_id, operation, data) VALUES (TG_ARGV[0], NEW.id, op, row_data);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER product_roots_changes AFTER INSERT OR UPDATE OR DELETE ON product_roots
+    FOR EACH ROW EXECUTE PROCEDURE record_change('product_root');
+CREATE TRIGGER products_changes AFTER INSERT OR UPDATE OR DELETE ON products
+    FOR EACH ROW EXECUTE PROCEDURE record_change('product');
+CREATE TRIGGER product_options_changes AFTER INSERT OR UPDATE OR DELETE ON product_options
+    FOR EACH ROW EXECUTE PROCEDURE record_change('product_option');
+CREATE TRIGGER product_option_values_changes AFTER INSERT OR UPDATE OR DELETE ON product_option_values
+    FOR EACH ROW EXECUTE PROCEDURE record_change('product_option_value');
+CREATE TRIGGER discounts_changes AFTER INSERT OR UPDATE OR DELETE ON discounts
+    FOR EACH ROW EXECUTE PROCEDURE record_change('discount');
+CREATE TRIGGER product_images_changes AFTER INSERT OR UPDATE OR DELETE ON product_images
+    FOR EACH ROW EXECUTE PROCEDURE record_change('product_image');
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS api_keys (
+    "id" bigserial,
+    "user_id" bigint NOT NULL,
+    "name" text NOT NULL DEFAULT '',
+    "prefix" text NOT NULL,
+    "key_hash" text NOT NULL,
+    "last_used_on" timestamp,
+    "expires_on" timestamp,
+    "created_on" timestamp NOT NULL DEFAULT NOW(),
+    "updated_on" timestamp,
+    "archived_on" timestamp,
+    UNIQUE ("key_hash"),
+    PRIMARY KEY ("id"),
+    FOREIGN KEY ("user_id") REFERENCES "users"("id")
+);
+
+CREATE INDEX "api_keys_user_id_idx" ON "api_keys" ("user_id") WHERE "archived_on" IS NULL;
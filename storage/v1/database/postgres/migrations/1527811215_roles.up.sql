@@ -0,0 +1,50 @@
+CREATE TABLE IF NOT EXISTS roles (
+    "id" bigserial,
+    "name" text NOT NULL,
+    "description" text NOT NULL DEFAULT '',
+    "created_on" timestamp NOT NULL DEFAULT NOW(),
+    "updated_on" timestamp,
+    "archived_on" timestamp,
+    UNIQUE ("name"),
+    PRIMARY KEY ("id")
+);
+
+CREATE TABLE IF NOT EXISTS permissions (
+    "id" bigserial,
+    "role_id" bigint NOT NULL,
+    "name" text NOT NULL,
+    "created_on" timestamp NOT NULL DEFAULT NOW(),
+    UNIQUE ("role_id", "name"),
+    PRIMARY KEY ("id"),
+    FOREIGN KEY ("role_id") REFERENCES "roles"("id")
+);
+
+CREATE TABLE IF NOT EXISTS user_roles (
+    "user_id" bigint NOT NULL,
+    "role_id" bigint NOT NULL,
+    "created_on" timestamp NOT NULL DEFAULT NOW(),
+    PRIMARY KEY ("user_id", "role_id"),
+    FOREIGN KEY ("user_id") REFERENCES "users"("id"),
+    FOREIGN KEY ("role_id") REFERENCES "roles"("id")
+);
+
+INSERT INTO roles ("name", "description") VALUES
+    ('catalog_editor', 'Manages products, their options and their images'),
+    ('marketing', 'Manages discounts'),
+    ('support', 'Looks up users and resets their passwords'),
+    ('developer', 'Manages webhooks and API keys, and follows the event stream and change feed');
+
+INSERT INTO permissions ("role_id", "name")
+    SELECT r.id, p.name
+    FROM roles r
+    JOIN (VALUES
+        ('catalog_editor', 'products:write'),
+        ('marketing', 'discounts:read'),
+        ('marketing', 'discounts:write'),
+        ('support', 'users:read'),
+        ('support', 'users:reset_password'),
+        ('developer', 'webhooks:read'),
+        ('developer', 'webhooks:write'),
+        ('developer', 'api_keys:write'),
+        ('developer', 'events:read')
+    ) AS p (role_name, name) ON p.role_name = r.name;
@@ -0,0 +1,2 @@
+-- there's no telling which images were archived by the up migration, so they stay archived
+SELECT 1;
@@ -0,0 +1,7 @@
+-- product roots used to be archived without their images, which kept the images' files from ever being collected
+UPDATE product_images
+SET archived_on = product_roots.archived_on
+FROM product_roots
+WHERE product_images.product_root_id = product_roots.id
+AND product_roots.archived_on IS NOT NULL
+AND product_images.archived_on IS NULL;
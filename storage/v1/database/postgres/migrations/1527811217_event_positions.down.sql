@@ -0,0 +1,5 @@
+DROP TRIGGER IF EXISTS events_position ON events;
+DROP FUNCTION IF EXISTS position_event();
+DROP INDEX IF EXISTS events_position_idx;
+ALTER TABLE events DROP COLUMN IF EXISTS "position";
+DROP SEQUENCE IF EXISTS events_position_seq;
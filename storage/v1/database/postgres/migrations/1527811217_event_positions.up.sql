@@ -0,0 +1,22 @@
+-- Events used to take a lock as they were written and hold it until their transaction committed, so every
+-- transaction that recorded an event waited on every other one. Instead, events are now given a position
+-- by a deferred trigger, which runs as their transaction commits, so the lock is only held while committing.
+-- Positions still become visible in the order they're given out, so a reader that has seen one can't later
+-- find a lower one. Readers follow positions rather than ids, since ids are allocated in insertion order.
+CREATE SEQUENCE IF NOT EXISTS events_position_seq;
+ALTER TABLE events ADD COLUMN "position" bigint;
+UPDATE events SET "position" = id;
+SELECT setval('events_position_seq', COALESCE((SELECT MAX(id) FROM events), 0) + 1, false);
+CREATE UNIQUE INDEX events_position_idx ON events ("position");
+
+CREATE FUNCTION position_event() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_advisory_xact_lock(hashtext('events'));
+    UPDATE events SET "position" = nextval('events_position_seq') WHERE id = NEW.id;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE CONSTRAINT TRIGGER events_position AFTER INSERT ON events
+    DEFERRABLE INITIALLY DEFERRED
+    FOR EACH ROW EXECUTE PROCEDURE position_event();
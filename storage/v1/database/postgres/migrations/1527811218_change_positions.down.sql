@@ -0,0 +1,33 @@
+DROP TRIGGER IF EXISTS changes_position ON changes;
+DROP FUNCTION IF EXISTS position_change();
+DROP INDEX IF EXISTS changes_position_idx;
+ALTER TABLE changes DROP COLUMN IF EXISTS "position";
+DROP SEQUENCE IF EXISTS changes_position_seq;
+
+CREATE OR REPLACE FUNCTION record_change() RETURNS trigger AS $$
+DECLARE
+    op change_operation;
+    row_data jsonb;
+BEGIN
+    PERFORM pg_advisory_xact_lock(hashtext('changes'));
+
+    IF TG_OP = 'INSERT' THEN
+        op := 'created';
+        row_data := to_jsonb(NEW);
+    ELSIF TG_OP = 'DELETE' OR (OLD.archived_on IS NULL AND NEW.archived_on IS NOT NULL) THEN
+        op := 'archived';
+    ELSIF OLD.archived_on IS NOT NULL AND NEW.archived_on IS NOT NULL THEN
+        RETURN NULL;
+    ELSE
+        op := 'updated';
+        row_data := to_jsonb(NEW);
+    END IF;
+
+    IF TG_OP = 'DELETE' THEN
+        INSERT INTO changes (resource, resource_id, operation, data) VALUES (TG_ARGV[0], OLD.id, op, row_data);
+    ELSE
+        INSERT INTO changes (resource, resource_id, operation, data) VALUES (TG_ARGV[0], NEW.id, op, row_data);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
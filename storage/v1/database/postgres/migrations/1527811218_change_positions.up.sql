@@ -0,0 +1,49 @@
+-- Recording a change used to take a lock that was held until the writing transaction committed, so every
+-- transaction that touched the catalog waited on every other one. Like events, changes are now given a
+-- position by a deferred trigger as their transaction commits, so the lock is only held while committing.
+-- Readers follow positions rather than ids, and existing positions match ids, so tokens clients already
+-- have keep working.
+CREATE SEQUENCE IF NOT EXISTS changes_position_seq;
+ALTER TABLE changes ADD COLUMN "position" bigint;
+UPDATE changes SET "position" = id;
+SELECT setval('changes_position_seq', COALESCE((SELECT MAX(id) FROM changes), 0) + 1, false);
+CREATE UNIQUE INDEX changes_position_idx ON changes ("position");
+
+CREATE OR REPLACE FUNCTION record_change() RETURNS trigger AS $$
+DECLARE
+    op change_operation;
+    row_data jsonb;
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        op := 'created';
+        row_data := to_jsonb(NEW);
+    ELSIF TG_OP = 'DELETE' OR (OLD.archived_on IS NULL AND NEW.archived_on IS NOT NULL) THEN
+        op := 'archived';
+    ELSIF OLD.archived_on IS NOT NULL AND NEW.archived_on IS NOT NULL THEN
+        -- changes to archived rows aren't interesting to anyone
+        RETURN NULL;
+    ELSE
+        op := 'updated';
+        row_data := to_jsonb(NEW);
+    END IF;
+
+    IF TG_OP = 'DELETE' THEN
+        INSERT INTO changes (resource, resource_id, operation, data) VALUES (TG_ARGV[0], OLD.id, op, row_data);
+    ELSE
+        INSERT INTO changes (resource, resource_id, operation, data) VALUES (TG_ARGV[0], NEW.id, op, row_data);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE FUNCTION position_change() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_advisory_xact_lock(hashtext('changes'));
+    UPDATE changes SET "position" = nextval('changes_position_seq') WHERE id = NEW.id;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE CONSTRAINT TRIGGER changes_position AFTER INSERT ON changes
+    DEFERRABLE INITIALLY DEFERRED
+    FOR EACH ROW EXECUTE PROCEDURE position_change();
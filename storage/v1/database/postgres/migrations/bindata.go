@@ -8,9 +8,46 @@
 // 1498638543_auth.up.sql
 // 1512371453_webhooks.down.sql
 // 1512371453_webhooks.up.sql
+// 1527811200_variant_templates.down.sql
+// 1527811200_variant_templates.up.sql
+// 1527811201_variant_images.down.sql
+// 1527811201_variant_images.up.sql
+// 1527811202_product_image_sort_order.down.sql
+// 1527811202_product_image_sort_order.up.sql
+// 1527811203_product_image_renditions.down.sql
+// 1527811203_product_image_renditions.up.sql
+// 1527811204_product_image_hashes.down.sql
+// 1527811204_product_image_hashes.up.sql
+// 1527811205_webhook_deliveries.down.sql
+// 1527811205_webhook_deliveries.up.sql
+// 1527811206_webhook_secrets.down.sql
+// 1527811206_webhook_secrets.up.sql
+// 1527811207_webhook_event_types.down.sql
+// 1527811207_webhook_event_types.up.sql
+// 1527811208_webhook_execution_log_details.down.sql
+// 1527811208_webhook_execution_log_details.up.sql
+// 1527811209_webhook_status.down.sql
+// 1527811209_webhook_status.up.sql
+// 1527811210_webhook_failure_tracking.down.sql
+// 1527811210_webhook_failure_tracking.up.sql
+// 1527811211_webhook_payload_filters.down.sql
+// 1527811211_webhook_payload_filters.up.sql
+// 1527811212_events.down.sql
+// 1527811212_events.up.sql
+// 1527811213_changes.down.sql
+// 1527811213_changes.up.sql
+// 1527811214_api_keys.down.sql
+// 1527811214_api_keys.up.sql
+// 1527811215_roles.down.sql
+// 1527811215_roles.up.sql
+// 1527811216_archived_product_root_images.down.sql
+// 1527811216_archived_product_root_images.up.sql
+// 1527811217_event_positions.down.sql
+// 1527811217_event_positions.up.sql
+// 1527811218_change_positions.down.sql
+// 1527811218_change_positions.up.sql
 // 9999999999_example_data.down.sql
 // 9999999999_example_data.up.sql
-// bindata.go
 // DO NOT EDIT!
 
 package migrations
@@ -415,1330 +452,1070 @@ func _1512371453_webhooksUpSql() (*asset, error) {
 	return a, nil
 }
 
-var __9999999999_example_dataDownSql = []byte(`DELETE FROM webhooks WHERE id IS NOT NULL;
-DELETE FROM discounts WHERE id IS NOT NULL;
-DELETE FROM product_variant_bridge WHERE id IS NOT NULL;
-DELETE FROM product_option_values WHERE id IS NOT NULL;
-DELETE FROM product_options WHERE id IS NOT NULL;
-DELETE FROM products WHERE id IS NOT NULL;`)
+var __1527811200_variant_templatesDownSql = []byte(`ALTER TABLE IF EXISTS "product_option_values"
+    DROP COLUMN "abbreviation";
 
-func _9999999999_example_dataDownSqlBytes() ([]byte, error) {
-	return __9999999999_example_dataDownSql, nil
+ALTER TABLE IF EXISTS "product_roots"
+    DROP COLUMN "option_summary_template",
+    DROP COLUMN "sku_template";`)
+
+func _1527811200_variant_templatesDownSqlBytes() ([]byte, error) {
+	return __1527811200_variant_templatesDownSql, nil
 }
 
-func _9999999999_example_dataDownSql() (*asset, error) {
-	bytes, err := _9999999999_example_dataDownSqlBytes()
+func _1527811200_variant_templatesDownSql() (*asset, error) {
+	bytes, err := _1527811200_variant_templatesDownSqlBytes()
 	if err != nil {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "9999999999_example_data.down.sql", size: 292, mode: os.FileMode(420), modTime: time.Unix(1520775515, 0)}
+	info := bindataFileInfo{name: "1527811200_variant_templates.down.sql", size: 191, mode: os.FileMode(420), modTime: time.Unix(1792327563, 0)}
 	a := &asset{bytes: bytes, info: info}
 	return a, nil
 }
 
-var __9999999999_example_dataUpSql = []byte(`INSERT INTO product_roots
-(
-    "name",
-    "subtitle",
-    "description",
-    "sku_prefix",
-    "manufacturer",
-    "brand",
-    "taxable",
-    "cost",
-    "product_weight",
-    "product_height",
-    "product_width",
-    "product_length",
-    "package_weight",
-    "package_height",
-    "package_width",
-    "package_length",
-    "quantity_per_package",
-    "available_on"
-)
-VALUES
-(
-    /* name                 */ 'Your Favorite Band''s T-Shirt',
-    /* subtitle             */ 'A t-shirt you can wear',
-    /* description          */ 'Wear this if you''d like. Or don''t, I''m not in charge of your actions',
-    /* sku_prefix           */ 't-shirt',
-    /* manufacturer         */ 'Record Company',
-    /* brand                */ 'Your Favorite Band',
-    /* taxable              */ 'true',
-    /* cost                 */ 20.00,
-    /* product_weight       */ 1,
-    /* product_height       */ 5,
-    /* product_width        */ 5,
-    /* product_length       */ 5,
-    /* package_weight       */ 1,
-    /* package_height       */ 5,
-    /* package_width        */ 5,
-    /* package_length       */ 5,
-    /* quantity_per_package */ 1,
-    /* available_on         */ NOW()
-),
-(
-    /* name                 */ 'Sleeping People - Sleeping People',
-    /* subtitle             */ 'A solid math rock album',
-    /* description          */ 'Arbitrary description can go here because real product descriptions are technically copywritten.',
-    /* sku_prefix           */ 'sleeping-people',
-    /* manufacturer         */ 'Record Company',
-    /* brand                */ 'Sleeping People',
-    /* taxable              */ 'true',
-    /* cost                 */ 5,
-    /* product_weight       */ 1,
-    /* product_height       */ 12,
-    /* product_width        */ 12,
-    /* product_length       */ .5,
-    /* package_weight       */ 1,
-    /* package_height       */ 12,
-    /* package_width        */ 12,
-    /* package_length       */ .5,
-    /* quantity_per_package */ 1,
-    /* available_on         */ NOW()
-),
-(
-    /* name                 */ 'Jaga Jazzist - One Armed Bandit',
-    /* subtitle             */ 'A solid jazz album',
-    /* description          */ 'Arbitrary description can go here because real product descriptions are technically copywritten.',
-    /* sku_prefix           */ 'one-armed-bandit',
-    /* manufacturer         */ 'Record Company',
-    /* brand                */ 'Jaga Jazzist',
-    /* taxable              */ 'true',
-    /* cost                 */ 5,
-    /* product_weight       */ 1,
-    /* product_height       */ 12,
-    /* product_width        */ 12,
-    /* product_length       */ .5,
-    /* package_weight       */ 1,
-    /* package_height       */ 12,
-    /* package_width        */ 12,
-    /* package_length       */ .5,
-    /* quantity_per_package */ 1,
-    /* available_on         */ NOW()
-),
-(
-    /* name                 */ 'Cloudkicker - Let Yourself Be Huge',
-    /* subtitle             */ 'A solid instrumental album',
-    /* description          */ 'Arbitrary description can go here because real product descriptions are technically copywritten.',
-    /* sku_prefix           */ 'let-yourself-be-huge',
-    /* manufacturer         */ 'Record Company',
-    /* brand                */ 'Cloudkicker',
-    /* taxable              */ 'true',
-    /* cost                 */ 5,
-    /* product_weight       */ 1,
-    /* product_height       */ 12,
-    /* product_width        */ 12,
-    /* product_length       */ .5,
-    /* package_weight       */ 1,
-    /* package_height       */ 12,
-    /* package_width        */ 12,
-    /* package_length       */ .5,
-    /* quantity_per_package */ 1,
-    /* available_on         */ NOW()
-),
-(
-    /* name                 */ 'Animals As Leaders - The Joy Of Motion',
-    /* subtitle             */ 'A solid prog metal album',
-    /* description          */ 'Arbitrary description can go here because real product descriptions are technically copywritten.',
-    /* sku_prefix           */ 'the-joy-of-motion',
-    /* manufacturer         */ 'Record Company',
-    /* brand                */ 'Animals As Leaders',
-    /* taxable              */ 'true',
-    /* cost                 */ 5,
-    /* product_weight       */ 1,
-    /* product_height       */ 12,
-    /* product_width        */ 12,
-    /* product_length       */ .5,
-    /* package_weight       */ 1,
-    /* package_height       */ 12,
-    /* package_width        */ 12,
-    /* package_length       */ .5,
-    /* quantity_per_package */ 1,
-    /* available_on         */ NOW()
-),
-(
-    /* name                 */ 'Mort Garson - Mother Earth''s Plantasia',
-    /* subtitle             */ 'A solid synth album',
-    /* description          */ 'Arbitrary description can go here because real product descriptions are technically copywritten.',
-    /* sku_prefix           */ 'mother-earths-plantasia',
-    /* manufacturer         */ 'Record Company',
-    /* brand                */ 'Mort Garson',
-    /* taxable              */ 'true',
-    /* cost                 */ 5,
-    /* product_weight       */ 1,
-    /* product_height       */ 12,
-    /* product_width        */ 12,
-    /* product_length       */ .5,
-    /* package_weight       */ 1,
-    /* package_height       */ 12,
-    /* package_width        */ 12,
-    /* package_length       */ .5,
-    /* quantity_per_package */ 1,
-    /* available_on         */ NOW()
-),
-(
-    /* name                 */ 'Camel - The Snow Goose',
-    /* subtitle             */ 'A solid prog rock album',
-    /* description          */ 'Arbitrary description can go here because real product descriptions are technically copywritten.',
-    /* sku_prefix           */ 'the-snow-goose',
-    /* manufacturer         */ 'Record Company',
-    /* brand                */ 'Camel',
-    /* taxable              */ 'true',
-    /* cost                 */ 5,
-    /* product_weight       */ 1,
-    /* product_height       */ 12,
-    /* product_width        */ 12,
-    /* product_length       */ .5,
-    /* package_weight       */ 1,
-    /* package_height       */ 12,
-    /* package_width        */ 12,
-    /* package_length       */ .5,
-    /* quantity_per_package */ 1,
-    /* available_on         */ NOW()
-),
-(
-    /* name                 */ 'Piglet - Lava Land',
-    /* subtitle             */ 'Another solid math rock album',
-    /* description          */ 'Arbitrary description can go here because real product descriptions are technically copywritten.',
-    /* sku_prefix           */ 'lava-land',
-    /* manufacturer         */ 'Record Company',
-    /* brand                */ 'Piglet',
-    /* taxable              */ 'true',
-    /* cost                 */ 5,
-    /* product_weight       */ 1,
-    /* product_height       */ 12,
-    /* product_width        */ 12,
-    /* product_length       */ .5,
-    /* package_weight       */ 1,
-    /* package_height       */ 12,
-    /* package_width        */ 12,
-    /* package_length       */ .5,
-    /* quantity_per_package */ 1,
-    /* available_on         */ NOW()
-),
-(
-    /* name                 */ 'Tera Melos - Untitled',
-    /* subtitle             */ 'Yet another solid math rock album',
-    /* description          */ 'Arbitrary description can go here because real product descriptions are technically copywritten.',
-    /* sku_prefix           */ 'untitled',
-    /* manufacturer         */ 'Record Company',
-    /* brand                */ 'Tera Melos',
-    /* taxable              */ 'true',
-    /* cost                 */ 5,
-    /* product_weight       */ 1,
-    /* product_height       */ 12,
-    /* product_width        */ 12,
-    /* product_length       */ .5,
-    /* package_weight       */ 1,
-    /* package_height       */ 12,
-    /* package_width        */ 12,
-    /* package_length       */ .5,
-    /* quantity_per_package */ 1,
-    /* available_on         */ NOW()
-),
-(
-    /* name                 */ 'Frank Zappa - Jazz From Hell',
-    /* subtitle             */ 'A solid Zappa album',
-    /* description          */ 'Arbitrary description can go here because real product descriptions are technically copywritten.',
-    /* sku_prefix           */ 'jazz-from-hell',
-    /* manufacturer         */ 'Record Company',
-    /* brand                */ 'Frank Zappa',
-    /* taxable              */ 'true',
-    /* cost                 */ 5,
-    /* product_weight       */ 1,
-    /* product_height       */ 12,
-    /* product_width        */ 12,
-    /* product_length       */ .5,
-    /* package_weight       */ 1,
-    /* package_height       */ 12,
-    /* package_width        */ 12,
-    /* package_length       */ .5,
-    /* quantity_per_package */ 1,
-    /* available_on         */ NOW()
-),
-(
-    /* name                 */ 'CHON - Newborn Sun',
-    /* subtitle             */ 'Yet another solid math rock album',
-    /* description          */ 'Arbitrary description can go here because real product descriptions are technically copywritten.',
-    /* sku_prefix           */ 'newborn-sun',
-    /* manufacturer         */ 'Record Company',
-    /* brand                */ 'CHON',
-    /* taxable              */ 'true',
-    /* cost                 */ 5,
-    /* product_weight       */ 1,
-    /* product_height       */ 12,
-    /* product_width        */ 12,
-    /* product_length       */ .5,
-    /* package_weight       */ 1,
-    /* package_height       */ 12,
-    /* package_width        */ 12,
-    /* package_length       */ .5,
-    /* quantity_per_package */ 1,
-    /* available_on         */ NOW()
-);
+var __1527811200_variant_templatesUpSql = []byte(`ALTER TABLE IF EXISTS "product_roots"
+    ADD COLUMN "sku_template" text NOT NULL DEFAULT '',
+    ADD COLUMN "option_summary_template" text NOT NULL DEFAULT '';
 
-INSERT INTO products
-(
-    "product_root_id",
-    "name",
-    "subtitle",
-    "description",
-    "option_summary",
-    "sku",
-    "upc",
-    "manufacturer",
-    "brand",
-    "quantity",
-    "taxable",
-    "price",
-    "on_sale",
-    "sale_price",
-    "cost",
-    "product_weight",
-    "product_height",
-    "product_width",
-    "product_length",
-    "package_weight",
-    "package_height",
-    "package_width",
-    "package_length",
-    "quantity_per_package"
-)
-VALUES
-(
-    /* product_root_id      */ 1,
-    /* name                 */ 'Your Favorite Band''s T-Shirt',
-    /* subtitle             */ 'A t-shirt you can wear',
-    /* description          */ 'Wear this if you''d like. Or don''t, I''m not in charge of your actions',
-    /* option_summary       */ 'Size: Small, Color: Red',
-    /* sku                  */ 't-shirt-small-red',
-    /* upc                  */ '',
-    /* manufacturer         */ 'Record Company',
-    /* brand                */ 'Your Favorite Band',
-    /* quantity             */ 666,
-    /* taxable              */ 'true',
-    /* price                */ 20.00,
-    /* on_sale              */ 'false',
-    /* sale_price           */ 0.00,
-    /* cost                 */ 10,
-    /* product_weight       */ 1,
-    /* product_height       */ 5,
-    /* product_width        */ 5,
-    /* product_length       */ 5,
-    /* package_weight       */ 1,
-    /* package_height       */ 5,
-    /* package_width        */ 5,
-    /* package_length       */ 5,
-    /* quantity_per_package */ 1
-),
-(
-    /* product_root_id      */ 1,
-    /* name                 */ 'Your Favorite Band''s T-Shirt',
-    /* subtitle             */ 'A t-shirt you can wear',
-    /* description          */ 'Wear this if you''d like. Or don''t, I''m not in charge of your actions',
-    /* option_summary       */ 'Size: Medium, Color: Red',
-    /* sku                  */ 't-shirt-medium-red',
-    /* upc                  */ '',
-    /* manufacturer         */ 'Record Company',
-    /* brand                */ 'Your Favorite Band',
-    /* quantity             */ 666,
-    /* taxable              */ 'true',
-    /* price                */ 20.00,
-    /* on_sale              */ 'false',
-    /* sale_price           */ 0.00,
-    /* cost                 */ 10,
-    /* product_weight       */ 1,
-    /* product_height       */ 5,
-    /* product_width        */ 5,
-    /* product_length       */ 5,
-    /* package_weight       */ 1,
-    /* package_height       */ 5,
-    /* package_width        */ 5,
-    /* package_length       */ 5,
-    /* quantity_per_package */ 1
-),
-(
-    /* product_root_id      */ 1,
-    /* name                 */ 'Your Favorite Band''s T-Shirt',
-    /* subtitle             */ 'A t-shirt you can wear',
-    /* description          */ 'Wear this if you''d like. Or don''t, I''m not in charge of your actions',
-    /* option_summary       */ 'Size: Large, Color: Red',
-    /* sku                  */ 't-shirt-large-red',
-    /* upc                  */ '',
-    /* manufacturer         */ 'Record Company',
-    /* brand                */ 'Your Favorite Band',
-    /* quantity             */ 666,
-    /* taxable              */ 'true',
-    /* price                */ 20.00,
-    /* on_sale              */ 'false',
-    /* sale_price           */ 0.00,
-    /* cost                 */ 10,
-    /* product_weight       */ 1,
-    /* product_height       */ 5,
-    /* product_width        */ 5,
-    /* product_length       */ 5,
-    /* package_weight       */ 1,
-    /* package_height       */ 5,
-    /* package_width        */ 5,
-    /* package_length       */ 5,
-    /* quantity_per_package */ 1
-),
-(
-    /* product_root_id      */ 1,
-    /* name                 */ 'Your Favorite Band''s T-Shirt',
-    /* subtitle             */ 'A t-shirt you can wear',
-    /* description          */ 'Wear this if you''d like. Or don''t, I''m not in charge of your actions',
-    /* option_summary       */ 'Size: Small, Color: Blue',
-    /* sku                  */ 't-shirt-small-blue',
-    /* upc                  */ '',
-    /* manufacturer         */ 'Record Company',
-    /* brand                */ 'Your Favorite Band',
-    /* quantity             */ 666,
-    /* taxable              */ 'true',
-    /* price                */ 20.00,
-    /* on_sale              */ 'false',
-    /* sale_price           */ 0.00,
-    /* cost                 */ 10,
-    /* product_weight       */ 1,
-    /* product_height       */ 5,
-    /* product_width        */ 5,
-    /* product_length       */ 5,
-    /* package_weight       */ 1,
-    /* package_height       */ 5,
-    /* package_width        */ 5,
-    /* package_length       */ 5,
-    /* quantity_per_package */ 1
-),
-(
-    /* product_root_id      */ 1,
-    /* name                 */ 'Your Favorite Band''s T-Shirt',
-    /* subtitle             */ 'A t-shirt you can wear',
-    /* description          */ 'Wear this if you''d like. Or don''t, I''m not in charge of your actions',
-    /* option_summary       */ 'Size: Medium, Color: Blue',
-    /* sku                  */ 't-shirt-medium-blue',
-    /* upc                  */ '',
-    /* manufacturer         */ 'Record Company',
-    /* brand                */ 'Your Favorite Band',
-    /* quantity             */ 666,
-    /* taxable              */ 'true',
-    /* price                */ 20.00,
-    /* on_sale              */ 'false',
-    /* sale_price           */ 0.00,
-    /* cost                 */ 10,
-    /* product_weight       */ 1,
-    /* product_height       */ 5,
-    /* product_width        */ 5,
-    /* product_length       */ 5,
-    /* package_weight       */ 1,
-    /* package_height       */ 5,
-    /* package_width        */ 5,
-    /* package_length       */ 5,
-    /* quantity_per_package */ 1
-),
-(
-    /* product_root_id      */ 1,
-    /* name                 */ 'Your Favorite Band''s T-Shirt',
-    /* subtitle             */ 'A t-shirt you can wear',
-    /* description          */ 'Wear this if you''d like. Or don''t, I''m not in charge of your actions',
-    /* option_summary       */ 'Size: Large, Color: Blue',
-    /* sku                  */ 't-shirt-large-blue',
-    /* upc                  */ '',
-    /* manufacturer         */ 'Record Company',
-    /* brand                */ 'Your Favorite Band',
-    /* quantity             */ 666,
-    /* taxable              */ 'true',
-    /* price                */ 20.00,
-    /* on_sale              */ 'false',
-    /* sale_price           */ 0.00,
-    /* cost                 */ 10,
-    /* product_weight       */ 1,
-    /* product_height       */ 5,
-    /* product_width        */ 5,
-    /* product_length       */ 5,
-    /* package_weight       */ 1,
-    /* package_height       */ 5,
-    /* package_width        */ 5,
-    /* package_length       */ 5,
-    /* quantity_per_package */ 1
-),
-(
-    /* product_root_id      */ 1,
-    /* name                 */ 'Your Favorite Band''s T-Shirt',
-    /* subtitle             */ 'A t-shirt you can wear',
-    /* description          */ 'Wear this if you''d like. Or don''t, I''m not in charge of your actions',
-    /* option_summary       */ 'Size: Small, Color: Green',
-    /* sku                  */ 't-shirt-small-green',
-    /* upc                  */ '',
-    /* manufacturer         */ 'Record Company',
-    /* brand                */ 'Your Favorite Band',
-    /* quantity             */ 666,
-    /* taxable              */ 'true',
-    /* price                */ 20.00,
-    /* on_sale              */ 'false',
-    /* sale_price           */ 0.00,
-    /* cost                 */ 10,
-    /* product_weight       */ 1,
-    /* product_height       */ 5,
-    /* product_width        */ 5,
-    /* product_length       */ 5,
-    /* package_weight       */ 1,
-    /* package_height       */ 5,
-    /* package_width        */ 5,
-    /* package_length       */ 5,
-    /* quantity_per_package */ 1
-),
-(
-    /* product_root_id      */ 1,
-    /* name                 */ 'Your Favorite Band''s T-Shirt',
-    /* subtitle             */ 'A t-shirt you can wear',
-    /* description          */ 'Wear this if you''d like. Or don''t, I''m not in charge of your actions',
-    /* option_summary       */ 'Size: Medium, Color: Green',
-    /* sku                  */ 't-shirt-medium-green',
-    /* upc                  */ '',
-    /* manufacturer         */ 'Record Company',
-    /* brand                */ 'Your Favorite Band',
-    /* quantity             */ 666,
-    /* taxable              */ 'true',
-    /* price                */ 20.00,
-    /* on_sale              */ 'false',
-    /* sale_price           */ 0.00,
-    /* cost                 */ 10,
-    /* product_weight       */ 1,
-    /* product_height       */ 5,
-    /* product_width        */ 5,
-    /* product_length       */ 5,
-    /* package_weight       */ 1,
-    /* package_height       */ 5,
-    /* package_width        */ 5,
-    /* package_length       */ 5,
-    /* quantity_per_package */ 1
-),
-(
-    /* product_root_id      */ 1,
-    /* name                 */ 'Your Favorite Band''s T-Shirt',
-    /* subtitle             */ 'A t-shirt you can wear',
-    /* description          */ 'Wear this if you''d like. Or don''t, I''m not in charge of your actions',
-    /* option_summary       */ 'Size: Large, Color: Green',
-    /* sku                  */ 't-shirt-large-green',
-    /* upc                  */ '',
-    /* manufacturer         */ 'Record Company',
-    /* brand                */ 'Your Favorite Band',
-    /* quantity             */ 666,
-    /* taxable              */ 'true',
-    /* price                */ 20.00,
-    /* on_sale              */ 'false',
-    /* sale_price           */ 0.00,
-    /* cost                 */ 10,
-    /* product_weight       */ 1,
-    /* product_height       */ 5,
-    /* product_width        */ 5,
-    /* product_length       */ 5,
-    /* package_weight       */ 1,
-    /* package_height       */ 5,
-    /* package_width        */ 5,
-    /* package_length       */ 5,
-    /* quantity_per_package */ 1
-),(
-    /* product_root_id      */ 2,
-    /* name                 */ 'Sleeping People - Sleeping People',
-    /* subtitle             */ 'A solid math rock album',
-    /* description          */ 'Arbitrary description can go here because real product descriptions are technically copywritten.',
-    /* option_summary       */ '',
-    /* sku                  */ 'sleeping-people',
-    /* upc                  */ '656605908410',
-    /* manufacturer         */ 'Record Company',
-    /* brand                */ 'Sleeping People',
-    /* quantity             */ 123,
-    /* taxable              */ 'true',
-    /* price                */ 12.34,
-    /* on_sale              */ 'false',
-    /* sale_price           */ 0.00,
-    /* cost                 */ 5,
-    /* product_weight       */ 1,
-    /* product_height       */ 12,
-    /* product_width        */ 12,
-    /* product_length       */ .5,
-    /* package_weight       */ 1,
-    /* package_height       */ 12,
-    /* package_width        */ 12,
-    /* package_length       */ .5,
-    /* quantity_per_package */ 1
-),
-(
-    /* product_root_id      */ 3,
-    /* name                 */ 'Jaga Jazzist - One Armed Bandit',
-    /* subtitle             */ 'A solid jazz album',
-    /* description          */ 'Arbitrary description can go here because real product descriptions are technically copywritten.',
-    /* option_summary       */ '',
-    /* sku                  */ 'one-armed-bandit',
-    /* upc                  */ '5021392578187',
-    /* manufacturer         */ 'Record Company',
-    /* brand                */ 'Jaga Jazzist',
-    /* quantity             */ 123,
-    /* taxable              */ 'true',
-    /* price                */ 12.34,
-    /* on_sale              */ 'false',
-    /* sale_price           */ 0.00,
-    /* cost                 */ 5,
-    /* product_weight       */ 1,
-    /* product_height       */ 12,
-    /* product_width        */ 12,
-    /* product_length       */ .5,
-    /* package_weight       */ 1,
-    /* package_height       */ 12,
-    /* package_width        */ 12,
-    /* package_length       */ .5,
-    /* quantity_per_package */ 1
-),
-(
-    /* product_root_id      */ 4,
-    /* name                 */ 'Cloudkicker - Let Yourself Be Huge',
-    /* subtitle             */ 'A solid instrumental album',
-    /* description          */ 'Arbitrary description can go here because real product descriptions are technically copywritten.',
-    /* option_summary       */ '',
-    /* sku                  */ 'let-yourself-be-huge',
-    /* upc                  */ '',
-    /* manufacturer         */ 'Record Company',
-    /* brand                */ 'Cloudkicker',
-    /* quantity             */ 123,
-    /* taxable              */ 'true',
-    /* price                */ 12.34,
-    /* on_sale              */ 'false',
-    /* sale_price           */ 0.00,
-    /* cost                 */ 5,
-    /* product_weight       */ 1,
-    /* product_height       */ 12,
-    /* product_width        */ 12,
-    /* product_length       */ .5,
-    /* package_weight       */ 1,
-    /* package_height       */ 12,
-    /* package_width        */ 12,
-    /* package_length       */ .5,
-    /* quantity_per_package */ 1
-),
-(
-    /* product_root_id      */ 5,
-    /* name                 */ 'Animals As Leaders - The Joy Of Motion',
-    /* subtitle             */ 'A solid prog metal album',
-    /* description          */ 'Arbitrary description can go here because real product descriptions are technically copywritten.',
-    /* option_summary       */ '',
-    /* sku                  */ 'the-joy-of-motion',
-    /* upc                  */ '817424013895',
-    /* manufacturer         */ 'Record Company',
-    /* brand                */ 'Animals As Leaders',
-    /* quantity             */ 123,
-    /* taxable              */ 'true',
-    /* price                */ 12.34,
-    /* on_sale              */ 'false',
-    /* sale_price           */ 0.00,
-    /* cost                 */ 5,
-    /* product_weight       */ 1,
-    /* product_height       */ 12,
-    /* product_width        */ 12,
-    /* product_length       */ .5,
-    /* package_weight       */ 1,
-    /* package_height       */ 12,
-    /* package_width        */ 12,
-    /* package_length       */ .5,
-    /* quantity_per_package */ 1
-),
-(
-    /* product_root_id      */ 6,
-    /* name                 */ 'Mort Garson - Mother Earth''s Plantasia',
-    /* subtitle             */ 'A solid synth album',
-    /* description          */ 'Arbitrary description can go here because real product descriptions are technically copywritten.',
-    /* option_summary       */ '',
-    /* sku                  */ 'mother-earths-plantasia',
-    /* upc                  */ '5291103812552',
-    /* manufacturer         */ 'Record Company',
-    /* brand                */ 'Mort Garson',
-    /* quantity             */ 123,
-    /* taxable              */ 'true',
-    /* price                */ 12.34,
-    /* on_sale              */ 'false',
-    /* sale_price           */ 0.00,
-    /* cost                 */ 5,
-    /* product_weight       */ 1,
-    /* product_height       */ 12,
-    /* product_width        */ 12,
-    /* product_length       */ .5,
-    /* package_weight       */ 1,
-    /* package_height       */ 12,
-    /* package_width        */ 12,
-    /* package_length       */ .5,
-    /* quantity_per_package */ 1
-),
-(
-    /* product_root_id      */ 7,
-    /* name                 */ 'Camel - The Snow Goose',
-    /* subtitle             */ 'A solid prog rock album',
-    /* description          */ 'Arbitrary description can go here because real product descriptions are technically copywritten.',
-    /* option_summary       */ '',
-    /* sku                  */ 'the-snow-goose',
-    /* upc                  */ '600753356661',
-    /* manufacturer         */ 'Record Company',
-    /* brand                */ 'Camel',
-    /* quantity             */ 123,
-    /* taxable              */ 'true',
-    /* price                */ 12.34,
-    /* on_sale              */ 'false',
-    /* sale_price           */ 0.00,
-    /* cost                 */ 5,
-    /* product_weight       */ 1,
-    /* product_height       */ 12,
-    /* product_width        */ 12,
-    /* product_length       */ .5,
-    /* package_weight       */ 1,
-    /* package_height       */ 12,
-    /* package_width        */ 12,
-    /* package_length       */ .5,
-    /* quantity_per_package */ 1
-),
-(
-    /* product_root_id      */ 8,
-    /* name                 */ 'Piglet - Lava Land',
-    /* subtitle             */ 'Another solid math rock album',
-    /* description          */ 'Arbitrary description can go here because real product descriptions are technically copywritten.',
-    /* option_summary       */ '',
-    /* sku                  */ 'lava-land',
-    /* upc                  */ '',
-    /* manufacturer         */ 'Record Company',
-    /* brand                */ 'Piglet',
-    /* quantity             */ 123,
-    /* taxable              */ 'true',
-    /* price                */ 12.34,
-    /* on_sale              */ 'false',
-    /* sale_price           */ 0.00,
-    /* cost                 */ 5,
-    /* product_weight       */ 1,
-    /* product_height       */ 12,
-    /* product_width        */ 12,
-    /* product_length       */ .5,
-    /* package_weight       */ 1,
-    /* package_height       */ 12,
-    /* package_width        */ 12,
-    /* package_length       */ .5,
-    /* quantity_per_package */ 1
-),
-(
-    /* product_root_id      */ 9,
-    /* name                 */ 'Tera Melos - Untitled',
-    /* subtitle             */ 'Yet another solid math rock album',
-    /* description          */ 'Arbitrary description can go here because real product descriptions are technically copywritten.',
-    /* option_summary       */ '',
-    /* sku                  */ 'untitled',
-    /* upc                  */ '634457550513',
-    /* manufacturer         */ 'Record Company',
-    /* brand                */ 'Tera Melos',
-    /* quantity             */ 123,
-    /* taxable              */ 'true',
-    /* price                */ 12.34,
-    /* on_sale              */ 'false',
-    /* sale_price           */ 0.00,
-    /* cost                 */ 5,
-    /* product_weight       */ 1,
-    /* product_height       */ 12,
-    /* product_width        */ 12,
-    /* product_length       */ .5,
-    /* package_weight       */ 1,
-    /* package_height       */ 12,
-    /* package_width        */ 12,
-    /* package_length       */ .5,
-    /* quantity_per_package */ 1
-),
-(
-    /* product_root_id      */ 10,
-    /* name                 */ 'Frank Zappa - Jazz From Hell',
-    /* subtitle             */ 'A solid Zappa album',
-    /* description          */ 'Arbitrary description can go here because real product descriptions are technically copywritten.',
-    /* option_summary       */ '',
-    /* sku                  */ 'jazz-from-hell',
-    /* upc                  */ '013347420516',
-    /* manufacturer         */ 'Record Company',
-    /* brand                */ 'Frank Zappa',
-    /* quantity             */ 123,
-    /* taxable              */ 'true',
-    /* price                */ 12.34,
-    /* on_sale              */ 'false',
-    /* sale_price           */ 0.00,
-    /* cost                 */ 5,
-    /* product_weight       */ 1,
-    /* product_height       */ 12,
-    /* product_width        */ 12,
-    /* product_length       */ .5,
-    /* package_weight       */ 1,
-    /* package_height       */ 12,
-    /* package_width        */ 12,
-    /* package_length       */ .5,
-    /* quantity_per_package */ 1
-),
-(
-    /* product_root_id      */ 11,
-    /* name                 */ 'CHON - Newborn Sun',
-    /* subtitle             */ 'Yet another solid math rock album',
-    /* description          */ 'Arbitrary description can go here because real product descriptions are technically copywritten.',
-    /* option_summary       */ '',
-    /* sku                  */ 'newborn-sun',
-    /* upc                  */ '794558090315',
-    /* manufacturer         */ 'Record Company',
-    /* brand                */ 'CHON',
-    /* quantity             */ 123,
-    /* taxable              */ 'true',
-    /* price                */ 12.34,
-    /* on_sale              */ 'false',
-    /* sale_price           */ 0.00,
-    /* cost                 */ 5,
-    /* product_weight       */ 1,
-    /* product_height       */ 12,
-    /* product_width        */ 12,
-    /* product_length       */ .5,
-    /* package_weight       */ 1,
-    /* package_height       */ 12,
-    /* package_width        */ 12,
-    /* package_length       */ .5,
-    /* quantity_per_package */ 1
+ALTER TABLE IF EXISTS "product_option_values"
+    ADD COLUMN "abbreviation" text NOT NULL DEFAULT '';`)
+
+func _1527811200_variant_templatesUpSqlBytes() ([]byte, error) {
+	return __1527811200_variant_templatesUpSql, nil
+}
+
+func _1527811200_variant_templatesUpSql() (*asset, error) {
+	bytes, err := _1527811200_variant_templatesUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1527811200_variant_templates.up.sql", size: 263, mode: os.FileMode(420), modTime: time.Unix(1792327563, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __1527811201_variant_imagesDownSql = []byte(`DROP INDEX IF EXISTS product_image_bridge_active_idx;
+
+DELETE FROM product_image_bridge WHERE archived_on IS NOT NULL;
+
+ALTER TABLE IF EXISTS "product_image_bridge"
+    DROP COLUMN IF EXISTS "archived_on",
+    DROP COLUMN IF EXISTS "created_on",
+    ADD UNIQUE ("product_id", "product_image_id");
+`)
+
+func _1527811201_variant_imagesDownSqlBytes() ([]byte, error) {
+	return __1527811201_variant_imagesDownSql, nil
+}
+
+func _1527811201_variant_imagesDownSql() (*asset, error) {
+	bytes, err := _1527811201_variant_imagesDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1527811201_variant_images.down.sql", size: 297, mode: os.FileMode(420), modTime: time.Unix(1792327852, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __1527811201_variant_imagesUpSql = []byte(`ALTER TABLE IF EXISTS "product_image_bridge"
+    ADD COLUMN "created_on" timestamp NOT NULL DEFAULT NOW(),
+    ADD COLUMN "archived_on" timestamp,
+    DROP CONSTRAINT IF EXISTS "product_image_bridge_product_id_product_image_id_key";
+
+CREATE UNIQUE INDEX product_image_bridge_active_idx ON product_image_bridge (product_id, product_image_id) WHERE archived_on IS NULL;
+`)
+
+func _1527811201_variant_imagesUpSqlBytes() ([]byte, error) {
+	return __1527811201_variant_imagesUpSql, nil
+}
+
+func _1527811201_variant_imagesUpSql() (*asset, error) {
+	bytes, err := _1527811201_variant_imagesUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1527811201_variant_images.up.sql", size: 368, mode: os.FileMode(420), modTime: time.Unix(1792327852, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __1527811202_product_image_sort_orderDownSql = []byte(`ALTER TABLE IF EXISTS "product_images"
+    DROP COLUMN "sort_order";
+`)
+
+func _1527811202_product_image_sort_orderDownSqlBytes() ([]byte, error) {
+	return __1527811202_product_image_sort_orderDownSql, nil
+}
+
+func _1527811202_product_image_sort_orderDownSql() (*asset, error) {
+	bytes, err := _1527811202_product_image_sort_orderDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1527811202_product_image_sort_order.down.sql", size: 69, mode: os.FileMode(420), modTime: time.Unix(1792328085, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __1527811202_product_image_sort_orderUpSql = []byte(`ALTER TABLE IF EXISTS "product_images"
+    ADD COLUMN "sort_order" integer NOT NULL DEFAULT 0;
+`)
+
+func _1527811202_product_image_sort_orderUpSqlBytes() ([]byte, error) {
+	return __1527811202_product_image_sort_orderUpSql, nil
+}
+
+func _1527811202_product_image_sort_orderUpSql() (*asset, error) {
+	bytes, err := _1527811202_product_image_sort_orderUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1527811202_product_image_sort_order.up.sql", size: 95, mode: os.FileMode(420), modTime: time.Unix(1792328085, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __1527811203_product_image_renditionsDownSql = []byte(`ALTER TABLE IF EXISTS "product_images"
+    DROP COLUMN IF EXISTS "renditions";
+`)
+
+func _1527811203_product_image_renditionsDownSqlBytes() ([]byte, error) {
+	return __1527811203_product_image_renditionsDownSql, nil
+}
+
+func _1527811203_product_image_renditionsDownSql() (*asset, error) {
+	bytes, err := _1527811203_product_image_renditionsDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1527811203_product_image_renditions.down.sql", size: 79, mode: os.FileMode(420), modTime: time.Unix(1792329115, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __1527811203_product_image_renditionsUpSql = []byte(`ALTER TABLE IF EXISTS "product_images"
+    ADD COLUMN "renditions" jsonb NOT NULL DEFAULT '{}'::jsonb;
+
+UPDATE "product_images"
+    SET "renditions" = jsonb_build_object('thumbnail', "thumbnail_url", 'main', "main_url", 'original', "original_url");
+`)
+
+func _1527811203_product_image_renditionsUpSqlBytes() ([]byte, error) {
+	return __1527811203_product_image_renditionsUpSql, nil
+}
+
+func _1527811203_product_image_renditionsUpSql() (*asset, error) {
+	bytes, err := _1527811203_product_image_renditionsUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1527811203_product_image_renditions.up.sql", size: 249, mode: os.FileMode(420), modTime: time.Unix(1792329115, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __1527811204_product_image_hashesDownSql = []byte(`DROP INDEX IF EXISTS "product_images_content_hash_idx";
+
+ALTER TABLE IF EXISTS "product_images"
+    DROP COLUMN IF EXISTS "content_hash",
+    DROP COLUMN IF EXISTS "perceptual_hash";
+`)
+
+func _1527811204_product_image_hashesDownSqlBytes() ([]byte, error) {
+	return __1527811204_product_image_hashesDownSql, nil
+}
+
+func _1527811204_product_image_hashesDownSql() (*asset, error) {
+	bytes, err := _1527811204_product_image_hashesDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1527811204_product_image_hashes.down.sql", size: 183, mode: os.FileMode(420), modTime: time.Unix(1792331925, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __1527811204_product_image_hashesUpSql = []byte(`ALTER TABLE IF EXISTS "product_images"
+    ADD COLUMN "content_hash" text NOT NULL DEFAULT '',
+    ADD COLUMN "perceptual_hash" text NOT NULL DEFAULT '';
+
+CREATE INDEX "product_images_content_hash_idx" ON "product_images" ("content_hash") WHERE "content_hash" <> '' AND "archived_on" IS NULL;
+`)
+
+func _1527811204_product_image_hashesUpSqlBytes() ([]byte, error) {
+	return __1527811204_product_image_hashesUpSql, nil
+}
+
+func _1527811204_product_image_hashesUpSql() (*asset, error) {
+	bytes, err := _1527811204_product_image_hashesUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1527811204_product_image_hashes.up.sql", size: 293, mode: os.FileMode(420), modTime: time.Unix(1792331925, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __1527811205_webhook_deliveriesDownSql = []byte(`DROP INDEX IF EXISTS "webhook_deliveries_pending_idx";
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TYPE IF EXISTS webhook_delivery_status;
+`)
+
+func _1527811205_webhook_deliveriesDownSqlBytes() ([]byte, error) {
+	return __1527811205_webhook_deliveriesDownSql, nil
+}
+
+func _1527811205_webhook_deliveriesDownSql() (*asset, error) {
+	bytes, err := _1527811205_webhook_deliveriesDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1527811205_webhook_deliveries.down.sql", size: 141, mode: os.FileMode(420), modTime: time.Unix(1792334920, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __1527811205_webhook_deliveriesUpSql = []byte(`CREATE TYPE webhook_delivery_status AS ENUM ('pending', 'delivered', 'dead');
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    "id" bigserial,
+    "webhook_id" bigint NOT NULL,
+    "event_type" text NOT NULL,
+    "content_type" text NOT NULL DEFAULT 'application/json',
+    "payload" text NOT NULL,
+    "status" webhook_delivery_status NOT NULL DEFAULT 'pending',
+    "attempts" int NOT NULL DEFAULT 0,
+    "next_attempt_on" timestamp NOT NULL DEFAULT NOW(),
+    "locked_until" timestamp,
+    "last_status_code" int NOT NULL DEFAULT 0,
+    "last_error" text NOT NULL DEFAULT '',
+    "created_on" timestamp NOT NULL DEFAULT NOW(),
+    "updated_on" timestamp,
+    "delivered_on" timestamp,
+    PRIMARY KEY ("id"),
+    FOREIGN KEY ("webhook_id") REFERENCES "webhooks"("id")
 );
 
-INSERT INTO product_options
-(
-    "name",
-    "product_root_id"
-)
-VALUES
-('color', 1),
-('size',  1 );
+CREATE INDEX "webhook_deliveries_pending_idx" ON "webhook_deliveries" ("next_attempt_on") WHERE "status" = 'pending';
+`)
 
-INSERT INTO product_option_values
-(
-    "product_option_id",
-    "value"
-)
-VALUES
-(1, 'red'   ),
-(1, 'green' ),
-(1, 'blue'  ),
-(2, 'small' ),
-(2, 'medium'),
-(2, 'large' );
+func _1527811205_webhook_deliveriesUpSqlBytes() ([]byte, error) {
+	return __1527811205_webhook_deliveriesUpSql, nil
+}
+
+func _1527811205_webhook_deliveriesUpSql() (*asset, error) {
+	bytes, err := _1527811205_webhook_deliveriesUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1527811205_webhook_deliveries.up.sql", size: 897, mode: os.FileMode(420), modTime: time.Unix(1792334920, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __1527811206_webhook_secretsDownSql = []byte(`ALTER TABLE webhooks DROP COLUMN IF EXISTS "secret_rotated_on";
+ALTER TABLE webhooks DROP COLUMN IF EXISTS "previous_secret";
+ALTER TABLE webhooks DROP COLUMN IF EXISTS "secret";
+`)
+
+func _1527811206_webhook_secretsDownSqlBytes() ([]byte, error) {
+	return __1527811206_webhook_secretsDownSql, nil
+}
+
+func _1527811206_webhook_secretsDownSql() (*asset, error) {
+	bytes, err := _1527811206_webhook_secretsDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1527811206_webhook_secrets.down.sql", size: 179, mode: os.FileMode(420), modTime: time.Unix(1792335758, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __1527811206_webhook_secretsUpSql = []byte(`ALTER TABLE webhooks ADD COLUMN "secret" text NOT NULL DEFAULT '';
+ALTER TABLE webhooks ADD COLUMN "previous_secret" text NOT NULL DEFAULT '';
+ALTER TABLE webhooks ADD COLUMN "secret_rotated_on" timestamp;
+`)
+
+func _1527811206_webhook_secretsUpSqlBytes() ([]byte, error) {
+	return __1527811206_webhook_secretsUpSql, nil
+}
+
+func _1527811206_webhook_secretsUpSql() (*asset, error) {
+	bytes, err := _1527811206_webhook_secretsUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1527811206_webhook_secrets.up.sql", size: 206, mode: os.FileMode(420), modTime: time.Unix(1792335758, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __1527811207_webhook_event_typesDownSql = []byte(`DROP INDEX IF EXISTS "webhooks_event_types_idx";
+
+CREATE TYPE webhook_event AS ENUM ('product_created', 'product_updated', 'product_archived');
+ALTER TABLE webhooks ADD COLUMN "event_type" webhook_event;
+UPDATE webhooks SET "event_type" = replace("event_types"[1], '.', '_')::webhook_event
+    WHERE "event_types"[1] IN ('product.created', 'product.updated', 'product.archived');
+-- the old enum can't represent anything else, so those webhooks can't be kept around
+UPDATE webhooks SET "event_type" = 'product_updated', "archived_on" = COALESCE("archived_on", NOW())
+    WHERE "event_type" IS NULL;
+ALTER TABLE webhooks ALTER COLUMN "event_type" SET NOT NULL;
+ALTER TABLE webhooks DROP COLUMN "event_types";
+`)
+
+func _1527811207_webhook_event_typesDownSqlBytes() ([]byte, error) {
+	return __1527811207_webhook_event_typesDownSql, nil
+}
+
+func _1527811207_webhook_event_typesDownSql() (*asset, error) {
+	bytes, err := _1527811207_webhook_event_typesDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1527811207_webhook_event_types.down.sql", size: 708, mode: os.FileMode(420), modTime: time.Unix(1792336010, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __1527811207_webhook_event_typesUpSql = []byte(`ALTER TABLE webhooks ADD COLUMN "event_types" text[] NOT NULL DEFAULT '{}';
+UPDATE webhooks SET "event_types" = ARRAY[replace("event_type"::text, '_', '.')];
+ALTER TABLE webhooks DROP COLUMN "event_type";
+DROP TYPE IF EXISTS webhook_event;
+
+CREATE INDEX "webhooks_event_types_idx" ON "webhooks" USING GIN ("event_types");
+`)
+
+func _1527811207_webhook_event_typesUpSqlBytes() ([]byte, error) {
+	return __1527811207_webhook_event_typesUpSql, nil
+}
+
+func _1527811207_webhook_event_typesUpSql() (*asset, error) {
+	bytes, err := _1527811207_webhook_event_typesUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1527811207_webhook_event_types.up.sql", size: 322, mode: os.FileMode(420), modTime: time.Unix(1792336010, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
 
-INSERT INTO product_variant_bridge
-(
-    "id",
-    "product_id",
-    "product_option_value_id"
-)
-VALUES
-(1,  1, 1),
-(2,  1, 4),
-(3,  2, 1),
-(4,  2, 5),
-(5,  3, 1),
-(6,  3, 6),
-(7,  4, 3),
-(8,  4, 4),
-(9,  5, 3),
-(10, 5, 5),
-(11, 6, 3),
-(12, 6, 6),
-(13, 7, 2),
-(14, 7, 4),
-(15, 8, 2),
-(16, 8, 5),
-(17, 9, 2),
-(18, 9, 6);
+var __1527811208_webhook_execution_log_detailsDownSql = []byte(`DROP INDEX IF EXISTS "webhook_execution_logs_webhook_id_idx";
 
-INSERT INTO discounts
-(
-    "name",
-    "discount_type",
-    "amount",
-    "starts_on",
-    "expires_on"
-)
-VALUES
-(
-    '10% off',
-    'percentage',
-    10.00,
-    NOW(),
-    NOW() + (1 * interval '1 month')
-),
-(
-    '50% off',
-    'percentage',
-    50.00,
-    NOW(),
-    null
-),
-(
-    'New customer special',
-    'flat_amount',
-    10.00,
-    NOW(),
-    null
-);
+ALTER TABLE webhook_execution_logs DROP COLUMN IF EXISTS "archived_on";
+ALTER TABLE webhook_execution_logs DROP COLUMN IF EXISTS "updated_on";
+ALTER TABLE webhook_execution_logs DROP COLUMN IF EXISTS "error";
+ALTER TABLE webhook_execution_logs DROP COLUMN IF EXISTS "latency_ms";
+ALTER TABLE webhook_execution_logs DROP COLUMN IF EXISTS "response_body";
+ALTER TABLE webhook_execution_logs DROP COLUMN IF EXISTS "request_body";
+ALTER TABLE webhook_execution_logs DROP COLUMN IF EXISTS "request_headers";
+ALTER TABLE webhook_execution_logs DROP COLUMN IF EXISTS "request_url";
+ALTER TABLE webhook_execution_logs DROP COLUMN IF EXISTS "attempt";
+ALTER TABLE webhook_execution_logs DROP COLUMN IF EXISTS "event_type";
+ALTER TABLE webhook_execution_logs DROP COLUMN IF EXISTS "delivery_id";
+`)
 
-INSERT INTO webhooks
-(
-    "url",
-    "event_type"
-)
-VALUES
-(
-    'http://httpbin/status/200',
-    'product_created'
-),
-(
-    'http://httpbin/status/200',
-    'product_updated'
-),
-(
-    'http://httpbin/status/200',
-    'product_archived'
-);
+func _1527811208_webhook_execution_log_detailsDownSqlBytes() ([]byte, error) {
+	return __1527811208_webhook_execution_log_detailsDownSql, nil
+}
+
+func _1527811208_webhook_execution_log_detailsDownSql() (*asset, error) {
+	bytes, err := _1527811208_webhook_execution_log_detailsDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1527811208_webhook_execution_log_details.down.sql", size: 849, mode: os.FileMode(420), modTime: time.Unix(1792338800, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __1527811208_webhook_execution_log_detailsUpSql = []byte(`ALTER TABLE webhook_execution_logs ADD COLUMN "delivery_id" bigint REFERENCES "webhook_deliveries"("id");
+ALTER TABLE webhook_execution_logs ADD COLUMN "event_type" text NOT NULL DEFAULT '';
+ALTER TABLE webhook_execution_logs ADD COLUMN "attempt" int NOT NULL DEFAULT 0;
+ALTER TABLE webhook_execution_logs ADD COLUMN "request_url" text NOT NULL DEFAULT '';
+ALTER TABLE webhook_execution_logs ADD COLUMN "request_headers" jsonb NOT NULL DEFAULT '{}';
+ALTER TABLE webhook_execution_logs ADD COLUMN "request_body" text NOT NULL DEFAULT '';
+ALTER TABLE webhook_execution_logs ADD COLUMN "response_body" text NOT NULL DEFAULT '';
+ALTER TABLE webhook_execution_logs ADD COLUMN "latency_ms" bigint NOT NULL DEFAULT 0;
+ALTER TABLE webhook_execution_logs ADD COLUMN "error" text NOT NULL DEFAULT '';
+ALTER TABLE webhook_execution_logs ADD COLUMN "updated_on" timestamp;
+ALTER TABLE webhook_execution_logs ADD COLUMN "archived_on" timestamp;
+
+CREATE INDEX "webhook_execution_logs_webhook_id_idx" ON "webhook_execution_logs" ("webhook_id", "executed_on" DESC);
 `)
 
-func _9999999999_example_dataUpSqlBytes() ([]byte, error) {
-	return __9999999999_example_dataUpSql, nil
+func _1527811208_webhook_execution_log_detailsUpSqlBytes() ([]byte, error) {
+	return __1527811208_webhook_execution_log_detailsUpSql, nil
 }
 
-func _9999999999_example_dataUpSql() (*asset, error) {
-	bytes, err := _9999999999_example_dataUpSqlBytes()
+func _1527811208_webhook_execution_log_detailsUpSql() (*asset, error) {
+	bytes, err := _1527811208_webhook_execution_log_detailsUpSqlBytes()
 	if err != nil {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "9999999999_example_data.up.sql", size: 31030, mode: os.FileMode(420), modTime: time.Unix(1520775515, 0)}
+	info := bindataFileInfo{name: "1527811208_webhook_execution_log_details.up.sql", size: 1050, mode: os.FileMode(420), modTime: time.Unix(1792338800, 0)}
 	a := &asset{bytes: bytes, info: info}
 	return a, nil
 }
 
-var _bindataGo = []byte(`// Code generated by go-bindata.
-// sources:
-// 1495495688_products.down.sql
-// 1495495688_products.up.sql
-// 1496132528_discounts.down.sql
-// 1496132528_discounts.up.sql
-// 1498638543_auth.down.sql
-// 1498638543_auth.up.sql
-// 1512371453_webhooks.down.sql
-// 1512371453_webhooks.up.sql
-// 9999999999_example_data.down.sql
-// 9999999999_example_data.up.sql
-// bindata.go
-// DO NOT EDIT!
+var __1527811209_webhook_statusDownSql = []byte(`ALTER TABLE webhooks DROP COLUMN IF EXISTS "status";
 
-package migrations
+DROP TYPE IF EXISTS webhook_status;
+`)
 
-import (
-	"fmt"
-	"io/ioutil"
-	"os"
-	"path/filepath"
-	"strings"
-	"time"
-)
-type asset struct {
-	bytes []byte
-	info  os.FileInfo
+func _1527811209_webhook_statusDownSqlBytes() ([]byte, error) {
+	return __1527811209_webhook_statusDownSql, nil
 }
 
-type bindataFileInfo struct {
-	name    string
-	size    int64
-	mode    os.FileMode
-	modTime time.Time
+func _1527811209_webhook_statusDownSql() (*asset, error) {
+	bytes, err := _1527811209_webhook_statusDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1527811209_webhook_status.down.sql", size: 90, mode: os.FileMode(420), modTime: time.Unix(1792339084, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
 }
 
-func (fi bindataFileInfo) Name() string {
-	return fi.name
+var __1527811209_webhook_statusUpSql = []byte(`CREATE TYPE webhook_status AS ENUM ('pending', 'active', 'disabled');
+
+ALTER TABLE webhooks ADD COLUMN "status" webhook_status NOT NULL DEFAULT 'active';
+`)
+
+func _1527811209_webhook_statusUpSqlBytes() ([]byte, error) {
+	return __1527811209_webhook_statusUpSql, nil
 }
-func (fi bindataFileInfo) Size() int64 {
-	return fi.size
+
+func _1527811209_webhook_statusUpSql() (*asset, error) {
+	bytes, err := _1527811209_webhook_statusUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1527811209_webhook_status.up.sql", size: 154, mode: os.FileMode(420), modTime: time.Unix(1792339084, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
 }
-func (fi bindataFileInfo) Mode() os.FileMode {
-	return fi.mode
+
+var __1527811210_webhook_failure_trackingDownSql = []byte(`ALTER TABLE webhooks DROP COLUMN IF EXISTS "paused_until";
+ALTER TABLE webhooks DROP COLUMN IF EXISTS "consecutive_failures";
+`)
+
+func _1527811210_webhook_failure_trackingDownSqlBytes() ([]byte, error) {
+	return __1527811210_webhook_failure_trackingDownSql, nil
 }
-func (fi bindataFileInfo) ModTime() time.Time {
-	return fi.modTime
+
+func _1527811210_webhook_failure_trackingDownSql() (*asset, error) {
+	bytes, err := _1527811210_webhook_failure_trackingDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1527811210_webhook_failure_tracking.down.sql", size: 126, mode: os.FileMode(420), modTime: time.Unix(1792339365, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
 }
-func (fi bindataFileInfo) IsDir() bool {
-	return false
+
+var __1527811210_webhook_failure_trackingUpSql = []byte(`ALTER TABLE webhooks ADD COLUMN "consecutive_failures" int NOT NULL DEFAULT 0;
+ALTER TABLE webhooks ADD COLUMN "paused_until" timestamp;
+`)
+
+func _1527811210_webhook_failure_trackingUpSqlBytes() ([]byte, error) {
+	return __1527811210_webhook_failure_trackingUpSql, nil
 }
-func (fi bindataFileInfo) Sys() interface{} {
-	return nil
+
+func _1527811210_webhook_failure_trackingUpSql() (*asset, error) {
+	bytes, err := _1527811210_webhook_failure_trackingUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1527811210_webhook_failure_tracking.up.sql", size: 137, mode: os.FileMode(420), modTime: time.Unix(1792339365, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
 }
 
-var __1495495688_productsDownSql = []byte(`+"`"+`DROP TABLE product_image_bridge;
-DROP TABLE product_variant_bridge;
-DROP TABLE product_option_values;
-DROP TABLE product_options;
-DROP TABLE products;
-DROP TABLE product_images CASCADE;
-DROP TABLE product_roots CASCADE;`+"`"+`)
+var __1527811211_webhook_payload_filtersDownSql = []byte(`ALTER TABLE webhooks DROP COLUMN IF EXISTS "filter";
+ALTER TABLE webhooks DROP COLUMN IF EXISTS "fields";
+`)
 
-func _1495495688_productsDownSqlBytes() ([]byte, error) {
-	return __1495495688_productsDownSql, nil
+func _1527811211_webhook_payload_filtersDownSqlBytes() ([]byte, error) {
+	return __1527811211_webhook_payload_filtersDownSql, nil
 }
 
-func _1495495688_productsDownSql() (*asset, error) {
-	bytes, err := _1495495688_productsDownSqlBytes()
+func _1527811211_webhook_payload_filtersDownSql() (*asset, error) {
+	bytes, err := _1527811211_webhook_payload_filtersDownSqlBytes()
 	if err != nil {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "1495495688_products.down.sql", size: 219, mode: os.FileMode(420), modTime: time.Unix(1520775515, 0)}
+	info := bindataFileInfo{name: "1527811211_webhook_payload_filters.down.sql", size: 106, mode: os.FileMode(420), modTime: time.Unix(1792339713, 0)}
 	a := &asset{bytes: bytes, info: info}
 	return a, nil
 }
 
-var __1495495688_productsUpSql = []byte(`+"`"+`CREATE TABLE IF NOT EXISTS product_roots (
-    "id" bigserial,
-    "name" text NOT NULL,
-    "primary_image_id" bigint,
-    "subtitle" text NOT NULL DEFAULT '',
-    "description" text NOT NULL DEFAULT '',
-    "sku_prefix" text NOT NULL,
-    "manufacturer" text NOT NULL DEFAULT '',
-    "brand" text NOT NULL DEFAULT '',
-    "taxable" boolean NOT NULL DEFAULT 'false',
-    "cost" numeric(15, 2) NOT NULL DEFAULT 0,
-    "product_weight" numeric(15, 2) NOT NULL DEFAULT 0,
-    "product_height" numeric(15, 2) NOT NULL DEFAULT 0,
-    "product_width" numeric(15, 2) NOT NULL DEFAULT 0,
-    "product_length" numeric(15, 2) NOT NULL DEFAULT 0,
-    "package_weight" numeric(15, 2) NOT NULL DEFAULT 0,
-    "package_height" numeric(15, 2) NOT NULL DEFAULT 0,
-    "package_width" numeric(15, 2) NOT NULL DEFAULT 0,
-    "package_length" numeric(15, 2) NOT NULL DEFAULT 0,
-    "quantity_per_package" integer NOT NULL DEFAULT 1,
-    "available_on" timestamp NOT NULL DEFAULT NOW(),
-    "created_on" timestamp NOT NULL DEFAULT NOW(),
-    "updated_on" timestamp,
-    "archived_on" timestamp,
-    UNIQUE ("sku_prefix", "archived_on"),
-    PRIMARY KEY ("id")
-);
+var __1527811211_webhook_payload_filtersUpSql = []byte(`ALTER TABLE webhooks ADD COLUMN "fields" text[] NOT NULL DEFAULT '{}';
+ALTER TABLE webhooks ADD COLUMN "filter" text NOT NULL DEFAULT '';
+`)
 
-CREATE TABLE IF NOT EXISTS products (
-    "id" bigserial,
-    "product_root_id" bigint NOT NULL,
-    "primary_image_id" bigint,
-    "name" text NOT NULL,
-    "subtitle" text NOT NULL DEFAULT '',
-    "description" text NOT NULL DEFAULT '',
-    "option_summary" text NOT NULL DEFAULT '',
-    "sku" text NOT NULL,
-    "upc" text NOT NULL DEFAULT '',
-    "manufacturer" text NOT NULL DEFAULT '',
-    "brand" text NOT NULL DEFAULT '',
-    "quantity" integer NOT NULL DEFAULT 0,
-    "taxable" boolean NOT NULL DEFAULT 'false',
-    "price" numeric(15, 2) NOT NULL,
-    "on_sale" boolean NOT NULL DEFAULT 'false',
-    "sale_price" numeric(15, 2) NOT NULL DEFAULT 0 CONSTRAINT sale_price_must_not_be_zero CHECK(
-        (sale_price != 0 AND on_sale IS TRUE)
-                         OR
-        (sale_price  = 0 AND on_sale IS FALSE)
-    ),
-    "cost" numeric(15, 2) NOT NULL DEFAULT 0,
-    "product_weight" numeric(15, 2) NOT NULL DEFAULT 0,
-    "product_height" numeric(15, 2) NOT NULL DEFAULT 0,
-    "product_width" numeric(15, 2) NOT NULL DEFAULT 0,
-    "product_length" numeric(15, 2) NOT NULL DEFAULT 0,
-    "package_weight" numeric(15, 2) NOT NULL DEFAULT 0,
-    "package_height" numeric(15, 2) NOT NULL DEFAULT 0,
-    "package_width" numeric(15, 2) NOT NULL DEFAULT 0,
-    "package_length" numeric(15, 2) NOT NULL DEFAULT 0,
-    "quantity_per_package" integer NOT NULL DEFAULT 1,
-    "available_on" timestamp NOT NULL DEFAULT NOW(),
-    "created_on" timestamp NOT NULL DEFAULT NOW(),
-    "updated_on" timestamp,
-    "archived_on" timestamp,
-    UNIQUE ("sku", "archived_on"),
-    PRIMARY KEY ("id"),
-    FOREIGN KEY ("product_root_id") REFERENCES "product_roots"("id")
-);
-CREATE UNIQUE INDEX products_upc_empty_but_not_null_idx ON products (upc) WHERE upc != '';
+func _1527811211_webhook_payload_filtersUpSqlBytes() ([]byte, error) {
+	return __1527811211_webhook_payload_filtersUpSql, nil
+}
+
+func _1527811211_webhook_payload_filtersUpSql() (*asset, error) {
+	bytes, err := _1527811211_webhook_payload_filtersUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1527811211_webhook_payload_filters.up.sql", size: 138, mode: os.FileMode(420), modTime: time.Unix(1792339713, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __1527811212_eventsDownSql = []byte(`DROP TABLE IF EXISTS events;
+`)
 
-CREATE TABLE IF NOT EXISTS product_images (
+func _1527811212_eventsDownSqlBytes() ([]byte, error) {
+	return __1527811212_eventsDownSql, nil
+}
+
+func _1527811212_eventsDownSql() (*asset, error) {
+	bytes, err := _1527811212_eventsDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1527811212_events.down.sql", size: 29, mode: os.FileMode(420), modTime: time.Unix(1792340478, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __1527811212_eventsUpSql = []byte(`CREATE TABLE IF NOT EXISTS events (
     "id" bigserial,
-    "product_root_id" bigint NOT NULL,
-    "thumbnail_url" text NOT NULL,
-    "main_url" text NOT NULL,
-    "original_url" text NOT NULL,
-    "source_url" text NOT NULL DEFAULT '',
+    "event_type" text NOT NULL,
+    "payload" text NOT NULL,
     "created_on" timestamp NOT NULL DEFAULT NOW(),
-    "updated_on" timestamp,
-    "archived_on" timestamp,
-    PRIMARY KEY ("id"),
-    FOREIGN KEY ("product_root_id") REFERENCES "product_roots"("id")
+    PRIMARY KEY ("id")
 );
+`)
 
-ALTER TABLE IF EXISTS "product_roots"
-    ADD FOREIGN KEY ("primary_image_id") REFERENCES "product_images"("id");
+func _1527811212_eventsUpSqlBytes() ([]byte, error) {
+	return __1527811212_eventsUpSql, nil
+}
 
-ALTER TABLE IF EXISTS "products"
-    ADD FOREIGN KEY ("primary_image_id") REFERENCES "product_images"("id");
+func _1527811212_eventsUpSql() (*asset, error) {
+	bytes, err := _1527811212_eventsUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
 
-CREATE TABLE IF NOT EXISTS product_image_bridge (
-    "id" bigserial,
-    "product_id" bigint NOT NULL,
-    "product_image_id" bigint NOT NULL,
-    UNIQUE ("product_id", "product_image_id"),
-    PRIMARY KEY ("id"),
-    FOREIGN KEY ("product_id") REFERENCES "products"("id"),
-    FOREIGN KEY ("product_image_id") REFERENCES "product_images"("id")
-);
+	info := bindataFileInfo{name: "1527811212_events.up.sql", size: 194, mode: os.FileMode(420), modTime: time.Unix(1792340478, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
 
-CREATE TABLE IF NOT EXISTS product_options (
-    "id" bigserial,
-    "name" text NOT NULL,
-    "product_root_id" bigint NOT NULL,
-    "created_on" timestamp NOT NULL DEFAULT NOW(),
-    "updated_on" timestamp,
-    "archived_on" timestamp,
-    UNIQUE ("product_root_id", "name"),
-    UNIQUE ("name", "archived_on"),
-    PRIMARY KEY ("id"),
-    FOREIGN KEY ("product_root_id") REFERENCES "product_roots"("id")
-);
+var __1527811213_changesDownSql = []byte(`DROP TRIGGER IF EXISTS product_images_changes ON product_images;
+DROP TRIGGER IF EXISTS discounts_changes ON discounts;
+DROP TRIGGER IF EXISTS product_option_values_changes ON product_option_values;
+DROP TRIGGER IF EXISTS product_options_changes ON product_options;
+DROP TRIGGER IF EXISTS products_changes ON products;
+DROP TRIGGER IF EXISTS product_roots_changes ON product_roots;
+DROP FUNCTION IF EXISTS record_change();
+DROP TABLE IF EXISTS changes;
+DROP TYPE IF EXISTS change_operation;
+`)
 
-CREATE TABLE IF NOT EXISTS product_option_values (
+func _1527811213_changesDownSqlBytes() ([]byte, error) {
+	return __1527811213_changesDownSql, nil
+}
+
+func _1527811213_changesDownSql() (*asset, error) {
+	bytes, err := _1527811213_changesDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1527811213_changes.down.sql", size: 491, mode: os.FileMode(420), modTime: time.Unix(1792340801, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __1527811213_changesUpSql = []byte(`CREATE TYPE change_operation AS ENUM ('created', 'updated', 'archived');
+
+CREATE TABLE IF NOT EXISTS changes (
     "id" bigserial,
-    "product_option_id" bigint NOT NULL,
-    "value" text NOT NULL,
-    "created_on" timestamp NOT NULL DEFAULT NOW(),
-    "updated_on" timestamp,
-    "archived_on" timestamp,
-    UNIQUE ("product_option_id", "value"),
-    UNIQUE ("value", "archived_on"),
-    PRIMARY KEY ("id"),
-    FOREIGN KEY ("product_option_id") REFERENCES "product_options"("id")
+    "resource" text NOT NULL,
+    "resource_id" bigint NOT NULL,
+    "operation" change_operation NOT NULL,
+    "data" jsonb,
+    "changed_on" timestamp NOT NULL DEFAULT NOW(),
+    PRIMARY KEY ("id")
 );
 
-CREATE TABLE IF NOT EXISTS product_variant_bridge (
-    "id" bigserial,
-    "product_id" bigint NOT NULL,
-    "product_option_value_id" bigint NOT NULL,
-    "created_on" timestamp NOT NULL DEFAULT NOW(),
-    "archived_on" timestamp,
-    FOREIGN KEY ("product_id") REFERENCES "products"("id"),
-    FOREIGN KEY ("product_option_value_id") REFERENCES "product_option_values"("id")
-);`+"`"+`)
+-- the feed starts with everything that exists now, so a client reading it from the beginning ends up with the whole catalog
+INSERT INTO changes (resource, resource_id, operation, data)
+    SELECT 'product_root', id, 'created', to_jsonb(t) FROM product_roots t WHERE archived_on IS NULL ORDER BY id;
+INSERT INTO changes (resource, resource_id, operation, data)
+    SELECT 'product_image', id, 'created', to_jsonb(t) FROM product_images t WHERE archived_on IS NULL ORDER BY id;
+INSERT INTO changes (resource, resource_id, operation, data)
+    SELECT 'product_option', id, 'created', to_jsonb(t) FROM product_options t WHERE archived_on IS NULL ORDER BY id;
+INSERT INTO changes (resource, resource_id, operation, data)
+    SELECT 'product_option_value', id, 'created', to_jsonb(t) FROM product_option_values t WHERE archived_on IS NULL ORDER BY id;
+INSERT INTO changes (resource, resource_id, operation, data)
+    SELECT 'product', id, 'created', to_jsonb(t) FROM products t WHERE archived_on IS NULL ORDER BY id;
+INSERT INTO changes (resource, resource_id, operation, data)
+    SELECT 'discount', id, 'created', to_jsonb(t) FROM discounts t WHERE archived_on IS NULL ORDER BY id;
+
+-- record_change is called by a trigger on each table in the change feed, with the name of the table's
+-- resource as its argument. Archiving a row records a tombstone, which has no data.
+--
+-- The advisory lock is held until the writing transaction commits, so changes become visible in the order
+-- their ids were allocated, and a reader can't skip past a change that hasn't been committed yet.
+CREATE FUNCTION record_change() RETURNS trigger AS $$
+DECLARE
+    op change_operation;
+    row_data jsonb;
+BEGIN
+    PERFORM pg_advisory_xact_lock(hashtext('changes'));
+
+    IF TG_OP = 'INSERT' THEN
+        op := 'created';
+        row_data := to_jsonb(NEW);
+    ELSIF TG_OP = 'DELETE' OR (OLD.archived_on IS NULL AND NEW.archived_on IS NOT NULL) THEN
+        op := 'archived';
+    ELSIF OLD.archived_on IS NOT NULL AND NEW.archived_on IS NOT NULL THEN
+        -- changes to archived rows aren't interesting to anyone
+        RETURN NULL;
+    ELSE
+        op := 'updated';
+        row_data := to_jsonb(NEW);
+    END IF;
+
+    IF TG_OP = 'DELETE' THEN
+        INSERT INTO changes (resource, resource_id, operation, data) VALUES (TG_ARGV[0], OLD.id, op, row_data);
+    ELSE
+        INSERT INTO changes (resource, resource_id, operation, data) VALUES (TG_ARGV[0], NEW.id, op, row_data);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER product_roots_changes AFTER INSERT OR UPDATE OR DELETE ON product_roots
+    FOR EACH ROW EXECUTE PROCEDURE record_change('product_root');
+CREATE TRIGGER products_changes AFTER INSERT OR UPDATE OR DELETE ON products
+    FOR EACH ROW EXECUTE PROCEDURE record_change('product');
+CREATE TRIGGER product_options_changes AFTER INSERT OR UPDATE OR DELETE ON product_options
+    FOR EACH ROW EXECUTE PROCEDURE record_change('product_option');
+CREATE TRIGGER product_option_values_changes AFTER INSERT OR UPDATE OR DELETE ON product_option_values
+    FOR EACH ROW EXECUTE PROCEDURE record_change('product_option_value');
+CREATE TRIGGER discounts_changes AFTER INSERT OR UPDATE OR DELETE ON discounts
+    FOR EACH ROW EXECUTE PROCEDURE record_change('discount');
+CREATE TRIGGER product_images_changes AFTER INSERT OR UPDATE OR DELETE ON product_images
+    FOR EACH ROW EXECUTE PROCEDURE record_change('product_image');
+`)
 
-func _1495495688_productsUpSqlBytes() ([]byte, error) {
-	return __1495495688_productsUpSql, nil
+func _1527811213_changesUpSqlBytes() ([]byte, error) {
+	return __1527811213_changesUpSql, nil
 }
 
-func _1495495688_productsUpSql() (*asset, error) {
-	bytes, err := _1495495688_productsUpSqlBytes()
+func _1527811213_changesUpSql() (*asset, error) {
+	bytes, err := _1527811213_changesUpSqlBytes()
 	if err != nil {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "1495495688_products.up.sql", size: 5151, mode: os.FileMode(420), modTime: time.Unix(1520775515, 0)}
+	info := bindataFileInfo{name: "1527811213_changes.up.sql", size: 3775, mode: os.FileMode(420), modTime: time.Unix(1792340801, 0)}
 	a := &asset{bytes: bytes, info: info}
 	return a, nil
 }
 
-var __1496132528_discountsDownSql = []byte(`+"`"+`DROP TABLE discounts;
-DROP TYPE discount_type CASCADE;`+"`"+`)
+var __1527811214_api_keysDownSql = []byte(`DROP INDEX IF EXISTS "api_keys_user_id_idx";
+DROP TABLE IF EXISTS api_keys;
+`)
 
-func _1496132528_discountsDownSqlBytes() ([]byte, error) {
-	return __1496132528_discountsDownSql, nil
+func _1527811214_api_keysDownSqlBytes() ([]byte, error) {
+	return __1527811214_api_keysDownSql, nil
 }
 
-func _1496132528_discountsDownSql() (*asset, error) {
-	bytes, err := _1496132528_discountsDownSqlBytes()
+func _1527811214_api_keysDownSql() (*asset, error) {
+	bytes, err := _1527811214_api_keysDownSqlBytes()
 	if err != nil {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "1496132528_discounts.down.sql", size: 54, mode: os.FileMode(420), modTime: time.Unix(1520775515, 0)}
+	info := bindataFileInfo{name: "1527811214_api_keys.down.sql", size: 76, mode: os.FileMode(420), modTime: time.Unix(1792340977, 0)}
 	a := &asset{bytes: bytes, info: info}
 	return a, nil
 }
 
-var __1496132528_discountsUpSql = []byte(`+"`"+`CREATE TYPE discount_type AS ENUM ('percentage', 'flat_amount');
-CREATE TABLE IF NOT EXISTS discounts (
+var __1527811214_api_keysUpSql = []byte(`CREATE TABLE IF NOT EXISTS api_keys (
     "id" bigserial,
-    "name" text NOT NULL,
-    "discount_type" discount_type NOT NULL DEFAULT 'percentage',
-    "amount" numeric(15, 2) NOT NULL DEFAULT 0,
+    "user_id" bigint NOT NULL,
+    "name" text NOT NULL DEFAULT '',
+    "prefix" text NOT NULL,
+    "key_hash" text NOT NULL,
+    "last_used_on" timestamp,
     "expires_on" timestamp,
-    "requires_code" boolean NOT NULL DEFAULT FALSE,
-    "code" text NOT NULL DEFAULT '' CONSTRAINT code_must_be_provided CHECK(
-        (code != '' AND requires_code IS TRUE)
-                        OR
-        (code = '' AND requires_code IS FALSE)
-    ),
-    "limited_use" boolean NOT NULL DEFAULT FALSE,
-    "number_of_uses" bigint NOT NULL DEFAULT 0 CONSTRAINT use_number_must_be_provided CHECK(
-        (number_of_uses != 0 AND limited_use IS TRUE)
-                            OR
-        (number_of_uses = 0 AND limited_use IS FALSE)
-    ),
-    "login_required" boolean NOT NULL DEFAULT FALSE,
-    "starts_on" timestamp NOT NULL DEFAULT NOW(),
     "created_on" timestamp NOT NULL DEFAULT NOW(),
     "updated_on" timestamp,
     "archived_on" timestamp,
-    PRIMARY KEY ("id")
-);`+"`"+`)
+    UNIQUE ("key_hash"),
+    PRIMARY KEY ("id"),
+    FOREIGN KEY ("user_id") REFERENCES "users"("id")
+);
 
-func _1496132528_discountsUpSqlBytes() ([]byte, error) {
-	return __1496132528_discountsUpSql, nil
+CREATE INDEX "api_keys_user_id_idx" ON "api_keys" ("user_id") WHERE "archived_on" IS NULL;
+`)
+
+func _1527811214_api_keysUpSqlBytes() ([]byte, error) {
+	return __1527811214_api_keysUpSql, nil
 }
 
-func _1496132528_discountsUpSql() (*asset, error) {
-	bytes, err := _1496132528_discountsUpSqlBytes()
+func _1527811214_api_keysUpSql() (*asset, error) {
+	bytes, err := _1527811214_api_keysUpSqlBytes()
 	if err != nil {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "1496132528_discounts.up.sql", size: 1072, mode: os.FileMode(420), modTime: time.Unix(1520775515, 0)}
+	info := bindataFileInfo{name: "1527811214_api_keys.up.sql", size: 547, mode: os.FileMode(420), modTime: time.Unix(1792340977, 0)}
 	a := &asset{bytes: bytes, info: info}
 	return a, nil
 }
 
-var __1498638543_authDownSql = []byte(`+"`"+`DROP TABLE password_reset_tokens;
-DROP TABLE login_attempts;
-DROP TABLE users;`+"`"+`)
+var __1527811215_rolesDownSql = []byte(`DROP TABLE IF EXISTS user_roles;
+DROP TABLE IF EXISTS permissions;
+DROP TABLE IF EXISTS roles;
+`)
 
-func _1498638543_authDownSqlBytes() ([]byte, error) {
-	return __1498638543_authDownSql, nil
+func _1527811215_rolesDownSqlBytes() ([]byte, error) {
+	return __1527811215_rolesDownSql, nil
 }
 
-func _1498638543_authDownSql() (*asset, error) {
-	bytes, err := _1498638543_authDownSqlBytes()
+func _1527811215_rolesDownSql() (*asset, error) {
+	bytes, err := _1527811215_rolesDownSqlBytes()
 	if err != nil {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "1498638543_auth.down.sql", size: 78, mode: os.FileMode(420), modTime: time.Unix(1520775515, 0)}
+	info := bindataFileInfo{name: "1527811215_roles.down.sql", size: 95, mode: os.FileMode(420), modTime: time.Unix(1792341715, 0)}
 	a := &asset{bytes: bytes, info: info}
 	return a, nil
 }
 
-var __1498638543_authUpSql = []byte(`+"`"+`CREATE TABLE IF NOT EXISTS users (
+var __1527811215_rolesUpSql = []byte(`CREATE TABLE IF NOT EXISTS roles (
     "id" bigserial,
-    "first_name" text NOT NULL,
-    "last_name" text NOT NULL,
-    "username" text NOT NULL,
-    "email" text NOT NULL,
-    "password" text NOT NULL,
-    "is_admin" bool NOT NULL DEFAULT 'false',
-    "password_last_changed_on" timestamp,
+    "name" text NOT NULL,
+    "description" text NOT NULL DEFAULT '',
     "created_on" timestamp NOT NULL DEFAULT NOW(),
     "updated_on" timestamp,
     "archived_on" timestamp,
-    UNIQUE ("username", "archived_on"),
+    UNIQUE ("name"),
     PRIMARY KEY ("id")
 );
 
-CREATE TABLE IF NOT EXISTS password_reset_tokens (
+CREATE TABLE IF NOT EXISTS permissions (
     "id" bigserial,
-    "user_id" bigint NOT NULL,
-    "token" text NOT NULL,
+    "role_id" bigint NOT NULL,
+    "name" text NOT NULL,
     "created_on" timestamp NOT NULL DEFAULT NOW(),
-    "expires_on" timestamp NOT NULL DEFAULT NOW() + (15 * interval '1 minute'),
-    "password_reset_on" timestamp,
-    UNIQUE ("token"),
+    UNIQUE ("role_id", "name"),
     PRIMARY KEY ("id"),
-    FOREIGN KEY ("user_id") REFERENCES "users"("id")
+    FOREIGN KEY ("role_id") REFERENCES "roles"("id")
 );
 
-CREATE TABLE IF NOT EXISTS login_attempts (
-    "id" bigserial,
-    "username" text NOT NULL,
-    "successful" boolean NOT NULL DEFAULT 'false',
-    "created_on" timestamp NOT NULL DEFAULT NOW()
-)`+"`"+`)
+CREATE TABLE IF NOT EXISTS user_roles (
+    "user_id" bigint NOT NULL,
+    "role_id" bigint NOT NULL,
+    "created_on" timestamp NOT NULL DEFAULT NOW(),
+    PRIMARY KEY ("user_id", "role_id"),
+    FOREIGN KEY ("user_id") REFERENCES "users"("id"),
+    FOREIGN KEY ("role_id") REFERENCES "roles"("id")
+);
 
-func _1498638543_authUpSqlBytes() ([]byte, error) {
-	return __1498638543_authUpSql, nil
+INSERT INTO roles ("name", "description") VALUES
+    ('catalog_editor', 'Manages products, their options and their images'),
+    ('marketing', 'Manages discounts'),
+    ('support', 'Looks up users and resets their passwords'),
+    ('developer', 'Manages webhooks and API keys, and follows the event stream and change feed');
+
+INSERT INTO permissions ("role_id", "name")
+    SELECT r.id, p.name
+    FROM roles r
+    JOIN (VALUES
+        ('catalog_editor', 'products:write'),
+        ('marketing', 'discounts:read'),
+        ('marketing', 'discounts:write'),
+        ('support', 'users:read'),
+        ('support', 'users:reset_password'),
+        ('developer', 'webhooks:read'),
+        ('developer', 'webhooks:write'),
+        ('developer', 'api_keys:write'),
+        ('developer', 'events:read')
+    ) AS p (role_name, name) ON p.role_name = r.name;
+`)
+
+func _1527811215_rolesUpSqlBytes() ([]byte, error) {
+	return __1527811215_rolesUpSql, nil
 }
 
-func _1498638543_authUpSql() (*asset, error) {
-	bytes, err := _1498638543_authUpSqlBytes()
+func _1527811215_rolesUpSql() (*asset, error) {
+	bytes, err := _1527811215_rolesUpSqlBytes()
 	if err != nil {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "1498638543_auth.up.sql", size: 1062, mode: os.FileMode(420), modTime: time.Unix(1521205186, 0)}
+	info := bindataFileInfo{name: "1527811215_roles.up.sql", size: 1717, mode: os.FileMode(420), modTime: time.Unix(1792341715, 0)}
 	a := &asset{bytes: bytes, info: info}
 	return a, nil
 }
 
-var __1512371453_webhooksDownSql = []byte(`+"`"+`DROP TABLE webhook_execution_logs;
-DROP TABLE webhooks;
-DROP TYPE webhook_event CASCADE;
-DROP TYPE content_type CASCADE;`+"`"+`)
+var __1527811216_archived_product_root_imagesDownSql = []byte(`-- there's no telling which images were archived by the up migration, so they stay archived
+SELECT 1;
+`)
 
-func _1512371453_webhooksDownSqlBytes() ([]byte, error) {
-	return __1512371453_webhooksDownSql, nil
+func _1527811216_archived_product_root_imagesDownSqlBytes() ([]byte, error) {
+	return __1527811216_archived_product_root_imagesDownSql, nil
 }
 
-func _1512371453_webhooksDownSql() (*asset, error) {
-	bytes, err := _1512371453_webhooksDownSqlBytes()
+func _1527811216_archived_product_root_imagesDownSql() (*asset, error) {
+	bytes, err := _1527811216_archived_product_root_imagesDownSqlBytes()
 	if err != nil {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "1512371453_webhooks.down.sql", size: 120, mode: os.FileMode(420), modTime: time.Unix(1520775515, 0)}
+	info := bindataFileInfo{name: "1527811216_archived_product_root_images.down.sql", size: 102, mode: os.FileMode(420), modTime: time.Unix(1792343617, 0)}
 	a := &asset{bytes: bytes, info: info}
 	return a, nil
 }
 
-var __1512371453_webhooksUpSql = []byte(`+"`"+`CREATE TYPE webhook_event AS ENUM ('product_created', 'product_updated', 'product_archived');
-CREATE TYPE content_type AS ENUM ('application/json', 'application/xml');
+var __1527811216_archived_product_root_imagesUpSql = []byte(`-- product roots used to be archived without their images, which kept the images' files from ever being collected
+UPDATE product_images
+SET archived_on = product_roots.archived_on
+FROM product_roots
+WHERE product_images.product_root_id = product_roots.id
+AND product_roots.archived_on IS NOT NULL
+AND product_images.archived_on IS NULL;
+`)
 
-CREATE TABLE IF NOT EXISTS webhooks (
-    "id" bigserial,
-    "url" text NOT NULL,
-    "event_type" webhook_event NOT NULL,
-    "content_type" content_type NOT NULL DEFAULT 'application/json',
-    "created_on" timestamp NOT NULL DEFAULT NOW(),
-    "updated_on" timestamp,
-    "archived_on" timestamp,
-    PRIMARY KEY ("id")
-);
+func _1527811216_archived_product_root_imagesUpSqlBytes() ([]byte, error) {
+	return __1527811216_archived_product_root_imagesUpSql, nil
+}
 
-CREATE TABLE IF NOT EXISTS webhook_execution_logs (
-    "id" bigserial,
-    "webhook_id" bigint NOT NULL,
-    "status_code" int NOT NULL,
-    "succeeded" boolean NOT NULL DEFAULT 'false',
-    "executed_on" timestamp NOT NULL DEFAULT NOW(),
-    PRIMARY KEY ("id"),
-    FOREIGN KEY ("webhook_id") REFERENCES "webhooks"("id")
-);`+"`"+`)
+func _1527811216_archived_product_root_imagesUpSql() (*asset, error) {
+	bytes, err := _1527811216_archived_product_root_imagesUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
 
-func _1512371453_webhooksUpSqlBytes() ([]byte, error) {
-	return __1512371453_webhooksUpSql, nil
+	info := bindataFileInfo{name: "1527811216_archived_product_root_images.up.sql", size: 337, mode: os.FileMode(420), modTime: time.Unix(1792343617, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
 }
 
-func _1512371453_webhooksUpSql() (*asset, error) {
-	bytes, err := _1512371453_webhooksUpSqlBytes()
+var __1527811217_event_positionsDownSql = []byte(`DROP TRIGGER IF EXISTS events_position ON events;
+DROP FUNCTION IF EXISTS position_event();
+DROP INDEX IF EXISTS events_position_idx;
+ALTER TABLE events DROP COLUMN IF EXISTS "position";
+DROP SEQUENCE IF EXISTS events_position_seq;
+`)
+
+func _1527811217_event_positionsDownSqlBytes() ([]byte, error) {
+	return __1527811217_event_positionsDownSql, nil
+}
+
+func _1527811217_event_positionsDownSql() (*asset, error) {
+	bytes, err := _1527811217_event_positionsDownSqlBytes()
 	if err != nil {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "1512371453_webhooks.up.sql", size: 822, mode: os.FileMode(420), modTime: time.Unix(1520775515, 0)}
+	info := bindataFileInfo{name: "1527811217_event_positions.down.sql", size: 232, mode: os.FileMode(420), modTime: time.Unix(1792344406, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __1527811217_event_positionsUpSql = []byte(`-- Events used to take a lock as they were written and hold it until their transaction committed, so every
+-- transaction that recorded an event waited on every other one. Instead, events are now given a position
+-- by a deferred trigger, which runs as their transaction commits, so the lock is only held while committing.
+-- Positions still become visible in the order they're given out, so a reader that has seen one can't later
+-- find a lower one. Readers follow positions rather than ids, since ids are allocated in insertion order.
+CREATE SEQUENCE IF NOT EXISTS events_position_seq;
+ALTER TABLE events ADD COLUMN "position" bigint;
+UPDATE events SET "position" = id;
+SELECT setval('events_position_seq', COALESCE((SELECT MAX(id) FROM events), 0) + 1, false);
+CREATE UNIQUE INDEX events_position_idx ON events ("position");
+
+CREATE FUNCTION position_event() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_advisory_xact_lock(hashtext('events'));
+    UPDATE events SET "position" = nextval('events_position_seq') WHERE id = NEW.id;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE CONSTRAINT TRIGGER events_position AFTER INSERT ON events
+    DEFERRABLE INITIALLY DEFERRED
+    FOR EACH ROW EXECUTE PROCEDURE position_event();
+`)
+
+func _1527811217_event_positionsUpSqlBytes() ([]byte, error) {
+	return __1527811217_event_positionsUpSql, nil
+}
+
+func _1527811217_event_positionsUpSql() (*asset, error) {
+	bytes, err := _1527811217_event_positionsUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1527811217_event_positions.up.sql", size: 1227, mode: os.FileMode(420), modTime: time.Unix(1792344406, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __1527811218_change_positionsDownSql = []byte(`DROP TRIGGER IF EXISTS changes_position ON changes;
+DROP FUNCTION IF EXISTS position_change();
+DROP INDEX IF EXISTS changes_position_idx;
+ALTER TABLE changes DROP COLUMN IF EXISTS "position";
+DROP SEQUENCE IF EXISTS changes_position_seq;
+
+CREATE OR REPLACE FUNCTION record_change() RETURNS trigger AS $$
+DECLARE
+    op change_operation;
+    row_data jsonb;
+BEGIN
+    PERFORM pg_advisory_xact_lock(hashtext('changes'));
+
+    IF TG_OP = 'INSERT' THEN
+        op := 'created';
+        row_data := to_jsonb(NEW);
+    ELSIF TG_OP = 'DELETE' OR (OLD.archived_on IS NULL AND NEW.archived_on IS NOT NULL) THEN
+        op := 'archived';
+    ELSIF OLD.archived_on IS NOT NULL AND NEW.archived_on IS NOT NULL THEN
+        RETURN NULL;
+    ELSE
+        op := 'updated';
+        row_data := to_jsonb(NEW);
+    END IF;
+
+    IF TG_OP = 'DELETE' THEN
+        INSERT INTO changes (resource, resource_id, operation, data) VALUES (TG_ARGV[0], OLD.id, op, row_data);
+    ELSE
+        INSERT INTO changes (resource, resource_id, operation, data) VALUES (TG_ARGV[0], NEW.id, op, row_data);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+`)
+
+func _1527811218_change_positionsDownSqlBytes() ([]byte, error) {
+	return __1527811218_change_positionsDownSql, nil
+}
+
+func _1527811218_change_positionsDownSql() (*asset, error) {
+	bytes, err := _1527811218_change_positionsDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1527811218_change_positions.down.sql", size: 1123, mode: os.FileMode(420), modTime: time.Unix(1792346689, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var __1527811218_change_positionsUpSql = []byte(`-- Recording a change used to take a lock that was held until the writing transaction committed, so every
+-- transaction that touched the catalog waited on every other one. Like events, changes are now given a
+-- position by a deferred trigger as their transaction commits, so the lock is only held while committing.
+-- Readers follow positions rather than ids, and existing positions match ids, so tokens clients already
+-- have keep working.
+CREATE SEQUENCE IF NOT EXISTS changes_position_seq;
+ALTER TABLE changes ADD COLUMN "position" bigint;
+UPDATE changes SET "position" = id;
+SELECT setval('changes_position_seq', COALESCE((SELECT MAX(id) FROM changes), 0) + 1, false);
+CREATE UNIQUE INDEX changes_position_idx ON changes ("position");
+
+CREATE OR REPLACE FUNCTION record_change() RETURNS trigger AS $$
+DECLARE
+    op change_operation;
+    row_data jsonb;
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        op := 'created';
+        row_data := to_jsonb(NEW);
+    ELSIF TG_OP = 'DELETE' OR (OLD.archived_on IS NULL AND NEW.archived_on IS NOT NULL) THEN
+        op := 'archived';
+    ELSIF OLD.archived_on IS NOT NULL AND NEW.archived_on IS NOT NULL THEN
+        -- changes to archived rows aren't interesting to anyone
+        RETURN NULL;
+    ELSE
+        op := 'updated';
+        row_data := to_jsonb(NEW);
+    END IF;
+
+    IF TG_OP = 'DELETE' THEN
+        INSERT INTO changes (resource, resource_id, operation, data) VALUES (TG_ARGV[0], OLD.id, op, row_data);
+    ELSE
+        INSERT INTO changes (resource, resource_id, operation, data) VALUES (TG_ARGV[0], NEW.id, op, row_data);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE FUNCTION position_change() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_advisory_xact_lock(hashtext('changes'));
+    UPDATE changes SET "position" = nextval('changes_position_seq') WHERE id = NEW.id;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE CONSTRAINT TRIGGER changes_position AFTER INSERT ON changes
+    DEFERRABLE INITIALLY DEFERRED
+    FOR EACH ROW EXECUTE PROCEDURE position_change();
+`)
+
+func _1527811218_change_positionsUpSqlBytes() ([]byte, error) {
+	return __1527811218_change_positionsUpSql, nil
+}
+
+func _1527811218_change_positionsUpSql() (*asset, error) {
+	bytes, err := _1527811218_change_positionsUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1527811218_change_positions.up.sql", size: 2040, mode: os.FileMode(420), modTime: time.Unix(1792346689, 0)}
 	a := &asset{bytes: bytes, info: info}
 	return a, nil
 }
 
-var __9999999999_example_dataDownSql = []byte(`+"`"+`DELETE FROM webhooks WHERE id IS NOT NULL;
+var __9999999999_example_dataDownSql = []byte(`DELETE FROM webhooks WHERE id IS NOT NULL;
 DELETE FROM discounts WHERE id IS NOT NULL;
 DELETE FROM product_variant_bridge WHERE id IS NOT NULL;
 DELETE FROM product_option_values WHERE id IS NOT NULL;
 DELETE FROM product_options WHERE id IS NOT NULL;
-DELETE FROM products WHERE id IS NOT NULL;`+"`"+`)
+DELETE FROM products WHERE id IS NOT NULL;`)
 
 func _9999999999_example_dataDownSqlBytes() ([]byte, error) {
 	return __9999999999_example_dataDownSql, nil
@@ -1755,7 +1532,7 @@ func _9999999999_example_dataDownSql() (*asset, error) {
 	return a, nil
 }
 
-var __9999999999_example_dataUpSql = []byte(`+"`"+`INSERT INTO product_roots
+var __9999999999_example_dataUpSql = []byte(`INSERT INTO product_roots
 (
     "name",
     "subtitle",
@@ -2617,19 +2394,19 @@ VALUES
     'http://httpbin/status/200',
     'product_archived'
 );
-`+"`"+``)
+`)
 
-func bindataGoBytes() ([]byte, error) {
-	return _bindataGo, nil
+func _9999999999_example_dataUpSqlBytes() ([]byte, error) {
+	return __9999999999_example_dataUpSql, nil
 }
 
-func bindataGo() (*asset, error) {
-	bytes, err := bindataGoBytes()
+func _9999999999_example_dataUpSql() (*asset, error) {
+	bytes, err := _9999999999_example_dataUpSqlBytes()
 	if err != nil {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "bindata.go", size: 91070, mode: os.FileMode(420), modTime: time.Unix(1526606774, 0)}
+	info := bindataFileInfo{name: "9999999999_example_data.up.sql", size: 31030, mode: os.FileMode(420), modTime: time.Unix(1520775515, 0)}
 	a := &asset{bytes: bytes, info: info}
 	return a, nil
 }
@@ -2694,9 +2471,46 @@ var _bindata = map[string]func() (*asset, error){
 	"1498638543_auth.up.sql": _1498638543_authUpSql,
 	"1512371453_webhooks.down.sql": _1512371453_webhooksDownSql,
 	"1512371453_webhooks.up.sql": _1512371453_webhooksUpSql,
+	"1527811200_variant_templates.down.sql": _1527811200_variant_templatesDownSql,
+	"1527811200_variant_templates.up.sql": _1527811200_variant_templatesUpSql,
+	"1527811201_variant_images.down.sql": _1527811201_variant_imagesDownSql,
+	"1527811201_variant_images.up.sql": _1527811201_variant_imagesUpSql,
+	"1527811202_product_image_sort_order.down.sql": _1527811202_product_image_sort_orderDownSql,
+	"1527811202_product_image_sort_order.up.sql": _1527811202_product_image_sort_orderUpSql,
+	"1527811203_product_image_renditions.down.sql": _1527811203_product_image_renditionsDownSql,
+	"1527811203_product_image_renditions.up.sql": _1527811203_product_image_renditionsUpSql,
+	"1527811204_product_image_hashes.down.sql": _1527811204_product_image_hashesDownSql,
+	"1527811204_product_image_hashes.up.sql": _1527811204_product_image_hashesUpSql,
+	"1527811205_webhook_deliveries.down.sql": _1527811205_webhook_deliveriesDownSql,
+	"1527811205_webhook_deliveries.up.sql": _1527811205_webhook_deliveriesUpSql,
+	"1527811206_webhook_secrets.down.sql": _1527811206_webhook_secretsDownSql,
+	"1527811206_webhook_secrets.up.sql": _1527811206_webhook_secretsUpSql,
+	"1527811207_webhook_event_types.down.sql": _1527811207_webhook_event_typesDownSql,
+	"1527811207_webhook_event_types.up.sql": _1527811207_webhook_event_typesUpSql,
+	"1527811208_webhook_execution_log_details.down.sql": _1527811208_webhook_execution_log_detailsDownSql,
+	"1527811208_webhook_execution_log_details.up.sql": _1527811208_webhook_execution_log_detailsUpSql,
+	"1527811209_webhook_status.down.sql": _1527811209_webhook_statusDownSql,
+	"1527811209_webhook_status.up.sql": _1527811209_webhook_statusUpSql,
+	"1527811210_webhook_failure_tracking.down.sql": _1527811210_webhook_failure_trackingDownSql,
+	"1527811210_webhook_failure_tracking.up.sql": _1527811210_webhook_failure_trackingUpSql,
+	"1527811211_webhook_payload_filters.down.sql": _1527811211_webhook_payload_filtersDownSql,
+	"1527811211_webhook_payload_filters.up.sql": _1527811211_webhook_payload_filtersUpSql,
+	"1527811212_events.down.sql": _1527811212_eventsDownSql,
+	"1527811212_events.up.sql": _1527811212_eventsUpSql,
+	"1527811213_changes.down.sql": _1527811213_changesDownSql,
+	"1527811213_changes.up.sql": _1527811213_changesUpSql,
+	"1527811214_api_keys.down.sql": _1527811214_api_keysDownSql,
+	"1527811214_api_keys.up.sql": _1527811214_api_keysUpSql,
+	"1527811215_roles.down.sql": _1527811215_rolesDownSql,
+	"1527811215_roles.up.sql": _1527811215_rolesUpSql,
+	"1527811216_archived_product_root_images.down.sql": _1527811216_archived_product_root_imagesDownSql,
+	"1527811216_archived_product_root_images.up.sql": _1527811216_archived_product_root_imagesUpSql,
+	"1527811217_event_positions.down.sql": _1527811217_event_positionsDownSql,
+	"1527811217_event_positions.up.sql": _1527811217_event_positionsUpSql,
+	"1527811218_change_positions.down.sql": _1527811218_change_positionsDownSql,
+	"1527811218_change_positions.up.sql": _1527811218_change_positionsUpSql,
 	"9999999999_example_data.down.sql": _9999999999_example_dataDownSql,
 	"9999999999_example_data.up.sql": _9999999999_example_dataUpSql,
-	"bindata.go": bindataGo,
 }
 
 // AssetDir returns the file names below a certain
@@ -2747,9 +2561,46 @@ var _bintree = &bintree{nil, map[string]*bintree{
 	"1498638543_auth.up.sql": &bintree{_1498638543_authUpSql, map[string]*bintree{}},
 	"1512371453_webhooks.down.sql": &bintree{_1512371453_webhooksDownSql, map[string]*bintree{}},
 	"1512371453_webhooks.up.sql": &bintree{_1512371453_webhooksUpSql, map[string]*bintree{}},
+	"1527811200_variant_templates.down.sql": &bintree{_1527811200_variant_templatesDownSql, map[string]*bintree{}},
+	"1527811200_variant_templates.up.sql": &bintree{_1527811200_variant_templatesUpSql, map[string]*bintree{}},
+	"1527811201_variant_images.down.sql": &bintree{_1527811201_variant_imagesDownSql, map[string]*bintree{}},
+	"1527811201_variant_images.up.sql": &bintree{_1527811201_variant_imagesUpSql, map[string]*bintree{}},
+	"1527811202_product_image_sort_order.down.sql": &bintree{_1527811202_product_image_sort_orderDownSql, map[string]*bintree{}},
+	"1527811202_product_image_sort_order.up.sql": &bintree{_1527811202_product_image_sort_orderUpSql, map[string]*bintree{}},
+	"1527811203_product_image_renditions.down.sql": &bintree{_1527811203_product_image_renditionsDownSql, map[string]*bintree{}},
+	"1527811203_product_image_renditions.up.sql": &bintree{_1527811203_product_image_renditionsUpSql, map[string]*bintree{}},
+	"1527811204_product_image_hashes.down.sql": &bintree{_1527811204_product_image_hashesDownSql, map[string]*bintree{}},
+	"1527811204_product_image_hashes.up.sql": &bintree{_1527811204_product_image_hashesUpSql, map[string]*bintree{}},
+	"1527811205_webhook_deliveries.down.sql": &bintree{_1527811205_webhook_deliveriesDownSql, map[string]*bintree{}},
+	"1527811205_webhook_deliveries.up.sql": &bintree{_1527811205_webhook_deliveriesUpSql, map[string]*bintree{}},
+	"1527811206_webhook_secrets.down.sql": &bintree{_1527811206_webhook_secretsDownSql, map[string]*bintree{}},
+	"1527811206_webhook_secrets.up.sql": &bintree{_1527811206_webhook_secretsUpSql, map[string]*bintree{}},
+	"1527811207_webhook_event_types.down.sql": &bintree{_1527811207_webhook_event_typesDownSql, map[string]*bintree{}},
+	"1527811207_webhook_event_types.up.sql": &bintree{_1527811207_webhook_event_typesUpSql, map[string]*bintree{}},
+	"1527811208_webhook_execution_log_details.down.sql": &bintree{_1527811208_webhook_execution_log_detailsDownSql, map[string]*bintree{}},
+	"1527811208_webhook_execution_log_details.up.sql": &bintree{_1527811208_webhook_execution_log_detailsUpSql, map[string]*bintree{}},
+	"1527811209_webhook_status.down.sql": &bintree{_1527811209_webhook_statusDownSql, map[string]*bintree{}},
+	"1527811209_webhook_status.up.sql": &bintree{_1527811209_webhook_statusUpSql, map[string]*bintree{}},
+	"1527811210_webhook_failure_tracking.down.sql": &bintree{_1527811210_webhook_failure_trackingDownSql, map[string]*bintree{}},
+	"1527811210_webhook_failure_tracking.up.sql": &bintree{_1527811210_webhook_failure_trackingUpSql, map[string]*bintree{}},
+	"1527811211_webhook_payload_filters.down.sql": &bintree{_1527811211_webhook_payload_filtersDownSql, map[string]*bintree{}},
+	"1527811211_webhook_payload_filters.up.sql": &bintree{_1527811211_webhook_payload_filtersUpSql, map[string]*bintree{}},
+	"1527811212_events.down.sql": &bintree{_1527811212_eventsDownSql, map[string]*bintree{}},
+	"1527811212_events.up.sql": &bintree{_1527811212_eventsUpSql, map[string]*bintree{}},
+	"1527811213_changes.down.sql": &bintree{_1527811213_changesDownSql, map[string]*bintree{}},
+	"1527811213_changes.up.sql": &bintree{_1527811213_changesUpSql, map[string]*bintree{}},
+	"1527811214_api_keys.down.sql": &bintree{_1527811214_api_keysDownSql, map[string]*bintree{}},
+	"1527811214_api_keys.up.sql": &bintree{_1527811214_api_keysUpSql, map[string]*bintree{}},
+	"1527811215_roles.down.sql": &bintree{_1527811215_rolesDownSql, map[string]*bintree{}},
+	"1527811215_roles.up.sql": &bintree{_1527811215_rolesUpSql, map[string]*bintree{}},
+	"1527811216_archived_product_root_images.down.sql": &bintree{_1527811216_archived_product_root_imagesDownSql, map[string]*bintree{}},
+	"1527811216_archived_product_root_images.up.sql": &bintree{_1527811216_archived_product_root_imagesUpSql, map[string]*bintree{}},
+	"1527811217_event_positions.down.sql": &bintree{_1527811217_event_positionsDownSql, map[string]*bintree{}},
+	"1527811217_event_positions.up.sql": &bintree{_1527811217_event_positionsUpSql, map[string]*bintree{}},
+	"1527811218_change_positions.down.sql": &bintree{_1527811218_change_positionsDownSql, map[string]*bintree{}},
+	"1527811218_change_positions.up.sql": &bintree{_1527811218_change_positionsUpSql, map[string]*bintree{}},
 	"9999999999_example_data.down.sql": &bintree{_9999999999_example_dataDownSql, map[string]*bintree{}},
 	"9999999999_example_data.up.sql": &bintree{_9999999999_example_dataUpSql, map[string]*bintree{}},
-	"bindata.go": &bintree{bindataGo, map[string]*bintree{}},
 }}
 
 // RestoreAsset restores an asset under the given directory
@@ -2,6 +2,8 @@ package postgres
 
 import (
 	"database/sql"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/dairycart/dairycart/models/v1"
@@ -29,7 +31,9 @@ const productImageBridgeSelectionQuery = `
     SELECT
         id,
         product_id,
-        product_image_id
+        product_image_id,
+        created_on,
+        archived_on
     FROM
         product_image_bridge
     WHERE
@@ -41,7 +45,7 @@ const productImageBridgeSelectionQuery = `
 func (pg *postgres) GetProductImageBridge(db database.Querier, id uint64) (*models.ProductImageBridge, error) {
 	p := &models.ProductImageBridge{}
 
-	err := db.QueryRow(productImageBridgeSelectionQuery, id).Scan(&p.ID, &p.ProductID, &p.ProductImageID)
+	err := db.QueryRow(productImageBridgeSelectionQuery, id).Scan(&p.ID, &p.ProductID, &p.ProductImageID, &p.CreatedOn, &p.ArchivedOn)
 
 	return p, err
 }
@@ -53,6 +57,8 @@ func buildProductImageBridgeListRetrievalQuery(qf *models.QueryFilter) (string,
 			"id",
 			"product_id",
 			"product_image_id",
+			"created_on",
+			"archived_on",
 		).
 		From("product_image_bridge")
 
@@ -75,6 +81,8 @@ func (pg *postgres) GetProductImageBridgeList(db database.Querier, qf *models.Qu
 			&p.ID,
 			&p.ProductID,
 			&p.ProductImageID,
+			&p.CreatedOn,
+			&p.ArchivedOn,
 		)
 		if err != nil {
 			return nil, err
@@ -123,6 +131,34 @@ func (pg *postgres) CreateProductImageBridge(db database.Querier, nu *models.Pro
 	return createdID, createdOn, err
 }
 
+func buildMultiProductImageBridgeCreationQuery(productID uint64, imageIDs []uint64) (query string, values []interface{}) {
+	values = append(values, productID)
+	var valueStrings []string
+	for ix, id := range imageIDs {
+		valueStrings = append(valueStrings, fmt.Sprintf("($1, $%d)", ix+2))
+		values = append(values, id)
+	}
+
+	query = fmt.Sprintf(`
+        INSERT INTO product_image_bridge
+            (
+                product_id, product_image_id
+            )
+        VALUES
+            %s
+        ON CONFLICT DO NOTHING;
+    `, strings.Join(valueStrings, `,
+            `))
+
+	return query, values
+}
+
+func (pg *postgres) CreateMultipleProductImageBridgesForProductID(db database.Querier, productID uint64, imageIDs []uint64) error {
+	query, args := buildMultiProductImageBridgeCreationQuery(productID, imageIDs)
+	_, err := db.Exec(query, args...)
+	return err
+}
+
 const productImageBridgeUpdateQuery = `
     UPDATE product_image_bridge
     SET
@@ -150,3 +186,17 @@ func (pg *postgres) DeleteProductImageBridge(db database.Querier, id uint64) (t
 	err = db.QueryRow(productImageBridgeDeletionQuery, id).Scan(&t)
 	return t, err
 }
+
+const productImageBridgeDeletionQueryByProductIDAndImageID = `
+    UPDATE product_image_bridge
+    SET archived_on = NOW()
+    WHERE product_id = $1
+    AND product_image_id = $2
+    AND archived_on IS NULL
+    RETURNING archived_on
+`
+
+func (pg *postgres) DeleteProductImageBridgeByProductIDAndImageID(db database.Querier, productID uint64, imageID uint64) (t time.Time, err error) {
+	err = db.QueryRow(productImageBridgeDeletionQueryByProductIDAndImageID, productID, imageID).Scan(&t)
+	return t, err
+}
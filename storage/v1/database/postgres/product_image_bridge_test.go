@@ -69,10 +69,14 @@ func setProductImageBridgeReadQueryExpectation(t *testing.T, mock sqlmock.Sqlmoc
 		"id",
 		"product_id",
 		"product_image_id",
+		"created_on",
+		"archived_on",
 	}).AddRow(
 		toReturn.ID,
 		toReturn.ProductID,
 		toReturn.ProductImageID,
+		toReturn.CreatedOn,
+		toReturn.ArchivedOn,
 	)
 	mock.ExpectQuery(query).WithArgs(id).WillReturnRows(exampleRows).WillReturnError(err)
 }
@@ -101,18 +105,26 @@ func setProductImageBridgeListReadQueryExpectation(t *testing.T, mock sqlmock.Sq
 		"id",
 		"product_id",
 		"product_image_id",
+		"created_on",
+		"archived_on",
 	}).AddRow(
 		example.ID,
 		example.ProductID,
 		example.ProductImageID,
+		example.CreatedOn,
+		example.ArchivedOn,
 	).AddRow(
 		example.ID,
 		example.ProductID,
 		example.ProductImageID,
+		example.CreatedOn,
+		example.ArchivedOn,
 	).AddRow(
 		example.ID,
 		example.ProductID,
 		example.ProductImageID,
+		example.CreatedOn,
+		example.ArchivedOn,
 	).RowError(1, rowErr)
 
 	query, _ := buildProductImageBridgeListRetrievalQuery(qf)
@@ -333,3 +345,114 @@ func TestDeleteProductImageBridgeByID(t *testing.T) {
 		assert.Nil(t, mock.ExpectationsWereMet(), "not all database expectations were met")
 	})
 }
+
+func TestBuildMultiProductImageBridgeCreationQuery(t *testing.T) {
+	t.Run("single productimagebridge", func(*testing.T) {
+		expectedQuery := `
+        INSERT INTO product_image_bridge
+            (
+                product_id, product_image_id
+            )
+        VALUES
+            ($1, $2)
+        ON CONFLICT DO NOTHING;
+    `
+		expectedValues := []interface{}{
+			uint64(1),
+			uint64(2),
+		}
+
+		exampleInput := []uint64{2}
+		actualQuery, actualValues := buildMultiProductImageBridgeCreationQuery(1, exampleInput)
+
+		assert.Equal(t, expectedQuery, actualQuery)
+		assert.Equal(t, expectedValues, actualValues)
+	})
+
+	t.Run("multiple productimagebridge", func(*testing.T) {
+		expectedQuery := `
+        INSERT INTO product_image_bridge
+            (
+                product_id, product_image_id
+            )
+        VALUES
+            ($1, $2),
+            ($1, $3),
+            ($1, $4)
+        ON CONFLICT DO NOTHING;
+    `
+		expectedValues := []interface{}{
+			uint64(1),
+			uint64(2),
+			uint64(3),
+			uint64(4),
+		}
+
+		exampleInput := []uint64{2, 3, 4}
+		actualQuery, actualValues := buildMultiProductImageBridgeCreationQuery(1, exampleInput)
+
+		assert.Equal(t, expectedQuery, actualQuery)
+		assert.Equal(t, expectedValues, actualValues)
+	})
+}
+
+func setMultipleProductImageBridgeCreationQueryExpectation(t *testing.T, mock sqlmock.Sqlmock, productID uint64, imageIDs []uint64, err error) {
+	t.Helper()
+	query, args := buildMultiProductImageBridgeCreationQuery(productID, imageIDs)
+	queryToExpect := formatQueryForSQLMock(query)
+
+	var argsToExpect []driver.Value
+	for _, x := range args {
+		argsToExpect = append(argsToExpect, x)
+	}
+
+	mock.ExpectExec(queryToExpect).
+		WithArgs(argsToExpect...).
+		WillReturnResult(sqlmock.NewResult(1, 1)).
+		WillReturnError(err)
+}
+
+func TestCreateMultipleProductImageBridgesForProductID(t *testing.T) {
+	t.Parallel()
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+	client := NewPostgres()
+
+	t.Run("optimal behavior", func(t *testing.T) {
+		exampleProductID := uint64(1)
+		exampleImageIDs := []uint64{2, 3, 4}
+		setMultipleProductImageBridgeCreationQueryExpectation(t, mock, exampleProductID, exampleImageIDs, nil)
+		err := client.CreateMultipleProductImageBridgesForProductID(mockDB, exampleProductID, exampleImageIDs)
+
+		assert.NoError(t, err)
+		assert.Nil(t, mock.ExpectationsWereMet(), "not all database expectations were met")
+	})
+}
+
+func setProductImageBridgeDeletionByProductIDAndImageIDQueryExpectation(t *testing.T, mock sqlmock.Sqlmock, productID uint64, imageID uint64, err error) {
+	t.Helper()
+	query := formatQueryForSQLMock(productImageBridgeDeletionQueryByProductIDAndImageID)
+	exampleRows := sqlmock.NewRows([]string{"archived_on"}).AddRow(buildTestTime(t))
+	mock.ExpectQuery(query).WithArgs(productID, imageID).WillReturnRows(exampleRows).WillReturnError(err)
+}
+
+func TestDeleteProductImageBridgeByProductIDAndImageID(t *testing.T) {
+	t.Parallel()
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+	exampleProductID := uint64(1)
+	exampleImageID := uint64(2)
+	client := NewPostgres()
+
+	t.Run("optimal behavior", func(t *testing.T) {
+		setProductImageBridgeDeletionByProductIDAndImageIDQueryExpectation(t, mock, exampleProductID, exampleImageID, nil)
+		expected := buildTestTime(t)
+		actual, err := client.DeleteProductImageBridgeByProductIDAndImageID(mockDB, exampleProductID, exampleImageID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, expected, actual, "expected deletion time did not match actual deletion time")
+		assert.Nil(t, mock.ExpectationsWereMet(), "not all database expectations were met")
+	})
+}
@@ -24,6 +24,21 @@ func (pg *postgres) SetPrimaryProductImageForProduct(db database.Querier, produc
 	return t, err
 }
 
+const clearProductPrimaryImageQuery = `
+    UPDATE products
+    SET
+        primary_image_id = NULL,
+        updated_on = NOW()
+    WHERE id = $1
+    RETURNING updated_on;
+`
+
+// ClearPrimaryProductImageForProduct leaves a product without a primary image
+func (pg *postgres) ClearPrimaryProductImageForProduct(db database.Querier, productID uint64) (t time.Time, err error) {
+	err = db.QueryRow(clearProductPrimaryImageQuery, productID).Scan(&t)
+	return t, err
+}
+
 const productImageQueryByProductID = `
     SELECT
         id,
@@ -34,13 +49,19 @@ const productImageQueryByProductID = `
         source_url,
         created_on,
         updated_on,
-        archived_on
+        archived_on,
+        sort_order,
+        renditions,
+        content_hash,
+        perceptual_hash
     FROM
         product_images
     WHERE
         archived_on is null
     AND
-        product_id = $1
+        id IN (SELECT product_image_id FROM product_image_bridge WHERE product_id = $1 AND archived_on IS NULL)
+    ORDER BY
+        sort_order, id
 `
 
 func (pg *postgres) GetProductImagesByProductID(db database.Querier, productID uint64) ([]models.ProductImage, error) {
@@ -63,6 +84,265 @@ func (pg *postgres) GetProductImagesByProductID(db database.Querier, productID u
 			&p.CreatedOn,
 			&p.UpdatedOn,
 			&p.ArchivedOn,
+			&p.SortOrder,
+			&p.Renditions,
+			&p.ContentHash,
+			&p.PerceptualHash,
+		)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, p)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return list, err
+}
+
+const productImageQueryByProductRootID = `
+    SELECT
+        id,
+        product_root_id,
+        thumbnail_url,
+        main_url,
+        original_url,
+        source_url,
+        created_on,
+        updated_on,
+        archived_on,
+        sort_order,
+        renditions,
+        content_hash,
+        perceptual_hash
+    FROM
+        product_images
+    WHERE
+        archived_on is null
+    AND
+        product_root_id = $1
+    ORDER BY
+        sort_order, id
+`
+
+func (pg *postgres) GetProductImagesByProductRootID(db database.Querier, productRootID uint64) ([]models.ProductImage, error) {
+	var list []models.ProductImage
+
+	rows, err := db.Query(productImageQueryByProductRootID, productRootID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var p models.ProductImage
+		err := rows.Scan(
+			&p.ID,
+			&p.ProductRootID,
+			&p.ThumbnailURL,
+			&p.MainURL,
+			&p.OriginalURL,
+			&p.SourceURL,
+			&p.CreatedOn,
+			&p.UpdatedOn,
+			&p.ArchivedOn,
+			&p.SortOrder,
+			&p.Renditions,
+			&p.ContentHash,
+			&p.PerceptualHash,
+		)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, p)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return list, err
+}
+
+const productImageQueryByContentHash = `
+    SELECT
+        id,
+        product_root_id,
+        thumbnail_url,
+        main_url,
+        original_url,
+        source_url,
+        created_on,
+        updated_on,
+        archived_on,
+        sort_order,
+        renditions,
+        content_hash,
+        perceptual_hash
+    FROM
+        product_images
+    WHERE
+        archived_on is null
+    AND
+        content_hash = $1
+    ORDER BY
+        id
+`
+
+func (pg *postgres) GetProductImagesByContentHash(db database.Querier, contentHash string) ([]models.ProductImage, error) {
+	var list []models.ProductImage
+
+	rows, err := db.Query(productImageQueryByContentHash, contentHash)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var p models.ProductImage
+		err := rows.Scan(
+			&p.ID,
+			&p.ProductRootID,
+			&p.ThumbnailURL,
+			&p.MainURL,
+			&p.OriginalURL,
+			&p.SourceURL,
+			&p.CreatedOn,
+			&p.UpdatedOn,
+			&p.ArchivedOn,
+			&p.SortOrder,
+			&p.Renditions,
+			&p.ContentHash,
+			&p.PerceptualHash,
+		)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, p)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return list, err
+}
+
+const hashedProductImagesQuery = `
+    SELECT
+        id,
+        product_root_id,
+        thumbnail_url,
+        main_url,
+        original_url,
+        source_url,
+        created_on,
+        updated_on,
+        archived_on,
+        sort_order,
+        renditions,
+        content_hash,
+        perceptual_hash
+    FROM
+        product_images
+    WHERE
+        archived_on is null
+    AND
+        perceptual_hash <> ''
+    ORDER BY
+        id
+`
+
+func (pg *postgres) GetHashedProductImages(db database.Querier) ([]models.ProductImage, error) {
+	var list []models.ProductImage
+
+	rows, err := db.Query(hashedProductImagesQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var p models.ProductImage
+		err := rows.Scan(
+			&p.ID,
+			&p.ProductRootID,
+			&p.ThumbnailURL,
+			&p.MainURL,
+			&p.OriginalURL,
+			&p.SourceURL,
+			&p.CreatedOn,
+			&p.UpdatedOn,
+			&p.ArchivedOn,
+			&p.SortOrder,
+			&p.Renditions,
+			&p.ContentHash,
+			&p.PerceptualHash,
+		)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, p)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return list, err
+}
+
+const productImagesAfterIDQuery = `
+    SELECT
+        id,
+        product_root_id,
+        thumbnail_url,
+        main_url,
+        original_url,
+        source_url,
+        created_on,
+        updated_on,
+        archived_on,
+        sort_order,
+        renditions,
+        content_hash,
+        perceptual_hash
+    FROM
+        product_images
+    WHERE
+        archived_on is null
+    AND
+        id > $1
+    ORDER BY
+        id
+    LIMIT $2
+`
+
+// GetProductImagesAfterID returns up to limit product images with IDs greater than afterID, in ID order. Paging
+// through them by ID doesn't skip or repeat images the way pages of a list do when rows change in between.
+func (pg *postgres) GetProductImagesAfterID(db database.Querier, afterID uint64, limit uint) ([]models.ProductImage, error) {
+	var list []models.ProductImage
+
+	rows, err := db.Query(productImagesAfterIDQuery, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var p models.ProductImage
+		err := rows.Scan(
+			&p.ID,
+			&p.ProductRootID,
+			&p.ThumbnailURL,
+			&p.MainURL,
+			&p.OriginalURL,
+			&p.SourceURL,
+			&p.CreatedOn,
+			&p.UpdatedOn,
+			&p.ArchivedOn,
+			&p.SortOrder,
+			&p.Renditions,
+			&p.ContentHash,
+			&p.PerceptualHash,
 		)
 		if err != nil {
 			return nil, err
@@ -102,7 +382,11 @@ const productImageSelectionQuery = `
         source_url,
         created_on,
         updated_on,
-        archived_on
+        archived_on,
+        sort_order,
+        renditions,
+        content_hash,
+        perceptual_hash
     FROM
         product_images
     WHERE
@@ -114,7 +398,7 @@ const productImageSelectionQuery = `
 func (pg *postgres) GetProductImage(db database.Querier, id uint64) (*models.ProductImage, error) {
 	p := &models.ProductImage{}
 
-	err := db.QueryRow(productImageSelectionQuery, id).Scan(&p.ID, &p.ProductRootID, &p.ThumbnailURL, &p.MainURL, &p.OriginalURL, &p.SourceURL, &p.CreatedOn, &p.UpdatedOn, &p.ArchivedOn)
+	err := db.QueryRow(productImageSelectionQuery, id).Scan(&p.ID, &p.ProductRootID, &p.ThumbnailURL, &p.MainURL, &p.OriginalURL, &p.SourceURL, &p.CreatedOn, &p.UpdatedOn, &p.ArchivedOn, &p.SortOrder, &p.Renditions, &p.ContentHash, &p.PerceptualHash)
 
 	return p, err
 }
@@ -132,6 +416,10 @@ func buildProductImageListRetrievalQuery(qf *models.QueryFilter) (string, []inte
 			"created_on",
 			"updated_on",
 			"archived_on",
+			"sort_order",
+			"renditions",
+			"content_hash",
+			"perceptual_hash",
 		).
 		From("product_images")
 
@@ -160,6 +448,10 @@ func (pg *postgres) GetProductImageList(db database.Querier, qf *models.QueryFil
 			&p.CreatedOn,
 			&p.UpdatedOn,
 			&p.ArchivedOn,
+			&p.SortOrder,
+			&p.Renditions,
+			&p.ContentHash,
+			&p.PerceptualHash,
 		)
 		if err != nil {
 			return nil, err
@@ -193,18 +485,18 @@ func (pg *postgres) GetProductImageCount(db database.Querier, qf *models.QueryFi
 const productImageCreationQuery = `
     INSERT INTO product_images
         (
-            product_root_id, thumbnail_url, main_url, original_url, source_url
+            product_root_id, thumbnail_url, main_url, original_url, source_url, sort_order, renditions, content_hash, perceptual_hash
         )
     VALUES
         (
-            $1, $2, $3, $4, $5
+            $1, $2, $3, $4, $5, $6, $7, $8, $9
         )
     RETURNING
         id, created_on;
 `
 
 func (pg *postgres) CreateProductImage(db database.Querier, nu *models.ProductImage) (createdID uint64, createdOn time.Time, err error) {
-	err = db.QueryRow(productImageCreationQuery, &nu.ProductRootID, &nu.ThumbnailURL, &nu.MainURL, &nu.OriginalURL, &nu.SourceURL).Scan(&createdID, &createdOn)
+	err = db.QueryRow(productImageCreationQuery, &nu.ProductRootID, &nu.ThumbnailURL, &nu.MainURL, &nu.OriginalURL, &nu.SourceURL, &nu.SortOrder, &nu.Renditions, &nu.ContentHash, &nu.PerceptualHash).Scan(&createdID, &createdOn)
 	return createdID, createdOn, err
 }
 
@@ -216,14 +508,18 @@ const productImageUpdateQuery = `
         main_url = $3,
         original_url = $4,
         source_url = $5,
+        sort_order = $6,
+        renditions = $7,
+        content_hash = $8,
+        perceptual_hash = $9,
         updated_on = NOW()
-    WHERE id = $6
+    WHERE id = $10
     RETURNING updated_on;
 `
 
 func (pg *postgres) UpdateProductImage(db database.Querier, updated *models.ProductImage) (time.Time, error) {
 	var t time.Time
-	err := db.QueryRow(productImageUpdateQuery, &updated.ProductRootID, &updated.ThumbnailURL, &updated.MainURL, &updated.OriginalURL, &updated.SourceURL, &updated.ID).Scan(&t)
+	err := db.QueryRow(productImageUpdateQuery, &updated.ProductRootID, &updated.ThumbnailURL, &updated.MainURL, &updated.OriginalURL, &updated.SourceURL, &updated.SortOrder, &updated.Renditions, &updated.ContentHash, &updated.PerceptualHash, &updated.ID).Scan(&t)
 	return t, err
 }
 
@@ -238,3 +534,16 @@ func (pg *postgres) DeleteProductImage(db database.Querier, id uint64) (t time.T
 	err = db.QueryRow(productImageDeletionQuery, id).Scan(&t)
 	return t, err
 }
+
+const productImagesWithProductRootIDDeletionQuery = `
+    UPDATE product_images
+    SET archived_on = NOW()
+    WHERE product_root_id = $1
+    AND archived_on IS NULL
+    RETURNING archived_on
+`
+
+func (pg *postgres) ArchiveProductImagesWithProductRootID(db database.Querier, id uint64) (t time.Time, err error) {
+	err = db.QueryRow(productImagesWithProductRootIDDeletionQuery, id).Scan(&t)
+	return t, err
+}
@@ -42,6 +42,50 @@ func TestSetPrimaryProductImageForProduct(t *testing.T) {
 	})
 }
 
+func TestClearPrimaryProductImageForProduct(t *testing.T) {
+	t.Parallel()
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	exampleProductID := uint64(2)
+	client := NewPostgres()
+
+	t.Run("normal operation", func(*testing.T) {
+		query := formatQueryForSQLMock(clearProductPrimaryImageQuery)
+		exampleRows := sqlmock.NewRows([]string{"updated_on"}).AddRow(buildTestTime(t))
+		mock.ExpectQuery(query).WithArgs(exampleProductID).WillReturnRows(exampleRows)
+
+		expected := buildTestTime(t)
+		actual, err := client.ClearPrimaryProductImageForProduct(mockDB, exampleProductID)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, actual)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestArchiveProductImagesWithProductRootID(t *testing.T) {
+	t.Parallel()
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	exampleProductRootID := uint64(1)
+	client := NewPostgres()
+
+	t.Run("normal operation", func(*testing.T) {
+		query := formatQueryForSQLMock(productImagesWithProductRootIDDeletionQuery)
+		exampleRows := sqlmock.NewRows([]string{"archived_on"}).AddRow(buildTestTime(t))
+		mock.ExpectQuery(query).WithArgs(exampleProductRootID).WillReturnRows(exampleRows)
+
+		expected := buildTestTime(t)
+		actual, err := client.ArchiveProductImagesWithProductRootID(mockDB, exampleProductRootID)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, actual)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
 func setProductImageByProductIDQueryExpectation(t *testing.T, mock sqlmock.Sqlmock, id uint64, example *models.ProductImage, rowErr error, err error) {
 	t.Helper()
 
@@ -55,6 +99,10 @@ func setProductImageByProductIDQueryExpectation(t *testing.T, mock sqlmock.Sqlmo
 		"created_on",
 		"updated_on",
 		"archived_on",
+		"sort_order",
+		"renditions",
+		"content_hash",
+		"perceptual_hash",
 	}).AddRow(
 		example.ID,
 		example.ProductRootID,
@@ -65,6 +113,10 @@ func setProductImageByProductIDQueryExpectation(t *testing.T, mock sqlmock.Sqlmo
 		example.CreatedOn,
 		example.UpdatedOn,
 		example.ArchivedOn,
+		example.SortOrder,
+		[]byte(`{}`),
+		example.ContentHash,
+		example.PerceptualHash,
 	).AddRow(
 		example.ID,
 		example.ProductRootID,
@@ -75,6 +127,10 @@ func setProductImageByProductIDQueryExpectation(t *testing.T, mock sqlmock.Sqlmo
 		example.CreatedOn,
 		example.UpdatedOn,
 		example.ArchivedOn,
+		example.SortOrder,
+		[]byte(`{}`),
+		example.ContentHash,
+		example.PerceptualHash,
 	).AddRow(
 		example.ID,
 		example.ProductRootID,
@@ -85,6 +141,10 @@ func setProductImageByProductIDQueryExpectation(t *testing.T, mock sqlmock.Sqlmo
 		example.CreatedOn,
 		example.UpdatedOn,
 		example.ArchivedOn,
+		example.SortOrder,
+		[]byte(`{}`),
+		example.ContentHash,
+		example.PerceptualHash,
 	).RowError(1, rowErr)
 
 	query := formatQueryForSQLMock(productImageQueryByProductID)
@@ -144,6 +204,449 @@ func TestGetProductImagesByProductID(t *testing.T) {
 	})
 }
 
+func setProductImageByProductRootIDQueryExpectation(t *testing.T, mock sqlmock.Sqlmock, id uint64, example *models.ProductImage, rowErr error, err error) {
+	t.Helper()
+
+	exampleRows := sqlmock.NewRows([]string{
+		"id",
+		"product_root_id",
+		"thumbnail_url",
+		"main_url",
+		"original_url",
+		"source_url",
+		"created_on",
+		"updated_on",
+		"archived_on",
+		"sort_order",
+		"renditions",
+		"content_hash",
+		"perceptual_hash",
+	}).AddRow(
+		example.ID,
+		example.ProductRootID,
+		example.ThumbnailURL,
+		example.MainURL,
+		example.OriginalURL,
+		example.SourceURL,
+		example.CreatedOn,
+		example.UpdatedOn,
+		example.ArchivedOn,
+		example.SortOrder,
+		[]byte(`{}`),
+		example.ContentHash,
+		example.PerceptualHash,
+	).AddRow(
+		example.ID,
+		example.ProductRootID,
+		example.ThumbnailURL,
+		example.MainURL,
+		example.OriginalURL,
+		example.SourceURL,
+		example.CreatedOn,
+		example.UpdatedOn,
+		example.ArchivedOn,
+		example.SortOrder,
+		[]byte(`{}`),
+		example.ContentHash,
+		example.PerceptualHash,
+	).AddRow(
+		example.ID,
+		example.ProductRootID,
+		example.ThumbnailURL,
+		example.MainURL,
+		example.OriginalURL,
+		example.SourceURL,
+		example.CreatedOn,
+		example.UpdatedOn,
+		example.ArchivedOn,
+		example.SortOrder,
+		[]byte(`{}`),
+		example.ContentHash,
+		example.PerceptualHash,
+	).RowError(1, rowErr)
+
+	query := formatQueryForSQLMock(productImageQueryByProductRootID)
+	mock.ExpectQuery(query).
+		WithArgs(id).
+		WillReturnRows(exampleRows).
+		WillReturnError(err)
+}
+
+func TestGetProductImagesByProductRootID(t *testing.T) {
+	t.Parallel()
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+	exampleID := uint64(1)
+	example := &models.ProductImage{ID: exampleID}
+	client := NewPostgres()
+
+	t.Run("optimal behavior", func(t *testing.T) {
+		setProductImageByProductRootIDQueryExpectation(t, mock, exampleID, example, nil, nil)
+		actual, err := client.GetProductImagesByProductRootID(mockDB, exampleID)
+
+		assert.NoError(t, err)
+		assert.NotEmpty(t, actual, "list retrieval method should not return an empty slice")
+		assert.Nil(t, mock.ExpectationsWereMet(), "not all database expectations were met")
+	})
+
+	t.Run("with error executing query", func(t *testing.T) {
+		setProductImageByProductRootIDQueryExpectation(t, mock, exampleID, example, nil, errors.New("pineapple on pizza"))
+		actual, err := client.GetProductImagesByProductRootID(mockDB, exampleID)
+
+		assert.NotNil(t, err)
+		assert.Nil(t, actual)
+		assert.Nil(t, mock.ExpectationsWereMet(), "not all database expectations were met")
+	})
+
+	t.Run("with error scanning values", func(t *testing.T) {
+		exampleRows := sqlmock.NewRows([]string{"things"}).AddRow("stuff")
+		query := formatQueryForSQLMock(productImageQueryByProductRootID)
+		mock.ExpectQuery(query).
+			WillReturnRows(exampleRows)
+
+		actual, err := client.GetProductImagesByProductRootID(mockDB, exampleID)
+
+		assert.NotNil(t, err)
+		assert.Nil(t, actual)
+		assert.Nil(t, mock.ExpectationsWereMet(), "not all database expectations were met")
+	})
+
+	t.Run("with with row errors", func(t *testing.T) {
+		setProductImageByProductRootIDQueryExpectation(t, mock, exampleID, example, errors.New("pineapple on pizza"), nil)
+		actual, err := client.GetProductImagesByProductRootID(mockDB, exampleID)
+
+		assert.NotNil(t, err)
+		assert.Nil(t, actual)
+		assert.Nil(t, mock.ExpectationsWereMet(), "not all database expectations were met")
+	})
+}
+
+func setProductImageByContentHashQueryExpectation(t *testing.T, mock sqlmock.Sqlmock, contentHash string, example *models.ProductImage, rowErr error, err error) {
+	t.Helper()
+
+	exampleRows := sqlmock.NewRows([]string{
+		"id",
+		"product_root_id",
+		"thumbnail_url",
+		"main_url",
+		"original_url",
+		"source_url",
+		"created_on",
+		"updated_on",
+		"archived_on",
+		"sort_order",
+		"renditions",
+		"content_hash",
+		"perceptual_hash",
+	}).AddRow(
+		example.ID,
+		example.ProductRootID,
+		example.ThumbnailURL,
+		example.MainURL,
+		example.OriginalURL,
+		example.SourceURL,
+		example.CreatedOn,
+		example.UpdatedOn,
+		example.ArchivedOn,
+		example.SortOrder,
+		[]byte(`{}`),
+		example.ContentHash,
+		example.PerceptualHash,
+	).AddRow(
+		example.ID,
+		example.ProductRootID,
+		example.ThumbnailURL,
+		example.MainURL,
+		example.OriginalURL,
+		example.SourceURL,
+		example.CreatedOn,
+		example.UpdatedOn,
+		example.ArchivedOn,
+		example.SortOrder,
+		[]byte(`{}`),
+		example.ContentHash,
+		example.PerceptualHash,
+	).AddRow(
+		example.ID,
+		example.ProductRootID,
+		example.ThumbnailURL,
+		example.MainURL,
+		example.OriginalURL,
+		example.SourceURL,
+		example.CreatedOn,
+		example.UpdatedOn,
+		example.ArchivedOn,
+		example.SortOrder,
+		[]byte(`{}`),
+		example.ContentHash,
+		example.PerceptualHash,
+	).RowError(1, rowErr)
+
+	query := formatQueryForSQLMock(productImageQueryByContentHash)
+	mock.ExpectQuery(query).
+		WithArgs(contentHash).
+		WillReturnRows(exampleRows).
+		WillReturnError(err)
+}
+
+func TestGetProductImagesByContentHash(t *testing.T) {
+	t.Parallel()
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+	exampleHash := "deadbeef"
+	example := &models.ProductImage{ID: 1, ContentHash: exampleHash}
+	client := NewPostgres()
+
+	t.Run("optimal behavior", func(t *testing.T) {
+		setProductImageByContentHashQueryExpectation(t, mock, exampleHash, example, nil, nil)
+		actual, err := client.GetProductImagesByContentHash(mockDB, exampleHash)
+
+		assert.NoError(t, err)
+		assert.NotEmpty(t, actual, "list retrieval method should not return an empty slice")
+		assert.Nil(t, mock.ExpectationsWereMet(), "not all database expectations were met")
+	})
+
+	t.Run("with error executing query", func(t *testing.T) {
+		setProductImageByContentHashQueryExpectation(t, mock, exampleHash, example, nil, errors.New("pineapple on pizza"))
+		actual, err := client.GetProductImagesByContentHash(mockDB, exampleHash)
+
+		assert.NotNil(t, err)
+		assert.Nil(t, actual)
+		assert.Nil(t, mock.ExpectationsWereMet(), "not all database expectations were met")
+	})
+
+	t.Run("with error scanning values", func(t *testing.T) {
+		exampleRows := sqlmock.NewRows([]string{"things"}).AddRow("stuff")
+		query := formatQueryForSQLMock(productImageQueryByContentHash)
+		mock.ExpectQuery(query).
+			WillReturnRows(exampleRows)
+
+		actual, err := client.GetProductImagesByContentHash(mockDB, exampleHash)
+
+		assert.NotNil(t, err)
+		assert.Nil(t, actual)
+		assert.Nil(t, mock.ExpectationsWereMet(), "not all database expectations were met")
+	})
+
+	t.Run("with with row errors", func(t *testing.T) {
+		setProductImageByContentHashQueryExpectation(t, mock, exampleHash, example, errors.New("pineapple on pizza"), nil)
+		actual, err := client.GetProductImagesByContentHash(mockDB, exampleHash)
+
+		assert.NotNil(t, err)
+		assert.Nil(t, actual)
+		assert.Nil(t, mock.ExpectationsWereMet(), "not all database expectations were met")
+	})
+}
+
+func setHashedProductImagesQueryExpectation(t *testing.T, mock sqlmock.Sqlmock, example *models.ProductImage, rowErr error, err error) {
+	t.Helper()
+
+	exampleRows := sqlmock.NewRows([]string{
+		"id",
+		"product_root_id",
+		"thumbnail_url",
+		"main_url",
+		"original_url",
+		"source_url",
+		"created_on",
+		"updated_on",
+		"archived_on",
+		"sort_order",
+		"renditions",
+		"content_hash",
+		"perceptual_hash",
+	}).AddRow(
+		example.ID,
+		example.ProductRootID,
+		example.ThumbnailURL,
+		example.MainURL,
+		example.OriginalURL,
+		example.SourceURL,
+		example.CreatedOn,
+		example.UpdatedOn,
+		example.ArchivedOn,
+		example.SortOrder,
+		[]byte(`{}`),
+		example.ContentHash,
+		example.PerceptualHash,
+	).AddRow(
+		example.ID,
+		example.ProductRootID,
+		example.ThumbnailURL,
+		example.MainURL,
+		example.OriginalURL,
+		example.SourceURL,
+		example.CreatedOn,
+		example.UpdatedOn,
+		example.ArchivedOn,
+		example.SortOrder,
+		[]byte(`{}`),
+		example.ContentHash,
+		example.PerceptualHash,
+	).AddRow(
+		example.ID,
+		example.ProductRootID,
+		example.ThumbnailURL,
+		example.MainURL,
+		example.OriginalURL,
+		example.SourceURL,
+		example.CreatedOn,
+		example.UpdatedOn,
+		example.ArchivedOn,
+		example.SortOrder,
+		[]byte(`{}`),
+		example.ContentHash,
+		example.PerceptualHash,
+	).RowError(1, rowErr)
+
+	query := formatQueryForSQLMock(hashedProductImagesQuery)
+	mock.ExpectQuery(query).
+		WillReturnRows(exampleRows).
+		WillReturnError(err)
+}
+
+func TestGetHashedProductImages(t *testing.T) {
+	t.Parallel()
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+	example := &models.ProductImage{ID: 1, PerceptualHash: "00ff00ff00ff00ff"}
+	client := NewPostgres()
+
+	t.Run("optimal behavior", func(t *testing.T) {
+		setHashedProductImagesQueryExpectation(t, mock, example, nil, nil)
+		actual, err := client.GetHashedProductImages(mockDB)
+
+		assert.NoError(t, err)
+		assert.NotEmpty(t, actual, "list retrieval method should not return an empty slice")
+		assert.Nil(t, mock.ExpectationsWereMet(), "not all database expectations were met")
+	})
+
+	t.Run("with error executing query", func(t *testing.T) {
+		setHashedProductImagesQueryExpectation(t, mock, example, nil, errors.New("pineapple on pizza"))
+		actual, err := client.GetHashedProductImages(mockDB)
+
+		assert.NotNil(t, err)
+		assert.Nil(t, actual)
+		assert.Nil(t, mock.ExpectationsWereMet(), "not all database expectations were met")
+	})
+
+	t.Run("with error scanning values", func(t *testing.T) {
+		exampleRows := sqlmock.NewRows([]string{"things"}).AddRow("stuff")
+		query := formatQueryForSQLMock(hashedProductImagesQuery)
+		mock.ExpectQuery(query).
+			WillReturnRows(exampleRows)
+
+		actual, err := client.GetHashedProductImages(mockDB)
+
+		assert.NotNil(t, err)
+		assert.Nil(t, actual)
+		assert.Nil(t, mock.ExpectationsWereMet(), "not all database expectations were met")
+	})
+
+	t.Run("with with row errors", func(t *testing.T) {
+		setHashedProductImagesQueryExpectation(t, mock, example, errors.New("pineapple on pizza"), nil)
+		actual, err := client.GetHashedProductImages(mockDB)
+
+		assert.NotNil(t, err)
+		assert.Nil(t, actual)
+		assert.Nil(t, mock.ExpectationsWereMet(), "not all database expectations were met")
+	})
+}
+
+func setProductImagesAfterIDQueryExpectation(t *testing.T, mock sqlmock.Sqlmock, afterID uint64, limit uint, example *models.ProductImage, rowErr error, err error) {
+	t.Helper()
+
+	exampleRows := sqlmock.NewRows([]string{
+		"id",
+		"product_root_id",
+		"thumbnail_url",
+		"main_url",
+		"original_url",
+		"source_url",
+		"created_on",
+		"updated_on",
+		"archived_on",
+		"sort_order",
+		"renditions",
+		"content_hash",
+		"perceptual_hash",
+	}).AddRow(
+		example.ID,
+		example.ProductRootID,
+		example.ThumbnailURL,
+		example.MainURL,
+		example.OriginalURL,
+		example.SourceURL,
+		example.CreatedOn,
+		example.UpdatedOn,
+		example.ArchivedOn,
+		example.SortOrder,
+		[]byte(`{}`),
+		example.ContentHash,
+		example.PerceptualHash,
+	).AddRow(
+		example.ID+1,
+		example.ProductRootID,
+		example.ThumbnailURL,
+		example.MainURL,
+		example.OriginalURL,
+		example.SourceURL,
+		example.CreatedOn,
+		example.UpdatedOn,
+		example.ArchivedOn,
+		example.SortOrder,
+		[]byte(`{}`),
+		example.ContentHash,
+		example.PerceptualHash,
+	).RowError(1, rowErr)
+
+	query := formatQueryForSQLMock(productImagesAfterIDQuery)
+	mock.ExpectQuery(query).
+		WithArgs(afterID, limit).
+		WillReturnRows(exampleRows).
+		WillReturnError(err)
+}
+
+func TestGetProductImagesAfterID(t *testing.T) {
+	t.Parallel()
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+	example := &models.ProductImage{ID: 11}
+	exampleAfterID, exampleLimit := uint64(10), uint(50)
+	client := NewPostgres()
+
+	t.Run("optimal behavior", func(t *testing.T) {
+		setProductImagesAfterIDQueryExpectation(t, mock, exampleAfterID, exampleLimit, example, nil, nil)
+		actual, err := client.GetProductImagesAfterID(mockDB, exampleAfterID, exampleLimit)
+
+		assert.NoError(t, err)
+		assert.Len(t, actual, 2)
+		assert.Nil(t, mock.ExpectationsWereMet(), "not all database expectations were met")
+	})
+
+	t.Run("with error executing query", func(t *testing.T) {
+		setProductImagesAfterIDQueryExpectation(t, mock, exampleAfterID, exampleLimit, example, nil, errors.New("pineapple on pizza"))
+		actual, err := client.GetProductImagesAfterID(mockDB, exampleAfterID, exampleLimit)
+
+		assert.NotNil(t, err)
+		assert.Nil(t, actual)
+		assert.Nil(t, mock.ExpectationsWereMet(), "not all database expectations were met")
+	})
+
+	t.Run("with with row errors", func(t *testing.T) {
+		setProductImagesAfterIDQueryExpectation(t, mock, exampleAfterID, exampleLimit, example, errors.New("pineapple on pizza"), nil)
+		actual, err := client.GetProductImagesAfterID(mockDB, exampleAfterID, exampleLimit)
+
+		assert.NotNil(t, err)
+		assert.Nil(t, actual)
+		assert.Nil(t, mock.ExpectationsWereMet(), "not all database expectations were met")
+	})
+}
+
 func setProductImageExistenceQueryExpectation(t *testing.T, mock sqlmock.Sqlmock, id uint64, shouldExist bool, err error) {
 	t.Helper()
 	query := formatQueryForSQLMock(productImageExistenceQuery)
@@ -204,6 +707,10 @@ func setProductImageReadQueryExpectation(t *testing.T, mock sqlmock.Sqlmock, id
 		"created_on",
 		"updated_on",
 		"archived_on",
+		"sort_order",
+		"renditions",
+		"content_hash",
+		"perceptual_hash",
 	}).AddRow(
 		toReturn.ID,
 		toReturn.ProductRootID,
@@ -214,6 +721,10 @@ func setProductImageReadQueryExpectation(t *testing.T, mock sqlmock.Sqlmock, id
 		toReturn.CreatedOn,
 		toReturn.UpdatedOn,
 		toReturn.ArchivedOn,
+		toReturn.SortOrder,
+		[]byte(`{}`),
+		toReturn.ContentHash,
+		toReturn.PerceptualHash,
 	)
 	mock.ExpectQuery(query).WithArgs(id).WillReturnRows(exampleRows).WillReturnError(err)
 }
@@ -224,7 +735,7 @@ func TestGetProductImage(t *testing.T) {
 	assert.NoError(t, err)
 	defer mockDB.Close()
 	exampleID := uint64(1)
-	expected := &models.ProductImage{ID: exampleID}
+	expected := &models.ProductImage{ID: exampleID, Renditions: models.RenditionURLs{}}
 	client := NewPostgres()
 
 	t.Run("optimal behavior", func(t *testing.T) {
@@ -248,6 +759,10 @@ func setProductImageListReadQueryExpectation(t *testing.T, mock sqlmock.Sqlmock,
 		"created_on",
 		"updated_on",
 		"archived_on",
+		"sort_order",
+		"renditions",
+		"content_hash",
+		"perceptual_hash",
 	}).AddRow(
 		example.ID,
 		example.ProductRootID,
@@ -258,6 +773,10 @@ func setProductImageListReadQueryExpectation(t *testing.T, mock sqlmock.Sqlmock,
 		example.CreatedOn,
 		example.UpdatedOn,
 		example.ArchivedOn,
+		example.SortOrder,
+		[]byte(`{}`),
+		example.ContentHash,
+		example.PerceptualHash,
 	).AddRow(
 		example.ID,
 		example.ProductRootID,
@@ -268,6 +787,10 @@ func setProductImageListReadQueryExpectation(t *testing.T, mock sqlmock.Sqlmock,
 		example.CreatedOn,
 		example.UpdatedOn,
 		example.ArchivedOn,
+		example.SortOrder,
+		[]byte(`{}`),
+		example.ContentHash,
+		example.PerceptualHash,
 	).AddRow(
 		example.ID,
 		example.ProductRootID,
@@ -278,6 +801,10 @@ func setProductImageListReadQueryExpectation(t *testing.T, mock sqlmock.Sqlmock,
 		example.CreatedOn,
 		example.UpdatedOn,
 		example.ArchivedOn,
+		example.SortOrder,
+		[]byte(`{}`),
+		example.ContentHash,
+		example.PerceptualHash,
 	).RowError(1, rowErr)
 
 	query, _ := buildProductImageListRetrievalQuery(qf)
@@ -402,6 +929,10 @@ func setProductImageCreationQueryExpectation(t *testing.T, mock sqlmock.Sqlmock,
 			toCreate.MainURL,
 			toCreate.OriginalURL,
 			toCreate.SourceURL,
+			toCreate.SortOrder,
+			toCreate.Renditions,
+			toCreate.ContentHash,
+			toCreate.PerceptualHash,
 		).
 		WillReturnRows(exampleRows).
 		WillReturnError(err)
@@ -441,6 +972,10 @@ func setProductImageUpdateQueryExpectation(t *testing.T, mock sqlmock.Sqlmock, t
 			toUpdate.MainURL,
 			toUpdate.OriginalURL,
 			toUpdate.SourceURL,
+			toUpdate.SortOrder,
+			toUpdate.Renditions,
+			toUpdate.ContentHash,
+			toUpdate.PerceptualHash,
 			toUpdate.ID,
 		).
 		WillReturnRows(exampleRows).
@@ -44,7 +44,8 @@ const productOptionValueRetrievalQueryByOptionID = `
         value,
         created_on,
         updated_on,
-        archived_on
+        archived_on,
+        abbreviation
     FROM
         product_option_values
     WHERE
@@ -70,6 +71,7 @@ func (pg *postgres) GetProductOptionValuesForOption(db database.Querier, optionI
 			&p.CreatedOn,
 			&p.UpdatedOn,
 			&p.ArchivedOn,
+			&p.Abbreviation,
 		)
 		if err != nil {
 			return nil, err
@@ -106,7 +108,8 @@ const productOptionValueSelectionQuery = `
         value,
         created_on,
         updated_on,
-        archived_on
+        archived_on,
+        abbreviation
     FROM
         product_option_values
     WHERE
@@ -118,7 +121,7 @@ const productOptionValueSelectionQuery = `
 func (pg *postgres) GetProductOptionValue(db database.Querier, id uint64) (*models.ProductOptionValue, error) {
 	p := &models.ProductOptionValue{}
 
-	err := db.QueryRow(productOptionValueSelectionQuery, id).Scan(&p.ID, &p.ProductOptionID, &p.Value, &p.CreatedOn, &p.UpdatedOn, &p.ArchivedOn)
+	err := db.QueryRow(productOptionValueSelectionQuery, id).Scan(&p.ID, &p.ProductOptionID, &p.Value, &p.CreatedOn, &p.UpdatedOn, &p.ArchivedOn, &p.Abbreviation)
 
 	return p, err
 }
@@ -133,6 +136,7 @@ func buildProductOptionValueListRetrievalQuery(qf *models.QueryFilter) (string,
 			"created_on",
 			"updated_on",
 			"archived_on",
+			"abbreviation",
 		).
 		From("product_option_values")
 
@@ -158,6 +162,7 @@ func (pg *postgres) GetProductOptionValueList(db database.Querier, qf *models.Qu
 			&p.CreatedOn,
 			&p.UpdatedOn,
 			&p.ArchivedOn,
+			&p.Abbreviation,
 		)
 		if err != nil {
 			return nil, err
@@ -191,18 +196,18 @@ func (pg *postgres) GetProductOptionValueCount(db database.Querier, qf *models.Q
 const productOptionValueCreationQuery = `
     INSERT INTO product_option_values
         (
-            product_option_id, value
+            product_option_id, value, abbreviation
         )
     VALUES
         (
-            $1, $2
+            $1, $2, $3
         )
     RETURNING
         id, created_on;
 `
 
 func (pg *postgres) CreateProductOptionValue(db database.Querier, nu *models.ProductOptionValue) (createdID uint64, createdOn time.Time, err error) {
-	err = db.QueryRow(productOptionValueCreationQuery, &nu.ProductOptionID, &nu.Value).Scan(&createdID, &createdOn)
+	err = db.QueryRow(productOptionValueCreationQuery, &nu.ProductOptionID, &nu.Value, &nu.Abbreviation).Scan(&createdID, &createdOn)
 	return createdID, createdOn, err
 }
 
@@ -211,14 +216,15 @@ const productOptionValueUpdateQuery = `
     SET
         product_option_id = $1,
         value = $2,
+        abbreviation = $3,
         updated_on = NOW()
-    WHERE id = $3
+    WHERE id = $4
     RETURNING updated_on;
 `
 
 func (pg *postgres) UpdateProductOptionValue(db database.Querier, updated *models.ProductOptionValue) (time.Time, error) {
 	var t time.Time
-	err := db.QueryRow(productOptionValueUpdateQuery, &updated.ProductOptionID, &updated.Value, &updated.ID).Scan(&t)
+	err := db.QueryRow(productOptionValueUpdateQuery, &updated.ProductOptionID, &updated.Value, &updated.Abbreviation, &updated.ID).Scan(&t)
 	return t, err
 }
 
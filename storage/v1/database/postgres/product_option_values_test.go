@@ -96,6 +96,7 @@ func setProductOptionValueForOptionIDReadQueryExpectation(t *testing.T, mock sql
 		"created_on",
 		"updated_on",
 		"archived_on",
+		"abbreviation",
 	}).AddRow(
 		example.ID,
 		example.ProductOptionID,
@@ -103,6 +104,7 @@ func setProductOptionValueForOptionIDReadQueryExpectation(t *testing.T, mock sql
 		example.CreatedOn,
 		example.UpdatedOn,
 		example.ArchivedOn,
+		example.Abbreviation,
 	).AddRow(
 		example.ID,
 		example.ProductOptionID,
@@ -110,6 +112,7 @@ func setProductOptionValueForOptionIDReadQueryExpectation(t *testing.T, mock sql
 		example.CreatedOn,
 		example.UpdatedOn,
 		example.ArchivedOn,
+		example.Abbreviation,
 	).AddRow(
 		example.ID,
 		example.ProductOptionID,
@@ -117,6 +120,7 @@ func setProductOptionValueForOptionIDReadQueryExpectation(t *testing.T, mock sql
 		example.CreatedOn,
 		example.UpdatedOn,
 		example.ArchivedOn,
+		example.Abbreviation,
 	).RowError(1, rowErr)
 
 	mock.ExpectQuery(formatQueryForSQLMock(productOptionValueRetrievalQueryByOptionID)).
@@ -230,6 +234,7 @@ func setProductOptionValueReadQueryExpectation(t *testing.T, mock sqlmock.Sqlmoc
 		"created_on",
 		"updated_on",
 		"archived_on",
+		"abbreviation",
 	}).AddRow(
 		toReturn.ID,
 		toReturn.ProductOptionID,
@@ -237,6 +242,7 @@ func setProductOptionValueReadQueryExpectation(t *testing.T, mock sqlmock.Sqlmoc
 		toReturn.CreatedOn,
 		toReturn.UpdatedOn,
 		toReturn.ArchivedOn,
+		toReturn.Abbreviation,
 	)
 	mock.ExpectQuery(query).WithArgs(id).WillReturnRows(exampleRows).WillReturnError(err)
 }
@@ -268,6 +274,7 @@ func setProductOptionValueListReadQueryExpectation(t *testing.T, mock sqlmock.Sq
 		"created_on",
 		"updated_on",
 		"archived_on",
+		"abbreviation",
 	}).AddRow(
 		example.ID,
 		example.ProductOptionID,
@@ -275,6 +282,7 @@ func setProductOptionValueListReadQueryExpectation(t *testing.T, mock sqlmock.Sq
 		example.CreatedOn,
 		example.UpdatedOn,
 		example.ArchivedOn,
+		example.Abbreviation,
 	).AddRow(
 		example.ID,
 		example.ProductOptionID,
@@ -282,6 +290,7 @@ func setProductOptionValueListReadQueryExpectation(t *testing.T, mock sqlmock.Sq
 		example.CreatedOn,
 		example.UpdatedOn,
 		example.ArchivedOn,
+		example.Abbreviation,
 	).AddRow(
 		example.ID,
 		example.ProductOptionID,
@@ -289,6 +298,7 @@ func setProductOptionValueListReadQueryExpectation(t *testing.T, mock sqlmock.Sq
 		example.CreatedOn,
 		example.UpdatedOn,
 		example.ArchivedOn,
+		example.Abbreviation,
 	).RowError(1, rowErr)
 
 	query, _ := buildProductOptionValueListRetrievalQuery(qf)
@@ -410,6 +420,7 @@ func setProductOptionValueCreationQueryExpectation(t *testing.T, mock sqlmock.Sq
 		WithArgs(
 			toCreate.ProductOptionID,
 			toCreate.Value,
+			toCreate.Abbreviation,
 		).
 		WillReturnRows(exampleRows).
 		WillReturnError(err)
@@ -446,6 +457,7 @@ func setProductOptionValueUpdateQueryExpectation(t *testing.T, mock sqlmock.Sqlm
 		WithArgs(
 			toUpdate.ProductOptionID,
 			toUpdate.Value,
+			toUpdate.Abbreviation,
 			toUpdate.ID,
 		).
 		WillReturnRows(exampleRows).
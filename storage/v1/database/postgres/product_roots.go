@@ -64,7 +64,9 @@ const productRootSelectionQuery = `
         available_on,
         created_on,
         updated_on,
-        archived_on
+        archived_on,
+        sku_template,
+        option_summary_template
     FROM
         product_roots
     WHERE
@@ -76,7 +78,7 @@ const productRootSelectionQuery = `
 func (pg *postgres) GetProductRoot(db database.Querier, id uint64) (*models.ProductRoot, error) {
 	p := &models.ProductRoot{}
 
-	err := db.QueryRow(productRootSelectionQuery, id).Scan(&p.ID, &p.Name, &p.PrimaryImageID, &p.Subtitle, &p.Description, &p.SKUPrefix, &p.Manufacturer, &p.Brand, &p.Taxable, &p.Cost, &p.ProductWeight, &p.ProductHeight, &p.ProductWidth, &p.ProductLength, &p.PackageWeight, &p.PackageHeight, &p.PackageWidth, &p.PackageLength, &p.QuantityPerPackage, &p.AvailableOn, &p.CreatedOn, &p.UpdatedOn, &p.ArchivedOn)
+	err := db.QueryRow(productRootSelectionQuery, id).Scan(&p.ID, &p.Name, &p.PrimaryImageID, &p.Subtitle, &p.Description, &p.SKUPrefix, &p.Manufacturer, &p.Brand, &p.Taxable, &p.Cost, &p.ProductWeight, &p.ProductHeight, &p.ProductWidth, &p.ProductLength, &p.PackageWeight, &p.PackageHeight, &p.PackageWidth, &p.PackageLength, &p.QuantityPerPackage, &p.AvailableOn, &p.CreatedOn, &p.UpdatedOn, &p.ArchivedOn, &p.SKUTemplate, &p.OptionSummaryTemplate)
 
 	return p, err
 }
@@ -108,6 +110,8 @@ func buildProductRootListRetrievalQuery(qf *models.QueryFilter) (string, []inter
 			"created_on",
 			"updated_on",
 			"archived_on",
+			"sku_template",
+			"option_summary_template",
 		).
 		From("product_roots")
 
@@ -150,6 +154,8 @@ func (pg *postgres) GetProductRootList(db database.Querier, qf *models.QueryFilt
 			&p.CreatedOn,
 			&p.UpdatedOn,
 			&p.ArchivedOn,
+			&p.SKUTemplate,
+			&p.OptionSummaryTemplate,
 		)
 		if err != nil {
 			return nil, err
@@ -183,18 +189,18 @@ func (pg *postgres) GetProductRootCount(db database.Querier, qf *models.QueryFil
 const productRootCreationQuery = `
     INSERT INTO product_roots
         (
-            name, primary_image_id, subtitle, description, sku_prefix, manufacturer, brand, taxable, cost, product_weight, product_height, product_width, product_length, package_weight, package_height, package_width, package_length, quantity_per_package, available_on
+            name, primary_image_id, subtitle, description, sku_prefix, manufacturer, brand, taxable, cost, product_weight, product_height, product_width, product_length, package_weight, package_height, package_width, package_length, quantity_per_package, available_on, sku_template, option_summary_template
         )
     VALUES
         (
-            $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19
+            $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21
         )
     RETURNING
         id, created_on;
 `
 
 func (pg *postgres) CreateProductRoot(db database.Querier, nu *models.ProductRoot) (createdID uint64, createdOn time.Time, err error) {
-	err = db.QueryRow(productRootCreationQuery, &nu.Name, &nu.PrimaryImageID, &nu.Subtitle, &nu.Description, &nu.SKUPrefix, &nu.Manufacturer, &nu.Brand, &nu.Taxable, &nu.Cost, &nu.ProductWeight, &nu.ProductHeight, &nu.ProductWidth, &nu.ProductLength, &nu.PackageWeight, &nu.PackageHeight, &nu.PackageWidth, &nu.PackageLength, &nu.QuantityPerPackage, &nu.AvailableOn).Scan(&createdID, &createdOn)
+	err = db.QueryRow(productRootCreationQuery, &nu.Name, &nu.PrimaryImageID, &nu.Subtitle, &nu.Description, &nu.SKUPrefix, &nu.Manufacturer, &nu.Brand, &nu.Taxable, &nu.Cost, &nu.ProductWeight, &nu.ProductHeight, &nu.ProductWidth, &nu.ProductLength, &nu.PackageWeight, &nu.PackageHeight, &nu.PackageWidth, &nu.PackageLength, &nu.QuantityPerPackage, &nu.AvailableOn, &nu.SKUTemplate, &nu.OptionSummaryTemplate).Scan(&createdID, &createdOn)
 	return createdID, createdOn, err
 }
 
@@ -220,14 +226,16 @@ const productRootUpdateQuery = `
         package_length = $17,
         quantity_per_package = $18,
         available_on = $19,
+        sku_template = $20,
+        option_summary_template = $21,
         updated_on = NOW()
-    WHERE id = $20
+    WHERE id = $22
     RETURNING updated_on;
 `
 
 func (pg *postgres) UpdateProductRoot(db database.Querier, updated *models.ProductRoot) (time.Time, error) {
 	var t time.Time
-	err := db.QueryRow(productRootUpdateQuery, &updated.Name, &updated.PrimaryImageID, &updated.Subtitle, &updated.Description, &updated.SKUPrefix, &updated.Manufacturer, &updated.Brand, &updated.Taxable, &updated.Cost, &updated.ProductWeight, &updated.ProductHeight, &updated.ProductWidth, &updated.ProductLength, &updated.PackageWeight, &updated.PackageHeight, &updated.PackageWidth, &updated.PackageLength, &updated.QuantityPerPackage, &updated.AvailableOn, &updated.ID).Scan(&t)
+	err := db.QueryRow(productRootUpdateQuery, &updated.Name, &updated.PrimaryImageID, &updated.Subtitle, &updated.Description, &updated.SKUPrefix, &updated.Manufacturer, &updated.Brand, &updated.Taxable, &updated.Cost, &updated.ProductWeight, &updated.ProductHeight, &updated.ProductWidth, &updated.ProductLength, &updated.PackageWeight, &updated.PackageHeight, &updated.PackageWidth, &updated.PackageLength, &updated.QuantityPerPackage, &updated.AvailableOn, &updated.SKUTemplate, &updated.OptionSummaryTemplate, &updated.ID).Scan(&t)
 	return t, err
 }
 
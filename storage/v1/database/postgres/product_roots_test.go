@@ -135,6 +135,8 @@ func setProductRootReadQueryExpectation(t *testing.T, mock sqlmock.Sqlmock, id u
 		"created_on",
 		"updated_on",
 		"archived_on",
+		"sku_template",
+		"option_summary_template",
 	}).AddRow(
 		toReturn.ID,
 		toReturn.Name,
@@ -159,6 +161,8 @@ func setProductRootReadQueryExpectation(t *testing.T, mock sqlmock.Sqlmock, id u
 		toReturn.CreatedOn,
 		toReturn.UpdatedOn,
 		toReturn.ArchivedOn,
+		toReturn.SKUTemplate,
+		toReturn.OptionSummaryTemplate,
 	)
 	mock.ExpectQuery(query).WithArgs(id).WillReturnRows(exampleRows).WillReturnError(err)
 }
@@ -207,6 +211,8 @@ func setProductRootListReadQueryExpectation(t *testing.T, mock sqlmock.Sqlmock,
 		"created_on",
 		"updated_on",
 		"archived_on",
+		"sku_template",
+		"option_summary_template",
 	}).AddRow(
 		example.ID,
 		example.Name,
@@ -231,6 +237,8 @@ func setProductRootListReadQueryExpectation(t *testing.T, mock sqlmock.Sqlmock,
 		example.CreatedOn,
 		example.UpdatedOn,
 		example.ArchivedOn,
+		example.SKUTemplate,
+		example.OptionSummaryTemplate,
 	).AddRow(
 		example.ID,
 		example.Name,
@@ -255,6 +263,8 @@ func setProductRootListReadQueryExpectation(t *testing.T, mock sqlmock.Sqlmock,
 		example.CreatedOn,
 		example.UpdatedOn,
 		example.ArchivedOn,
+		example.SKUTemplate,
+		example.OptionSummaryTemplate,
 	).AddRow(
 		example.ID,
 		example.Name,
@@ -279,6 +289,8 @@ func setProductRootListReadQueryExpectation(t *testing.T, mock sqlmock.Sqlmock,
 		example.CreatedOn,
 		example.UpdatedOn,
 		example.ArchivedOn,
+		example.SKUTemplate,
+		example.OptionSummaryTemplate,
 	).RowError(1, rowErr)
 
 	query, _ := buildProductRootListRetrievalQuery(qf)
@@ -417,6 +429,8 @@ func setProductRootCreationQueryExpectation(t *testing.T, mock sqlmock.Sqlmock,
 			toCreate.PackageLength,
 			toCreate.QuantityPerPackage,
 			toCreate.AvailableOn,
+			toCreate.SKUTemplate,
+			toCreate.OptionSummaryTemplate,
 		).
 		WillReturnRows(exampleRows).
 		WillReturnError(err)
@@ -470,6 +484,8 @@ func setProductRootUpdateQueryExpectation(t *testing.T, mock sqlmock.Sqlmock, to
 			toUpdate.PackageLength,
 			toUpdate.QuantityPerPackage,
 			toUpdate.AvailableOn,
+			toUpdate.SKUTemplate,
+			toUpdate.OptionSummaryTemplate,
 			toUpdate.ID,
 		).
 		WillReturnRows(exampleRows).
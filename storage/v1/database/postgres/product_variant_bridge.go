@@ -221,3 +221,37 @@ func (pg *postgres) DeleteProductVariantBridgeByProductID(db database.Querier, p
 	err = db.QueryRow(productVariantBridgeDeletionQueryByProductID, productID).Scan(&t)
 	return t, err
 }
+
+const productIDsByOptionValueIDQuery = `
+    SELECT
+        product_id
+    FROM
+        product_variant_bridge
+    WHERE
+        archived_on IS NULL
+    AND
+        product_option_value_id = $1
+`
+
+func (pg *postgres) GetProductIDsByOptionValueID(db database.Querier, optionValueID uint64) ([]uint64, error) {
+	var ids []uint64
+
+	rows, err := db.Query(productIDsByOptionValueIDQuery, optionValueID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id uint64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, err
+}
@@ -517,3 +517,53 @@ func TestDeleteProductVariantBridgeByProductID(t *testing.T) {
 		assert.Nil(t, mock.ExpectationsWereMet(), "not all database expectations were met")
 	})
 }
+
+func setProductIDsByOptionValueIDQueryExpectation(t *testing.T, mock sqlmock.Sqlmock, optionValueID uint64, rowErr error, err error) {
+	t.Helper()
+	exampleRows := sqlmock.NewRows([]string{"product_id"}).
+		AddRow(uint64(1)).
+		AddRow(uint64(2)).
+		AddRow(uint64(3)).
+		RowError(1, rowErr)
+
+	mock.ExpectQuery(formatQueryForSQLMock(productIDsByOptionValueIDQuery)).
+		WithArgs(optionValueID).
+		WillReturnRows(exampleRows).
+		WillReturnError(err)
+}
+
+func TestGetProductIDsByOptionValueID(t *testing.T) {
+	t.Parallel()
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+	exampleOptionValueID := uint64(1)
+	client := NewPostgres()
+
+	t.Run("optimal behavior", func(t *testing.T) {
+		setProductIDsByOptionValueIDQueryExpectation(t, mock, exampleOptionValueID, nil, nil)
+		actual, err := client.GetProductIDsByOptionValueID(mockDB, exampleOptionValueID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []uint64{1, 2, 3}, actual)
+		assert.Nil(t, mock.ExpectationsWereMet(), "not all database expectations were met")
+	})
+
+	t.Run("with error executing query", func(t *testing.T) {
+		setProductIDsByOptionValueIDQueryExpectation(t, mock, exampleOptionValueID, nil, errors.New("pineapple on pizza"))
+		actual, err := client.GetProductIDsByOptionValueID(mockDB, exampleOptionValueID)
+
+		assert.NotNil(t, err)
+		assert.Nil(t, actual)
+		assert.Nil(t, mock.ExpectationsWereMet(), "not all database expectations were met")
+	})
+
+	t.Run("with with row errors", func(t *testing.T) {
+		setProductIDsByOptionValueIDQueryExpectation(t, mock, exampleOptionValueID, errors.New("pineapple on pizza"), nil)
+		actual, err := client.GetProductIDsByOptionValueID(mockDB, exampleOptionValueID)
+
+		assert.NotNil(t, err)
+		assert.Nil(t, actual)
+		assert.Nil(t, mock.ExpectationsWereMet(), "not all database expectations were met")
+	})
+}
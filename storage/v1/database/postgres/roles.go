@@ -0,0 +1,190 @@
+package postgres
+
+import (
+	"time"
+
+	"github.com/dairycart/dairycart/models/v1"
+	"github.com/dairycart/dairycart/storage/v1/database"
+
+	"github.com/lib/pq"
+)
+
+const roleSelectionQuery = `
+    SELECT
+        r.id,
+        r.name,
+        r.description,
+        array_remove(array_agg(p.name ORDER BY p.name), NULL),
+        r.created_on,
+        r.updated_on,
+        r.archived_on
+    FROM
+        roles r
+    LEFT JOIN permissions p ON p.role_id = r.id
+    WHERE
+        r.archived_on is null
+    AND
+        r.id = $1
+    GROUP BY r.id
+`
+
+func (pg *postgres) GetRole(db database.Querier, id uint64) (*models.Role, error) {
+	r := &models.Role{}
+
+	err := db.QueryRow(roleSelectionQuery, id).Scan(&r.ID, &r.Name, &r.Description, pq.Array(&r.Permissions), &r.CreatedOn, &r.UpdatedOn, &r.ArchivedOn)
+
+	return r, err
+}
+
+const rolesQuery = `
+    SELECT
+        r.id,
+        r.name,
+        r.description,
+        array_remove(array_agg(p.name ORDER BY p.name), NULL),
+        r.created_on,
+        r.updated_on,
+        r.archived_on
+    FROM
+        roles r
+    LEFT JOIN permissions p ON p.role_id = r.id
+    WHERE
+        r.archived_on is null
+    GROUP BY r.id
+    ORDER BY r.id
+`
+
+const rolesByUserIDQuery = `
+    SELECT
+        r.id,
+        r.name,
+        r.description,
+        array_remove(array_agg(p.name ORDER BY p.name), NULL),
+        r.created_on,
+        r.updated_on,
+        r.archived_on
+    FROM
+        roles r
+    JOIN user_roles ur ON ur.role_id = r.id
+    LEFT JOIN permissions p ON p.role_id = r.id
+    WHERE
+        r.archived_on is null
+    AND
+        ur.user_id = $1
+    GROUP BY r.id
+    ORDER BY r.id
+`
+
+func scanRoles(db database.Querier, query string, args ...interface{}) ([]models.Role, error) {
+	var list []models.Role
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var r models.Role
+		err := rows.Scan(
+			&r.ID,
+			&r.Name,
+			&r.Description,
+			pq.Array(&r.Permissions),
+			&r.CreatedOn,
+			&r.UpdatedOn,
+			&r.ArchivedOn,
+		)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, r)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return list, err
+}
+
+// GetRoles returns every role, along with its permissions
+func (pg *postgres) GetRoles(db database.Querier) ([]models.Role, error) {
+	return scanRoles(db, rolesQuery)
+}
+
+// GetRolesByUserID returns the roles a user has been given
+func (pg *postgres) GetRolesByUserID(db database.Querier, userID uint64) ([]models.Role, error) {
+	return scanRoles(db, rolesByUserIDQuery, userID)
+}
+
+const permissionsByUserIDQuery = `
+    SELECT DISTINCT
+        p.name
+    FROM
+        permissions p
+    JOIN roles r ON r.id = p.role_id
+    JOIN user_roles ur ON ur.role_id = r.id
+    WHERE
+        r.archived_on is null
+    AND
+        ur.user_id = $1
+    ORDER BY p.name
+`
+
+// GetPermissionsByUserID returns the names of every permission a user has through their roles
+func (pg *postgres) GetPermissionsByUserID(db database.Querier, userID uint64) ([]string, error) {
+	var permissions []string
+
+	rows, err := db.Query(permissionsByUserIDQuery, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var p string
+		if err := rows.Scan(&p); err != nil {
+			return nil, err
+		}
+		permissions = append(permissions, p)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return permissions, err
+}
+
+// giving a user a role they already have leaves the original assignment alone
+const userRoleAssignmentQuery = `
+    INSERT INTO user_roles
+        (
+            user_id, role_id
+        )
+    VALUES
+        (
+            $1, $2
+        )
+    ON CONFLICT (user_id, role_id) DO UPDATE SET user_id = EXCLUDED.user_id
+    RETURNING
+        created_on
+`
+
+// AssignRoleToUser gives a user a role, and returns when they were given it
+func (pg *postgres) AssignRoleToUser(db database.Querier, userID uint64, roleID uint64) (createdOn time.Time, err error) {
+	err = db.QueryRow(userRoleAssignmentQuery, userID, roleID).Scan(&createdOn)
+	return createdOn, err
+}
+
+const userRoleRemovalQuery = `
+    DELETE FROM user_roles
+    WHERE user_id = $1
+    AND role_id = $2
+    RETURNING role_id
+`
+
+// RemoveRoleFromUser takes a role away from a user. It returns sql.ErrNoRows if they didn't have it.
+func (pg *postgres) RemoveRoleFromUser(db database.Querier, userID uint64, roleID uint64) error {
+	var removed uint64
+	return db.QueryRow(userRoleRemovalQuery, userID, roleID).Scan(&removed)
+}
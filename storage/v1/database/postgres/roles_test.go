@@ -0,0 +1,207 @@
+package postgres
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	// internal dependencies
+	"github.com/dairycart/dairycart/models/v1"
+
+	// external dependencies
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+var roleColumns = []string{
+	"id",
+	"name",
+	"description",
+	"permissions",
+	"created_on",
+	"updated_on",
+	"archived_on",
+}
+
+func roleRowValues(r *models.Role) []driver.Value {
+	return []driver.Value{
+		r.ID,
+		r.Name,
+		r.Description,
+		"{discounts:read,discounts:write}",
+		r.CreatedOn,
+		nil,
+		nil,
+	}
+}
+
+func TestGetRole(t *testing.T) {
+	t.Parallel()
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+	client := NewPostgres()
+	expected := &models.Role{ID: 1, Name: "marketing", Description: "Manages discounts", Permissions: []string{"discounts:read", "discounts:write"}}
+
+	t.Run("optimal behavior", func(t *testing.T) {
+		mock.ExpectQuery(formatQueryForSQLMock(roleSelectionQuery)).
+			WithArgs(expected.ID).
+			WillReturnRows(sqlmock.NewRows(roleColumns).AddRow(roleRowValues(expected)...))
+
+		actual, err := client.GetRole(mockDB, expected.ID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, expected, actual, "expected role did not match actual role")
+		assert.Nil(t, mock.ExpectationsWereMet(), "not all database expectations were met")
+	})
+}
+
+func TestGetRoles(t *testing.T) {
+	t.Parallel()
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+	client := NewPostgres()
+	example := &models.Role{ID: 1, Name: "marketing"}
+
+	t.Run("optimal behavior", func(t *testing.T) {
+		exampleRows := sqlmock.NewRows(roleColumns).
+			AddRow(roleRowValues(example)...).
+			AddRow(roleRowValues(example)...)
+		mock.ExpectQuery(formatQueryForSQLMock(rolesQuery)).
+			WillReturnRows(exampleRows)
+
+		actual, err := client.GetRoles(mockDB)
+
+		assert.NoError(t, err)
+		assert.Len(t, actual, 2)
+		assert.Nil(t, mock.ExpectationsWereMet(), "not all database expectations were met")
+	})
+
+	t.Run("with error executing query", func(t *testing.T) {
+		mock.ExpectQuery(formatQueryForSQLMock(rolesQuery)).
+			WillReturnError(errors.New("pineapple on pizza"))
+
+		actual, err := client.GetRoles(mockDB)
+
+		assert.NotNil(t, err)
+		assert.Nil(t, actual)
+		assert.Nil(t, mock.ExpectationsWereMet(), "not all database expectations were met")
+	})
+
+	t.Run("with error scanning values", func(t *testing.T) {
+		mock.ExpectQuery(formatQueryForSQLMock(rolesQuery)).
+			WillReturnRows(sqlmock.NewRows([]string{"things"}).AddRow("stuff"))
+
+		actual, err := client.GetRoles(mockDB)
+
+		assert.NotNil(t, err)
+		assert.Nil(t, actual)
+		assert.Nil(t, mock.ExpectationsWereMet(), "not all database expectations were met")
+	})
+}
+
+func TestGetRolesByUserID(t *testing.T) {
+	t.Parallel()
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+	client := NewPostgres()
+	example := &models.Role{ID: 1, Name: "marketing"}
+
+	t.Run("optimal behavior", func(t *testing.T) {
+		mock.ExpectQuery(formatQueryForSQLMock(rolesByUserIDQuery)).
+			WithArgs(uint64(2)).
+			WillReturnRows(sqlmock.NewRows(roleColumns).AddRow(roleRowValues(example)...))
+
+		actual, err := client.GetRolesByUserID(mockDB, 2)
+
+		assert.NoError(t, err)
+		assert.Len(t, actual, 1)
+		assert.Equal(t, []string{"discounts:read", "discounts:write"}, actual[0].Permissions)
+		assert.Nil(t, mock.ExpectationsWereMet(), "not all database expectations were met")
+	})
+}
+
+func TestGetPermissionsByUserID(t *testing.T) {
+	t.Parallel()
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+	client := NewPostgres()
+
+	t.Run("optimal behavior", func(t *testing.T) {
+		mock.ExpectQuery(formatQueryForSQLMock(permissionsByUserIDQuery)).
+			WithArgs(uint64(2)).
+			WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("discounts:read").AddRow("products:write"))
+
+		actual, err := client.GetPermissionsByUserID(mockDB, 2)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"discounts:read", "products:write"}, actual)
+		assert.Nil(t, mock.ExpectationsWereMet(), "not all database expectations were met")
+	})
+
+	t.Run("with error executing query", func(t *testing.T) {
+		mock.ExpectQuery(formatQueryForSQLMock(permissionsByUserIDQuery)).
+			WillReturnError(errors.New("pineapple on pizza"))
+
+		actual, err := client.GetPermissionsByUserID(mockDB, 2)
+
+		assert.NotNil(t, err)
+		assert.Nil(t, actual)
+		assert.Nil(t, mock.ExpectationsWereMet(), "not all database expectations were met")
+	})
+}
+
+func TestAssignRoleToUser(t *testing.T) {
+	t.Parallel()
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+	client := NewPostgres()
+
+	t.Run("optimal behavior", func(t *testing.T) {
+		expected := buildTestTime(t)
+		mock.ExpectQuery(formatQueryForSQLMock(userRoleAssignmentQuery)).
+			WithArgs(uint64(2), uint64(1)).
+			WillReturnRows(sqlmock.NewRows([]string{"created_on"}).AddRow(expected))
+
+		actual, err := client.AssignRoleToUser(mockDB, 2, 1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, expected, actual, "expected assignment time did not match actual assignment time")
+		assert.Nil(t, mock.ExpectationsWereMet(), "not all database expectations were met")
+	})
+}
+
+func TestRemoveRoleFromUser(t *testing.T) {
+	t.Parallel()
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+	client := NewPostgres()
+
+	t.Run("optimal behavior", func(t *testing.T) {
+		mock.ExpectQuery(formatQueryForSQLMock(userRoleRemovalQuery)).
+			WithArgs(uint64(2), uint64(1)).
+			WillReturnRows(sqlmock.NewRows([]string{"role_id"}).AddRow(uint64(1)))
+
+		err := client.RemoveRoleFromUser(mockDB, 2, 1)
+
+		assert.NoError(t, err)
+		assert.Nil(t, mock.ExpectationsWereMet(), "not all database expectations were met")
+	})
+
+	t.Run("without the role", func(t *testing.T) {
+		mock.ExpectQuery(formatQueryForSQLMock(userRoleRemovalQuery)).
+			WithArgs(uint64(2), uint64(1)).
+			WillReturnRows(sqlmock.NewRows([]string{"role_id"}))
+
+		err := client.RemoveRoleFromUser(mockDB, 2, 1)
+
+		assert.Equal(t, sql.ErrNoRows, err)
+		assert.Nil(t, mock.ExpectationsWereMet(), "not all database expectations were met")
+	})
+}
@@ -0,0 +1,253 @@
+package postgres
+
+import (
+	"time"
+
+	"github.com/dairycart/dairycart/models/v1"
+	"github.com/dairycart/dairycart/storage/v1/database"
+
+	"github.com/Masterminds/squirrel"
+)
+
+const webhookDeliverySelectionQuery = `
+    SELECT
+        id,
+        webhook_id,
+        event_type,
+        content_type,
+        payload,
+        status,
+        attempts,
+        next_attempt_on,
+        locked_until,
+        last_status_code,
+        last_error,
+        created_on,
+        updated_on,
+        delivered_on
+    FROM
+        webhook_deliveries
+    WHERE
+        id = $1
+`
+
+func (pg *postgres) GetWebhookDelivery(db database.Querier, id uint64) (*models.WebhookDelivery, error) {
+	w := &models.WebhookDelivery{}
+
+	err := db.QueryRow(webhookDeliverySelectionQuery, id).Scan(&w.ID, &w.WebhookID, &w.EventType, &w.ContentType, &w.Payload, &w.Status, &w.Attempts, &w.NextAttemptOn, &w.LockedUntil, &w.LastStatusCode, &w.LastError, &w.CreatedOn, &w.UpdatedOn, &w.DeliveredOn)
+
+	return w, err
+}
+
+// webhookDeliveryQueryFilter copies a query filter without the archived_on condition, since deliveries are never archived
+func webhookDeliveryQueryFilter(qf *models.QueryFilter) *models.QueryFilter {
+	if qf == nil {
+		return nil
+	}
+	out := *qf
+	out.IncludeArchived = true
+	return &out
+}
+
+func buildWebhookDeliveryListRetrievalQuery(qf *models.QueryFilter) (string, []interface{}) {
+	sqlBuilder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	queryBuilder := sqlBuilder.
+		Select(
+			"id",
+			"webhook_id",
+			"event_type",
+			"content_type",
+			"payload",
+			"status",
+			"attempts",
+			"next_attempt_on",
+			"locked_until",
+			"last_status_code",
+			"last_error",
+			"created_on",
+			"updated_on",
+			"delivered_on",
+		).
+		From("webhook_deliveries")
+
+	query, args, _ := applyQueryFilterToQueryBuilder(queryBuilder, webhookDeliveryQueryFilter(qf), true).ToSql()
+	return query, args
+}
+
+func (pg *postgres) GetWebhookDeliveryList(db database.Querier, qf *models.QueryFilter) ([]models.WebhookDelivery, error) {
+	var list []models.WebhookDelivery
+	query, args := buildWebhookDeliveryListRetrievalQuery(qf)
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var w models.WebhookDelivery
+		err := rows.Scan(
+			&w.ID,
+			&w.WebhookID,
+			&w.EventType,
+			&w.ContentType,
+			&w.Payload,
+			&w.Status,
+			&w.Attempts,
+			&w.NextAttemptOn,
+			&w.LockedUntil,
+			&w.LastStatusCode,
+			&w.LastError,
+			&w.CreatedOn,
+			&w.UpdatedOn,
+			&w.DeliveredOn,
+		)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, w)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return list, err
+}
+
+func buildWebhookDeliveryCountRetrievalQuery(qf *models.QueryFilter) (string, []interface{}) {
+	queryBuilder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar).
+		Select("count(id)").
+		From("webhook_deliveries")
+
+	query, args, _ := applyQueryFilterToQueryBuilder(queryBuilder, webhookDeliveryQueryFilter(qf), false).ToSql()
+	return query, args
+}
+
+func (pg *postgres) GetWebhookDeliveryCount(db database.Querier, qf *models.QueryFilter) (uint64, error) {
+	var count uint64
+	query, args := buildWebhookDeliveryCountRetrievalQuery(qf)
+	err := db.QueryRow(query, args...).Scan(&count)
+	return count, err
+}
+
+// webhookDeliveryClaimQuery leases the deliveries that are due to whoever asked for them. SKIP LOCKED
+// lets more than one server dispatch at once without handing out the same delivery twice, and the lease
+// means a delivery gets tried again if whoever claimed it dies before recording how it went. Deliveries to
+// webhooks that have been paused for failing are left where they are until the pause is over.
+const webhookDeliveryClaimQuery = `
+    UPDATE webhook_deliveries
+    SET
+        locked_until = NOW() + $2 * interval '1 millisecond'
+    WHERE id IN (
+        SELECT id
+        FROM webhook_deliveries
+        WHERE
+            status = 'pending'
+        AND
+            next_attempt_on <= NOW()
+        AND
+            (locked_until IS NULL OR locked_until <= NOW())
+        AND
+            NOT EXISTS (
+                SELECT 1 FROM webhooks
+                WHERE webhooks.id = webhook_deliveries.webhook_id
+                AND webhooks.paused_until > NOW()
+            )
+        ORDER BY next_attempt_on
+        LIMIT $1
+        FOR UPDATE SKIP LOCKED
+    )
+    RETURNING
+        id,
+        webhook_id,
+        event_type,
+        content_type,
+        payload,
+        status,
+        attempts,
+        next_attempt_on,
+        locked_until,
+        last_status_code,
+        last_error,
+        created_on,
+        updated_on,
+        delivered_on
+`
+
+func (pg *postgres) ClaimPendingWebhookDeliveries(db database.Querier, limit uint, lease time.Duration) ([]models.WebhookDelivery, error) {
+	var list []models.WebhookDelivery
+
+	rows, err := db.Query(webhookDeliveryClaimQuery, limit, int64(lease/time.Millisecond))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var w models.WebhookDelivery
+		err := rows.Scan(
+			&w.ID,
+			&w.WebhookID,
+			&w.EventType,
+			&w.ContentType,
+			&w.Payload,
+			&w.Status,
+			&w.Attempts,
+			&w.NextAttemptOn,
+			&w.LockedUntil,
+			&w.LastStatusCode,
+			&w.LastError,
+			&w.CreatedOn,
+			&w.UpdatedOn,
+			&w.DeliveredOn,
+		)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, w)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return list, err
+}
+
+const webhookDeliveryCreationQuery = `
+    INSERT INTO webhook_deliveries
+        (
+            webhook_id, event_type, content_type, payload
+        )
+    VALUES
+        (
+            $1, $2, $3, $4
+        )
+    RETURNING
+        id, created_on;
+`
+
+func (pg *postgres) CreateWebhookDelivery(db database.Querier, nu *models.WebhookDelivery) (createdID uint64, createdOn time.Time, err error) {
+	err = db.QueryRow(webhookDeliveryCreationQuery, &nu.WebhookID, &nu.EventType, &nu.ContentType, &nu.Payload).Scan(&createdID, &createdOn)
+	return createdID, createdOn, err
+}
+
+const webhookDeliveryUpdateQuery = `
+    UPDATE webhook_deliveries
+    SET
+        status = $1,
+        attempts = $2,
+        next_attempt_on = $3,
+        locked_until = $4,
+        last_status_code = $5,
+        last_error = $6,
+        delivered_on = $7,
+        updated_on = NOW()
+    WHERE id = $8
+    RETURNING updated_on;
+`
+
+func (pg *postgres) UpdateWebhookDelivery(db database.Querier, updated *models.WebhookDelivery) (time.Time, error) {
+	var t time.Time
+	err := db.QueryRow(webhookDeliveryUpdateQuery, &updated.Status, &updated.Attempts, &updated.NextAttemptOn, &updated.LockedUntil, &updated.LastStatusCode, &updated.LastError, &updated.DeliveredOn, &updated.ID).Scan(&t)
+	return t, err
+}
@@ -0,0 +1,290 @@
+package postgres
+
+import (
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	// internal dependencies
+	"github.com/dairycart/dairycart/models/v1"
+
+	// external dependencies
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+var webhookDeliveryColumns = []string{
+	"id",
+	"webhook_id",
+	"event_type",
+	"content_type",
+	"payload",
+	"status",
+	"attempts",
+	"next_attempt_on",
+	"locked_until",
+	"last_status_code",
+	"last_error",
+	"created_on",
+	"updated_on",
+	"delivered_on",
+}
+
+func webhookDeliveryRowValues(w *models.WebhookDelivery) []driver.Value {
+	return []driver.Value{
+		w.ID,
+		w.WebhookID,
+		w.EventType,
+		w.ContentType,
+		w.Payload,
+		w.Status,
+		w.Attempts,
+		w.NextAttemptOn,
+		nil,
+		w.LastStatusCode,
+		w.LastError,
+		w.CreatedOn,
+		nil,
+		nil,
+	}
+}
+
+func setWebhookDeliveryReadQueryExpectation(t *testing.T, mock sqlmock.Sqlmock, id uint64, toReturn *models.WebhookDelivery, err error) {
+	t.Helper()
+	query := formatQueryForSQLMock(webhookDeliverySelectionQuery)
+
+	exampleRows := sqlmock.NewRows(webhookDeliveryColumns).AddRow(webhookDeliveryRowValues(toReturn)...)
+	mock.ExpectQuery(query).WithArgs(id).WillReturnRows(exampleRows).WillReturnError(err)
+}
+
+func TestGetWebhookDelivery(t *testing.T) {
+	t.Parallel()
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+	exampleID := uint64(1)
+	expected := &models.WebhookDelivery{ID: exampleID, WebhookID: 2, Payload: "{}", Status: models.WebhookDeliveryPending}
+	client := NewPostgres()
+
+	t.Run("optimal behavior", func(t *testing.T) {
+		setWebhookDeliveryReadQueryExpectation(t, mock, exampleID, expected, nil)
+		actual, err := client.GetWebhookDelivery(mockDB, exampleID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, expected, actual, "expected webhook delivery did not match actual webhook delivery")
+		assert.Nil(t, mock.ExpectationsWereMet(), "not all database expectations were met")
+	})
+}
+
+func setWebhookDeliveryListReadQueryExpectation(t *testing.T, mock sqlmock.Sqlmock, qf *models.QueryFilter, example *models.WebhookDelivery, rowErr error, err error) {
+	exampleRows := sqlmock.NewRows(webhookDeliveryColumns).
+		AddRow(webhookDeliveryRowValues(example)...).
+		AddRow(webhookDeliveryRowValues(example)...).
+		AddRow(webhookDeliveryRowValues(example)...).
+		RowError(1, rowErr)
+
+	query, _ := buildWebhookDeliveryListRetrievalQuery(qf)
+
+	mock.ExpectQuery(formatQueryForSQLMock(query)).
+		WillReturnRows(exampleRows).
+		WillReturnError(err)
+}
+
+func TestGetWebhookDeliveryList(t *testing.T) {
+	t.Parallel()
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+	example := &models.WebhookDelivery{ID: 1}
+	client := NewPostgres()
+	exampleQF := &models.QueryFilter{
+		Limit: 25,
+		Page:  1,
+	}
+
+	t.Run("optimal behavior", func(t *testing.T) {
+		setWebhookDeliveryListReadQueryExpectation(t, mock, exampleQF, example, nil, nil)
+		actual, err := client.GetWebhookDeliveryList(mockDB, exampleQF)
+
+		assert.NoError(t, err)
+		assert.NotEmpty(t, actual, "list retrieval method should not return an empty slice")
+		assert.Nil(t, mock.ExpectationsWereMet(), "not all database expectations were met")
+	})
+
+	t.Run("with error executing query", func(t *testing.T) {
+		setWebhookDeliveryListReadQueryExpectation(t, mock, exampleQF, example, nil, errors.New("pineapple on pizza"))
+		actual, err := client.GetWebhookDeliveryList(mockDB, exampleQF)
+
+		assert.NotNil(t, err)
+		assert.Nil(t, actual)
+		assert.Nil(t, mock.ExpectationsWereMet(), "not all database expectations were met")
+	})
+
+	t.Run("with error scanning values", func(t *testing.T) {
+		exampleRows := sqlmock.NewRows([]string{"things"}).AddRow("stuff")
+		query, _ := buildWebhookDeliveryListRetrievalQuery(exampleQF)
+		mock.ExpectQuery(formatQueryForSQLMock(query)).
+			WillReturnRows(exampleRows)
+
+		actual, err := client.GetWebhookDeliveryList(mockDB, exampleQF)
+
+		assert.NotNil(t, err)
+		assert.Nil(t, actual)
+		assert.Nil(t, mock.ExpectationsWereMet(), "not all database expectations were met")
+	})
+
+	t.Run("with with row errors", func(t *testing.T) {
+		setWebhookDeliveryListReadQueryExpectation(t, mock, exampleQF, example, errors.New("pineapple on pizza"), nil)
+		actual, err := client.GetWebhookDeliveryList(mockDB, exampleQF)
+
+		assert.NotNil(t, err)
+		assert.Nil(t, actual)
+		assert.Nil(t, mock.ExpectationsWereMet(), "not all database expectations were met")
+	})
+}
+
+func TestBuildWebhookDeliveryCountRetrievalQuery(t *testing.T) {
+	t.Parallel()
+
+	exampleQF := &models.QueryFilter{
+		Limit: 25,
+		Page:  1,
+	}
+	// deliveries don't have an archived_on column to filter on
+	expected := `SELECT count(id) FROM webhook_deliveries LIMIT 25`
+	actual, _ := buildWebhookDeliveryCountRetrievalQuery(exampleQF)
+
+	assert.Equal(t, expected, actual, "expected and actual queries should match")
+	assert.False(t, exampleQF.IncludeArchived, "query filter should not be modified")
+}
+
+func TestGetWebhookDeliveryCount(t *testing.T) {
+	t.Parallel()
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+	client := NewPostgres()
+	expected := uint64(123)
+	exampleQF := &models.QueryFilter{
+		Limit: 25,
+		Page:  1,
+	}
+
+	t.Run("optimal behavior", func(t *testing.T) {
+		query, _ := buildWebhookDeliveryCountRetrievalQuery(exampleQF)
+		mock.ExpectQuery(formatQueryForSQLMock(query)).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(expected))
+		actual, err := client.GetWebhookDeliveryCount(mockDB, exampleQF)
+
+		assert.NoError(t, err)
+		assert.Equal(t, expected, actual, "count retrieval method should return the expected value")
+		assert.Nil(t, mock.ExpectationsWereMet(), "not all database expectations were met")
+	})
+}
+
+func TestClaimPendingWebhookDeliveries(t *testing.T) {
+	t.Parallel()
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+	example := &models.WebhookDelivery{ID: 1, WebhookID: 2, Status: models.WebhookDeliveryPending}
+	client := NewPostgres()
+
+	t.Run("optimal behavior", func(t *testing.T) {
+		exampleRows := sqlmock.NewRows(webhookDeliveryColumns).
+			AddRow(webhookDeliveryRowValues(example)...).
+			AddRow(webhookDeliveryRowValues(example)...)
+		mock.ExpectQuery(formatQueryForSQLMock(webhookDeliveryClaimQuery)).
+			WithArgs(uint(4), int64(20000)).
+			WillReturnRows(exampleRows)
+
+		actual, err := client.ClaimPendingWebhookDeliveries(mockDB, 4, 20*time.Second)
+
+		assert.NoError(t, err)
+		assert.Len(t, actual, 2)
+		assert.Nil(t, mock.ExpectationsWereMet(), "not all database expectations were met")
+	})
+
+	t.Run("with error executing query", func(t *testing.T) {
+		mock.ExpectQuery(formatQueryForSQLMock(webhookDeliveryClaimQuery)).
+			WillReturnError(errors.New("pineapple on pizza"))
+
+		actual, err := client.ClaimPendingWebhookDeliveries(mockDB, 4, 20*time.Second)
+
+		assert.NotNil(t, err)
+		assert.Nil(t, actual)
+		assert.Nil(t, mock.ExpectationsWereMet(), "not all database expectations were met")
+	})
+}
+
+func TestCreateWebhookDelivery(t *testing.T) {
+	t.Parallel()
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+	exampleInput := &models.WebhookDelivery{
+		WebhookID:   2,
+		EventType:   "product_updated",
+		ContentType: "application/json",
+		Payload:     "{}",
+	}
+	client := NewPostgres()
+
+	t.Run("optimal behavior", func(t *testing.T) {
+		expectedCreatedOn := buildTestTime(t)
+		mock.ExpectQuery(formatQueryForSQLMock(webhookDeliveryCreationQuery)).
+			WithArgs(
+				exampleInput.WebhookID,
+				exampleInput.EventType,
+				exampleInput.ContentType,
+				exampleInput.Payload,
+			).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_on"}).AddRow(uint64(1), expectedCreatedOn))
+
+		actualID, actualCreatedOn, err := client.CreateWebhookDelivery(mockDB, exampleInput)
+
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(1), actualID, "expected and actual IDs don't match")
+		assert.Equal(t, expectedCreatedOn, actualCreatedOn, "expected creation time did not match actual creation time")
+		assert.Nil(t, mock.ExpectationsWereMet(), "not all database expectations were met")
+	})
+}
+
+func TestUpdateWebhookDelivery(t *testing.T) {
+	t.Parallel()
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+	exampleInput := &models.WebhookDelivery{
+		ID:             1,
+		Status:         models.WebhookDeliveryDelivered,
+		Attempts:       3,
+		NextAttemptOn:  buildTestTime(t),
+		LastStatusCode: 200,
+		DeliveredOn:    &models.Dairytime{Time: buildTestTime(t)},
+	}
+	client := NewPostgres()
+
+	t.Run("optimal behavior", func(t *testing.T) {
+		expected := buildTestTime(t)
+		mock.ExpectQuery(formatQueryForSQLMock(webhookDeliveryUpdateQuery)).
+			WithArgs(
+				exampleInput.Status,
+				exampleInput.Attempts,
+				exampleInput.NextAttemptOn,
+				nil,
+				exampleInput.LastStatusCode,
+				exampleInput.LastError,
+				exampleInput.DeliveredOn.Time,
+				exampleInput.ID,
+			).
+			WillReturnRows(sqlmock.NewRows([]string{"updated_on"}).AddRow(expected))
+
+		actual, err := client.UpdateWebhookDelivery(mockDB, exampleInput)
+
+		assert.NoError(t, err)
+		assert.Equal(t, expected, actual, "expected and actual update times did not match")
+		assert.Nil(t, mock.ExpectationsWereMet(), "not all database expectations were met")
+	})
+}
@@ -29,9 +29,20 @@ const webhookExecutionLogSelectionQuery = `
     SELECT
         id,
         webhook_id,
+        delivery_id,
+        event_type,
+        attempt,
+        request_url,
+        request_headers,
+        request_body,
         status_code,
+        response_body,
+        latency_ms,
+        error,
         succeeded,
-        executed_on
+        executed_on,
+        updated_on,
+        archived_on
     FROM
         webhook_execution_logs
     WHERE
@@ -43,30 +54,84 @@ const webhookExecutionLogSelectionQuery = `
 func (pg *postgres) GetWebhookExecutionLog(db database.Querier, id uint64) (*models.WebhookExecutionLog, error) {
 	w := &models.WebhookExecutionLog{}
 
-	err := db.QueryRow(webhookExecutionLogSelectionQuery, id).Scan(&w.ID, &w.WebhookID, &w.StatusCode, &w.Succeeded, &w.ExecutedOn)
+	err := db.QueryRow(webhookExecutionLogSelectionQuery, id).Scan(&w.ID, &w.WebhookID, &w.DeliveryID, &w.EventType, &w.Attempt, &w.RequestURL, &w.RequestHeaders, &w.RequestBody, &w.StatusCode, &w.ResponseBody, &w.LatencyMS, &w.Error, &w.Succeeded, &w.ExecutedOn, &w.UpdatedOn, &w.ArchivedOn)
 
 	return w, err
 }
 
-func buildWebhookExecutionLogListRetrievalQuery(qf *models.QueryFilter) (string, []interface{}) {
+// applyWebhookExecutionLogQueryFilter applies a query filter to an execution log query. Execution logs don't
+// have a created_on column, so the creation time conditions apply to executed_on instead.
+func applyWebhookExecutionLogQueryFilter(queryBuilder squirrel.SelectBuilder, qf *models.QueryFilter, includeOffset bool) squirrel.SelectBuilder {
+	if qf == nil {
+		return queryBuilder
+	}
+
+	filter := *qf
+	filter.CreatedAfter, filter.CreatedBefore = time.Time{}, time.Time{}
+	queryBuilder = applyQueryFilterToQueryBuilder(queryBuilder, &filter, includeOffset)
+
+	if !qf.CreatedAfter.IsZero() {
+		queryBuilder = queryBuilder.Where(squirrel.Gt{"executed_on": qf.CreatedAfter})
+	}
+	if !qf.CreatedBefore.IsZero() {
+		queryBuilder = queryBuilder.Where(squirrel.Lt{"executed_on": qf.CreatedBefore})
+	}
+	return queryBuilder
+}
+
+func buildWebhookExecutionLogSelectQuery() squirrel.SelectBuilder {
 	sqlBuilder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
-	queryBuilder := sqlBuilder.
+	return sqlBuilder.
 		Select(
 			"id",
 			"webhook_id",
+			"delivery_id",
+			"event_type",
+			"attempt",
+			"request_url",
+			"request_headers",
+			"request_body",
 			"status_code",
+			"response_body",
+			"latency_ms",
+			"error",
 			"succeeded",
 			"executed_on",
+			"updated_on",
+			"archived_on",
 		).
 		From("webhook_execution_logs")
+}
+
+func buildWebhookExecutionLogListRetrievalQuery(qf *models.QueryFilter) (string, []interface{}) {
+	queryBuilder := buildWebhookExecutionLogSelectQuery()
 
-	query, args, _ := applyQueryFilterToQueryBuilder(queryBuilder, qf, true).ToSql()
+	query, args, _ := applyWebhookExecutionLogQueryFilter(queryBuilder, qf, true).ToSql()
 	return query, args
 }
 
 func (pg *postgres) GetWebhookExecutionLogList(db database.Querier, qf *models.QueryFilter) ([]models.WebhookExecutionLog, error) {
-	var list []models.WebhookExecutionLog
 	query, args := buildWebhookExecutionLogListRetrievalQuery(qf)
+	return queryWebhookExecutionLogs(db, query, args)
+}
+
+func buildWebhookExecutionLogListByWebhookIDRetrievalQuery(webhookID uint64, qf *models.QueryFilter) (string, []interface{}) {
+	queryBuilder := buildWebhookExecutionLogSelectQuery().
+		Where(squirrel.Eq{"webhook_id": webhookID}).
+		OrderBy("executed_on DESC", "id DESC")
+
+	query, args, _ := applyWebhookExecutionLogQueryFilter(queryBuilder, qf, true).ToSql()
+	return query, args
+}
+
+// GetWebhookExecutionLogsByWebhookID returns a page of a webhook's execution logs, most recent first
+func (pg *postgres) GetWebhookExecutionLogsByWebhookID(db database.Querier, webhookID uint64, qf *models.QueryFilter) ([]models.WebhookExecutionLog, error) {
+	query, args := buildWebhookExecutionLogListByWebhookIDRetrievalQuery(webhookID, qf)
+	return queryWebhookExecutionLogs(db, query, args)
+}
+
+func queryWebhookExecutionLogs(db database.Querier, query string, args []interface{}) ([]models.WebhookExecutionLog, error) {
+	var list []models.WebhookExecutionLog
 
 	rows, err := db.Query(query, args...)
 	if err != nil {
@@ -78,9 +143,20 @@ func (pg *postgres) GetWebhookExecutionLogList(db database.Querier, qf *models.Q
 		err := rows.Scan(
 			&w.ID,
 			&w.WebhookID,
+			&w.DeliveryID,
+			&w.EventType,
+			&w.Attempt,
+			&w.RequestURL,
+			&w.RequestHeaders,
+			&w.RequestBody,
 			&w.StatusCode,
+			&w.ResponseBody,
+			&w.LatencyMS,
+			&w.Error,
 			&w.Succeeded,
 			&w.ExecutedOn,
+			&w.UpdatedOn,
+			&w.ArchivedOn,
 		)
 		if err != nil {
 			return nil, err
@@ -100,7 +176,7 @@ func buildWebhookExecutionLogCountRetrievalQuery(qf *models.QueryFilter) (string
 		Select("count(id)").
 		From("webhook_execution_logs")
 
-	query, args, _ := applyQueryFilterToQueryBuilder(queryBuilder, qf, false).ToSql()
+	query, args, _ := applyWebhookExecutionLogQueryFilter(queryBuilder, qf, false).ToSql()
 	return query, args
 }
 
@@ -111,21 +187,38 @@ func (pg *postgres) GetWebhookExecutionLogCount(db database.Querier, qf *models.
 	return count, err
 }
 
+func buildWebhookExecutionLogCountByWebhookIDRetrievalQuery(webhookID uint64, qf *models.QueryFilter) (string, []interface{}) {
+	queryBuilder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar).
+		Select("count(id)").
+		From("webhook_execution_logs").
+		Where(squirrel.Eq{"webhook_id": webhookID})
+
+	query, args, _ := applyWebhookExecutionLogQueryFilter(queryBuilder, qf, false).ToSql()
+	return query, args
+}
+
+func (pg *postgres) GetWebhookExecutionLogCountByWebhookID(db database.Querier, webhookID uint64, qf *models.QueryFilter) (uint64, error) {
+	var count uint64
+	query, args := buildWebhookExecutionLogCountByWebhookIDRetrievalQuery(webhookID, qf)
+	err := db.QueryRow(query, args...).Scan(&count)
+	return count, err
+}
+
 const webhookExecutionLogCreationQuery = `
     INSERT INTO webhook_execution_logs
         (
-            webhook_id, status_code, succeeded, executed_on
+            webhook_id, delivery_id, event_type, attempt, request_url, request_headers, request_body, status_code, response_body, latency_ms, error, succeeded, executed_on
         )
     VALUES
         (
-            $1, $2, $3, $4
+            $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
         )
     RETURNING
-        id, created_on;
+        id, executed_on;
 `
 
 func (pg *postgres) CreateWebhookExecutionLog(db database.Querier, nu *models.WebhookExecutionLog) (createdID uint64, createdOn time.Time, err error) {
-	err = db.QueryRow(webhookExecutionLogCreationQuery, &nu.WebhookID, &nu.StatusCode, &nu.Succeeded, &nu.ExecutedOn).Scan(&createdID, &createdOn)
+	err = db.QueryRow(webhookExecutionLogCreationQuery, &nu.WebhookID, &nu.DeliveryID, &nu.EventType, &nu.Attempt, &nu.RequestURL, &nu.RequestHeaders, &nu.RequestBody, &nu.StatusCode, &nu.ResponseBody, &nu.LatencyMS, &nu.Error, &nu.Succeeded, &nu.ExecutedOn).Scan(&createdID, &createdOn)
 	return createdID, createdOn, err
 }
 
@@ -68,15 +68,37 @@ func setWebhookExecutionLogReadQueryExpectation(t *testing.T, mock sqlmock.Sqlmo
 	exampleRows := sqlmock.NewRows([]string{
 		"id",
 		"webhook_id",
+		"delivery_id",
+		"event_type",
+		"attempt",
+		"request_url",
+		"request_headers",
+		"request_body",
 		"status_code",
+		"response_body",
+		"latency_ms",
+		"error",
 		"succeeded",
 		"executed_on",
+		"updated_on",
+		"archived_on",
 	}).AddRow(
 		toReturn.ID,
 		toReturn.WebhookID,
+		toReturn.DeliveryID,
+		toReturn.EventType,
+		toReturn.Attempt,
+		toReturn.RequestURL,
+		[]byte(`{}`),
+		toReturn.RequestBody,
 		toReturn.StatusCode,
+		toReturn.ResponseBody,
+		toReturn.LatencyMS,
+		toReturn.Error,
 		toReturn.Succeeded,
 		toReturn.ExecutedOn,
+		toReturn.UpdatedOn,
+		toReturn.ArchivedOn,
 	)
 	mock.ExpectQuery(query).WithArgs(id).WillReturnRows(exampleRows).WillReturnError(err)
 }
@@ -87,7 +109,7 @@ func TestGetWebhookExecutionLog(t *testing.T) {
 	assert.NoError(t, err)
 	defer mockDB.Close()
 	exampleID := uint64(1)
-	expected := &models.WebhookExecutionLog{ID: exampleID}
+	expected := &models.WebhookExecutionLog{ID: exampleID, RequestHeaders: models.WebhookHeaders{}}
 	client := NewPostgres()
 
 	t.Run("optimal behavior", func(t *testing.T) {
@@ -104,27 +126,71 @@ func setWebhookExecutionLogListReadQueryExpectation(t *testing.T, mock sqlmock.S
 	exampleRows := sqlmock.NewRows([]string{
 		"id",
 		"webhook_id",
+		"delivery_id",
+		"event_type",
+		"attempt",
+		"request_url",
+		"request_headers",
+		"request_body",
 		"status_code",
+		"response_body",
+		"latency_ms",
+		"error",
 		"succeeded",
 		"executed_on",
+		"updated_on",
+		"archived_on",
 	}).AddRow(
 		example.ID,
 		example.WebhookID,
+		example.DeliveryID,
+		example.EventType,
+		example.Attempt,
+		example.RequestURL,
+		[]byte(`{}`),
+		example.RequestBody,
 		example.StatusCode,
+		example.ResponseBody,
+		example.LatencyMS,
+		example.Error,
 		example.Succeeded,
 		example.ExecutedOn,
+		example.UpdatedOn,
+		example.ArchivedOn,
 	).AddRow(
 		example.ID,
 		example.WebhookID,
+		example.DeliveryID,
+		example.EventType,
+		example.Attempt,
+		example.RequestURL,
+		[]byte(`{}`),
+		example.RequestBody,
 		example.StatusCode,
+		example.ResponseBody,
+		example.LatencyMS,
+		example.Error,
 		example.Succeeded,
 		example.ExecutedOn,
+		example.UpdatedOn,
+		example.ArchivedOn,
 	).AddRow(
 		example.ID,
 		example.WebhookID,
+		example.DeliveryID,
+		example.EventType,
+		example.Attempt,
+		example.RequestURL,
+		[]byte(`{}`),
+		example.RequestBody,
 		example.StatusCode,
+		example.ResponseBody,
+		example.LatencyMS,
+		example.Error,
 		example.Succeeded,
 		example.ExecutedOn,
+		example.UpdatedOn,
+		example.ArchivedOn,
 	).RowError(1, rowErr)
 
 	query, _ := buildWebhookExecutionLogListRetrievalQuery(qf)
@@ -188,6 +254,151 @@ func TestGetWebhookExecutionLogList(t *testing.T) {
 	})
 }
 
+func TestBuildWebhookExecutionLogListByWebhookIDRetrievalQuery(t *testing.T) {
+	t.Parallel()
+
+	t.Run("optimal behavior", func(t *testing.T) {
+		exampleQF := &models.QueryFilter{
+			Limit: 25,
+			Page:  2,
+		}
+		expected := `SELECT id, webhook_id, delivery_id, event_type, attempt, request_url, request_headers, request_body, status_code, response_body, latency_ms, error, succeeded, executed_on, updated_on, archived_on FROM webhook_execution_logs WHERE webhook_id = $1 AND archived_on IS NULL ORDER BY executed_on DESC, id DESC LIMIT 25 OFFSET 25`
+		actual, args := buildWebhookExecutionLogListByWebhookIDRetrievalQuery(1, exampleQF)
+
+		assert.Equal(t, expected, actual, "expected and actual queries should match")
+		assert.Equal(t, []interface{}{uint64(1)}, args)
+	})
+
+	t.Run("with creation time filters", func(t *testing.T) {
+		exampleQF := &models.QueryFilter{
+			Limit:        25,
+			Page:         1,
+			CreatedAfter: buildTestTime(t),
+		}
+		expected := `SELECT id, webhook_id, delivery_id, event_type, attempt, request_url, request_headers, request_body, status_code, response_body, latency_ms, error, succeeded, executed_on, updated_on, archived_on FROM webhook_execution_logs WHERE webhook_id = $1 AND archived_on IS NULL AND executed_on > $2 ORDER BY executed_on DESC, id DESC LIMIT 25`
+		actual, args := buildWebhookExecutionLogListByWebhookIDRetrievalQuery(1, exampleQF)
+
+		assert.Equal(t, expected, actual, "execution logs should be filtered by executed_on instead of created_on")
+		assert.Equal(t, []interface{}{uint64(1), buildTestTime(t)}, args)
+	})
+}
+
+func TestGetWebhookExecutionLogsByWebhookID(t *testing.T) {
+	t.Parallel()
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+	exampleWebhookID := uint64(1)
+	client := NewPostgres()
+	exampleQF := &models.QueryFilter{
+		Limit: 25,
+		Page:  1,
+	}
+	query, _ := buildWebhookExecutionLogListByWebhookIDRetrievalQuery(exampleWebhookID, exampleQF)
+
+	t.Run("optimal behavior", func(t *testing.T) {
+		exampleRows := sqlmock.NewRows([]string{
+			"id",
+			"webhook_id",
+			"delivery_id",
+			"event_type",
+			"attempt",
+			"request_url",
+			"request_headers",
+			"request_body",
+			"status_code",
+			"response_body",
+			"latency_ms",
+			"error",
+			"succeeded",
+			"executed_on",
+			"updated_on",
+			"archived_on",
+		}).AddRow(
+			uint64(2),
+			exampleWebhookID,
+			uint64(3),
+			"product.created",
+			1,
+			"https://dairycart.com/webhook",
+			[]byte(`{"Content-Type":"application/json"}`),
+			`{"id":1}`,
+			200,
+			"ok",
+			int64(120),
+			"",
+			true,
+			buildTestTime(t),
+			nil,
+			nil,
+		)
+		mock.ExpectQuery(formatQueryForSQLMock(query)).
+			WithArgs(exampleWebhookID).
+			WillReturnRows(exampleRows)
+
+		deliveryID := uint64(3)
+		expected := []models.WebhookExecutionLog{
+			{
+				ID:             2,
+				WebhookID:      exampleWebhookID,
+				DeliveryID:     &deliveryID,
+				EventType:      "product.created",
+				Attempt:        1,
+				RequestURL:     "https://dairycart.com/webhook",
+				RequestHeaders: models.WebhookHeaders{"Content-Type": "application/json"},
+				RequestBody:    `{"id":1}`,
+				StatusCode:     200,
+				ResponseBody:   "ok",
+				LatencyMS:      120,
+				Succeeded:      true,
+				ExecutedOn:     buildTestTime(t),
+			},
+		}
+		actual, err := client.GetWebhookExecutionLogsByWebhookID(mockDB, exampleWebhookID, exampleQF)
+
+		assert.NoError(t, err)
+		assert.Equal(t, expected, actual)
+		assert.Nil(t, mock.ExpectationsWereMet(), "not all database expectations were met")
+	})
+
+	t.Run("with error executing query", func(t *testing.T) {
+		mock.ExpectQuery(formatQueryForSQLMock(query)).
+			WithArgs(exampleWebhookID).
+			WillReturnError(errors.New("pineapple on pizza"))
+		actual, err := client.GetWebhookExecutionLogsByWebhookID(mockDB, exampleWebhookID, exampleQF)
+
+		assert.NotNil(t, err)
+		assert.Nil(t, actual)
+		assert.Nil(t, mock.ExpectationsWereMet(), "not all database expectations were met")
+	})
+}
+
+func TestGetWebhookExecutionLogCountByWebhookID(t *testing.T) {
+	t.Parallel()
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+	client := NewPostgres()
+	expected := uint64(123)
+	exampleQF := &models.QueryFilter{
+		Limit: 25,
+		Page:  1,
+	}
+
+	t.Run("optimal behavior", func(t *testing.T) {
+		query, _ := buildWebhookExecutionLogCountByWebhookIDRetrievalQuery(1, exampleQF)
+		assert.Equal(t, `SELECT count(id) FROM webhook_execution_logs WHERE webhook_id = $1 AND archived_on IS NULL LIMIT 25`, query)
+
+		exampleRow := sqlmock.NewRows([]string{"count"}).AddRow(expected)
+		mock.ExpectQuery(formatQueryForSQLMock(query)).WithArgs(uint64(1)).WillReturnRows(exampleRow)
+		actual, err := client.GetWebhookExecutionLogCountByWebhookID(mockDB, 1, exampleQF)
+
+		assert.NoError(t, err)
+		assert.Equal(t, expected, actual, "count retrieval method should return the expected value")
+		assert.Nil(t, mock.ExpectationsWereMet(), "not all database expectations were met")
+	})
+}
+
 func TestBuildWebhookExecutionLogCountRetrievalQuery(t *testing.T) {
 	t.Parallel()
 
@@ -241,11 +452,20 @@ func setWebhookExecutionLogCreationQueryExpectation(t *testing.T, mock sqlmock.S
 	t.Helper()
 	query := formatQueryForSQLMock(webhookExecutionLogCreationQuery)
 	tt := buildTestTime(t)
-	exampleRows := sqlmock.NewRows([]string{"id", "created_on"}).AddRow(uint64(1), tt)
+	exampleRows := sqlmock.NewRows([]string{"id", "executed_on"}).AddRow(uint64(1), tt)
 	mock.ExpectQuery(query).
 		WithArgs(
 			toCreate.WebhookID,
+			toCreate.DeliveryID,
+			toCreate.EventType,
+			toCreate.Attempt,
+			toCreate.RequestURL,
+			toCreate.RequestHeaders,
+			toCreate.RequestBody,
 			toCreate.StatusCode,
+			toCreate.ResponseBody,
+			toCreate.LatencyMS,
+			toCreate.Error,
 			toCreate.Succeeded,
 			toCreate.ExecutedOn,
 		).
@@ -8,23 +8,38 @@ import (
 	"github.com/dairycart/dairycart/storage/v1/database"
 
 	"github.com/Masterminds/squirrel"
+	"github.com/lib/pq"
 )
 
 const webhookQueryByEventType = `
     SELECT
         id,
         url,
-        event_type,
+        event_types,
         content_type,
+        fields,
+        filter,
+        status,
+        consecutive_failures,
+        paused_until,
+        secret,
+        previous_secret,
+        secret_rotated_on,
         created_on,
         updated_on,
         archived_on
     FROM
         webhooks
     WHERE
-        event_type = $1
+        event_types && ARRAY[$1::text, split_part($1::text, '.', 1) || '.*', '*']
+    AND
+        status = 'active'
+    AND
+        archived_on IS NULL
 `
 
+// GetWebhooksByEventType returns the active webhooks subscribed to an event, either by name,
+// through a wildcard for the event's resource (like `product.*`), or through `*`
 func (pg *postgres) GetWebhooksByEventType(db database.Querier, eventType string) ([]models.Webhook, error) {
 	var list []models.Webhook
 
@@ -38,8 +53,16 @@ func (pg *postgres) GetWebhooksByEventType(db database.Querier, eventType string
 		err := rows.Scan(
 			&w.ID,
 			&w.URL,
-			&w.EventType,
+			pq.Array(&w.EventTypes),
 			&w.ContentType,
+			pq.Array(&w.Fields),
+			&w.Filter,
+			&w.Status,
+			&w.ConsecutiveFailures,
+			&w.PausedUntil,
+			&w.Secret,
+			&w.PreviousSecret,
+			&w.SecretRotatedOn,
 			&w.CreatedOn,
 			&w.UpdatedOn,
 			&w.ArchivedOn,
@@ -76,8 +99,16 @@ const webhookSelectionQuery = `
     SELECT
         id,
         url,
-        event_type,
+        event_types,
         content_type,
+        fields,
+        filter,
+        status,
+        consecutive_failures,
+        paused_until,
+        secret,
+        previous_secret,
+        secret_rotated_on,
         created_on,
         updated_on,
         archived_on
@@ -92,7 +123,7 @@ const webhookSelectionQuery = `
 func (pg *postgres) GetWebhook(db database.Querier, id uint64) (*models.Webhook, error) {
 	w := &models.Webhook{}
 
-	err := db.QueryRow(webhookSelectionQuery, id).Scan(&w.ID, &w.URL, &w.EventType, &w.ContentType, &w.CreatedOn, &w.UpdatedOn, &w.ArchivedOn)
+	err := db.QueryRow(webhookSelectionQuery, id).Scan(&w.ID, &w.URL, pq.Array(&w.EventTypes), &w.ContentType, pq.Array(&w.Fields), &w.Filter, &w.Status, &w.ConsecutiveFailures, &w.PausedUntil, &w.Secret, &w.PreviousSecret, &w.SecretRotatedOn, &w.CreatedOn, &w.UpdatedOn, &w.ArchivedOn)
 
 	return w, err
 }
@@ -103,8 +134,16 @@ func buildWebhookListRetrievalQuery(qf *models.QueryFilter) (string, []interface
 		Select(
 			"id",
 			"url",
-			"event_type",
+			"event_types",
 			"content_type",
+			"fields",
+			"filter",
+			"status",
+			"consecutive_failures",
+			"paused_until",
+			"secret",
+			"previous_secret",
+			"secret_rotated_on",
 			"created_on",
 			"updated_on",
 			"archived_on",
@@ -129,8 +168,16 @@ func (pg *postgres) GetWebhookList(db database.Querier, qf *models.QueryFilter)
 		err := rows.Scan(
 			&w.ID,
 			&w.URL,
-			&w.EventType,
+			pq.Array(&w.EventTypes),
 			&w.ContentType,
+			pq.Array(&w.Fields),
+			&w.Filter,
+			&w.Status,
+			&w.ConsecutiveFailures,
+			&w.PausedUntil,
+			&w.Secret,
+			&w.PreviousSecret,
+			&w.SecretRotatedOn,
 			&w.CreatedOn,
 			&w.UpdatedOn,
 			&w.ArchivedOn,
@@ -167,18 +214,18 @@ func (pg *postgres) GetWebhookCount(db database.Querier, qf *models.QueryFilter)
 const webhookCreationQuery = `
     INSERT INTO webhooks
         (
-            url, event_type, content_type
+            url, event_types, content_type, fields, filter, secret, status
         )
     VALUES
         (
-            $1, $2, $3
+            $1, $2, $3, COALESCE($4, '{}'::text[]), $5, $6, COALESCE(NULLIF($7, ''), 'active')::webhook_status
         )
     RETURNING
-        id, executed_on;
+        id, created_on;
 `
 
 func (pg *postgres) CreateWebhook(db database.Querier, nu *models.Webhook) (createdID uint64, createdOn time.Time, err error) {
-	err = db.QueryRow(webhookCreationQuery, &nu.URL, &nu.EventType, &nu.ContentType).Scan(&createdID, &createdOn)
+	err = db.QueryRow(webhookCreationQuery, &nu.URL, pq.Array(nu.EventTypes), &nu.ContentType, pq.Array(nu.Fields), &nu.Filter, &nu.Secret, &nu.Status).Scan(&createdID, &createdOn)
 	return createdID, createdOn, err
 }
 
@@ -186,19 +233,97 @@ const webhookUpdateQuery = `
     UPDATE webhooks
     SET
         url = $1,
-        event_type = $2,
+        event_types = $2,
         content_type = $3,
+        fields = COALESCE($4, '{}'::text[]),
+        filter = $5,
         updated_on = NOW()
-    WHERE id = $4
+    WHERE id = $6
     RETURNING updated_on;
 `
 
 func (pg *postgres) UpdateWebhook(db database.Querier, updated *models.Webhook) (time.Time, error) {
 	var t time.Time
-	err := db.QueryRow(webhookUpdateQuery, &updated.URL, &updated.EventType, &updated.ContentType, &updated.ID).Scan(&t)
+	err := db.QueryRow(webhookUpdateQuery, &updated.URL, pq.Array(updated.EventTypes), &updated.ContentType, pq.Array(updated.Fields), &updated.Filter, &updated.ID).Scan(&t)
+	return t, err
+}
+
+const webhookSecretRotationQuery = `
+    UPDATE webhooks
+    SET
+        previous_secret = secret,
+        secret = $1,
+        secret_rotated_on = NOW(),
+        updated_on = NOW()
+    WHERE id = $2
+    RETURNING secret_rotated_on;
+`
+
+func (pg *postgres) RotateWebhookSecret(db database.Querier, id uint64, secret string) (time.Time, error) {
+	var t time.Time
+	err := db.QueryRow(webhookSecretRotationQuery, secret, id).Scan(&t)
 	return t, err
 }
 
+// webhookStatusUpdateQuery also starts the webhook's failure tracking over, so a webhook that's
+// been disabled for failing isn't paused again as soon as it's active
+const webhookStatusUpdateQuery = `
+    UPDATE webhooks
+    SET
+        status = $1,
+        consecutive_failures = 0,
+        paused_until = NULL,
+        updated_on = NOW()
+    WHERE id = $2
+    RETURNING updated_on;
+`
+
+func (pg *postgres) UpdateWebhookStatus(db database.Querier, id uint64, status string) (time.Time, error) {
+	var t time.Time
+	err := db.QueryRow(webhookStatusUpdateQuery, status, id).Scan(&t)
+	return t, err
+}
+
+const webhookFailureIncrementQuery = `
+    UPDATE webhooks
+    SET consecutive_failures = consecutive_failures + 1
+    WHERE id = $1
+    RETURNING consecutive_failures;
+`
+
+// IncrementWebhookFailures records that a delivery to a webhook failed, and returns how many have failed in a row
+func (pg *postgres) IncrementWebhookFailures(db database.Querier, id uint64) (int, error) {
+	var failures int
+	err := db.QueryRow(webhookFailureIncrementQuery, id).Scan(&failures)
+	return failures, err
+}
+
+const webhookFailureResetQuery = `
+    UPDATE webhooks
+    SET
+        consecutive_failures = 0,
+        paused_until = NULL
+    WHERE id = $1
+`
+
+// ResetWebhookFailures records that a delivery to a webhook succeeded
+func (pg *postgres) ResetWebhookFailures(db database.Querier, id uint64) error {
+	_, err := db.Exec(webhookFailureResetQuery, id)
+	return err
+}
+
+const webhookPauseQuery = `
+    UPDATE webhooks
+    SET paused_until = $1
+    WHERE id = $2
+`
+
+// PauseWebhook stops deliveries to a webhook from being claimed until the given time
+func (pg *postgres) PauseWebhook(db database.Querier, id uint64, until time.Time) error {
+	_, err := db.Exec(webhookPauseQuery, until, id)
+	return err
+}
+
 const webhookDeletionQuery = `
     UPDATE webhooks
     SET archived_on = NOW()
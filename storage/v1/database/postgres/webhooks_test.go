@@ -19,32 +19,64 @@ func setWebhookReadQueryExpectationByEventType(t *testing.T, mock sqlmock.Sqlmoc
 	exampleRows := sqlmock.NewRows([]string{
 		"id",
 		"url",
-		"event_type",
+		"event_types",
 		"content_type",
+		"fields",
+		"filter",
+		"status",
+		"consecutive_failures",
+		"paused_until",
+		"secret",
+		"previous_secret",
+		"secret_rotated_on",
 		"created_on",
 		"updated_on",
 		"archived_on",
 	}).AddRow(
 		example.ID,
 		example.URL,
-		example.EventType,
+		"{product.created}",
 		example.ContentType,
+		"{price,quantity}",
+		example.Filter,
+		example.Status,
+		example.ConsecutiveFailures,
+		example.PausedUntil,
+		example.Secret,
+		example.PreviousSecret,
+		example.SecretRotatedOn,
 		example.CreatedOn,
 		example.UpdatedOn,
 		example.ArchivedOn,
 	).AddRow(
 		example.ID,
 		example.URL,
-		example.EventType,
+		"{product.created}",
 		example.ContentType,
+		"{price,quantity}",
+		example.Filter,
+		example.Status,
+		example.ConsecutiveFailures,
+		example.PausedUntil,
+		example.Secret,
+		example.PreviousSecret,
+		example.SecretRotatedOn,
 		example.CreatedOn,
 		example.UpdatedOn,
 		example.ArchivedOn,
 	).AddRow(
 		example.ID,
 		example.URL,
-		example.EventType,
+		"{product.created}",
 		example.ContentType,
+		"{price,quantity}",
+		example.Filter,
+		example.Status,
+		example.ConsecutiveFailures,
+		example.PausedUntil,
+		example.Secret,
+		example.PreviousSecret,
+		example.SecretRotatedOn,
 		example.CreatedOn,
 		example.UpdatedOn,
 		example.ArchivedOn,
@@ -62,8 +94,8 @@ func TestGetWebhooksByEventType(t *testing.T) {
 	defer mockDB.Close()
 	client := NewPostgres()
 
-	exampleEventType := "product_updated"
-	example := &models.Webhook{}
+	exampleEventType := "product.updated"
+	example := &models.Webhook{EventTypes: []string{"product.created"}}
 
 	t.Run("optimal behavior", func(t *testing.T) {
 		setWebhookReadQueryExpectationByEventType(t, mock, example, nil, nil)
@@ -158,16 +190,32 @@ func setWebhookReadQueryExpectation(t *testing.T, mock sqlmock.Sqlmock, id uint6
 	exampleRows := sqlmock.NewRows([]string{
 		"id",
 		"url",
-		"event_type",
+		"event_types",
 		"content_type",
+		"fields",
+		"filter",
+		"status",
+		"consecutive_failures",
+		"paused_until",
+		"secret",
+		"previous_secret",
+		"secret_rotated_on",
 		"created_on",
 		"updated_on",
 		"archived_on",
 	}).AddRow(
 		toReturn.ID,
 		toReturn.URL,
-		toReturn.EventType,
+		"{product.created}",
 		toReturn.ContentType,
+		"{price,quantity}",
+		toReturn.Filter,
+		toReturn.Status,
+		toReturn.ConsecutiveFailures,
+		toReturn.PausedUntil,
+		toReturn.Secret,
+		toReturn.PreviousSecret,
+		toReturn.SecretRotatedOn,
 		toReturn.CreatedOn,
 		toReturn.UpdatedOn,
 		toReturn.ArchivedOn,
@@ -181,7 +229,7 @@ func TestGetWebhook(t *testing.T) {
 	assert.NoError(t, err)
 	defer mockDB.Close()
 	exampleID := uint64(1)
-	expected := &models.Webhook{ID: exampleID}
+	expected := &models.Webhook{ID: exampleID, EventTypes: []string{"product.created"}, Fields: []string{"price", "quantity"}, Filter: `brand == "Acme"`}
 	client := NewPostgres()
 
 	t.Run("optimal behavior", func(t *testing.T) {
@@ -198,32 +246,64 @@ func setWebhookListReadQueryExpectation(t *testing.T, mock sqlmock.Sqlmock, qf *
 	exampleRows := sqlmock.NewRows([]string{
 		"id",
 		"url",
-		"event_type",
+		"event_types",
 		"content_type",
+		"fields",
+		"filter",
+		"status",
+		"consecutive_failures",
+		"paused_until",
+		"secret",
+		"previous_secret",
+		"secret_rotated_on",
 		"created_on",
 		"updated_on",
 		"archived_on",
 	}).AddRow(
 		example.ID,
 		example.URL,
-		example.EventType,
+		"{product.created}",
 		example.ContentType,
+		"{price,quantity}",
+		example.Filter,
+		example.Status,
+		example.ConsecutiveFailures,
+		example.PausedUntil,
+		example.Secret,
+		example.PreviousSecret,
+		example.SecretRotatedOn,
 		example.CreatedOn,
 		example.UpdatedOn,
 		example.ArchivedOn,
 	).AddRow(
 		example.ID,
 		example.URL,
-		example.EventType,
+		"{product.created}",
 		example.ContentType,
+		"{price,quantity}",
+		example.Filter,
+		example.Status,
+		example.ConsecutiveFailures,
+		example.PausedUntil,
+		example.Secret,
+		example.PreviousSecret,
+		example.SecretRotatedOn,
 		example.CreatedOn,
 		example.UpdatedOn,
 		example.ArchivedOn,
 	).AddRow(
 		example.ID,
 		example.URL,
-		example.EventType,
+		"{product.created}",
 		example.ContentType,
+		"{price,quantity}",
+		example.Filter,
+		example.Status,
+		example.ConsecutiveFailures,
+		example.PausedUntil,
+		example.Secret,
+		example.PreviousSecret,
+		example.SecretRotatedOn,
 		example.CreatedOn,
 		example.UpdatedOn,
 		example.ArchivedOn,
@@ -242,7 +322,7 @@ func TestGetWebhookList(t *testing.T) {
 	assert.NoError(t, err)
 	defer mockDB.Close()
 	exampleID := uint64(1)
-	example := &models.Webhook{ID: exampleID}
+	example := &models.Webhook{ID: exampleID, EventTypes: []string{"product.created"}}
 	client := NewPostgres()
 	exampleQF := &models.QueryFilter{
 		Limit: 25,
@@ -347,8 +427,12 @@ func setWebhookCreationQueryExpectation(t *testing.T, mock sqlmock.Sqlmock, toCr
 	mock.ExpectQuery(query).
 		WithArgs(
 			toCreate.URL,
-			toCreate.EventType,
+			`{"product.created"}`,
 			toCreate.ContentType,
+			`{"price","quantity"}`,
+			toCreate.Filter,
+			toCreate.Secret,
+			toCreate.Status,
 		).
 		WillReturnRows(exampleRows).
 		WillReturnError(err)
@@ -360,7 +444,7 @@ func TestCreateWebhook(t *testing.T) {
 	assert.NoError(t, err)
 	defer mockDB.Close()
 	expectedID := uint64(1)
-	exampleInput := &models.Webhook{ID: expectedID}
+	exampleInput := &models.Webhook{ID: expectedID, EventTypes: []string{"product.created"}, Fields: []string{"price", "quantity"}}
 	client := NewPostgres()
 
 	t.Run("optimal behavior", func(t *testing.T) {
@@ -384,8 +468,10 @@ func setWebhookUpdateQueryExpectation(t *testing.T, mock sqlmock.Sqlmock, toUpda
 	mock.ExpectQuery(query).
 		WithArgs(
 			toUpdate.URL,
-			toUpdate.EventType,
+			`{"product.created"}`,
 			toUpdate.ContentType,
+			`{"price","quantity"}`,
+			toUpdate.Filter,
 			toUpdate.ID,
 		).
 		WillReturnRows(exampleRows).
@@ -397,7 +483,7 @@ func TestUpdateWebhookByID(t *testing.T) {
 	mockDB, mock, err := sqlmock.New()
 	assert.NoError(t, err)
 	defer mockDB.Close()
-	exampleInput := &models.Webhook{ID: uint64(1)}
+	exampleInput := &models.Webhook{ID: uint64(1), EventTypes: []string{"product.created"}, Fields: []string{"price", "quantity"}}
 	client := NewPostgres()
 
 	t.Run("optimal behavior", func(t *testing.T) {
@@ -411,6 +497,33 @@ func TestUpdateWebhookByID(t *testing.T) {
 	})
 }
 
+func setWebhookSecretRotationQueryExpectation(t *testing.T, mock sqlmock.Sqlmock, id uint64, secret string, err error) {
+	t.Helper()
+	query := formatQueryForSQLMock(webhookSecretRotationQuery)
+	exampleRows := sqlmock.NewRows([]string{"secret_rotated_on"}).AddRow(buildTestTime(t))
+	mock.ExpectQuery(query).WithArgs(secret, id).WillReturnRows(exampleRows).WillReturnError(err)
+}
+
+func TestRotateWebhookSecret(t *testing.T) {
+	t.Parallel()
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+	exampleID := uint64(1)
+	exampleSecret := "new secret"
+	client := NewPostgres()
+
+	t.Run("optimal behavior", func(t *testing.T) {
+		setWebhookSecretRotationQueryExpectation(t, mock, exampleID, exampleSecret, nil)
+		expected := buildTestTime(t)
+		actual, err := client.RotateWebhookSecret(mockDB, exampleID, exampleSecret)
+
+		assert.NoError(t, err)
+		assert.Equal(t, expected, actual, "expected rotation time did not match actual rotation time")
+		assert.Nil(t, mock.ExpectationsWereMet(), "not all database expectations were met")
+	})
+}
+
 func setWebhookDeletionQueryExpectation(t *testing.T, mock sqlmock.Sqlmock, id uint64, err error) {
 	t.Helper()
 	query := formatQueryForSQLMock(webhookDeletionQuery)
@@ -418,6 +531,84 @@ func setWebhookDeletionQueryExpectation(t *testing.T, mock sqlmock.Sqlmock, id u
 	mock.ExpectQuery(query).WithArgs(id).WillReturnRows(exampleRows).WillReturnError(err)
 }
 
+func TestUpdateWebhookStatus(t *testing.T) {
+	t.Parallel()
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+	exampleID := uint64(1)
+	client := NewPostgres()
+
+	t.Run("optimal behavior", func(t *testing.T) {
+		exampleRows := sqlmock.NewRows([]string{"updated_on"}).AddRow(buildTestTime(t))
+		mock.ExpectQuery(formatQueryForSQLMock(webhookStatusUpdateQuery)).
+			WithArgs(models.WebhookStatusActive, exampleID).
+			WillReturnRows(exampleRows)
+		expected := buildTestTime(t)
+		actual, err := client.UpdateWebhookStatus(mockDB, exampleID, models.WebhookStatusActive)
+
+		assert.NoError(t, err)
+		assert.Equal(t, expected, actual, "expected update time did not match actual update time")
+		assert.Nil(t, mock.ExpectationsWereMet(), "not all database expectations were met")
+	})
+}
+
+func TestIncrementWebhookFailures(t *testing.T) {
+	t.Parallel()
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+	exampleID := uint64(1)
+	client := NewPostgres()
+
+	t.Run("optimal behavior", func(t *testing.T) {
+		mock.ExpectQuery(formatQueryForSQLMock(webhookFailureIncrementQuery)).
+			WithArgs(exampleID).
+			WillReturnRows(sqlmock.NewRows([]string{"consecutive_failures"}).AddRow(3))
+		actual, err := client.IncrementWebhookFailures(mockDB, exampleID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 3, actual)
+		assert.Nil(t, mock.ExpectationsWereMet(), "not all database expectations were met")
+	})
+}
+
+func TestResetWebhookFailures(t *testing.T) {
+	t.Parallel()
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+	exampleID := uint64(1)
+	client := NewPostgres()
+
+	t.Run("optimal behavior", func(t *testing.T) {
+		mock.ExpectExec(formatQueryForSQLMock(webhookFailureResetQuery)).
+			WithArgs(exampleID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, client.ResetWebhookFailures(mockDB, exampleID))
+		assert.Nil(t, mock.ExpectationsWereMet(), "not all database expectations were met")
+	})
+}
+
+func TestPauseWebhook(t *testing.T) {
+	t.Parallel()
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+	exampleID := uint64(1)
+	client := NewPostgres()
+
+	t.Run("optimal behavior", func(t *testing.T) {
+		mock.ExpectExec(formatQueryForSQLMock(webhookPauseQuery)).
+			WithArgs(buildTestTime(t), exampleID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, client.PauseWebhook(mockDB, exampleID, buildTestTime(t)))
+		assert.Nil(t, mock.ExpectationsWereMet(), "not all database expectations were met")
+	})
+}
+
 func TestDeleteWebhookByID(t *testing.T) {
 	t.Parallel()
 	mockDB, mock, err := sqlmock.New()
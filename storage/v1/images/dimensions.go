@@ -0,0 +1,30 @@
+package images
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"image"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultMaxPixels is how many pixels an image can have before we refuse to decode it, unless configured
+// otherwise. Decoding allocates memory for every pixel, so a small file that claims to be enormous could
+// otherwise take all of it.
+const DefaultMaxPixels = 50 << 20
+
+// CheckDimensions reads the dimensions of the image at the start of in without consuming it, and returns an
+// error if it has more than maxPixels. The image's header has to fit in in's buffer.
+func CheckDimensions(in *bufio.Reader, maxPixels int64) error {
+	// Peek returns an error when the image is smaller than the buffer, which is fine.
+	head, _ := in.Peek(in.Size())
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(head))
+	if err != nil {
+		return errors.Wrap(err, "error reading image dimensions")
+	}
+	if int64(cfg.Width)*int64(cfg.Height) > maxPixels {
+		return fmt.Errorf("image is %dx%d, which is more than the %d pixels allowed", cfg.Width, cfg.Height, maxPixels)
+	}
+	return nil
+}
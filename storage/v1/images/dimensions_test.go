@@ -0,0 +1,38 @@
+package images
+
+import (
+	"bufio"
+	"bytes"
+	"image"
+	"image/png"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckDimensions(t *testing.T) {
+	t.Parallel()
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, png.Encode(buf, image.NewRGBA(image.Rect(0, 0, 10, 20))))
+	encoded := buf.Bytes()
+
+	t.Run("optimal behavior", func(t *testing.T) {
+		in := bufio.NewReader(bytes.NewReader(encoded))
+		assert.NoError(t, CheckDimensions(in, 200))
+
+		rest, err := ioutil.ReadAll(in)
+		require.NoError(t, err)
+		assert.Equal(t, encoded, rest, "checking shouldn't consume anything")
+	})
+
+	t.Run("with too many pixels", func(t *testing.T) {
+		assert.Error(t, CheckDimensions(bufio.NewReader(bytes.NewReader(encoded)), 199))
+	})
+
+	t.Run("with something that isn't an image", func(t *testing.T) {
+		assert.Error(t, CheckDimensions(bufio.NewReader(bytes.NewReader([]byte("lol"))), 200))
+	})
+}
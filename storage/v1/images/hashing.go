@@ -0,0 +1,80 @@
+package images
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/draw"
+	"math/bits"
+	"strconv"
+
+	"github.com/nfnt/resize"
+)
+
+// ContentHash returns the hex-encoded SHA-256 of an image's pixels. It's computed from the decoded
+// image rather than the uploaded bytes, so the same picture hashes the same regardless of metadata.
+func ContentHash(img image.Image) string {
+	bounds := img.Bounds()
+	nrgba, ok := img.(*image.NRGBA)
+	if !ok || nrgba.Rect.Min != (image.Point{}) || nrgba.Stride != 4*bounds.Dx() {
+		nrgba = image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+		draw.Draw(nrgba, nrgba.Rect, img, bounds.Min, draw.Src)
+	}
+
+	h := sha256.New()
+	size := make([]byte, 8)
+	binary.BigEndian.PutUint32(size[:4], uint32(bounds.Dx()))
+	binary.BigEndian.PutUint32(size[4:], uint32(bounds.Dy()))
+	h.Write(size)
+	h.Write(nrgba.Pix)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// ContentKey is where images with a given content hash are stored, relative to the storer's root.
+// The first two characters get their own directory, so no one directory ends up with every image.
+func ContentKey(contentHash string) string {
+	if len(contentHash) < 2 {
+		return contentHash
+	}
+	return fmt.Sprintf("%s/%s", contentHash[:2], contentHash)
+}
+
+// PerceptualHash is a 64-bit difference hash. Images that look alike have hashes that differ in few bits,
+// even after being resized, recompressed, or slightly color corrected.
+type PerceptualHash uint64
+
+// ComputePerceptualHash shrinks an image to 9x8 grayscale pixels, and sets a bit for every pixel that's
+// brighter than its neighbor to the right.
+func ComputePerceptualHash(img image.Image) PerceptualHash {
+	small := resize.Resize(9, 8, img, resize.Bilinear)
+	gray := image.NewGray(image.Rect(0, 0, 9, 8))
+	draw.Draw(gray, gray.Rect, small, small.Bounds().Min, draw.Src)
+
+	var h PerceptualHash
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			h <<= 1
+			if gray.GrayAt(x, y).Y > gray.GrayAt(x+1, y).Y {
+				h |= 1
+			}
+		}
+	}
+	return h
+}
+
+// ParsePerceptualHash parses the hex string produced by PerceptualHash.String
+func ParsePerceptualHash(s string) (PerceptualHash, error) {
+	h, err := strconv.ParseUint(s, 16, 64)
+	return PerceptualHash(h), err
+}
+
+func (h PerceptualHash) String() string {
+	return fmt.Sprintf("%016x", uint64(h))
+}
+
+// Distance is the number of bits that differ between two hashes. Anything under about 10 is likely the same picture.
+func (h PerceptualHash) Distance(other PerceptualHash) int {
+	return bits.OnesCount64(uint64(h ^ other))
+}
@@ -0,0 +1,80 @@
+package images
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/nfnt/resize"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func buildGradientImage(width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x * 255 / width), G: uint8(y * 255 / height), B: 128, A: 255})
+		}
+	}
+	return img
+}
+
+func TestContentHash(t *testing.T) {
+	t.Parallel()
+
+	img := buildGradientImage(40, 30)
+
+	// the same pixels in a different image type hash the same
+	nrgba := image.NewNRGBA(img.Bounds())
+	for y := 0; y < 30; y++ {
+		for x := 0; x < 40; x++ {
+			nrgba.Set(x, y, img.At(x, y))
+		}
+	}
+	assert.Equal(t, ContentHash(img), ContentHash(nrgba))
+	assert.Len(t, ContentHash(img), 64)
+
+	// as do the same pixels somewhere else
+	assert.Equal(t, ContentHash(img), ContentHash(img.SubImage(img.Bounds())))
+
+	changed := buildGradientImage(40, 30)
+	changed.Set(0, 0, color.Black)
+	assert.NotEqual(t, ContentHash(img), ContentHash(changed))
+
+	// same pixels, different shape
+	assert.NotEqual(t, ContentHash(image.NewRGBA(image.Rect(0, 0, 2, 8))), ContentHash(image.NewRGBA(image.Rect(0, 0, 4, 4))))
+}
+
+func TestContentKey(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "ab/abcdef", ContentKey("abcdef"))
+}
+
+func TestPerceptualHash(t *testing.T) {
+	t.Parallel()
+
+	img := buildGradientImage(400, 300)
+	h := ComputePerceptualHash(img)
+
+	resized := ComputePerceptualHash(resize.Resize(123, 0, img, resize.Lanczos3))
+	assert.True(t, h.Distance(resized) <= 4, "resized image should be a near duplicate, but was %d bits away", h.Distance(resized))
+
+	flipped := image.NewRGBA(img.Bounds())
+	for y := 0; y < 300; y++ {
+		for x := 0; x < 400; x++ {
+			flipped.Set(399-x, y, img.At(x, y))
+		}
+	}
+	other := ComputePerceptualHash(flipped)
+	assert.True(t, h.Distance(other) > 10, "mirrored image should not be a near duplicate, but was %d bits away", h.Distance(other))
+
+	parsed, err := ParsePerceptualHash(h.String())
+	require.NoError(t, err)
+	assert.Equal(t, h, parsed)
+	assert.Len(t, h.String(), 16)
+
+	_, err = ParsePerceptualHash("not hex")
+	assert.Error(t, err)
+}
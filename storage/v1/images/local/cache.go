@@ -0,0 +1,145 @@
+package local
+
+import (
+	"container/list"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// tempFilePrefix marks files that are still being written. Cache keys never start with it.
+const tempFilePrefix = "tmp-"
+
+// diskCache keeps files in a directory, evicting the least recently used ones once their
+// total size exceeds maxSize. Files already in the directory are picked up on creation,
+// oldest first, so the cache survives restarts.
+type diskCache struct {
+	dir     string
+	maxSize int64
+
+	mu      sync.Mutex
+	size    int64
+	order   *list.List // most recently used at the front
+	entries map[string]*list.Element
+}
+
+type diskCacheEntry struct {
+	key  string
+	size int64
+}
+
+func newDiskCache(dir string, maxSize int64) (*diskCache, error) {
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, errors.Wrap(err, "error creating cache directory")
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading cache directory")
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].ModTime().After(files[j].ModTime()) })
+
+	c := &diskCache{
+		dir:     dir,
+		maxSize: maxSize,
+		order:   list.New(),
+		entries: map[string]*list.Element{},
+	}
+	for _, f := range files {
+		if f.IsDir() || strings.HasPrefix(f.Name(), tempFilePrefix) {
+			// leftovers from writes that never finished
+			os.Remove(filepath.Join(dir, f.Name()))
+			continue
+		}
+		c.entries[f.Name()] = c.order.PushBack(&diskCacheEntry{key: f.Name(), size: f.Size()})
+		c.size += f.Size()
+	}
+	c.evict()
+
+	return c, nil
+}
+
+func (c *diskCache) path(key string) string {
+	return filepath.Join(c.dir, key)
+}
+
+// Get opens the cached file for a key, if there is one
+func (c *diskCache) Get(key string) (*os.File, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	f, err := os.Open(c.path(key))
+	if err != nil {
+		// somebody cleaned up after us
+		c.remove(e)
+		return nil, false
+	}
+	c.order.MoveToFront(e)
+	return f, true
+}
+
+// Put stores data under a key. Anything too big to ever fit in the cache isn't stored.
+func (c *diskCache) Put(key string, data []byte) error {
+	size := int64(len(data))
+	if size > c.maxSize {
+		return nil
+	}
+
+	tmp, err := ioutil.TempFile(c.dir, tempFilePrefix)
+	if err != nil {
+		return errors.Wrap(err, "error creating cache file")
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), c.path(key))
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return errors.Wrap(err, "error writing cache file")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.entries[key]; ok {
+		entry := e.Value.(*diskCacheEntry)
+		c.size += size - entry.size
+		entry.size = size
+		c.order.MoveToFront(e)
+	} else {
+		c.entries[key] = c.order.PushFront(&diskCacheEntry{key: key, size: size})
+		c.size += size
+	}
+	c.evict()
+
+	return nil
+}
+
+// evict removes the least recently used files until the cache fits. c.mu must be held, unless nobody else has c yet.
+func (c *diskCache) evict() {
+	for c.size > c.maxSize && c.order.Len() > 0 {
+		e := c.order.Back()
+		os.Remove(c.path(e.Value.(*diskCacheEntry).key))
+		c.remove(e)
+	}
+}
+
+func (c *diskCache) remove(e *list.Element) {
+	entry := e.Value.(*diskCacheEntry)
+	c.order.Remove(e)
+	delete(c.entries, entry.key)
+	c.size -= entry.size
+}
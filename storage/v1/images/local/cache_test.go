@@ -0,0 +1,56 @@
+package local
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiskCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dairycart-cache")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	c, err := newDiskCache(dir, 10)
+	require.NoError(t, err)
+
+	require.NoError(t, c.Put("a", []byte("aaaa")))
+	require.NoError(t, c.Put("b", []byte("bbbb")))
+
+	// reading a makes b the least recently used
+	f, ok := c.Get("a")
+	require.True(t, ok)
+	contents, err := ioutil.ReadAll(f)
+	f.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "aaaa", string(contents))
+
+	require.NoError(t, c.Put("c", []byte("cccc")))
+	_, ok = c.Get("b")
+	assert.False(t, ok, "least recently used entry should be evicted")
+	_, err = os.Stat(filepath.Join(dir, "b"))
+	assert.True(t, os.IsNotExist(err), "evicted entry should be removed from disk")
+
+	t.Run("with entry too big to cache", func(*testing.T) {
+		require.NoError(t, c.Put("d", []byte("ddddddddddd")))
+		_, ok := c.Get("d")
+		assert.False(t, ok)
+	})
+
+	t.Run("reloading from disk", func(*testing.T) {
+		ioutil.WriteFile(filepath.Join(dir, tempFilePrefix+"unfinished"), []byte("x"), 0644)
+
+		reloaded, err := newDiskCache(dir, 10)
+		require.NoError(t, err)
+		assert.Equal(t, int64(8), reloaded.size)
+		_, ok := reloaded.Get("c")
+		assert.True(t, ok)
+
+		_, err = os.Stat(filepath.Join(dir, tempFilePrefix+"unfinished"))
+		assert.True(t, os.IsNotExist(err), "unfinished writes should be cleaned up")
+	})
+}
@@ -3,26 +3,27 @@ package local
 import (
 	"fmt"
 	"image"
-	"image/png"
+	"io"
 	"net"
 	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/dairycart/dairycart/storage/v1/images"
 
 	"github.com/go-chi/chi"
-	"github.com/nfnt/resize"
 	"github.com/pkg/errors"
 	"github.com/spf13/viper"
 )
 
 const (
+	// these are all relative to the `[image_storage]` config section
 	portKey                     = "port"
-	baseURLKey                  = "image_storage.base_url"
-	storageDirKey               = "image_storage.storage_dir"
-	routePrefixKey              = "image_storage.route_prefix"
+	baseURLKey                  = "base_url"
+	storageDirKey               = "storage_dir"
+	routePrefixKey              = "route_prefix"
 	LocalProductImagesDirectory = "product_images"
 )
 
@@ -30,6 +31,9 @@ type localImageStorer struct {
 	BaseURL     string
 	StorageDir  string
 	RoutePrefix string
+	Renditions  images.RenditionSet
+
+	resizer *resizer
 }
 
 var _ images.ImageStorer = (*localImageStorer)(nil)
@@ -39,15 +43,17 @@ func NewLocalImageStorer() *localImageStorer {
 		BaseURL:     "http://localhost:4321",
 		StorageDir:  LocalProductImagesDirectory,
 		RoutePrefix: LocalProductImagesDirectory,
+		Renditions:  images.DefaultRenditionSet(),
 	}
 }
 
-func saveImage(in image.Image, path string) error {
+func saveImage(in image.Image, path string, sourceFormat string, rendition images.Rendition) error {
 	f, err := os.Create(path)
 	if err != nil {
 		return errors.Wrap(err, "error creating local file")
 	}
-	return png.Encode(f, in)
+	defer f.Close()
+	return rendition.Encode(f, in, sourceFormat)
 }
 
 func ensureDomainHasNoPort(domain string) (string, error) {
@@ -100,13 +106,20 @@ func (l *localImageStorer) Init(cfg *viper.Viper, router chi.Router) error {
 		l.BaseURL = fmt.Sprintf("%s:%d", domain, port)
 	}
 
+	l.Renditions, err = images.LoadRenditionSet(cfg)
+	if err != nil {
+		return errors.Wrap(err, "error parsing image rendition config")
+	}
+
 	storageDir := cfg.GetString(storageDirKey)
 	if storageDir != "" {
 		l.StorageDir = storageDir
 	}
 
 	if _, err := os.Stat(l.StorageDir); os.IsNotExist(err) {
-		return os.MkdirAll(l.StorageDir, os.ModePerm)
+		if err = os.MkdirAll(l.StorageDir, os.ModePerm); err != nil {
+			return errors.Wrap(err, "error creating image storage directory")
+		}
 	}
 
 	routePrefix := cfg.GetString(routePrefixKey)
@@ -114,20 +127,25 @@ func (l *localImageStorer) Init(cfg *viper.Viper, router chi.Router) error {
 		l.RoutePrefix = l.StorageDir
 	}
 
+	if cfg.GetBool(resizeEnabledKey) {
+		l.resizer, err = newResizer(cfg, l.StorageDir)
+		if err != nil {
+			return errors.Wrap(err, "error configuring image resizing")
+		}
+		// chi tries this before the file server's wildcard route, and falls through to it when the last segment isn't a size
+		router.Get(fmt.Sprintf("/{dir}/{key}/{variant:%s}", resizeVariantPattern), l.resizer.ServeHTTP)
+	}
+
 	fileServer(router, "/", http.Dir(l.StorageDir))
 	return nil
 }
 
 func (l *localImageStorer) CreateThumbnails(in image.Image) images.ProductImageSet {
-	return images.ProductImageSet{
-		Thumbnail: resize.Thumbnail(100, 100, in, resize.NearestNeighbor),
-		Main:      resize.Thumbnail(500, 500, in, resize.NearestNeighbor),
-		Original:  in,
-	}
+	return l.Renditions.Create(in)
 }
 
-func (l *localImageStorer) StoreImages(in images.ProductImageSet, sku string, id uint) (*images.ProductImageLocations, error) {
-	photoDir := fmt.Sprintf("%s/%s/%d", l.StorageDir, sku, id)
+func (l *localImageStorer) StoreImages(in images.ProductImageSet, key string) (images.ProductImageLocations, error) {
+	photoDir := fmt.Sprintf("%s/%s", l.StorageDir, key)
 
 	var err error
 	if _, err = os.Stat(photoDir); os.IsNotExist(err) {
@@ -136,28 +154,106 @@ func (l *localImageStorer) StoreImages(in images.ProductImageSet, sku string, id
 			return nil, errors.Wrap(err, "error creating necessary folders")
 		}
 	}
-	out := &images.ProductImageLocations{}
+	out := images.ProductImageLocations{}
 
-	thumbnailPath := fmt.Sprintf("%s/thumbnail.png", photoDir)
-	err = saveImage(in.Thumbnail, thumbnailPath)
-	if err != nil {
-		return nil, err
+	for _, rendition := range l.Renditions {
+		img, ok := in.Renditions[rendition.Name]
+		if !ok {
+			continue
+		}
+
+		path := fmt.Sprintf("%s/%s.%s", photoDir, rendition.Name, rendition.Extension(in.Format))
+		if err = saveImage(img, path, in.Format, rendition); err != nil {
+			return out, err
+		}
+		out[rendition.Name] = fmt.Sprintf("%s/%s", l.BaseURL, path)
+	}
+
+	return out, nil
+}
+
+func (l *localImageStorer) pathFromURL(u string) (string, error) {
+	path := strings.TrimPrefix(u, fmt.Sprintf("%s/", l.BaseURL))
+	if path == u || !strings.HasPrefix(filepath.Clean(path), filepath.Clean(l.StorageDir)+string(filepath.Separator)) {
+		return "", fmt.Errorf("image location %s is not managed by this image storer", u)
+	}
+	return path, nil
+}
+
+func (l *localImageStorer) DeleteImages(in images.ProductImageLocations) error {
+	var photoDir string
+	for _, u := range in {
+		if u == "" {
+			continue
+		}
+
+		path, err := l.pathFromURL(u)
+		if err != nil {
+			return err
+		}
+		photoDir = filepath.Dir(path)
+
+		if err = os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return errors.Wrap(err, "error deleting local file")
+		}
+	}
+
+	if photoDir != "" {
+		// this will fail if the folder isn't empty, which is fine
+		os.Remove(photoDir)
 	}
-	out.Thumbnail = fmt.Sprintf("%s/%s", l.BaseURL, thumbnailPath)
+	return nil
+}
 
-	mainPath := fmt.Sprintf("%s/main.png", photoDir)
-	err = saveImage(in.Main, mainPath)
+func (l *localImageStorer) ReadImage(location string) (io.ReadCloser, error) {
+	path, err := l.pathFromURL(location)
 	if err != nil {
-		return out, err
+		return nil, err
 	}
-	out.Main = fmt.Sprintf("%s/%s", l.BaseURL, mainPath)
+	f, err := os.Open(path)
+	return f, errors.Wrap(err, "error opening local file")
+}
+
+func (l *localImageStorer) ListImages() ([]images.StoredImage, error) {
+	var out []images.StoredImage
+	err := filepath.Walk(l.StorageDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(l.StorageDir, path)
+		if err != nil {
+			return err
+		}
+		out = append(out, images.StoredImage{
+			// this needs to match what StoreImages returns exactly, or everything will look orphaned
+			Location:   fmt.Sprintf("%s/%s/%s", l.BaseURL, l.StorageDir, filepath.ToSlash(rel)),
+			Size:       info.Size(),
+			ModifiedOn: info.ModTime(),
+		})
+		return nil
+	})
+	return out, errors.Wrap(err, "error listing local files")
+}
 
-	originalPath := fmt.Sprintf("%s/original.png", photoDir)
-	err = saveImage(in.Original, originalPath)
+func (l *localImageStorer) DeleteImage(location string) error {
+	path, err := l.pathFromURL(location)
 	if err != nil {
-		return out, err
+		return err
+	}
+	if err = os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "error deleting local file")
 	}
-	out.Original = fmt.Sprintf("%s/%s", l.BaseURL, originalPath)
 
-	return out, nil
+	// clean up the folders StoreImages made, stopping at the first one with anything left in it
+	root := filepath.Clean(l.StorageDir)
+	for dir := filepath.Dir(path); strings.HasPrefix(filepath.Clean(dir), root+string(filepath.Separator)); dir = filepath.Dir(dir) {
+		if os.Remove(dir) != nil {
+			break
+		}
+	}
+	return nil
 }
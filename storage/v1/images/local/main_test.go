@@ -1,9 +1,164 @@
 package local
 
 import (
+	"fmt"
+	"image"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+
+	_ "image/gif"
+	_ "image/jpeg"
+
+	"github.com/dairycart/dairycart/storage/v1/images"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
-func TestUnimplemented(t *testing.T) {
-	t.Skip("TODO: test me")
+func TestDeleteImages(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dairycart-images")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	l := &localImageStorer{
+		BaseURL:    "http://localhost:4321",
+		StorageDir: filepath.Join(dir, LocalProductImagesDirectory),
+		Renditions: images.DefaultRenditionSet(),
+	}
+
+	t.Run("optimal behavior", func(*testing.T) {
+		img := image.NewRGBA(image.Rect(0, 0, 10, 10))
+		locations, err := l.StoreImages(l.CreateThumbnails(img), "example/0")
+		require.NoError(t, err)
+
+		err = l.DeleteImages(locations)
+		assert.NoError(t, err)
+
+		_, err = os.Stat(filepath.Join(l.StorageDir, "example", "0"))
+		assert.True(t, os.IsNotExist(err), "image directory should be removed")
+	})
+
+	t.Run("with already deleted images", func(*testing.T) {
+		locations := images.ProductImageLocations{
+			images.ThumbnailRendition: fmt.Sprintf("%s/%s/example/1/thumbnail.png", l.BaseURL, l.StorageDir),
+		}
+		assert.NoError(t, l.DeleteImages(locations))
+	})
+
+	t.Run("with foreign location", func(*testing.T) {
+		locations := images.ProductImageLocations{
+			images.ThumbnailRendition: "https://example.com/some/other/place.png",
+		}
+		assert.Error(t, l.DeleteImages(locations))
+	})
+
+	t.Run("with path outside storage directory", func(*testing.T) {
+		locations := images.ProductImageLocations{
+			images.ThumbnailRendition: fmt.Sprintf("%s/%s/../../etc/passwd", l.BaseURL, l.StorageDir),
+		}
+		assert.Error(t, l.DeleteImages(locations))
+	})
+}
+
+func TestListAndDeleteImage(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dairycart-images")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	l := &localImageStorer{
+		BaseURL:    "http://localhost:4321",
+		StorageDir: filepath.Join(dir, LocalProductImagesDirectory),
+		Renditions: images.DefaultRenditionSet(),
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	imgset := l.CreateThumbnails(img)
+	imgset.Format = images.PNG
+	first, err := l.StoreImages(imgset, "example/0")
+	require.NoError(t, err)
+	second, err := l.StoreImages(imgset, images.ContentKey(images.ContentHash(img)))
+	require.NoError(t, err)
+
+	t.Run("listing", func(*testing.T) {
+		stored, err := l.ListImages()
+		require.NoError(t, err)
+
+		var listed []string
+		for _, s := range stored {
+			assert.NotZero(t, s.Size)
+			assert.False(t, s.ModifiedOn.IsZero())
+			listed = append(listed, s.Location)
+		}
+
+		var expected []string
+		for _, locations := range []images.ProductImageLocations{first, second} {
+			for _, location := range locations {
+				expected = append(expected, location)
+			}
+		}
+		assert.ElementsMatch(t, expected, listed)
+	})
+
+	t.Run("deleting", func(*testing.T) {
+		for _, location := range second {
+			require.NoError(t, l.DeleteImage(location))
+		}
+
+		// both levels of the content addressed folder should be gone, but not the storage directory itself
+		_, err = os.Stat(filepath.Join(l.StorageDir, images.ContentHash(img)[:2]))
+		assert.True(t, os.IsNotExist(err), "image directory should be removed")
+		_, err = os.Stat(l.StorageDir)
+		assert.NoError(t, err)
+
+		stored, err := l.ListImages()
+		require.NoError(t, err)
+		assert.Len(t, stored, len(first))
+	})
+
+	t.Run("with path outside storage directory", func(*testing.T) {
+		assert.Error(t, l.DeleteImage(fmt.Sprintf("%s/%s/../../etc/passwd", l.BaseURL, l.StorageDir)))
+	})
+}
+
+func TestStoreImagesWithRenditionConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dairycart-images")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	l := &localImageStorer{
+		BaseURL:    "http://localhost:4321",
+		StorageDir: filepath.Join(dir, LocalProductImagesDirectory),
+		Renditions: images.RenditionSet{
+			{Name: "original"},
+			{Name: "square", MaxWidth: 4, MaxHeight: 4, Mode: images.CropMode},
+			{Name: images.ThumbnailRendition, MaxWidth: 5, MaxHeight: 5, Format: images.JPEG, Quality: 50},
+		},
+	}
+
+	imgset := l.CreateThumbnails(image.NewRGBA(image.Rect(0, 0, 10, 6)))
+	imgset.Format = images.GIF
+	locations, err := l.StoreImages(imgset, "example/1")
+	require.NoError(t, err)
+
+	assert.Len(t, locations, 3)
+	assert.True(t, strings.HasSuffix(locations[images.ThumbnailRendition], "/thumbnail.jpg"))
+	assert.True(t, strings.HasSuffix(locations["square"], "/square.gif"))
+	assert.True(t, strings.HasSuffix(locations[images.OriginalRendition], "/original.gif"))
+
+	rc, err := l.ReadImage(locations["square"])
+	require.NoError(t, err)
+	square, _, err := image.Decode(rc)
+	rc.Close()
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 4, 4), square.Bounds().Sub(square.Bounds().Min))
+
+	f, err := os.Open(filepath.Join(l.StorageDir, "example", "1", "thumbnail.jpg"))
+	require.NoError(t, err)
+	defer f.Close()
+	_, format, err := image.Decode(f)
+	assert.NoError(t, err)
+	assert.Equal(t, images.JPEG, format)
 }
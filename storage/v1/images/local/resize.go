@@ -0,0 +1,248 @@
+package local
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+
+	"github.com/dairycart/dairycart/storage/v1/images"
+
+	"github.com/go-chi/chi"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+	_ "golang.org/x/image/webp"
+)
+
+const (
+	// these are all relative to the `[image_storage]` config section
+	resizeEnabledKey      = "resize.enabled"
+	resizeCacheDirKey     = "resize.cache_dir"
+	resizeCacheSizeKey    = "resize.cache_size"
+	resizeSigningKeyKey   = "resize.signing_key"
+	resizeAllowedSizesKey = "resize.allowed_sizes"
+	resizeMaxDimensionKey = "resize.max_dimension"
+	resizeMaxPixelsKey    = "resize.max_pixels"
+	resizeMaxAgeKey       = "resize.max_age"
+
+	defaultResizeCacheDir     = "product_image_cache"
+	defaultResizeCacheSize    = 256 << 20 // 256 MB
+	defaultResizeMaxDimension = 2000
+	defaultResizeMaxAge       = 24 * time.Hour
+
+	// originalPeekSize is how much of an original is read ahead to find its dimensions
+	originalPeekSize = 128 << 10
+
+	// ResizeSignatureParam is the query parameter resize URLs carry their signature in
+	ResizeSignatureParam = "sig"
+
+	resizeVariantPattern = `[0-9]+x[0-9]+\.(?:png|jpg|jpeg|gif)`
+)
+
+var (
+	resizeVariantRegexp = regexp.MustCompile(`^([0-9]{1,5})x([0-9]{1,5})\.(png|jpg|jpeg|gif)$`)
+	// images are either stored under `{sku}/{id}`, from before they were content addressed, or `{prefix}/{hash}`
+	imageKeyRegexp = regexp.MustCompile(`^(?:[0-9]+|[0-9a-f]{64})$`)
+)
+
+// SignResizePath returns the signature for a resize request, where path looks like `{prefix}/{hash}/{w}x{h}.{fmt}`.
+// Storefronts that need sizes outside the allowlist add it to the image URL as the `sig` query parameter.
+func SignResizePath(key, path string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(path))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// resizer serves resized versions of stored originals, at whatever size the URL asks for. Since that
+// could otherwise be used to make us burn CPU and disk on endless sizes, requests must either be for
+// an allowlisted size or carry a valid signature.
+type resizer struct {
+	storageDir   string
+	signingKey   string
+	allowedSizes map[string]bool
+	maxDimension uint
+	maxPixels    int64
+	maxAge       time.Duration
+
+	cache *diskCache
+	// sem limits how many images get resized at once
+	sem chan struct{}
+}
+
+func newResizer(cfg *viper.Viper, storageDir string) (*resizer, error) {
+	r := &resizer{
+		storageDir:   storageDir,
+		signingKey:   cfg.GetString(resizeSigningKeyKey),
+		allowedSizes: map[string]bool{},
+		maxDimension: defaultResizeMaxDimension,
+		maxPixels:    images.DefaultMaxPixels,
+		maxAge:       defaultResizeMaxAge,
+		sem:          make(chan struct{}, runtime.NumCPU()),
+	}
+
+	for _, size := range cfg.GetStringSlice(resizeAllowedSizesKey) {
+		r.allowedSizes[strings.ToLower(size)] = true
+	}
+	if r.signingKey == "" && len(r.allowedSizes) == 0 {
+		return nil, errors.New("resizing requires a signing key, a list of allowed sizes, or both")
+	}
+
+	if cfg.IsSet(resizeMaxDimensionKey) {
+		r.maxDimension = uint(cfg.GetInt(resizeMaxDimensionKey))
+	}
+	if cfg.IsSet(resizeMaxPixelsKey) {
+		r.maxPixels = cfg.GetInt64(resizeMaxPixelsKey)
+	}
+	if cfg.IsSet(resizeMaxAgeKey) {
+		r.maxAge = cfg.GetDuration(resizeMaxAgeKey)
+	}
+
+	cacheDir := cfg.GetString(resizeCacheDirKey)
+	if cacheDir == "" {
+		cacheDir = defaultResizeCacheDir
+	}
+	cacheSize := cfg.GetInt64(resizeCacheSizeKey)
+	if cacheSize <= 0 {
+		cacheSize = defaultResizeCacheSize
+	}
+
+	var err error
+	r.cache, err = newDiskCache(cacheDir, cacheSize)
+	return r, err
+}
+
+func (r *resizer) authorized(req *http.Request, relativePath string, width, height uint64) bool {
+	if r.allowedSizes[fmt.Sprintf("%dx%d", width, height)] {
+		return true
+	}
+	if r.signingKey == "" {
+		return false
+	}
+	sig := req.URL.Query().Get(ResizeSignatureParam)
+	return hmac.Equal([]byte(sig), []byte(SignResizePath(r.signingKey, relativePath)))
+}
+
+// validImageDirectory reports whether dir is a single path segment that stays inside the storage directory
+func validImageDirectory(dir string) bool {
+	return dir != "" && dir != "." && dir != ".." && !strings.ContainsAny(dir, `/\`)
+}
+
+// findOriginal returns the path of the stored original for an image
+func (r *resizer) findOriginal(dir, key string) (string, os.FileInfo, error) {
+	matches, err := filepath.Glob(filepath.Join(r.storageDir, dir, key, fmt.Sprintf("%s.*", images.OriginalRendition)))
+	if err != nil || len(matches) == 0 {
+		return "", nil, os.ErrNotExist
+	}
+	info, err := os.Stat(matches[0])
+	return matches[0], info, err
+}
+
+func (r *resizer) ServeHTTP(res http.ResponseWriter, req *http.Request) {
+	dir, key := chi.URLParam(req, "dir"), chi.URLParam(req, "key")
+	variant := chi.URLParam(req, "variant")
+
+	match := resizeVariantRegexp.FindStringSubmatch(variant)
+	if match == nil || !validImageDirectory(dir) || !imageKeyRegexp.MatchString(key) {
+		http.NotFound(res, req)
+		return
+	}
+	width, _ := strconv.ParseUint(match[1], 10, 64)
+	height, _ := strconv.ParseUint(match[2], 10, 64)
+	format := match[3]
+	if format == "jpg" {
+		format = images.JPEG
+	}
+
+	relativePath := fmt.Sprintf("%s/%s/%s", dir, key, variant)
+	if !r.authorized(req, relativePath, width, height) {
+		http.Error(res, "image size not allowed", http.StatusForbidden)
+		return
+	}
+	if (width == 0 && height == 0) || width > uint64(r.maxDimension) || height > uint64(r.maxDimension) {
+		http.Error(res, "invalid image size", http.StatusBadRequest)
+		return
+	}
+
+	originalPath, original, err := r.findOriginal(dir, key)
+	if err != nil {
+		http.NotFound(res, req)
+		return
+	}
+
+	// replacing an image rewrites its original, which changes the key, so stale variants just age out of the cache
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s:%d:%d", relativePath, original.ModTime().UnixNano(), original.Size())))
+	cacheKey := hex.EncodeToString(sum[:])
+
+	res.Header().Set("ETag", fmt.Sprintf(`"%s"`, cacheKey[:32]))
+	res.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int64(r.maxAge/time.Second)))
+	res.Header().Set("Content-Type", fmt.Sprintf("image/%s", format))
+
+	if f, ok := r.cache.Get(cacheKey); ok {
+		defer f.Close()
+		http.ServeContent(res, req, variant, original.ModTime(), f)
+		return
+	}
+
+	// let browsers revalidate without us doing any resizing
+	if match := req.Header.Get("If-None-Match"); match != "" && match == res.Header().Get("ETag") {
+		res.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	data, err := r.resize(originalPath, images.Rendition{
+		Name:          variant,
+		MaxWidth:      uint(width),
+		MaxHeight:     uint(height),
+		Mode:          images.FitMode,
+		Interpolation: images.DefaultInterpolation,
+		Format:        format,
+	})
+	if err != nil {
+		http.Error(res, "error resizing image", http.StatusInternalServerError)
+		return
+	}
+
+	// a failure to cache doesn't stop us from serving what we made
+	r.cache.Put(cacheKey, data)
+	http.ServeContent(res, req, variant, original.ModTime(), bytes.NewReader(data))
+}
+
+func (r *resizer) resize(originalPath string, rendition images.Rendition) ([]byte, error) {
+	r.sem <- struct{}{}
+	defer func() { <-r.sem }()
+
+	f, err := os.Open(originalPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "error opening original image")
+	}
+	defer f.Close()
+
+	in := bufio.NewReaderSize(f, originalPeekSize)
+	if err = images.CheckDimensions(in, r.maxPixels); err != nil {
+		return nil, errors.Wrap(err, "error checking original image")
+	}
+
+	img, sourceFormat, err := image.Decode(in)
+	if err != nil {
+		return nil, errors.Wrap(err, "error decoding original image")
+	}
+
+	buf := &bytes.Buffer{}
+	err = rendition.Encode(buf, rendition.Apply(img), sourceFormat)
+	return buf.Bytes(), err
+}
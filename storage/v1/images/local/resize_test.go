@@ -0,0 +1,142 @@
+package local
+
+import (
+	"fmt"
+	"image"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dairycart/dairycart/storage/v1/images"
+
+	"github.com/go-chi/chi"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResizeHandler(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dairycart-images")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	cfg := viper.New()
+	cfg.Set(storageDirKey, filepath.Join(dir, LocalProductImagesDirectory))
+	cfg.Set(resizeEnabledKey, true)
+	cfg.Set(resizeCacheDirKey, filepath.Join(dir, "cache"))
+	cfg.Set(resizeSigningKeyKey, "secret")
+	cfg.Set(resizeAllowedSizesKey, []string{"50x50"})
+	cfg.Set(resizeMaxDimensionKey, 500)
+
+	l := NewLocalImageStorer()
+	router := chi.NewRouter()
+	router.Route("/product_images", func(r chi.Router) {
+		require.NoError(t, l.Init(cfg, r))
+	})
+
+	imgset := l.CreateThumbnails(image.NewRGBA(image.Rect(0, 0, 200, 100)))
+	imgset.Format = images.PNG
+	_, err = l.StoreImages(imgset, "skate/12")
+	require.NoError(t, err)
+	hash := images.ContentHash(imgset.Renditions[images.OriginalRendition])
+	_, err = l.StoreImages(imgset, images.ContentKey(hash))
+	require.NoError(t, err)
+
+	get := func(path string, headers ...string) *httptest.ResponseRecorder {
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		for i := 0; i+1 < len(headers); i += 2 {
+			req.Header.Set(headers[i], headers[i+1])
+		}
+		router.ServeHTTP(res, req)
+		return res
+	}
+
+	t.Run("optimal conditions", func(*testing.T) {
+		res := get("/product_images/skate/12/50x50.jpg")
+		require.Equal(t, http.StatusOK, res.Code)
+		assert.Equal(t, "image/jpeg", res.Header().Get("Content-Type"))
+		assert.Equal(t, "public, max-age=86400", res.Header().Get("Cache-Control"))
+		assert.NotEmpty(t, res.Header().Get("ETag"))
+
+		img, format, err := image.Decode(res.Body)
+		require.NoError(t, err)
+		assert.Equal(t, images.JPEG, format)
+		assert.Equal(t, image.Rect(0, 0, 50, 25), img.Bounds())
+
+		// the second time around comes from the cache
+		cached := get("/product_images/skate/12/50x50.jpg")
+		assert.Equal(t, http.StatusOK, cached.Code)
+		assert.Equal(t, res.Header().Get("ETag"), cached.Header().Get("ETag"))
+		assert.Equal(t, 1, l.resizer.cache.order.Len())
+
+		notModified := get("/product_images/skate/12/50x50.jpg", "If-None-Match", res.Header().Get("ETag"))
+		assert.Equal(t, http.StatusNotModified, notModified.Code)
+	})
+
+	t.Run("with content addressed image", func(*testing.T) {
+		res := get(fmt.Sprintf("/product_images/%s/50x50.png", images.ContentKey(hash)))
+		assert.Equal(t, http.StatusOK, res.Code)
+	})
+
+	t.Run("with invalid image key", func(*testing.T) {
+		assert.Equal(t, http.StatusNotFound, get("/product_images/skate/abc/50x50.png").Code)
+	})
+
+	t.Run("with signed size", func(*testing.T) {
+		path := fmt.Sprintf("/product_images/skate/12/120x0.png?%s=%s", ResizeSignatureParam, SignResizePath("secret", "skate/12/120x0.png"))
+		res := get(path)
+		require.Equal(t, http.StatusOK, res.Code)
+
+		img, _, err := image.Decode(res.Body)
+		require.NoError(t, err)
+		assert.Equal(t, image.Rect(0, 0, 120, 60), img.Bounds())
+	})
+
+	t.Run("with unsigned size", func(*testing.T) {
+		assert.Equal(t, http.StatusForbidden, get("/product_images/skate/12/51x51.png").Code)
+	})
+
+	t.Run("with invalid signature", func(*testing.T) {
+		assert.Equal(t, http.StatusForbidden, get("/product_images/skate/12/51x51.png?sig=nope").Code)
+	})
+
+	t.Run("with oversized dimensions", func(*testing.T) {
+		path := fmt.Sprintf("/product_images/skate/12/501x10.png?%s=%s", ResizeSignatureParam, SignResizePath("secret", "skate/12/501x10.png"))
+		assert.Equal(t, http.StatusBadRequest, get(path).Code)
+	})
+
+	t.Run("with original that has too many pixels", func(*testing.T) {
+		l.resizer.maxPixels = 200*100 - 1
+		defer func() { l.resizer.maxPixels = images.DefaultMaxPixels }()
+
+		// a size nothing else asked for, so it can't come from the cache
+		path := fmt.Sprintf("/product_images/skate/12/40x40.png?%s=%s", ResizeSignatureParam, SignResizePath("secret", "skate/12/40x40.png"))
+		assert.Equal(t, http.StatusInternalServerError, get(path).Code)
+	})
+
+	t.Run("with nonexistent image", func(*testing.T) {
+		assert.Equal(t, http.StatusNotFound, get("/product_images/skate/13/50x50.png").Code)
+	})
+
+	t.Run("with non-size file name", func(*testing.T) {
+		// this falls through to the file server, rather than being treated as a resize request
+		assert.NotEqual(t, http.StatusForbidden, get("/product_images/skate/12/original.png").Code)
+	})
+}
+
+func TestInitWithResizingAndNoRestrictions(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dairycart-images")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	cfg := viper.New()
+	cfg.Set(storageDirKey, filepath.Join(dir, LocalProductImagesDirectory))
+	cfg.Set(resizeEnabledKey, true)
+	cfg.Set(resizeCacheDirKey, filepath.Join(dir, "cache"))
+
+	assert.Error(t, NewLocalImageStorer().Init(cfg, chi.NewRouter()))
+}
@@ -2,25 +2,64 @@ package images
 
 import (
 	"image"
+	"io"
+	"time"
 
 	"github.com/go-chi/chi"
 	"github.com/spf13/viper"
 )
 
 type ProductImageSet struct {
-	Thumbnail image.Image
-	Main      image.Image
-	Original  image.Image
+	// Renditions maps rendition names to the resized images
+	Renditions map[string]image.Image
+
+	// Format is the format the original image was uploaded in (png, jpeg, gif, or webp)
+	Format string
+}
+
+// ProductImageLocations maps rendition names to the URLs they're stored at
+type ProductImageLocations map[string]string
+
+// Difference returns the locations that aren't also found in other, regardless of which rendition they belong to
+func (l ProductImageLocations) Difference(other ProductImageLocations) ProductImageLocations {
+	current := map[string]bool{}
+	for _, location := range other {
+		current[location] = true
+	}
+
+	out := ProductImageLocations{}
+	for name, location := range l {
+		if location != "" && !current[location] {
+			out[name] = location
+		}
+	}
+	return out
+}
+
+// StoredImage describes a file an ImageStorer is holding onto, whether or not anything refers to it
+type StoredImage struct {
+	Location   string    `json:"location"`
+	Size       int64     `json:"size"`
+	ModifiedOn time.Time `json:"modified_on"`
 }
 
-type ProductImageLocations struct {
-	Thumbnail string
-	Main      string
-	Original  string
+// DefaultRouteMountPath is where the routes an ImageStorer registers in Init are served from
+const DefaultRouteMountPath = "/product_images"
+
+// RouteMounter is implemented by image storers whose routes are served from a configurable path. RouteMountPath
+// is only called after Init, so it can depend on the storer's config.
+type RouteMounter interface {
+	RouteMountPath() string
 }
 
 type ImageStorer interface {
 	Init(config *viper.Viper, router chi.Router) error
 	CreateThumbnails(img image.Image) ProductImageSet
-	StoreImages(imgset ProductImageSet, sku string, id uint) (*ProductImageLocations, error)
+	// StoreImages saves every rendition in a set under key, which is a relative path like the ones ContentKey returns
+	StoreImages(imgset ProductImageSet, key string) (ProductImageLocations, error)
+	DeleteImages(locations ProductImageLocations) error
+	ReadImage(location string) (io.ReadCloser, error)
+	// ListImages returns every file in storage, with locations in the same form StoreImages returns them
+	ListImages() ([]StoredImage, error)
+	DeleteImage(location string) error
 }
@@ -2,6 +2,7 @@ package imgmock
 
 import (
 	"image"
+	"io"
 
 	"github.com/dairycart/dairycart/storage/v1/images"
 
@@ -27,7 +28,27 @@ func (m *MockImageStorer) CreateThumbnails(in image.Image) images.ProductImageSe
 	return args.Get(0).(images.ProductImageSet)
 }
 
-func (m *MockImageStorer) StoreImages(in images.ProductImageSet, sku string, id uint) (*images.ProductImageLocations, error) {
-	args := m.Called(in, sku, id)
-	return args.Get(0).(*images.ProductImageLocations), args.Error(1)
+func (m *MockImageStorer) StoreImages(in images.ProductImageSet, key string) (images.ProductImageLocations, error) {
+	args := m.Called(in, key)
+	return args.Get(0).(images.ProductImageLocations), args.Error(1)
+}
+
+func (m *MockImageStorer) DeleteImages(locations images.ProductImageLocations) error {
+	args := m.Called(locations)
+	return args.Error(0)
+}
+
+func (m *MockImageStorer) ReadImage(location string) (io.ReadCloser, error) {
+	args := m.Called(location)
+	return args.Get(0).(io.ReadCloser), args.Error(1)
+}
+
+func (m *MockImageStorer) ListImages() ([]images.StoredImage, error) {
+	args := m.Called()
+	return args.Get(0).([]images.StoredImage), args.Error(1)
+}
+
+func (m *MockImageStorer) DeleteImage(location string) error {
+	args := m.Called(location)
+	return args.Error(0)
 }
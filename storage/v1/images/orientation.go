@@ -0,0 +1,82 @@
+package images
+
+import (
+	"image"
+	"io"
+
+	"github.com/rwcarlsen/goexif/exif"
+)
+
+// EXIF orientation values, see http://sylvana.net/jpegcrop/exif_orientation.html
+const (
+	orientationNormal     = 1
+	orientationFlipH      = 2
+	orientationRotate180  = 3
+	orientationFlipV      = 4
+	orientationTranspose  = 5
+	orientationRotate90   = 6
+	orientationTransverse = 7
+	orientationRotate270  = 8
+)
+
+// ReadOrientation returns the EXIF orientation of a JPEG, or 1 (normal) if it doesn't have one
+func ReadOrientation(in io.Reader) int {
+	x, err := exif.Decode(in)
+	if err != nil {
+		return orientationNormal
+	}
+
+	tag, err := x.Get(exif.Orientation)
+	if err != nil {
+		return orientationNormal
+	}
+
+	orientation, err := tag.Int(0)
+	if err != nil || orientation < orientationNormal || orientation > orientationRotate270 {
+		return orientationNormal
+	}
+	return orientation
+}
+
+// ApplyOrientation returns a copy of img transformed so that it displays upright, given its EXIF orientation
+func ApplyOrientation(img image.Image, orientation int) image.Image {
+	if orientation <= orientationNormal || orientation > orientationRotate270 {
+		return img
+	}
+
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+
+	// the last four orientations swap width and height
+	var out *image.NRGBA
+	if orientation >= orientationTranspose {
+		out = image.NewNRGBA(image.Rect(0, 0, h, w))
+	} else {
+		out = image.NewNRGBA(image.Rect(0, 0, w, h))
+	}
+
+	ob := out.Bounds()
+	for y := 0; y < ob.Dy(); y++ {
+		for x := 0; x < ob.Dx(); x++ {
+			var sx, sy int
+			switch orientation {
+			case orientationFlipH:
+				sx, sy = w-1-x, y
+			case orientationRotate180:
+				sx, sy = w-1-x, h-1-y
+			case orientationFlipV:
+				sx, sy = x, h-1-y
+			case orientationTranspose:
+				sx, sy = y, x
+			case orientationRotate90:
+				sx, sy = y, h-1-x
+			case orientationTransverse:
+				sx, sy = w-1-y, h-1-x
+			case orientationRotate270:
+				sx, sy = w-1-y, x
+			}
+			out.Set(x, y, img.At(b.Min.X+sx, b.Min.Y+sy))
+		}
+	}
+	return out
+}
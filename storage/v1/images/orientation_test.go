@@ -0,0 +1,88 @@
+package images
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+
+	_ "image/gif"
+	_ "image/png"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// buildTestJPEGWithOrientation encodes img as a JPEG with an EXIF APP1 segment carrying the given orientation
+func buildTestJPEGWithOrientation(t *testing.T, img image.Image, orientation uint16) []byte {
+	t.Helper()
+	encoded := &bytes.Buffer{}
+	require.NoError(t, jpeg.Encode(encoded, img, nil))
+
+	tiff := &bytes.Buffer{}
+	tiff.WriteString("MM\x00\x2a")
+	binary.Write(tiff, binary.BigEndian, uint32(8))      // offset of the first IFD
+	binary.Write(tiff, binary.BigEndian, uint16(1))      // one entry
+	binary.Write(tiff, binary.BigEndian, uint16(0x0112)) // orientation tag
+	binary.Write(tiff, binary.BigEndian, uint16(3))      // SHORT
+	binary.Write(tiff, binary.BigEndian, uint32(1))      // one value
+	binary.Write(tiff, binary.BigEndian, orientation)    // the value
+	binary.Write(tiff, binary.BigEndian, uint16(0))      // padding
+	binary.Write(tiff, binary.BigEndian, uint32(0))      // no next IFD
+	payload := append([]byte("Exif\x00\x00"), tiff.Bytes()...)
+
+	out := &bytes.Buffer{}
+	out.Write(encoded.Bytes()[:2]) // SOI
+	out.Write([]byte{0xFF, 0xE1})
+	binary.Write(out, binary.BigEndian, uint16(len(payload)+2))
+	out.Write(payload)
+	out.Write(encoded.Bytes()[2:])
+	return out.Bytes()
+}
+
+func TestReadOrientation(t *testing.T) {
+	t.Parallel()
+
+	img := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	assert.Equal(t, 6, ReadOrientation(bytes.NewReader(buildTestJPEGWithOrientation(t, img, 6))))
+
+	plain := &bytes.Buffer{}
+	require.NoError(t, jpeg.Encode(plain, img, nil))
+	assert.Equal(t, 1, ReadOrientation(plain))
+}
+
+func TestApplyOrientation(t *testing.T) {
+	t.Parallel()
+
+	// a 2x1 image, red on the left and blue on the right
+	red := color.NRGBA{R: 255, A: 255}
+	blue := color.NRGBA{B: 255, A: 255}
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	img.Set(0, 0, red)
+	img.Set(1, 0, blue)
+
+	testCases := []struct {
+		orientation int
+		width       int
+		height      int
+		first       color.NRGBA // the pixel at (0, 0) afterwards
+	}{
+		{orientationNormal, 2, 1, red},
+		{orientationFlipH, 2, 1, blue},
+		{orientationRotate180, 2, 1, blue},
+		{orientationFlipV, 2, 1, red},
+		{orientationTranspose, 1, 2, red},
+		{orientationRotate90, 1, 2, red},
+		{orientationTransverse, 1, 2, blue},
+		{orientationRotate270, 1, 2, blue},
+	}
+
+	for _, tc := range testCases {
+		actual := ApplyOrientation(img, tc.orientation)
+		assert.Equal(t, tc.width, actual.Bounds().Dx(), "orientation %d", tc.orientation)
+		assert.Equal(t, tc.height, actual.Bounds().Dy(), "orientation %d", tc.orientation)
+		assert.Equal(t, tc.first, color.NRGBAModel.Convert(actual.At(0, 0)), "orientation %d", tc.orientation)
+	}
+}
@@ -0,0 +1,284 @@
+package images
+
+import (
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/nfnt/resize"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+)
+
+const (
+	PNG  = "png"
+	JPEG = "jpeg"
+	GIF  = "gif"
+	WebP = "webp"
+
+	FitMode  = "fit"
+	CropMode = "crop"
+
+	ThumbnailRendition = "thumbnail"
+	MainRendition      = "main"
+	// OriginalRendition is always stored, and is what other renditions get regenerated from
+	OriginalRendition = "original"
+
+	DefaultJPEGQuality   = jpeg.DefaultQuality
+	DefaultInterpolation = "lanczos"
+
+	// this is relative to the `[image_storage]` config section that image storers are initialized with
+	renditionsKey = "renditions"
+)
+
+var (
+	// encodableFormats are the formats we can write renditions in. We can read WebP, but not write it.
+	encodableFormats = map[string]bool{
+		PNG:  true,
+		JPEG: true,
+		GIF:  true,
+	}
+
+	interpolationFunctions = map[string]resize.InterpolationFunction{
+		"nearest":  resize.NearestNeighbor,
+		"bilinear": resize.Bilinear,
+		"bicubic":  resize.Bicubic,
+		"mitchell": resize.MitchellNetravali,
+		"lanczos2": resize.Lanczos2,
+		"lanczos":  resize.Lanczos3,
+		"lanczos3": resize.Lanczos3,
+	}
+
+	// rendition names end up in file names and object keys, so we keep them boring
+	validRenditionName = regexp.MustCompile(`^[a-z0-9_\-]{1,32}$`)
+)
+
+// Rendition describes one of the versions of a product image that gets stored
+type Rendition struct {
+	Name string
+	// MaxWidth and MaxHeight bound the size of the rendition. Zero means unbounded.
+	MaxWidth  uint
+	MaxHeight uint
+	// Mode is either `fit`, which scales the image to fit within the bounds, or
+	// `crop`, which scales it to fill them and trims whatever hangs over.
+	Mode string
+	// Interpolation is the resampling function: lanczos, lanczos2, bicubic, mitchell, bilinear, or nearest
+	Interpolation string
+	// Format is the output format. When empty, the source image's format is kept where possible.
+	Format string
+	// Quality is the JPEG quality, from 1 to 100
+	Quality int
+}
+
+// RenditionSet is the full list of renditions an image storer creates for each image
+type RenditionSet []Rendition
+
+// DefaultRenditionSet returns the renditions used when none are configured
+func DefaultRenditionSet() RenditionSet {
+	return RenditionSet{
+		{Name: MainRendition, MaxWidth: 500, MaxHeight: 500, Mode: FitMode, Interpolation: DefaultInterpolation},
+		{Name: OriginalRendition},
+		{Name: ThumbnailRendition, MaxWidth: 100, MaxHeight: 100, Mode: FitMode, Interpolation: DefaultInterpolation},
+	}
+}
+
+// LoadRenditionSet reads renditions from the `[image_storage.renditions.<name>]` config sections. cfg should
+// be the `[image_storage]` section itself. When no renditions are configured, DefaultRenditionSet is used.
+// The original rendition is always included, though it only honors size limits, format, and quality.
+func LoadRenditionSet(cfg *viper.Viper) (RenditionSet, error) {
+	configured := cfg.GetStringMap(renditionsKey)
+	if len(configured) == 0 {
+		return DefaultRenditionSet(), nil
+	}
+
+	var (
+		out         RenditionSet
+		hasOriginal bool
+	)
+	for name := range configured {
+		r, err := loadRendition(cfg, name)
+		if err != nil {
+			return nil, err
+		}
+		hasOriginal = hasOriginal || r.Name == OriginalRendition
+		out = append(out, r)
+	}
+
+	if !hasOriginal {
+		out = append(out, Rendition{Name: OriginalRendition})
+	}
+
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+func loadRendition(cfg *viper.Viper, name string) (Rendition, error) {
+	key := func(field string) string {
+		return fmt.Sprintf("%s.%s.%s", renditionsKey, name, field)
+	}
+
+	r := Rendition{
+		Name:          strings.ToLower(name),
+		MaxWidth:      uint(cfg.GetInt(key("max_width"))),
+		MaxHeight:     uint(cfg.GetInt(key("max_height"))),
+		Mode:          strings.ToLower(cfg.GetString(key("mode"))),
+		Interpolation: strings.ToLower(cfg.GetString(key("interpolation"))),
+		Format:        strings.ToLower(cfg.GetString(key("format"))),
+		Quality:       cfg.GetInt(key("quality")),
+	}
+
+	if !validRenditionName.MatchString(r.Name) {
+		return r, fmt.Errorf("invalid rendition name: %s", name)
+	}
+	if cfg.GetInt(key("max_width")) < 0 || cfg.GetInt(key("max_height")) < 0 {
+		return r, fmt.Errorf("invalid %s rendition size", name)
+	}
+
+	if r.Mode == "" {
+		r.Mode = FitMode
+	} else if r.Mode != FitMode && r.Mode != CropMode {
+		return r, fmt.Errorf("invalid %s rendition mode: %s", name, r.Mode)
+	}
+	if r.Mode == CropMode && (r.MaxWidth == 0 || r.MaxHeight == 0) {
+		return r, fmt.Errorf("%s rendition must have both a width and height to be cropped", name)
+	}
+
+	if r.Interpolation == "" {
+		r.Interpolation = DefaultInterpolation
+	} else if _, ok := interpolationFunctions[r.Interpolation]; !ok {
+		return r, fmt.Errorf("invalid %s rendition interpolation: %s", name, r.Interpolation)
+	}
+
+	if r.Format == "jpg" {
+		r.Format = JPEG
+	}
+	if r.Format != "" && !encodableFormats[r.Format] {
+		return r, fmt.Errorf("invalid %s rendition format: %s", name, r.Format)
+	}
+	if r.Quality < 0 || r.Quality > 100 {
+		return r, fmt.Errorf("invalid %s rendition quality: %d", name, r.Quality)
+	}
+	return r, nil
+}
+
+// Get returns the rendition with the given name
+func (rs RenditionSet) Get(name string) (Rendition, bool) {
+	for _, r := range rs {
+		if r.Name == name {
+			return r, true
+		}
+	}
+	return Rendition{}, false
+}
+
+// Create builds every rendition of an image
+func (rs RenditionSet) Create(in image.Image) ProductImageSet {
+	out := ProductImageSet{Renditions: map[string]image.Image{}}
+	for _, r := range rs {
+		out.Renditions[r.Name] = r.Apply(in)
+	}
+	return out
+}
+
+// Apply resizes an image according to the rendition's bounds. Images are never scaled up.
+func (r Rendition) Apply(in image.Image) image.Image {
+	if r.MaxWidth == 0 && r.MaxHeight == 0 {
+		return in
+	}
+
+	interpolation, ok := interpolationFunctions[r.Interpolation]
+	if !ok {
+		interpolation = interpolationFunctions[DefaultInterpolation]
+	}
+
+	b := in.Bounds()
+	if r.Mode != CropMode {
+		maxWidth, maxHeight := r.MaxWidth, r.MaxHeight
+		if maxWidth == 0 {
+			maxWidth = uint(b.Dx())
+		}
+		if maxHeight == 0 {
+			maxHeight = uint(b.Dy())
+		}
+		return resize.Thumbnail(maxWidth, maxHeight, in, interpolation)
+	}
+
+	// crop mode: scale so the image covers the bounds, then trim the excess evenly from both sides
+	width, height := r.MaxWidth, r.MaxHeight
+	if uint(b.Dx()) < width || uint(b.Dy()) < height {
+		// too small to fill the bounds without scaling up, so shrink the bounds, keeping their aspect ratio
+		factor := math.Min(float64(b.Dx())/float64(width), float64(b.Dy())/float64(height))
+		width, height = uint(float64(width)*factor), uint(float64(height)*factor)
+	}
+
+	scaled := in
+	widthRatio := float64(width) / float64(b.Dx())
+	heightRatio := float64(height) / float64(b.Dy())
+	if widthRatio > heightRatio {
+		scaled = resize.Resize(width, 0, in, interpolation)
+	} else {
+		scaled = resize.Resize(0, height, in, interpolation)
+	}
+
+	sb := scaled.Bounds()
+	x0 := sb.Min.X + (sb.Dx()-int(width))/2
+	y0 := sb.Min.Y + (sb.Dy()-int(height))/2
+	crop := image.Rect(x0, y0, x0+int(width), y0+int(height))
+
+	if sub, ok := scaled.(interface {
+		SubImage(image.Rectangle) image.Image
+	}); ok {
+		return sub.SubImage(crop)
+	}
+	return scaled
+}
+
+// OutputFormat returns the format a rendition of an image in sourceFormat will be written in
+func (r Rendition) OutputFormat(sourceFormat string) string {
+	if r.Format != "" {
+		return r.Format
+	}
+	if encodableFormats[sourceFormat] {
+		return sourceFormat
+	}
+	// WebP (or anything else we can't write) falls back to a lossless format
+	return PNG
+}
+
+// Extension returns the file extension for a rendition of an image in sourceFormat
+func (r Rendition) Extension(sourceFormat string) string {
+	if format := r.OutputFormat(sourceFormat); format != JPEG {
+		return format
+	}
+	return "jpg"
+}
+
+// ContentType returns the MIME type of a rendition of an image in sourceFormat
+func (r Rendition) ContentType(sourceFormat string) string {
+	return fmt.Sprintf("image/%s", r.OutputFormat(sourceFormat))
+}
+
+// Encode writes a rendition of an image in sourceFormat to w
+func (r Rendition) Encode(w io.Writer, img image.Image, sourceFormat string) error {
+	var err error
+	switch r.OutputFormat(sourceFormat) {
+	case JPEG:
+		quality := r.Quality
+		if quality == 0 {
+			quality = DefaultJPEGQuality
+		}
+		err = jpeg.Encode(w, img, &jpeg.Options{Quality: quality})
+	case GIF:
+		err = gif.Encode(w, img, nil)
+	default:
+		err = png.Encode(w, img)
+	}
+	return errors.Wrap(err, fmt.Sprintf("error encoding %s image", r.Name))
+}
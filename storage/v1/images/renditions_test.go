@@ -0,0 +1,151 @@
+package images
+
+import (
+	"bytes"
+	"image"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadRenditionSet(t *testing.T) {
+	t.Parallel()
+
+	t.Run("optimal conditions", func(_t *testing.T) {
+		cfg := viper.New()
+		cfg.Set("renditions.thumbnail.max_width", 150)
+		cfg.Set("renditions.thumbnail.max_height", 150)
+		cfg.Set("renditions.thumbnail.mode", "crop")
+		cfg.Set("renditions.thumbnail.format", "jpg")
+		cfg.Set("renditions.thumbnail.quality", 60)
+		cfg.Set("renditions.zoom.max_width", 2000)
+		cfg.Set("renditions.zoom.interpolation", "bicubic")
+
+		expected := RenditionSet{
+			{Name: OriginalRendition},
+			{Name: ThumbnailRendition, MaxWidth: 150, MaxHeight: 150, Mode: CropMode, Interpolation: DefaultInterpolation, Format: JPEG, Quality: 60},
+			{Name: "zoom", MaxWidth: 2000, Mode: FitMode, Interpolation: "bicubic"},
+		}
+		actual, err := LoadRenditionSet(cfg)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("without renditions", func(_t *testing.T) {
+		actual, err := LoadRenditionSet(viper.New())
+		assert.NoError(t, err)
+		assert.Equal(t, DefaultRenditionSet(), actual)
+	})
+
+	t.Run("with configured original", func(_t *testing.T) {
+		cfg := viper.New()
+		cfg.Set("renditions.original.format", "png")
+
+		actual, err := LoadRenditionSet(cfg)
+		assert.NoError(t, err)
+		require.Len(t, actual, 1)
+		assert.Equal(t, PNG, actual[0].Format)
+	})
+
+	invalidCases := map[string]map[string]interface{}{
+		"with unwritable format":          {"renditions.original.format": "webp"},
+		"with invalid quality":            {"renditions.main.quality": 101},
+		"with invalid mode":               {"renditions.main.mode": "stretch"},
+		"with invalid interpolation":      {"renditions.main.interpolation": "crayons"},
+		"with negative size":              {"renditions.main.max_width": -1},
+		"with crop and unbounded side":    {"renditions.main.mode": "crop", "renditions.main.max_width": 10},
+		"with unsafe rendition name":      {"renditions.../main.max_width": 10},
+		"with overly long rendition name": {"renditions.abcdefghijklmnopqrstuvwxyz0123456789.max_width": 10},
+	}
+	for name, values := range invalidCases {
+		t.Run(name, func(_t *testing.T) {
+			cfg := viper.New()
+			for k, v := range values {
+				cfg.Set(k, v)
+			}
+			_, err := LoadRenditionSet(cfg)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestRenditionApply(t *testing.T) {
+	t.Parallel()
+
+	img := image.NewRGBA(image.Rect(0, 0, 200, 100))
+	testCases := []struct {
+		rendition Rendition
+		expected  image.Rectangle
+	}{
+		{Rendition{}, image.Rect(0, 0, 200, 100)},
+		{Rendition{MaxWidth: 100, MaxHeight: 100, Mode: FitMode}, image.Rect(0, 0, 100, 50)},
+		{Rendition{MaxWidth: 50, Mode: FitMode}, image.Rect(0, 0, 50, 25)},
+		{Rendition{MaxWidth: 1000, MaxHeight: 1000, Mode: FitMode}, image.Rect(0, 0, 200, 100)},
+		{Rendition{MaxWidth: 50, MaxHeight: 50, Mode: CropMode, Interpolation: "nearest"}, image.Rect(0, 0, 50, 50)},
+		{Rendition{MaxWidth: 150, MaxHeight: 150, Mode: CropMode}, image.Rect(0, 0, 100, 100)},
+	}
+
+	for _, tc := range testCases {
+		b := tc.rendition.Apply(img).Bounds()
+		assert.Equal(t, tc.expected, b.Sub(b.Min), "%+v", tc.rendition)
+	}
+}
+
+func TestRenditionSetCreate(t *testing.T) {
+	t.Parallel()
+
+	imgset := DefaultRenditionSet().Create(image.NewRGBA(image.Rect(0, 0, 1000, 1000)))
+	require.Len(t, imgset.Renditions, 3)
+	assert.Equal(t, 100, imgset.Renditions[ThumbnailRendition].Bounds().Dx())
+	assert.Equal(t, 500, imgset.Renditions[MainRendition].Bounds().Dx())
+	assert.Equal(t, 1000, imgset.Renditions[OriginalRendition].Bounds().Dx())
+}
+
+func TestRenditionOutputFormat(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		rendition    Rendition
+		sourceFormat string
+		expected     string
+		extension    string
+	}{
+		{Rendition{}, PNG, PNG, "png"},
+		{Rendition{}, JPEG, JPEG, "jpg"},
+		{Rendition{}, GIF, GIF, "gif"},
+		{Rendition{}, WebP, PNG, "png"},
+		{Rendition{Format: JPEG}, PNG, JPEG, "jpg"},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, tc.rendition.OutputFormat(tc.sourceFormat))
+		assert.Equal(t, tc.extension, tc.rendition.Extension(tc.sourceFormat))
+	}
+}
+
+func TestRenditionEncode(t *testing.T) {
+	t.Parallel()
+
+	img := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	for _, format := range []string{PNG, JPEG, GIF} {
+		buf := &bytes.Buffer{}
+		require.NoError(t, Rendition{Format: format, Quality: 90}.Encode(buf, img, WebP))
+
+		_, actualFormat, err := image.Decode(buf)
+		assert.NoError(t, err)
+		assert.Equal(t, format, actualFormat)
+	}
+}
+
+func TestProductImageLocationsDifference(t *testing.T) {
+	t.Parallel()
+
+	previous := ProductImageLocations{ThumbnailRendition: "a.png", MainRendition: "b.png", OriginalRendition: "c.png"}
+	current := ProductImageLocations{ThumbnailRendition: "a.jpg", MainRendition: "b.png", "zoom": "c.png"}
+
+	expected := ProductImageLocations{ThumbnailRendition: "a.png"}
+	assert.Equal(t, expected, previous.Difference(current))
+	assert.Empty(t, previous.Difference(previous))
+}
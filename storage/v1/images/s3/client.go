@@ -0,0 +1,303 @@
+package s3
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	signingAlgorithm = "AWS4-HMAC-SHA256"
+	amzDateFormat    = "20060102T150405Z"
+	amzDayFormat     = "20060102"
+	unsignedPayload  = "UNSIGNED-PAYLOAD"
+	serviceName      = "s3"
+)
+
+// client is just enough of an S3 client for storing product images. It speaks to anything
+// that understands S3's REST API and signature version 4, like AWS, MinIO, or Ceph.
+type client struct {
+	endpoint        *url.URL
+	bucket          string
+	region          string
+	accessKeyID     string
+	secretAccessKey string
+	pathStyle       bool
+
+	httpClient *http.Client
+	now        func() time.Time
+}
+
+// objectURL returns the unsigned URL of an object
+func (c *client) objectURL(key string) *url.URL {
+	u := *c.endpoint
+	if c.pathStyle {
+		u.Path = fmt.Sprintf("%s/%s/%s", strings.TrimSuffix(u.Path, "/"), c.bucket, key)
+	} else {
+		u.Host = fmt.Sprintf("%s.%s", c.bucket, u.Host)
+		u.Path = fmt.Sprintf("%s/%s", strings.TrimSuffix(u.Path, "/"), key)
+	}
+	return &u
+}
+
+func (c *client) putObject(key string, body []byte, contentType string) error {
+	req, err := http.NewRequest(http.MethodPut, c.objectURL(key).String(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	c.sign(req, hashHex(body))
+	return c.do(req, http.StatusOK)
+}
+
+func (c *client) deleteObject(key string) error {
+	req, err := http.NewRequest(http.MethodDelete, c.objectURL(key).String(), nil)
+	if err != nil {
+		return err
+	}
+	c.sign(req, hashHex(nil))
+	// S3 happily returns a 204 for objects that don't exist
+	return c.do(req, http.StatusNoContent, http.StatusOK)
+}
+
+// getObject returns the body of an object, which the caller must close
+func (c *client) getObject(key string) (io.ReadCloser, error) {
+	req, err := http.NewRequest(http.MethodGet, c.objectURL(key).String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	c.sign(req, hashHex(nil))
+	return c.fetch(req)
+}
+
+// fetch makes a request that's expected to succeed with a body, and returns that body for the caller to close
+func (c *client) fetch(req *http.Request) (io.ReadCloser, error) {
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "error communicating with object storage")
+	}
+	if res.StatusCode != http.StatusOK {
+		defer res.Body.Close()
+		body, _ := ioutil.ReadAll(io.LimitReader(res.Body, 1024))
+		return nil, fmt.Errorf("object storage responded to %s %s with status %d: %s", req.Method, req.URL.Path, res.StatusCode, body)
+	}
+	return res.Body, nil
+}
+
+type objectInfo struct {
+	Key          string    `xml:"Key"`
+	Size         int64     `xml:"Size"`
+	LastModified time.Time `xml:"LastModified"`
+}
+
+type listObjectsResult struct {
+	Contents              []objectInfo `xml:"Contents"`
+	IsTruncated           bool         `xml:"IsTruncated"`
+	NextContinuationToken string       `xml:"NextContinuationToken"`
+}
+
+// listObjects returns every object whose key starts with prefix, following continuation tokens until there are no more
+func (c *client) listObjects(prefix string) ([]objectInfo, error) {
+	var (
+		out   []objectInfo
+		token string
+	)
+	for {
+		u := c.objectURL("")
+		q := url.Values{}
+		q.Set("list-type", "2")
+		if prefix != "" {
+			q.Set("prefix", prefix)
+		}
+		if token != "" {
+			q.Set("continuation-token", token)
+		}
+		u.RawQuery = canonicalQuery(q)
+
+		req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+		if err != nil {
+			return nil, err
+		}
+		c.sign(req, hashHex(nil))
+
+		body, err := c.fetch(req)
+		if err != nil {
+			return nil, err
+		}
+		var result listObjectsResult
+		err = xml.NewDecoder(body).Decode(&result)
+		body.Close()
+		if err != nil {
+			return nil, errors.Wrap(err, "error parsing object list")
+		}
+
+		out = append(out, result.Contents...)
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return out, nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+// presignGetObject returns a URL anybody can use to fetch an object until it expires
+func (c *client) presignGetObject(key string, expiry time.Duration) string {
+	u := c.objectURL(key)
+	now := c.now().UTC()
+
+	q := url.Values{}
+	q.Set("X-Amz-Algorithm", signingAlgorithm)
+	q.Set("X-Amz-Credential", fmt.Sprintf("%s/%s", c.accessKeyID, c.scope(now)))
+	q.Set("X-Amz-Date", now.Format(amzDateFormat))
+	q.Set("X-Amz-Expires", fmt.Sprintf("%d", int64(expiry/time.Second)))
+	q.Set("X-Amz-SignedHeaders", "host")
+
+	canonicalRequest := strings.Join([]string{
+		http.MethodGet,
+		canonicalURI(u),
+		canonicalQuery(q),
+		fmt.Sprintf("host:%s\n", u.Host),
+		"host",
+		unsignedPayload,
+	}, "\n")
+
+	q.Set("X-Amz-Signature", c.signature(now, canonicalRequest))
+	u.RawQuery = canonicalQuery(q)
+	return u.String()
+}
+
+func (c *client) do(req *http.Request, acceptableStatuses ...int) error {
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "error communicating with object storage")
+	}
+	defer res.Body.Close()
+
+	for _, status := range acceptableStatuses {
+		if res.StatusCode == status {
+			io.Copy(ioutil.Discard, res.Body)
+			return nil
+		}
+	}
+
+	body, _ := ioutil.ReadAll(io.LimitReader(res.Body, 1024))
+	return fmt.Errorf("object storage responded to %s %s with status %d: %s", req.Method, req.URL.Path, res.StatusCode, body)
+}
+
+// sign adds signature version 4 authentication headers to a request
+func (c *client) sign(req *http.Request, payloadHash string) {
+	now := c.now().UTC()
+	req.Header.Set("X-Amz-Date", now.Format(amzDateFormat))
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headerNames := []string{"host"}
+	headerValues := map[string]string{"host": req.URL.Host}
+	for name := range req.Header {
+		lower := strings.ToLower(name)
+		if strings.HasPrefix(lower, "x-amz-") || lower == "content-type" {
+			headerNames = append(headerNames, lower)
+			headerValues[lower] = strings.TrimSpace(req.Header.Get(name))
+		}
+	}
+	sort.Strings(headerNames)
+
+	var canonicalHeaders string
+	for _, name := range headerNames {
+		canonicalHeaders += fmt.Sprintf("%s:%s\n", name, headerValues[name])
+	}
+	signedHeaders := strings.Join(headerNames, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalURI(req.URL),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		signingAlgorithm, c.accessKeyID, c.scope(now), signedHeaders, c.signature(now, canonicalRequest)))
+}
+
+func (c *client) scope(t time.Time) string {
+	return fmt.Sprintf("%s/%s/%s/aws4_request", t.Format(amzDayFormat), c.region, serviceName)
+}
+
+func (c *client) signature(t time.Time, canonicalRequest string) string {
+	stringToSign := strings.Join([]string{
+		signingAlgorithm,
+		t.Format(amzDateFormat),
+		c.scope(t),
+		hashHex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+c.secretAccessKey), t.Format(amzDayFormat))
+	key = hmacSHA256(key, c.region)
+	key = hmacSHA256(key, serviceName)
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func hashHex(in []byte) string {
+	sum := sha256.Sum256(in)
+	return hex.EncodeToString(sum[:])
+}
+
+// uriEncode escapes everything but the unreserved characters, the way S3 expects
+func uriEncode(in string, encodeSlash bool) string {
+	var out strings.Builder
+	for _, b := range []byte(in) {
+		switch {
+		case 'A' <= b && b <= 'Z', 'a' <= b && b <= 'z', '0' <= b && b <= '9', b == '-', b == '_', b == '.', b == '~':
+			out.WriteByte(b)
+		case b == '/' && !encodeSlash:
+			out.WriteByte(b)
+		default:
+			fmt.Fprintf(&out, "%%%02X", b)
+		}
+	}
+	return out.String()
+}
+
+func canonicalURI(u *url.URL) string {
+	if u.Path == "" {
+		return "/"
+	}
+	return uriEncode(u.Path, false)
+}
+
+func canonicalQuery(q url.Values) string {
+	var keys []string
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var pairs []string
+	for _, k := range keys {
+		values := q[k]
+		sort.Strings(values)
+		for _, v := range values {
+			pairs = append(pairs, fmt.Sprintf("%s=%s", uriEncode(k, true), uriEncode(v, true)))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
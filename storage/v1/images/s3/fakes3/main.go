@@ -0,0 +1,185 @@
+// Package fakes3 is an in-process stand-in for an S3 bucket, for testing things that use object storage
+// without having to run MinIO. It only understands path-style requests, and doesn't verify signatures
+// beyond making sure they were made with the right access key.
+package fakes3
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Object struct {
+	Body         []byte
+	ContentType  string
+	LastModified time.Time
+}
+
+type Server struct {
+	*httptest.Server
+	Bucket      string
+	AccessKeyID string
+	// MaxKeys is how many objects a list request returns at most, like S3's own limit of 1000
+	MaxKeys int
+
+	mu      sync.Mutex
+	objects map[string]Object
+}
+
+// NewServer starts a fake S3 server with a single, empty bucket. Close it when you're done.
+func NewServer(bucket, accessKeyID string) *Server {
+	s := &Server{
+		Bucket:      bucket,
+		AccessKeyID: accessKeyID,
+		MaxKeys:     1000,
+		objects:     map[string]Object{},
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// Object returns the object stored under key, if there is one
+func (s *Server) Object(key string) (Object, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o, ok := s.objects[key]
+	return o, ok
+}
+
+// PutObject stores an object directly, without going through the API. It's considered modified now,
+// unless it says otherwise.
+func (s *Server) PutObject(key string, o Object) {
+	if o.LastModified.IsZero() {
+		o.LastModified = time.Now()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[key] = o
+}
+
+// Keys returns the keys of every stored object, in order
+func (s *Server) Keys() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var keys []string
+	for k := range s.objects {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (s *Server) authorized(req *http.Request) bool {
+	if auth := req.Header.Get("Authorization"); auth != "" {
+		return strings.Contains(auth, fmt.Sprintf("Credential=%s/", s.AccessKeyID)) && strings.Contains(auth, "Signature=")
+	}
+	q := req.URL.Query()
+	return strings.HasPrefix(q.Get("X-Amz-Credential"), fmt.Sprintf("%s/", s.AccessKeyID)) && q.Get("X-Amz-Signature") != ""
+}
+
+func writeError(res http.ResponseWriter, status int, code string) {
+	res.Header().Set("Content-Type", "application/xml")
+	res.WriteHeader(status)
+	fmt.Fprintf(res, `<?xml version="1.0" encoding="UTF-8"?><Error><Code>%s</Code></Error>`, code)
+}
+
+func (s *Server) handle(res http.ResponseWriter, req *http.Request) {
+	if !s.authorized(req) {
+		writeError(res, http.StatusForbidden, "AccessDenied")
+		return
+	}
+
+	parts := strings.SplitN(strings.TrimPrefix(req.URL.Path, "/"), "/", 2)
+	if parts[0] != s.Bucket {
+		writeError(res, http.StatusNotFound, "NoSuchBucket")
+		return
+	}
+
+	var key string
+	if len(parts) == 2 {
+		key = parts[1]
+	}
+
+	if key == "" {
+		if req.Method == http.MethodGet && req.URL.Query().Get("list-type") == "2" {
+			s.listObjects(res, req)
+			return
+		}
+		writeError(res, http.StatusNotImplemented, "NotImplemented")
+		return
+	}
+
+	switch req.Method {
+	case http.MethodPut:
+		body, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			writeError(res, http.StatusBadRequest, "IncompleteBody")
+			return
+		}
+		s.PutObject(key, Object{Body: body, ContentType: req.Header.Get("Content-Type")})
+		res.WriteHeader(http.StatusOK)
+	case http.MethodGet:
+		o, ok := s.Object(key)
+		if !ok {
+			writeError(res, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		res.Header().Set("Content-Type", o.ContentType)
+		res.Write(o.Body)
+	case http.MethodDelete:
+		s.mu.Lock()
+		delete(s.objects, key)
+		s.mu.Unlock()
+		res.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(res, http.StatusMethodNotAllowed, "MethodNotAllowed")
+	}
+}
+
+type listedObject struct {
+	Key          string    `xml:"Key"`
+	Size         int       `xml:"Size"`
+	LastModified time.Time `xml:"LastModified"`
+}
+
+type listBucketResult struct {
+	XMLName               xml.Name       `xml:"ListBucketResult"`
+	Name                  string         `xml:"Name"`
+	Prefix                string         `xml:"Prefix"`
+	KeyCount              int            `xml:"KeyCount"`
+	IsTruncated           bool           `xml:"IsTruncated"`
+	NextContinuationToken string         `xml:"NextContinuationToken,omitempty"`
+	Contents              []listedObject `xml:"Contents"`
+}
+
+// listObjects handles ListObjectsV2 requests. Continuation tokens are just the last key of the previous page.
+func (s *Server) listObjects(res http.ResponseWriter, req *http.Request) {
+	q := req.URL.Query()
+	prefix, after := q.Get("prefix"), q.Get("continuation-token")
+
+	result := listBucketResult{Name: s.Bucket, Prefix: prefix}
+	for _, key := range s.Keys() {
+		if !strings.HasPrefix(key, prefix) || (after != "" && key <= after) {
+			continue
+		}
+		if len(result.Contents) == s.MaxKeys {
+			result.IsTruncated = true
+			result.NextContinuationToken = result.Contents[len(result.Contents)-1].Key
+			break
+		}
+
+		o, _ := s.Object(key)
+		result.Contents = append(result.Contents, listedObject{Key: key, Size: len(o.Body), LastModified: o.LastModified.UTC()})
+	}
+	result.KeyCount = len(result.Contents)
+
+	res.Header().Set("Content-Type", "application/xml")
+	res.Write([]byte(xml.Header))
+	xml.NewEncoder(res).Encode(result)
+}
@@ -0,0 +1,302 @@
+package s3
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/dairycart/dairycart/storage/v1/images"
+
+	"github.com/go-chi/chi"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+)
+
+const (
+	// these are all relative to the `[image_storage]` config section
+	endpointKey        = "endpoint"
+	bucketKey          = "bucket"
+	regionKey          = "region"
+	accessKeyIDKey     = "access_key_id"
+	secretAccessKeyKey = "secret_access_key"
+	pathStyleKey       = "path_style"
+	keyPrefixKey       = "key_prefix"
+	urlModeKey         = "url_mode"
+	publicURLKey       = "public_url"
+	baseURLKey         = "base_url"
+	routePrefixKey     = "route_prefix"
+	presignExpiryKey   = "presign_expiry"
+
+	// PublicURLMode means images are linked to directly, so the bucket (or a CDN in front of it) must be public
+	PublicURLMode = "public"
+	// PresignedURLMode means images are linked to through the API, which redirects to a short-lived pre-signed URL
+	PresignedURLMode = "presigned"
+
+	defaultRegion         = "us-east-1"
+	defaultKeyPrefix      = "product_images"
+	defaultBaseURL        = "http://localhost:4321"
+	defaultRoutePrefix    = "product_images"
+	defaultPresignExpiry  = 15 * time.Minute
+	defaultRequestTimeout = 30 * time.Second
+)
+
+type s3ImageStorer struct {
+	KeyPrefix     string
+	URLMode       string
+	PublicURL     string
+	BaseURL       string
+	RoutePrefix   string
+	PresignExpiry time.Duration
+	Renditions    images.RenditionSet
+
+	client *client
+}
+
+var (
+	_ images.ImageStorer  = (*s3ImageStorer)(nil)
+	_ images.RouteMounter = (*s3ImageStorer)(nil)
+)
+
+func NewS3ImageStorer() *s3ImageStorer {
+	return &s3ImageStorer{
+		KeyPrefix:     defaultKeyPrefix,
+		URLMode:       PublicURLMode,
+		BaseURL:       defaultBaseURL,
+		RoutePrefix:   defaultRoutePrefix,
+		PresignExpiry: defaultPresignExpiry,
+		Renditions:    images.DefaultRenditionSet(),
+	}
+}
+
+func (s *s3ImageStorer) Init(cfg *viper.Viper, router chi.Router) error {
+	bucket := cfg.GetString(bucketKey)
+	if bucket == "" {
+		return errors.New("no bucket specified for s3 image storage")
+	}
+
+	region := cfg.GetString(regionKey)
+	if region == "" {
+		region = defaultRegion
+	}
+
+	endpoint := cfg.GetString(endpointKey)
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", region)
+	}
+	endpointURL, err := url.Parse(endpoint)
+	if err != nil || endpointURL.Host == "" {
+		return fmt.Errorf("invalid s3 endpoint: %s", endpoint)
+	}
+
+	// credentials can come from the environment, so they don't have to live in the config file
+	accessKeyID := cfg.GetString(accessKeyIDKey)
+	if accessKeyID == "" {
+		accessKeyID = os.Getenv("AWS_ACCESS_KEY_ID")
+	}
+	secretAccessKey := cfg.GetString(secretAccessKeyKey)
+	if secretAccessKey == "" {
+		secretAccessKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+	}
+	if accessKeyID == "" || secretAccessKey == "" {
+		return errors.New("no credentials provided for s3 image storage")
+	}
+
+	s.client = &client{
+		endpoint:        endpointURL,
+		bucket:          bucket,
+		region:          region,
+		accessKeyID:     accessKeyID,
+		secretAccessKey: secretAccessKey,
+		pathStyle:       cfg.GetBool(pathStyleKey),
+		httpClient:      &http.Client{Timeout: defaultRequestTimeout},
+		now:             time.Now,
+	}
+
+	if cfg.IsSet(keyPrefixKey) {
+		s.KeyPrefix = strings.Trim(cfg.GetString(keyPrefixKey), "/")
+	}
+
+	s.Renditions, err = images.LoadRenditionSet(cfg)
+	if err != nil {
+		return errors.Wrap(err, "error parsing image rendition config")
+	}
+
+	if mode := cfg.GetString(urlModeKey); mode != "" {
+		s.URLMode = mode
+	}
+	switch s.URLMode {
+	case PublicURLMode:
+		s.PublicURL = strings.TrimSuffix(cfg.GetString(publicURLKey), "/")
+		if s.PublicURL == "" {
+			s.PublicURL = strings.TrimSuffix(s.client.objectURL("").String(), "/")
+		}
+	case PresignedURLMode:
+		if baseURL := cfg.GetString(baseURLKey); baseURL != "" {
+			s.BaseURL = strings.TrimSuffix(baseURL, "/")
+		}
+		if routePrefix := strings.Trim(cfg.GetString(routePrefixKey), "/"); routePrefix != "" {
+			s.RoutePrefix = routePrefix
+		}
+		if expiry := cfg.GetDuration(presignExpiryKey); expiry > 0 {
+			s.PresignExpiry = expiry
+		}
+		router.Get("/*", s.buildPresignedRedirectHandler())
+	default:
+		return fmt.Errorf("invalid s3 url mode: %s", s.URLMode)
+	}
+
+	return nil
+}
+
+// RouteMountPath is where the pre-signed redirect route has to be served from for the URLs StoreImages returns to work
+func (s *s3ImageStorer) RouteMountPath() string {
+	return "/" + s.RoutePrefix
+}
+
+func (s *s3ImageStorer) CreateThumbnails(in image.Image) images.ProductImageSet {
+	return s.Renditions.Create(in)
+}
+
+func (s *s3ImageStorer) objectKey(relativePath string) string {
+	if s.KeyPrefix == "" {
+		return relativePath
+	}
+	return fmt.Sprintf("%s/%s", s.KeyPrefix, relativePath)
+}
+
+// locationForKey returns the URL an object will be linked to from
+func (s *s3ImageStorer) locationForKey(key string) string {
+	if s.URLMode == PresignedURLMode {
+		return fmt.Sprintf("%s/%s/%s", s.BaseURL, s.RoutePrefix, strings.TrimPrefix(key, s.objectKey("")))
+	}
+	return fmt.Sprintf("%s/%s", s.PublicURL, key)
+}
+
+// keyForLocation is the inverse of locationForKey
+func (s *s3ImageStorer) keyForLocation(location string) (string, error) {
+	var key string
+	if s.URLMode == PresignedURLMode {
+		prefix := fmt.Sprintf("%s/%s/", s.BaseURL, s.RoutePrefix)
+		if !strings.HasPrefix(location, prefix) {
+			return "", fmt.Errorf("image location %s is not managed by this image storer", location)
+		}
+		key = s.objectKey(strings.TrimPrefix(location, prefix))
+	} else {
+		prefix := fmt.Sprintf("%s/", s.PublicURL)
+		if !strings.HasPrefix(location, prefix) {
+			return "", fmt.Errorf("image location %s is not managed by this image storer", location)
+		}
+		key = strings.TrimPrefix(location, prefix)
+	}
+
+	if !strings.HasPrefix(key, s.objectKey("")) || path.Clean("/"+key) != "/"+key {
+		return "", fmt.Errorf("image location %s is not managed by this image storer", location)
+	}
+	return key, nil
+}
+
+func (s *s3ImageStorer) storeRendition(img image.Image, sourceFormat string, rendition images.Rendition, key string) (string, error) {
+	buf := &bytes.Buffer{}
+	if err := rendition.Encode(buf, img, sourceFormat); err != nil {
+		return "", err
+	}
+
+	objectKey := s.objectKey(fmt.Sprintf("%s/%s.%s", key, rendition.Name, rendition.Extension(sourceFormat)))
+	if err := s.client.putObject(objectKey, buf.Bytes(), rendition.ContentType(sourceFormat)); err != nil {
+		return "", errors.Wrap(err, fmt.Sprintf("error uploading %s image", rendition.Name))
+	}
+	return s.locationForKey(objectKey), nil
+}
+
+func (s *s3ImageStorer) StoreImages(in images.ProductImageSet, key string) (images.ProductImageLocations, error) {
+	out := images.ProductImageLocations{}
+
+	for _, rendition := range s.Renditions {
+		img, ok := in.Renditions[rendition.Name]
+		if !ok {
+			continue
+		}
+
+		location, err := s.storeRendition(img, in.Format, rendition, key)
+		if err != nil {
+			return out, err
+		}
+		out[rendition.Name] = location
+	}
+
+	return out, nil
+}
+
+func (s *s3ImageStorer) DeleteImages(in images.ProductImageLocations) error {
+	for _, location := range in {
+		if location == "" {
+			continue
+		}
+
+		key, err := s.keyForLocation(location)
+		if err != nil {
+			return err
+		}
+
+		if err = s.client.deleteObject(key); err != nil {
+			return errors.Wrap(err, "error deleting image from object storage")
+		}
+	}
+	return nil
+}
+
+func (s *s3ImageStorer) ReadImage(location string) (io.ReadCloser, error) {
+	key, err := s.keyForLocation(location)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := s.client.getObject(key)
+	return body, errors.Wrap(err, "error reading image from object storage")
+}
+
+func (s *s3ImageStorer) ListImages() ([]images.StoredImage, error) {
+	objects, err := s.client.listObjects(s.objectKey(""))
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing images in object storage")
+	}
+
+	out := make([]images.StoredImage, 0, len(objects))
+	for _, o := range objects {
+		out = append(out, images.StoredImage{
+			Location:   s.locationForKey(o.Key),
+			Size:       o.Size,
+			ModifiedOn: o.LastModified,
+		})
+	}
+	return out, nil
+}
+
+func (s *s3ImageStorer) DeleteImage(location string) error {
+	key, err := s.keyForLocation(location)
+	if err != nil {
+		return err
+	}
+	return errors.Wrap(s.client.deleteObject(key), "error deleting image from object storage")
+}
+
+func (s *s3ImageStorer) buildPresignedRedirectHandler() http.HandlerFunc {
+	return func(res http.ResponseWriter, req *http.Request) {
+		relativePath := chi.URLParam(req, "*")
+		if relativePath == "" || path.Clean("/"+relativePath) != "/"+relativePath {
+			http.NotFound(res, req)
+			return
+		}
+
+		// let clients hang onto the redirect for a while, but not for as long as the URL is good for
+		res.Header().Set("Cache-Control", fmt.Sprintf("private, max-age=%d", int64(s.PresignExpiry/time.Second)/2))
+		http.Redirect(res, req, s.client.presignGetObject(s.objectKey(relativePath), s.PresignExpiry), http.StatusFound)
+	}
+}
@@ -0,0 +1,233 @@
+package s3
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	_ "image/jpeg"
+	_ "image/png"
+
+	"github.com/dairycart/dairycart/storage/v1/images"
+	"github.com/dairycart/dairycart/storage/v1/images/s3/fakes3"
+
+	"github.com/go-chi/chi"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	exampleBucket      = "dairycart"
+	exampleAccessKeyID = "AKIDEXAMPLE"
+)
+
+func buildTestConfig(endpoint string) *viper.Viper {
+	cfg := viper.New()
+	cfg.Set(endpointKey, endpoint)
+	cfg.Set(bucketKey, exampleBucket)
+	cfg.Set(accessKeyIDKey, exampleAccessKeyID)
+	cfg.Set(secretAccessKeyKey, "secret")
+	cfg.Set(pathStyleKey, true)
+	return cfg
+}
+
+func TestInit(t *testing.T) {
+	t.Run("optimal conditions", func(*testing.T) {
+		s := NewS3ImageStorer()
+		err := s.Init(buildTestConfig("http://localhost:9000"), chi.NewRouter())
+		assert.NoError(t, err)
+		assert.Equal(t, "http://localhost:9000/dairycart", s.PublicURL)
+	})
+
+	t.Run("without bucket", func(*testing.T) {
+		cfg := buildTestConfig("http://localhost:9000")
+		cfg.Set(bucketKey, "")
+		assert.Error(t, NewS3ImageStorer().Init(cfg, chi.NewRouter()))
+	})
+
+	t.Run("without credentials", func(*testing.T) {
+		cfg := buildTestConfig("http://localhost:9000")
+		cfg.Set(secretAccessKeyKey, "")
+		assert.Error(t, NewS3ImageStorer().Init(cfg, chi.NewRouter()))
+	})
+
+	t.Run("with invalid url mode", func(*testing.T) {
+		cfg := buildTestConfig("http://localhost:9000")
+		cfg.Set(urlModeKey, "carrier pigeon")
+		assert.Error(t, NewS3ImageStorer().Init(cfg, chi.NewRouter()))
+	})
+}
+
+func TestStoreAndDeleteImages(t *testing.T) {
+	server := fakes3.NewServer(exampleBucket, exampleAccessKeyID)
+	defer server.Close()
+
+	cfg := buildTestConfig(server.URL)
+	cfg.Set(publicURLKey, "https://cdn.dairycart.com/")
+	cfg.Set("renditions.thumbnail.max_width", 5)
+	cfg.Set("renditions.thumbnail.max_height", 5)
+	cfg.Set("renditions.thumbnail.format", "jpeg")
+
+	s := NewS3ImageStorer()
+	require.NoError(t, s.Init(cfg, chi.NewRouter()))
+
+	imgset := s.CreateThumbnails(image.NewRGBA(image.Rect(0, 0, 10, 10)))
+	imgset.Format = images.PNG
+	locations, err := s.StoreImages(imgset, "skateboard/12")
+	require.NoError(t, err)
+
+	expected := images.ProductImageLocations{
+		images.ThumbnailRendition: "https://cdn.dairycart.com/product_images/skateboard/12/thumbnail.jpg",
+		images.OriginalRendition:  "https://cdn.dairycart.com/product_images/skateboard/12/original.png",
+	}
+	assert.Equal(t, expected, locations)
+
+	obj, ok := server.Object("product_images/skateboard/12/original.png")
+	require.True(t, ok)
+	assert.Equal(t, "image/png", obj.ContentType)
+	_, format, err := image.Decode(bytes.NewReader(obj.Body))
+	assert.NoError(t, err)
+	assert.Equal(t, images.PNG, format)
+
+	obj, ok = server.Object("product_images/skateboard/12/thumbnail.jpg")
+	require.True(t, ok)
+	assert.Equal(t, "image/jpeg", obj.ContentType)
+
+	rc, err := s.ReadImage(locations[images.ThumbnailRendition])
+	require.NoError(t, err)
+	thumbnail, _, err := image.Decode(rc)
+	rc.Close()
+	require.NoError(t, err)
+	assert.Equal(t, 5, thumbnail.Bounds().Dx())
+
+	require.NoError(t, s.DeleteImages(locations))
+	assert.Empty(t, server.Keys())
+
+	t.Run("with foreign location", func(*testing.T) {
+		err := s.DeleteImages(images.ProductImageLocations{images.MainRendition: "https://example.com/whatever.png"})
+		assert.Error(t, err)
+	})
+
+	t.Run("with location outside key prefix", func(*testing.T) {
+		err := s.DeleteImages(images.ProductImageLocations{images.MainRendition: "https://cdn.dairycart.com/product_images/../secrets.txt"})
+		assert.Error(t, err)
+	})
+
+	t.Run("with wrong credentials", func(*testing.T) {
+		wrongCfg := buildTestConfig(server.URL)
+		wrongCfg.Set(accessKeyIDKey, "nope")
+		wrong := NewS3ImageStorer()
+		require.NoError(t, wrong.Init(wrongCfg, chi.NewRouter()))
+
+		_, err := wrong.StoreImages(imgset, "skateboard/13")
+		assert.Error(t, err)
+	})
+}
+
+func TestListAndDeleteImage(t *testing.T) {
+	server := fakes3.NewServer(exampleBucket, exampleAccessKeyID)
+	defer server.Close()
+	// small pages make sure the continuation tokens get followed
+	server.MaxKeys = 2
+
+	cfg := buildTestConfig(server.URL)
+	cfg.Set(publicURLKey, "https://cdn.dairycart.com/")
+
+	s := NewS3ImageStorer()
+	require.NoError(t, s.Init(cfg, chi.NewRouter()))
+
+	modified := time.Date(2018, 6, 1, 12, 0, 0, 0, time.UTC)
+	for _, key := range []string{"product_images/a/1/original.png", "product_images/a/1/main.png", "product_images/b/2/original.png"} {
+		server.PutObject(key, fakes3.Object{Body: []byte("hi"), ContentType: "image/png", LastModified: modified})
+	}
+	// this one belongs to somebody else
+	server.PutObject("backups/dump.sql", fakes3.Object{Body: []byte("hi")})
+
+	stored, err := s.ListImages()
+	require.NoError(t, err)
+	expected := []images.StoredImage{
+		{Location: "https://cdn.dairycart.com/product_images/a/1/main.png", Size: 2, ModifiedOn: modified},
+		{Location: "https://cdn.dairycart.com/product_images/a/1/original.png", Size: 2, ModifiedOn: modified},
+		{Location: "https://cdn.dairycart.com/product_images/b/2/original.png", Size: 2, ModifiedOn: modified},
+	}
+	assert.Equal(t, expected, stored)
+
+	require.NoError(t, s.DeleteImage(stored[0].Location))
+	assert.Equal(t, []string{"backups/dump.sql", "product_images/a/1/original.png", "product_images/b/2/original.png"}, server.Keys())
+
+	assert.Error(t, s.DeleteImage("https://cdn.dairycart.com/backups/dump.sql"))
+}
+
+func TestPresignedURLMode(t *testing.T) {
+	server := fakes3.NewServer(exampleBucket, exampleAccessKeyID)
+	defer server.Close()
+
+	cfg := buildTestConfig(server.URL)
+	cfg.Set(urlModeKey, PresignedURLMode)
+	cfg.Set(baseURLKey, "https://api.dairycart.com")
+
+	router := chi.NewRouter()
+	s := NewS3ImageStorer()
+	router.Route("/product_images", func(r chi.Router) {
+		require.NoError(t, s.Init(cfg, r))
+	})
+	assert.Equal(t, "/product_images", s.RouteMountPath())
+
+	imgset := s.CreateThumbnails(image.NewRGBA(image.Rect(0, 0, 10, 10)))
+	imgset.Format = images.PNG
+	locations, err := s.StoreImages(imgset, "skateboard/12")
+	require.NoError(t, err)
+	assert.Equal(t, "https://api.dairycart.com/product_images/skateboard/12/main.png", locations[images.MainRendition])
+
+	res := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, strings.TrimPrefix(locations[images.MainRendition], "https://api.dairycart.com"), nil)
+	router.ServeHTTP(res, req)
+	require.Equal(t, http.StatusFound, res.Code)
+
+	redirect := res.Header().Get("Location")
+	assert.True(t, strings.HasPrefix(redirect, fmt.Sprintf("%s/%s/product_images/skateboard/12/main.png?", server.URL, exampleBucket)))
+
+	// the pre-signed URL should actually work
+	fetched, err := http.Get(redirect)
+	require.NoError(t, err)
+	defer fetched.Body.Close()
+	assert.Equal(t, http.StatusOK, fetched.StatusCode)
+
+	require.NoError(t, s.DeleteImages(locations))
+	assert.Empty(t, server.Keys())
+}
+
+func TestPresignedURLModeWithRoutePrefix(t *testing.T) {
+	server := fakes3.NewServer(exampleBucket, exampleAccessKeyID)
+	defer server.Close()
+
+	cfg := buildTestConfig(server.URL)
+	cfg.Set(urlModeKey, PresignedURLMode)
+	cfg.Set(baseURLKey, "https://api.dairycart.com")
+	cfg.Set(routePrefixKey, "/images/")
+
+	s := NewS3ImageStorer()
+	imageRouter := chi.NewRouter()
+	require.NoError(t, s.Init(cfg, imageRouter))
+	require.Equal(t, "/images", s.RouteMountPath())
+
+	router := chi.NewRouter()
+	router.Mount(s.RouteMountPath(), imageRouter)
+
+	imgset := s.CreateThumbnails(image.NewRGBA(image.Rect(0, 0, 10, 10)))
+	imgset.Format = images.PNG
+	locations, err := s.StoreImages(imgset, "skateboard/12")
+	require.NoError(t, err)
+	assert.Equal(t, "https://api.dairycart.com/images/skateboard/12/main.png", locations[images.MainRendition])
+
+	res := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, strings.TrimPrefix(locations[images.MainRendition], "https://api.dairycart.com"), nil)
+	router.ServeHTTP(res, req)
+	assert.Equal(t, http.StatusFound, res.Code)
+}